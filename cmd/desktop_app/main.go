@@ -12,6 +12,7 @@ import (
 	"github.com/nelsonmarro/verith/config"
 	"github.com/nelsonmarro/verith/internal/application/report"
 	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/domain"
 	"github.com/nelsonmarro/verith/internal/infrastructure/database"
 	persistence "github.com/nelsonmarro/verith/internal/infrastructure/persistence"
 	"github.com/nelsonmarro/verith/internal/infrastructure/storage"
@@ -85,33 +86,45 @@ func main() {
 	infoLogger.Println("Storage Service initialized.")
 
 	// ---- Infrastructure (Repositories) ----
-	accRepo := persistence.NewAccountRepository(pool)
-	catRepo := persistence.NewCategoryRepository(pool)
-	txRepo := persistence.NewTransactionRepository(pool)
-	reportRepo := persistence.NewReportRepository(pool)
+	// Los repositorios comparten la empresa elegida tras el login
+	companyScope := domain.NewCompanyScope(0)
+	companyRepo := persistence.NewCompanyRepository(pool)
+	accRepo := persistence.NewAccountRepository(pool, companyScope)
+	catRepo := persistence.NewCategoryRepository(pool, companyScope)
+	txRepo := persistence.NewTransactionRepository(pool, companyScope)
+	reportRepo := persistence.NewReportRepository(pool, companyScope)
 	userRepo := persistence.NewUserRepository(pool)
-	recurRepo := persistence.NewRecurringTransactionRepository(pool)
-	issuerRepo := persistence.NewIssuerRepository(pool)
-	receiptRepo := persistence.NewElectronicReceiptRepository(pool)
-	clientRepo := persistence.NewTaxPayerRepository(pool)
+	recurRepo := persistence.NewRecurringTransactionRepository(pool, companyScope)
+	issuerRepo := persistence.NewIssuerRepository(pool, companyScope)
+	receiptRepo := persistence.NewElectronicReceiptRepository(pool, companyScope)
+	clientRepo := persistence.NewTaxPayerRepository(pool, companyScope)
 	emissionRepo := persistence.NewEmissionPointRepository(pool)
+	shipmentRepo := persistence.NewShipmentRepository(pool, companyScope)
+	taxReportRepo := persistence.NewTaxReportRepository(pool, companyScope)
+	taxRateRepo := persistence.NewTaxRateRepository(pool)
 
 	// ---- Application (Report Generators) ----
 	csvGen := report.NewCSVReportGenerator()
 	pdfGen := report.NewPDFReportGenerator()
 
 	// ---- SRI (Client & Service) ----
-	sriClient := sri.NewSoapClient()
+	sriClient := sri.NewSoapClient().WithBaseURL(conf.SRI.BaseURL)
+	if conf.SRI.BaseURL != "" {
+		infoLogger.Printf("Usando servicios SRI en %s", conf.SRI.BaseURL)
+	}
 
 	// ---- Application (Services) ----
 	accService := service.NewAccountService(accRepo)
 	catService := service.NewCategoryService(catRepo)
-	txService := service.NewTransactionService(txRepo, storageService, accService)
+	txService := service.NewTransactionService(txRepo, storageService, accService, taxRateRepo)
 	userService := service.NewUserService(userRepo)
-	reportService := service.NewReportService(reportRepo, txRepo, catRepo, csvGen, pdfGen)
+	reportService := service.NewReportService(reportRepo, txRepo, catRepo, taxReportRepo, issuerRepo, csvGen, pdfGen)
 	recurService := service.NewRecurringTransactionService(recurRepo, txRepo, infoLogger)
-	issuerService := service.NewIssuerService(issuerRepo, emissionRepo)
+	issuerService := service.NewIssuerService(issuerRepo, emissionRepo, taxRateRepo)
 	taxService := service.NewTaxPayerService(clientRepo)
+	shipmentService := service.NewShipmentService(shipmentRepo, txRepo, clientRepo)
+	taxReportService := service.NewTaxReportService(taxReportRepo, issuerRepo)
+	companyService := service.NewCompanyService(companyRepo, companyScope)
 
 	// Decodificar API Key de Resend (inyectada al compilar)
 	resendAPIKey, err := security.DecodeSMTPPassword(ResendAPIKeyEncrypted)
@@ -122,7 +135,10 @@ func main() {
 
 	// Mail Service (Resend)
 	mailService := service.NewMailService(conf, resendAPIKey)
-	sriService := service.NewSriService(txRepo, issuerRepo, receiptRepo, clientRepo, emissionRepo, sriClient, mailService, infoLogger)
+	sriService := service.NewSriService(txRepo, issuerRepo, receiptRepo, clientRepo, emissionRepo, shipmentRepo, taxRateRepo, sriClient, mailService, infoLogger)
+	// Los XML descargados del SRI quedan junto a los adjuntos para poder retomar la descarga
+	receivedDir, _ := storageService.GetFullPath("recibidos")
+	purchaseImportService := service.NewPurchaseImportService(txRepo, clientRepo, issuerRepo, taxRateRepo, storageService, sriClient, receivedDir)
 
 	// ---- UI Initialization ----
 	myApp := app.NewWithID("com.verith")
@@ -130,15 +146,19 @@ func main() {
 
 	userUI := ui.NewUI(
 		&ui.Services{
-			AccService:    accService,
-			CatService:    catService,
-			TxService:     txService,
-			UserService:   userService,
-			ReportService: reportService,
-			RecurService:  recurService,
-			IssuerService: issuerService,
-			SriService:    sriService,
-			TaxService:    taxService,
+			AccService:            accService,
+			CatService:            catService,
+			TxService:             txService,
+			UserService:           userService,
+			ReportService:         reportService,
+			RecurService:          recurService,
+			IssuerService:         issuerService,
+			SriService:            sriService,
+			TaxService:            taxService,
+			ShipmentService:       shipmentService,
+			PurchaseImportService: purchaseImportService,
+			TaxReportService:      taxReportService,
+			CompanyService:        companyService,
 		},
 		infoLogger,
 		errorLogger,
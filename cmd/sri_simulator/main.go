@@ -0,0 +1,48 @@
+// Command sri_simulator levanta un simulador local de los servicios web offline del SRI.
+//
+// Para que la aplicación lo use, configure sri.base_url con la URL que se imprime al iniciar.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/nelsonmarro/verith/internal/sri/srisim"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8089", "dirección donde escucha el simulador")
+	devuelta := flag.String("devuelta", "", "códigos de error separados por coma con los que se devuelve cada comprobante (ej. 35,39)")
+	enProceso := flag.Int("en-proceso", 0, "consultas de autorización que responden EN PROCESO antes del resultado final")
+	noAutorizado := flag.String("no-autorizado", "", "códigos de error con los que se responde NO AUTORIZADO")
+	delay := flag.Duration("delay", 0, "demora de cada respuesta; mayor al timeout del cliente simula un SRI caído")
+	flag.Parse()
+
+	sim := srisim.NewServer(srisim.Scenario{
+		Devuelta:       mensajes(*devuelta),
+		PollsEnProceso: *enProceso,
+		NoAutorizado:   mensajes(*noAutorizado),
+		Delay:          *delay,
+	})
+
+	log.Printf("Simulador SRI escuchando en http://%s", *addr)
+	log.Printf("Recepción:    http://%s%s", *addr, sri.PathRecepcion)
+	log.Printf("Autorización: http://%s%s", *addr, sri.PathAutorizacion)
+
+	srv := &http.Server{Addr: *addr, Handler: sim, ReadHeaderTimeout: 10 * time.Second}
+	log.Fatal(srv.ListenAndServe())
+}
+
+func mensajes(codes string) []sri.Mensaje {
+	var result []sri.Mensaje
+	for code := range strings.SplitSeq(codes, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			result = append(result, srisim.Error(code))
+		}
+	}
+	return result
+}
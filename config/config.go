@@ -38,11 +38,17 @@ type Email struct {
 	User string `mapstructure:"user"`
 }
 
+// SRI permite apuntar los servicios web a otro servidor, como el simulador local (cmd/sri_simulator).
+type SRI struct {
+	BaseURL string `mapstructure:"base_url"`
+}
+
 type Config struct {
 	Database Database `mapstructure:"database"`
 	App      App      `mapstructure:"app"`
 	Storage  Storage  `mapstructure:"storage"`
 	Email    Email    `mapstructure:"email"`
+	SRI      SRI      `mapstructure:"sri"`
 }
 
 var (
@@ -124,3 +124,51 @@ func (g *CSVReportGenerator) DailyReport(ctx context.Context, report *domain.Dai
 
 	return nil
 }
+
+// VATDeclarationReport generates a CSV worksheet with the form 104 boxes of a month.
+func (g *CSVReportGenerator) VATDeclarationReport(ctx context.Context, declaration *domain.VATDeclaration, outputPath string, currentUser *domain.User) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Summary Header
+	summaryData := [][]string{
+		{"Declaración de IVA (Formulario 104)"},
+		{"Período", fmt.Sprintf("%02d/%d", int(declaration.Month), declaration.Year)},
+		{"RUC", declaration.IssuerRUC},
+		{"Razón Social", declaration.IssuerName},
+		{"Comprobantes de Venta", fmt.Sprintf("%d", declaration.SalesCount)},
+		{"Notas de Crédito", fmt.Sprintf("%d", declaration.CreditNoteCount)},
+		{"Comprobantes de Compra", fmt.Sprintf("%d", declaration.PurchaseCount)},
+		{"Retenciones Emitidas", fmt.Sprintf("%d", declaration.WithholdingCount)},
+	}
+	if err := writer.WriteAll(summaryData); err != nil {
+		return err
+	}
+
+	for _, section := range declaration.Sections() {
+		// Spacer
+		if err := writer.Write([]string{}); err != nil {
+			return err
+		}
+		if err := writer.Write([]string{section.Title, "Casillero", "Valor"}); err != nil {
+			return err
+		}
+		for _, box := range section.Boxes {
+			if err := writer.Write([]string{box.Label, box.Code, box.Value.StringFixed(2)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Add footer
+	_ = writer.Write([]string{}) // Spacer
+	_ = writer.Write([]string{"Reporte Generado Por:", fmt.Sprintf("%s %s", currentUser.FirstName, currentUser.LastName)})
+
+	return nil
+}
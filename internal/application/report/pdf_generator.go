@@ -305,3 +305,82 @@ func (g *PDFReportGenerator) buildTransactionsTable(m core.Maroto, transactions
 		m.AddRows(dataRow)
 	}
 }
+
+// VATDeclarationReport generates a PDF worksheet with the form 104 boxes of a month, to copy
+// into the SRI declaration.
+func (g *PDFReportGenerator) VATDeclarationReport(ctx context.Context, declaration *domain.VATDeclaration, outputPath string, currentUser *domain.User) error {
+	cfg := config.NewBuilder().
+		WithPageNumber().
+		WithLeftMargin(10).
+		WithTopMargin(15).
+		WithRightMargin(10).
+		WithBottomMargin(20).
+		Build()
+
+	m := maroto.New(cfg)
+
+	footerProps := props.Text{Top: 1, Size: 8, Style: fontstyle.Italic, Align: align.Left}
+	if err := m.RegisterFooter(
+		row.New(10).Add(
+			text.NewCol(12, "Valores referenciales calculados a partir de los comprobantes registrados; verifíquelos antes de declarar.", footerProps),
+		),
+		row.New(10).Add(
+			text.NewCol(12, fmt.Sprintf("Reporte Generado Por: %s %s", currentUser.FirstName, currentUser.LastName), footerProps),
+		),
+	); err != nil {
+		return err
+	}
+
+	g.buildTitle(m, "Declaración de IVA (Formulario 104)")
+	g.buildVATDeclarationHeader(m, declaration)
+
+	for _, section := range declaration.Sections() {
+		m.AddRow(5) // Add some space
+		g.buildVATSection(m, section)
+	}
+
+	document, err := m.Generate()
+	if err != nil {
+		return fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return document.Save(outputPath)
+}
+
+func (g *PDFReportGenerator) buildVATDeclarationHeader(m core.Maroto, declaration *domain.VATDeclaration) {
+	labelStyle := props.Text{Style: fontstyle.Bold, Align: align.Right, Top: 1}
+	valueStyle := props.Text{Align: align.Left, Top: 1}
+
+	rows := [][2]string{
+		{"Período: ", fmt.Sprintf("%02d/%d", int(declaration.Month), declaration.Year)},
+		{"RUC: ", declaration.IssuerRUC},
+		{"Razón Social: ", declaration.IssuerName},
+		{"Comprobantes: ", fmt.Sprintf("%d ventas, %d notas de crédito, %d compras, %d retenciones",
+			declaration.SalesCount, declaration.CreditNoteCount, declaration.PurchaseCount, declaration.WithholdingCount)},
+	}
+	for _, r := range rows {
+		m.AddRow(8,
+			col.New(4).Add(text.New(r[0], labelStyle)),
+			col.New(8).Add(text.New(r[1], valueStyle)),
+		)
+	}
+}
+
+func (g *PDFReportGenerator) buildVATSection(m core.Maroto, section domain.VATSection) {
+	headerStyle := &props.Cell{BackgroundColor: &props.Color{Red: 220, Green: 230, Blue: 240}}
+	headerText := props.Text{Style: fontstyle.Bold, Align: align.Left, Top: 1.5, Left: 1}
+	codeStyle := props.Text{Style: fontstyle.Bold, Align: align.Center, Top: 1}
+	labelStyle := props.Text{Align: align.Left, Top: 1, Size: 9}
+	valueStyle := props.Text{Align: align.Right, Top: 1, Right: 1}
+
+	m.AddRow(8,
+		col.New(12).Add(text.New(section.Title, headerText)).WithStyle(headerStyle),
+	)
+	for _, box := range section.Boxes {
+		m.AddRow(7,
+			col.New(1).Add(text.New(box.Code, codeStyle)),
+			col.New(8).Add(text.New(box.Label, labelStyle)),
+			col.New(3).Add(text.New(fmt.Sprintf("$%s", box.Value.StringFixed(2)), valueStyle)),
+		)
+	}
+}
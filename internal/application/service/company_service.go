@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+// CompanyService administra las empresas de la instalación y la empresa con la que trabaja la sesión.
+type CompanyService struct {
+	repo  CompanyRepository
+	scope *domain.CompanyScope
+}
+
+func NewCompanyService(repo CompanyRepository, scope *domain.CompanyScope) *CompanyService {
+	return &CompanyService{repo: repo, scope: scope}
+}
+
+// GetCompanies devuelve todas las empresas registradas.
+func (s *CompanyService) GetCompanies(ctx context.Context) ([]domain.Company, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// GetCurrentCompany devuelve la empresa seleccionada en la sesión.
+func (s *CompanyService) GetCurrentCompany(ctx context.Context) (*domain.Company, error) {
+	id, err := s.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+	company, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if company == nil {
+		return nil, fmt.Errorf("la empresa %d no existe", id)
+	}
+	return company, nil
+}
+
+// CreateCompany registra una empresa nueva con sus libros vacíos. Solo un administrador puede hacerlo.
+func (s *CompanyService) CreateCompany(ctx context.Context, company *domain.Company, currentUser *domain.User) error {
+	if currentUser == nil || !currentUser.CanConfigureSystem() {
+		return fmt.Errorf("no tiene permisos para crear empresas")
+	}
+	company.Name = strings.TrimSpace(company.Name)
+	if err := company.Validate(); err != nil {
+		return err
+	}
+	company.IsActive = true
+	return s.repo.Create(ctx, company)
+}
+
+// SelectCompany cambia la empresa de la sesión; desde ese momento los repositorios solo ven sus datos.
+func (s *CompanyService) SelectCompany(ctx context.Context, id int) (*domain.Company, error) {
+	company, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if company == nil {
+		return nil, fmt.Errorf("la empresa %d no existe", id)
+	}
+	if !company.IsActive {
+		return nil, fmt.Errorf("la empresa %s está inactiva", company.Name)
+	}
+	s.scope.Select(company.ID)
+	return company, nil
+}
+
+// ClearCompany deja la sesión sin empresa, por ejemplo al cerrar sesión.
+func (s *CompanyService) ClearCompany() {
+	s.scope.Select(0)
+}
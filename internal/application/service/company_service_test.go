@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanyService_SelectCompany(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Cambia la empresa de la sesión", func(t *testing.T) {
+		mockRepo := new(mocks.MockCompanyRepository)
+		scope := domain.NewCompanyScope(0)
+		svc := service.NewCompanyService(mockRepo, scope)
+
+		mockRepo.On("GetByID", ctx, 2).Return(&domain.Company{BaseEntity: domain.BaseEntity{ID: 2}, Name: "Comercial Andina", IsActive: true}, nil).Once()
+
+		company, err := svc.SelectCompany(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "Comercial Andina", company.Name)
+
+		id, err := scope.ID()
+		require.NoError(t, err)
+		assert.Equal(t, 2, id)
+	})
+
+	t.Run("Rechaza una empresa inactiva sin cambiar la sesión", func(t *testing.T) {
+		mockRepo := new(mocks.MockCompanyRepository)
+		scope := domain.NewCompanyScope(1)
+		svc := service.NewCompanyService(mockRepo, scope)
+
+		mockRepo.On("GetByID", ctx, 3).Return(&domain.Company{BaseEntity: domain.BaseEntity{ID: 3}, Name: "Cerrada", IsActive: false}, nil).Once()
+
+		_, err := svc.SelectCompany(ctx, 3)
+		require.Error(t, err)
+
+		id, _ := scope.ID()
+		assert.Equal(t, 1, id)
+	})
+
+	t.Run("Al limpiar la sesión los datos quedan inaccesibles", func(t *testing.T) {
+		scope := domain.NewCompanyScope(1)
+		svc := service.NewCompanyService(new(mocks.MockCompanyRepository), scope)
+
+		svc.ClearCompany()
+
+		_, err := scope.ID()
+		assert.ErrorIs(t, err, domain.ErrNoCompanySelected)
+	})
+}
+
+func TestCompanyService_CreateCompany(t *testing.T) {
+	ctx := context.Background()
+	admin := &domain.User{Role: domain.RoleAdmin}
+
+	t.Run("Crea la empresa activa", func(t *testing.T) {
+		mockRepo := new(mocks.MockCompanyRepository)
+		svc := service.NewCompanyService(mockRepo, domain.NewCompanyScope(1))
+
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(c *domain.Company) bool {
+			return c.Name == "Comercial Andina" && c.IsActive
+		})).Return(nil).Once()
+
+		err := svc.CreateCompany(ctx, &domain.Company{Name: "  Comercial Andina "}, admin)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Solo un administrador puede crear empresas", func(t *testing.T) {
+		mockRepo := new(mocks.MockCompanyRepository)
+		svc := service.NewCompanyService(mockRepo, domain.NewCompanyScope(1))
+
+		err := svc.CreateCompany(ctx, &domain.Company{Name: "Comercial Andina"}, &domain.User{Role: domain.RoleCashier})
+		require.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Exige el nombre", func(t *testing.T) {
+		mockRepo := new(mocks.MockCompanyRepository)
+		svc := service.NewCompanyService(mockRepo, domain.NewCompanyScope(1))
+
+		err := svc.CreateCompany(ctx, &domain.Company{Name: "  "}, admin)
+		require.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
@@ -67,10 +67,15 @@ type TransactionRepository interface {
 	GetBalanceAsOf(ctx context.Context, accountID int, date time.Time) (decimal.Decimal, error)
 	GetTransactionByID(ctx context.Context, id int) (*domain.Transaction, error)
 	GetItemsByTransactionID(ctx context.Context, transactionID int) ([]domain.TransactionItem, error)
+	GetPaymentsByTransactionID(ctx context.Context, transactionID int) ([]domain.TransactionPayment, error)
+	GetAdditionalFieldsByTransactionID(ctx context.Context, transactionID int) ([]domain.AdditionalField, error)
 	VoidTransaction(ctx context.Context, transactionID int, currentUser domain.User) (int, error)
 	RevertVoidTransaction(ctx context.Context, voidTransactionID int) error
+	PartialVoidTransaction(ctx context.Context, originalID int, reversal *domain.Transaction) error
+	GetCreditedAmount(ctx context.Context, transactionID int) (float64, error)
 	UpdateTransaction(ctx context.Context, tx *domain.Transaction) error
 	UpdateAttachmentPath(ctx context.Context, transactionID int, attachmentPath string) error
+	SupplierAccessKeyExists(ctx context.Context, accessKey string) (bool, error)
 }
 
 type StorageService interface {
@@ -84,6 +89,17 @@ type ReportRepository interface {
 	GetReconciliation(ctx context.Context, accountID int, startDate, endDate time.Time) (*domain.Reconciliation, error)
 }
 
+// TaxReportRepository obtiene los comprobantes de un período para los anexos y declaraciones del SRI.
+type TaxReportRepository interface {
+	GetSaleDocuments(ctx context.Context, startDate, endDate time.Time) ([]domain.TaxSaleDocument, error)
+	GetPurchaseDocuments(ctx context.Context, startDate, endDate time.Time) ([]domain.TaxPurchaseDocument, error)
+}
+
+// TaxRateRepository lee el catálogo de tarifas de IVA con sus vigencias.
+type TaxRateRepository interface {
+	GetAll(ctx context.Context) (domain.TaxRateTable, error)
+}
+
 type RecurringTransactionRepository interface {
 	Create(ctx context.Context, rt *domain.RecurringTransaction) error
 	GetAll(ctx context.Context) ([]domain.RecurringTransaction, error)
@@ -113,15 +129,31 @@ type EmissionPointRepository interface {
 	IncrementSequence(ctx context.Context, id int) error
 	Create(ctx context.Context, ep *domain.EmissionPoint) error
 	Update(ctx context.Context, ep *domain.EmissionPoint) error
+	GetEstablishments(ctx context.Context, issuerID int) ([]domain.Establishment, error)
+	GetEstablishment(ctx context.Context, issuerID int, code string) (*domain.Establishment, error)
+	SaveEstablishment(ctx context.Context, e *domain.Establishment) error
 }
 
 type ElectronicReceiptRepository interface {
 	Create(ctx context.Context, er *domain.ElectronicReceipt) error
 	Update(ctx context.Context, er *domain.ElectronicReceipt) error
-	UpdateStatus(ctx context.Context, accessKey string, status string, message string, authDate *time.Time) error
-	UpdateXML(ctx context.Context, accessKey string, xmlContent string) error
-	UpdateTaxPayerID(ctx context.Context, accessKey string, taxPayerID int) error
+	UpdateStatus(ctx context.Context, accessKey string, status domain.ReceiptStatus, message string, authDate *time.Time) error
 	UpdateEmailSent(ctx context.Context, accessKey string, sent bool) error
 	GetByAccessKey(ctx context.Context, accessKey string) (*domain.ElectronicReceipt, error)
 	FindPendingReceipts(ctx context.Context) ([]domain.ElectronicReceipt, error)
+	FindAuthorized(ctx context.Context, from, to time.Time, receiptTypes []string) ([]domain.ElectronicReceipt, error)
+	GetEvents(ctx context.Context, receiptID int) ([]domain.ReceiptEvent, error)
+}
+
+type ShipmentRepository interface {
+	Create(ctx context.Context, s *domain.Shipment) error
+	GetByID(ctx context.Context, id int) (*domain.Shipment, error)
+	GetAll(ctx context.Context) ([]domain.Shipment, error)
+}
+
+type CompanyRepository interface {
+	GetAll(ctx context.Context) ([]domain.Company, error)
+	GetByID(ctx context.Context, id int) (*domain.Company, error)
+	Create(ctx context.Context, company *domain.Company) error
+	Update(ctx context.Context, company *domain.Company) error
 }
@@ -3,18 +3,44 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
 	"github.com/zalando/go-keyring"
 )
 
 type IssuerService struct {
-	repo   IssuerRepository
-	epRepo EmissionPointRepository
+	repo        IssuerRepository
+	epRepo      EmissionPointRepository
+	taxRateRepo TaxRateRepository
+
+	inspectCertificate func(p12Path, password string) (*sri.CertificateInfo, error)
+}
+
+func NewIssuerService(repo IssuerRepository, epRepo EmissionPointRepository, taxRateRepo TaxRateRepository) *IssuerService {
+	return &IssuerService{repo: repo, epRepo: epRepo, taxRateRepo: taxRateRepo, inspectCertificate: sri.InspectCertificate}
 }
 
-func NewIssuerService(repo IssuerRepository, epRepo EmissionPointRepository) *IssuerService {
-	return &IssuerService{repo: repo, epRepo: epRepo}
+// GetTaxRates devuelve el catálogo de tarifas de IVA con sus vigencias.
+func (s *IssuerService) GetTaxRates(ctx context.Context) (domain.TaxRateTable, error) {
+	return loadTaxRates(ctx, s.taxRateRepo)
+}
+
+// loadTaxRates lee el catálogo de tarifas de IVA, que siembran las migraciones en la tabla tax_rates.
+func loadTaxRates(ctx context.Context, repo TaxRateRepository) (domain.TaxRateTable, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("no hay un repositorio de tarifas de IVA configurado")
+	}
+	rates, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las tarifas de IVA: %w", err)
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("el catálogo de tarifas de IVA está vacío")
+	}
+	return rates, nil
 }
 
 func (s *IssuerService) GetActive(ctx context.Context) (*domain.Issuer, error) {
@@ -33,8 +59,144 @@ func (s *IssuerService) UpdateEmissionPoint(ctx context.Context, ep *domain.Emis
 	return s.epRepo.Update(ctx, ep)
 }
 
+// GetInvoicePoints devuelve los puntos de emisión activos para facturar, uno por establecimiento y
+// punto, para elegirlos en las ventas y como punto por defecto de los usuarios.
+func (s *IssuerService) GetInvoicePoints(ctx context.Context) ([]domain.EmissionPoint, error) {
+	points, err := s.GetEmissionPoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var result []domain.EmissionPoint
+	for _, p := range points {
+		if p.ReceiptType == "01" && p.IsActive {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return domain.EmissionPointLabel(result[i].EstablishmentCode, result[i].EmissionPointCode) <
+			domain.EmissionPointLabel(result[j].EstablishmentCode, result[j].EmissionPointCode)
+	})
+	return result, nil
+}
+
+// GetEstablishments devuelve el establecimiento principal, tomado de los datos del emisor, seguido de
+// los establecimientos adicionales.
+func (s *IssuerService) GetEstablishments(ctx context.Context) ([]domain.Establishment, error) {
+	issuer, err := s.repo.GetActive(ctx)
+	if err != nil || issuer == nil {
+		return nil, fmt.Errorf("no hay emisor activo")
+	}
+	main := domain.Establishment{
+		IssuerID: issuer.ID,
+		Code:     issuer.EstablishmentCode,
+		Name:     "Matriz",
+		Address:  issuer.EstablishmentAddress,
+		IsActive: true,
+	}
+	others, err := s.epRepo.GetEstablishments(ctx, issuer.ID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]domain.Establishment{main}, others...), nil
+}
+
+// SaveEstablishment registra o actualiza un establecimiento adicional. El principal se edita en los
+// datos del emisor.
+func (s *IssuerService) SaveEstablishment(ctx context.Context, e *domain.Establishment) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	issuer, err := s.repo.GetActive(ctx)
+	if err != nil || issuer == nil {
+		return fmt.Errorf("no hay emisor activo")
+	}
+	if e.Code == issuer.EstablishmentCode {
+		return fmt.Errorf("el establecimiento %s es el principal; modifique su dirección en los datos del emisor", e.Code)
+	}
+	if e.ID == 0 {
+		existing, err := s.epRepo.GetEstablishment(ctx, issuer.ID, e.Code)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("el establecimiento %s ya está registrado", e.Code)
+		}
+	}
+	e.IssuerID = issuer.ID
+	return s.epRepo.SaveEstablishment(ctx, e)
+}
+
+// AddEmissionPoint habilita un punto de emisión (ej. una caja) en un establecimiento registrado, con
+// sus secuenciales para todos los tipos de comprobante.
+func (s *IssuerService) AddEmissionPoint(ctx context.Context, establishmentCode, emissionPointCode string) error {
+	if err := domain.ValidateEmissionCode("punto de emisión", emissionPointCode); err != nil {
+		return err
+	}
+	issuer, err := s.repo.GetActive(ctx)
+	if err != nil || issuer == nil {
+		return fmt.Errorf("no hay emisor activo")
+	}
+	if establishmentCode != issuer.EstablishmentCode {
+		estab, err := s.epRepo.GetEstablishment(ctx, issuer.ID, establishmentCode)
+		if err != nil {
+			return err
+		}
+		if estab == nil || !estab.IsActive {
+			return fmt.Errorf("el establecimiento %s no está registrado o está inactivo", establishmentCode)
+		}
+	}
+	existing, err := s.epRepo.GetByPoint(ctx, issuer.ID, establishmentCode, emissionPointCode, "01")
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("el punto de emisión %s ya existe", domain.EmissionPointLabel(establishmentCode, emissionPointCode))
+	}
+	return s.ensureEmissionPoints(ctx, issuer.ID, establishmentCode, emissionPointCode)
+}
+
+// emissionReceiptTypes son los comprobantes que se pueden emitir desde cada punto: factura,
+// liquidación, nota de crédito, nota de débito, guía de remisión y retención.
+var emissionReceiptTypes = []string{"01", "03", "04", "05", "06", "07"}
+
+// ensureEmissionPoints crea los secuenciales que falten del punto de emisión.
+func (s *IssuerService) ensureEmissionPoints(ctx context.Context, issuerID int, establishmentCode, emissionPointCode string) error {
+	for _, rt := range emissionReceiptTypes {
+		ep, err := s.epRepo.GetByPoint(ctx, issuerID, establishmentCode, emissionPointCode, rt)
+		if err != nil {
+			return err
+		}
+		if ep != nil {
+			continue
+		}
+		newEP := &domain.EmissionPoint{
+			IssuerID:          issuerID,
+			EstablishmentCode: establishmentCode,
+			EmissionPointCode: emissionPointCode,
+			ReceiptType:       rt,
+			CurrentSequence:   0,
+			IsActive:          true,
+		}
+		if err := s.epRepo.Create(ctx, newEP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SaveIssuerConfig guarda la configuración del emisor en la DB y la contraseña en el Keyring.
 func (s *IssuerService) SaveIssuerConfig(ctx context.Context, issuer *domain.Issuer, password string) error {
+	if err := validateIssuerAdditionalFields(issuer.AdditionalFields); err != nil {
+		return err
+	}
+
+	// 0. El certificado de firma debe ser del emisor y estar vigente
+	if issuer.SignaturePath != "" {
+		if _, err := s.checkCertificate(issuer, password); err != nil {
+			return err
+		}
+	}
+
 	// 1. Guardar/Actualizar en DB
 	existing, err := s.repo.GetActive(ctx)
 	if err != nil {
@@ -56,21 +218,7 @@ func (s *IssuerService) SaveIssuerConfig(ctx context.Context, issuer *domain.Iss
 
 	// 1.5 Pre-inicializar Puntos de Emisión si no existen
 	// Esto permite que el usuario pueda migrar secuenciales inmediatamente después de guardar
-	receiptTypes := []string{"01", "04"} // Factura y Nota de Crédito
-	for _, rt := range receiptTypes {
-		ep, err := s.epRepo.GetByPoint(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode, rt)
-		if err == nil && ep == nil {
-			newEP := &domain.EmissionPoint{
-				IssuerID:          issuer.ID,
-				EstablishmentCode: issuer.EstablishmentCode,
-				EmissionPointCode: issuer.EmissionPointCode,
-				ReceiptType:       rt,
-				CurrentSequence:   0,
-				IsActive:          true,
-			}
-			_ = s.epRepo.Create(ctx, newEP)
-		}
-	}
+	_ = s.ensureEmissionPoints(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode)
 
 	// 2. Guardar contraseña en Keyring del SO de forma segura
 	// Service: "Verith", User: RUC
@@ -85,6 +233,55 @@ func (s *IssuerService) SaveIssuerConfig(ctx context.Context, issuer *domain.Iss
 	return nil
 }
 
+// checkCertificate abre el .p12 del emisor con la contraseña ingresada o, si no se ingresó una
+// nueva, con la guardada en el llavero, y verifica que sea del RUC del emisor y esté vigente.
+func (s *IssuerService) checkCertificate(issuer *domain.Issuer, password string) (*sri.CertificateInfo, error) {
+	if password == "" {
+		stored, err := s.GetSignaturePassword(issuer.RUC)
+		if err != nil {
+			return nil, fmt.Errorf("ingrese la contraseña del certificado de firma electrónica")
+		}
+		password = stored
+	}
+	info, err := s.inspectCertificate(issuer.SignaturePath, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := info.Validate(issuer.RUC, time.Now()); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// GetCertificateInfo lee el certificado de firma del emisor activo con la contraseña guardada.
+// Devuelve nil si todavía no se configuró la firma.
+func (s *IssuerService) GetCertificateInfo(ctx context.Context) (*sri.CertificateInfo, error) {
+	issuer, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if issuer == nil || issuer.SignaturePath == "" {
+		return nil, nil
+	}
+	password, err := s.GetSignaturePassword(issuer.RUC)
+	if err != nil {
+		return nil, fmt.Errorf("no se encontró la contraseña del certificado de firma electrónica: %w", err)
+	}
+	return s.inspectCertificate(issuer.SignaturePath, password)
+}
+
+// validateIssuerAdditionalFields limita los campos por defecto para que, sumados a los del cliente,
+// no superen el máximo que admite el SRI en cualquier comprobante.
+func validateIssuerAdditionalFields(fields []domain.AdditionalField) error {
+	if err := domain.ValidateAdditionalFields(fields); err != nil {
+		return err
+	}
+	if len(fields) > domain.MaxIssuerAdditionalFields {
+		return fmt.Errorf("se admiten máximo %d campos adicionales por defecto", domain.MaxIssuerAdditionalFields)
+	}
+	return nil
+}
+
 func (s *IssuerService) GetIssuerConfig(ctx context.Context) (*domain.Issuer, error) {
 	return s.repo.GetActive(ctx)
 }
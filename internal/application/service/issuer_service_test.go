@@ -3,9 +3,11 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/nelsonmarro/verith/internal/application/service/mocks"
 	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/zalando/go-keyring"
@@ -17,7 +19,7 @@ func TestSaveIssuerConfig_Integration(t *testing.T) {
 
 	mockRepo := new(mocks.MockIssuerRepository)
 	mockEpRepo := new(mocks.MockEmissionPointRepository)
-	service := NewIssuerService(mockRepo, mockEpRepo)
+	service := NewIssuerService(mockRepo, mockEpRepo, nil)
 	ctx := context.Background()
 
 	t.Run("Create New Issuer Config", func(t *testing.T) {
@@ -33,11 +35,19 @@ func TestSaveIssuerConfig_Integration(t *testing.T) {
 		mockRepo.On("GetActive", ctx).Return(nil, nil).Once() // No existe
 		mockRepo.On("Create", ctx, newIssuer).Return(nil).Once()
 		
-		// New logic calls GetByPoint and Create for default points (01, 04)
+		// New logic calls GetByPoint and Create for default points (01, 04, 07)
 		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "01").Return(nil, nil).Once()
 		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "03").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
 		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "04").Return(nil, nil).Once()
 		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "05").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "06").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "07").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
 
 		// Act
 		err := service.SaveIssuerConfig(ctx, newIssuer, password)
@@ -75,8 +85,16 @@ func TestSaveIssuerConfig_Integration(t *testing.T) {
 		// New logic expectations
 		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "01").Return(nil, nil).Once()
 		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "03").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
 		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "04").Return(nil, nil).Once()
 		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "05").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "06").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		mockEpRepo.On("GetByPoint", ctx, mock.Anything, mock.Anything, mock.Anything, "07").Return(nil, nil).Once()
+		mockEpRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
 
 		// Act
 		err := service.SaveIssuerConfig(ctx, updatedIssuer, newPassword)
@@ -90,4 +108,92 @@ func TestSaveIssuerConfig_Integration(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, newPassword, storedPass)
 	})
+
+	t.Run("Rejects too many default additional fields", func(t *testing.T) {
+		// Arrange: sumados a los 3 del cliente superarían el máximo del SRI
+		issuer := &domain.Issuer{RUC: "1790012345001"}
+		for i := 0; i <= domain.MaxIssuerAdditionalFields; i++ {
+			issuer.AdditionalFields = append(issuer.AdditionalFields, domain.AdditionalField{Name: "Campo", Value: "x"})
+		}
+
+		// Act
+		err := service.SaveIssuerConfig(ctx, issuer, "")
+
+		// Assert
+		assert.ErrorContains(t, err, "máximo 12 campos adicionales")
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, issuer)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, issuer)
+	})
+
+	t.Run("Rejects a certificate that belongs to another RUC", func(t *testing.T) {
+		issuer := &domain.Issuer{RUC: "1790012345001", SignaturePath: "/firmas/otra.p12"}
+		service.inspectCertificate = func(path, password string) (*sri.CertificateInfo, error) {
+			assert.Equal(t, "/firmas/otra.p12", path)
+			assert.Equal(t, "Clave", password)
+			return &sri.CertificateInfo{Subject: "OTRA EMPRESA", Identification: "0990012345001", NotBefore: time.Now().AddDate(-1, 0, 0), NotAfter: time.Now().AddDate(1, 0, 0)}, nil
+		}
+
+		err := service.SaveIssuerConfig(ctx, issuer, "Clave")
+
+		assert.ErrorIs(t, err, sri.ErrCertificateRUC)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, issuer)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, issuer)
+	})
+
+	t.Run("Rejects an expired certificate opened with the stored password", func(t *testing.T) {
+		// La contraseña guardada en el primer caso se usa cuando no se ingresa una nueva
+		issuer := &domain.Issuer{RUC: "1790012345001", SignaturePath: "/firmas/vieja.p12"}
+		service.inspectCertificate = func(path, password string) (*sri.CertificateInfo, error) {
+			assert.Equal(t, "NewPass456", password)
+			return &sri.CertificateInfo{Identification: "1790012345001", NotBefore: time.Now().AddDate(-2, 0, 0), NotAfter: time.Now().AddDate(0, 0, -1)}, nil
+		}
+
+		err := service.SaveIssuerConfig(ctx, issuer, "")
+
+		assert.ErrorIs(t, err, sri.ErrCertificateExpired)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, issuer)
+	})
+
+	t.Run("Reads the certificate of the active issuer", func(t *testing.T) {
+		expiring := &sri.CertificateInfo{Identification: "1790012345001", NotAfter: time.Now().AddDate(0, 0, 5)}
+		service.inspectCertificate = func(path, password string) (*sri.CertificateInfo, error) {
+			assert.Equal(t, "/firmas/actual.p12", path)
+			assert.Equal(t, "NewPass456", password)
+			return expiring, nil
+		}
+		mockRepo.On("GetActive", ctx).Return(&domain.Issuer{RUC: "1790012345001", SignaturePath: "/firmas/actual.p12"}, nil).Once()
+
+		info, err := service.GetCertificateInfo(ctx)
+
+		assert.NoError(t, err)
+		assert.Same(t, expiring, info)
+		assert.True(t, info.ExpiresSoon(time.Now()))
+	})
+}
+
+func TestGetTaxRates(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Sin repositorio de tarifas", func(t *testing.T) {
+		_, err := NewIssuerService(nil, nil, nil).GetTaxRates(ctx)
+		assert.ErrorContains(t, err, "repositorio de tarifas")
+	})
+
+	t.Run("Catálogo vacío", func(t *testing.T) {
+		mockTaxRateRepo := new(mocks.MockTaxRateRepository)
+		mockTaxRateRepo.On("GetAll", ctx).Return(domain.TaxRateTable{}, nil).Once()
+
+		_, err := NewIssuerService(nil, nil, mockTaxRateRepo).GetTaxRates(ctx)
+		assert.ErrorContains(t, err, "está vacío")
+	})
+
+	t.Run("Devuelve el catálogo de la base", func(t *testing.T) {
+		rates := domain.TaxRateTable{{Code: 4, Percentage: 15, General: true, ValidFrom: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}}
+		mockTaxRateRepo := new(mocks.MockTaxRateRepository)
+		mockTaxRateRepo.On("GetAll", ctx).Return(rates, nil).Once()
+
+		got, err := NewIssuerService(nil, nil, mockTaxRateRepo).GetTaxRates(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, rates, got)
+	})
 }
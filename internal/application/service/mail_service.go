@@ -62,6 +62,25 @@ func (s *MailServiceImpl) SendReceipt(issuer *domain.Issuer, recipientEmail stri
 		}
 	}
 
+	if receipt.ReceiptType == "03" {
+		subject = fmt.Sprintf("Liquidación de Compra Electrónica - %s", issuer.TradeName)
+		data["ClientName"] = "Proveedor"
+	}
+
+	if receipt.ReceiptType == "05" {
+		subject = fmt.Sprintf("Nota de Débito Electrónica - %s", issuer.TradeName)
+	}
+
+	if receipt.ReceiptType == "06" {
+		subject = fmt.Sprintf("Guía de Remisión Electrónica - %s", issuer.TradeName)
+	}
+
+	if receipt.ReceiptType == "07" {
+		// La retención usa la plantilla general; solo cambia el asunto
+		subject = fmt.Sprintf("Comprobante de Retención Electrónico - %s", issuer.TradeName)
+		data["ClientName"] = "Proveedor"
+	}
+
 	// 2. Render Template
 	var body bytes.Buffer
 	t, err := template.ParseFiles(templatePath)
@@ -27,8 +27,9 @@ func TestSriService_MigrationSequences(t *testing.T) {
 	logger := log.New(os.Stdout, "[MIGRATION-STRESS] ", log.LstdFlags)
 
 	svc := service.NewSriService(
-		mockTxRepo, mockIssuerRepo, mockReceiptRepo, mockTaxPayerRepo, mockEmissionRepo, mockSriClient, mockMail, logger,
+		mockTxRepo, mockIssuerRepo, mockReceiptRepo, mockTaxPayerRepo, mockEmissionRepo, nil, newTestTaxRateRepo(), mockSriClient, mockMail, logger,
 	)
+	svc.AuthorizationDelay = 0
 	
 	mockSigner := new(MockDocumentSigner)
 	svc.SetSignerFactory(func(path, password string) service.DocumentSigner {
@@ -44,15 +45,17 @@ func TestSriService_MigrationSequences(t *testing.T) {
 	issuer := &domain.Issuer{
 		BaseEntity: domain.BaseEntity{ID: 1},
 		RUC: "1790012345001", EstablishmentCode: "001", EmissionPointCode: "001", Environment: 1,
+		BusinessName: "Empresa de Prueba S.A.", MainAddress: "Av. Amazonas y Colón",
 	}
-	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 5}, Identification: "1712345678", IdentificationType: "05", Email: "c@t.com"}
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 5}, Identification: "1712345678", IdentificationType: "05", Name: "Cliente Migrado", Email: "c@t.com"}
 	
 	t.Run("Scenario: Migration of Invoice from 1500", func(t *testing.T) {
 		txID := 200
 		tx := &domain.Transaction{
 			BaseEntity: domain.BaseEntity{ID: txID},
-			Amount: 100, TransactionDate: time.Now(), TaxPayerID: &client.ID,
-			Category: &domain.Category{Type: domain.Income},
+			Amount: 100, Subtotal0: 100, TransactionDate: time.Now(), TaxPayerID: &client.ID,
+			Description: "Venta migrada",
+			Category:    &domain.Category{Type: domain.Income},
 		}
 
 		// Simulamos que en la DB el secuencial es 1500 (migrado)
@@ -64,6 +67,8 @@ func TestSriService_MigrationSequences(t *testing.T) {
 		// EXPECTATIONS
 		mockTxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
 		mockTxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return([]domain.TransactionItem{}, nil).Once()
+		mockTxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{}, nil).Once()
+		mockTxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
 		mockIssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
 		mockTaxPayerRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
 		
@@ -85,8 +90,8 @@ func TestSriService_MigrationSequences(t *testing.T) {
 		})).Return(nil).Once()
 
 		// Mock SRI (para que termine el flujo)
-		mockSriClient.On("EnviarComprobante", mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
-		mockSriClient.On("AutorizarComprobante", mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+		mockSriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 
 		// ACT
@@ -105,6 +110,8 @@ func TestSriService_MigrationSequences(t *testing.T) {
 		originalTx := &domain.Transaction{
 			BaseEntity: domain.BaseEntity{ID: originalTxID},
 			TaxPayerID: &client.ID,
+			TransactionDate: time.Now().Add(-24 * time.Hour),
+			Amount: 100, Subtotal0: 100,
 			ElectronicReceipt: &domain.ElectronicReceipt{SRIStatus: "AUTORIZADO", AccessKey: "1234567890123456789012345678901234567890123456789"},
 		}
 		voidTx := &domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}
@@ -114,7 +121,7 @@ func TestSriService_MigrationSequences(t *testing.T) {
 
 		mockTxRepo.On("GetTransactionByID", mock.Anything, voidTxID).Return(voidTx, nil).Once()
 		mockTxRepo.On("GetTransactionByID", mock.Anything, originalTxID).Return(originalTx, nil).Once()
-		mockTxRepo.On("GetItemsByTransactionID", mock.Anything, originalTxID).Return([]domain.TransactionItem{}, nil).Once()
+		mockTxRepo.On("GetItemsByTransactionID", mock.Anything, originalTxID).Return([]domain.TransactionItem{{Description: "Venta migrada", Quantity: 1, UnitPrice: 100, Subtotal: 100}}, nil).Once()
 		mockIssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
 		mockTaxPayerRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
 
@@ -131,8 +138,8 @@ func TestSriService_MigrationSequences(t *testing.T) {
 			return r.ReceiptType == "04" && seq == "000000051"
 		})).Return(nil).Once()
 
-		mockSriClient.On("EnviarComprobante", mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
-		mockSriClient.On("AutorizarComprobante", mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+		mockSriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 
 		// ACT
@@ -30,12 +30,14 @@ func TestMigration_SequenceContinuity_Integration(t *testing.T) {
 	
 	// 1. Repositorios Reales
 	db := persistence.GetTestPool(t) // Helper que asumo existe o similar
-	txRepo := persistence.NewTransactionRepository(db)
-	issuerRepo := persistence.NewIssuerRepository(db)
-	receiptRepo := persistence.NewElectronicReceiptRepository(db)
-	clientRepo := persistence.NewTaxPayerRepository(db)
+	scope := domain.NewCompanyScope(1)
+	txRepo := persistence.NewTransactionRepository(db, scope)
+	issuerRepo := persistence.NewIssuerRepository(db, scope)
+	receiptRepo := persistence.NewElectronicReceiptRepository(db, scope)
+	clientRepo := persistence.NewTaxPayerRepository(db, scope)
 	epRepo := persistence.NewEmissionPointRepository(db)
-	categoryRepo := persistence.NewCategoryRepository(db)
+	categoryRepo := persistence.NewCategoryRepository(db, scope)
+	taxRateRepo := persistence.NewTaxRateRepository(db)
 
 	// 2. Mocks de Infraestructura
 	mockSriClient := new(mocks.MockSRIClient)
@@ -43,7 +45,8 @@ func TestMigration_SequenceContinuity_Integration(t *testing.T) {
 	logger := log.New(os.Stdout, "[MIGRATION-TEST] ", log.LstdFlags)
 
 	// 3. Servicio Real
-	svc := service.NewSriService(txRepo, issuerRepo, receiptRepo, clientRepo, epRepo, mockSriClient, mockMail, logger)
+	svc := service.NewSriService(txRepo, issuerRepo, receiptRepo, clientRepo, epRepo, nil, taxRateRepo, mockSriClient, mockMail, logger)
+	svc.AuthorizationDelay = 0
 	
 	// Mock Signer para no necesitar archivo .p12 real
 	mockSigner := new(MockDocumentSigner)
@@ -76,7 +79,7 @@ func TestMigration_SequenceContinuity_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	// Crear Cuenta
-	accRepo := persistence.NewAccountRepository(db)
+	accRepo := persistence.NewAccountRepository(db, scope)
 	acc := &domain.Account{Name: "Caja Principal", InitialBalance: 1000}
 	err = accRepo.Create(ctx, acc)
 	require.NoError(t, err)
@@ -105,8 +108,8 @@ func TestMigration_SequenceContinuity_Integration(t *testing.T) {
 		require.NoError(t, err)
 
 		// Mock SRI Response
-		mockSriClient.On("EnviarComprobante", mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
-		mockSriClient.On("AutorizarComprobante", mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+		mockSriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
 
 		// EJECUTAR EMISIÓN
 		err = svc.EmitirFactura(ctx, tx.ID, "pass")
@@ -152,8 +155,8 @@ func TestMigration_SequenceContinuity_Integration(t *testing.T) {
 		txRepo.Create(ctx, voidTx)
 
 		// Mock SRI
-		mockSriClient.On("EnviarComprobante", mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
-		mockSriClient.On("AutorizarComprobante", mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+		mockSriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
 
 		// EJECUTAR ANULACIÓN (NC)
 		claveNC, err := svc.EmitirNotaCredito(ctx, voidTx.ID, originalTx.ID, "Error", "pass")
@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCompanyRepository struct {
+	mock.Mock
+}
+
+func (m *MockCompanyRepository) GetAll(ctx context.Context) ([]domain.Company, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepository) GetByID(ctx context.Context, id int) (*domain.Company, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepository) Create(ctx context.Context, company *domain.Company) error {
+	args := m.Called(ctx, company)
+	return args.Error(0)
+}
+
+func (m *MockCompanyRepository) Update(ctx context.Context, company *domain.Company) error {
+	args := m.Called(ctx, company)
+	return args.Error(0)
+}
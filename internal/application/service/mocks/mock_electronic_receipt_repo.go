@@ -22,18 +22,9 @@ func (m *MockElectronicReceiptRepository) Update(ctx context.Context, er *domain
 	return args.Error(0)
 }
 
-func (m *MockElectronicReceiptRepository) UpdateStatus(ctx context.Context, accessKey string, status string, message string, authDate *time.Time) error {
-	args := m.Called(ctx, accessKey, status, message, authDate)
-	return args.Error(0)
-}
-
-func (m *MockElectronicReceiptRepository) UpdateXML(ctx context.Context, accessKey string, xmlContent string) error {
-	args := m.Called(ctx, accessKey, xmlContent)
-	return args.Error(0)
-}
-
-func (m *MockElectronicReceiptRepository) UpdateTaxPayerID(ctx context.Context, accessKey string, taxPayerID int) error {
-	args := m.Called(ctx, accessKey, taxPayerID)
+// UpdateStatus registra el estado como string para que las expectativas usen los literales del SRI.
+func (m *MockElectronicReceiptRepository) UpdateStatus(ctx context.Context, accessKey string, status domain.ReceiptStatus, message string, authDate *time.Time) error {
+	args := m.Called(ctx, accessKey, string(status), message, authDate)
 	return args.Error(0)
 }
 
@@ -57,3 +48,19 @@ func (m *MockElectronicReceiptRepository) FindPendingReceipts(ctx context.Contex
 	}
 	return args.Get(0).([]domain.ElectronicReceipt), args.Error(1)
 }
+
+func (m *MockElectronicReceiptRepository) GetEvents(ctx context.Context, receiptID int) ([]domain.ReceiptEvent, error) {
+	args := m.Called(ctx, receiptID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ReceiptEvent), args.Error(1)
+}
+
+func (m *MockElectronicReceiptRepository) FindAuthorized(ctx context.Context, from, to time.Time, receiptTypes []string) ([]domain.ElectronicReceipt, error) {
+	args := m.Called(ctx, from, to, receiptTypes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ElectronicReceipt), args.Error(1)
+}
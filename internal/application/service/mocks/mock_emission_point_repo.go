@@ -41,3 +41,24 @@ func (m *MockEmissionPointRepository) Update(ctx context.Context, ep *domain.Emi
 	args := m.Called(ctx, ep)
 	return args.Error(0)
 }
+
+func (m *MockEmissionPointRepository) GetEstablishments(ctx context.Context, issuerID int) ([]domain.Establishment, error) {
+	args := m.Called(ctx, issuerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Establishment), args.Error(1)
+}
+
+func (m *MockEmissionPointRepository) GetEstablishment(ctx context.Context, issuerID int, code string) (*domain.Establishment, error) {
+	args := m.Called(ctx, issuerID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Establishment), args.Error(1)
+}
+
+func (m *MockEmissionPointRepository) SaveEstablishment(ctx context.Context, e *domain.Establishment) error {
+	args := m.Called(ctx, e)
+	return args.Error(0)
+}
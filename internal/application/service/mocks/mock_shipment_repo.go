@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockShipmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockShipmentRepository) Create(ctx context.Context, s *domain.Shipment) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockShipmentRepository) GetByID(ctx context.Context, id int) (*domain.Shipment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Shipment), args.Error(1)
+}
+
+func (m *MockShipmentRepository) GetAll(ctx context.Context) ([]domain.Shipment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Shipment), args.Error(1)
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/nelsonmarro/verith/internal/sri"
 	"github.com/stretchr/testify/mock"
 )
@@ -9,18 +11,26 @@ type MockSRIClient struct {
 	mock.Mock
 }
 
-func (m *MockSRIClient) EnviarComprobante(xmlFirmado []byte, environment int) (*sri.RespuestaRecepcion, error) {
-	args := m.Called(xmlFirmado, environment)
+func (m *MockSRIClient) EnviarComprobante(ctx context.Context, xmlFirmado []byte, environment int) (*sri.RespuestaRecepcion, error) {
+	args := m.Called(ctx, xmlFirmado, environment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*sri.RespuestaRecepcion), args.Error(1)
 }
 
-func (m *MockSRIClient) AutorizarComprobante(claveAcceso string, environment int) (*sri.RespuestaAutorizacion, error) {
-	args := m.Called(claveAcceso, environment)
+func (m *MockSRIClient) AutorizarComprobante(ctx context.Context, claveAcceso string, environment int) (*sri.RespuestaAutorizacion, error) {
+	args := m.Called(ctx, claveAcceso, environment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*sri.RespuestaAutorizacion), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *MockSRIClient) AutorizarLote(ctx context.Context, claveAccesoLote string, environment int) (*sri.RespuestaAutorizacionLote, error) {
+	args := m.Called(ctx, claveAccesoLote, environment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sri.RespuestaAutorizacionLote), args.Error(1)
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaxRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaxRateRepository) GetAll(ctx context.Context) (domain.TaxRateTable, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(domain.TaxRateTable), args.Error(1)
+}
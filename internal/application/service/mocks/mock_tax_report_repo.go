@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaxReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaxReportRepository) GetSaleDocuments(ctx context.Context, startDate, endDate time.Time) ([]domain.TaxSaleDocument, error) {
+	args := m.Called(ctx, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TaxSaleDocument), args.Error(1)
+}
+
+func (m *MockTaxReportRepository) GetPurchaseDocuments(ctx context.Context, startDate, endDate time.Time) ([]domain.TaxPurchaseDocument, error) {
+	args := m.Called(ctx, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TaxPurchaseDocument), args.Error(1)
+}
@@ -79,6 +79,22 @@ func (m *MockTransactionRepository) GetItemsByTransactionID(ctx context.Context,
 	return args.Get(0).([]domain.TransactionItem), args.Error(1)
 }
 
+func (m *MockTransactionRepository) GetPaymentsByTransactionID(ctx context.Context, transactionID int) ([]domain.TransactionPayment, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransactionPayment), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetAdditionalFieldsByTransactionID(ctx context.Context, transactionID int) ([]domain.AdditionalField, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AdditionalField), args.Error(1)
+}
+
 func (m *MockTransactionRepository) VoidTransaction(ctx context.Context, transactionID int, currentUser domain.User) (int, error) {
 	args := m.Called(ctx, transactionID, currentUser)
 	return args.Int(0), args.Error(1)
@@ -89,6 +105,16 @@ func (m *MockTransactionRepository) RevertVoidTransaction(ctx context.Context, v
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) PartialVoidTransaction(ctx context.Context, originalID int, reversal *domain.Transaction) error {
+	args := m.Called(ctx, originalID, reversal)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetCreditedAmount(ctx context.Context, transactionID int) (float64, error) {
+	args := m.Called(ctx, transactionID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 func (m *MockTransactionRepository) UpdateTransaction(ctx context.Context, tx *domain.Transaction) error {
 	args := m.Called(ctx, tx)
 	return args.Error(0)
@@ -97,4 +123,8 @@ func (m *MockTransactionRepository) UpdateTransaction(ctx context.Context, tx *d
 func (m *MockTransactionRepository) UpdateAttachmentPath(ctx context.Context, transactionID int, attachmentPath string) error {
 	args := m.Called(ctx, transactionID, attachmentPath)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+func (m *MockTransactionRepository) SupplierAccessKeyExists(ctx context.Context, accessKey string) (bool, error) {
+	args := m.Called(ctx, accessKey)
+	return args.Bool(0), args.Error(1)
+}
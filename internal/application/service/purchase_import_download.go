@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// DefaultDownloadInterval es la pausa entre consultas al SRI en la descarga masiva; el servicio de
+// autorización rechaza las ráfagas de peticiones desde la misma IP.
+const DefaultDownloadInterval = 700 * time.Millisecond
+
+// DownloadReceived procesa el TXT de comprobantes recibidos del portal del SRI: consulta cada factura
+// al servicio de autorización y la importa como egreso con ImportSupplierInvoice.
+//
+// La descarga se puede retomar: las facturas ya importadas se omiten sin consultar al SRI y cada XML
+// descargado se guarda en la carpeta de descargas, así que si se cancela (o se cae la conexión) al
+// volver a cargar el mismo TXT solo se consultan las que faltan. onProgress, si no es nil, recibe
+// cada resultado a medida que se procesa.
+func (s *PurchaseImportService) DownloadReceived(
+	ctx context.Context,
+	reportPath string,
+	accountID, categoryID int,
+	currentUser domain.User,
+	onProgress func(done, total int, result domain.ReceivedDocumentResult),
+) ([]domain.ReceivedDocumentResult, error) {
+	if s.sriClient == nil {
+		return nil, errors.New("el cliente del SRI no está configurado")
+	}
+
+	f, err := os.Open(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el reporte: %w", err)
+	}
+	entries, err := sri.ParseReceivedReport(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("el archivo no contiene claves de acceso")
+	}
+
+	if err := os.MkdirAll(s.downloadDir, 0o755); err != nil {
+		return nil, fmt.Errorf("no se pudo crear la carpeta de descargas: %w", err)
+	}
+
+	var lastRequest time.Time
+	results := make([]domain.ReceivedDocumentResult, 0, len(entries))
+	for i, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := domain.ReceivedDocumentResult{AccessKey: entry.AccessKey, SupplierName: entry.SupplierName}
+		s.processReceived(ctx, entry, &result, accountID, categoryID, currentUser, &lastRequest)
+		if result.Status == domain.ReceivedFailed && ctx.Err() != nil {
+			// Cancelado durante la espera o la consulta: queda pendiente para la próxima vez
+			return results, ctx.Err()
+		}
+
+		results = append(results, result)
+		if onProgress != nil {
+			onProgress(i+1, len(entries), result)
+		}
+	}
+	return results, nil
+}
+
+func (s *PurchaseImportService) processReceived(
+	ctx context.Context,
+	entry sri.ReceivedReportEntry,
+	result *domain.ReceivedDocumentResult,
+	accountID, categoryID int,
+	currentUser domain.User,
+	lastRequest *time.Time,
+) {
+	fail := func(format string, args ...any) {
+		result.Status = domain.ReceivedFailed
+		result.Message = fmt.Sprintf(format, args...)
+	}
+
+	key, err := sri.ParseAccessKey(entry.AccessKey)
+	if err != nil {
+		fail("línea %d: %v", entry.Line, err)
+		return
+	}
+	result.DocumentNumber = key.DocumentNumber()
+	if key.DocType != "01" {
+		result.Status = domain.ReceivedSkipped
+		result.Message = fmt.Sprintf("%s: solo se importan facturas", key.DocTypeName())
+		return
+	}
+
+	exists, err := s.txRepo.SupplierAccessKeyExists(ctx, key.Raw)
+	if err != nil {
+		fail("%v", err)
+		return
+	}
+	if exists {
+		result.Status = domain.ReceivedAlreadyImported
+		return
+	}
+
+	xmlPath := filepath.Join(s.downloadDir, key.Raw+".xml")
+	if _, err := os.Stat(xmlPath); err != nil {
+		if err := s.waitTurn(ctx, lastRequest); err != nil {
+			fail("descarga cancelada")
+			return
+		}
+		if err := s.fetchAuthorized(ctx, key, xmlPath); err != nil {
+			fail("%v", err)
+			return
+		}
+	}
+
+	tx, err := s.ImportSupplierInvoice(ctx, xmlPath, accountID, categoryID, currentUser)
+	switch {
+	case errors.Is(err, domain.ErrPurchaseAlreadyImported):
+		result.Status = domain.ReceivedAlreadyImported
+	case err != nil:
+		fail("%v", err)
+	default:
+		result.Status = domain.ReceivedNew
+		result.TransactionID = tx.ID
+	}
+}
+
+// waitTurn espera lo necesario para no consultar al SRI más de una vez por DownloadInterval.
+func (s *PurchaseImportService) waitTurn(ctx context.Context, lastRequest *time.Time) error {
+	if wait := time.Until(lastRequest.Add(s.DownloadInterval)); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	*lastRequest = time.Now()
+	return nil
+}
+
+// fetchAuthorized consulta el comprobante al SRI y guarda su XML autorizado en destPath.
+func (s *PurchaseImportService) fetchAuthorized(ctx context.Context, key *sri.AccessKey, destPath string) error {
+	resp, err := s.sriClient.AutorizarComprobante(ctx, key.Raw, key.Environment)
+	if err != nil {
+		return err
+	}
+
+	var auth *sri.Autorizacion
+	for i := range resp.Autorizaciones.Autorizacion {
+		a := &resp.Autorizaciones.Autorizacion[i]
+		if a.Estado == "AUTORIZADO" {
+			auth = a
+			break
+		}
+	}
+	if auth == nil {
+		if len(resp.Autorizaciones.Autorizacion) == 0 {
+			return errors.New("el SRI no devolvió el comprobante")
+		}
+		return fmt.Errorf("el comprobante está %s en el SRI", resp.Autorizaciones.Autorizacion[0].Estado)
+	}
+
+	data, err := auth.AuthorizedXML()
+	if err != nil {
+		return fmt.Errorf("error armando el XML autorizado: %w", err)
+	}
+
+	// Se escribe a un temporal y se renombra para no dejar XML a medias si el proceso se interrumpe
+	tmpPath := destPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("no se pudo guardar el XML descargado: %w", err)
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return fmt.Errorf("no se pudo guardar el XML descargado: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAutorizacionServer imita el servicio AutorizacionComprobantesOffline: responde con el comprobante
+// registrado para cada clave o con cero comprobantes si no la conoce.
+type fakeAutorizacionServer struct {
+	mu       sync.Mutex
+	docs     map[string]string
+	requests []string
+}
+
+var claveConsultada = regexp.MustCompile(`<claveAccesoComprobante>(\d+)</claveAccesoComprobante>`)
+
+func (f *fakeAutorizacionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	m := claveConsultada.FindSubmatch(body)
+	if m == nil {
+		http.Error(w, "petición inválida", http.StatusBadRequest)
+		return
+	}
+	key := string(m[1])
+
+	f.mu.Lock()
+	f.requests = append(f.requests, key)
+	doc, ok := f.docs[key]
+	f.mu.Unlock()
+
+	autorizaciones := ""
+	count := 0
+	if ok {
+		count = 1
+		autorizaciones = fmt.Sprintf(`<autorizacion><estado>AUTORIZADO</estado><numeroAutorizacion>%s</numeroAutorizacion>`+
+			`<fechaAutorizacion>2026-02-03T09:30:00-05:00</fechaAutorizacion><ambiente>PRODUCCIÓN</ambiente>`+
+			`<comprobante><![CDATA[%s]]></comprobante><mensajes/></autorizacion>`, key, doc)
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=UTF-8")
+	_, _ = fmt.Fprintf(w, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>`+
+		`<ns2:autorizacionComprobanteResponse xmlns:ns2="http://ec.gob.sri.ws.autorizacion"><RespuestaAutorizacionComprobante>`+
+		`<claveAccesoConsultada>%s</claveAccesoConsultada><numeroComprobantes>%d</numeroComprobantes>`+
+		`<autorizaciones>%s</autorizaciones></RespuestaAutorizacionComprobante></ns2:autorizacionComprobanteResponse>`+
+		`</soap:Body></soap:Envelope>`, key, count, autorizaciones)
+}
+
+func (f *fakeAutorizacionServer) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func TestDownloadReceived(t *testing.T) {
+	ctx := context.Background()
+	user := domain.User{BaseEntity: domain.BaseEntity{ID: 3}}
+	issuer := &domain.Issuer{BaseEntity: domain.BaseEntity{ID: 1}, RUC: "1790012345001"}
+
+	newXML, newKey := supplierInvoiceXML(t, issuer.RUC, "000000500")
+	_, importedKey := supplierInvoiceXML(t, issuer.RUC, "000000501")
+	_, missingKey := supplierInvoiceXML(t, issuer.RUC, "000000502")
+	retentionKey := sri.GenerateAccessKey(time.Date(2026, 2, 4, 0, 0, 0, 0, time.Local), "07", "0991234567001", 2, "001", "002", "000000033", "87654321", 1)
+	brokenKey := newKey[:48] + fmt.Sprint((int(newKey[48]-'0')+1)%10)
+
+	fake := &fakeAutorizacionServer{docs: map[string]string{newKey: newXML}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	line := func(serie, key string) string {
+		return fmt.Sprintf("0991234567001\tDistribuidora del Pacífico S.A.\tFactura\t%s\t%s\t03/02/2026 09:30:00\t03/02/2026\t1790012345001\t120.00\t15.00\t135.00\t", serie, key)
+	}
+	report := strings.Join([]string{
+		"RUC_EMISOR\tRAZON_SOCIAL_EMISOR\tTIPO_COMPROBANTE\tSERIE_COMPROBANTE\tCLAVE_ACCESO\tFECHA_AUTORIZACION\tFECHA_EMISION\tIDENTIFICACION_RECEPTOR\tVALOR_SIN_IMPUESTOS\tIVA\tIMPORTE_TOTAL\tNUMERO_DOCUMENTO_MODIFICADO",
+		line("001-002-000000500", newKey),
+		line("001-002-000000501", importedKey),
+		line("001-002-000000033", retentionKey),
+		line("001-002-000000502", missingKey),
+		line("001-002-000000500", newKey),
+		line("001-002-000000500", brokenKey),
+	}, "\r\n")
+	reportPath := filepath.Join(t.TempDir(), "recibidos.txt")
+	require.NoError(t, os.WriteFile(reportPath, []byte(report), 0o600))
+
+	downloadDir := t.TempDir()
+	mockTxRepo := new(mocks.MockTransactionRepository)
+	mockClientRepo := new(mocks.MockTaxPayerRepository)
+	mockIssuerRepo := new(mocks.MockIssuerRepository)
+	mockStorage := new(mocks.MockStorageService)
+	mockIssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+
+	svc := service.NewPurchaseImportService(mockTxRepo, mockClientRepo, mockIssuerRepo, newTestTaxRateRepo(), mockStorage, &sri.SoapClient{
+		Timeout:         5 * time.Second,
+		AutorizacionURL: srv.URL,
+	}, downloadDir)
+	svc.DownloadInterval = 40 * time.Millisecond
+
+	statuses := func(results []domain.ReceivedDocumentResult) map[string]domain.ReceivedDocumentStatus {
+		m := map[string]domain.ReceivedDocumentStatus{}
+		for _, r := range results {
+			m[r.AccessKey] = r.Status
+		}
+		return m
+	}
+
+	t.Run("Descarga e importa las facturas nuevas", func(t *testing.T) {
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, newKey).Return(false, nil).Times(2)
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, importedKey).Return(true, nil).Once()
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, missingKey).Return(false, nil).Once()
+		mockClientRepo.On("GetByIdentification", mock.Anything, "0991234567001").
+			Return(&domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 12}}, nil).Once()
+		mockTxRepo.On("CreateTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Transaction).ID = 901
+		}).Return(nil).Once()
+		mockStorage.On("Save", mock.Anything, filepath.Join(downloadDir, newKey+".xml"), mock.Anything).Return("stored.xml", nil).Once()
+		mockTxRepo.On("UpdateAttachmentPath", mock.Anything, 901, "stored.xml").Return(nil).Once()
+
+		var progress []int
+		start := time.Now()
+		results, err := svc.DownloadReceived(ctx, reportPath, 10, 20, user, func(done, total int, _ domain.ReceivedDocumentResult) {
+			assert.Equal(t, 5, total)
+			progress = append(progress, done)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, progress)
+		require.Len(t, results, 5)
+		assert.Equal(t, map[string]domain.ReceivedDocumentStatus{
+			newKey:       domain.ReceivedNew,
+			importedKey:  domain.ReceivedAlreadyImported,
+			retentionKey: domain.ReceivedSkipped,
+			missingKey:   domain.ReceivedFailed,
+			brokenKey:    domain.ReceivedFailed,
+		}, statuses(results))
+		assert.Equal(t, 901, results[0].TransactionID)
+		assert.Equal(t, "Distribuidora del Pacífico S.A.", results[0].SupplierName)
+		assert.Equal(t, "001-002-000000500", results[0].DocumentNumber)
+		assert.Contains(t, results[3].Message, "no devolvió el comprobante")
+
+		// Solo se consultan las facturas que faltan, respetando la pausa entre peticiones
+		assert.Equal(t, []string{newKey, missingKey}, fake.requests)
+		assert.GreaterOrEqual(t, time.Since(start), svc.DownloadInterval)
+		assert.FileExists(t, filepath.Join(downloadDir, newKey+".xml"))
+		mockTxRepo.AssertExpectations(t)
+	})
+
+	t.Run("Al retomar no vuelve a consultar lo ya descargado", func(t *testing.T) {
+		// La factura nueva quedó descargada pero su importación se interrumpió
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, newKey).Return(false, nil).Times(2)
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, importedKey).Return(true, nil).Once()
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, missingKey).Return(false, nil).Once()
+		mockClientRepo.On("GetByIdentification", mock.Anything, "0991234567001").
+			Return(&domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 12}}, nil).Once()
+		mockTxRepo.On("CreateTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockStorage.On("Save", mock.Anything, mock.Anything, mock.Anything).Return("stored.xml", nil).Once()
+		mockTxRepo.On("UpdateAttachmentPath", mock.Anything, mock.Anything, "stored.xml").Return(nil).Once()
+
+		before := fake.requestCount()
+		results, err := svc.DownloadReceived(ctx, reportPath, 10, 20, user, nil)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReceivedNew, statuses(results)[newKey])
+		assert.Equal(t, []string{missingKey}, fake.requests[before:])
+	})
+
+	t.Run("Se detiene al cancelar", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		results, err := svc.DownloadReceived(cancelled, reportPath, 10, 20, user, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, results)
+	})
+}
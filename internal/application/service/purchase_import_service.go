@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// PurchaseImportService registra como egresos las facturas electrónicas recibidas de proveedores.
+type PurchaseImportService struct {
+	txRepo      TransactionRepository
+	clientRepo  TaxPayerRepository
+	issuerRepo  IssuerRepository
+	taxRateRepo TaxRateRepository
+	storage     StorageService
+	sriClient   sri.Client
+	downloadDir string // XML descargados del SRI pendientes de importar (ver DownloadReceived)
+
+	// DownloadInterval es la pausa mínima entre consultas al SRI en DownloadReceived.
+	DownloadInterval time.Duration
+}
+
+func NewPurchaseImportService(
+	txRepo TransactionRepository,
+	clientRepo TaxPayerRepository,
+	issuerRepo IssuerRepository,
+	taxRateRepo TaxRateRepository,
+	storage StorageService,
+	sriClient sri.Client,
+	downloadDir string,
+) *PurchaseImportService {
+	return &PurchaseImportService{
+		txRepo:           txRepo,
+		clientRepo:       clientRepo,
+		issuerRepo:       issuerRepo,
+		taxRateRepo:      taxRateRepo,
+		storage:          storage,
+		sriClient:        sriClient,
+		downloadDir:      downloadDir,
+		DownloadInterval: DefaultDownloadInterval,
+	}
+}
+
+// ImportSupplierInvoice lee el XML autorizado de una factura de proveedor y la registra como egreso
+// en la cuenta y categoría indicadas, con sus ítems y el desglose del IVA. El proveedor se busca por
+// RUC y se crea si no existe; el XML original queda guardado como adjunto de la transacción.
+func (s *PurchaseImportService) ImportSupplierInvoice(
+	ctx context.Context,
+	xmlPath string,
+	accountID, categoryID int,
+	currentUser domain.User,
+) (*domain.Transaction, error) {
+	if accountID == 0 || categoryID == 0 {
+		return nil, errors.New("seleccione la cuenta y la categoría del egreso")
+	}
+
+	data, err := os.ReadFile(xmlPath)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el archivo: %w", err)
+	}
+
+	invoice, err := sri.ParseAuthorizedInvoice(data)
+	if err != nil {
+		return nil, err
+	}
+	f := invoice.Factura
+	docNumber := invoice.Key.DocumentNumber()
+
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo el emisor: %w", err)
+	}
+	if issuer != nil && f.InfoFactura.IdentificacionComprador != issuer.RUC {
+		return nil, fmt.Errorf("la factura %s está emitida a %s (%s), no a este emisor",
+			docNumber, f.InfoFactura.RazonSocialComprador, f.InfoFactura.IdentificacionComprador)
+	}
+
+	exists, err := s.txRepo.SupplierAccessKeyExists(ctx, invoice.Key.Raw)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s de %s", domain.ErrPurchaseAlreadyImported, docNumber, f.InfoTributaria.RazonSocial)
+	}
+
+	rates, err := loadTaxRates(ctx, s.taxRateRepo)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := purchaseFromInvoice(f, rates)
+	if err != nil {
+		return nil, fmt.Errorf("factura %s: %w", docNumber, err)
+	}
+
+	supplier, err := s.findOrCreateSupplier(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Description = fmt.Sprintf("Factura %s - %s", docNumber, f.InfoTributaria.RazonSocial)
+	tx.AccountID = accountID
+	tx.CategoryID = categoryID
+	tx.TaxPayerID = &supplier.ID
+	tx.SupplierAccessKey = &invoice.Key.Raw
+	tx.CreatedByID = currentUser.ID
+	tx.UpdatedByID = currentUser.ID
+
+	if err := s.txRepo.CreateTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("error al registrar la compra: %w", err)
+	}
+
+	// Se guarda el archivo tal como llegó, con la firma del proveedor intacta
+	destinationName := fmt.Sprintf("tx-%d-%s.xml", tx.ID, invoice.Key.Raw)
+	storagePath, err := s.storage.Save(ctx, xmlPath, destinationName)
+	if err != nil {
+		return tx, fmt.Errorf("la compra se registró pero no se pudo guardar el XML: %w", err)
+	}
+	if err := s.txRepo.UpdateAttachmentPath(ctx, tx.ID, storagePath); err != nil {
+		return tx, fmt.Errorf("la compra se registró pero no se pudo vincular el XML: %w", err)
+	}
+	tx.AttachmentPath = &storagePath
+
+	return tx, nil
+}
+
+func (s *PurchaseImportService) findOrCreateSupplier(ctx context.Context, f *sri.Factura) (*domain.TaxPayer, error) {
+	ruc := f.InfoTributaria.Ruc
+	supplier, err := s.clientRepo.GetByIdentification(ctx, ruc)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando al proveedor: %w", err)
+	}
+	if supplier != nil {
+		return supplier, nil
+	}
+
+	supplier = &domain.TaxPayer{
+		Identification:     ruc,
+		IdentificationType: "04",
+		Name:               f.InfoTributaria.RazonSocial,
+		Address:            f.InfoTributaria.DirMatriz,
+	}
+	if err := s.clientRepo.Create(ctx, supplier); err != nil {
+		return nil, fmt.Errorf("error creando al proveedor %s: %w", ruc, err)
+	}
+	return supplier, nil
+}
+
+// purchaseFromInvoice arma el egreso con los valores de la factura tal como los declaró el proveedor.
+// Las tarifas de IVA deben existir en el catálogo y estar vigentes a la fecha de emisión; el ICE
+// (código 3) y el IRBPNR (código 5) se registran como impuestos de los ítems.
+func purchaseFromInvoice(f *sri.Factura, rates domain.TaxRateTable) (*domain.Transaction, error) {
+	date, err := time.ParseInLocation("02/01/2006", f.InfoFactura.FechaEmision, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("fecha de emisión %q inválida", f.InfoFactura.FechaEmision)
+	}
+
+	tx := &domain.Transaction{TransactionDate: date}
+	for _, ti := range f.InfoFactura.TotalConImpuestos.TotalImpuesto {
+		base, err := parseAmount("baseImponible", ti.BaseImponible)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseAmount("valor", ti.Valor)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.TrimSpace(ti.Codigo) {
+		case strconv.Itoa(domain.ItemTaxICE):
+			tx.ICEAmount += value
+			continue
+		case strconv.Itoa(domain.ItemTaxIRBPNR):
+			tx.IRBPNRAmount += value
+			continue
+		}
+		rate, err := purchaseTaxRate(ti.Codigo, ti.CodigoPorcentaje, date, rates)
+		if err != nil {
+			return nil, err
+		}
+		if rate.Taxed() {
+			tx.Subtotal15 += base
+			tx.TaxAmount += value
+		} else {
+			tx.Subtotal0 += base
+		}
+	}
+
+	// La base del IVA declarada por el proveedor incluye el ICE; en el egreso los subtotales van
+	// sin él, como en las ventas, y el ICE se suma aparte al importe total.
+	var iceTaxed, iceZero float64
+	for i, d := range f.Detalles.Detalle {
+		item := domain.TransactionItem{Description: strings.TrimSpace(d.Descripcion)}
+		var taxed bool
+		for _, imp := range d.Impuestos.Impuesto {
+			if tax, ok, err := purchaseItemTax(imp); err != nil {
+				return nil, fmt.Errorf("detalle %d: %w", i+1, err)
+			} else if ok {
+				item.Taxes = append(item.Taxes, tax)
+				continue
+			}
+			rate, err := purchaseTaxRate(imp.Codigo, imp.CodigoPorcentaje, date, rates)
+			if err != nil {
+				return nil, fmt.Errorf("detalle %d: %w", i+1, err)
+			}
+			item.TaxRate = rate.Code
+			taxed = rate.Taxed()
+		}
+		for _, tax := range item.Taxes {
+			if tax.TaxCode != domain.ItemTaxICE {
+				continue
+			}
+			if taxed {
+				iceTaxed += tax.Amount
+			} else {
+				iceZero += tax.Amount
+			}
+		}
+		fields := []struct {
+			name  string
+			value string
+			dest  *float64
+		}{
+			{"cantidad", d.Cantidad, &item.Quantity},
+			{"precioUnitario", d.PrecioUnitario, &item.UnitPrice},
+			{"descuento", d.Descuento, &item.Discount},
+			{"precioTotalSinImpuesto", d.PrecioTotalSinImpuesto, &item.Subtotal},
+		}
+		for _, fld := range fields {
+			if *fld.dest, err = parseAmount(fld.name, fld.value); err != nil {
+				return nil, fmt.Errorf("detalle %d: %w", i+1, err)
+			}
+		}
+		tx.Items = append(tx.Items, item)
+	}
+
+	if tx.Tip, err = parseAmount("propina", f.InfoFactura.Propina); err != nil {
+		return nil, err
+	}
+	if tx.Amount, err = parseAmount("importeTotal", f.InfoFactura.ImporteTotal); err != nil {
+		return nil, err
+	}
+
+	tx.Subtotal15 = math.Round((tx.Subtotal15-iceTaxed)*100) / 100
+	tx.Subtotal0 = math.Round((tx.Subtotal0-iceZero)*100) / 100
+	tx.TaxAmount = math.Round(tx.TaxAmount*100) / 100
+	tx.ICEAmount = math.Round(tx.ICEAmount*100) / 100
+	tx.IRBPNRAmount = math.Round(tx.IRBPNRAmount*100) / 100
+	sum := tx.Subtotal15 + tx.Subtotal0 + tx.TaxAmount + tx.ICEAmount + tx.IRBPNRAmount + tx.Tip
+	if math.Abs(math.Round(sum*100)-math.Round(tx.Amount*100)) >= 1 {
+		return nil, fmt.Errorf("el importe total ($%.2f) no coincide con la suma de bases, IVA, ICE, IRBPNR y propina ($%.2f)", tx.Amount, sum)
+	}
+	return tx, nil
+}
+
+// purchaseTaxRate resuelve el código de IVA del SRI con la tarifa vigente en la fecha de la factura.
+func purchaseTaxRate(codigo, codigoPorcentaje string, date time.Time, rates domain.TaxRateTable) (domain.TaxRate, error) {
+	if codigo != "2" {
+		return domain.TaxRate{}, fmt.Errorf("el impuesto con código %s no está soportado", codigo)
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(codigoPorcentaje))
+	if err != nil {
+		return domain.TaxRate{}, fmt.Errorf("la tarifa de IVA con código %s no está soportada", codigoPorcentaje)
+	}
+	return rates.Find(code, date)
+}
+
+// purchaseItemTax convierte el ICE o el IRBPNR de un detalle en el impuesto del ítem. Devuelve false
+// para el IVA. La tarifa del ICE es específica cuando el valor resulta de multiplicarla por la base
+// (unidades) y no de aplicarla como porcentaje; el IRBPNR siempre es por botella.
+func purchaseItemTax(imp sri.Impuesto) (domain.ItemTax, bool, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(imp.Codigo))
+	if err != nil || (code != domain.ItemTaxICE && code != domain.ItemTaxIRBPNR) {
+		return domain.ItemTax{}, false, nil
+	}
+	tax := domain.ItemTax{TaxCode: code, PercentageCode: strings.TrimSpace(imp.CodigoPorcentaje)}
+	if tax.Rate, err = parseAmount("tarifa", imp.Tarifa); err != nil {
+		return domain.ItemTax{}, false, err
+	}
+	if tax.Base, err = parseAmount("baseImponible", imp.BaseImponible); err != nil {
+		return domain.ItemTax{}, false, err
+	}
+	if tax.Amount, err = parseAmount("valor", imp.Valor); err != nil {
+		return domain.ItemTax{}, false, err
+	}
+	tax.Specific = code == domain.ItemTaxIRBPNR ||
+		math.Abs(tax.Base*tax.Rate/100-tax.Amount) >= 0.01 && math.Abs(tax.Base*tax.Rate-tax.Amount) < 0.01
+	if err := tax.Validate(); err != nil {
+		return domain.ItemTax{}, false, err
+	}
+	return tax, true, nil
+}
+
+func parseAmount(field, value string) (float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor %q inválido en %s", value, field)
+	}
+	return v, nil
+}
@@ -0,0 +1,256 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// supplierInvoiceXML arma la factura de un proveedor con una línea gravada con 15% y otra con 0%.
+func supplierInvoiceXML(t *testing.T, buyerRUC, sequential string) (string, string) {
+	t.Helper()
+	supplierRUC := "0991234567001"
+	key := sri.GenerateAccessKey(time.Date(2026, 2, 3, 0, 0, 0, 0, time.Local), "01", supplierRUC, 2, "001", "002", sequential, "87654321", 1)
+
+	f := &sri.Factura{ID: "comprobante", Version: "2.1.0"}
+	f.InfoTributaria = sri.InfoTributaria{
+		Ambiente: "2", TipoEmision: "1", RazonSocial: "Distribuidora del Pacífico S.A.", Ruc: supplierRUC,
+		ClaveAcceso: key, CodDoc: "01", Estab: "001", PtoEmi: "002", Secuencial: sequential, DirMatriz: "Av. 9 de Octubre, Guayaquil",
+	}
+	f.InfoFactura = sri.InfoFactura{
+		FechaEmision: "03/02/2026", TipoIdentificacionComprador: "04", RazonSocialComprador: "Empresa de Prueba S.A.",
+		IdentificacionComprador: buyerRUC, TotalSinImpuestos: "120.00", TotalDescuento: "5.00", Propina: "0.00",
+		ImporteTotal: "135.00", Moneda: "DOLAR",
+	}
+	f.InfoFactura.TotalConImpuestos.TotalImpuesto = []sri.TotalImpuesto{
+		{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "100.00", Valor: "15.00"},
+		{Codigo: "2", CodigoPorcentaje: "0", BaseImponible: "20.00", Valor: "0.00"},
+	}
+	gravado := sri.Detalle{Descripcion: "Resma de papel", Cantidad: "5.000000", PrecioUnitario: "21.000000", Descuento: "5.00", PrecioTotalSinImpuesto: "100.00"}
+	gravado.Impuestos.Impuesto = []sri.Impuesto{{Codigo: "2", CodigoPorcentaje: "4", Tarifa: "15", BaseImponible: "100.00", Valor: "15.00"}}
+	exento := sri.Detalle{Descripcion: "Libro contable", Cantidad: "2.000000", PrecioUnitario: "10.000000", Descuento: "0.00", PrecioTotalSinImpuesto: "20.00"}
+	exento.Impuestos.Impuesto = []sri.Impuesto{{Codigo: "2", CodigoPorcentaje: "0", Tarifa: "0", BaseImponible: "20.00", Valor: "0.00"}}
+	f.Detalles.Detalle = []sri.Detalle{gravado, exento}
+
+	facturaXML, err := sri.MarshalFactura(f)
+	require.NoError(t, err)
+	return string(facturaXML), key
+}
+
+// writeSupplierInvoice guarda la factura en un directorio temporal dentro de la envoltura <autorizacion>.
+func writeSupplierInvoice(t *testing.T, buyerRUC string) (string, string) {
+	t.Helper()
+	facturaXML, key := supplierInvoiceXML(t, buyerRUC, "000000458")
+	return writeAuthorizedInvoice(t, key, facturaXML), key
+}
+
+// iceSupplierInvoice arma la factura de un proveedor con un licor gravado con ICE ad valorem y agua
+// embotellada con IRBPNR, ambos con 15% de IVA. La base del IVA del licor incluye el ICE.
+func iceSupplierInvoice(t *testing.T, buyerRUC, importeTotal string) (string, string) {
+	t.Helper()
+	supplierRUC := "0991234567001"
+	key := sri.GenerateAccessKey(time.Date(2026, 2, 3, 0, 0, 0, 0, time.Local), "01", supplierRUC, 2, "001", "002", "000000459", "87654321", 1)
+
+	f := &sri.Factura{ID: "comprobante", Version: "2.1.0"}
+	f.InfoTributaria = sri.InfoTributaria{
+		Ambiente: "2", TipoEmision: "1", RazonSocial: "Distribuidora del Pacífico S.A.", Ruc: supplierRUC,
+		ClaveAcceso: key, CodDoc: "01", Estab: "001", PtoEmi: "002", Secuencial: "000000459", DirMatriz: "Av. 9 de Octubre, Guayaquil",
+	}
+	f.InfoFactura = sri.InfoFactura{
+		FechaEmision: "03/02/2026", TipoIdentificacionComprador: "04", RazonSocialComprador: "Empresa de Prueba S.A.",
+		IdentificacionComprador: buyerRUC, TotalSinImpuestos: "105.00", TotalDescuento: "0.00", Propina: "0.00",
+		ImporteTotal: importeTotal, Moneda: "DOLAR",
+	}
+	f.InfoFactura.TotalConImpuestos.TotalImpuesto = []sri.TotalImpuesto{
+		{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "125.00", Valor: "18.75"},
+		{Codigo: "3", CodigoPorcentaje: "3610", BaseImponible: "100.00", Valor: "20.00"},
+		{Codigo: "5", CodigoPorcentaje: "5001", BaseImponible: "10.00", Valor: "0.20"},
+	}
+	licor := sri.Detalle{Descripcion: "Whisky 750 ml", Cantidad: "2.000000", PrecioUnitario: "50.000000", Descuento: "0.00", PrecioTotalSinImpuesto: "100.00"}
+	licor.Impuestos.Impuesto = []sri.Impuesto{
+		{Codigo: "2", CodigoPorcentaje: "4", Tarifa: "15", BaseImponible: "120.00", Valor: "18.00"},
+		{Codigo: "3", CodigoPorcentaje: "3610", Tarifa: "20", BaseImponible: "100.00", Valor: "20.00"},
+	}
+	agua := sri.Detalle{Descripcion: "Agua 500 ml", Cantidad: "10.000000", PrecioUnitario: "0.500000", Descuento: "0.00", PrecioTotalSinImpuesto: "5.00"}
+	agua.Impuestos.Impuesto = []sri.Impuesto{
+		{Codigo: "2", CodigoPorcentaje: "4", Tarifa: "15", BaseImponible: "5.00", Valor: "0.75"},
+		{Codigo: "5", CodigoPorcentaje: "5001", Tarifa: "0.02", BaseImponible: "10.00", Valor: "0.20"},
+	}
+	f.Detalles.Detalle = []sri.Detalle{licor, agua}
+
+	facturaXML, err := sri.MarshalFactura(f)
+	require.NoError(t, err)
+	return writeAuthorizedInvoice(t, key, string(facturaXML)), key
+}
+
+// writeAuthorizedInvoice guarda la factura en un directorio temporal dentro de la envoltura <autorizacion>.
+func writeAuthorizedInvoice(t *testing.T, key, facturaXML string) string {
+	t.Helper()
+	data := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<autorizacion>
+  <estado>AUTORIZADO</estado>
+  <numeroAutorizacion>%s</numeroAutorizacion>
+  <fechaAutorizacion>2026-02-03T09:30:00-05:00</fechaAutorizacion>
+  <ambiente>PRODUCCIÓN</ambiente>
+  <comprobante><![CDATA[%s]]></comprobante>
+</autorizacion>`, key, facturaXML)
+
+	path := filepath.Join(t.TempDir(), "factura.xml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func TestImportSupplierInvoice(t *testing.T) {
+	ctx := context.Background()
+	user := domain.User{BaseEntity: domain.BaseEntity{ID: 3}}
+	issuer := &domain.Issuer{BaseEntity: domain.BaseEntity{ID: 1}, RUC: "1790012345001"}
+
+	setup := func() (*service.PurchaseImportService, *mocks.MockTransactionRepository, *mocks.MockTaxPayerRepository, *mocks.MockStorageService) {
+		mockTxRepo := new(mocks.MockTransactionRepository)
+		mockClientRepo := new(mocks.MockTaxPayerRepository)
+		mockIssuerRepo := new(mocks.MockIssuerRepository)
+		mockStorage := new(mocks.MockStorageService)
+		mockIssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		return service.NewPurchaseImportService(mockTxRepo, mockClientRepo, mockIssuerRepo, newTestTaxRateRepo(), mockStorage, nil, t.TempDir()), mockTxRepo, mockClientRepo, mockStorage
+	}
+
+	t.Run("Registra la compra, crea al proveedor y guarda el XML", func(t *testing.T) {
+		svc, mockTxRepo, mockClientRepo, mockStorage := setup()
+		path, key := writeSupplierInvoice(t, issuer.RUC)
+
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, key).Return(false, nil).Once()
+		mockClientRepo.On("GetByIdentification", mock.Anything, "0991234567001").Return(nil, nil).Once()
+		var supplier *domain.TaxPayer
+		mockClientRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.TaxPayer")).Run(func(args mock.Arguments) {
+			supplier = args.Get(1).(*domain.TaxPayer)
+			supplier.ID = 77
+		}).Return(nil).Once()
+
+		var created *domain.Transaction
+		mockTxRepo.On("CreateTransaction", mock.Anything, mock.AnythingOfType("*domain.Transaction")).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*domain.Transaction)
+			created.ID = 900
+		}).Return(nil).Once()
+		mockStorage.On("Save", mock.Anything, path, "tx-900-"+key+".xml").Return("tx-900-"+key+".xml", nil).Once()
+		mockTxRepo.On("UpdateAttachmentPath", mock.Anything, 900, "tx-900-"+key+".xml").Return(nil).Once()
+
+		tx, err := svc.ImportSupplierInvoice(ctx, path, 10, 20, user)
+		require.NoError(t, err)
+		require.Same(t, created, tx)
+
+		assert.Equal(t, "04", supplier.IdentificationType)
+		assert.Equal(t, "Distribuidora del Pacífico S.A.", supplier.Name)
+
+		assert.Equal(t, "Factura 001-002-000000458 - Distribuidora del Pacífico S.A.", tx.Description)
+		assert.Equal(t, time.Date(2026, 2, 3, 0, 0, 0, 0, time.Local), tx.TransactionDate)
+		assert.Equal(t, 10, tx.AccountID)
+		assert.Equal(t, 20, tx.CategoryID)
+		assert.Equal(t, 77, *tx.TaxPayerID)
+		assert.Equal(t, key, *tx.SupplierAccessKey)
+		assert.Equal(t, 3, tx.CreatedByID)
+		assert.Equal(t, 100.0, tx.Subtotal15)
+		assert.Equal(t, 20.0, tx.Subtotal0)
+		assert.Equal(t, 15.0, tx.TaxAmount)
+		assert.Equal(t, 135.0, tx.Amount)
+
+		require.Len(t, tx.Items, 2)
+		assert.Equal(t, domain.TransactionItem{Description: "Resma de papel", Quantity: 5, UnitPrice: 21, Discount: 5, Subtotal: 100, TaxRate: 4}, tx.Items[0])
+		assert.Equal(t, 0, tx.Items[1].TaxRate)
+		assert.Equal(t, 20.0, tx.Items[1].Subtotal)
+
+		mockTxRepo.AssertExpectations(t)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("Reutiliza al proveedor existente", func(t *testing.T) {
+		svc, mockTxRepo, mockClientRepo, mockStorage := setup()
+		path, key := writeSupplierInvoice(t, issuer.RUC)
+
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, key).Return(false, nil).Once()
+		mockClientRepo.On("GetByIdentification", mock.Anything, "0991234567001").
+			Return(&domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 12}, Identification: "0991234567001"}, nil).Once()
+		mockTxRepo.On("CreateTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockStorage.On("Save", mock.Anything, path, mock.Anything).Return("stored.xml", nil).Once()
+		mockTxRepo.On("UpdateAttachmentPath", mock.Anything, mock.Anything, "stored.xml").Return(nil).Once()
+
+		tx, err := svc.ImportSupplierInvoice(ctx, path, 10, 20, user)
+		require.NoError(t, err)
+		assert.Equal(t, 12, *tx.TaxPayerID)
+		mockClientRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Registra el ICE y el IRBPNR de los ítems", func(t *testing.T) {
+		svc, mockTxRepo, mockClientRepo, mockStorage := setup()
+		path, key := iceSupplierInvoice(t, issuer.RUC, "143.95")
+
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, key).Return(false, nil).Once()
+		mockClientRepo.On("GetByIdentification", mock.Anything, "0991234567001").
+			Return(&domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 12}, Identification: "0991234567001"}, nil).Once()
+		mockTxRepo.On("CreateTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockStorage.On("Save", mock.Anything, path, mock.Anything).Return("stored.xml", nil).Once()
+		mockTxRepo.On("UpdateAttachmentPath", mock.Anything, mock.Anything, "stored.xml").Return(nil).Once()
+
+		tx, err := svc.ImportSupplierInvoice(ctx, path, 10, 20, user)
+		require.NoError(t, err)
+
+		// La base del IVA del proveedor (125) incluye el ICE; el subtotal del egreso no
+		assert.Equal(t, 105.0, tx.Subtotal15)
+		assert.Equal(t, 18.75, tx.TaxAmount)
+		assert.Equal(t, 20.0, tx.ICEAmount)
+		assert.Equal(t, 0.2, tx.IRBPNRAmount)
+		assert.Equal(t, 143.95, tx.Amount)
+
+		require.Len(t, tx.Items, 2)
+		assert.Equal(t, 4, tx.Items[0].TaxRate)
+		assert.Equal(t, []domain.ItemTax{{TaxCode: domain.ItemTaxICE, PercentageCode: "3610", Rate: 20, Base: 100, Amount: 20}}, tx.Items[0].Taxes)
+		assert.Equal(t, []domain.ItemTax{{TaxCode: domain.ItemTaxIRBPNR, PercentageCode: "5001", Rate: 0.02, Specific: true, Base: 10, Amount: 0.2}}, tx.Items[1].Taxes)
+	})
+
+	t.Run("Rechaza un importe total que no incluye el ICE", func(t *testing.T) {
+		svc, mockTxRepo, mockClientRepo, _ := setup()
+		path, key := iceSupplierInvoice(t, issuer.RUC, "123.95")
+
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, key).Return(false, nil).Once()
+
+		_, err := svc.ImportSupplierInvoice(ctx, path, 10, 20, user)
+		assert.ErrorContains(t, err, "no coincide")
+		mockTxRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+		mockClientRepo.AssertNotCalled(t, "GetByIdentification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rechaza una factura ya importada", func(t *testing.T) {
+		svc, mockTxRepo, mockClientRepo, _ := setup()
+		path, key := writeSupplierInvoice(t, issuer.RUC)
+
+		mockTxRepo.On("SupplierAccessKeyExists", mock.Anything, key).Return(true, nil).Once()
+
+		_, err := svc.ImportSupplierInvoice(ctx, path, 10, 20, user)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, domain.ErrPurchaseAlreadyImported))
+		assert.Contains(t, err.Error(), "001-002-000000458")
+		mockTxRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+		mockClientRepo.AssertNotCalled(t, "GetByIdentification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rechaza facturas emitidas a otro comprador", func(t *testing.T) {
+		svc, mockTxRepo, _, _ := setup()
+		path, _ := writeSupplierInvoice(t, "1712345678001")
+
+		_, err := svc.ImportSupplierInvoice(ctx, path, 10, 20, user)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no a este emisor")
+		mockTxRepo.AssertNotCalled(t, "SupplierAccessKeyExists", mock.Anything, mock.Anything)
+	})
+}
@@ -25,19 +25,28 @@ type DailyReportGenerator interface {
 	DailyReport(ctx context.Context, report *domain.DailyReport, outputPath string, currentUser *domain.User) error
 }
 
+// VATDeclarationReportGenerator defines an interface for generating the monthly VAT (form 104) worksheet.
+type VATDeclarationReportGenerator interface {
+	VATDeclarationReport(ctx context.Context, declaration *domain.VATDeclaration, outputPath string, currentUser *domain.User) error
+}
+
 // ReportServiceImpl provides methods to generate financial reports.
 type ReportServiceImpl struct {
 	repo            ReportRepository
 	transactionRepo TransactionRepository
 	categoryRepo    CategoryRepository
+	taxRepo         TaxReportRepository
+	issuerRepo      IssuerRepository
 	csvGenerator    interface { // <-- This is the
 		TransactionReportGenerator
 		DailyReportGenerator
+		VATDeclarationReportGenerator
 	}
 	pdfGenerator interface { // This generator must be able to handle all report types
 		TransactionReportGenerator
 		ReconciliationReportGenerator
 		DailyReportGenerator
+		VATDeclarationReportGenerator
 	}
 }
 
@@ -46,20 +55,26 @@ func NewReportService(
 	repo ReportRepository,
 	transactionRepo TransactionRepository,
 	categoryRepo CategoryRepository,
+	taxRepo TaxReportRepository,
+	issuerRepo IssuerRepository,
 	csvGenerator interface {
 		TransactionReportGenerator
 		DailyReportGenerator
+		VATDeclarationReportGenerator
 	},
 	pdfGenerator interface {
 		TransactionReportGenerator
 		ReconciliationReportGenerator
 		DailyReportGenerator
+		VATDeclarationReportGenerator
 	},
 ) *ReportServiceImpl {
 	return &ReportServiceImpl{
 		repo:            repo,
 		transactionRepo: transactionRepo,
 		categoryRepo:    categoryRepo,
+		taxRepo:         taxRepo,
+		issuerRepo:      issuerRepo,
 		csvGenerator:    csvGenerator,
 		pdfGenerator:    pdfGenerator,
 	}
@@ -91,6 +106,7 @@ func (s *ReportServiceImpl) GetFinancialSummary(
 
 	var totalIncome decimal.Decimal
 	var totalExpenses decimal.Decimal
+	var totalTips decimal.Decimal
 	incomeMap := make(map[string]decimal.Decimal)
 	expenseMap := make(map[string]decimal.Decimal)
 
@@ -101,6 +117,9 @@ func (s *ReportServiceImpl) GetFinancialSummary(
 			case domain.Income:
 				totalIncome = totalIncome.Add(amount)
 				incomeMap[tx.Category.Name] = incomeMap[tx.Category.Name].Add(amount)
+				if !tx.IsVoided {
+					totalTips = totalTips.Add(decimal.NewFromFloat(tx.Tip))
+				}
 			case domain.Outcome:
 				totalExpenses = totalExpenses.Add(amount)
 				expenseMap[tx.Category.Name] = expenseMap[tx.Category.Name].Add(amount)
@@ -114,6 +133,7 @@ func (s *ReportServiceImpl) GetFinancialSummary(
 		TotalIncome:        totalIncome,
 		TotalExpenses:      totalExpenses,
 		NetProfitLoss:      netProfitLoss,
+		TotalTips:          totalTips,
 		IncomeByCategory:   mapToSortedSlice(incomeMap),
 		ExpensesByCategory: mapToSortedSlice(expenseMap),
 	}, nil
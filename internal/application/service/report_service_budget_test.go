@@ -16,7 +16,7 @@ func TestGetBudgetOverview(t *testing.T) {
 	mockTxRepo := new(mocks.MockTransactionRepository)
 	mockCatRepo := new(mocks.MockCategoryRepository)
 	
-	service := NewReportService(nil, mockTxRepo, mockCatRepo, nil, nil)
+	service := NewReportService(nil, mockTxRepo, mockCatRepo, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	startDate := time.Now().AddDate(0, 0, -30)
@@ -15,7 +15,7 @@ func TestGetFinancialSummary(t *testing.T) {
 	mockTxRepo := new(mocks.MockTransactionRepository)
 	
 	// We don't need the other dependencies for this test
-	service := NewReportService(nil, mockTxRepo, nil, nil, nil)
+	service := NewReportService(nil, mockTxRepo, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	startDate := time.Now().AddDate(0, 0, -30)
@@ -93,4 +93,26 @@ func TestGetFinancialSummary(t *testing.T) {
 		
 		mockTxRepo.AssertExpectations(t)
 	})
+
+	t.Run("Sums tips of active sales only", func(t *testing.T) {
+		// Arrange
+		transactions := []domain.Transaction{
+			{Amount: 125, Tip: 10, Category: &domain.Category{Type: domain.Income}},
+			{Amount: 62.5, Tip: 5, IsVoided: true, Category: &domain.Category{Type: domain.Income}},
+			{Amount: 62.5, Category: &domain.Category{Type: domain.Outcome}},
+		}
+
+		mockTxRepo.On("FindAllTransactions", ctx, mock.AnythingOfType("domain.TransactionFilters"), (*string)(nil)).
+			Return(transactions, nil).Once()
+
+		// Act
+		summary, err := service.GetFinancialSummary(ctx, startDate, endDate, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "187.5", summary.TotalIncome.String())
+		assert.Equal(t, "10", summary.TotalTips.String())
+
+		mockTxRepo.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// GenerateVATDeclaration builds the form 104 worksheet for a month. Sales are split by rate and
+// reduced by the credit notes issued in the month, purchases add their VAT as tax credit, and the
+// credit carried over from the previous month (box 605) is applied before the amount payable.
+func (s *ReportServiceImpl) GenerateVATDeclaration(ctx context.Context, year int, month time.Month, previousCredit decimal.Decimal) (*domain.VATDeclaration, error) {
+	if month < time.January || month > time.December {
+		return nil, fmt.Errorf("mes %d inválido", month)
+	}
+	if previousCredit.IsNegative() {
+		return nil, errors.New("el crédito tributario del mes anterior no puede ser negativo")
+	}
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issuer: %w", err)
+	}
+	if issuer == nil {
+		return nil, errors.New("no hay un emisor activo configurado")
+	}
+
+	startDate := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	sales, err := s.taxRepo.GetSaleDocuments(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sale documents: %w", err)
+	}
+	purchases, err := s.taxRepo.GetPurchaseDocuments(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase documents: %w", err)
+	}
+
+	d := &domain.VATDeclaration{
+		Year:           year,
+		Month:          month,
+		IssuerRUC:      issuer.RUC,
+		IssuerName:     issuer.BusinessName,
+		PreviousCredit: previousCredit,
+		Withholdings:   map[string]decimal.Decimal{},
+	}
+	addVATSales(d, sales)
+	if err := addVATPurchases(d, purchases); err != nil {
+		return nil, err
+	}
+	settleVAT(d)
+	return d, nil
+}
+
+// GenerateVATDeclarationFile writes the form 104 worksheet in the requested format.
+func (s *ReportServiceImpl) GenerateVATDeclarationFile(ctx context.Context, declaration *domain.VATDeclaration, outputPath string, format string, currentUser *domain.User) error {
+	switch format {
+	case "CSV":
+		return s.csvGenerator.VATDeclarationReport(ctx, declaration, outputPath, currentUser)
+	case "PDF":
+		return s.pdfGenerator.VATDeclarationReport(ctx, declaration, outputPath, currentUser)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// addVATSales suma las facturas y notas de débito autorizadas en el valor bruto y descuenta las notas
+// de crédito en el neto. El ICE forma parte de la base gravada con IVA. Los comprobantes anulados en
+// el portal del SRI no se declaran.
+func addVATSales(d *domain.VATDeclaration, sales []domain.TaxSaleDocument) {
+	var creditNotes15, creditNotes0, creditNotesTax, salesTax decimal.Decimal
+	for _, doc := range sales {
+		if doc.SRIStatus != "AUTORIZADO" {
+			continue
+		}
+		subtotal15 := decimal.NewFromFloat(doc.Subtotal15).Add(decimal.NewFromFloat(doc.ICEAmount))
+		subtotal0 := decimal.NewFromFloat(doc.Subtotal0)
+		tax := decimal.NewFromFloat(doc.TaxAmount)
+		switch doc.ReceiptType {
+		case "04":
+			d.CreditNoteCount++
+			creditNotes15 = creditNotes15.Add(subtotal15)
+			creditNotes0 = creditNotes0.Add(subtotal0)
+			creditNotesTax = creditNotesTax.Add(tax)
+		default:
+			d.SalesCount++
+			d.SalesGross15 = d.SalesGross15.Add(subtotal15)
+			d.SalesGross0 = d.SalesGross0.Add(subtotal0)
+			salesTax = salesTax.Add(tax)
+		}
+	}
+
+	d.SalesNet15 = d.SalesGross15.Sub(creditNotes15)
+	d.SalesNet0 = d.SalesGross0.Sub(creditNotes0)
+	d.SalesTax15 = salesTax.Sub(creditNotesTax).Round(2)
+	d.SalesGross = d.SalesGross15.Add(d.SalesGross0)
+	d.SalesNet = d.SalesNet15.Add(d.SalesNet0)
+	d.SalesTax = d.SalesTax15
+}
+
+// addVATPurchases suma las compras con comprobante del proveedor y las retenciones de IVA que se
+// hicieron sobre ellas.
+func addVATPurchases(d *domain.VATDeclaration, purchases []domain.TaxPurchaseDocument) error {
+	for _, p := range purchases {
+		d.PurchaseCount++
+		d.PurchasesGross15 = d.PurchasesGross15.Add(decimal.NewFromFloat(p.Subtotal15))
+		d.PurchasesGross0 = d.PurchasesGross0.Add(decimal.NewFromFloat(p.Subtotal0))
+		d.PurchasesTax15 = d.PurchasesTax15.Add(decimal.NewFromFloat(p.TaxAmount))
+
+		if p.WithholdingXML == "" {
+			continue
+		}
+		_, docs, err := purchaseWithholding(p)
+		if err != nil {
+			return fmt.Errorf("compra %d: %w", p.TransactionID, err)
+		}
+		d.WithholdingCount++
+		for _, doc := range docs {
+			for _, r := range doc.Retenciones.Retencion {
+				if r.Codigo != domain.WithholdingTaxIVA {
+					continue
+				}
+				rate, _ := strconv.ParseFloat(r.PorcentajeRetener, 64)
+				pct := strconv.FormatFloat(rate, 'f', -1, 64)
+				if !isVATWithholdingRate(pct) {
+					return fmt.Errorf("porcentaje de retención de IVA %s%% no reconocido", r.PorcentajeRetener)
+				}
+				value, err := decimal.NewFromString(r.ValorRetenido)
+				if err != nil {
+					return fmt.Errorf("compra %d: valor retenido inválido %q", p.TransactionID, r.ValorRetenido)
+				}
+				d.Withholdings[pct] = d.Withholdings[pct].Add(value)
+				d.TotalWithholdings = d.TotalWithholdings.Add(value)
+			}
+		}
+	}
+
+	// No se registran notas de crédito de proveedores: el valor neto es igual al bruto
+	d.PurchasesNet15 = d.PurchasesGross15
+	d.PurchasesNet0 = d.PurchasesGross0
+	d.PurchasesTax15 = d.PurchasesTax15.Round(2)
+	d.PurchasesGross = d.PurchasesGross15.Add(d.PurchasesGross0)
+	d.PurchasesNet = d.PurchasesNet15.Add(d.PurchasesNet0)
+	d.PurchasesTax = d.PurchasesTax15
+	return nil
+}
+
+// settleVAT compensa el impuesto generado con el crédito tributario del mes y el saldo del mes
+// anterior, y deja el crédito sobrante para el mes siguiente.
+func settleVAT(d *domain.VATDeclaration) {
+	d.TaxToSettle = d.SalesTax
+	d.ApplicableTaxCredit = d.PurchasesTax
+
+	difference := d.TaxToSettle.Sub(d.ApplicableTaxCredit)
+	if difference.IsPositive() {
+		d.TaxCaused = difference
+	} else {
+		d.TaxCredit = difference.Neg()
+	}
+
+	if d.TaxCaused.GreaterThan(d.PreviousCredit) {
+		d.VATPayable = d.TaxCaused.Sub(d.PreviousCredit)
+	} else {
+		d.CreditCarriedForward = d.PreviousCredit.Sub(d.TaxCaused)
+	}
+	d.CreditCarriedForward = d.CreditCarriedForward.Add(d.TaxCredit)
+	d.TotalPayable = d.VATPayable.Add(d.TotalWithholdings)
+}
+
+func isVATWithholdingRate(rate string) bool {
+	for _, w := range domain.VATWithholdingBoxes {
+		if w.Rate == rate {
+			return true
+		}
+	}
+	return false
+}
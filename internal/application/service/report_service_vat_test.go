@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateVATDeclaration(t *testing.T) {
+	ctx := context.Background()
+	issuer := &domain.Issuer{RUC: "1790012345001", BusinessName: "Compañía Andina Cía. Ltda."}
+	dec := func(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+	sales := []domain.TaxSaleDocument{
+		{ReceiptType: "01", SRIStatus: "AUTORIZADO", Subtotal15: 100, Subtotal0: 20, TaxAmount: 15, Amount: 135},
+		{ReceiptType: "01", SRIStatus: "AUTORIZADO", Subtotal15: 40, Subtotal0: 10, TaxAmount: 6, Amount: 56},
+		{ReceiptType: "04", SRIStatus: "AUTORIZADO", Subtotal15: 40, Subtotal0: 10, TaxAmount: 6, Amount: 56},
+		// Anulada en el portal del SRI: no se declara
+		{ReceiptType: "01", SRIStatus: "ANULADO", Subtotal15: 30, TaxAmount: 4.5, Amount: 34.5},
+	}
+
+	supplierInvoiceKey := "0302202601099123456700120020010000004588765432111"
+	cr := &sri.ComprobanteRetencion{}
+	cr.DocsSustento.DocSustento = []sri.DocSustento{{CodSustento: "01", CodDocSustento: "01", NumAutDocSustento: supplierInvoiceKey}}
+	cr.DocsSustento.DocSustento[0].Retenciones.Retencion = []sri.Retencion{
+		{Codigo: "1", CodigoRetencion: "312", BaseImponible: "800.00", PorcentajeRetener: "1.75", ValorRetenido: "14.00"},
+		{Codigo: "2", CodigoRetencion: "1", BaseImponible: "120.00", PorcentajeRetener: "30", ValorRetenido: "36.00"},
+	}
+	withholdingXML, err := sri.MarshalComprobanteRetencion(cr)
+	require.NoError(t, err)
+
+	purchases := []domain.TaxPurchaseDocument{
+		{TransactionID: 20, AccessKey: supplierInvoiceKey, Subtotal15: 800, TaxAmount: 120, Amount: 920, WithholdingXML: string(withholdingXML)},
+		{TransactionID: 21, Subtotal0: 80, Amount: 80},
+	}
+
+	newService := func() (*ReportServiceImpl, *mocks.MockTaxReportRepository) {
+		taxRepo := new(mocks.MockTaxReportRepository)
+		issuerRepo := new(mocks.MockIssuerRepository)
+		issuerRepo.On("GetActive", ctx).Return(issuer, nil)
+		return NewReportService(nil, nil, nil, taxRepo, issuerRepo, nil, nil), taxRepo
+	}
+
+	t.Run("Nets credit notes and carries the tax credit forward", func(t *testing.T) {
+		svc, taxRepo := newService()
+		start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)
+		end := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+		taxRepo.On("GetSaleDocuments", ctx, start, end).Return(sales, nil)
+		taxRepo.On("GetPurchaseDocuments", ctx, start, end).Return(purchases, nil)
+
+		d, err := svc.GenerateVATDeclaration(ctx, 2026, time.February, dec("10"))
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, d.SalesCount)
+		assert.Equal(t, 1, d.CreditNoteCount)
+		assert.True(t, d.SalesGross15.Equal(dec("140")))
+		assert.True(t, d.SalesNet15.Equal(dec("100")))
+		assert.True(t, d.SalesNet0.Equal(dec("20")))
+		assert.True(t, d.SalesNet.Equal(dec("120")))
+		assert.True(t, d.SalesTax.Equal(dec("15")))
+
+		assert.Equal(t, 2, d.PurchaseCount)
+		assert.True(t, d.PurchasesNet.Equal(dec("880")))
+		assert.True(t, d.PurchasesTax.Equal(dec("120")))
+
+		// 15 generado - 120 de crédito del mes + 10 del mes anterior
+		assert.True(t, d.TaxCaused.IsZero())
+		assert.True(t, d.TaxCredit.Equal(dec("105")))
+		assert.True(t, d.CreditCarriedForward.Equal(dec("115")))
+		assert.True(t, d.VATPayable.IsZero())
+
+		assert.True(t, d.Withholdings["30"].Equal(dec("36")))
+		assert.True(t, d.TotalWithholdings.Equal(dec("36")))
+		assert.True(t, d.TotalPayable.Equal(dec("36")))
+	})
+
+	t.Run("Applies the previous credit to the tax caused", func(t *testing.T) {
+		svc, taxRepo := newService()
+		taxRepo.On("GetSaleDocuments", ctx, mock.Anything, mock.Anything).Return(sales[:1], nil)
+		taxRepo.On("GetPurchaseDocuments", ctx, mock.Anything, mock.Anything).Return([]domain.TaxPurchaseDocument{
+			{TransactionID: 30, Subtotal15: 40, TaxAmount: 6, Amount: 46},
+		}, nil)
+
+		d, err := svc.GenerateVATDeclaration(ctx, 2026, time.March, dec("5"))
+		require.NoError(t, err)
+
+		assert.True(t, d.TaxCaused.Equal(dec("9")))
+		assert.True(t, d.VATPayable.Equal(dec("4")))
+		assert.True(t, d.CreditCarriedForward.IsZero())
+		assert.True(t, d.TotalPayable.Equal(dec("4")))
+
+		var codes []string
+		for _, section := range d.Sections() {
+			for _, box := range section.Boxes {
+				codes = append(codes, box.Code)
+			}
+		}
+		assert.Contains(t, codes, "605")
+		assert.Contains(t, codes, "859")
+	})
+
+	t.Run("Rejects a negative previous credit", func(t *testing.T) {
+		svc, _ := newService()
+		_, err := svc.GenerateVATDeclaration(ctx, 2026, time.March, dec("-1"))
+		assert.ErrorContains(t, err, "negativo")
+	})
+}
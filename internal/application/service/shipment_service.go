@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+type ShipmentService struct {
+	repo       ShipmentRepository
+	txRepo     TransactionRepository
+	clientRepo TaxPayerRepository
+}
+
+func NewShipmentService(repo ShipmentRepository, txRepo TransactionRepository, clientRepo TaxPayerRepository) *ShipmentService {
+	return &ShipmentService{repo: repo, txRepo: txRepo, clientRepo: clientRepo}
+}
+
+func (s *ShipmentService) GetShipmentByID(ctx context.Context, id int) (*domain.Shipment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ShipmentService) GetAllShipments(ctx context.Context) ([]domain.Shipment, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// CreateShipment valida y guarda el envío. La guía se emite después con SriService.EmitirGuiaRemision.
+func (s *ShipmentService) CreateShipment(ctx context.Context, shipment *domain.Shipment, currentUser domain.User) error {
+	if shipment == nil {
+		return errors.New("el envío no puede ser nulo")
+	}
+	if shipment.CarrierID == 0 {
+		return errors.New("debe seleccionar el transportista")
+	}
+	if strings.TrimSpace(shipment.Plate) == "" {
+		return errors.New("la placa del vehículo es obligatoria")
+	}
+	if strings.TrimSpace(shipment.DepartureAddress) == "" {
+		return errors.New("la dirección de partida es obligatoria")
+	}
+	if shipment.StartDate.IsZero() || shipment.EndDate.IsZero() {
+		return errors.New("las fechas de transporte son obligatorias")
+	}
+	if shipment.EndDate.Before(shipment.StartDate) {
+		return errors.New("la fecha fin de transporte no puede ser anterior a la fecha de inicio")
+	}
+	if len(shipment.Recipients) == 0 {
+		return errors.New("el envío debe tener al menos un destinatario")
+	}
+	for i, rcp := range shipment.Recipients {
+		if rcp.TaxPayerID == 0 {
+			return fmt.Errorf("destinatario %d: seleccione el destinatario", i+1)
+		}
+		if strings.TrimSpace(rcp.ArrivalAddress) == "" || strings.TrimSpace(rcp.Reason) == "" {
+			return fmt.Errorf("destinatario %d: la dirección de llegada y el motivo son obligatorios", i+1)
+		}
+		if len(rcp.Items) == 0 {
+			return fmt.Errorf("destinatario %d: agregue al menos un ítem", i+1)
+		}
+		for _, item := range rcp.Items {
+			if strings.TrimSpace(item.Description) == "" || item.Quantity <= 0 {
+				return fmt.Errorf("destinatario %d: los ítems requieren descripción y cantidad mayor a cero", i+1)
+			}
+		}
+	}
+
+	shipment.CreatedByID = currentUser.ID
+	return s.repo.Create(ctx, shipment)
+}
+
+// NewShipmentFromTransaction prepara (sin guardar) un envío a partir de una factura autorizada:
+// el cliente pasa a ser el destinatario y los ítems facturados se copian como mercadería.
+func (s *ShipmentService) NewShipmentFromTransaction(ctx context.Context, transactionID int) (*domain.Shipment, error) {
+	tx, err := s.txRepo.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo transacción: %w", err)
+	}
+	if tx.Category == nil || tx.Category.Type != domain.Income {
+		return nil, errors.New("solo se pueden generar guías de remisión desde facturas de venta")
+	}
+	if tx.TaxPayerID == nil {
+		return nil, errors.New("la factura no tiene cliente asignado")
+	}
+
+	client, err := s.clientRepo.GetByID(ctx, *tx.TaxPayerID)
+	if err != nil || client == nil {
+		return nil, errors.New("no se encontró el cliente de la factura")
+	}
+
+	items, err := s.txRepo.GetItemsByTransactionID(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("error cargando ítems de la factura: %w", err)
+	}
+
+	recipient := domain.ShipmentRecipient{
+		TaxPayerID:     client.ID,
+		TaxPayer:       client,
+		ArrivalAddress: client.Address,
+		Reason:         "Venta",
+	}
+	for _, item := range items {
+		recipient.Items = append(recipient.Items, domain.ShipmentItem{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	// La factura autorizada es el documento sustento del traslado
+	if r := tx.ElectronicReceipt; r != nil && r.ReceiptType == "01" && r.SRIStatus == "AUTORIZADO" {
+		if key, err := sri.ParseAccessKey(r.AccessKey); err == nil {
+			docDate := tx.TransactionDate
+			recipient.SupportDocNumber = key.DocumentNumber()
+			recipient.SupportDocDate = &docDate
+			recipient.SupportDocAuthKey = key.Raw
+		}
+	}
+
+	txID := tx.ID
+	today := time.Now()
+	return &domain.Shipment{
+		TransactionID: &txID,
+		StartDate:     today,
+		EndDate:       today,
+		Recipients:    []domain.ShipmentRecipient{recipient},
+	}, nil
+}
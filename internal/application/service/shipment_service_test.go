@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateShipment(t *testing.T) {
+	ctx := context.Background()
+	user := domain.User{BaseEntity: domain.BaseEntity{ID: 7}}
+
+	validShipment := func() *domain.Shipment {
+		return &domain.Shipment{
+			CarrierID:        3,
+			Plate:            "PBA-1234",
+			DepartureAddress: "Bodega Norte",
+			StartDate:        time.Now(),
+			EndDate:          time.Now().Add(24 * time.Hour),
+			Recipients: []domain.ShipmentRecipient{
+				{TaxPayerID: 5, ArrivalAddress: "Av. Principal", Reason: "Venta", Items: []domain.ShipmentItem{{Description: "Cemento", Quantity: 10}}},
+			},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockShipmentRepository)
+		svc := service.NewShipmentService(mockRepo, nil, nil)
+
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(s *domain.Shipment) bool { return s.CreatedByID == 7 })).Return(nil).Once()
+
+		err := svc.CreateShipment(ctx, validShipment(), user)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Fecha fin anterior al inicio", func(t *testing.T) {
+		mockRepo := new(mocks.MockShipmentRepository)
+		svc := service.NewShipmentService(mockRepo, nil, nil)
+
+		s := validShipment()
+		s.EndDate = s.StartDate.Add(-24 * time.Hour)
+
+		err := svc.CreateShipment(ctx, s, user)
+		assert.ErrorContains(t, err, "fecha fin")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail - Destinatario sin ítems", func(t *testing.T) {
+		mockRepo := new(mocks.MockShipmentRepository)
+		svc := service.NewShipmentService(mockRepo, nil, nil)
+
+		s := validShipment()
+		s.Recipients[0].Items = nil
+
+		err := svc.CreateShipment(ctx, s, user)
+		assert.ErrorContains(t, err, "al menos un ítem")
+	})
+}
+
+func TestNewShipmentFromTransaction(t *testing.T) {
+	ctx := context.Background()
+	clientID := 5
+
+	mockTxRepo := new(mocks.MockTransactionRepository)
+	mockClientRepo := new(mocks.MockTaxPayerRepository)
+	svc := service.NewShipmentService(new(mocks.MockShipmentRepository), mockTxRepo, mockClientRepo)
+
+	invoiceDate := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	mockTxRepo.On("GetTransactionByID", ctx, 10).Return(&domain.Transaction{
+		BaseEntity:      domain.BaseEntity{ID: 10},
+		TransactionDate: invoiceDate,
+		TaxPayerID:      &clientID,
+		Category:        &domain.Category{Type: domain.Income},
+		ElectronicReceipt: &domain.ElectronicReceipt{
+			ReceiptType: "01",
+			SRIStatus:   "AUTORIZADO",
+			AccessKey:   "1002202601179000000000110010020000000771234567813",
+		},
+	}, nil).Once()
+	mockClientRepo.On("GetByID", ctx, clientID).Return(&domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: clientID}, Name: "Cliente", Address: "Calle 1"}, nil).Once()
+	mockTxRepo.On("GetItemsByTransactionID", ctx, 10).Return([]domain.TransactionItem{
+		{Description: "Cemento", Quantity: 10, UnitPrice: 8},
+		{Description: "Varilla", Quantity: 4, UnitPrice: 12},
+	}, nil).Once()
+
+	shipment, err := svc.NewShipmentFromTransaction(ctx, 10)
+	require.NoError(t, err)
+	require.NotNil(t, shipment.TransactionID)
+	assert.Equal(t, 10, *shipment.TransactionID)
+	require.Len(t, shipment.Recipients, 1)
+
+	rcp := shipment.Recipients[0]
+	assert.Equal(t, clientID, rcp.TaxPayerID)
+	assert.Equal(t, "Calle 1", rcp.ArrivalAddress)
+	assert.Equal(t, "001-002-000000077", rcp.SupportDocNumber)
+	assert.Equal(t, invoiceDate, *rcp.SupportDocDate)
+	require.Len(t, rcp.Items, 2)
+	assert.Equal(t, 4.0, rcp.Items[1].Quantity)
+}
@@ -28,6 +28,7 @@ type MailService interface {
 type DocumentSigner interface {
 	Sign(xmlBytes []byte, algo signer.HashAlgorithm) ([]byte, error)
 	SignCreditNote(xmlBytes []byte, algo signer.HashAlgorithm) ([]byte, error)
+	SignDocument(xmlBytes []byte, algo signer.HashAlgorithm) ([]byte, error)
 }
 
 type SriService struct {
@@ -36,11 +37,23 @@ type SriService struct {
 	receiptRepo   ElectronicReceiptRepository
 	clientRepo    TaxPayerRepository
 	epRepo        EmissionPointRepository // Added
+	shipmentRepo  ShipmentRepository
+	taxRateRepo   TaxRateRepository
 	sriClient     sri.Client
 	rideGen       *sri.RideGenerator
 	mailService   MailService
 	logger        *log.Logger
 	signerFactory func(path, password string) DocumentSigner
+
+	// AuthorizationDelay es la espera entre el envío y la primera consulta de autorización,
+	// para dar tiempo al SRI de procesar el comprobante.
+	AuthorizationDelay time.Duration
+	// BatchThreshold es la cantidad de comprobantes sin enviar a partir de la cual la sincronización
+	// los envía en lotes. Cero desactiva los lotes.
+	BatchThreshold int
+	// BatchPolls es la cantidad de consultas de autorización de cada lote antes de dejar los
+	// comprobantes pendientes a la sincronización individual.
+	BatchPolls int
 }
 
 func NewSriService(
@@ -49,23 +62,30 @@ func NewSriService(
 	receiptRepo ElectronicReceiptRepository,
 	clientRepo TaxPayerRepository,
 	epRepo EmissionPointRepository, // Added
+	shipmentRepo ShipmentRepository,
+	taxRateRepo TaxRateRepository,
 	sriClient sri.Client,
 	mailService MailService,
 	logger *log.Logger,
 ) *SriService {
 	return &SriService{
-		txRepo:      txRepo,
-		issuerRepo:  issuerRepo,
-		receiptRepo: receiptRepo,
-		clientRepo:  clientRepo,
-		epRepo:      epRepo, // Added
-		sriClient:   sriClient,
-		mailService: mailService,
-		rideGen:     sri.NewRideGenerator(),
-		logger:      logger,
+		txRepo:       txRepo,
+		issuerRepo:   issuerRepo,
+		receiptRepo:  receiptRepo,
+		clientRepo:   clientRepo,
+		epRepo:       epRepo, // Added
+		shipmentRepo: shipmentRepo,
+		taxRateRepo:  taxRateRepo,
+		sriClient:    sriClient,
+		mailService:  mailService,
+		rideGen:      sri.NewRideGenerator(),
+		logger:       logger,
 		signerFactory: func(path, password string) DocumentSigner {
 			return sri.NewDocumentSigner(path, password)
 		},
+		AuthorizationDelay: 3 * time.Second,
+		BatchThreshold:     10,
+		BatchPolls:         3,
 	}
 }
 
@@ -127,37 +147,72 @@ func (s *SriService) GenerateRide(ctx context.Context, transactionID int) (strin
 		authDate = *tx.ElectronicReceipt.AuthorizationDate
 	}
 
-	if tx.ElectronicReceipt.ReceiptType == "04" {
+	if err := s.renderRide(tx.ElectronicReceipt.ReceiptType, xmlContent, outputPath, issuer.LogoPath, authDate, tx.ElectronicReceipt.AccessKey); err != nil {
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+// renderRide interpreta el XML según el tipo de comprobante y escribe su RIDE en outputPath.
+func (s *SriService) renderRide(receiptType, xmlContent, outputPath, logoPath string, authDate time.Time, accessKey string) error {
+	var err error
+	switch receiptType {
+	case "03":
+		var lc sri.LiquidacionCompra
+		if err := xml.Unmarshal([]byte(xmlContent), &lc); err != nil {
+			return fmt.Errorf("error al leer XML de Liquidación de Compra: %w", err)
+		}
+		err = s.rideGen.GenerateLiquidacionCompraRide(&lc, outputPath, logoPath, authDate, accessKey)
+	case "04":
 		var nc sri.NotaCredito
 		if err := xml.Unmarshal([]byte(xmlContent), &nc); err != nil {
-			return "", fmt.Errorf("error al leer XML de Nota de Crédito: %w", err)
+			return fmt.Errorf("error al leer XML de Nota de Crédito: %w", err)
 		}
-		err = s.rideGen.GenerateNotaCreditoRide(&nc, outputPath, issuer.LogoPath, authDate, tx.ElectronicReceipt.AccessKey)
-	} else {
+		err = s.rideGen.GenerateNotaCreditoRide(&nc, outputPath, logoPath, authDate, accessKey)
+	case "05":
+		var nd sri.NotaDebito
+		if err := xml.Unmarshal([]byte(xmlContent), &nd); err != nil {
+			return fmt.Errorf("error al leer XML de Nota de Débito: %w", err)
+		}
+		err = s.rideGen.GenerateNotaDebitoRide(&nd, outputPath, logoPath, authDate, accessKey)
+	case "06":
+		var gr sri.GuiaRemision
+		if err := xml.Unmarshal([]byte(xmlContent), &gr); err != nil {
+			return fmt.Errorf("error al leer XML de Guía de Remisión: %w", err)
+		}
+		err = s.rideGen.GenerateGuiaRemisionRide(&gr, outputPath, logoPath, authDate, accessKey)
+	case "07":
+		var cr sri.ComprobanteRetencion
+		if err := xml.Unmarshal([]byte(xmlContent), &cr); err != nil {
+			return fmt.Errorf("error al leer XML de Retención: %w", err)
+		}
+		err = s.rideGen.GenerateRetencionRide(&cr, outputPath, logoPath, authDate, accessKey)
+	default:
 		var factura sri.Factura
 		if err := xml.Unmarshal([]byte(xmlContent), &factura); err != nil {
-			return "", fmt.Errorf("error al leer XML de Factura: %w", err)
+			return fmt.Errorf("error al leer XML de Factura: %w", err)
 		}
-		err = s.rideGen.GenerateFacturaRide(&factura, outputPath, issuer.LogoPath, authDate, tx.ElectronicReceipt.AccessKey)
+		err = s.rideGen.GenerateFacturaRide(&factura, outputPath, logoPath, authDate, accessKey)
 	}
 
 	if err != nil {
-		return "", fmt.Errorf("error generando PDF: %w", err)
+		return fmt.Errorf("error generando PDF: %w", err)
 	}
-
-	return outputPath, nil
+	return nil
 }
 
 // SyncReceipt verifica el estado de un comprobante pendiente y avanza el flujo si es necesario.
 // Retorna el nuevo estado y un error si ocurrió.
 func (s *SriService) SyncReceipt(ctx context.Context, receipt *domain.ElectronicReceipt) (string, error) {
 	// 1. Si nunca se envió exitosamente o falló el envío, intentamos enviar de nuevo
-	if receipt.SRIStatus == "PENDIENTE" || receipt.SRIStatus == "ERROR_ENVIO" || receipt.SRIStatus == "ERROR_RED" {
-		respRecepcion, err := s.sriClient.EnviarComprobante([]byte(receipt.XMLContent), receipt.Environment)
+	if isUnsent(receipt.SRIStatus) {
+		respRecepcion, err := s.sriClient.EnviarComprobante(ctx, []byte(receipt.XMLContent), receipt.Environment)
 		if err != nil {
 			// Sigue fallando la red/envío
-			_ = s.receiptRepo.UpdateStatus(ctx, receipt.AccessKey, "ERROR_RED", err.Error(), nil)
-			return "ERROR_RED", err
+			status := sendErrorStatus(err)
+			s.recordStatus(ctx, receipt.AccessKey, status, err.Error(), nil)
+			return string(status), err
 		}
 
 		if respRecepcion.Estado == "DEVUELTA" {
@@ -168,64 +223,65 @@ func (s *SriService) SyncReceipt(ctx context.Context, receipt *domain.Electronic
 					msg = fmt.Sprintf("%s: %s", msgs[0].Identificador, msgs[0].Mensaje)
 				}
 			}
-			_ = s.receiptRepo.UpdateStatus(ctx, receipt.AccessKey, "DEVUELTA", msg, nil)
+			s.recordStatus(ctx, receipt.AccessKey, domain.ReceiptReturned, msg, nil)
 			return "DEVUELTA", fmt.Errorf("%s", msg)
 		}
 
 		// Si pasó a RECIBIDA, actualizamos y seguimos al paso de autorización
-		_ = s.receiptRepo.UpdateStatus(ctx, receipt.AccessKey, "RECIBIDA", "Recibido por SRI", nil)
-		receipt.SRIStatus = "RECIBIDA"
+		s.recordStatus(ctx, receipt.AccessKey, domain.ReceiptReceived, "Recibido por SRI", nil)
+		receipt.SRIStatus = domain.ReceiptReceived
 	}
 
 	// 2. Si ya fue recibida, consultamos autorización
-	if receipt.SRIStatus == "RECIBIDA" || receipt.SRIStatus == "EN PROCESO" {
-		respAuth, err := s.sriClient.AutorizarComprobante(receipt.AccessKey, receipt.Environment)
+	if receipt.SRIStatus == domain.ReceiptReceived || receipt.SRIStatus == domain.ReceiptProcessing {
+		respAuth, err := s.sriClient.AutorizarComprobante(ctx, receipt.AccessKey, receipt.Environment)
 		if err != nil {
-			return receipt.SRIStatus, err // Error de red al consultar, mantenemos estado
+			return string(receipt.SRIStatus), err // Error de red al consultar, mantenemos estado
 		}
 
 		if len(respAuth.Autorizaciones.Autorizacion) > 0 {
-			auth := respAuth.Autorizaciones.Autorizacion[0]
-			authDate, _ := time.Parse(time.RFC3339, auth.FechaAutorizacion)
-
-			switch auth.Estado {
-
-			case "AUTORIZADO":
-
-				_ = s.receiptRepo.UpdateStatus(ctx, receipt.AccessKey, "AUTORIZADO", "Autorización Exitosa", &authDate)
-
-				receipt.SRIStatus = "AUTORIZADO"
-
-				receipt.AuthorizationDate = &authDate
-
-				// 3. Generar RIDE y enviar email
-				go func() {
-					if err := s.finalizeAndEmail(context.Background(), receipt); err != nil {
-						s.logger.Printf("Error procesando comprobante %s en segundo plano: %v", receipt.AccessKey, err)
-					}
-				}()
-
-				return "AUTORIZADO", nil
-
-			case "NO AUTORIZADO", "RECHAZADA", "RECHAZADO":
-
-				msg := "Rechazado"
+			status, err := s.applyAuthorization(ctx, receipt, &respAuth.Autorizaciones.Autorizacion[0])
+			return string(status), err
+		}
+		// Si no hay respuesta de autorización pero tampoco error, el SRI sigue procesando
+		// Forzamos estado EN PROCESO para que el siguiente ciclo no lo ignore si estaba en RECIBIDA
+		s.recordStatus(ctx, receipt.AccessKey, domain.ReceiptProcessing, "SRI procesando autorización...", nil)
+		return "EN PROCESO", nil
+	}
 
-				if len(auth.Mensajes.Mensaje) > 0 {
-					msg = auth.Mensajes.Mensaje[0].Mensaje
-				}
+	return string(receipt.SRIStatus), nil
+}
 
-				_ = s.receiptRepo.UpdateStatus(ctx, receipt.AccessKey, "RECHAZADA", msg, &authDate)
+// applyAuthorization registra la respuesta de autorización del SRI para el comprobante y devuelve su
+// nuevo estado. Un rechazo devuelve además el motivo como error; EN PROCESO deja el estado actual.
+func (s *SriService) applyAuthorization(ctx context.Context, receipt *domain.ElectronicReceipt, auth *sri.Autorizacion) (domain.ReceiptStatus, error) {
+	authDate, _ := time.Parse(time.RFC3339, auth.FechaAutorizacion)
+
+	status, _ := domain.ReceiptStatusFromSRI(auth.Estado)
+	switch status {
+	case domain.ReceiptAuthorized:
+		s.recordStatus(ctx, receipt.AccessKey, domain.ReceiptAuthorized, "Autorización Exitosa", &authDate)
+		receipt.SRIStatus = domain.ReceiptAuthorized
+		receipt.AuthorizationDate = &authDate
+
+		// 3. Generar RIDE y enviar email
+		go func() {
+			if err := s.finalizeAndEmail(context.Background(), receipt); err != nil {
+				s.logger.Printf("Error procesando comprobante %s en segundo plano: %v", receipt.AccessKey, err)
+			}
+		}()
 
-				return "RECHAZADA", fmt.Errorf("%s", msg)
+		return domain.ReceiptAuthorized, nil
 
-			}
-		} else {
-			// Si no hay respuesta de autorización pero tampoco error, el SRI sigue procesando
-			// Forzamos estado EN PROCESO para que el siguiente ciclo no lo ignore si estaba en RECIBIDA
-			_ = s.receiptRepo.UpdateStatus(ctx, receipt.AccessKey, "EN PROCESO", "SRI procesando autorización...", nil)
-			return "EN PROCESO", nil
+	case domain.ReceiptRejected:
+		msg := "Rechazado"
+		if len(auth.Mensajes.Mensaje) > 0 {
+			msg = auth.Mensajes.Mensaje[0].Mensaje
 		}
+		s.recordStatus(ctx, receipt.AccessKey, domain.ReceiptRejected, msg, &authDate)
+		receipt.SRIStatus = domain.ReceiptRejected
+
+		return domain.ReceiptRejected, fmt.Errorf("%s", msg)
 	}
 
 	return receipt.SRIStatus, nil
@@ -240,11 +296,22 @@ func (s *SriService) ProcessBackgroundSync(ctx context.Context) (int, error) {
 	}
 
 	authorizedCount := 0
+
+	// Los comprobantes sin enviar se mandan en lotes cuando se acumularon varios (ej. tras una caída del SRI)
+	pending, authorizedCount, err = s.syncBatches(ctx, pending)
+	if errors.Is(err, sri.ErrCircuitOpen) {
+		return authorizedCount, nil
+	}
+
 	for _, r := range pending {
 		oldStatus := r.SRIStatus
 		newStatus, err := s.SyncReceipt(ctx, &r)
 		if err != nil {
 			s.logger.Printf("Error sincronizando comprobante %s: %v", r.AccessKey, err)
+			if errors.Is(err, sri.ErrCircuitOpen) {
+				// El SRI está caído: el resto de la cola espera al próximo ciclo
+				break
+			}
 			continue
 		}
 
@@ -261,6 +328,19 @@ func (s *SriService) GetPendingQueue(ctx context.Context) ([]domain.ElectronicRe
 	return s.receiptRepo.FindPendingReceipts(ctx)
 }
 
+// GetReceiptEvents devuelve la historia del comprobante con esa clave: su creación, cada envío al
+// SRI con la respuesta obtenida y cada re-emisión, incluidas las que usaron claves anteriores.
+func (s *SriService) GetReceiptEvents(ctx context.Context, accessKey string) ([]domain.ReceiptEvent, error) {
+	receipt, err := s.receiptRepo.GetByAccessKey(ctx, accessKey)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, fmt.Errorf("no se encontró el comprobante %s", accessKey)
+	}
+	return s.receiptRepo.GetEvents(ctx, receipt.ID)
+}
+
 // EmitirFactura orquesta el proceso completo de facturación electrónica.
 func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signaturePassword string) error {
 	s.logger.Printf("Iniciando emisión de factura para transacción ID: %d", transactionID)
@@ -281,14 +361,23 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 	if tx.Category.Type == domain.Outcome {
 		return errors.New("no se pueden generar facturas de venta para transacciones de egreso (gastos)")
 	}
+	if tx.RelatedTransactionID != nil {
+		return errors.New("la transacción corresponde a una nota de débito; emítala desde la factura original")
+	}
 
-	issuer, err := s.issuerRepo.GetActive(ctx)
-	if err != nil || issuer == nil {
+	activeIssuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil || activeIssuer == nil {
 		return errors.New("no hay un emisor activo configurado")
 	}
+	establishmentCode, emissionPointCode := transactionPoint(tx)
+	issuer, err := s.issuerAtPoint(ctx, activeIssuer, establishmentCode, emissionPointCode)
+	if err != nil {
+		return err
+	}
 
 	var claveAcceso string
 	var secuencialSRI string
+	var reused *domain.ElectronicReceipt
 	isNewReceipt := true
 
 	// 2. Determinar si reusamos o creamos clave nueva
@@ -296,10 +385,10 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 		status := tx.ElectronicReceipt.SRIStatus
 
 		// Detectar zombies: EN PROCESO por más de 2 horas
-		isStuck := status == "EN PROCESO" && tx.ElectronicReceipt.CreatedAt.Add(2*time.Hour).Before(time.Now())
+		isStuck := tx.ElectronicReceipt.IsStuck(time.Now())
 
 		// Si falló definitivamente O está trabada, FORZAMOS nueva clave
-		if status == "NO AUTORIZADO" || status == "RECHAZADA" || status == "DEVUELTA" || isStuck {
+		if tx.ElectronicReceipt.CanReissue(time.Now()) {
 			isNewReceipt = true
 			if isStuck {
 				s.logger.Printf("Transacción trabada (%s desde %v). Forzando nueva Clave de Acceso.", status, tx.ElectronicReceipt.CreatedAt)
@@ -307,20 +396,59 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 				s.logger.Printf("Anterior falló (%s). Forzando nueva Clave de Acceso.", status)
 			}
 		} else {
-			claveAcceso = tx.ElectronicReceipt.AccessKey
-			secuencialSRI = claveAcceso[30:39]
+			// Solo se reenvía con la misma clave lo que el SRI todavía no recibió; lo recibido,
+			// en proceso o autorizado se consulta con la sincronización, sin volver a firmar.
+			if !isUnsent(status) {
+				return fmt.Errorf("la factura ya fue enviada al SRI (estado %s); sincronícela en lugar de volver a emitirla", status)
+			}
+			key, err := sri.ParseAccessKey(tx.ElectronicReceipt.AccessKey)
+			if err != nil {
+				return fmt.Errorf("la factura registrada tiene una clave de acceso dañada: %w", err)
+			}
+			reused, err = s.receiptRepo.GetByAccessKey(ctx, key.Raw)
+			if err != nil {
+				return fmt.Errorf("error obteniendo la factura registrada: %w", err)
+			}
+			if reused == nil {
+				return fmt.Errorf("la factura con clave %s no está registrada", key.Raw)
+			}
+			if !isUnsent(reused.SRIStatus) {
+				return fmt.Errorf("la factura ya fue enviada al SRI (estado %s); sincronícela en lugar de volver a emitirla", reused.SRIStatus)
+			}
+			claveAcceso = key.Raw
+			secuencialSRI = key.Sequential
 			isNewReceipt = false
+			// La re-emisión conserva el punto de emisión con el que se generó la clave
+			if issuer, err = s.issuerAtPoint(ctx, activeIssuer, key.Establishment, key.EmissionPoint); err != nil {
+				return err
+			}
 			s.logger.Printf("Reusando Clave de Acceso: %s", claveAcceso)
 		}
 	}
+	// Ítems y formas de pago van en el XML tanto con clave nueva como al reenviar con la misma
+	items, err := s.txRepo.GetItemsByTransactionID(ctx, transactionID)
+	if err != nil {
+		return fmt.Errorf("error cargando ítems: %w", err)
+	}
+	tx.Items = items
+
+	payments, err := s.txRepo.GetPaymentsByTransactionID(ctx, transactionID)
+	if err != nil {
+		return fmt.Errorf("error cargando formas de pago: %w", err)
+	}
+	if err := validatePayments(payments, tx.Amount); err != nil {
+		return err
+	}
+	tx.Payments = payments
+
+	fields, err := s.txRepo.GetAdditionalFieldsByTransactionID(ctx, transactionID)
+	if err != nil {
+		return fmt.Errorf("error cargando campos adicionales: %w", err)
+	}
+	tx.AdditionalFields = fields
+
 	if isNewReceipt {
 		// --- Lógica de Generación de Nuevo Secuencial ---
-		// ... (Carga de items, cliente, etc.)
-		items, err := s.txRepo.GetItemsByTransactionID(ctx, transactionID)
-		if err == nil {
-			tx.Items = items
-		}
-
 		var client *domain.TaxPayer
 		if tx.TaxPayerID != nil {
 			client, _ = s.clientRepo.GetByID(ctx, *tx.TaxPayerID)
@@ -328,37 +456,24 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 		if client == nil {
 			client, _ = s.clientRepo.GetByIdentification(ctx, "9999999999999")
 			if client == nil {
-				client = &domain.TaxPayer{Identification: "9999999999999", Name: "CONSUMIDOR FINAL", Email: ""}
+				client = &domain.TaxPayer{Identification: "9999999999999", IdentificationType: "07", Name: "CONSUMIDOR FINAL", Email: ""}
 				_ = s.clientRepo.Create(ctx, client)
 			}
 		}
 
-		ep, err := s.epRepo.GetByPoint(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode, "01")
-		if err != nil {
+		// Antes de reservar el secuencial, para no consumirlo en un comprobante que el SRI rechazaría
+		if err := validateAdditionalFieldCount(mergeAdditionalFields(client, issuer, tx.AdditionalFields)); err != nil {
 			return err
 		}
-		if ep == nil {
-			ep = &domain.EmissionPoint{IssuerID: issuer.ID, EstablishmentCode: issuer.EstablishmentCode, EmissionPointCode: issuer.EmissionPointCode, ReceiptType: "01", CurrentSequence: 0, IsActive: true}
-			if err := s.epRepo.Create(ctx, ep); err != nil {
-				return fmt.Errorf("error al crear punto de emisión inicial: %w", err)
-			}
-		}
-		if err := s.epRepo.IncrementSequence(ctx, ep.ID); err != nil {
+		if err := s.validateFacturaDraft(ctx, tx, issuer, client); err != nil {
 			return err
 		}
 
-		// Refrescamos para obtener el secuencial actualizado por la base de datos (considerando InitialSequence)
-		ep, err = s.epRepo.GetByPoint(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode, "01")
+		secuencialSRI, err = s.nextSequential(ctx, issuer, "01")
 		if err != nil {
-			return fmt.Errorf("error al refrescar punto de emisión: %w", err)
+			return err
 		}
-		secuencialSRI = fmt.Sprintf("%09d", ep.CurrentSequence)
-
-		// Generación de Código Numérico Seguro
-		// Usamos crypto/rand para evitar colisiones de claves en reinicios
-		nSafe, _ := rand.Int(rand.Reader, big.NewInt(100000000))
-		numericCode := fmt.Sprintf("%08d", nSafe.Int64())
-		claveAcceso = sri.GenerateAccessKey(tx.TransactionDate, "01", issuer.RUC, issuer.Environment, issuer.EstablishmentCode, issuer.EmissionPointCode, secuencialSRI, numericCode, 1)
+		claveAcceso = sri.GenerateAccessKey(tx.TransactionDate, "01", issuer.RUC, issuer.Environment, issuer.EstablishmentCode, issuer.EmissionPointCode, secuencialSRI, newNumericCode(), 1)
 	}
 
 	// 3. Generar y Firmar XML
@@ -371,7 +486,7 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 		clientMapping, _ = s.clientRepo.GetByIdentification(ctx, "9999999999999")
 		// Si aún así es nil, crearlo (caso extremo)
 		if clientMapping == nil {
-			clientMapping = &domain.TaxPayer{Identification: "9999999999999", Name: "CONSUMIDOR FINAL", Email: ""}
+			clientMapping = &domain.TaxPayer{Identification: "9999999999999", IdentificationType: "07", Name: "CONSUMIDOR FINAL", Email: ""}
 			_ = s.clientRepo.Create(ctx, clientMapping)
 		}
 	}
@@ -387,11 +502,25 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 	}
 	s.logger.Printf("DEBUG: clientMapping ID antes de crear recibo: %d", clientMapping.ID)
 
-	facturaXML := s.mapTransactionToFactura(tx, issuer, clientMapping, claveAcceso, secuencialSRI)
+	// Con clave nueva ya se validó antes de reservar el secuencial
+	if !isNewReceipt {
+		if err := validateAdditionalFieldCount(mergeAdditionalFields(clientMapping, issuer, tx.AdditionalFields)); err != nil {
+			return err
+		}
+	}
+
+	taxes, err := s.taxSummary(ctx, tx, tx.TransactionDate)
+	if err != nil {
+		return err
+	}
+	facturaXML := s.mapTransactionToFactura(tx, taxes, issuer, clientMapping, claveAcceso, secuencialSRI)
 	xmlBytes, err := sri.MarshalFactura(facturaXML)
 	if err != nil {
 		return err
 	}
+	if err := sri.ValidateFactura(xmlBytes); err != nil {
+		return err
+	}
 
 	// 6. Firmar XML Real usando el paquete propio
 	s.logger.Printf("Firmando XML...")
@@ -400,14 +529,7 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 	signedXML, err := signerObj.Sign(xmlBytes, sri.SHA1)
 	if err != nil {
 		s.logger.Printf("ERROR CRÍTICO AL FIRMAR: %v", err)
-		errStr := err.Error()
-		if strings.Contains(errStr, "no such file") || strings.Contains(errStr, "system cannot find") {
-			return fmt.Errorf("no se encuentra el archivo de firma (.p12) en la ruta configurada. Verifique la configuración del emisor")
-		}
-		if strings.Contains(errStr, "password") || strings.Contains(errStr, "mac check failed") {
-			return fmt.Errorf("contraseña de firma incorrecta")
-		}
-		return fmt.Errorf("error técnico al firmar: %w", err)
+		return signatureError(err)
 	}
 
 	// Limpieza de seguridad post-firmado
@@ -419,23 +541,29 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 		receipt := &domain.ElectronicReceipt{
 			TransactionID: tx.ID, IssuerID: issuer.ID, TaxPayerID: clientMapping.ID,
 			AccessKey: claveAcceso, ReceiptType: "01", XMLContent: signedXMLStr,
-			SRIStatus: "PENDIENTE", Environment: issuer.Environment,
+			SRIStatus: domain.ReceiptPending, Environment: issuer.Environment,
 		}
 		receipt.CreatedAt = time.Now() // Fix: Set timestamp explicitly for UI logic
 		if err := s.receiptRepo.Create(ctx, receipt); err != nil {
 			return err
 		}
 	} else {
-		_ = s.receiptRepo.UpdateXML(ctx, claveAcceso, signedXMLStr)
-		_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "PENDIENTE", "Re-emisión corregida", nil)
-		_ = s.receiptRepo.UpdateTaxPayerID(ctx, claveAcceso, clientMapping.ID)
+		// XML, cliente y estado se guardan juntos y solo si el estado actual permite volver a PENDIENTE
+		reused.XMLContent = signedXMLStr
+		reused.TaxPayerID = clientMapping.ID
+		reused.SRIStatus = domain.ReceiptPending
+		reused.SRIMessage = "Re-emisión corregida"
+		reused.AuthorizationDate = nil
+		if err := s.receiptRepo.Update(ctx, reused); err != nil {
+			return fmt.Errorf("no se puede re-emitir la factura: %w", err)
+		}
 	}
 
 	// 6. Enviar al SRI
 	s.logger.Printf("Enviando al SRI (Ambiente: %d)...", issuer.Environment)
-	respRecepcion, err := s.sriClient.EnviarComprobante([]byte(signedXMLStr), issuer.Environment)
+	respRecepcion, err := s.sriClient.EnviarComprobante(ctx, []byte(signedXMLStr), issuer.Environment)
 	if err != nil {
-		_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "ERROR_RED", err.Error(), nil)
+		s.recordStatus(ctx, claveAcceso, sendErrorStatus(err), err.Error(), nil)
 		return err
 	}
 
@@ -446,38 +574,41 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 			m := respRecepcion.Comprobantes.Comprobante[0].Mensajes.Mensaje[0]
 			msg = m.Mensaje
 			if !strings.Contains(strings.ToUpper(msg), "EN PROCESAMIENTO") {
-				_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "DEVUELTA", msg, nil)
+				s.recordStatus(ctx, claveAcceso, domain.ReceiptReturned, msg, nil)
 				s.logger.Printf("CRITICAL SRI ERROR (DEVUELTA): %s - %s", m.Identificador, msg)
 				return fmt.Errorf("comprobante devuelto: %s", msg)
 			}
 			s.logger.Printf("La clave ya está en procesamiento, continuando a autorización...")
 		} else {
-			_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "DEVUELTA", msg, nil)
+			s.recordStatus(ctx, claveAcceso, domain.ReceiptReturned, msg, nil)
 			return fmt.Errorf("comprobante devuelto: %s", msg)
 		}
 	}
 
 	// 7. Consultar Autorización
-	time.Sleep(3 * time.Second)
+	if err := s.waitForAuthorization(ctx); err != nil {
+		s.logger.Printf("Consulta de autorización cancelada; se verificará en background: %v", err)
+		return nil
+	}
 	s.logger.Printf("Consultando autorización...")
-	respAuth, err := s.sriClient.AutorizarComprobante(claveAcceso, issuer.Environment)
+	respAuth, err := s.sriClient.AutorizarComprobante(ctx, claveAcceso, issuer.Environment)
 	if err == nil && len(respAuth.Autorizaciones.Autorizacion) > 0 {
 		auth := respAuth.Autorizaciones.Autorizacion[0]
 		authDate, _ := time.Parse(time.RFC3339, auth.FechaAutorizacion)
 		s.logger.Printf("Estado final SRI: %s", auth.Estado)
 
-		msg := "Procesado"
-		if auth.Estado != "AUTORIZADO" && len(auth.Mensajes.Mensaje) > 0 {
-			m := auth.Mensajes.Mensaje[0]
-			msg = fmt.Sprintf("%s: %s (%s)", m.Identificador, m.Mensaje, m.InformacionAdicional)
+		msg := authorizationMessage(auth)
+		if auth.Estado != "AUTORIZADO" {
 			s.logger.Printf("MENSAJE SRI: %s", msg)
 		}
 
-		_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, auth.Estado, msg, &authDate)
+		if status, ok := domain.ReceiptStatusFromSRI(auth.Estado); ok {
+			s.recordStatus(ctx, claveAcceso, status, msg, &authDate)
+		}
 
 		if auth.Estado == "AUTORIZADO" {
 			receipt := &domain.ElectronicReceipt{
-				TransactionID: tx.ID, AccessKey: claveAcceso, SRIStatus: "AUTORIZADO",
+				TransactionID: tx.ID, AccessKey: claveAcceso, SRIStatus: domain.ReceiptAuthorized,
 				XMLContent: string(signedXML), AuthorizationDate: &authDate,
 				TaxPayerID: clientMapping.ID, // CORRECCIÓN: Asignar el ID del cliente
 			}
@@ -490,7 +621,7 @@ func (s *SriService) EmitirFactura(ctx context.Context, transactionID int, signa
 	} else if err == nil {
 		// No hay error de red, pero tampoco autorizaciones -> SRI sigue procesando
 		s.logger.Printf("El SRI aún está procesando el comprobante. Estado: EN PROCESO")
-		_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "EN PROCESO", "Esperando autorización...", nil)
+		s.recordStatus(ctx, claveAcceso, domain.ReceiptProcessing, "Esperando autorización...", nil)
 	}
 
 	return nil
@@ -526,22 +657,7 @@ func (s *SriService) finalizeAndEmail(ctx context.Context, receipt *domain.Elect
 		authDate = *receipt.AuthorizationDate
 	}
 
-	if receipt.ReceiptType == "04" {
-		var nc sri.NotaCredito
-		if err := xml.Unmarshal([]byte(receipt.XMLContent), &nc); err != nil {
-			s.logger.Printf("Failed to unmarshal NC XML for RIDE: %v", err)
-			return err
-		}
-		err = s.rideGen.GenerateNotaCreditoRide(&nc, pdfPath, issuer.LogoPath, authDate, receipt.AccessKey)
-	} else {
-		var factura sri.Factura
-		if err := xml.Unmarshal([]byte(receipt.XMLContent), &factura); err != nil {
-			s.logger.Printf("Failed to unmarshal Factura XML for RIDE: %v", err)
-			return err
-		}
-		err = s.rideGen.GenerateFacturaRide(&factura, pdfPath, issuer.LogoPath, authDate, receipt.AccessKey)
-	}
-
+	err = s.renderRide(receipt.ReceiptType, receipt.XMLContent, pdfPath, issuer.LogoPath, authDate, receipt.AccessKey)
 	if err != nil {
 		s.logger.Printf("Failed to generate RIDE PDF for %s: %v", receipt.AccessKey, err)
 		return err
@@ -623,139 +739,106 @@ func (s *SriService) ResendEmail(ctx context.Context, transactionID int) error {
 	return nil
 }
 
-func (s *SriService) mapTransactionToFactura(tx *domain.Transaction, issuer *domain.Issuer, client *domain.TaxPayer, claveAcceso string, secuencialSRI string) *sri.Factura {
+func (s *SriService) mapTransactionToFactura(tx *domain.Transaction, taxes *domain.TaxSummary, issuer *domain.Issuer, client *domain.TaxPayer, claveAcceso string, secuencialSRI string) *sri.Factura {
 	f := &sri.Factura{}
 
-	// Función auxiliar para limpiar strings de saltos de línea y tabulaciones
-	clean := func(str string) string {
-		str = strings.ReplaceAll(str, "\n", " ")
-		str = strings.ReplaceAll(str, "\r", "")
-		str = strings.ReplaceAll(str, "\t", " ")
-		return strings.TrimSpace(str)
-	}
-
 	f.InfoTributaria = sri.InfoTributaria{
 		Ambiente:        strconv.Itoa(issuer.Environment),
 		TipoEmision:     "1",
-		RazonSocial:     clean(issuer.BusinessName),
-		NombreComercial: clean(issuer.TradeName),
+		RazonSocial:     cleanText(issuer.BusinessName),
+		NombreComercial: cleanText(issuer.TradeName),
 		Ruc:             issuer.RUC,
 		ClaveAcceso:     claveAcceso,
 		CodDoc:          "01",
 		Estab:           issuer.EstablishmentCode,
 		PtoEmi:          issuer.EmissionPointCode,
 		Secuencial:      secuencialSRI,
-		DirMatriz:       clean(issuer.MainAddress),
+		DirMatriz:       cleanText(issuer.MainAddress),
 	}
 
-	// Calculamos subtotales formateados para asegurar que la suma final coincida con lo que el SRI lee
-	s15Str := fmt.Sprintf("%.2f", tx.Subtotal15)
-	s0Str := fmt.Sprintf("%.2f", tx.Subtotal0)
-	taxStr := fmt.Sprintf("%.2f", tx.TaxAmount)
 	totalStr := fmt.Sprintf("%.2f", tx.Amount)
 
+	// Descuentos por ítem (línea + parte del global); su suma es el totalDescuento
+	discounts := tx.ItemDiscounts()
+	totalDiscount := 0.0
+	for _, d := range discounts {
+		totalDiscount += d
+	}
+
 	f.InfoFactura = sri.InfoFactura{
 		FechaEmision:                tx.TransactionDate.Format("02/01/2006"),
-		DirEstablecimiento:          clean(issuer.EstablishmentAddress),
+		DirEstablecimiento:          cleanText(issuer.EstablishmentAddress),
 		ObligadoContabilidad:        map[bool]string{true: "SI", false: "NO"}[issuer.KeepAccounting],
 		TipoIdentificacionComprador: client.IdentificationType,
-		RazonSocialComprador:        clean(client.Name),
+		RazonSocialComprador:        cleanText(client.Name),
 		IdentificacionComprador:     client.Identification,
-		DireccionComprador:          clean(client.Address),
+		DireccionComprador:          cleanText(client.Address),
 		TotalSinImpuestos:           fmt.Sprintf("%.2f", tx.Subtotal15+tx.Subtotal0),
-		TotalDescuento:              "0.00",
-		Propina:                     "0.00",
+		TotalDescuento:              fmt.Sprintf("%.2f", totalDiscount),
+		Propina:                     fmt.Sprintf("%.2f", tx.Tip),
 		ImporteTotal:                totalStr,
 		Moneda:                      "DOLAR",
 	}
 
-	// Impuestos Totales
-	if tx.Subtotal15 > 0 {
-		f.InfoFactura.TotalConImpuestos.TotalImpuesto = append(f.InfoFactura.TotalConImpuestos.TotalImpuesto, sri.TotalImpuesto{
-			Codigo:           "2",
-			CodigoPorcentaje: "4", // 15% (Tarifa vigente 2026)
-			BaseImponible:    s15Str,
-			Valor:            taxStr,
-		})
-	}
+	// Impuestos Totales: una línea por tarifa vigente a la fecha de la factura, más el ICE y el IRBPNR
+	f.InfoFactura.TotalConImpuestos.TotalImpuesto = append(toTotalImpuestos(taxes.Totals), toOtherTotalImpuestos(taxes.OtherTotals)...)
 
-	if tx.Subtotal0 > 0 {
-		f.InfoFactura.TotalConImpuestos.TotalImpuesto = append(f.InfoFactura.TotalConImpuestos.TotalImpuesto, sri.TotalImpuesto{
-			Codigo:           "2",
-			CodigoPorcentaje: "0", // 0%
-			BaseImponible:    s0Str,
-			Valor:            "0.00",
+	// Pagos: el desglose registrado o, si no hay, todo sin utilización del sistema financiero
+	for _, p := range tx.Payments {
+		pago := sri.Pago{
+			FormaPago: p.PaymentMethod,
+			Total:     fmt.Sprintf("%.2f", p.Amount),
+		}
+		if p.Term > 0 {
+			pago.Plazo = strconv.Itoa(p.Term)
+			pago.UnidadTiempo = p.TimeUnit
+		}
+		f.InfoFactura.Pagos.Pago = append(f.InfoFactura.Pagos.Pago, pago)
+	}
+	if len(tx.Payments) == 0 {
+		f.InfoFactura.Pagos.Pago = append(f.InfoFactura.Pagos.Pago, sri.Pago{
+			FormaPago: domain.PaymentMethodNoFinancialSystem,
+			Total:     totalStr,
 		})
 	}
 
-	// Pago
-	f.InfoFactura.Pagos.Pago = append(f.InfoFactura.Pagos.Pago, sri.Pago{
-		FormaPago: "01", // Efectivo/Otros sin utilizacion sistema financiero por defecto
-		Total:     totalStr,
-	})
+	f.InfoAdicional = toInfoAdicional(mergeAdditionalFields(client, issuer, tx.AdditionalFields))
 
 	// Detalles
 	if len(tx.Items) > 0 {
-		for _, item := range tx.Items {
-			valTax := 0.0
-			codPerc := "0"
-			tarifa := "0"
-			if item.TaxRate == 4 {
-				codPerc = "4"
-				valTax = item.Subtotal * 0.15
-				tarifa = "15"
-			}
-
+		for i, item := range tx.Items {
+			// precioTotalSinImpuesto = cantidad * precioUnitario - descuento (línea + parte del global)
+			line := taxes.Items[i]
 			det := sri.Detalle{
-				Descripcion:            clean(item.Description),
+				Descripcion:            cleanText(item.Description),
 				Cantidad:               fmt.Sprintf("%.6f", item.Quantity),
 				PrecioUnitario:         fmt.Sprintf("%.6f", item.UnitPrice),
-				Descuento:              "0.00",
-				PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", item.Subtotal),
+				Descuento:              fmt.Sprintf("%.2f", discounts[i]),
+				PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", line.Net()),
 			}
-
-			impDet := sri.Impuesto{
-				Codigo:           "2",
-				CodigoPorcentaje: codPerc,
-				Tarifa:           tarifa,
-				BaseImponible:    fmt.Sprintf("%.2f", item.Subtotal),
-				Valor:            fmt.Sprintf("%.2f", valTax),
-			}
-			det.Impuestos.Impuesto = append(det.Impuestos.Impuesto, impDet)
+			det.Impuestos.Impuesto = detailImpuestos(taxes, i)
 			f.Detalles.Detalle = append(f.Detalles.Detalle, det)
-
 		}
 	} else {
-
-		// Fallback sanitizado
-		codPerc := "0"
-		tarifa := "0"
-		if tx.TaxAmount > 0 {
-			codPerc = "4"
-			tarifa = "15"
+		// Fallback sanitizado: una línea por cada base imponible de la transacción
+		for _, total := range taxes.Totals {
+			det := sri.Detalle{
+				Descripcion:            cleanText(tx.Description),
+				Cantidad:               "1.000000",
+				PrecioUnitario:         fmt.Sprintf("%.6f", total.Base),
+				Descuento:              "0.00",
+				PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", total.Base),
+			}
+			det.Impuestos.Impuesto = append(det.Impuestos.Impuesto, toImpuesto(total))
+			f.Detalles.Detalle = append(f.Detalles.Detalle, det)
 		}
-
-		f.Detalles.Detalle = append(f.Detalles.Detalle, sri.Detalle{
-			Descripcion:            clean(tx.Description),
-			Cantidad:               "1.000000",
-			PrecioUnitario:         fmt.Sprintf("%.6f", tx.Subtotal15+tx.Subtotal0),
-			Descuento:              "0.00",
-			PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", tx.Subtotal15+tx.Subtotal0),
-		})
-
-		f.Detalles.Detalle[0].Impuestos.Impuesto = append(f.Detalles.Detalle[0].Impuestos.Impuesto, sri.Impuesto{
-			Codigo:           "2",
-			CodigoPorcentaje: codPerc,
-			Tarifa:           tarifa,
-			BaseImponible:    fmt.Sprintf("%.2f", tx.Subtotal15+tx.Subtotal0),
-			Valor:            taxStr,
-		})
 	}
 
 	return f
 }
 
-// EmitirNotaCredito emite una Nota de Crédito electrónica para anular una factura.
+// EmitirNotaCredito emite una Nota de Crédito electrónica para anular una factura. Si voidTxID es
+// una devolución parcial (egreso vinculado con RelatedTransactionID) solo acredita sus ítems.
 func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, originalTxID int, motivo string, signaturePassword string) (string, error) {
 	s.logger.Printf("Iniciando emisión de Nota de Crédito para anular factura ID: %d", originalTxID)
 
@@ -783,48 +866,56 @@ func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, origin
 	}
 	originalTx.Items = originalItems
 
+	// En una devolución parcial los valores acreditados salen del egreso, no de la factura
+	creditTx := originalTx
+	if voidTx.IsPartialCredit() {
+		if *voidTx.RelatedTransactionID != originalTxID {
+			return "", errors.New("la devolución no corresponde a la factura indicada")
+		}
+		returnedItems, err := s.txRepo.GetItemsByTransactionID(ctx, voidTx.ID)
+		if err != nil {
+			return "", fmt.Errorf("error cargando items devueltos: %w", err)
+		}
+		voidTx.Items = returnedItems
+		creditTx = voidTx
+	}
+
 	issuer, err := s.issuerRepo.GetActive(ctx)
 	if err != nil {
 		return "", err
 	}
+	// La nota de crédito sale del punto de emisión registrado en la anulación o devolución
+	establishmentCode, emissionPointCode := transactionPoint(voidTx)
+	if issuer, err = s.issuerAtPoint(ctx, issuer, establishmentCode, emissionPointCode); err != nil {
+		return "", err
+	}
 
 	client, err := s.clientRepo.GetByID(ctx, *originalTx.TaxPayerID)
 	if err != nil || client == nil {
 		return "", fmt.Errorf("error obteniendo cliente de la factura original")
 	}
 
-	// 2. Generar Secuencial y Clave para la NC
-	// Usamos un nuevo punto de emisión o el mismo, pero con tipo '04' (Nota de Crédito)
-	ep, err := s.epRepo.GetByPoint(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode, "04")
+	// El IVA se acredita con las tarifas vigentes a la fecha de la factura original
+	taxes, err := s.taxSummary(ctx, creditTx, originalTx.TransactionDate)
 	if err != nil {
 		return "", err
 	}
-	if ep == nil {
-		ep = &domain.EmissionPoint{
-			IssuerID:          issuer.ID,
-			EstablishmentCode: issuer.EstablishmentCode,
-			EmissionPointCode: issuer.EmissionPointCode,
-			ReceiptType:       "04",
-			CurrentSequence:   0,
-			IsActive:          true,
-		}
-		if err := s.epRepo.Create(ctx, ep); err != nil {
-			return "", fmt.Errorf("error al crear punto de emisión para NC: %w", err)
-		}
+
+	// Validar el XML contra el XSD antes de reservar el secuencial de la NC
+	draftXML, err := sri.MarshalNotaCredito(s.mapToNotaCredito(originalTx, creditTx, taxes, issuer, client, draftAccessKey, draftSequential, motivo))
+	if err != nil {
+		return "", err
 	}
-	if err := s.epRepo.IncrementSequence(ctx, ep.ID); err != nil {
+	if err := sri.ValidateNotaCredito(draftXML); err != nil {
 		return "", err
 	}
 
-	// Refrescamos para obtener el secuencial actualizado por la base de datos (considerando InitialSequence)
-	ep, err = s.epRepo.GetByPoint(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode, "04")
+	// 2. Generar Secuencial y Clave para la NC
+	// Usamos un nuevo punto de emisión o el mismo, pero con tipo '04' (Nota de Crédito)
+	secuencialSRI, err := s.nextSequential(ctx, issuer, "04")
 	if err != nil {
-		return "", fmt.Errorf("error al refrescar punto de emisión: %w", err)
+		return "", err
 	}
-	secuencialSRI := fmt.Sprintf("%09d", ep.CurrentSequence)
-
-	nSafe, _ := rand.Int(rand.Reader, big.NewInt(100000000))
-	numericCode := fmt.Sprintf("%08d", nSafe.Int64())
 
 	// Generar Clave (Tipo 04)
 	claveAcceso := sri.GenerateAccessKey(
@@ -835,16 +926,19 @@ func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, origin
 		issuer.EstablishmentCode,
 		issuer.EmissionPointCode,
 		secuencialSRI,
-		numericCode,
+		newNumericCode(),
 		1,
 	)
 
 	// 3. Generar XML
-	ncXML := s.mapToNotaCredito(originalTx, issuer, client, claveAcceso, secuencialSRI, motivo)
+	ncXML := s.mapToNotaCredito(originalTx, creditTx, taxes, issuer, client, claveAcceso, secuencialSRI, motivo)
 	xmlBytes, err := sri.MarshalNotaCredito(ncXML)
 	if err != nil {
 		return "", err
 	}
+	if err := sri.ValidateNotaCredito(xmlBytes); err != nil {
+		return "", err
+	}
 
 	// 4. Firmar
 	signerObj := s.signerFactory(issuer.SignaturePath, signaturePassword)
@@ -864,7 +958,7 @@ func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, origin
 		receipt = voidTx.ElectronicReceipt
 		receipt.AccessKey = claveAcceso
 		receipt.XMLContent = signedXMLStr
-		receipt.SRIStatus = "PENDIENTE"
+		receipt.SRIStatus = domain.ReceiptPending
 		receipt.SRIMessage = ""
 		receipt.AuthorizationDate = nil
 		receipt.Environment = issuer.Environment
@@ -882,7 +976,7 @@ func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, origin
 			AccessKey:     claveAcceso,
 			ReceiptType:   "04",
 			XMLContent:    signedXMLStr,
-			SRIStatus:     "PENDIENTE",
+			SRIStatus:     domain.ReceiptPending,
 			Environment:   issuer.Environment,
 		}
 		receipt.CreatedAt = time.Now()
@@ -894,9 +988,9 @@ func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, origin
 
 
 	// 6. Enviar
-	resp, err := s.sriClient.EnviarComprobante([]byte(signedXMLStr), issuer.Environment)
+	resp, err := s.sriClient.EnviarComprobante(ctx, []byte(signedXMLStr), issuer.Environment)
 	if err != nil {
-		_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "ERROR_RED", err.Error(), nil)
+		s.recordStatus(ctx, claveAcceso, sendErrorStatus(err), err.Error(), nil)
 		return "", err
 	}
 
@@ -909,28 +1003,33 @@ func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, origin
 		// TOLERANCIA A FALLOS: Si ya está en procesamiento, NO es un error fatal.
 		if strings.Contains(strings.ToUpper(msg), "PROCESAMIENTO") {
 			s.logger.Printf("ADVERTENCIA SRI: Clave ya en procesamiento. Continuando flujo de consulta. Msg: %s", msg)
-			_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "EN PROCESO", "SRI reporta procesamiento previo", nil)
+			s.recordStatus(ctx, claveAcceso, domain.ReceiptProcessing, "SRI reporta procesamiento previo", nil)
 		} else {
-			_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "DEVUELTA", msg, nil)
+			s.recordStatus(ctx, claveAcceso, domain.ReceiptReturned, msg, nil)
 			return "", fmt.Errorf("SRI devolvió la NC: %s", msg)
 		}
 	} else {
-		_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, "RECIBIDA", "Enviado a SRI", nil)
+		s.recordStatus(ctx, claveAcceso, domain.ReceiptReceived, "Enviado a SRI", nil)
 	}
 
 	// 7. Autorizar
-	time.Sleep(3 * time.Second)
-	authResp, err := s.sriClient.AutorizarComprobante(claveAcceso, issuer.Environment)
+	if err := s.waitForAuthorization(ctx); err != nil {
+		s.logger.Printf("Consulta de autorización cancelada; se verificará en background: %v", err)
+		return claveAcceso, nil
+	}
+	authResp, err := s.sriClient.AutorizarComprobante(ctx, claveAcceso, issuer.Environment)
 	if err == nil && len(authResp.Autorizaciones.Autorizacion) > 0 {
 		auth := authResp.Autorizaciones.Autorizacion[0]
 		authDate, _ := time.Parse(time.RFC3339, auth.FechaAutorizacion)
 
-		_ = s.receiptRepo.UpdateStatus(ctx, claveAcceso, auth.Estado, "Procesado", &authDate)
+		if status, ok := domain.ReceiptStatusFromSRI(auth.Estado); ok {
+			s.recordStatus(ctx, claveAcceso, status, authorizationMessage(auth), &authDate)
+		}
 
 		switch auth.Estado {
 		case "AUTORIZADO":
 			receipt.AuthorizationDate = &authDate
-			receipt.SRIStatus = "AUTORIZADO"
+			receipt.SRIStatus = domain.ReceiptAuthorized
 			s.logger.Printf("Nota de Crédito Autorizada: %s", claveAcceso)
 
 			go func() {
@@ -955,109 +1054,447 @@ func (s *SriService) EmitirNotaCredito(ctx context.Context, voidTxID int, origin
 	return claveAcceso, nil
 }
 
-func (s *SriService) mapToNotaCredito(originalTx *domain.Transaction, issuer *domain.Issuer, client *domain.TaxPayer, claveAcceso, secuencial, motivo string) *sri.NotaCredito {
+// mapToNotaCredito toma la referencia de la factura de originalTx y los valores acreditados de creditTx
+// (la misma factura en una anulación total, o el egreso de devolución en una parcial).
+func (s *SriService) mapToNotaCredito(originalTx, creditTx *domain.Transaction, taxes *domain.TaxSummary, issuer *domain.Issuer, client *domain.TaxPayer, claveAcceso, secuencial, motivo string) *sri.NotaCredito {
 	nc := &sri.NotaCredito{}
 
-	clean := func(str string) string {
-		str = strings.ReplaceAll(str, "\n", " ")
-		str = strings.ReplaceAll(str, "\r", "")
-		str = strings.ReplaceAll(str, "\t", " ")
-		return strings.TrimSpace(str)
-	}
-
 	nc.InfoTributaria = sri.InfoTributaria{
 		Ambiente:        strconv.Itoa(issuer.Environment),
 		TipoEmision:     "1",
-		RazonSocial:     clean(issuer.BusinessName),
-		NombreComercial: clean(issuer.TradeName),
+		RazonSocial:     cleanText(issuer.BusinessName),
+		NombreComercial: cleanText(issuer.TradeName),
 		Ruc:             issuer.RUC,
 		ClaveAcceso:     claveAcceso,
 		CodDoc:          "04", // Nota de Crédito
 		Estab:           issuer.EstablishmentCode,
 		PtoEmi:          issuer.EmissionPointCode,
 		Secuencial:      secuencial,
-		DirMatriz:       clean(issuer.MainAddress),
+		DirMatriz:       cleanText(issuer.MainAddress),
 	}
 
 	// Recuperar datos de la factura original para referencia
 	// Formato: 001-001-000000123
 	originalDocNum := "000-000-000000000"
 	if originalTx.ElectronicReceipt != nil {
-		key := originalTx.ElectronicReceipt.AccessKey
-		if len(key) == 49 {
-			originalDocNum = fmt.Sprintf("%s-%s-%s", key[24:27], key[27:30], key[30:39])
+		if key, err := sri.ParseAccessKey(originalTx.ElectronicReceipt.AccessKey); err == nil {
+			originalDocNum = key.DocumentNumber()
 		}
 	}
 
-	s15Str := fmt.Sprintf("%.2f", originalTx.Subtotal15)
-	s0Str := fmt.Sprintf("%.2f", originalTx.Subtotal0)
-	taxStr := fmt.Sprintf("%.2f", originalTx.TaxAmount)
-	totalStr := fmt.Sprintf("%.2f", originalTx.Amount)
+	// La nota de crédito no tiene campo de propina: se acredita solo base más impuestos
+	totalStr := fmt.Sprintf("%.2f", creditTx.Amount-creditTx.Tip)
 
 	nc.InfoNotaCredito = sri.InfoNotaCredito{
 		FechaEmision:                time.Now().Format("02/01/2006"),
-		DirEstablecimiento:          clean(issuer.EstablishmentAddress),
+		DirEstablecimiento:          cleanText(issuer.EstablishmentAddress),
 		TipoIdentificacionComprador: client.IdentificationType,
-		RazonSocialComprador:        clean(client.Name),
+		RazonSocialComprador:        cleanText(client.Name),
 		IdentificacionComprador:     client.Identification,
 		ObligadoContabilidad:        map[bool]string{true: "SI", false: "NO"}[issuer.KeepAccounting],
 		CodDocModificado:            "01", // Factura
 		NumDocModificado:            originalDocNum,
 		FechaEmisionDocSustento:     originalTx.TransactionDate.Format("02/01/2006"),
-		TotalSinImpuestos:           fmt.Sprintf("%.2f", originalTx.Subtotal15+originalTx.Subtotal0),
+		TotalSinImpuestos:           fmt.Sprintf("%.2f", creditTx.Subtotal15+creditTx.Subtotal0),
 		ValorModificacion:           totalStr,
 		Moneda:                      "DOLAR",
 		Motivo:                      motivo,
 	}
 
-	// Impuestos Totales
-	if originalTx.Subtotal15 > 0 {
-		nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto = append(nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto, sri.TotalImpuesto{
+	// Impuestos Totales, con las tarifas que se aplicaron en la factura original
+	nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto = append(toTotalImpuestos(taxes.Totals), toOtherTotalImpuestos(taxes.OtherTotals)...)
+
+	// Detalles (Replicamos los originales o los devueltos, con sus descuentos)
+	discounts := creditTx.ItemDiscounts()
+	for i, item := range creditTx.Items {
+		line := taxes.Items[i]
+		det := sri.DetalleNC{
+			CodigoInterno:          "NC-RET", // Usamos CodigoInterno según XSD de NC
+			Descripcion:            cleanText(item.Description),
+			Cantidad:               fmt.Sprintf("%.6f", item.Quantity),
+			PrecioUnitario:         fmt.Sprintf("%.6f", item.UnitPrice),
+			Descuento:              fmt.Sprintf("%.2f", discounts[i]),
+			PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", line.Net()),
+		}
+		det.Impuestos.Impuesto = detailImpuestos(taxes, i)
+		nc.Detalles.Detalle = append(nc.Detalles.Detalle, det)
+	}
+
+	nc.InfoAdicional = toInfoAdicional(mergeAdditionalFields(client, issuer, nil))
+
+	return nc
+}
+
+// Clave y secuencial provisionales con el formato del XSD, para validar el borrador del comprobante
+// antes de reservar el secuencial real.
+var (
+	draftAccessKey  = strings.Repeat("0", 49)
+	draftSequential = "000000001"
+)
+
+// validateFacturaDraft valida contra el esquema la factura armada con la clave y el secuencial provisionales.
+func (s *SriService) validateFacturaDraft(ctx context.Context, tx *domain.Transaction, issuer *domain.Issuer, client *domain.TaxPayer) error {
+	taxes, err := s.taxSummary(ctx, tx, tx.TransactionDate)
+	if err != nil {
+		return err
+	}
+	xmlBytes, err := sri.MarshalFactura(s.mapTransactionToFactura(tx, taxes, issuer, client, draftAccessKey, draftSequential))
+	if err != nil {
+		return err
+	}
+	return sri.ValidateFactura(xmlBytes)
+}
+
+// taxSummary desglosa el IVA de la transacción con las tarifas del catálogo vigentes en la fecha.
+func (s *SriService) taxSummary(ctx context.Context, tx *domain.Transaction, date time.Time) (*domain.TaxSummary, error) {
+	rates, err := loadTaxRates(ctx, s.taxRateRepo)
+	if err != nil {
+		return nil, err
+	}
+	return tx.TaxSummary(rates, date)
+}
+
+// toImpuesto arma el impuesto de un detalle: código 2 (IVA) con el código de porcentaje y la tarifa.
+func toImpuesto(line domain.TaxLine) sri.Impuesto {
+	return sri.Impuesto{
+		Codigo:           "2",
+		CodigoPorcentaje: strconv.Itoa(line.Rate.Code),
+		Tarifa:           line.Rate.Tarifa(),
+		BaseImponible:    fmt.Sprintf("%.2f", line.Base),
+		Valor:            fmt.Sprintf("%.2f", line.Tax),
+	}
+}
+
+// toItemTaxImpuesto arma el impuesto de un detalle para el ICE (código 3) o el IRBPNR (código 5).
+func toItemTaxImpuesto(tax domain.ItemTax) sri.Impuesto {
+	return sri.Impuesto{
+		Codigo:           strconv.Itoa(tax.TaxCode),
+		CodigoPorcentaje: tax.PercentageCode,
+		Tarifa:           strconv.FormatFloat(tax.Rate, 'f', -1, 64),
+		BaseImponible:    fmt.Sprintf("%.2f", tax.Base),
+		Valor:            fmt.Sprintf("%.2f", tax.Amount),
+	}
+}
+
+// detailImpuestos devuelve los impuestos del ítem i: su IVA seguido de su ICE e IRBPNR.
+func detailImpuestos(taxes *domain.TaxSummary, i int) []sri.Impuesto {
+	impuestos := []sri.Impuesto{toImpuesto(taxes.Items[i])}
+	if i < len(taxes.ItemTaxes) {
+		for _, tax := range taxes.ItemTaxes[i] {
+			impuestos = append(impuestos, toItemTaxImpuesto(tax))
+		}
+	}
+	return impuestos
+}
+
+// toTotalImpuestos arma los totales por tarifa de la cabecera de facturas y notas de crédito.
+func toTotalImpuestos(totals []domain.TaxLine) []sri.TotalImpuesto {
+	result := make([]sri.TotalImpuesto, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, sri.TotalImpuesto{
 			Codigo:           "2",
-			CodigoPorcentaje: "4",
-			BaseImponible:    s15Str,
-			Valor:            taxStr,
+			CodigoPorcentaje: strconv.Itoa(total.Rate.Code),
+			BaseImponible:    fmt.Sprintf("%.2f", total.Base),
+			Valor:            fmt.Sprintf("%.2f", total.Tax),
 		})
 	}
-	if originalTx.Subtotal0 > 0 {
-		nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto = append(nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto, sri.TotalImpuesto{
-			Codigo:           "2",
-			CodigoPorcentaje: "0",
-			BaseImponible:    s0Str,
-			Valor:            "0.00",
+	return result
+}
+
+// toOtherTotalImpuestos arma los totales del ICE y el IRBPNR por código de porcentaje.
+func toOtherTotalImpuestos(others []domain.ItemTax) []sri.TotalImpuesto {
+	result := make([]sri.TotalImpuesto, 0, len(others))
+	for _, tax := range others {
+		result = append(result, sri.TotalImpuesto{
+			Codigo:           strconv.Itoa(tax.TaxCode),
+			CodigoPorcentaje: tax.PercentageCode,
+			BaseImponible:    fmt.Sprintf("%.2f", tax.Base),
+			Valor:            fmt.Sprintf("%.2f", tax.Amount),
 		})
 	}
+	return result
+}
+
+// withZeroRateFallback declara en tarifa 0% el monto de los egresos y cargos registrados sin desglose
+// de bases imponibles.
+func withZeroRateFallback(totals []domain.TaxLine, amount float64) []domain.TaxLine {
+	if len(totals) > 0 {
+		return totals
+	}
+	return []domain.TaxLine{{Rate: domain.TaxRate{Code: domain.TaxRateCodeZero}, Base: amount}}
+}
 
-	// Detalles (Replicamos los originales)
-	for _, item := range originalTx.Items {
-		codPerc := "0"
-		tarifa := "0"
-		valTax := 0.0
-		if item.TaxRate == 4 {
-			codPerc = "4"
-			tarifa = "15"
-			valTax = item.Subtotal * 0.15
+// taxBase suma el valor sin impuestos de todas las tarifas (las bases del IVA sin el ICE).
+func taxBase(totals []domain.TaxLine) float64 {
+	var base float64
+	for _, total := range totals {
+		base += total.Net()
+	}
+	return base
+}
+
+// transactionPoint devuelve el establecimiento y punto de emisión registrados en la transacción, o
+// cadenas vacías si usa los del emisor.
+func transactionPoint(tx *domain.Transaction) (string, string) {
+	if tx.EstablishmentCode == nil || tx.EmissionPointCode == nil {
+		return "", ""
+	}
+	return *tx.EstablishmentCode, *tx.EmissionPointCode
+}
+
+// issuerAtPoint devuelve una copia del emisor con el establecimiento y punto de emisión indicados y la
+// dirección de ese establecimiento, para que la clave de acceso, el secuencial y dirEstablecimiento
+// salgan del mismo punto. Sin códigos se usa el emisor tal como está configurado.
+func (s *SriService) issuerAtPoint(ctx context.Context, issuer *domain.Issuer, establishmentCode, emissionPointCode string) (*domain.Issuer, error) {
+	if establishmentCode == "" || emissionPointCode == "" ||
+		(establishmentCode == issuer.EstablishmentCode && emissionPointCode == issuer.EmissionPointCode) {
+		return issuer, nil
+	}
+
+	at := *issuer
+	at.EstablishmentCode = establishmentCode
+	at.EmissionPointCode = emissionPointCode
+	if establishmentCode != issuer.EstablishmentCode {
+		estab, err := s.epRepo.GetEstablishment(ctx, issuer.ID, establishmentCode)
+		if err != nil {
+			return nil, fmt.Errorf("error obteniendo el establecimiento %s: %w", establishmentCode, err)
 		}
+		if estab == nil || !estab.IsActive {
+			return nil, fmt.Errorf("el establecimiento %s no está registrado o está inactivo", establishmentCode)
+		}
+		at.EstablishmentAddress = estab.Address
+	}
+	return &at, nil
+}
 
-		det := sri.DetalleNC{
-			CodigoInterno:          "NC-RET", // Usamos CodigoInterno según XSD de NC
-			Descripcion:            clean(item.Description),
-			Cantidad:               fmt.Sprintf("%.6f", item.Quantity),
-			PrecioUnitario:         fmt.Sprintf("%.6f", item.UnitPrice),
-			Descuento:              "0.00",
-			PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", item.Subtotal),
+// nextSequential reserva el siguiente secuencial del punto de emisión activo para el tipo de comprobante,
+// creando el punto de emisión si aún no existe.
+func (s *SriService) nextSequential(ctx context.Context, issuer *domain.Issuer, receiptType string) (string, error) {
+	ep, err := s.epRepo.GetByPoint(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode, receiptType)
+	if err != nil {
+		return "", err
+	}
+	if ep == nil {
+		ep = &domain.EmissionPoint{
+			IssuerID:          issuer.ID,
+			EstablishmentCode: issuer.EstablishmentCode,
+			EmissionPointCode: issuer.EmissionPointCode,
+			ReceiptType:       receiptType,
+			CurrentSequence:   0,
+			IsActive:          true,
+		}
+		if err := s.epRepo.Create(ctx, ep); err != nil {
+			return "", fmt.Errorf("error al crear punto de emisión para tipo %s: %w", receiptType, err)
 		}
+	}
+	if err := s.epRepo.IncrementSequence(ctx, ep.ID); err != nil {
+		return "", err
+	}
 
-		impDet := sri.Impuesto{
-			Codigo:           "2",
-			CodigoPorcentaje: codPerc,
-			Tarifa:           tarifa,
-			BaseImponible:    fmt.Sprintf("%.2f", item.Subtotal),
-			Valor:            fmt.Sprintf("%.2f", valTax),
+	// Refrescamos para obtener el secuencial actualizado por la base de datos (considerando InitialSequence)
+	ep, err = s.epRepo.GetByPoint(ctx, issuer.ID, issuer.EstablishmentCode, issuer.EmissionPointCode, receiptType)
+	if err != nil {
+		return "", fmt.Errorf("error al refrescar punto de emisión: %w", err)
+	}
+	return fmt.Sprintf("%09d", ep.CurrentSequence), nil
+}
+
+// newNumericCode genera el código numérico de 8 dígitos de la clave de acceso con crypto/rand.
+func newNumericCode() string {
+	nSafe, _ := rand.Int(rand.Reader, big.NewInt(100000000))
+	return fmt.Sprintf("%08d", nSafe.Int64())
+}
+
+// signatureError traduce los errores de la librería de firma a mensajes entendibles para el usuario.
+func signatureError(err error) error {
+	errStr := err.Error()
+	if strings.Contains(errStr, "no such file") || strings.Contains(errStr, "system cannot find") {
+		return fmt.Errorf("no se encuentra el archivo de firma (.p12) en la ruta configurada. Verifique la configuración del emisor")
+	}
+	if strings.Contains(errStr, "password") || strings.Contains(errStr, "mac check failed") {
+		return fmt.Errorf("contraseña de firma incorrecta")
+	}
+	return fmt.Errorf("error técnico al firmar: %w", err)
+}
+
+// savePendingReceipt guarda el comprobante firmado como PENDIENTE. Si la transacción ya tenía
+// un recibo fallido se reutiliza la fila para no duplicar registros.
+func (s *SriService) savePendingReceipt(ctx context.Context, existing *domain.ElectronicReceipt, receipt *domain.ElectronicReceipt) error {
+	receipt.SRIStatus = domain.ReceiptPending
+	receipt.CreatedAt = time.Now()
+
+	if existing != nil {
+		receipt.ID = existing.ID
+		if err := s.receiptRepo.Update(ctx, receipt); err != nil {
+			return fmt.Errorf("error actualizando recibo: %w", err)
 		}
-		det.Impuestos.Impuesto = append(det.Impuestos.Impuesto, impDet)
-		nc.Detalles.Detalle = append(nc.Detalles.Detalle, det)
+		return nil
 	}
+	return s.receiptRepo.Create(ctx, receipt)
+}
 
-	return nc
+// submitAndAuthorize envía un comprobante ya guardado como PENDIENTE y consulta su autorización.
+// docLabel se usa en los mensajes de error (ej. "el comprobante de retención").
+func (s *SriService) submitAndAuthorize(ctx context.Context, receipt *domain.ElectronicReceipt, docLabel string) (string, error) {
+	claveAcceso := receipt.AccessKey
+
+	resp, err := s.sriClient.EnviarComprobante(ctx, []byte(receipt.XMLContent), receipt.Environment)
+	if err != nil {
+		s.recordStatus(ctx, claveAcceso, sendErrorStatus(err), err.Error(), nil)
+		return "", err
+	}
+
+	if rej := resp.Rechazo(); rej != nil {
+		// Si la clave ya está en procesamiento no es un error fatal, seguimos a la consulta.
+		if rej.EnProcesamiento() {
+			s.recordStatus(ctx, claveAcceso, domain.ReceiptProcessing, "SRI reporta procesamiento previo", nil)
+		} else {
+			s.recordStatus(ctx, claveAcceso, domain.ReceiptReturned, rej.Message(), nil)
+			return "", fmt.Errorf("SRI devolvió %s: %w", docLabel, rej)
+		}
+	} else {
+		s.recordStatus(ctx, claveAcceso, domain.ReceiptReceived, "Enviado a SRI", nil)
+	}
+
+	if err := s.waitForAuthorization(ctx); err != nil {
+		s.logger.Printf("Consulta de autorización cancelada para %s; se verificará en background.", claveAcceso)
+		return claveAcceso, nil
+	}
+	authResp, err := s.sriClient.AutorizarComprobante(ctx, claveAcceso, receipt.Environment)
+	if err != nil || len(authResp.Autorizaciones.Autorizacion) == 0 {
+		s.logger.Printf("SRI no respondió autorización inmediata para %s. Se verificará en background.", claveAcceso)
+		return claveAcceso, nil
+	}
+
+	auth := authResp.Autorizaciones.Autorizacion[0]
+	authDate, _ := time.Parse(time.RFC3339, auth.FechaAutorizacion)
+	if status, ok := domain.ReceiptStatusFromSRI(auth.Estado); ok {
+		s.recordStatus(ctx, claveAcceso, status, authorizationMessage(auth), &authDate)
+	}
+
+	switch auth.Estado {
+	case "AUTORIZADO":
+		receipt.AuthorizationDate = &authDate
+		receipt.SRIStatus = domain.ReceiptAuthorized
+		go func() {
+			if err := s.finalizeAndEmail(context.Background(), receipt); err != nil {
+				s.logger.Printf("Error procesando comprobante %s en segundo plano: %v", receipt.AccessKey, err)
+			}
+		}()
+		return claveAcceso, nil
+	case "EN PROCESO":
+		return claveAcceso, nil
+	default:
+		if rej := auth.Rechazo(); rej != nil {
+			return "", fmt.Errorf("SRI no autorizó %s: %w", docLabel, rej)
+		}
+		return "", fmt.Errorf("SRI no autorizó %s (%s)", docLabel, auth.Estado)
+	}
+}
+
+// waitForAuthorization espera AuthorizationDelay antes de consultar la autorización, o hasta que se
+// cancele el contexto.
+func (s *SriService) waitForAuthorization(ctx context.Context) error {
+	if s.AuthorizationDelay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(s.AuthorizationDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// sendErrorStatus es el estado de un comprobante cuyo envío falló: un SOAP Fault es un problema de la
+// petición (ERROR_ENVIO), cualquier otro error se trata como falla de red (ERROR_RED).
+func sendErrorStatus(err error) domain.ReceiptStatus {
+	var fault *sri.SOAPFaultError
+	if errors.As(err, &fault) {
+		return domain.ReceiptSendError
+	}
+	return domain.ReceiptNetworkError
+}
+
+// recordStatus registra el nuevo estado del comprobante. El repositorio valida el cambio contra el
+// ciclo del comprobante y lo agrega a su historia; si falla solo se deja en el log, porque el
+// intercambio con el SRI ya ocurrió y la sincronización volverá a consultarlo.
+func (s *SriService) recordStatus(ctx context.Context, accessKey string, status domain.ReceiptStatus, message string, authDate *time.Time) {
+	if err := s.receiptRepo.UpdateStatus(ctx, accessKey, status, message, authDate); err != nil {
+		s.logger.Printf("Error registrando el estado %s del comprobante %s: %v", status, accessKey, err)
+	}
+}
+
+// authorizationMessage arma el mensaje que queda en la historia del comprobante: "Procesado" si se
+// autorizó, o el primer mensaje del SRI con su identificador si no.
+func authorizationMessage(auth sri.Autorizacion) string {
+	if auth.Estado == "AUTORIZADO" || len(auth.Mensajes.Mensaje) == 0 {
+		return "Procesado"
+	}
+	m := auth.Mensajes.Mensaje[0]
+	return fmt.Sprintf("%s: %s (%s)", m.Identificador, m.Mensaje, m.InformacionAdicional)
+}
+
+// cleanText elimina saltos de línea y tabulaciones que el SRI rechaza en los campos de texto.
+func cleanText(str string) string {
+	str = strings.ReplaceAll(str, "\n", " ")
+	str = strings.ReplaceAll(str, "\r", "")
+	str = strings.ReplaceAll(str, "\t", " ")
+	return strings.TrimSpace(str)
+}
+
+// mergeAdditionalFields arma la sección infoAdicional: primero los datos de contacto del cliente,
+// luego los campos por defecto del emisor y al final los de la transacción. Un campo con el mismo
+// nombre que uno anterior lo reemplaza en su posición.
+func mergeAdditionalFields(client *domain.TaxPayer, issuer *domain.Issuer, custom []domain.AdditionalField) []domain.AdditionalField {
+	var fields []domain.AdditionalField
+	add := func(name, value string) {
+		name, value = cleanText(name), cleanText(value)
+		if name == "" || value == "" {
+			return
+		}
+		for i := range fields {
+			if strings.EqualFold(fields[i].Name, name) {
+				fields[i].Value = value
+				return
+			}
+		}
+		fields = append(fields, domain.AdditionalField{Name: name, Value: value})
+	}
+
+	if client != nil {
+		add("Dirección", client.Address)
+		add("Teléfono", client.Phone)
+		add("Email", client.Email)
+	}
+	if issuer != nil {
+		for _, f := range issuer.AdditionalFields {
+			add(f.Name, f.Value)
+		}
+	}
+	for _, f := range custom {
+		add(f.Name, f.Value)
+	}
+	return fields
+}
+
+// validateAdditionalFieldCount evita emitir un comprobante que el SRI rechazaría por exceso de campos.
+func validateAdditionalFieldCount(fields []domain.AdditionalField) error {
+	if len(fields) > domain.MaxAdditionalFields {
+		return fmt.Errorf("el comprobante admite máximo %d campos adicionales y tiene %d (incluye los del cliente y del emisor)", domain.MaxAdditionalFields, len(fields))
+	}
+	return nil
+}
+
+func toInfoAdicional(fields []domain.AdditionalField) *sri.InfoAdicional {
+	if len(fields) == 0 {
+		return nil
+	}
+	info := &sri.InfoAdicional{}
+	for _, f := range fields {
+		info.CampoAdicional = append(info.CampoAdicional, sri.CampoAdicional{Nombre: f.Name, Valor: f.Value})
+	}
+	return info
 }
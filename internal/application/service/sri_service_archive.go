@@ -0,0 +1,216 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+const (
+	// ArchiveIndexName es el índice CSV de los comprobantes del archivo legal.
+	ArchiveIndexName = "indice.csv"
+	// ArchiveManifestName es el manifiesto con el SHA-256 de cada archivo, en el formato de
+	// sha256sum para poder verificarlo con "sha256sum -c SHA256SUMS".
+	ArchiveManifestName = "SHA256SUMS"
+)
+
+// archiveFolders es la carpeta del archivo legal en la que va cada tipo de comprobante.
+var archiveFolders = map[string]string{
+	"01": "facturas",
+	"03": "liquidaciones_compra",
+	"04": "notas_credito",
+	"05": "notas_debito",
+	"06": "guias_remision",
+	"07": "retenciones",
+}
+
+// ExportArchive escribe en outputPath un ZIP con los comprobantes autorizados emitidos entre from y
+// to de los tipos indicados, para conservarlos durante el plazo que exige el SRI. Por cada
+// comprobante guarda el XML autorizado (con su <autorizacion>) y el RIDE regenerado, más un índice
+// CSV y un manifiesto SHA-256 para verificar después que el archivo no fue alterado.
+// Devuelve la cantidad de comprobantes archivados.
+func (s *SriService) ExportArchive(ctx context.Context, from, to time.Time, receiptTypes []string, outputPath string) (int, error) {
+	if len(receiptTypes) == 0 {
+		return 0, errors.New("seleccione al menos un tipo de comprobante")
+	}
+	if to.Before(from) {
+		return 0, errors.New("la fecha final no puede ser anterior a la inicial")
+	}
+
+	receipts, err := s.receiptRepo.FindAuthorized(ctx, from, to, receiptTypes)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo comprobantes autorizados: %w", err)
+	}
+	if len(receipts) == 0 {
+		return 0, errors.New("no hay comprobantes autorizados en el período seleccionado")
+	}
+	// La fecha de autorización va en el <autorizacion> del archivo legal: no se inventa una.
+	var undated []string
+	for _, r := range receipts {
+		if r.AuthorizationDate == nil {
+			undated = append(undated, r.AccessKey)
+		}
+	}
+	if len(undated) > 0 {
+		return 0, fmt.Errorf("los siguientes comprobantes no tienen fecha de autorización registrada; sincronícelos con el SRI antes de exportar: %s", strings.Join(undated, ", "))
+	}
+
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo emisor: %w", err)
+	}
+	if issuer == nil {
+		return 0, errors.New("no hay un emisor activo configurado")
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return 0, fmt.Errorf("error creando el archivo: %w", err)
+	}
+	archive := &archiveWriter{zw: zip.NewWriter(file)}
+
+	err = s.writeArchive(ctx, archive, receipts, issuer.LogoPath)
+	if closeErr := archive.zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(outputPath)
+		return 0, err
+	}
+	return len(receipts), nil
+}
+
+func (s *SriService) writeArchive(ctx context.Context, archive *archiveWriter, receipts []domain.ElectronicReceipt, logoPath string) error {
+	var index bytes.Buffer
+	w := csv.NewWriter(&index)
+	_ = w.Write([]string{
+		"Clave de Acceso", "Tipo", "Número", "Fecha Emisión", "Fecha Autorización",
+		"Cliente/Proveedor", "Total", "Ambiente", "XML", "RIDE",
+	})
+
+	for i := range receipts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r := &receipts[i]
+
+		key, err := sri.ParseAccessKey(r.AccessKey)
+		if err != nil {
+			return fmt.Errorf("comprobante %s: %w", r.AccessKey, err)
+		}
+		authDate := *r.AuthorizationDate
+
+		folder := archiveFolders[r.ReceiptType]
+		if folder == "" {
+			folder = "otros"
+		}
+		xmlName := path.Join(folder, r.AccessKey+".xml")
+		rideName := path.Join(folder, r.AccessKey+".pdf")
+
+		authorized, err := sri.MarshalAutorizacion(r.AccessKey, authDate, r.Environment, r.XMLContent)
+		if err != nil {
+			return fmt.Errorf("comprobante %s: %w", r.AccessKey, err)
+		}
+		if err := archive.add(xmlName, authorized, authDate); err != nil {
+			return err
+		}
+
+		ride, err := s.rideBytes(r, logoPath, authDate)
+		if err != nil {
+			return fmt.Errorf("comprobante %s: %w", r.AccessKey, err)
+		}
+		if err := archive.add(rideName, ride, authDate); err != nil {
+			return err
+		}
+
+		environment := "PRUEBAS"
+		if r.Environment == 2 {
+			environment = "PRODUCCIÓN"
+		}
+		total := ""
+		if r.TransactionID != 0 {
+			total = fmt.Sprintf("%.2f", r.TotalAmount)
+		}
+		_ = w.Write([]string{
+			r.AccessKey,
+			key.DocTypeName(),
+			key.DocumentNumber(),
+			key.Date.Format("02/01/2006"),
+			authDate.Format("02/01/2006 15:04:05"),
+			r.ClientName,
+			total,
+			environment,
+			xmlName,
+			rideName,
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error escribiendo el índice: %w", err)
+	}
+	if err := archive.add(ArchiveIndexName, index.Bytes(), time.Now()); err != nil {
+		return err
+	}
+	// El manifiesto va al final para cubrir todos los archivos, índice incluido.
+	return archive.writeManifest()
+}
+
+// rideBytes regenera el RIDE de un comprobante desde su XML.
+func (s *SriService) rideBytes(r *domain.ElectronicReceipt, logoPath string, authDate time.Time) ([]byte, error) {
+	tmpFile, err := os.CreateTemp("", fmt.Sprintf("ride-%s-*.pdf", r.AccessKey))
+	if err != nil {
+		return nil, fmt.Errorf("error creando archivo temporal: %w", err)
+	}
+	outputPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(outputPath)
+
+	if err := s.renderRide(r.ReceiptType, r.XMLContent, outputPath, logoPath, authDate, r.AccessKey); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(outputPath)
+}
+
+// archiveWriter escribe los archivos del ZIP y va armando su manifiesto SHA-256.
+type archiveWriter struct {
+	zw   *zip.Writer
+	sums strings.Builder
+}
+
+func (a *archiveWriter) add(name string, data []byte, modified time.Time) error {
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("error agregando %s al archivo: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("error agregando %s al archivo: %w", name, err)
+	}
+	fmt.Fprintf(&a.sums, "%x  %s\n", sha256.Sum256(data), name)
+	return nil
+}
+
+func (a *archiveWriter) writeManifest() error {
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: ArchiveManifestName, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("error escribiendo el manifiesto: %w", err)
+	}
+	if _, err := w.Write([]byte(a.sums.String())); err != nil {
+		return fmt.Errorf("error escribiendo el manifiesto: %w", err)
+	}
+	return nil
+}
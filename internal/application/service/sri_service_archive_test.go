@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func archiveFactura(t *testing.T) (string, string) {
+	t.Helper()
+	key := sri.GenerateAccessKey(time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local), "01", "1790000000001", 2, "001", "001", "000000042", "12345678", 1)
+
+	f := &sri.Factura{}
+	f.InfoTributaria = sri.InfoTributaria{
+		Ambiente: "2", TipoEmision: "1", RazonSocial: "Mi Empresa S.A.", Ruc: "1790000000001",
+		ClaveAcceso: key, CodDoc: "01", Estab: "001", PtoEmi: "001", Secuencial: "000000042", DirMatriz: "Quito",
+	}
+	f.InfoFactura = sri.InfoFactura{
+		FechaEmision: "05/03/2026", ObligadoContabilidad: "NO", TipoIdentificacionComprador: "05",
+		RazonSocialComprador: "Juan Perez", IdentificacionComprador: "1712345678",
+		TotalSinImpuestos: "100.00", TotalDescuento: "0.00", Propina: "0.00", ImporteTotal: "115.00", Moneda: "DOLAR",
+	}
+	f.InfoFactura.TotalConImpuestos.TotalImpuesto = []sri.TotalImpuesto{{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "100.00", Valor: "15.00"}}
+	f.InfoFactura.Pagos.Pago = []sri.Pago{{FormaPago: "01", Total: "115.00"}}
+	det := sri.Detalle{Descripcion: "Servicio", Cantidad: "1.000000", PrecioUnitario: "100.000000", Descuento: "0.00", PrecioTotalSinImpuesto: "100.00"}
+	det.Impuestos.Impuesto = []sri.Impuesto{{Codigo: "2", CodigoPorcentaje: "4", Tarifa: "15", BaseImponible: "100.00", Valor: "15.00"}}
+	f.Detalles.Detalle = []sri.Detalle{det}
+
+	xmlBytes, err := sri.MarshalFactura(f)
+	require.NoError(t, err)
+	return string(xmlBytes), key
+}
+
+func TestExportArchive(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	types := []string{"01", "04"}
+
+	t.Run("Writes the authorized XML, RIDE, index and manifest", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+		facturaXML, key := archiveFactura(t)
+		authDate := time.Date(2026, 3, 5, 10, 30, 0, 0, time.UTC)
+
+		m.ReceiptRepo.On("FindAuthorized", ctx, from, to, types).Return([]domain.ElectronicReceipt{{
+			TransactionID: 7, AccessKey: key, ReceiptType: "01", XMLContent: facturaXML,
+			AuthorizationDate: &authDate, SRIStatus: domain.ReceiptAuthorized, Environment: 2,
+			ClientName: "Juan Perez", TotalAmount: 115,
+		}}, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(&domain.Issuer{RUC: "1790000000001"}, nil).Once()
+
+		outputPath := filepath.Join(t.TempDir(), "archivo.zip")
+		count, err := svc.ExportArchive(ctx, from, to, types, outputPath)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		zr, err := zip.OpenReader(outputPath)
+		require.NoError(t, err)
+		defer zr.Close()
+
+		files := map[string][]byte{}
+		var names []string
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			rc.Close()
+			files[f.Name] = data
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"facturas/" + key + ".xml", "facturas/" + key + ".pdf", service.ArchiveIndexName, service.ArchiveManifestName}, names)
+
+		inv, err := sri.ParseAuthorizedInvoice(files["facturas/"+key+".xml"])
+		require.NoError(t, err)
+		assert.Equal(t, key, inv.NumeroAutorizacion)
+		assert.Equal(t, "115.00", inv.Factura.InfoFactura.ImporteTotal)
+		assert.True(t, strings.HasPrefix(string(files["facturas/"+key+".pdf"]), "%PDF"))
+
+		rows, err := csv.NewReader(strings.NewReader(string(files[service.ArchiveIndexName]))).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, []string{
+			key, "Factura", "001-001-000000042", "05/03/2026", "05/03/2026 10:30:00",
+			"Juan Perez", "115.00", "PRODUCCIÓN", "facturas/" + key + ".xml", "facturas/" + key + ".pdf",
+		}, rows[1])
+
+		// Cada línea del manifiesto debe coincidir con el contenido del archivo
+		manifest := strings.Split(strings.TrimSpace(string(files[service.ArchiveManifestName])), "\n")
+		require.Len(t, manifest, 3)
+		for _, line := range manifest {
+			sum, name, ok := strings.Cut(line, "  ")
+			require.True(t, ok, line)
+			assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(files[name])), sum, name)
+		}
+	})
+
+	t.Run("Error: no receipts in the period", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+		m.ReceiptRepo.On("FindAuthorized", ctx, from, to, types).Return(nil, nil).Once()
+
+		outputPath := filepath.Join(t.TempDir(), "archivo.zip")
+		_, err := svc.ExportArchive(ctx, from, to, types, outputPath)
+		assert.ErrorContains(t, err, "no hay comprobantes autorizados")
+		assert.NoFileExists(t, outputPath)
+	})
+
+	t.Run("Error: receipt without authorization date", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+		facturaXML, key := archiveFactura(t)
+		m.ReceiptRepo.On("FindAuthorized", ctx, from, to, types).Return([]domain.ElectronicReceipt{{
+			AccessKey: key, ReceiptType: "01", XMLContent: facturaXML, SRIStatus: domain.ReceiptAuthorized, Environment: 2,
+		}}, nil).Once()
+
+		outputPath := filepath.Join(t.TempDir(), "archivo.zip")
+		_, err := svc.ExportArchive(ctx, from, to, types, outputPath)
+		assert.ErrorContains(t, err, "no tienen fecha de autorización")
+		assert.ErrorContains(t, err, key)
+		assert.NoFileExists(t, outputPath)
+		m.IssuerRepo.AssertNotCalled(t, "GetActive")
+	})
+
+	t.Run("Error: no active issuer", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+		facturaXML, key := archiveFactura(t)
+		authDate := time.Date(2026, 3, 5, 10, 30, 0, 0, time.UTC)
+		m.ReceiptRepo.On("FindAuthorized", ctx, from, to, types).Return([]domain.ElectronicReceipt{{
+			AccessKey: key, ReceiptType: "01", XMLContent: facturaXML, AuthorizationDate: &authDate,
+			SRIStatus: domain.ReceiptAuthorized, Environment: 2,
+		}}, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(nil, nil).Once()
+
+		outputPath := filepath.Join(t.TempDir(), "archivo.zip")
+		_, err := svc.ExportArchive(ctx, from, to, types, outputPath)
+		assert.EqualError(t, err, "no hay un emisor activo configurado")
+		assert.NoFileExists(t, outputPath)
+	})
+
+	t.Run("Error: invalid parameters", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		_, err := svc.ExportArchive(ctx, from, to, nil, "archivo.zip")
+		assert.ErrorContains(t, err, "tipo de comprobante")
+		_, err = svc.ExportArchive(ctx, to, from, types, "archivo.zip")
+		assert.ErrorContains(t, err, "fecha final")
+		m.ReceiptRepo.AssertNotCalled(t, "FindAuthorized")
+	})
+}
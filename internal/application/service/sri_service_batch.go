@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// errLoteDevuelto indica que el SRI devolvió el lote completo y no comprobantes puntuales; sus
+// comprobantes se reenvían uno por uno.
+var errLoteDevuelto = errors.New("el SRI devolvió el lote completo")
+
+// batchGroup son los comprobantes que pueden ir en un mismo lote: mismo ambiente, tipo y emisor.
+type batchGroup struct {
+	key      *sri.AccessKey
+	receipts []*domain.ElectronicReceipt
+}
+
+// syncBatches envía en lotes los comprobantes que nunca llegaron al SRI cuando son al menos
+// BatchThreshold. Devuelve los comprobantes que quedan para la sincronización individual y
+// cuántos se autorizaron.
+func (s *SriService) syncBatches(ctx context.Context, pending []domain.ElectronicReceipt) ([]domain.ElectronicReceipt, int, error) {
+	if s.BatchThreshold <= 0 {
+		return pending, 0, nil
+	}
+
+	var rest []domain.ElectronicReceipt
+	var groups []*batchGroup
+	byGroup := map[string]*batchGroup{}
+	unsent := 0
+	for i := range pending {
+		r := &pending[i]
+		key, err := sri.ParseAccessKey(r.AccessKey)
+		if err != nil || !isUnsent(r.SRIStatus) {
+			rest = append(rest, *r)
+			continue
+		}
+		id := fmt.Sprintf("%d-%s-%s", key.Environment, key.DocType, key.RUC)
+		g, ok := byGroup[id]
+		if !ok {
+			g = &batchGroup{key: key}
+			byGroup[id] = g
+			groups = append(groups, g)
+		}
+		g.receipts = append(g.receipts, r)
+		unsent++
+	}
+	if unsent < s.BatchThreshold {
+		return pending, 0, nil
+	}
+
+	authorized := 0
+	for _, g := range groups {
+		if len(g.receipts) == 1 {
+			rest = append(rest, *g.receipts[0])
+			continue
+		}
+		for start := 0; start < len(g.receipts); start += sri.MaxLoteComprobantes {
+			chunk := g.receipts[start:min(start+sri.MaxLoteComprobantes, len(g.receipts))]
+			n, err := s.syncBatch(ctx, g.key, chunk)
+			authorized += n
+			switch {
+			case err == nil:
+			case errors.Is(err, errLoteDevuelto):
+				s.logger.Printf("Lote devuelto por el SRI; se envían sus %d comprobantes individualmente", len(chunk))
+				for _, r := range chunk {
+					rest = append(rest, *r)
+				}
+			case errors.Is(err, sri.ErrCircuitOpen):
+				return rest, authorized, err
+			default:
+				s.logger.Printf("Error sincronizando lote de %d comprobantes: %v", len(chunk), err)
+			}
+		}
+	}
+	return rest, authorized, nil
+}
+
+// isUnsent indica si el comprobante todavía no fue recibido por el SRI.
+func isUnsent(status domain.ReceiptStatus) bool {
+	return status == domain.ReceiptPending || status == domain.ReceiptSendError || status == domain.ReceiptNetworkError
+}
+
+// syncBatch envía los comprobantes en un lote, registra el resultado de recepción de cada uno y
+// consulta la autorización del lote hasta BatchPolls veces. Los que no terminan quedan RECIBIDA
+// para la sincronización individual. Devuelve cuántos se autorizaron.
+func (s *SriService) syncBatch(ctx context.Context, key *sri.AccessKey, receipts []*domain.ElectronicReceipt) (int, error) {
+	now := time.Now()
+	loteKey := sri.GenerateLoteAccessKey(now, key, fmt.Sprintf("%09d", now.UnixMilli()%1e9), newNumericCode())
+
+	xmls := make([]string, len(receipts))
+	byKey := make(map[string]*domain.ElectronicReceipt, len(receipts))
+	for i, r := range receipts {
+		xmls[i] = r.XMLContent
+		byKey[r.AccessKey] = r
+	}
+	loteXML, err := sri.MarshalLote(loteKey, key.RUC, xmls)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Printf("Enviando lote %s con %d comprobantes", loteKey, len(receipts))
+	resp, err := s.sriClient.EnviarComprobante(ctx, loteXML, key.Environment)
+	if err != nil {
+		status := sendErrorStatus(err)
+		for _, r := range receipts {
+			s.recordStatus(ctx, r.AccessKey, status, err.Error(), nil)
+		}
+		return 0, err
+	}
+
+	// Una devolución lista los comprobantes rechazados; el resto del lote quedó recibido
+	if resp.Estado == "DEVUELTA" {
+		if len(resp.Comprobantes.Comprobante) == 0 {
+			return 0, errLoteDevuelto
+		}
+		for _, c := range resp.Comprobantes.Comprobante {
+			if _, ok := byKey[c.ClaveAcceso]; !ok {
+				return 0, errLoteDevuelto
+			}
+		}
+	}
+
+	waiting := make(map[string]*domain.ElectronicReceipt, len(receipts))
+	for _, r := range receipts {
+		waiting[r.AccessKey] = r
+	}
+	for _, c := range resp.Comprobantes.Comprobante {
+		rej := &sri.RejectionError{ClaveAcceso: c.ClaveAcceso, Estado: "DEVUELTA", Mensajes: c.Mensajes.Mensaje}
+		if resp.Estado != "DEVUELTA" || rej.EnProcesamiento() {
+			// Ya se había recibido en un envío anterior; solo falta su autorización
+			continue
+		}
+		msg := "Comprobante Devuelto"
+		if len(rej.Mensajes) > 0 {
+			msg = fmt.Sprintf("%s: %s", rej.Mensajes[0].Identificador, rej.Mensajes[0].Mensaje)
+		}
+		s.recordStatus(ctx, c.ClaveAcceso, domain.ReceiptReturned, msg, nil)
+		byKey[c.ClaveAcceso].SRIStatus = domain.ReceiptReturned
+		delete(waiting, c.ClaveAcceso)
+	}
+	for _, r := range waiting {
+		s.recordStatus(ctx, r.AccessKey, domain.ReceiptReceived, "Recibido por SRI en lote", nil)
+		r.SRIStatus = domain.ReceiptReceived
+	}
+
+	authorized := 0
+	for attempt := 0; attempt < s.BatchPolls && len(waiting) > 0; attempt++ {
+		if err := s.waitForAuthorization(ctx); err != nil {
+			return authorized, err
+		}
+		authResp, err := s.sriClient.AutorizarLote(ctx, loteKey, key.Environment)
+		if err != nil {
+			return authorized, err
+		}
+		for i := range authResp.Autorizaciones.Autorizacion {
+			auth := &authResp.Autorizaciones.Autorizacion[i]
+			r, ok := waiting[auth.ClaveAcceso()]
+			if !ok {
+				continue
+			}
+			status, err := s.applyAuthorization(ctx, r, auth)
+			if err != nil {
+				s.logger.Printf("Comprobante %s del lote %s rechazado: %v", r.AccessKey, loteKey, err)
+			}
+			if status == domain.ReceiptAuthorized {
+				authorized++
+			}
+			if status.IsFinal() {
+				delete(waiting, r.AccessKey)
+			}
+		}
+	}
+	return authorized, nil
+}
@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/nelsonmarro/verith/internal/sri/srisim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessBackgroundSync_Lotes(t *testing.T) {
+	ctx := context.Background()
+
+	pendiente := func(secuencial string) domain.ElectronicReceipt {
+		key := sri.GenerateAccessKey(time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local), "01", "1790012345001", 1, "001", "001", secuencial, "12345678", 1)
+		return domain.ElectronicReceipt{
+			AccessKey:   key,
+			XMLContent:  fmt.Sprintf(`<factura><infoTributaria><claveAcceso>%s</claveAcceso></infoTributaria></factura>`, key),
+			SRIStatus:   "PENDIENTE",
+			Environment: 1,
+		}
+	}
+
+	newService := func(t *testing.T, client sri.Client) (*service.SriService, *sriMocks) {
+		svc, m := newTestSriServiceWithClient(t, client)
+		// La generación del RIDE tras autorizar corre en segundo plano y no es parte de esta prueba
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(nil, errors.New("sin emisor")).Maybe()
+		svc.BatchThreshold = 3
+		return svc, m
+	}
+
+	t.Run("Envía los pendientes en un lote y asigna el resultado a cada comprobante", func(t *testing.T) {
+		sim := srisim.NewServer(srisim.Scenario{PollsEnProceso: 1})
+		srv := httptest.NewServer(sim)
+		defer srv.Close()
+		client := sri.NewSoapClient().WithBaseURL(srv.URL)
+		client.Timeout = 2 * time.Second
+
+		ok1, ok2 := pendiente("000000201"), pendiente("000000202")
+		devuelto, rechazado := pendiente("000000203"), pendiente("000000204")
+		recibido := pendiente("000000299")
+		recibido.SRIStatus = "RECIBIDA"
+		sim.SetScenario(devuelto.AccessKey, srisim.Scenario{Devuelta: []sri.Mensaje{srisim.Error(srisim.ErrFirmaInvalida)}})
+		sim.SetScenario(rechazado.AccessKey, srisim.Scenario{NoAutorizado: []sri.Mensaje{srisim.Error(srisim.ErrSecuencialRepetido)}})
+
+		svc, m := newService(t, client)
+		m.ReceiptRepo.On("FindPendingReceipts", ctx).
+			Return([]domain.ElectronicReceipt{ok1, recibido, ok2, devuelto, rechazado}, nil).Once()
+		for _, r := range []domain.ElectronicReceipt{ok1, ok2, rechazado} {
+			m.ReceiptRepo.On("UpdateStatus", ctx, r.AccessKey, "RECIBIDA", "Recibido por SRI en lote", (*time.Time)(nil)).Return(nil).Once()
+		}
+		m.ReceiptRepo.On("UpdateStatus", ctx, devuelto.AccessKey, "DEVUELTA", "39: FIRMA INVALIDA", (*time.Time)(nil)).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, ok1.AccessKey, "AUTORIZADO", mock.Anything, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, ok2.AccessKey, "AUTORIZADO", mock.Anything, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, rechazado.AccessKey, "RECHAZADA", "SECUENCIAL REGISTRADO", mock.Anything).Return(nil).Once()
+		// El comprobante ya recibido sigue el camino individual; el simulador no lo conoce
+		m.ReceiptRepo.On("UpdateStatus", ctx, recibido.AccessKey, "EN PROCESO", mock.Anything, mock.Anything).Return(nil).Once()
+
+		count, err := svc.ProcessBackgroundSync(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		m.ReceiptRepo.AssertExpectations(t)
+		for _, r := range []domain.ElectronicReceipt{ok1, ok2, rechazado} {
+			_, polls := sim.Received(r.AccessKey)
+			assert.Equal(t, 2, polls, "una consulta EN PROCESO y la final, todas por la clave del lote")
+		}
+	})
+
+	t.Run("Un lote devuelto completo se reenvía comprobante por comprobante", func(t *testing.T) {
+		r1, r2, r3 := pendiente("000000301"), pendiente("000000302"), pendiente("000000303")
+		mockSriClient := new(mocks.MockSRIClient)
+		loteDevuelto := &sri.RespuestaRecepcion{Estado: "DEVUELTA"}
+		mockSriClient.On("EnviarComprobante", mock.Anything, mock.MatchedBy(func(data []byte) bool {
+			return len(data) > 0 && string(data[:5]) == "<?xml"
+		}), 1).Return(loteDevuelto, nil).Once()
+		for _, r := range []domain.ElectronicReceipt{r1, r2, r3} {
+			mockSriClient.On("EnviarComprobante", mock.Anything, []byte(r.XMLContent), 1).Return(nil, errors.New("timeout")).Once()
+		}
+
+		svc, m := newService(t, mockSriClient)
+		m.ReceiptRepo.On("FindPendingReceipts", ctx).Return([]domain.ElectronicReceipt{r1, r2, r3}, nil).Once()
+		for _, r := range []domain.ElectronicReceipt{r1, r2, r3} {
+			m.ReceiptRepo.On("UpdateStatus", ctx, r.AccessKey, "ERROR_RED", "timeout", (*time.Time)(nil)).Return(nil).Once()
+		}
+
+		count, err := svc.ProcessBackgroundSync(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		mockSriClient.AssertExpectations(t)
+		m.ReceiptRepo.AssertExpectations(t)
+		mockSriClient.AssertNotCalled(t, "AutorizarLote", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
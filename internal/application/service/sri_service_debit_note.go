@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// EmitirNotaDebito emite una Nota de Débito (05) para cobrar intereses o recargos sobre una
+// factura ya autorizada. debitTxID es el ingreso que registra el cargo; sus ítems son los motivos.
+func (s *SriService) EmitirNotaDebito(ctx context.Context, debitTxID int, originalTxID int, signaturePassword string) (string, error) {
+	s.logger.Printf("Iniciando emisión de Nota de Débito sobre factura ID: %d", originalTxID)
+
+	// 1. Cargar Datos
+	debitTx, err := s.txRepo.GetTransactionByID(ctx, debitTxID)
+	if err != nil {
+		return "", fmt.Errorf("error cargando transacción de la nota de débito: %w", err)
+	}
+	if debitTx.Category == nil || debitTx.Category.Type != domain.Income {
+		return "", errors.New("la nota de débito debe registrarse como una transacción de ingreso")
+	}
+	if debitTx.IsVoided {
+		return "", errors.New("la transacción está anulada")
+	}
+	if debitTx.RelatedTransactionID != nil && *debitTx.RelatedTransactionID != originalTxID {
+		return "", errors.New("la transacción no corresponde a la factura indicada")
+	}
+
+	originalTx, err := s.txRepo.GetTransactionByID(ctx, originalTxID)
+	if err != nil {
+		return "", fmt.Errorf("error cargando factura original: %w", err)
+	}
+	if originalTx.ElectronicReceipt == nil || originalTx.ElectronicReceipt.ReceiptType != "01" || originalTx.ElectronicReceipt.SRIStatus != "AUTORIZADO" {
+		return "", errors.New("la transacción original no tiene una factura autorizada")
+	}
+	if originalTx.TaxPayerID == nil {
+		return "", errors.New("la factura original no tiene cliente asignado")
+	}
+
+	// Solo se reemplaza un comprobante que falló definitivamente (mismo criterio que EmitirFactura)
+	existing := debitTx.ElectronicReceipt
+	if existing != nil {
+		switch {
+		case existing.ReceiptType != "05":
+			return "", fmt.Errorf("la transacción ya tiene un comprobante electrónico de tipo %s", existing.ReceiptType)
+		case existing.CanReissue(time.Now()):
+			s.logger.Printf("Nota de Débito anterior falló (%s). Se emitirá con nueva Clave de Acceso.", existing.SRIStatus)
+		default:
+			return "", fmt.Errorf("la transacción ya tiene una nota de débito en estado %s", existing.SRIStatus)
+		}
+	}
+
+	items, err := s.txRepo.GetItemsByTransactionID(ctx, debitTxID)
+	if err != nil {
+		return "", fmt.Errorf("error cargando motivos de la nota de débito: %w", err)
+	}
+	debitTx.Items = items
+
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil || issuer == nil {
+		return "", errors.New("no hay un emisor activo configurado")
+	}
+
+	client, err := s.clientRepo.GetByID(ctx, *originalTx.TaxPayerID)
+	if err != nil || client == nil {
+		return "", errors.New("error obteniendo cliente de la factura original")
+	}
+
+	taxes, err := s.taxSummary(ctx, debitTx, debitTx.TransactionDate)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. Secuencial y Clave de Acceso (Tipo 05)
+	secuencialSRI, err := s.nextSequential(ctx, issuer, "05")
+	if err != nil {
+		return "", err
+	}
+
+	emissionDate := time.Now()
+	claveAcceso := sri.GenerateAccessKey(
+		emissionDate,
+		"05",
+		issuer.RUC,
+		issuer.Environment,
+		issuer.EstablishmentCode,
+		issuer.EmissionPointCode,
+		secuencialSRI,
+		newNumericCode(),
+		1,
+	)
+
+	// 3. Generar y Firmar XML
+	ndXML := s.mapToNotaDebito(debitTx, originalTx, taxes, issuer, client, claveAcceso, secuencialSRI, emissionDate)
+	xmlBytes, err := sri.MarshalNotaDebito(ndXML)
+	if err != nil {
+		return "", err
+	}
+
+	signerObj := s.signerFactory(issuer.SignaturePath, signaturePassword)
+	signedXML, err := signerObj.SignDocument(xmlBytes, sri.SHA1)
+	if err != nil {
+		s.logger.Printf("Error al firmar nota de débito: %v", err)
+		return "", signatureError(err)
+	}
+
+	// 4. Guardar Recibo (vinculado a la transacción del cargo, no a la factura)
+	receipt := &domain.ElectronicReceipt{
+		TransactionID: debitTx.ID,
+		IssuerID:      issuer.ID,
+		TaxPayerID:    client.ID,
+		AccessKey:     claveAcceso,
+		ReceiptType:   "05",
+		XMLContent:    strings.TrimSpace(string(signedXML)),
+		Environment:   issuer.Environment,
+	}
+	if err := s.savePendingReceipt(ctx, existing, receipt); err != nil {
+		return "", err
+	}
+
+	// 5. Enviar y Autorizar
+	return s.submitAndAuthorize(ctx, receipt, "la nota de débito")
+}
+
+func (s *SriService) mapToNotaDebito(debitTx, originalTx *domain.Transaction, taxes *domain.TaxSummary, issuer *domain.Issuer, client *domain.TaxPayer, claveAcceso, secuencial string, emissionDate time.Time) *sri.NotaDebito {
+	nd := &sri.NotaDebito{}
+
+	nd.InfoTributaria = sri.InfoTributaria{
+		Ambiente:        strconv.Itoa(issuer.Environment),
+		TipoEmision:     "1",
+		RazonSocial:     cleanText(issuer.BusinessName),
+		NombreComercial: cleanText(issuer.TradeName),
+		Ruc:             issuer.RUC,
+		ClaveAcceso:     claveAcceso,
+		CodDoc:          "05", // Nota de Débito
+		Estab:           issuer.EstablishmentCode,
+		PtoEmi:          issuer.EmissionPointCode,
+		Secuencial:      secuencial,
+		DirMatriz:       cleanText(issuer.MainAddress),
+	}
+
+	// Número de la factura modificada a partir de su clave de acceso (001-001-000000123)
+	originalDocNum := "000-000-000000000"
+	if key, err := sri.ParseAccessKey(originalTx.ElectronicReceipt.AccessKey); err == nil {
+		originalDocNum = key.DocumentNumber()
+	}
+
+	// Cargos sin desglose se declaran en tarifa 0%
+	totals := withZeroRateFallback(taxes.Totals, debitTx.Amount)
+	totalStr := fmt.Sprintf("%.2f", debitTx.Amount)
+
+	nd.InfoNotaDebito = sri.InfoNotaDebito{
+		FechaEmision:                emissionDate.Format("02/01/2006"),
+		DirEstablecimiento:          cleanText(issuer.EstablishmentAddress),
+		TipoIdentificacionComprador: client.IdentificationType,
+		RazonSocialComprador:        cleanText(client.Name),
+		IdentificacionComprador:     client.Identification,
+		ObligadoContabilidad:        map[bool]string{true: "SI", false: "NO"}[issuer.KeepAccounting],
+		CodDocModificado:            "01", // Factura
+		NumDocModificado:            originalDocNum,
+		FechaEmisionDocSustento:     originalTx.TransactionDate.Format("02/01/2006"),
+		TotalSinImpuestos:           fmt.Sprintf("%.2f", taxBase(totals)),
+		ValorTotal:                  totalStr,
+	}
+
+	for _, total := range totals {
+		nd.InfoNotaDebito.Impuestos.Impuesto = append(nd.InfoNotaDebito.Impuestos.Impuesto, toImpuesto(total))
+	}
+
+	nd.InfoNotaDebito.Pagos.Pago = append(nd.InfoNotaDebito.Pagos.Pago, sri.Pago{
+		FormaPago: "01", // Sin utilización del sistema financiero, igual que la factura
+		Total:     totalStr,
+	})
+
+	// Cada ítem del cargo es un motivo; sin ítems usamos la descripción de la transacción
+	for _, item := range debitTx.Items {
+		nd.Motivos.Motivo = append(nd.Motivos.Motivo, sri.Motivo{
+			Razon: cleanText(item.Description),
+			Valor: fmt.Sprintf("%.2f", item.Subtotal),
+		})
+	}
+	if len(nd.Motivos.Motivo) == 0 {
+		nd.Motivos.Motivo = append(nd.Motivos.Motivo, sri.Motivo{
+			Razon: cleanText(debitTx.Description),
+			Valor: nd.InfoNotaDebito.TotalSinImpuestos,
+		})
+	}
+
+	return nd
+}
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirNotaDebito(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+
+	clientID := 5
+	client := &domain.TaxPayer{
+		Identification:     "1712345678",
+		IdentificationType: "05",
+		Name:               "Cliente Test",
+		Email:              "cliente@test.com",
+	}
+	client.ID = clientID
+
+	// Clave de la factura original: establecimiento 001, punto 002, secuencial 000000077
+	originalKey := "0101202601179000000000110010020000000771234567818"
+
+	originalTx := func() *domain.Transaction {
+		return &domain.Transaction{
+			BaseEntity:        domain.BaseEntity{ID: 10},
+			TransactionDate:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Amount:            115.00,
+			TaxPayerID:        &clientID,
+			Category:          &domain.Category{Type: domain.Income},
+			ElectronicReceipt: &domain.ElectronicReceipt{ReceiptType: "01", SRIStatus: "AUTORIZADO", AccessKey: originalKey},
+		}
+	}
+
+	debitTx := func() *domain.Transaction {
+		relatedID := 10
+		return &domain.Transaction{
+			BaseEntity:           domain.BaseEntity{ID: 20},
+			TransactionDate:      time.Now(),
+			Description:          "Nota de Débito - Factura ING-001",
+			Amount:               11.50,
+			Subtotal15:           10.00,
+			TaxAmount:            1.50,
+			TaxPayerID:           &clientID,
+			RelatedTransactionID: &relatedID,
+			Category:             &domain.Category{Type: domain.Income},
+		}
+	}
+
+	t.Run("Success: genera XML 05 referenciando la factura", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", ctx, 20).Return(debitTx(), nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, 10).Return(originalTx(), nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, 20).Return([]domain.TransactionItem{
+			{Description: "Intereses por mora", Quantity: 1, UnitPrice: 10, TaxRate: 4, Subtotal: 10},
+		}, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, clientID).Return(client, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "05").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 4}, ReceiptType: "05", CurrentSequence: 3}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, 4).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("SignDocument", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+
+		m.ReceiptRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.ElectronicReceipt) bool {
+			return r.ReceiptType == "05" && r.TransactionID == 20 && r.TaxPayerID == clientID
+		})).Return(nil).Once()
+
+		m.SriClient.On("EnviarComprobante", mock.Anything, []byte("<xml>signed</xml>"), issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.AnythingOfType("string"), issuer.Environment).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		key, err := svc.EmitirNotaDebito(ctx, 20, 10, "pass")
+		require.NoError(t, err)
+		assert.Equal(t, "05", key[8:10])
+
+		var nd sri.NotaDebito
+		require.NoError(t, xml.Unmarshal(unsigned, &nd))
+		assert.Equal(t, "1.0.0", nd.Version)
+		assert.Equal(t, "000000003", nd.InfoTributaria.Secuencial)
+		assert.Equal(t, "001-002-000000077", nd.InfoNotaDebito.NumDocModificado)
+		assert.Equal(t, "01/01/2026", nd.InfoNotaDebito.FechaEmisionDocSustento)
+		assert.Equal(t, "10.00", nd.InfoNotaDebito.TotalSinImpuestos)
+		assert.Equal(t, "11.50", nd.InfoNotaDebito.ValorTotal)
+		require.Len(t, nd.InfoNotaDebito.Impuestos.Impuesto, 1)
+		assert.Equal(t, "1.50", nd.InfoNotaDebito.Impuestos.Impuesto[0].Valor)
+		require.Len(t, nd.Motivos.Motivo, 1)
+		assert.Equal(t, "Intereses por mora", nd.Motivos.Motivo[0].Razon)
+
+		m.EpRepo.AssertExpectations(t)
+		m.Signer.AssertExpectations(t)
+		m.SriClient.AssertExpectations(t)
+	})
+
+	t.Run("Fallo: el SRI no autoriza y la historia guarda su mensaje", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", ctx, 20).Return(debitTx(), nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, 10).Return(originalTx(), nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, 20).Return([]domain.TransactionItem{
+			{Description: "Intereses por mora", Quantity: 1, UnitPrice: 10, TaxRate: 4, Subtotal: 10},
+		}, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, clientID).Return(client, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "05").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 4}, ReceiptType: "05", CurrentSequence: 3}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, 4).Return(nil).Once()
+		m.Signer.On("SignDocument", mock.Anything, signer.SHA1).Return([]byte("<xml>signed</xml>"), nil).Once()
+		m.ReceiptRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+
+		rejected := sri.Autorizacion{Estado: "NO AUTORIZADO"}
+		rejected.Mensajes.Mensaje = []sri.Mensaje{{Identificador: "52", Mensaje: "ERROR EN DIFERENCIAS", InformacionAdicional: "valor total"}}
+		resp := &sri.RespuestaAutorizacion{}
+		resp.Autorizaciones.Autorizacion = []sri.Autorizacion{rejected}
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.AnythingOfType("string"), issuer.Environment).Return(resp, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECHAZADA", "52: ERROR EN DIFERENCIAS (valor total)", mock.Anything).Return(nil).Once()
+
+		_, err := svc.EmitirNotaDebito(ctx, 20, 10, "pass")
+		assert.ErrorContains(t, err, "ERROR EN DIFERENCIAS")
+		m.ReceiptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fallo: factura original no autorizada", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		orig := originalTx()
+		orig.ElectronicReceipt.SRIStatus = "DEVUELTA"
+		m.TxRepo.On("GetTransactionByID", ctx, 20).Return(debitTx(), nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, 10).Return(orig, nil).Once()
+
+		_, err := svc.EmitirNotaDebito(ctx, 20, 10, "pass")
+		assert.ErrorContains(t, err, "factura autorizada")
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fallo: el cargo pertenece a otra factura", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", ctx, 20).Return(debitTx(), nil).Once()
+
+		_, err := svc.EmitirNotaDebito(ctx, 20, 99, "pass")
+		assert.ErrorContains(t, err, "no corresponde")
+	})
+}
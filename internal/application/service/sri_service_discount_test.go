@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_Descuentos(t *testing.T) {
+	ctx := context.Background()
+	txID := 200
+
+	issuer := testIssuer()
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", IdentificationType: "05", Name: "Test Client"}
+
+	// Silla: 2 x 50 con $10 de descuento de línea (IVA 15%); Servicio: 1 x 30 (IVA 0%).
+	// Descuento global de $12 prorrateado 90/30 => 9 y 3.
+	items := []domain.TransactionItem{
+		{Description: "Silla", Quantity: 2, UnitPrice: 50, Discount: 10, Subtotal: 90, TaxRate: 4},
+		{Description: "Servicio", Quantity: 1, UnitPrice: 30, Subtotal: 30, TaxRate: 0},
+	}
+	tx := &domain.Transaction{
+		BaseEntity:      domain.BaseEntity{ID: txID},
+		TransactionDate: time.Now(),
+		TaxPayerID:      &client.ID,
+		Discount:        12,
+		Items:           items,
+		Category:        &domain.Category{Type: domain.Income},
+	}
+	require.NoError(t, tx.RecalculateTotals(testTaxRates()))
+	require.Equal(t, 81.0, tx.Subtotal15)
+	require.Equal(t, 27.0, tx.Subtotal0)
+	require.Equal(t, 12.15, tx.TaxAmount)
+	require.Equal(t, 120.15, tx.Amount)
+	tx.Items = nil
+
+	svc, m := newTestSriService(t)
+
+	m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
+	m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return(items, nil).Once()
+	m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{}, nil).Once()
+	m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
+	m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+	m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+	m.EpRepo.On("GetByPoint", mock.Anything, issuer.ID, "001", "001", "01").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, CurrentSequence: 3}, nil)
+	m.EpRepo.On("IncrementSequence", mock.Anything, 10).Return(nil).Once()
+
+	var unsigned []byte
+	m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+		unsigned = args.Get(0).([]byte)
+	}).Return([]byte("<xml>signed</xml>"), nil).Once()
+	m.ReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+	m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+	require.NoError(t, svc.EmitirFactura(ctx, txID, "password"))
+
+	var f sri.Factura
+	require.NoError(t, xml.Unmarshal(unsigned, &f))
+	assert.Equal(t, "22.00", f.InfoFactura.TotalDescuento)
+	assert.Equal(t, "108.00", f.InfoFactura.TotalSinImpuestos)
+	assert.Equal(t, "120.15", f.InfoFactura.ImporteTotal)
+
+	require.Len(t, f.Detalles.Detalle, 2)
+	assert.Equal(t, "19.00", f.Detalles.Detalle[0].Descuento)
+	assert.Equal(t, "81.00", f.Detalles.Detalle[0].PrecioTotalSinImpuesto)
+	assert.Equal(t, "81.00", f.Detalles.Detalle[0].Impuestos.Impuesto[0].BaseImponible)
+	assert.Equal(t, "12.15", f.Detalles.Detalle[0].Impuestos.Impuesto[0].Valor)
+	assert.Equal(t, "3.00", f.Detalles.Detalle[1].Descuento)
+	assert.Equal(t, "27.00", f.Detalles.Detalle[1].PrecioTotalSinImpuesto)
+}
@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_PuntoDeEmision(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+	issuer.EstablishmentAddress = issuer.MainAddress
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", IdentificationType: "05", Name: "Test Client"}
+
+	setup := func(t *testing.T, txID int) (*service.SriService, *sriMocks) {
+		svc, m := newTestSriService(t)
+
+		estab, point := "002", "003"
+		items := []domain.TransactionItem{{Description: "Silla", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4}}
+		tx := &domain.Transaction{
+			BaseEntity:        domain.BaseEntity{ID: txID},
+			TransactionDate:   time.Now(),
+			TaxPayerID:        &client.ID,
+			Items:             items,
+			Category:          &domain.Category{Type: domain.Income},
+			EstablishmentCode: &estab,
+			EmissionPointCode: &point,
+		}
+		require.NoError(t, tx.RecalculateTotals(testTaxRates()))
+
+		m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return(items, nil).Once()
+		m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{}, nil).Once()
+		m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+		return svc, m
+	}
+
+	t.Run("Usa el código y la dirección del establecimiento de la venta", func(t *testing.T) {
+		svc, m := setup(t, 800)
+
+		m.EpRepo.On("GetEstablishment", mock.Anything, issuer.ID, "002").
+			Return(&domain.Establishment{IssuerID: issuer.ID, Code: "002", Name: "Sucursal Norte", Address: "Av. 6 de Diciembre N45", IsActive: true}, nil)
+		m.EpRepo.On("GetByPoint", mock.Anything, issuer.ID, "002", "003", "01").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 20}, CurrentSequence: 41}, nil)
+		m.EpRepo.On("IncrementSequence", mock.Anything, 20).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+		m.ReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		require.NoError(t, svc.EmitirFactura(ctx, 800, "password"))
+
+		var f sri.Factura
+		require.NoError(t, xml.Unmarshal(unsigned, &f))
+		assert.Equal(t, "002", f.InfoTributaria.Estab)
+		assert.Equal(t, "003", f.InfoTributaria.PtoEmi)
+		assert.Equal(t, "000000041", f.InfoTributaria.Secuencial)
+		assert.Equal(t, "002003", f.InfoTributaria.ClaveAcceso[24:30])
+		assert.Equal(t, "Av. Amazonas y Colón", f.InfoTributaria.DirMatriz)
+		assert.Equal(t, "Av. 6 de Diciembre N45", f.InfoFactura.DirEstablecimiento)
+		m.EpRepo.AssertExpectations(t)
+	})
+
+	t.Run("Rechaza un establecimiento inactivo sin consumir secuencial", func(t *testing.T) {
+		svc, m := setup(t, 801)
+
+		m.EpRepo.On("GetEstablishment", mock.Anything, issuer.ID, "002").
+			Return(&domain.Establishment{IssuerID: issuer.ID, Code: "002", Address: "Av. 6 de Diciembre N45", IsActive: false}, nil)
+
+		err := svc.EmitirFactura(ctx, 801, "password")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "establecimiento 002")
+
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+		m.Signer.AssertNotCalled(t, "Sign", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/mock"
+)
+
+// sriMocks agrupa las dependencias simuladas del SriService que arma newTestSriService.
+type sriMocks struct {
+	TxRepo       *mocks.MockTransactionRepository
+	IssuerRepo   *mocks.MockIssuerRepository
+	ReceiptRepo  *mocks.MockElectronicReceiptRepository
+	ClientRepo   *mocks.MockTaxPayerRepository
+	EpRepo       *mocks.MockEmissionPointRepository
+	ShipmentRepo *mocks.MockShipmentRepository
+	TaxRateRepo  *mocks.MockTaxRateRepository
+	SriClient    *mocks.MockSRIClient // nil si el servicio usa otro cliente
+	Mail         *mocks.MockMailService
+	Signer       *MockDocumentSigner
+}
+
+// newTestSriService arma un SriService con todas sus dependencias simuladas, el firmador simulado, el
+// catálogo de tarifas de IVA y sin espera antes de consultar la autorización.
+func newTestSriService(t *testing.T) (*service.SriService, *sriMocks) {
+	t.Helper()
+	client := new(mocks.MockSRIClient)
+	svc, m := newTestSriServiceWithClient(t, client)
+	m.SriClient = client
+	return svc, m
+}
+
+// newTestSriServiceWithClient es newTestSriService con otro cliente del SRI, como el del simulador.
+func newTestSriServiceWithClient(t *testing.T, client sri.Client) (*service.SriService, *sriMocks) {
+	t.Helper()
+	m := &sriMocks{
+		TxRepo:       new(mocks.MockTransactionRepository),
+		IssuerRepo:   new(mocks.MockIssuerRepository),
+		ReceiptRepo:  new(mocks.MockElectronicReceiptRepository),
+		ClientRepo:   new(mocks.MockTaxPayerRepository),
+		EpRepo:       new(mocks.MockEmissionPointRepository),
+		ShipmentRepo: new(mocks.MockShipmentRepository),
+		TaxRateRepo:  newTestTaxRateRepo(),
+		Mail:         new(mocks.MockMailService),
+		Signer:       new(MockDocumentSigner),
+	}
+	svc := service.NewSriService(m.TxRepo, m.IssuerRepo, m.ReceiptRepo, m.ClientRepo, m.EpRepo, m.ShipmentRepo, m.TaxRateRepo,
+		client, m.Mail, log.New(io.Discard, "", 0))
+	svc.AuthorizationDelay = 0
+	svc.SetSignerFactory(func(path, password string) service.DocumentSigner { return m.Signer })
+	return svc, m
+}
+
+// testIssuer es el emisor de las pruebas: matriz 001, punto 001, ambiente de pruebas.
+func testIssuer() *domain.Issuer {
+	return &domain.Issuer{
+		BaseEntity:        domain.BaseEntity{ID: 1},
+		RUC:               "1790012345001",
+		BusinessName:      "Empresa de Prueba S.A.",
+		MainAddress:       "Av. Amazonas y Colón",
+		EstablishmentCode: "001",
+		EmissionPointCode: "001",
+		Environment:       1,
+		SignaturePath:     "dummy.p12",
+	}
+}
+
+// newTestTaxRateRepo devuelve un repositorio de tarifas simulado que entrega testTaxRates.
+func newTestTaxRateRepo() *mocks.MockTaxRateRepository {
+	repo := new(mocks.MockTaxRateRepository)
+	repo.On("GetAll", mock.Anything).Return(testTaxRates(), nil).Maybe()
+	return repo
+}
+
+// testTaxRates es el catálogo de tarifas de IVA de las pruebas, el mismo que siembra la migración de tax_rates.
+func testTaxRates() domain.TaxRateTable {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	until := func(year int, month time.Month, day int) *time.Time {
+		d := date(year, month, day)
+		return &d
+	}
+	return domain.TaxRateTable{
+		{Code: 0, Percentage: 0, Description: "0%", ValidFrom: date(2000, 1, 1)},
+		{Code: 2, Percentage: 12, Description: "12%", General: true, ValidFrom: date(2000, 1, 1), ValidTo: until(2016, 5, 31)},
+		{Code: 3, Percentage: 14, Description: "14%", General: true, ValidFrom: date(2016, 6, 1), ValidTo: until(2017, 5, 31)},
+		{Code: 2, Percentage: 12, Description: "12%", General: true, ValidFrom: date(2017, 6, 1), ValidTo: until(2024, 3, 31)},
+		{Code: 4, Percentage: 15, Description: "15%", General: true, ValidFrom: date(2024, 4, 1)},
+		{Code: 5, Percentage: 5, Description: "5%", ValidFrom: date(2024, 4, 1)},
+		{Code: 6, Percentage: 0, Description: "No objeto de impuesto", ValidFrom: date(2000, 1, 1)},
+		{Code: 7, Percentage: 0, Description: "Exento de IVA", ValidFrom: date(2000, 1, 1)},
+		{Code: 8, Percentage: 8, Description: "IVA diferenciado (turismo)", ValidFrom: date(2023, 1, 1)},
+		{Code: 10, Percentage: 13, Description: "13%", ValidFrom: date(2025, 1, 1)},
+	}
+}
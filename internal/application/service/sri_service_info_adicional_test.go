@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_InfoAdicional(t *testing.T) {
+	ctx := context.Background()
+	txID := 400
+
+	issuer := testIssuer()
+	issuer.AdditionalFields = []domain.AdditionalField{{Name: "Sitio Web", Value: "www.empresa.ec"}}
+	client := &domain.TaxPayer{
+		BaseEntity:         domain.BaseEntity{ID: 50},
+		Identification:     "1712345678",
+		IdentificationType: "05",
+		Name:               "Test Client",
+		Email:              "cliente@test.com",
+		Phone:              "0991234567",
+		Address:            "Av. Amazonas\tN34",
+	}
+
+	setup := func(t *testing.T, fields []domain.AdditionalField) (*service.SriService, *sriMocks) {
+		svc, m := newTestSriService(t)
+
+		tx := &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: txID},
+			Amount:          11.5,
+			Subtotal15:      10,
+			TaxAmount:       1.5,
+			TransactionDate: time.Now(),
+			TaxPayerID:      &client.ID,
+			Category:        &domain.Category{Type: domain.Income},
+		}
+		m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return([]domain.TransactionItem{
+			{Description: "Item", Quantity: 1, UnitPrice: 10, Subtotal: 10, TaxRate: 4},
+		}, nil).Once()
+		m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{}, nil).Once()
+		m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return(fields, nil).Once()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+		return svc, m
+	}
+
+	t.Run("Success: combina cliente, emisor y transacción", func(t *testing.T) {
+		svc, m := setup(t, []domain.AdditionalField{
+			{Name: "Orden de Compra", Value: "OC-0012"},
+			{Name: "EMAIL", Value: "compras@cliente.com"}, // reemplaza el email del cliente
+		})
+
+		m.EpRepo.On("GetByPoint", mock.Anything, issuer.ID, "001", "001", "01").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, CurrentSequence: 2}, nil)
+		m.EpRepo.On("IncrementSequence", mock.Anything, 10).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+		m.ReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		require.NoError(t, svc.EmitirFactura(ctx, txID, "password"))
+
+		assert.Contains(t, string(unsigned), `<campoAdicional nombre="Orden de Compra">OC-0012</campoAdicional>`)
+
+		var f sri.Factura
+		require.NoError(t, xml.Unmarshal(unsigned, &f))
+		require.NotNil(t, f.InfoAdicional)
+		assert.Equal(t, []sri.CampoAdicional{
+			{Nombre: "Dirección", Valor: "Av. Amazonas N34"},
+			{Nombre: "Teléfono", Valor: "0991234567"},
+			{Nombre: "Email", Valor: "compras@cliente.com"},
+			{Nombre: "Sitio Web", Valor: "www.empresa.ec"},
+			{Nombre: "Orden de Compra", Valor: "OC-0012"},
+		}, f.InfoAdicional.CampoAdicional)
+	})
+
+	t.Run("Fallo: supera el máximo de campos del SRI", func(t *testing.T) {
+		var fields []domain.AdditionalField
+		for i := 0; i < 12; i++ {
+			fields = append(fields, domain.AdditionalField{Name: fmt.Sprintf("Campo %d", i), Value: "x"})
+		}
+		svc, m := setup(t, fields)
+
+		err := svc.EmitirFactura(ctx, txID, "password")
+		assert.ErrorContains(t, err, "máximo 15 campos adicionales")
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+		m.Signer.AssertNotCalled(t, "Sign", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_ICEIRBPNR(t *testing.T) {
+	ctx := context.Background()
+	txID := 700
+
+	issuer := testIssuer()
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", IdentificationType: "05", Name: "Test Client"}
+
+	items := []domain.TransactionItem{
+		{
+			Description: "Perfume", Quantity: 2, UnitPrice: 50, Subtotal: 100, TaxRate: 4,
+			Taxes: []domain.ItemTax{{TaxCode: domain.ItemTaxICE, PercentageCode: "3610", Rate: 20}},
+		},
+		{
+			Description: "Agua embotellada", Quantity: 10, UnitPrice: 0.5, Subtotal: 5, TaxRate: 4,
+			Taxes: []domain.ItemTax{{TaxCode: domain.ItemTaxIRBPNR, PercentageCode: "5001", Rate: domain.IRBPNRRate, Specific: true}},
+		},
+	}
+	tx := &domain.Transaction{
+		BaseEntity:      domain.BaseEntity{ID: txID},
+		TransactionDate: time.Now(),
+		TaxPayerID:      &client.ID,
+		Items:           items,
+		Category:        &domain.Category{Type: domain.Income},
+	}
+	require.NoError(t, tx.RecalculateTotals(testTaxRates()))
+	assert.Equal(t, 105.0, tx.Subtotal15)
+	assert.Equal(t, 20.0, tx.ICEAmount)
+	assert.Equal(t, 0.2, tx.IRBPNRAmount)
+	assert.Equal(t, 18.75, tx.TaxAmount)
+	assert.Equal(t, 143.95, tx.Amount)
+
+	svc, m := newTestSriService(t)
+
+	m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
+	m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return(tx.Items, nil).Once()
+	m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{}, nil).Once()
+	m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
+	m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+	m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+	m.EpRepo.On("GetByPoint", mock.Anything, issuer.ID, "001", "001", "01").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, CurrentSequence: 1}, nil)
+	m.EpRepo.On("IncrementSequence", mock.Anything, 10).Return(nil).Once()
+
+	var unsigned []byte
+	m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+		unsigned = args.Get(0).([]byte)
+	}).Return([]byte("<xml>signed</xml>"), nil).Once()
+	m.ReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+	m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+	// La factura pasa la validación contra el XSD antes de firmarse
+	require.NoError(t, svc.EmitirFactura(ctx, txID, "password"))
+
+	var f sri.Factura
+	require.NoError(t, xml.Unmarshal(unsigned, &f))
+
+	// El ICE forma parte de la base del IVA; el IRBPNR no
+	assert.Equal(t, []sri.TotalImpuesto{
+		{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "125.00", Valor: "18.75"},
+		{Codigo: "3", CodigoPorcentaje: "3610", BaseImponible: "100.00", Valor: "20.00"},
+		{Codigo: "5", CodigoPorcentaje: "5001", BaseImponible: "10.00", Valor: "0.20"},
+	}, f.InfoFactura.TotalConImpuestos.TotalImpuesto)
+	assert.Equal(t, "105.00", f.InfoFactura.TotalSinImpuestos)
+	assert.Equal(t, "143.95", f.InfoFactura.ImporteTotal)
+
+	require.Len(t, f.Detalles.Detalle, 2)
+	perfume := f.Detalles.Detalle[0]
+	assert.Equal(t, "100.00", perfume.PrecioTotalSinImpuesto)
+	require.Len(t, perfume.Impuestos.Impuesto, 2)
+	assert.Equal(t, "120.00", perfume.Impuestos.Impuesto[0].BaseImponible)
+	assert.Equal(t, "18.00", perfume.Impuestos.Impuesto[0].Valor)
+	assert.Equal(t, sri.Impuesto{Codigo: "3", CodigoPorcentaje: "3610", Tarifa: "20", BaseImponible: "100.00", Valor: "20.00"}, perfume.Impuestos.Impuesto[1])
+
+	water := f.Detalles.Detalle[1]
+	require.Len(t, water.Impuestos.Impuesto, 2)
+	assert.Equal(t, sri.Impuesto{Codigo: "5", CodigoPorcentaje: "5001", Tarifa: "0.02", BaseImponible: "10.00", Valor: "0.20"}, water.Impuestos.Impuesto[1])
+}
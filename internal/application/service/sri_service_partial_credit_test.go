@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirNotaCreditoParcial(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+
+	client := &domain.TaxPayer{Identification: "1710000000", IdentificationType: "05", Name: "Juan Perez", Email: "juan@test.com"}
+	client.ID = 5
+
+	originalID := 100
+	originalTx := func() *domain.Transaction {
+		return &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: originalID},
+			TransactionDate: time.Now().Add(-24 * time.Hour),
+			TaxPayerID:      &client.ID,
+			Amount:          230.00,
+			Subtotal15:      200.00,
+			TaxAmount:       30.00,
+			ElectronicReceipt: &domain.ElectronicReceipt{
+				ReceiptType: "01",
+				SRIStatus:   "AUTORIZADO",
+				AccessKey:   "1234567890123456789012345678901234567890123456789",
+			},
+		}
+	}
+
+	// Devolución de 1 de las 2 unidades vendidas
+	returnTx := func() *domain.Transaction {
+		return &domain.Transaction{
+			BaseEntity:           domain.BaseEntity{ID: 101},
+			Amount:               115.00,
+			Subtotal15:           100.00,
+			TaxAmount:            15.00,
+			RelatedTransactionID: &originalID,
+			Category:             &domain.Category{Type: domain.Outcome},
+		}
+	}
+
+	t.Run("Success: acredita solo los ítems devueltos", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", ctx, 101).Return(returnTx(), nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, originalID).Return(originalTx(), nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, originalID).Return([]domain.TransactionItem{
+			{Description: "Silla", Quantity: 2, UnitPrice: 100, Subtotal: 200, TaxRate: 4},
+		}, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, 101).Return([]domain.TransactionItem{
+			{Description: "Silla", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4},
+		}, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, client.ID).Return(client, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "04").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, ReceiptType: "04", CurrentSequence: 5}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, 10).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("SignCreditNote", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+
+		m.ReceiptRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.ElectronicReceipt) bool {
+			return r.ReceiptType == "04" && r.TransactionID == 101 && r.SRIStatus == "PENDIENTE"
+		})).Return(nil).Once()
+		m.SriClient.On("EnviarComprobante", mock.Anything, []byte("<xml>signed</xml>"), issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.AnythingOfType("string"), issuer.Environment).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		_, err := svc.EmitirNotaCredito(ctx, 101, originalID, "Devolución", "pass")
+		require.NoError(t, err)
+
+		var nc sri.NotaCredito
+		require.NoError(t, xml.Unmarshal(unsigned, &nc))
+		assert.Equal(t, "115.00", nc.InfoNotaCredito.ValorModificacion)
+		assert.Equal(t, "100.00", nc.InfoNotaCredito.TotalSinImpuestos)
+		require.Len(t, nc.Detalles.Detalle, 1)
+		assert.Equal(t, "1.000000", nc.Detalles.Detalle[0].Cantidad)
+
+		m.TxRepo.AssertExpectations(t)
+		m.Signer.AssertExpectations(t)
+	})
+
+	t.Run("Fallo: la devolución pertenece a otra factura", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		otherID := 999
+		tx := returnTx()
+		tx.RelatedTransactionID = &otherID
+		m.TxRepo.On("GetTransactionByID", ctx, 101).Return(tx, nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, originalID).Return(originalTx(), nil).Maybe()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, originalID).Return([]domain.TransactionItem{}, nil).Maybe()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Maybe()
+
+		_, err := svc.EmitirNotaCredito(ctx, 101, originalID, "Devolución", "pass")
+		assert.ErrorContains(t, err, "no corresponde")
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_FormasPago(t *testing.T) {
+	ctx := context.Background()
+	txID := 100
+
+	issuer := testIssuer()
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", IdentificationType: "05", Name: "Test Client"}
+
+	newTx := func() *domain.Transaction {
+		return &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: txID},
+			Amount:          115.0,
+			Subtotal15:      100.0,
+			TaxAmount:       15.0,
+			TransactionDate: time.Now(),
+			TaxPayerID:      &client.ID,
+			Category:        &domain.Category{Type: domain.Income},
+		}
+	}
+
+	setup := func(t *testing.T, payments []domain.TransactionPayment) (*service.SriService, *sriMocks) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(newTx(), nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return([]domain.TransactionItem{
+			{Description: "Item", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4},
+		}, nil).Once()
+		m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return(payments, nil).Once()
+		m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+		return svc, m
+	}
+
+	t.Run("Success: escribe cada forma de pago en el XML", func(t *testing.T) {
+		svc, m := setup(t, []domain.TransactionPayment{
+			{PaymentMethod: "19", Amount: 65},
+			{PaymentMethod: "20", Amount: 50, Term: 30, TimeUnit: "dias"},
+		})
+
+		m.EpRepo.On("GetByPoint", mock.Anything, issuer.ID, "001", "001", "01").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, CurrentSequence: 7}, nil)
+		m.EpRepo.On("IncrementSequence", mock.Anything, 10).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+
+		m.ReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		require.NoError(t, svc.EmitirFactura(ctx, txID, "password"))
+
+		var f sri.Factura
+		require.NoError(t, xml.Unmarshal(unsigned, &f))
+		pagos := f.InfoFactura.Pagos.Pago
+		require.Len(t, pagos, 2)
+		assert.Equal(t, sri.Pago{FormaPago: "19", Total: "65.00"}, pagos[0])
+		assert.Equal(t, sri.Pago{FormaPago: "20", Total: "50.00", Plazo: "30", UnidadTiempo: "dias"}, pagos[1])
+	})
+
+	t.Run("Fallo: el desglose no suma el total", func(t *testing.T) {
+		svc, m := setup(t, []domain.TransactionPayment{
+			{PaymentMethod: "01", Amount: 100},
+		})
+
+		err := svc.EmitirFactura(ctx, txID, "password")
+		assert.ErrorContains(t, err, "suman $100.00")
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+		m.Signer.AssertNotCalled(t, "Sign", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// EmitirLiquidacionCompra emite una Liquidación de Compra (03) por una compra a una persona
+// que no puede facturar. El comprobante queda vinculado al egreso que registra la compra.
+func (s *SriService) EmitirLiquidacionCompra(ctx context.Context, ps *domain.PurchaseSettlement, signaturePassword string) (string, error) {
+	if ps == nil {
+		return "", errors.New("datos de liquidación de compra vacíos")
+	}
+	s.logger.Printf("Iniciando emisión de Liquidación de Compra para transacción ID: %d", ps.TransactionID)
+
+	// 1. Cargar Datos
+	tx, err := s.txRepo.GetTransactionByID(ctx, ps.TransactionID)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo transacción: %w", err)
+	}
+	if tx.Category == nil || tx.Category.Type != domain.Outcome {
+		return "", errors.New("solo se pueden emitir liquidaciones de compra sobre transacciones de egreso")
+	}
+	if tx.IsVoided || tx.VoidsTransactionID != nil {
+		return "", errors.New("la transacción está anulada")
+	}
+	if tx.IsPartialCredit() {
+		return "", errors.New("la transacción es una devolución de venta; emita su nota de crédito")
+	}
+
+	existing := tx.ElectronicReceipt
+	if existing != nil {
+		switch {
+		case existing.ReceiptType != "03":
+			return "", fmt.Errorf("la transacción ya tiene un comprobante electrónico de tipo %s", existing.ReceiptType)
+		case existing.CanReissue(time.Now()):
+			s.logger.Printf("Liquidación anterior falló (%s). Se emitirá con nueva Clave de Acceso.", existing.SRIStatus)
+		default:
+			return "", fmt.Errorf("la transacción ya tiene una liquidación de compra en estado %s", existing.SRIStatus)
+		}
+	}
+
+	supplier, err := s.clientRepo.GetByID(ctx, ps.SupplierID)
+	if err != nil || supplier == nil {
+		return "", errors.New("no se encontró el proveedor")
+	}
+	if supplier.Identification == "9999999999999" || supplier.IdentificationType == "07" {
+		return "", errors.New("no se puede emitir una liquidación de compra a Consumidor Final")
+	}
+
+	items, err := s.txRepo.GetItemsByTransactionID(ctx, tx.ID)
+	if err == nil {
+		tx.Items = items
+	}
+
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil || issuer == nil {
+		return "", errors.New("no hay un emisor activo configurado")
+	}
+
+	taxes, err := s.taxSummary(ctx, tx, tx.TransactionDate)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. Secuencial y Clave de Acceso (Tipo 03)
+	secuencialSRI, err := s.nextSequential(ctx, issuer, "03")
+	if err != nil {
+		return "", err
+	}
+
+	emissionDate := time.Now()
+	claveAcceso := sri.GenerateAccessKey(
+		emissionDate,
+		"03",
+		issuer.RUC,
+		issuer.Environment,
+		issuer.EstablishmentCode,
+		issuer.EmissionPointCode,
+		secuencialSRI,
+		newNumericCode(),
+		1,
+	)
+
+	// 3. Generar y Firmar XML
+	lcXML := s.mapToLiquidacionCompra(tx, taxes, issuer, supplier, ps.PaymentMethod, claveAcceso, secuencialSRI, emissionDate)
+	xmlBytes, err := sri.MarshalLiquidacionCompra(lcXML)
+	if err != nil {
+		return "", err
+	}
+
+	signerObj := s.signerFactory(issuer.SignaturePath, signaturePassword)
+	signedXML, err := signerObj.SignDocument(xmlBytes, sri.SHA1)
+	if err != nil {
+		s.logger.Printf("Error al firmar liquidación de compra: %v", err)
+		return "", signatureError(err)
+	}
+
+	// 4. Guardar Recibo
+	receipt := &domain.ElectronicReceipt{
+		TransactionID: tx.ID,
+		IssuerID:      issuer.ID,
+		TaxPayerID:    supplier.ID,
+		AccessKey:     claveAcceso,
+		ReceiptType:   "03",
+		XMLContent:    strings.TrimSpace(string(signedXML)),
+		Environment:   issuer.Environment,
+	}
+	if err := s.savePendingReceipt(ctx, existing, receipt); err != nil {
+		return "", err
+	}
+
+	// 5. Enviar y Autorizar
+	return s.submitAndAuthorize(ctx, receipt, "la liquidación de compra")
+}
+
+func (s *SriService) mapToLiquidacionCompra(tx *domain.Transaction, taxes *domain.TaxSummary, issuer *domain.Issuer, supplier *domain.TaxPayer, paymentMethod, claveAcceso, secuencial string, emissionDate time.Time) *sri.LiquidacionCompra {
+	lc := &sri.LiquidacionCompra{}
+
+	lc.InfoTributaria = sri.InfoTributaria{
+		Ambiente:        strconv.Itoa(issuer.Environment),
+		TipoEmision:     "1",
+		RazonSocial:     cleanText(issuer.BusinessName),
+		NombreComercial: cleanText(issuer.TradeName),
+		Ruc:             issuer.RUC,
+		ClaveAcceso:     claveAcceso,
+		CodDoc:          "03", // Liquidación de Compra
+		Estab:           issuer.EstablishmentCode,
+		PtoEmi:          issuer.EmissionPointCode,
+		Secuencial:      secuencial,
+		DirMatriz:       cleanText(issuer.MainAddress),
+	}
+
+	// Egresos simples sin desglose se liquidan íntegramente en tarifa 0%
+	totals := withZeroRateFallback(taxes.Totals, tx.Amount)
+	totalStr := fmt.Sprintf("%.2f", tx.Amount)
+
+	lc.InfoLiquidacionCompra = sri.InfoLiquidacionCompra{
+		FechaEmision:                emissionDate.Format("02/01/2006"),
+		DirEstablecimiento:          cleanText(issuer.EstablishmentAddress),
+		ObligadoContabilidad:        map[bool]string{true: "SI", false: "NO"}[issuer.KeepAccounting],
+		TipoIdentificacionProveedor: supplier.IdentificationType,
+		RazonSocialProveedor:        cleanText(supplier.Name),
+		IdentificacionProveedor:     supplier.Identification,
+		DireccionProveedor:          cleanText(supplier.Address),
+		TotalSinImpuestos:           fmt.Sprintf("%.2f", taxBase(totals)),
+		TotalDescuento:              "0.00",
+		ImporteTotal:                totalStr,
+		Moneda:                      "DOLAR",
+	}
+
+	lc.InfoLiquidacionCompra.TotalConImpuestos.TotalImpuesto = append(toTotalImpuestos(totals), toOtherTotalImpuestos(taxes.OtherTotals)...)
+
+	if paymentMethod == "" {
+		paymentMethod = "01" // Sin utilización del sistema financiero
+	}
+	lc.InfoLiquidacionCompra.Pagos.Pago = append(lc.InfoLiquidacionCompra.Pagos.Pago, sri.Pago{
+		FormaPago: paymentMethod,
+		Total:     totalStr,
+	})
+
+	for i, item := range tx.Items {
+		line := taxes.Items[i]
+		det := sri.Detalle{
+			Descripcion:            cleanText(item.Description),
+			Cantidad:               fmt.Sprintf("%.6f", item.Quantity),
+			PrecioUnitario:         fmt.Sprintf("%.6f", item.UnitPrice),
+			Descuento:              "0.00",
+			PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", line.Net()),
+		}
+		det.Impuestos.Impuesto = detailImpuestos(taxes, i)
+		lc.Detalles.Detalle = append(lc.Detalles.Detalle, det)
+	}
+
+	// Sin ítems: una línea por base imponible con la descripción del egreso
+	if len(lc.Detalles.Detalle) == 0 {
+		for _, total := range totals {
+			det := sri.Detalle{
+				Descripcion:            cleanText(tx.Description),
+				Cantidad:               "1.000000",
+				PrecioUnitario:         fmt.Sprintf("%.6f", total.Base),
+				Descuento:              "0.00",
+				PrecioTotalSinImpuesto: fmt.Sprintf("%.2f", total.Base),
+			}
+			det.Impuestos.Impuesto = append(det.Impuestos.Impuesto, toImpuesto(total))
+			lc.Detalles.Detalle = append(lc.Detalles.Detalle, det)
+		}
+	}
+
+	return lc
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirLiquidacionCompra(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+
+	supplier := &domain.TaxPayer{
+		Identification:     "1712345678",
+		IdentificationType: "05",
+		Name:               "Juan Agricultor",
+		Address:            "Recinto La Unión",
+	}
+	supplier.ID = 9
+
+	expenseTx := func() *domain.Transaction {
+		return &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: 60},
+			Description:     "Compra de cacao",
+			TransactionDate: time.Now(),
+			Amount:          250.00,
+			Subtotal0:       250.00,
+			Category:        &domain.Category{Type: domain.Outcome},
+		}
+	}
+
+	t.Run("Success: genera XML 03 con los ítems del egreso", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", ctx, 60).Return(expenseTx(), nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, 60).Return([]domain.TransactionItem{
+			{Description: "Cacao en grano (qq)", Quantity: 2, UnitPrice: 125, Subtotal: 250},
+		}, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, supplier.ID).Return(supplier, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "03").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 4}, ReceiptType: "03", CurrentSequence: 5}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, 4).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("SignDocument", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+
+		m.ReceiptRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.ElectronicReceipt) bool {
+			return r.ReceiptType == "03" && r.TransactionID == 60 && r.TaxPayerID == supplier.ID
+		})).Return(nil).Once()
+
+		m.SriClient.On("EnviarComprobante", mock.Anything, []byte("<xml>signed</xml>"), issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.AnythingOfType("string"), issuer.Environment).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		key, err := svc.EmitirLiquidacionCompra(ctx, &domain.PurchaseSettlement{TransactionID: 60, SupplierID: supplier.ID}, "pass")
+		require.NoError(t, err)
+		assert.Equal(t, "03", key[8:10])
+
+		var lc sri.LiquidacionCompra
+		require.NoError(t, xml.Unmarshal(unsigned, &lc))
+		assert.Equal(t, "1.1.0", lc.Version)
+		assert.Equal(t, "000000005", lc.InfoTributaria.Secuencial)
+		assert.Equal(t, "05", lc.InfoLiquidacionCompra.TipoIdentificacionProveedor)
+		assert.Equal(t, "1712345678", lc.InfoLiquidacionCompra.IdentificacionProveedor)
+		assert.Equal(t, "250.00", lc.InfoLiquidacionCompra.ImporteTotal)
+		require.Len(t, lc.InfoLiquidacionCompra.Pagos.Pago, 1)
+		assert.Equal(t, "01", lc.InfoLiquidacionCompra.Pagos.Pago[0].FormaPago)
+		require.Len(t, lc.Detalles.Detalle, 1)
+		assert.Equal(t, "2.000000", lc.Detalles.Detalle[0].Cantidad)
+
+		m.EpRepo.AssertExpectations(t)
+		m.Signer.AssertExpectations(t)
+	})
+
+	t.Run("Fallo: el egreso ya tiene una retención", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		tx := expenseTx()
+		tx.ElectronicReceipt = &domain.ElectronicReceipt{ReceiptType: "07", SRIStatus: "AUTORIZADO"}
+		m.TxRepo.On("GetTransactionByID", ctx, 60).Return(tx, nil).Once()
+
+		_, err := svc.EmitirLiquidacionCompra(ctx, &domain.PurchaseSettlement{TransactionID: 60, SupplierID: supplier.ID}, "pass")
+		assert.ErrorContains(t, err, "tipo 07")
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fallo: proveedor consumidor final", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", ctx, 60).Return(expenseTx(), nil).Once()
+		m.ClientRepo.On("GetByID", ctx, 1).Return(&domain.TaxPayer{Identification: "9999999999999", IdentificationType: "07"}, nil).Once()
+
+		_, err := svc.EmitirLiquidacionCompra(ctx, &domain.PurchaseSettlement{TransactionID: 60, SupplierID: 1}, "pass")
+		assert.ErrorContains(t, err, "Consumidor Final")
+	})
+}
@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_ReusedAccessKey(t *testing.T) {
+	ctx := context.Background()
+	txID := 300
+
+	issuer := testIssuer()
+	key := sri.GenerateAccessKey(time.Now(), "01", issuer.RUC, 1, "001", "001", "000000007", "12345678", 1)
+
+	for _, status := range []domain.ReceiptStatus{domain.ReceiptAuthorized, domain.ReceiptReceived, domain.ReceiptProcessing} {
+		t.Run("Rechaza volver a emitir una factura "+string(status), func(t *testing.T) {
+			svc, m := newTestSriService(t)
+
+			m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(&domain.Transaction{
+				BaseEntity:        domain.BaseEntity{ID: txID},
+				Amount:            115,
+				TransactionDate:   time.Now(),
+				Category:          &domain.Category{Type: domain.Income},
+				ElectronicReceipt: &domain.ElectronicReceipt{BaseEntity: domain.BaseEntity{CreatedAt: time.Now()}, AccessKey: key, SRIStatus: status},
+			}, nil).Once()
+			m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+
+			err := svc.EmitirFactura(ctx, txID, "password")
+			assert.ErrorContains(t, err, "ya fue enviada al SRI")
+			m.Signer.AssertNotCalled(t, "Sign", mock.Anything, mock.Anything)
+			m.ReceiptRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			m.ReceiptRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	setupResend := func(t *testing.T, fields []domain.AdditionalField) (*service.SriService, *sriMocks) {
+		svc, m := newTestSriService(t)
+
+		client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", IdentificationType: "05", Name: "Test Client"}
+		m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(&domain.Transaction{
+			BaseEntity:        domain.BaseEntity{ID: txID},
+			Amount:            115,
+			Subtotal15:        100,
+			TaxAmount:         15,
+			TransactionDate:   time.Now(),
+			TaxPayerID:        &client.ID,
+			Category:          &domain.Category{Type: domain.Income},
+			ElectronicReceipt: &domain.ElectronicReceipt{BaseEntity: domain.BaseEntity{CreatedAt: time.Now()}, AccessKey: key, SRIStatus: domain.ReceiptNetworkError},
+		}, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return([]domain.TransactionItem{
+			{Description: "Item", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4},
+		}, nil).Once()
+		m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{
+			{PaymentMethod: "19", Amount: 65},
+			{PaymentMethod: "20", Amount: 50},
+		}, nil).Once()
+		m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return(fields, nil).Once()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+		m.ReceiptRepo.On("GetByAccessKey", mock.Anything, key).Return(&domain.ElectronicReceipt{
+			BaseEntity: domain.BaseEntity{ID: 9}, TransactionID: txID, TaxPayerID: client.ID, AccessKey: key, SRIStatus: domain.ReceiptNetworkError, Environment: 1,
+		}, nil).Once()
+		return svc, m
+	}
+
+	t.Run("Reenvía una factura ERROR_RED con la misma clave, sus formas de pago y campos adicionales", func(t *testing.T) {
+		svc, m := setupResend(t, []domain.AdditionalField{{Name: "Orden", Value: "OC-12"}})
+
+		var unsigned []byte
+		m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+		m.ReceiptRepo.On("Update", mock.Anything, mock.MatchedBy(func(er *domain.ElectronicReceipt) bool {
+			return er.ID == 9 && er.SRIStatus == domain.ReceiptPending && er.TaxPayerID == 50 && er.XMLContent == "<xml>signed</xml>"
+		})).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, key, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		require.NoError(t, svc.EmitirFactura(ctx, txID, "password"))
+
+		var f sri.Factura
+		require.NoError(t, xml.Unmarshal(unsigned, &f))
+		assert.Len(t, f.Detalles.Detalle, 1)
+		assert.Len(t, f.InfoFactura.Pagos.Pago, 2)
+		require.NotNil(t, f.InfoAdicional)
+		assert.Contains(t, f.InfoAdicional.CampoAdicional, sri.CampoAdicional{Nombre: "Orden", Valor: "OC-12"})
+		m.ReceiptRepo.AssertExpectations(t)
+		m.ReceiptRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rechaza reenviar con más campos adicionales de los permitidos", func(t *testing.T) {
+		fields := make([]domain.AdditionalField, domain.MaxAdditionalFields+1)
+		for i := range fields {
+			fields[i] = domain.AdditionalField{Name: fmt.Sprintf("Campo%d", i), Value: "x"}
+		}
+		svc, m := setupResend(t, fields)
+
+		err := svc.EmitirFactura(ctx, txID, "password")
+		assert.ErrorContains(t, err, "campos adicionales")
+		m.Signer.AssertNotCalled(t, "Sign", mock.Anything, mock.Anything)
+		m.ReceiptRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitir_ValidacionEsquema(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+	// Cliente antiguo sin tipo de identificación registrado
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", Name: "Test Client"}
+
+	t.Run("Factura: reporta los campos inválidos sin consumir secuencial", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+		txID := 400
+
+		items := []domain.TransactionItem{
+			{Description: "Silla", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4},
+			{Description: "   ", Quantity: 1, UnitPrice: 10, Subtotal: 10},
+		}
+		tx := &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: txID},
+			TransactionDate: time.Now(),
+			TaxPayerID:      &client.ID,
+			Items:           items,
+			Category:        &domain.Category{Type: domain.Income},
+		}
+		require.NoError(t, tx.RecalculateTotals(testTaxRates()))
+
+		m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return(items, nil).Once()
+		m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{}, nil).Once()
+		m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+
+		err := svc.EmitirFactura(ctx, txID, "password")
+		require.Error(t, err)
+
+		var verrs sri.ValidationErrors
+		require.True(t, errors.As(err, &verrs))
+		var paths []string
+		for _, ve := range verrs {
+			paths = append(paths, ve.Path)
+		}
+		assert.ElementsMatch(t, []string{
+			"factura/infoFactura/tipoIdentificacionComprador",
+			"factura/detalles/detalle[2]/descripcion",
+		}, paths)
+
+		m.EpRepo.AssertNotCalled(t, "GetByPoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+		m.Signer.AssertNotCalled(t, "Sign", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Nota de crédito: sin motivo no se reserva el secuencial", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+		originalID := 500
+		validClient := *client
+		validClient.IdentificationType = "05"
+
+		original := &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: originalID},
+			TransactionDate: time.Now().Add(-24 * time.Hour),
+			TaxPayerID:      &client.ID,
+			Amount:          115,
+			Subtotal15:      100,
+			TaxAmount:       15,
+			ElectronicReceipt: &domain.ElectronicReceipt{
+				ReceiptType: "01",
+				SRIStatus:   "AUTORIZADO",
+				AccessKey:   "1234567890123456789012345678901234567890123456789",
+			},
+		}
+
+		m.TxRepo.On("GetTransactionByID", mock.Anything, 501).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: 501}}, nil).Once()
+		m.TxRepo.On("GetTransactionByID", mock.Anything, originalID).Return(original, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", mock.Anything, originalID).Return([]domain.TransactionItem{
+			{Description: "Silla", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4},
+		}, nil).Once()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(&validClient, nil)
+
+		_, err := svc.EmitirNotaCredito(ctx, 501, originalID, "", "password")
+		require.Error(t, err)
+
+		var verrs sri.ValidationErrors
+		require.True(t, errors.As(err, &verrs))
+		require.Len(t, verrs, 1)
+		assert.Equal(t, "notaCredito/infoNotaCredito/motivo", verrs[0].Path)
+
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+		m.Signer.AssertNotCalled(t, "SignCreditNote", mock.Anything, mock.Anything)
+	})
+}
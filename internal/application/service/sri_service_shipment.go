@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// EmitirGuiaRemision emite la Guía de Remisión (06) que respalda el traslado de un envío.
+func (s *SriService) EmitirGuiaRemision(ctx context.Context, shipmentID int, signaturePassword string) (string, error) {
+	s.logger.Printf("Iniciando emisión de Guía de Remisión para envío ID: %d", shipmentID)
+
+	// 1. Cargar Datos
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo envío: %w", err)
+	}
+	if len(shipment.Recipients) == 0 {
+		return "", errors.New("el envío no tiene destinatarios")
+	}
+
+	// Solo se reemplaza un comprobante que falló definitivamente o quedó trabado EN PROCESO
+	existing := shipment.ElectronicReceipt
+	if existing != nil {
+		switch {
+		case existing.CanReissue(time.Now()):
+			s.logger.Printf("Guía anterior falló (%s). Se emitirá con nueva Clave de Acceso.", existing.SRIStatus)
+			// El recibo del listado no trae ID; lo recuperamos para actualizar la misma fila
+			existing, err = s.receiptRepo.GetByAccessKey(ctx, existing.AccessKey)
+			if err != nil {
+				return "", fmt.Errorf("error recuperando guía anterior: %w", err)
+			}
+		default:
+			return "", fmt.Errorf("el envío ya tiene una guía de remisión en estado %s", existing.SRIStatus)
+		}
+	}
+
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil || issuer == nil {
+		return "", errors.New("no hay un emisor activo configurado")
+	}
+
+	// 2. Secuencial y Clave de Acceso (Tipo 06)
+	secuencialSRI, err := s.nextSequential(ctx, issuer, "06")
+	if err != nil {
+		return "", err
+	}
+
+	claveAcceso := sri.GenerateAccessKey(
+		time.Now(),
+		"06",
+		issuer.RUC,
+		issuer.Environment,
+		issuer.EstablishmentCode,
+		issuer.EmissionPointCode,
+		secuencialSRI,
+		newNumericCode(),
+		1,
+	)
+
+	// 3. Generar y Firmar XML
+	grXML := s.mapToGuiaRemision(shipment, issuer, claveAcceso, secuencialSRI)
+	xmlBytes, err := sri.MarshalGuiaRemision(grXML)
+	if err != nil {
+		return "", err
+	}
+
+	signerObj := s.signerFactory(issuer.SignaturePath, signaturePassword)
+	signedXML, err := signerObj.SignDocument(xmlBytes, sri.SHA1)
+	if err != nil {
+		s.logger.Printf("Error al firmar guía de remisión: %v", err)
+		return "", signatureError(err)
+	}
+
+	// 4. Guardar Recibo: se notifica al primer destinatario
+	receipt := &domain.ElectronicReceipt{
+		ShipmentID:  &shipment.ID,
+		IssuerID:    issuer.ID,
+		TaxPayerID:  shipment.Recipients[0].TaxPayerID,
+		AccessKey:   claveAcceso,
+		ReceiptType: "06",
+		XMLContent:  strings.TrimSpace(string(signedXML)),
+		Environment: issuer.Environment,
+	}
+	if err := s.savePendingReceipt(ctx, existing, receipt); err != nil {
+		return "", err
+	}
+
+	// 5. Enviar y Autorizar
+	return s.submitAndAuthorize(ctx, receipt, "la guía de remisión")
+}
+
+// GenerateShipmentRide genera el RIDE de la guía de un envío en un archivo temporal.
+func (s *SriService) GenerateShipmentRide(ctx context.Context, shipmentID int) (string, error) {
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo envío: %w", err)
+	}
+	if shipment.ElectronicReceipt == nil {
+		return "", errors.New("este envío no tiene guía de remisión electrónica")
+	}
+
+	receipt, err := s.receiptRepo.GetByAccessKey(ctx, shipment.ElectronicReceipt.AccessKey)
+	if err != nil {
+		return "", fmt.Errorf("error recuperando contenido XML: %w", err)
+	}
+	if receipt == nil {
+		return "", errors.New("el recibo electrónico no se encontró en la base de datos")
+	}
+
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo emisor: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", fmt.Sprintf("ride-%s-*.pdf", receipt.AccessKey))
+	if err != nil {
+		return "", fmt.Errorf("error creando archivo temporal: %w", err)
+	}
+	outputPath := tmpFile.Name()
+	tmpFile.Close()
+
+	authDate := time.Now()
+	if receipt.AuthorizationDate != nil {
+		authDate = *receipt.AuthorizationDate
+	}
+
+	if err := s.renderRide(receipt.ReceiptType, receipt.XMLContent, outputPath, issuer.LogoPath, authDate, receipt.AccessKey); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
+
+func (s *SriService) mapToGuiaRemision(shipment *domain.Shipment, issuer *domain.Issuer, claveAcceso, secuencial string) *sri.GuiaRemision {
+	gr := &sri.GuiaRemision{}
+
+	gr.InfoTributaria = sri.InfoTributaria{
+		Ambiente:        strconv.Itoa(issuer.Environment),
+		TipoEmision:     "1",
+		RazonSocial:     cleanText(issuer.BusinessName),
+		NombreComercial: cleanText(issuer.TradeName),
+		Ruc:             issuer.RUC,
+		ClaveAcceso:     claveAcceso,
+		CodDoc:          "06", // Guía de Remisión
+		Estab:           issuer.EstablishmentCode,
+		PtoEmi:          issuer.EmissionPointCode,
+		Secuencial:      secuencial,
+		DirMatriz:       cleanText(issuer.MainAddress),
+	}
+
+	gr.InfoGuiaRemision = sri.InfoGuiaRemision{
+		DirEstablecimiento:              cleanText(issuer.EstablishmentAddress),
+		DirPartida:                      cleanText(shipment.DepartureAddress),
+		RazonSocialTransportista:        cleanText(shipment.Carrier.Name),
+		TipoIdentificacionTransportista: shipment.Carrier.IdentificationType,
+		RucTransportista:                shipment.Carrier.Identification,
+		ObligadoContabilidad:            map[bool]string{true: "SI", false: "NO"}[issuer.KeepAccounting],
+		FechaIniTransporte:              shipment.StartDate.Format("02/01/2006"),
+		FechaFinTransporte:              shipment.EndDate.Format("02/01/2006"),
+		Placa:                           strings.ToUpper(strings.TrimSpace(shipment.Plate)),
+	}
+
+	for _, rcp := range shipment.Recipients {
+		dest := sri.Destinatario{
+			IdentificacionDestinatario: rcp.TaxPayer.Identification,
+			RazonSocialDestinatario:    cleanText(rcp.TaxPayer.Name),
+			DirDestinatario:            cleanText(rcp.ArrivalAddress),
+			MotivoTraslado:             cleanText(rcp.Reason),
+			Ruta:                       cleanText(rcp.Route),
+		}
+		if rcp.SupportDocNumber != "" {
+			dest.CodDocSustento = "01" // Factura
+			dest.NumDocSustento = rcp.SupportDocNumber
+			dest.NumAutDocSustento = rcp.SupportDocAuthKey
+			if rcp.SupportDocDate != nil {
+				dest.FechaEmisionDocSustento = rcp.SupportDocDate.Format("02/01/2006")
+			}
+		}
+		for _, item := range rcp.Items {
+			dest.Detalles.Detalle = append(dest.Detalles.Detalle, sri.DetalleGuia{
+				CodigoInterno: strings.TrimSpace(item.Code),
+				Descripcion:   cleanText(item.Description),
+				Cantidad:      fmt.Sprintf("%.6f", item.Quantity),
+			})
+		}
+		gr.Destinatarios.Destinatario = append(gr.Destinatarios.Destinatario, dest)
+	}
+
+	return gr
+}
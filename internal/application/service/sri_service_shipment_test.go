@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirGuiaRemision(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+
+	docDate := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	shipment := func() *domain.Shipment {
+		return &domain.Shipment{
+			BaseEntity:       domain.BaseEntity{ID: 30},
+			CarrierID:        3,
+			Plate:            "pba-1234",
+			DepartureAddress: "Bodega Norte",
+			StartDate:        time.Date(2026, 2, 11, 0, 0, 0, 0, time.UTC),
+			EndDate:          time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC),
+			Carrier:          &domain.TaxPayer{Identification: "1790011122001", IdentificationType: "04", Name: "Transportes Andinos"},
+			Recipients: []domain.ShipmentRecipient{
+				{
+					TaxPayerID:       5,
+					TaxPayer:         &domain.TaxPayer{Identification: "1712345678", Name: "Cliente Destino"},
+					ArrivalAddress:   "Av. Principal 123",
+					Reason:           "Venta",
+					SupportDocNumber: "001-002-000000077",
+					SupportDocDate:   &docDate,
+					Items:            []domain.ShipmentItem{{Code: "P-1", Description: "Cemento", Quantity: 10}},
+				},
+			},
+		}
+	}
+
+	t.Run("Success: genera XML 06 con destinatarios", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.ShipmentRepo.On("GetByID", ctx, 30).Return(shipment(), nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "06").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 6}, ReceiptType: "06", CurrentSequence: 1}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, 6).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("SignDocument", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+
+		m.ReceiptRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.ElectronicReceipt) bool {
+			return r.ReceiptType == "06" && r.TransactionID == 0 && r.ShipmentID != nil && *r.ShipmentID == 30 && r.TaxPayerID == 5
+		})).Return(nil).Once()
+
+		m.SriClient.On("EnviarComprobante", mock.Anything, []byte("<xml>signed</xml>"), issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.AnythingOfType("string"), issuer.Environment).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		key, err := svc.EmitirGuiaRemision(ctx, 30, "pass")
+		require.NoError(t, err)
+		assert.Equal(t, "06", key[8:10])
+
+		var gr sri.GuiaRemision
+		require.NoError(t, xml.Unmarshal(unsigned, &gr))
+		assert.Equal(t, "1.1.0", gr.Version)
+		assert.Equal(t, "PBA-1234", gr.InfoGuiaRemision.Placa)
+		assert.Equal(t, "11/02/2026", gr.InfoGuiaRemision.FechaIniTransporte)
+		assert.Equal(t, "1790011122001", gr.InfoGuiaRemision.RucTransportista)
+		require.Len(t, gr.Destinatarios.Destinatario, 1)
+
+		dest := gr.Destinatarios.Destinatario[0]
+		assert.Equal(t, "001-002-000000077", dest.NumDocSustento)
+		assert.Equal(t, "10/02/2026", dest.FechaEmisionDocSustento)
+		require.Len(t, dest.Detalles.Detalle, 1)
+		assert.Equal(t, "10.000000", dest.Detalles.Detalle[0].Cantidad)
+
+		m.Signer.AssertExpectations(t)
+		m.SriClient.AssertExpectations(t)
+	})
+
+	t.Run("Fallo: ya tiene una guía autorizada", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		s := shipment()
+		s.ElectronicReceipt = &domain.ElectronicReceipt{ReceiptType: "06", SRIStatus: "AUTORIZADO"}
+		m.ShipmentRepo.On("GetByID", ctx, 30).Return(s, nil).Once()
+
+		_, err := svc.EmitirGuiaRemision(ctx, 30, "pass")
+		assert.ErrorContains(t, err, "AUTORIZADO")
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/nelsonmarro/verith/internal/sri/srisim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSyncReceipt_Simulador recorre la sincronización contra el simulador del SRI en lugar de mocks del cliente.
+func TestSyncReceipt_Simulador(t *testing.T) {
+	ctx := context.Background()
+
+	sim := srisim.NewServer(srisim.Scenario{})
+	srv := httptest.NewServer(sim)
+	defer srv.Close()
+
+	setup := func(t *testing.T) (*service.SriService, *sriMocks) {
+		client := sri.NewSoapClient().WithBaseURL(srv.URL)
+		client.Timeout = 2 * time.Second
+		svc, m := newTestSriServiceWithClient(t, client)
+		// La generación del RIDE tras autorizar corre en segundo plano y no es parte de esta prueba
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(nil, errors.New("sin emisor")).Maybe()
+		return svc, m
+	}
+
+	receipt := func(secuencial string) *domain.ElectronicReceipt {
+		key := sri.GenerateAccessKey(time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local), "01", "1790012345001", 1, "001", "001", secuencial, "12345678", 1)
+		return &domain.ElectronicReceipt{
+			AccessKey:   key,
+			XMLContent:  fmt.Sprintf(`<factura><infoTributaria><claveAcceso>%s</claveAcceso></infoTributaria></factura>`, key),
+			SRIStatus:   "PENDIENTE",
+			Environment: 1,
+		}
+	}
+
+	t.Run("Un comprobante pendiente queda autorizado", func(t *testing.T) {
+		svc, m := setup(t)
+		r := receipt("000000101")
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, r.AccessKey, "RECIBIDA", mock.Anything, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, r.AccessKey, "AUTORIZADO", mock.Anything, mock.Anything).Return(nil).Once()
+
+		status, err := svc.SyncReceipt(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, "AUTORIZADO", status)
+		require.NotNil(t, r.AuthorizationDate)
+		m.ReceiptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Un comprobante devuelto guarda el código del SRI", func(t *testing.T) {
+		svc, m := setup(t)
+		r := receipt("000000102")
+		sim.SetScenario(r.AccessKey, srisim.Scenario{Devuelta: []sri.Mensaje{srisim.Error(srisim.ErrSecuencialRepetido)}})
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, r.AccessKey, "DEVUELTA", "45: SECUENCIAL REGISTRADO", mock.Anything).Return(nil).Once()
+
+		status, err := svc.SyncReceipt(ctx, r)
+		require.Error(t, err)
+		assert.Equal(t, "DEVUELTA", status)
+		m.ReceiptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Sin respuesta de autorización queda EN PROCESO", func(t *testing.T) {
+		svc, m := setup(t)
+		r := receipt("000000103")
+		r.SRIStatus = "RECIBIDA" // El simulador nunca lo recibió, así que no hay autorizaciones
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, r.AccessKey, "EN PROCESO", mock.Anything, mock.Anything).Return(nil).Once()
+
+		status, err := svc.SyncReceipt(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, "EN PROCESO", status)
+		m.ReceiptRepo.AssertExpectations(t)
+	})
+}
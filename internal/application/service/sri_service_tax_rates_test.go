@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_TarifasVigentes(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", IdentificationType: "05", Name: "Test Client"}
+
+	emit := func(t *testing.T, txID int, date time.Time, items []domain.TransactionItem) sri.Factura {
+		tx := &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: txID},
+			TransactionDate: date,
+			TaxPayerID:      &client.ID,
+			Items:           items,
+			Category:        &domain.Category{Type: domain.Income},
+		}
+		require.NoError(t, tx.RecalculateTotals(testTaxRates()))
+		tx.Items = nil
+
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return(items, nil).Once()
+		m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{}, nil).Once()
+		m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+		m.EpRepo.On("GetByPoint", mock.Anything, issuer.ID, "001", "001", "01").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, CurrentSequence: 1}, nil)
+		m.EpRepo.On("IncrementSequence", mock.Anything, 10).Return(nil).Once()
+
+		var unsigned []byte
+		m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+		m.ReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		require.NoError(t, svc.EmitirFactura(ctx, txID, "password"))
+		m.TaxRateRepo.AssertExpectations(t)
+
+		var f sri.Factura
+		require.NoError(t, xml.Unmarshal(unsigned, &f))
+		return f
+	}
+
+	t.Run("Varias tarifas: un totalImpuesto por código", func(t *testing.T) {
+		f := emit(t, 600, time.Now(), []domain.TransactionItem{
+			{Description: "Silla", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4},
+			{Description: "Hospedaje turístico", Quantity: 1, UnitPrice: 40, Subtotal: 40, TaxRate: 5},
+			{Description: "Libro", Quantity: 1, UnitPrice: 10, Subtotal: 10, TaxRate: 0},
+		})
+
+		totals := f.InfoFactura.TotalConImpuestos.TotalImpuesto
+		require.Len(t, totals, 3)
+		assert.Equal(t, "0", totals[0].CodigoPorcentaje)
+		assert.Equal(t, "10.00", totals[0].BaseImponible)
+		assert.Equal(t, "4", totals[1].CodigoPorcentaje)
+		assert.Equal(t, "15.00", totals[1].Valor)
+		assert.Equal(t, "5", totals[2].CodigoPorcentaje)
+		assert.Equal(t, "2.00", totals[2].Valor)
+
+		require.Len(t, f.Detalles.Detalle, 3)
+		assert.Equal(t, "15", f.Detalles.Detalle[0].Impuestos.Impuesto[0].Tarifa)
+		assert.Equal(t, "5", f.Detalles.Detalle[1].Impuestos.Impuesto[0].Tarifa)
+		assert.Equal(t, "0", f.Detalles.Detalle[2].Impuestos.Impuesto[0].Tarifa)
+		assert.Equal(t, "167.00", f.InfoFactura.ImporteTotal)
+	})
+
+	t.Run("Factura histórica: usa la tarifa vigente a su fecha", func(t *testing.T) {
+		f := emit(t, 601, time.Date(2023, 8, 1, 10, 0, 0, 0, time.Local), []domain.TransactionItem{
+			{Description: "Silla", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 2},
+		})
+
+		require.Len(t, f.Detalles.Detalle, 1)
+		impuesto := f.Detalles.Detalle[0].Impuestos.Impuesto[0]
+		assert.Equal(t, "2", impuesto.CodigoPorcentaje)
+		assert.Equal(t, "12", impuesto.Tarifa)
+		assert.Equal(t, "12.00", impuesto.Valor)
+		assert.Equal(t, "112.00", f.InfoFactura.ImporteTotal)
+	})
+}
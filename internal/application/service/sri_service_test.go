@@ -27,7 +27,8 @@ func TestSyncReceipt(t *testing.T) {
 	logger := log.New(io.Discard, "", 0)
 
 	// Service
-	service := NewSriService(mockTxRepo, mockIssuerRepo, mockReceiptRepo, mockClientRepo, nil, mockSriClient, mockMailService, logger)
+	service := NewSriService(mockTxRepo, mockIssuerRepo, mockReceiptRepo, mockClientRepo, nil, nil, nil, mockSriClient, mockMailService, logger)
+	service.AuthorizationDelay = 0
 	ctx := context.Background()
 
 	// Default expectations for the async finalizeAndEmail (to prevent panics)
@@ -49,11 +50,11 @@ func TestSyncReceipt(t *testing.T) {
 			Estado: "RECIBIDA",
 		}
 
-		mockSriClient.On("EnviarComprobante", []byte(receipt.XMLContent), 1).Return(sriResponse, nil).Once()
+		mockSriClient.On("EnviarComprobante", mock.Anything, []byte(receipt.XMLContent), 1).Return(sriResponse, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", ctx, receipt.AccessKey, "RECIBIDA", "Recibido por SRI", (*time.Time)(nil)).Return(nil).Once()
 
 		// Expect immediate authorization check (since it moved to RECIBIDA)
-		mockSriClient.On("AutorizarComprobante", receipt.AccessKey, 1).Return(&sri.RespuestaAutorizacion{NumeroComprobantes: "0"}, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, receipt.AccessKey, 1).Return(&sri.RespuestaAutorizacion{NumeroComprobantes: "0"}, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", ctx, receipt.AccessKey, "EN PROCESO", mock.Anything, (*time.Time)(nil)).Return(nil).Once()
 
 		// Act
@@ -91,7 +92,7 @@ func TestSyncReceipt(t *testing.T) {
 			},
 		}
 
-		mockSriClient.On("AutorizarComprobante", receipt.AccessKey, 1).Return(sriResponse, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, receipt.AccessKey, 1).Return(sriResponse, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", ctx, receipt.AccessKey, "AUTORIZADO", "Autorización Exitosa", &authDate).Return(nil).Once()
 
 		// Act
@@ -108,7 +109,7 @@ func TestSyncReceipt(t *testing.T) {
 		// Simula respuesta vacía o 0 comprobantes
 		sriResponse := &sri.RespuestaAutorizacion{NumeroComprobantes: "0"}
 		
-		mockSriClient.On("AutorizarComprobante", receipt.AccessKey, 1).Return(sriResponse, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, receipt.AccessKey, 1).Return(sriResponse, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", ctx, receipt.AccessKey, "EN PROCESO", mock.Anything, (*time.Time)(nil)).Return(nil).Once()
 
 		status, err := service.SyncReceipt(ctx, receipt)
@@ -135,7 +136,7 @@ func TestSyncReceipt(t *testing.T) {
 			},
 		}
 		
-		mockSriClient.On("AutorizarComprobante", receipt.AccessKey, 1).Return(sriResponse, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, receipt.AccessKey, 1).Return(sriResponse, nil).Once()
 		// Debe guardar el mensaje de error concatenado
 		mockReceiptRepo.On("UpdateStatus", ctx, receipt.AccessKey, "RECHAZADA", mock.MatchedBy(func(msg string) bool {
 			return len(msg) > 0 // El mensaje debe contener el error
@@ -146,6 +147,18 @@ func TestSyncReceipt(t *testing.T) {
 		assert.Contains(t, err.Error(), "FIRMA INVALIDA")
 		assert.Equal(t, "RECHAZADA", status)
 	})
+
+	t.Run("PENDIENTE -> ERROR_ENVIO (SOAP Fault)", func(t *testing.T) {
+		receipt := &domain.ElectronicReceipt{AccessKey: "KEY_FAULT", XMLContent: "<xml/>", SRIStatus: "PENDIENTE", Environment: 1}
+		fault := &sri.SOAPFaultError{Code: "soap:Client", Message: "Unmarshalling Error"}
+
+		mockSriClient.On("EnviarComprobante", mock.Anything, []byte(receipt.XMLContent), 1).Return(nil, fault).Once()
+		mockReceiptRepo.On("UpdateStatus", ctx, receipt.AccessKey, "ERROR_ENVIO", fault.Error(), (*time.Time)(nil)).Return(nil).Once()
+
+		status, err := service.SyncReceipt(ctx, receipt)
+		require.ErrorAs(t, err, &fault)
+		assert.Equal(t, "ERROR_ENVIO", status)
+	})
 }
 
 func TestProcessBackgroundSync(t *testing.T) {
@@ -157,7 +170,8 @@ func TestProcessBackgroundSync(t *testing.T) {
 	mockMailService := new(mocks.MockMailService)
 	logger := log.New(io.Discard, "", 0)
 	
-	service := NewSriService(mockTxRepo, mockIssuerRepo, mockReceiptRepo, mockClientRepo, nil, mockSriClient, mockMailService, logger)
+	service := NewSriService(mockTxRepo, mockIssuerRepo, mockReceiptRepo, mockClientRepo, nil, nil, nil, mockSriClient, mockMailService, logger)
+	service.AuthorizationDelay = 0
 	ctx := context.Background()
 
 	// Default expectations for the async finalizeAndEmail (to prevent panics)
@@ -186,16 +200,16 @@ func TestProcessBackgroundSync(t *testing.T) {
 				Autorizacion: []sri.Autorizacion{{Estado: "AUTORIZADO", FechaAutorizacion: time.Now().Format(time.RFC3339)}},
 			},
 		}
-		mockSriClient.On("AutorizarComprobante", "KEY1", 1).Return(sriAuthResp, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, "KEY1", 1).Return(sriAuthResp, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", ctx, "KEY1", "AUTORIZADO", mock.Anything, mock.Anything).Return(nil).Once()
 
 		// 2. KEY2: Send Fail (Network Error)
-		mockSriClient.On("EnviarComprobante", mock.Anything, 1).Return(nil, fmt.Errorf("timeout")).Once()
+		mockSriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(nil, fmt.Errorf("timeout")).Once()
 		mockReceiptRepo.On("UpdateStatus", ctx, "KEY2", "ERROR_RED", mock.Anything, mock.Anything).Return(nil).Once()
 
 		// 3. KEY3: Still Processing
 		sriPendingResp := &sri.RespuestaAutorizacion{NumeroComprobantes: "0"}
-		mockSriClient.On("AutorizarComprobante", "KEY3", 1).Return(sriPendingResp, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, "KEY3", 1).Return(sriPendingResp, nil).Once()
 		mockReceiptRepo.On("UpdateStatus", ctx, "KEY3", "EN PROCESO", mock.Anything, mock.Anything).Return(nil).Once()
 
 		// Act
@@ -219,4 +233,48 @@ func TestProcessBackgroundSync(t *testing.T) {
 		assert.Equal(t, 0, count)
 		mockSriClient.AssertNotCalled(t, "AutorizarComprobante")
 	})
-}
\ No newline at end of file
+
+	t.Run("Should stop when the SRI circuit is open", func(t *testing.T) {
+		pending := []domain.ElectronicReceipt{
+			{AccessKey: "DOWN1", SRIStatus: "RECIBIDA", Environment: 1},
+			{AccessKey: "DOWN2", SRIStatus: "RECIBIDA", Environment: 1},
+		}
+		mockReceiptRepo.On("FindPendingReceipts", ctx).Return(pending, nil).Once()
+		mockSriClient.On("AutorizarComprobante", mock.Anything, "DOWN1", 1).Return(nil, sri.ErrCircuitOpen).Once()
+
+		count, err := service.ProcessBackgroundSync(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		mockSriClient.AssertNotCalled(t, "AutorizarComprobante", mock.Anything, "DOWN2", 1)
+	})
+}
+func TestGetReceiptEvents(t *testing.T) {
+	mockReceiptRepo := new(mocks.MockElectronicReceiptRepository)
+	service := NewSriService(nil, nil, mockReceiptRepo, nil, nil, nil, nil, nil, nil, log.New(io.Discard, "", 0))
+	service.AuthorizationDelay = 0
+	ctx := context.Background()
+
+	t.Run("Should return the history of the receipt with that key", func(t *testing.T) {
+		events := []domain.ReceiptEvent{
+			{ReceiptID: 7, ToStatus: domain.ReceiptPending, Message: "Comprobante generado"},
+			{ReceiptID: 7, FromStatus: domain.ReceiptPending, ToStatus: domain.ReceiptReceived, Message: "Recibido por SRI"},
+		}
+		mockReceiptRepo.On("GetByAccessKey", ctx, "KEY7").Return(&domain.ElectronicReceipt{BaseEntity: domain.BaseEntity{ID: 7}}, nil).Once()
+		mockReceiptRepo.On("GetEvents", ctx, 7).Return(events, nil).Once()
+
+		got, err := service.GetReceiptEvents(ctx, "KEY7")
+
+		require.NoError(t, err)
+		assert.Equal(t, events, got)
+	})
+
+	t.Run("Should fail when the receipt does not exist", func(t *testing.T) {
+		mockReceiptRepo.On("GetByAccessKey", ctx, "MISSING").Return(nil, nil).Once()
+
+		_, err := service.GetReceiptEvents(ctx, "MISSING")
+
+		assert.ErrorContains(t, err, "no se encontró el comprobante")
+		mockReceiptRepo.AssertNumberOfCalls(t, "GetEvents", 1) // Solo la del caso anterior
+	})
+}
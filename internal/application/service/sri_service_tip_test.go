@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirFactura_Propina(t *testing.T) {
+	ctx := context.Background()
+	txID := 300
+
+	issuer := testIssuer()
+	client := &domain.TaxPayer{BaseEntity: domain.BaseEntity{ID: 50}, Identification: "1712345678", IdentificationType: "05", Name: "Test Client"}
+
+	// Consumo de $40 gravado con 15%: la propina del 10% se calcula sobre la base y no grava IVA
+	items := []domain.TransactionItem{
+		{Description: "Almuerzo", Quantity: 2, UnitPrice: 20, Subtotal: 40, TaxRate: 4},
+	}
+	tx := &domain.Transaction{
+		BaseEntity:      domain.BaseEntity{ID: txID},
+		TransactionDate: time.Now(),
+		TaxPayerID:      &client.ID,
+		Items:           items,
+		Category:        &domain.Category{Type: domain.Income},
+	}
+	require.NoError(t, tx.RecalculateTotals(testTaxRates()))
+	tx.Tip = tx.TipFor(domain.LegalServiceTipRate)
+	require.NoError(t, tx.RecalculateTotals(testTaxRates()))
+	require.Equal(t, 4.0, tx.Tip)
+	require.Equal(t, 6.0, tx.TaxAmount)
+	require.Equal(t, 50.0, tx.Amount)
+	tx.Items = nil
+
+	svc, m := newTestSriService(t)
+
+	m.TxRepo.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil).Once()
+	m.TxRepo.On("GetItemsByTransactionID", mock.Anything, txID).Return(items, nil).Once()
+	m.TxRepo.On("GetPaymentsByTransactionID", mock.Anything, txID).Return([]domain.TransactionPayment{
+		{PaymentMethod: "19", Amount: 50},
+	}, nil).Once()
+	m.TxRepo.On("GetAdditionalFieldsByTransactionID", mock.Anything, txID).Return([]domain.AdditionalField{}, nil).Once()
+	m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil)
+	m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil)
+	m.EpRepo.On("GetByPoint", mock.Anything, issuer.ID, "001", "001", "01").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, CurrentSequence: 1}, nil)
+	m.EpRepo.On("IncrementSequence", mock.Anything, 10).Return(nil).Once()
+
+	var unsigned []byte
+	m.Signer.On("Sign", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+		unsigned = args.Get(0).([]byte)
+	}).Return([]byte("<xml>signed</xml>"), nil).Once()
+	m.ReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	m.ReceiptRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+	m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, 1).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+	require.NoError(t, svc.EmitirFactura(ctx, txID, "password"))
+
+	var f sri.Factura
+	require.NoError(t, xml.Unmarshal(unsigned, &f))
+	assert.Equal(t, "4.00", f.InfoFactura.Propina)
+	assert.Equal(t, "40.00", f.InfoFactura.TotalSinImpuestos)
+	assert.Equal(t, "50.00", f.InfoFactura.ImporteTotal)
+	require.Len(t, f.Detalles.Detalle, 1)
+	assert.Equal(t, "40.00", f.Detalles.Detalle[0].Impuestos.Impuesto[0].BaseImponible)
+	assert.Equal(t, "6.00", f.Detalles.Detalle[0].Impuestos.Impuesto[0].Valor)
+	assert.Equal(t, "50.00", f.InfoFactura.Pagos.Pago[0].Total)
+}
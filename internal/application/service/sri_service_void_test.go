@@ -2,13 +2,10 @@ package service_test
 
 import (
 	"context"
-	"io"
-	"log"
 	"testing"
 	"time"
 
 	"github.com/nelsonmarro/verith/internal/application/service"
-	"github.com/nelsonmarro/verith/internal/application/service/mocks"
 	"github.com/nelsonmarro/verith/internal/domain"
 	"github.com/nelsonmarro/verith/internal/sri"
 	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
@@ -31,28 +28,13 @@ func (m *MockDocumentSigner) SignCreditNote(xmlBytes []byte, algo signer.HashAlg
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *MockDocumentSigner) SignDocument(xmlBytes []byte, algo signer.HashAlgorithm) ([]byte, error) {
+	args := m.Called(xmlBytes, algo)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 func TestEmitirNotaCredito(t *testing.T) {
-	// Setup Mocks
-	mockTxRepo := new(mocks.MockTransactionRepository)
-	mockIssuerRepo := new(mocks.MockIssuerRepository)
-	mockReceiptRepo := new(mocks.MockElectronicReceiptRepository)
-	mockClientRepo := new(mocks.MockTaxPayerRepository)
-	mockEpRepo := new(mocks.MockEmissionPointRepository)
-	mockSriClient := new(mocks.MockSRIClient)
-	mockMailService := new(mocks.MockMailService)
-	logger := log.New(io.Discard, "", 0)
-
-	// Service Under Test
-	sriService := service.NewSriService(
-		mockTxRepo,
-		mockIssuerRepo,
-		mockReceiptRepo,
-		mockClientRepo,
-		mockEpRepo,
-		mockSriClient,
-		mockMailService,
-		logger,
-	)
+	sriService, m := newTestSriService(t)
 
 	ctx := context.Background()
 	originalTxID := 100
@@ -64,6 +46,7 @@ func TestEmitirNotaCredito(t *testing.T) {
 	now := time.Now()
 	issuer := &domain.Issuer{
 		RUC:               "1790000000001",
+		BusinessName:      "Empresa de Prueba S.A.",
 		Environment:       1,
 		SignaturePath:     "/dummy/path.p12",
 		EstablishmentCode: "001",
@@ -73,9 +56,10 @@ func TestEmitirNotaCredito(t *testing.T) {
 	issuer.ID = 1
 	
 	client := &domain.TaxPayer{
-		Identification: "1710000000",
-		Name:           "Juan Perez",
-		Email:          "juan@test.com",
+		Identification:     "1710000000",
+		IdentificationType: "05",
+		Name:               "Juan Perez",
+		Email:              "juan@test.com",
 	}
 	client.ID = 5
 
@@ -95,7 +79,7 @@ func TestEmitirNotaCredito(t *testing.T) {
 		})
 
 		// 1. Cargar Datos
-		mockTxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
 		originalTx := &domain.Transaction{
 			BaseEntity: domain.BaseEntity{ID: originalTxID},
 			TransactionDate:   now.Add(-24 * time.Hour),
@@ -105,31 +89,31 @@ func TestEmitirNotaCredito(t *testing.T) {
 			Subtotal15:        100.00,
 			TaxAmount:         12.00,
 		}
-		mockTxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
 
-		mockTxRepo.On("GetItemsByTransactionID", ctx, originalTxID).Return([]domain.TransactionItem{
+		m.TxRepo.On("GetItemsByTransactionID", ctx, originalTxID).Return([]domain.TransactionItem{
 			{Description: "Item 1", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4},
 		}, nil).Once()
 
-		mockIssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
-		mockClientRepo.On("GetByID", ctx, client.ID).Return(client, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, client.ID).Return(client, nil).Once()
 
 		// 2. Generar Secuencial
-		mockEpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "04").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, ReceiptType: "04", CurrentSequence: 5}, nil).Times(2)
-		mockEpRepo.On("IncrementSequence", ctx, mock.Anything).Return(nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "04").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, ReceiptType: "04", CurrentSequence: 5}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, mock.Anything).Return(nil).Once()
 
 		// 3. Firma (Simulada)
 		signedXml := []byte("<xml>signed</xml>")
 		mockSigner.On("SignCreditNote", mock.Anything, signer.SHA1).Return(signedXml, nil).Once()
 
 		// 4. Guardar Recibo PENDIENTE
-		mockReceiptRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.ElectronicReceipt) bool {
+		m.ReceiptRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.ElectronicReceipt) bool {
 			return r.ReceiptType == "04" && r.SRIStatus == "PENDIENTE" && r.XMLContent == string(signedXml)
 		})).Return(nil).Once()
 
 		// 5. Enviar al SRI
-		mockSriClient.On("EnviarComprobante", signedXml, issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
-		mockReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+		m.SriClient.On("EnviarComprobante", mock.Anything, signedXml, issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
 
 		// 6. Autorizar (Simular espera)
 		authResp := &sri.RespuestaAutorizacion{
@@ -143,16 +127,16 @@ func TestEmitirNotaCredito(t *testing.T) {
 			},
 		}
 		// Match any access key generated (since it's random)
-		mockSriClient.On("AutorizarComprobante", mock.AnythingOfType("string"), issuer.Environment).Return(authResp, nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.AnythingOfType("string"), issuer.Environment).Return(authResp, nil).Once()
 
 		// 7. Actualizar Estado Final
-		mockReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "AUTORIZADO", "Procesado", mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "AUTORIZADO", "Procesado", mock.Anything).Return(nil).Once()
 		
 		// 8. Mock finalizeAndEmail requirements (Async calls)
 		// GetActive is called again inside finalizeAndEmail
-		mockIssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil).Maybe()
-		mockClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil).Maybe()
-		mockMailService.On("SendReceipt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		m.IssuerRepo.On("GetActive", mock.Anything).Return(issuer, nil).Maybe()
+		m.ClientRepo.On("GetByID", mock.Anything, client.ID).Return(client, nil).Maybe()
+		m.Mail.On("SendReceipt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 
 		// Ejecución
 		_, err := sriService.EmitirNotaCredito(ctx, voidTxID, originalTxID, motivo, password)
@@ -162,19 +146,19 @@ func TestEmitirNotaCredito(t *testing.T) {
 
 		// Verificación
 		assert.NoError(t, err)
-		mockTxRepo.AssertExpectations(t)
-		mockSriClient.AssertExpectations(t)
+		m.TxRepo.AssertExpectations(t)
+		m.SriClient.AssertExpectations(t)
 		mockSigner.AssertExpectations(t)
 	})
 
 	t.Run("Fallo: Transacción original sin recibo autorizado", func(t *testing.T) {
 		// Mock Original Tx (Sin recibo)
-		mockTxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
 		originalTx := &domain.Transaction{
 			BaseEntity: domain.BaseEntity{ID: originalTxID},
 			ElectronicReceipt: nil, // Fallo aquí
 		}
-		mockTxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
 
 				_, err := sriService.EmitirNotaCredito(ctx, voidTxID, originalTxID, motivo, password)
 
@@ -187,20 +171,20 @@ func TestEmitirNotaCredito(t *testing.T) {
 		mockSigner := new(MockDocumentSigner)
 		sriService.SetSignerFactory(func(path, password string) service.DocumentSigner { return mockSigner })
 
-		mockTxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
 		originalTx := &domain.Transaction{
-			BaseEntity: domain.BaseEntity{ID: originalTxID}, ElectronicReceipt: originalReceipt, TaxPayerID: &client.ID, Amount: 100, Subtotal15: 100,
+			BaseEntity: domain.BaseEntity{ID: originalTxID}, TransactionDate: now.Add(-24 * time.Hour), ElectronicReceipt: originalReceipt, TaxPayerID: &client.ID, Amount: 100, Subtotal15: 100,
 		}
-		mockTxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
-		mockTxRepo.On("GetItemsByTransactionID", ctx, originalTxID).Return([]domain.TransactionItem{{Description: "Item", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4}}, nil).Once()
-		mockIssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
-		mockClientRepo.On("GetByID", ctx, client.ID).Return(client, nil).Once()
-		mockEpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "04").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, ReceiptType: "04", CurrentSequence: 6}, nil).Times(2)
-		mockEpRepo.On("IncrementSequence", ctx, mock.Anything).Return(nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, originalTxID).Return([]domain.TransactionItem{{Description: "Item", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4}}, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, client.ID).Return(client, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "04").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, ReceiptType: "04", CurrentSequence: 6}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, mock.Anything).Return(nil).Once()
 		mockSigner.On("SignCreditNote", mock.Anything, signer.SHA1).Return([]byte("<xml>"), nil).Once()
 		
 		// Create receipt PENDING
-		mockReceiptRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
 
 		// SRI Devuelve
 		sriResp := &sri.RespuestaRecepcion{
@@ -213,10 +197,10 @@ func TestEmitirNotaCredito(t *testing.T) {
 				},
 			},
 		}
-		mockSriClient.On("EnviarComprobante", mock.Anything, issuer.Environment).Return(sriResp, nil).Once()
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, issuer.Environment).Return(sriResp, nil).Once()
 		
 		// Update Status DEVUELTA
-		mockReceiptRepo.On("UpdateStatus", ctx, mock.Anything, "DEVUELTA", "Error de esquema", mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.Anything, "DEVUELTA", "Error de esquema", mock.Anything).Return(nil).Once()
 
 		_, err := sriService.EmitirNotaCredito(ctx, voidTxID, originalTxID, motivo, password)
 		assert.Error(t, err)
@@ -228,34 +212,36 @@ func TestEmitirNotaCredito(t *testing.T) {
 		mockSigner := new(MockDocumentSigner)
 		sriService.SetSignerFactory(func(path, password string) service.DocumentSigner { return mockSigner })
 
-		mockTxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, voidTxID).Return(&domain.Transaction{BaseEntity: domain.BaseEntity{ID: voidTxID}}, nil).Once()
 		originalTx := &domain.Transaction{
-			BaseEntity: domain.BaseEntity{ID: originalTxID}, ElectronicReceipt: originalReceipt, TaxPayerID: &client.ID, Amount: 100, Subtotal15: 100,
+			BaseEntity: domain.BaseEntity{ID: originalTxID}, TransactionDate: now.Add(-24 * time.Hour), ElectronicReceipt: originalReceipt, TaxPayerID: &client.ID, Amount: 100, Subtotal15: 100,
 		}
-		mockTxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
-		mockTxRepo.On("GetItemsByTransactionID", ctx, originalTxID).Return([]domain.TransactionItem{{Description: "Item", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4}}, nil).Once()
-		mockIssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
-		mockClientRepo.On("GetByID", ctx, client.ID).Return(client, nil).Once()
-		mockEpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "04").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, ReceiptType: "04", CurrentSequence: 7}, nil).Times(2)
-		mockEpRepo.On("IncrementSequence", ctx, mock.Anything).Return(nil).Once()
+		m.TxRepo.On("GetTransactionByID", ctx, originalTxID).Return(originalTx, nil).Once()
+		m.TxRepo.On("GetItemsByTransactionID", ctx, originalTxID).Return([]domain.TransactionItem{{Description: "Item", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4}}, nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, client.ID).Return(client, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "04").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 10}, ReceiptType: "04", CurrentSequence: 7}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, mock.Anything).Return(nil).Once()
 		mockSigner.On("SignCreditNote", mock.Anything, signer.SHA1).Return([]byte("<xml>"), nil).Once()
-		mockReceiptRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+		m.ReceiptRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
 		
 		// Envío OK
-		mockSriClient.On("EnviarComprobante", mock.Anything, issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
-		mockReceiptRepo.On("UpdateStatus", ctx, mock.Anything, "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+		m.SriClient.On("EnviarComprobante", mock.Anything, mock.Anything, issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.Anything, "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
 
 		// Autorización FALLIDA
+		rejected := sri.Autorizacion{Estado: "NO AUTORIZADO"}
+		rejected.Mensajes.Mensaje = []sri.Mensaje{{Identificador: "65", Mensaje: "FECHA EMISION EXTEMPORANEA", InformacionAdicional: "fuera de plazo"}}
 		authResp := &sri.RespuestaAutorizacion{
 			NumeroComprobantes: "1",
 			Autorizaciones: struct { Autorizacion []sri.Autorizacion `xml:"autorizacion"` }{
-				Autorizacion: []sri.Autorizacion{{Estado: "NO AUTORIZADO"}},
+				Autorizacion: []sri.Autorizacion{rejected},
 			},
 		}
-		mockSriClient.On("AutorizarComprobante", mock.Anything, issuer.Environment).Return(authResp, nil).Once()
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.Anything, issuer.Environment).Return(authResp, nil).Once()
 		
-		// Update Status PROCESADO (pero estado interno NO AUTORIZADO)
-		mockReceiptRepo.On("UpdateStatus", ctx, mock.Anything, "NO AUTORIZADO", "Procesado", mock.Anything).Return(nil).Once()
+		// La historia guarda el motivo del SRI, no "Procesado"
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.Anything, "RECHAZADA", "65: FECHA EMISION EXTEMPORANEA (fuera de plazo)", mock.Anything).Return(nil).Once()
 
 		_, err := sriService.EmitirNotaCredito(ctx, voidTxID, originalTxID, motivo, password)
 		assert.Error(t, err)
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// numDocPattern valida el formato establecimiento-punto-secuencial (001-001-000000123)
+var numDocPattern = regexp.MustCompile(`^\d{3}-\d{3}-\d{9}$`)
+
+// EmitirRetencion emite un Comprobante de Retención (07) sobre la factura de un proveedor,
+// vinculado a la transacción de egreso que la registra.
+func (s *SriService) EmitirRetencion(ctx context.Context, w *domain.Withholding, signaturePassword string) (string, error) {
+	s.logger.Printf("Iniciando emisión de Retención para transacción ID: %d", w.TransactionID)
+
+	if err := validateWithholding(w); err != nil {
+		return "", err
+	}
+
+	// 1. Cargar Datos
+	tx, err := s.txRepo.GetTransactionByID(ctx, w.TransactionID)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo transacción: %w", err)
+	}
+	if tx.Category == nil || tx.Category.Type != domain.Outcome {
+		return "", errors.New("solo se pueden emitir retenciones sobre transacciones de egreso")
+	}
+	if tx.IsVoided {
+		return "", errors.New("la transacción está anulada")
+	}
+	if tx.IsPartialCredit() {
+		return "", errors.New("la transacción es una devolución de venta; emita su nota de crédito")
+	}
+
+	// Solo permitimos reemplazar comprobantes que fallaron definitivamente
+	// (o que quedaron trabados EN PROCESO por más de 2 horas, igual que en EmitirFactura)
+	existing := tx.ElectronicReceipt
+	if existing != nil {
+		switch {
+		case existing.ReceiptType != "07":
+			return "", fmt.Errorf("la transacción ya tiene un comprobante electrónico de tipo %s", existing.ReceiptType)
+		case existing.CanReissue(time.Now()):
+			s.logger.Printf("Retención anterior falló (%s). Se emitirá con nueva Clave de Acceso.", existing.SRIStatus)
+		default:
+			return "", fmt.Errorf("la transacción ya tiene un comprobante de retención en estado %s", existing.SRIStatus)
+		}
+	}
+
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil || issuer == nil {
+		return "", errors.New("no hay un emisor activo configurado")
+	}
+
+	supplier, err := s.clientRepo.GetByID(ctx, w.SupplierID)
+	if err != nil || supplier == nil {
+		return "", errors.New("no se encontró el proveedor (sujeto retenido)")
+	}
+	if supplier.Identification == "9999999999999" {
+		return "", errors.New("no se puede emitir una retención a Consumidor Final")
+	}
+
+	taxes, err := s.taxSummary(ctx, tx, tx.TransactionDate)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. Secuencial y Clave de Acceso (Tipo 07)
+	secuencialSRI, err := s.nextSequential(ctx, issuer, "07")
+	if err != nil {
+		return "", err
+	}
+
+	emissionDate := time.Now()
+	claveAcceso := sri.GenerateAccessKey(
+		emissionDate,
+		"07",
+		issuer.RUC,
+		issuer.Environment,
+		issuer.EstablishmentCode,
+		issuer.EmissionPointCode,
+		secuencialSRI,
+		newNumericCode(),
+		1,
+	)
+
+	// 3. Generar y Firmar XML
+	crXML := s.mapToRetencion(tx, taxes, issuer, supplier, w, claveAcceso, secuencialSRI, emissionDate)
+	xmlBytes, err := sri.MarshalComprobanteRetencion(crXML)
+	if err != nil {
+		return "", err
+	}
+
+	signerObj := s.signerFactory(issuer.SignaturePath, signaturePassword)
+	signedXML, err := signerObj.SignDocument(xmlBytes, sri.SHA1)
+	if err != nil {
+		s.logger.Printf("Error al firmar retención: %v", err)
+		return "", signatureError(err)
+	}
+
+	// 4. Guardar Recibo
+	receipt := &domain.ElectronicReceipt{
+		TransactionID: tx.ID,
+		IssuerID:      issuer.ID,
+		TaxPayerID:    supplier.ID,
+		AccessKey:     claveAcceso,
+		ReceiptType:   "07",
+		XMLContent:    strings.TrimSpace(string(signedXML)),
+		Environment:   issuer.Environment,
+	}
+	if err := s.savePendingReceipt(ctx, existing, receipt); err != nil {
+		return "", err
+	}
+
+	// 5. Enviar y Autorizar
+	return s.submitAndAuthorize(ctx, receipt, "el comprobante de retención")
+}
+
+// printedAuthPattern es el número de autorización de 10 dígitos de los comprobantes preimpresos.
+var printedAuthPattern = regexp.MustCompile(`^\d{10}$`)
+
+// validateSupportAuthorization acepta una autorización vacía, la de un comprobante preimpreso o una
+// clave de acceso válida que corresponda al número de factura indicado.
+func validateSupportAuthorization(auth, docNumber string) error {
+	auth = strings.TrimSpace(auth)
+	if auth == "" || printedAuthPattern.MatchString(auth) {
+		return nil
+	}
+	key, err := sri.ParseAccessKey(auth)
+	if err != nil {
+		return fmt.Errorf("autorización de la factura del proveedor: %w", err)
+	}
+	if key.DocumentNumber() != docNumber {
+		return fmt.Errorf("la clave de acceso corresponde al comprobante %s y no al %s", key.DocumentNumber(), docNumber)
+	}
+	return nil
+}
+
+func validateWithholding(w *domain.Withholding) error {
+	if w == nil {
+		return errors.New("datos de retención vacíos")
+	}
+	if len(w.Lines) == 0 {
+		return errors.New("la retención debe tener al menos una línea")
+	}
+	if !numDocPattern.MatchString(w.SupportDocNumber) {
+		return errors.New("el número de la factura del proveedor debe tener el formato 001-001-000000123")
+	}
+	if w.SupportDocDate.IsZero() || w.SupportDocDate.After(time.Now()) {
+		return errors.New("la fecha de la factura del proveedor no es válida")
+	}
+	if err := validateSupportAuthorization(w.SupportDocAuthKey, w.SupportDocNumber); err != nil {
+		return err
+	}
+	for i, l := range w.Lines {
+		if l.TaxCode != domain.WithholdingTaxRenta && l.TaxCode != domain.WithholdingTaxIVA {
+			return fmt.Errorf("línea %d: impuesto no soportado (%s)", i+1, l.TaxCode)
+		}
+		if strings.TrimSpace(l.RetentionCode) == "" {
+			return fmt.Errorf("línea %d: falta el código de retención", i+1)
+		}
+		if l.TaxBase <= 0 {
+			return fmt.Errorf("línea %d: la base imponible debe ser mayor a cero", i+1)
+		}
+		if l.Percentage <= 0 || l.Percentage > 100 {
+			return fmt.Errorf("línea %d: porcentaje de retención inválido", i+1)
+		}
+	}
+	return nil
+}
+
+func (s *SriService) mapToRetencion(tx *domain.Transaction, taxes *domain.TaxSummary, issuer *domain.Issuer, supplier *domain.TaxPayer, w *domain.Withholding, claveAcceso, secuencial string, emissionDate time.Time) *sri.ComprobanteRetencion {
+	cr := &sri.ComprobanteRetencion{}
+
+	cr.InfoTributaria = sri.InfoTributaria{
+		Ambiente:        strconv.Itoa(issuer.Environment),
+		TipoEmision:     "1",
+		RazonSocial:     cleanText(issuer.BusinessName),
+		NombreComercial: cleanText(issuer.TradeName),
+		Ruc:             issuer.RUC,
+		ClaveAcceso:     claveAcceso,
+		CodDoc:          "07", // Comprobante de Retención
+		Estab:           issuer.EstablishmentCode,
+		PtoEmi:          issuer.EmissionPointCode,
+		Secuencial:      secuencial,
+		DirMatriz:       cleanText(issuer.MainAddress),
+	}
+
+	cr.InfoCompRetencion = sri.InfoCompRetencion{
+		FechaEmision:                     emissionDate.Format("02/01/2006"),
+		DirEstablecimiento:               cleanText(issuer.EstablishmentAddress),
+		ObligadoContabilidad:             map[bool]string{true: "SI", false: "NO"}[issuer.KeepAccounting],
+		TipoIdentificacionSujetoRetenido: supplier.IdentificationType,
+		ParteRel:                         "NO",
+		RazonSocialSujetoRetenido:        cleanText(supplier.Name),
+		IdentificacionSujetoRetenido:     supplier.Identification,
+		PeriodoFiscal:                    emissionDate.Format("01/2006"),
+	}
+
+	supportCode := w.SupportCode
+	if supportCode == "" {
+		supportCode = "01" // Crédito tributario para declaración de IVA
+	}
+	supportDocType := w.SupportDocType
+	if supportDocType == "" {
+		supportDocType = "01"
+	}
+
+	// Egresos simples sin desglose se declaran íntegramente en tarifa 0%
+	totals := withZeroRateFallback(taxes.Totals, tx.Amount)
+
+	doc := sri.DocSustento{
+		CodSustento:             supportCode,
+		CodDocSustento:          supportDocType,
+		NumDocSustento:          strings.ReplaceAll(w.SupportDocNumber, "-", ""),
+		FechaEmisionDocSustento: w.SupportDocDate.Format("02/01/2006"),
+		FechaRegistroContable:   tx.TransactionDate.Format("02/01/2006"),
+		NumAutDocSustento:       strings.TrimSpace(w.SupportDocAuthKey),
+		PagoLocExt:              "01", // Pago local
+		TotalSinImpuestos:       fmt.Sprintf("%.2f", taxBase(totals)),
+		ImporteTotal:            fmt.Sprintf("%.2f", tx.Amount),
+	}
+
+	// Impuestos del documento sustento (según lo registrado en el egreso)
+	for _, total := range totals {
+		doc.ImpuestosDocSustento.ImpuestoDocSustento = append(doc.ImpuestosDocSustento.ImpuestoDocSustento, sri.ImpuestoDocSustento{
+			CodImpuestoDocSustento: "2",
+			CodigoPorcentaje:       strconv.Itoa(total.Rate.Code),
+			BaseImponible:          fmt.Sprintf("%.2f", total.Base),
+			Tarifa:                 total.Rate.Tarifa(),
+			ValorImpuesto:          fmt.Sprintf("%.2f", total.Tax),
+		})
+	}
+
+	for _, l := range w.Lines {
+		doc.Retenciones.Retencion = append(doc.Retenciones.Retencion, sri.Retencion{
+			Codigo:            l.TaxCode,
+			CodigoRetencion:   strings.TrimSpace(l.RetentionCode),
+			BaseImponible:     fmt.Sprintf("%.2f", l.TaxBase),
+			PorcentajeRetener: strconv.FormatFloat(l.Percentage, 'f', -1, 64),
+			ValorRetenido:     fmt.Sprintf("%.2f", l.Amount()),
+		})
+	}
+
+	doc.Pagos.Pago = append(doc.Pagos.Pago, sri.Pago{
+		FormaPago: "20", // Otros con utilización del sistema financiero
+		Total:     fmt.Sprintf("%.2f", tx.Amount),
+	})
+
+	cr.DocsSustento.DocSustento = append(cr.DocsSustento.DocSustento, doc)
+	return cr
+}
@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitirRetencion(t *testing.T) {
+	ctx := context.Background()
+
+	issuer := testIssuer()
+
+	supplier := &domain.TaxPayer{
+		Identification:     "1791234567001",
+		IdentificationType: "04",
+		Name:               "Proveedor S.A.",
+		Email:              "proveedor@test.com",
+	}
+	supplier.ID = 8
+
+	expenseTx := func() *domain.Transaction {
+		return &domain.Transaction{
+			BaseEntity:      domain.BaseEntity{ID: 50},
+			TransactionDate: time.Now().Add(-48 * time.Hour),
+			Amount:          115.00,
+			Subtotal15:      100.00,
+			TaxAmount:       15.00,
+			Category:        &domain.Category{Type: domain.Outcome},
+		}
+	}
+
+	validWithholding := func() *domain.Withholding {
+		return &domain.Withholding{
+			TransactionID:    50,
+			SupplierID:       supplier.ID,
+			SupportDocNumber: "001-002-000000345",
+			SupportDocDate:   time.Now().Add(-48 * time.Hour),
+			Lines: []domain.WithholdingLine{
+				{TaxCode: domain.WithholdingTaxRenta, RetentionCode: "312", TaxBase: 100, Percentage: 1.75},
+				{TaxCode: domain.WithholdingTaxIVA, RetentionCode: "1", TaxBase: 15, Percentage: 30},
+			},
+		}
+	}
+
+	t.Run("Success: genera XML 07 y queda RECIBIDA", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		m.TxRepo.On("GetTransactionByID", ctx, 50).Return(expenseTx(), nil).Once()
+		m.IssuerRepo.On("GetActive", ctx).Return(issuer, nil).Once()
+		m.ClientRepo.On("GetByID", ctx, supplier.ID).Return(supplier, nil).Once()
+		m.EpRepo.On("GetByPoint", ctx, issuer.ID, "001", "001", "07").Return(&domain.EmissionPoint{BaseEntity: domain.BaseEntity{ID: 3}, ReceiptType: "07", CurrentSequence: 12}, nil).Times(2)
+		m.EpRepo.On("IncrementSequence", ctx, 3).Return(nil).Once()
+
+		// Capturamos el XML antes de firmar para validar su contenido
+		var unsigned []byte
+		m.Signer.On("SignDocument", mock.Anything, signer.SHA1).Run(func(args mock.Arguments) {
+			unsigned = args.Get(0).([]byte)
+		}).Return([]byte("<xml>signed</xml>"), nil).Once()
+
+		m.ReceiptRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.ElectronicReceipt) bool {
+			return r.ReceiptType == "07" && r.TransactionID == 50 && r.TaxPayerID == supplier.ID && r.SRIStatus == "PENDIENTE"
+		})).Return(nil).Once()
+
+		m.SriClient.On("EnviarComprobante", mock.Anything, []byte("<xml>signed</xml>"), issuer.Environment).Return(&sri.RespuestaRecepcion{Estado: "RECIBIDA"}, nil).Once()
+		m.ReceiptRepo.On("UpdateStatus", ctx, mock.AnythingOfType("string"), "RECIBIDA", "Enviado a SRI", mock.Anything).Return(nil).Once()
+		// Sin autorizaciones todavía: queda para el proceso en segundo plano
+		m.SriClient.On("AutorizarComprobante", mock.Anything, mock.AnythingOfType("string"), issuer.Environment).Return(&sri.RespuestaAutorizacion{}, nil).Once()
+
+		key, err := svc.EmitirRetencion(ctx, validWithholding(), "pass")
+		require.NoError(t, err)
+		assert.Len(t, key, 49)
+		assert.Equal(t, "07", key[8:10])
+
+		var cr sri.ComprobanteRetencion
+		require.NoError(t, xml.Unmarshal(unsigned, &cr))
+		assert.Equal(t, "2.0.0", cr.Version)
+		assert.Equal(t, "000000012", cr.InfoTributaria.Secuencial)
+		assert.Equal(t, supplier.Identification, cr.InfoCompRetencion.IdentificacionSujetoRetenido)
+		require.Len(t, cr.DocsSustento.DocSustento, 1)
+
+		doc := cr.DocsSustento.DocSustento[0]
+		assert.Equal(t, "001002000000345", doc.NumDocSustento)
+		assert.Equal(t, "115.00", doc.ImporteTotal)
+		require.Len(t, doc.Retenciones.Retencion, 2)
+		assert.Equal(t, "1.75", doc.Retenciones.Retencion[0].ValorRetenido)
+		assert.Equal(t, "4.50", doc.Retenciones.Retencion[1].ValorRetenido)
+
+		m.EpRepo.AssertExpectations(t)
+		m.Signer.AssertExpectations(t)
+		m.SriClient.AssertExpectations(t)
+	})
+
+	t.Run("Fallo: transacción de ingreso", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		tx := expenseTx()
+		tx.Category = &domain.Category{Type: domain.Income}
+		m.TxRepo.On("GetTransactionByID", ctx, 50).Return(tx, nil).Once()
+
+		_, err := svc.EmitirRetencion(ctx, validWithholding(), "pass")
+		assert.ErrorContains(t, err, "transacciones de egreso")
+		m.EpRepo.AssertNotCalled(t, "IncrementSequence", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fallo: número de factura del proveedor inválido", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		w := validWithholding()
+		w.SupportDocNumber = "345"
+
+		_, err := svc.EmitirRetencion(ctx, w, "pass")
+		assert.ErrorContains(t, err, "001-001-000000123")
+		m.TxRepo.AssertNotCalled(t, "GetTransactionByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fallo: clave de acceso del proveedor inválida", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		w := validWithholding()
+		key := sri.GenerateAccessKey(w.SupportDocDate, "01", "1790011122001", 2, "001", "002", "000000345", "12345678", 1)
+		w.SupportDocAuthKey = key[:48] + "X"
+
+		_, err := svc.EmitirRetencion(ctx, w, "pass")
+		assert.ErrorIs(t, err, sri.ErrInvalidAccessKey)
+
+		// Clave válida pero de otro comprobante
+		w.SupportDocAuthKey = sri.GenerateAccessKey(w.SupportDocDate, "01", "1790011122001", 2, "001", "002", "000000999", "12345678", 1)
+		_, err = svc.EmitirRetencion(ctx, w, "pass")
+		assert.ErrorContains(t, err, "001-002-000000999")
+		m.TxRepo.AssertNotCalled(t, "GetTransactionByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fallo: ya existe una retención autorizada", func(t *testing.T) {
+		svc, m := newTestSriService(t)
+
+		tx := expenseTx()
+		tx.ElectronicReceipt = &domain.ElectronicReceipt{ReceiptType: "07", SRIStatus: "AUTORIZADO"}
+		m.TxRepo.On("GetTransactionByID", ctx, 50).Return(tx, nil).Once()
+
+		_, err := svc.EmitirRetencion(ctx, validWithholding(), "pass")
+		assert.ErrorContains(t, err, "AUTORIZADO")
+	})
+}
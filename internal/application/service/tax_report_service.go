@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// atsBankingThreshold es el monto desde el que una compra debe reportar su forma de pago en el ATS
+// (pagos que deben hacerse por el sistema financiero).
+const atsBankingThreshold = 500.0
+
+// TaxReportService genera los anexos tributarios que se cargan en el DIMM del SRI.
+type TaxReportService struct {
+	repo       TaxReportRepository
+	issuerRepo IssuerRepository
+}
+
+func NewTaxReportService(repo TaxReportRepository, issuerRepo IssuerRepository) *TaxReportService {
+	return &TaxReportService{repo: repo, issuerRepo: issuerRepo}
+}
+
+// GenerateATS arma el Anexo Transaccional Simplificado del mes, lo valida contra el esquema del SRI
+// y lo guarda en outputPath.
+func (s *TaxReportService) GenerateATS(ctx context.Context, year int, month time.Month, outputPath string) error {
+	data, err := s.BuildATS(ctx, year, month)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+		return fmt.Errorf("no se pudo guardar el ATS: %w", err)
+	}
+	return nil
+}
+
+// BuildATS devuelve el XML del ATS del mes. Las ventas salen de los comprobantes autorizados
+// (agrupados por cliente y tipo de comprobante) y las compras de los egresos con comprobante del
+// proveedor; los comprobantes anulados en el SRI van en la sección de anulados.
+func (s *TaxReportService) BuildATS(ctx context.Context, year int, month time.Month) ([]byte, error) {
+	if month < time.January || month > time.December {
+		return nil, fmt.Errorf("mes %d inválido", month)
+	}
+	issuer, err := s.issuerRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el emisor: %w", err)
+	}
+	if issuer == nil {
+		return nil, errors.New("no hay un emisor activo configurado")
+	}
+
+	startDate := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	sales, err := s.repo.GetSaleDocuments(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	purchases, err := s.repo.GetPurchaseDocuments(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	ats := &sri.ATS{
+		TipoIDInformante: "R",
+		IdInformante:     issuer.RUC,
+		RazonSocial:      atsText(issuer.BusinessName),
+		Anio:             strconv.Itoa(year),
+		Mes:              fmt.Sprintf("%02d", int(month)),
+		CodigoOperativo:  "IVA",
+	}
+
+	if err := addATSPurchases(ats, purchases); err != nil {
+		return nil, err
+	}
+	if err := addATSSales(ats, sales, issuer.EstablishmentCode); err != nil {
+		return nil, err
+	}
+
+	data, err := sri.MarshalATS(ats)
+	if err != nil {
+		return nil, err
+	}
+	if err := sri.ValidateATS(data); err != nil {
+		return nil, fmt.Errorf("el ATS generado no es válido: %w", err)
+	}
+	return data, nil
+}
+
+func addATSPurchases(ats *sri.ATS, purchases []domain.TaxPurchaseDocument) error {
+	if len(purchases) == 0 {
+		return nil
+	}
+	ats.Compras = &sri.ATSCompras{}
+	for _, p := range purchases {
+		key, err := sri.ParseAccessKey(p.AccessKey)
+		if err != nil {
+			return fmt.Errorf("compra %d: %w", p.TransactionID, err)
+		}
+
+		// Egresos simples sin desglose se declaran íntegramente en tarifa 0%
+		base0 := p.Subtotal0
+		if p.Subtotal15 == 0 && base0 == 0 {
+			base0 = p.Amount
+		}
+
+		d := sri.DetalleCompras{
+			CodSustento:       "02", // Costo o gasto
+			TpIdProv:          atsSupplierIDType(p.SupplierIdentificationType, p.SupplierIdentification),
+			IdProv:            p.SupplierIdentification,
+			TipoComprobante:   key.DocType,
+			ParteRel:          "NO",
+			FechaRegistro:     p.TransactionDate.Format("02/01/2006"),
+			Establecimiento:   key.Establishment,
+			PuntoEmision:      key.EmissionPoint,
+			Secuencial:        atsSequential(key.Sequential),
+			FechaEmision:      key.Date.Format("02/01/2006"),
+			Autorizacion:      key.Raw,
+			BaseNoGraIva:      "0.00",
+			BaseImponible:     fmt.Sprintf("%.2f", base0),
+			BaseImpGrav:       fmt.Sprintf("%.2f", p.Subtotal15+p.ICEAmount), // La base del IVA incluye el ICE
+			BaseImpExe:        "0.00",
+			MontoIce:          fmt.Sprintf("%.2f", p.ICEAmount),
+			MontoIva:          fmt.Sprintf("%.2f", p.TaxAmount),
+			ValRetBien10:      "0.00",
+			ValRetServ20:      "0.00",
+			ValorRetBienes:    "0.00",
+			ValRetServ50:      "0.00",
+			ValorRetServicios: "0.00",
+			ValRetServ100:     "0.00",
+			TotbasesImpReemb:  "0.00",
+			PagoExterior:      sri.ATSPagoExterior{PagoLocExt: "01", PaisEfecPago: "NA", AplicConvDobTrib: "NA", PagExtSujRetNorLeg: "NA"},
+		}
+		if p.TaxAmount > 0 {
+			d.CodSustento = "01" // Crédito tributario para declaración de IVA
+		}
+		if d.TpIdProv == "03" {
+			d.TipoProv = "01"
+			d.DenoProv = atsText(p.SupplierName)
+		}
+		if p.Amount >= atsBankingThreshold {
+			d.FormasDePago = &sri.ATSFormasDePago{FormaPago: []string{"20"}} // Otros con utilización del sistema financiero
+		}
+
+		if p.WithholdingXML != "" {
+			if err := addATSWithholding(&d, p); err != nil {
+				return fmt.Errorf("compra %s: %w", key.DocumentNumber(), err)
+			}
+		}
+		ats.Compras.DetalleCompras = append(ats.Compras.DetalleCompras, d)
+	}
+	return nil
+}
+
+// purchaseWithholding lee el XML de la retención emitida sobre la compra y devuelve los documentos
+// sustento que corresponden a su comprobante.
+func purchaseWithholding(p domain.TaxPurchaseDocument) (*sri.ComprobanteRetencion, []sri.DocSustento, error) {
+	var cr sri.ComprobanteRetencion
+	if err := xml.Unmarshal([]byte(p.WithholdingXML), &cr); err != nil {
+		return nil, nil, fmt.Errorf("no se pudo leer la retención: %w", err)
+	}
+	var docs []sri.DocSustento
+	for _, doc := range cr.DocsSustento.DocSustento {
+		if doc.NumAutDocSustento != "" && doc.NumAutDocSustento != p.AccessKey {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return &cr, docs, nil
+}
+
+// addATSWithholding toma los valores retenidos del XML de la retención emitida sobre la compra.
+func addATSWithholding(d *sri.DetalleCompras, p domain.TaxPurchaseDocument) error {
+	cr, docs, err := purchaseWithholding(p)
+	if err != nil {
+		return err
+	}
+	key, err := sri.ParseAccessKey(p.WithholdingAccessKey)
+	if err != nil {
+		return fmt.Errorf("retención: %w", err)
+	}
+
+	ivaFields := map[string]*string{
+		"10":  &d.ValRetBien10,
+		"20":  &d.ValRetServ20,
+		"30":  &d.ValorRetBienes,
+		"50":  &d.ValRetServ50,
+		"70":  &d.ValorRetServicios,
+		"100": &d.ValRetServ100,
+	}
+	ivaTotals := map[string]float64{}
+
+	for _, doc := range docs {
+		if doc.CodSustento != "" {
+			d.CodSustento = doc.CodSustento
+		}
+		for _, r := range doc.Retenciones.Retencion {
+			value, _ := strconv.ParseFloat(r.ValorRetenido, 64)
+			rate, _ := strconv.ParseFloat(r.PorcentajeRetener, 64)
+			switch r.Codigo {
+			case domain.WithholdingTaxIVA:
+				pct := strconv.FormatFloat(rate, 'f', -1, 64)
+				if _, ok := ivaFields[pct]; !ok {
+					return fmt.Errorf("porcentaje de retención de IVA %s%% no reconocido", r.PorcentajeRetener)
+				}
+				ivaTotals[pct] += value
+			case domain.WithholdingTaxRenta:
+				base, _ := strconv.ParseFloat(r.BaseImponible, 64)
+				if d.Air == nil {
+					d.Air = &sri.ATSAir{}
+				}
+				d.Air.DetalleAir = append(d.Air.DetalleAir, sri.DetalleAir{
+					CodRetAir:     r.CodigoRetencion,
+					BaseImpAir:    fmt.Sprintf("%.2f", base),
+					PorcentajeAir: fmt.Sprintf("%.2f", rate),
+					ValRetAir:     fmt.Sprintf("%.2f", value),
+				})
+			}
+		}
+	}
+	for pct, total := range ivaTotals {
+		*ivaFields[pct] = fmt.Sprintf("%.2f", total)
+	}
+
+	d.EstabRetencion1 = key.Establishment
+	d.PtoEmiRetencion1 = key.EmissionPoint
+	d.SecRetencion1 = atsSequential(key.Sequential)
+	d.AutRetencion1 = key.Raw
+	d.FechaEmiRet1 = cr.InfoCompRetencion.FechaEmision
+	return nil
+}
+
+// atsSaleTypes traduce el codDoc del comprobante al tipo de comprobante del módulo de ventas.
+var atsSaleTypes = map[string]string{
+	"01": "18", // Documentos autorizados utilizados en ventas
+	"04": "04",
+	"05": "05",
+}
+
+func addATSSales(ats *sri.ATS, sales []domain.TaxSaleDocument, issuerEstablishment string) error {
+	type salesGroup struct {
+		detail   sri.DetalleVentas
+		base0    float64
+		base15   float64
+		tax      float64
+		ice      float64
+		payments map[string]bool
+	}
+	var groups []*salesGroup
+	byKey := map[string]*salesGroup{}
+	establishments := map[string]float64{issuerEstablishment: 0}
+
+	for _, doc := range sales {
+		key, err := sri.ParseAccessKey(doc.AccessKey)
+		if err != nil {
+			return fmt.Errorf("venta %d: %w", doc.TransactionID, err)
+		}
+		if _, ok := establishments[key.Establishment]; !ok {
+			establishments[key.Establishment] = 0
+		}
+
+		if doc.SRIStatus == "ANULADO" {
+			if ats.Anulados == nil {
+				ats.Anulados = &sri.ATSAnulados{}
+			}
+			seq := atsSequential(key.Sequential)
+			ats.Anulados.DetalleAnulados = append(ats.Anulados.DetalleAnulados, sri.DetalleAnulados{
+				TipoComprobante:  key.DocType,
+				Establecimiento:  key.Establishment,
+				PuntoEmision:     key.EmissionPoint,
+				SecuencialInicio: seq,
+				SecuencialFin:    seq,
+				Autorizacion:     key.Raw,
+			})
+			continue
+		}
+
+		docType := atsSaleTypes[doc.ReceiptType]
+		idType := atsCustomerIDType(doc.CustomerIdentificationType)
+		groupKey := idType + "|" + doc.CustomerIdentification + "|" + docType
+		g, ok := byKey[groupKey]
+		if !ok {
+			g = &salesGroup{
+				detail: sri.DetalleVentas{
+					TpIdCliente:     idType,
+					IdCliente:       doc.CustomerIdentification,
+					TipoComprobante: docType,
+					TipoEmision:     "E",
+				},
+				payments: map[string]bool{},
+			}
+			if idType != "07" {
+				g.detail.ParteRelVtas = "NO"
+			}
+			if idType == "06" {
+				g.detail.TipoCliente = "01"
+				g.detail.DenoCli = atsText(doc.CustomerName)
+			}
+			byKey[groupKey] = g
+			groups = append(groups, g)
+		}
+
+		g.detail.NumeroComprobantes++
+		g.base0 += doc.Subtotal0
+		g.base15 += doc.Subtotal15 + doc.ICEAmount // La base del IVA incluye el ICE
+		g.tax += doc.TaxAmount
+		g.ice += doc.ICEAmount
+		if len(doc.PaymentMethods) == 0 {
+			g.payments["01"] = true
+		}
+		for _, pm := range doc.PaymentMethods {
+			g.payments[pm] = true
+		}
+
+		base := doc.Subtotal0 + doc.Subtotal15
+		if doc.ReceiptType == "04" {
+			base = -base
+		}
+		establishments[key.Establishment] += base
+	}
+
+	if len(groups) > 0 {
+		ats.Ventas = &sri.ATSVentas{}
+		for _, g := range groups {
+			g.detail.BaseNoGraIva = "0.00"
+			g.detail.BaseImponible = fmt.Sprintf("%.2f", g.base0)
+			g.detail.BaseImpGrav = fmt.Sprintf("%.2f", g.base15)
+			g.detail.MontoIva = fmt.Sprintf("%.2f", g.tax)
+			g.detail.MontoIce = fmt.Sprintf("%.2f", g.ice)
+			g.detail.ValorRetIva = "0.00"
+			g.detail.ValorRetRenta = "0.00"
+			if g.detail.TipoComprobante != "04" {
+				g.detail.FormasDePago = &sri.ATSFormasDePago{FormaPago: sortedKeys(g.payments)}
+			}
+			ats.Ventas.DetalleVentas = append(ats.Ventas.DetalleVentas, g.detail)
+		}
+	}
+
+	codes := make([]string, 0, len(establishments))
+	for code := range establishments {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var total float64
+	ats.VentasEstablecimiento = &sri.ATSVentasEstablecimiento{}
+	for _, code := range codes {
+		// Las notas de crédito no pueden dejar en negativo las ventas del establecimiento
+		sold := max(establishments[code], 0)
+		total += sold
+		ats.VentasEstablecimiento.VentaEst = append(ats.VentasEstablecimiento.VentaEst, sri.VentaEst{
+			CodEstab:    code,
+			VentasEstab: fmt.Sprintf("%.2f", sold),
+			IvaComp:     "0.00",
+		})
+	}
+	ats.NumEstabRuc = fmt.Sprintf("%03d", len(codes))
+	ats.TotalVentas = fmt.Sprintf("%.2f", total)
+	return nil
+}
+
+// atsCustomerIDType traduce el tipo de identificación del comprador al del módulo de ventas;
+// las identificaciones del exterior se reportan como pasaporte.
+func atsCustomerIDType(idType string) string {
+	switch idType {
+	case "04", "05", "06", "07":
+		return idType
+	default:
+		return "06"
+	}
+}
+
+// atsSupplierIDType traduce el tipo de identificación del proveedor al del módulo de compras
+// (01 RUC, 02 Cédula, 03 Pasaporte). Sin tipo registrado se deduce por la longitud.
+func atsSupplierIDType(idType, identification string) string {
+	switch idType {
+	case "04":
+		return "01"
+	case "05":
+		return "02"
+	case "06", "08":
+		return "03"
+	}
+	switch len(identification) {
+	case 13:
+		return "01"
+	case 10:
+		return "02"
+	default:
+		return "03"
+	}
+}
+
+// atsSequential quita los ceros a la izquierda del secuencial, como lo exige el ATS.
+func atsSequential(seq string) string {
+	trimmed := strings.TrimLeft(seq, "0")
+	if trimmed == "" {
+		return "0"
+	}
+	return trimmed
+}
+
+// atsText deja solo letras sin tilde, dígitos y espacios en mayúsculas: el DIMM rechaza los nombres
+// con tildes, eñes o signos de puntuación.
+func atsText(s string) string {
+	replacer := strings.NewReplacer(
+		"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U", "Ñ", "N",
+		"á", "A", "é", "E", "í", "I", "ó", "O", "ú", "U", "ü", "U", "ñ", "N",
+	)
+	s = replacer.Replace(cleanText(s))
+
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			sb.WriteRune(r)
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			sb.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
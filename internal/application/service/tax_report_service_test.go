@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/application/service"
+	"github.com/nelsonmarro/verith/internal/application/service/mocks"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateATS(t *testing.T) {
+	ctx := context.Background()
+	issuer := &domain.Issuer{BaseEntity: domain.BaseEntity{ID: 1}, RUC: "1790012345001", BusinessName: "Compañía Andina Cía. Ltda.", EstablishmentCode: "001"}
+	day := func(d int) time.Time { return time.Date(2026, 2, d, 10, 0, 0, 0, time.Local) }
+	ownKey := func(codDoc, estab, seq string, d int) string {
+		return sri.GenerateAccessKey(day(d), codDoc, issuer.RUC, 2, estab, "001", seq, "12345678", 1)
+	}
+
+	sales := []domain.TaxSaleDocument{
+		{
+			AccessKey: ownKey("01", "001", "000000010", 2), ReceiptType: "01", SRIStatus: "AUTORIZADO", TransactionID: 1,
+			CustomerIdentification: "1712345678", CustomerIdentificationType: "05", CustomerName: "Juan Pérez",
+			Subtotal15: 100, TaxAmount: 15, Amount: 115, PaymentMethods: []string{"19"},
+		},
+		// Factura anulada en el sistema: se reporta junto con su nota de crédito
+		{
+			AccessKey: ownKey("01", "001", "000000011", 5), ReceiptType: "01", SRIStatus: "AUTORIZADO", TransactionID: 2,
+			CustomerIdentification: "1712345678", CustomerIdentificationType: "05", CustomerName: "Juan Pérez",
+			Subtotal15: 40, Subtotal0: 10, TaxAmount: 6, Amount: 56,
+		},
+		{
+			AccessKey: ownKey("04", "001", "000000003", 6), ReceiptType: "04", SRIStatus: "AUTORIZADO", TransactionID: 3,
+			CustomerIdentification: "1712345678", CustomerIdentificationType: "05", CustomerName: "Juan Pérez",
+			Subtotal15: 40, Subtotal0: 10, TaxAmount: 6, Amount: 56,
+		},
+		{
+			AccessKey: ownKey("01", "002", "000000001", 7), ReceiptType: "01", SRIStatus: "AUTORIZADO", TransactionID: 4,
+			CustomerIdentification: "9999999999999", CustomerIdentificationType: "07", CustomerName: "CONSUMIDOR FINAL",
+			Subtotal0: 20, Amount: 20,
+		},
+		{
+			AccessKey: ownKey("01", "001", "000000012", 8), ReceiptType: "01", SRIStatus: "ANULADO", TransactionID: 5,
+			CustomerIdentification: "1712345678", CustomerIdentificationType: "05", Subtotal15: 30, TaxAmount: 4.5, Amount: 34.5,
+		},
+	}
+
+	supplierInvoiceKey := sri.GenerateAccessKey(day(3), "01", "0991234567001", 2, "002", "001", "000000458", "87654321", 1)
+	withholdingKey := ownKey("07", "001", "000000021", 4)
+	cr := &sri.ComprobanteRetencion{}
+	cr.InfoCompRetencion.FechaEmision = "04/02/2026"
+	cr.DocsSustento.DocSustento = []sri.DocSustento{{
+		CodSustento: "01", CodDocSustento: "01", NumAutDocSustento: supplierInvoiceKey,
+	}}
+	cr.DocsSustento.DocSustento[0].Retenciones.Retencion = []sri.Retencion{
+		{Codigo: "1", CodigoRetencion: "312", BaseImponible: "800.00", PorcentajeRetener: "1.75", ValorRetenido: "14.00"},
+		{Codigo: "2", CodigoRetencion: "1", BaseImponible: "120.00", PorcentajeRetener: "30", ValorRetenido: "36.00"},
+	}
+	withholdingXML, err := sri.MarshalComprobanteRetencion(cr)
+	require.NoError(t, err)
+
+	purchases := []domain.TaxPurchaseDocument{
+		{
+			TransactionID: 20, TransactionDate: day(4), SupplierIdentification: "0991234567001", SupplierIdentificationType: "04",
+			SupplierName: "Distribuidora del Pacífico S.A.", AccessKey: supplierInvoiceKey,
+			Subtotal15: 800, TaxAmount: 120, Amount: 920,
+			WithholdingAccessKey: withholdingKey, WithholdingXML: string(withholdingXML),
+		},
+		// Liquidación de compra a una persona con pasaporte, sin desglose de impuestos
+		{
+			TransactionID: 21, TransactionDate: day(9), SupplierIdentification: "AB123456", SupplierIdentificationType: "06",
+			SupplierName: "María Núñez", AccessKey: ownKey("03", "001", "000000004", 9), Amount: 80,
+		},
+	}
+
+	newService := func() (*service.TaxReportService, *mocks.MockTaxReportRepository, *mocks.MockIssuerRepository) {
+		repo := new(mocks.MockTaxReportRepository)
+		issuerRepo := new(mocks.MockIssuerRepository)
+		return service.NewTaxReportService(repo, issuerRepo), repo, issuerRepo
+	}
+
+	t.Run("Builds and validates the monthly annex", func(t *testing.T) {
+		svc, repo, issuerRepo := newService()
+		issuerRepo.On("GetActive", ctx).Return(issuer, nil)
+		start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)
+		end := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+		repo.On("GetSaleDocuments", ctx, start, end).Return(sales, nil)
+		repo.On("GetPurchaseDocuments", ctx, start, end).Return(purchases, nil)
+
+		outputPath := filepath.Join(t.TempDir(), "ATS022026.xml")
+		require.NoError(t, svc.GenerateATS(ctx, 2026, time.February, outputPath))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		var ats sri.ATS
+		require.NoError(t, xml.Unmarshal(data, &ats))
+
+		assert.Equal(t, "COMPANIA ANDINA CIA LTDA", ats.RazonSocial)
+		assert.Equal(t, "2026", ats.Anio)
+		assert.Equal(t, "02", ats.Mes)
+		assert.Equal(t, "002", ats.NumEstabRuc)
+		assert.Equal(t, "120.00", ats.TotalVentas)
+
+		// Ventas agrupadas por cliente y tipo de comprobante; la anulada en el SRI va aparte
+		require.Len(t, ats.Ventas.DetalleVentas, 3)
+		invoices := ats.Ventas.DetalleVentas[0]
+		assert.Equal(t, "18", invoices.TipoComprobante)
+		assert.Equal(t, 2, invoices.NumeroComprobantes)
+		assert.Equal(t, "140.00", invoices.BaseImpGrav)
+		assert.Equal(t, "10.00", invoices.BaseImponible)
+		assert.Equal(t, "21.00", invoices.MontoIva)
+		assert.Equal(t, []string{"01", "19"}, invoices.FormasDePago.FormaPago)
+
+		creditNotes := ats.Ventas.DetalleVentas[1]
+		assert.Equal(t, "04", creditNotes.TipoComprobante)
+		assert.Equal(t, 1, creditNotes.NumeroComprobantes)
+		assert.Nil(t, creditNotes.FormasDePago)
+
+		finalConsumer := ats.Ventas.DetalleVentas[2]
+		assert.Equal(t, "07", finalConsumer.TpIdCliente)
+		assert.Empty(t, finalConsumer.ParteRelVtas)
+
+		assert.Equal(t, []sri.VentaEst{
+			{CodEstab: "001", VentasEstab: "100.00", IvaComp: "0.00"},
+			{CodEstab: "002", VentasEstab: "20.00", IvaComp: "0.00"},
+		}, ats.VentasEstablecimiento.VentaEst)
+
+		require.Len(t, ats.Anulados.DetalleAnulados, 1)
+		assert.Equal(t, "12", ats.Anulados.DetalleAnulados[0].SecuencialInicio)
+
+		// Compras con las retenciones tomadas del XML de la retención
+		require.Len(t, ats.Compras.DetalleCompras, 2)
+		invoice := ats.Compras.DetalleCompras[0]
+		assert.Equal(t, "01", invoice.TpIdProv)
+		assert.Equal(t, "458", invoice.Secuencial)
+		assert.Equal(t, "03/02/2026", invoice.FechaEmision)
+		assert.Equal(t, "04/02/2026", invoice.FechaRegistro)
+		assert.Equal(t, "800.00", invoice.BaseImpGrav)
+		assert.Equal(t, "36.00", invoice.ValorRetBienes)
+		assert.Equal(t, []sri.DetalleAir{{CodRetAir: "312", BaseImpAir: "800.00", PorcentajeAir: "1.75", ValRetAir: "14.00"}}, invoice.Air.DetalleAir)
+		assert.Equal(t, "21", invoice.SecRetencion1)
+		assert.Equal(t, []string{"20"}, invoice.FormasDePago.FormaPago)
+
+		settlement := ats.Compras.DetalleCompras[1]
+		assert.Equal(t, "03", settlement.TipoComprobante)
+		assert.Equal(t, "03", settlement.TpIdProv)
+		assert.Equal(t, "MARIA NUNEZ", settlement.DenoProv)
+		assert.Equal(t, "02", settlement.CodSustento)
+		assert.Equal(t, "80.00", settlement.BaseImponible)
+		assert.Nil(t, settlement.FormasDePago)
+		assert.Empty(t, settlement.AutRetencion1)
+	})
+
+	t.Run("Generates an empty annex for a month without movements", func(t *testing.T) {
+		svc, repo, issuerRepo := newService()
+		issuerRepo.On("GetActive", ctx).Return(issuer, nil)
+		repo.On("GetSaleDocuments", ctx, mock.Anything, mock.Anything).Return(nil, nil)
+		repo.On("GetPurchaseDocuments", ctx, mock.Anything, mock.Anything).Return(nil, nil)
+
+		data, err := svc.BuildATS(ctx, 2026, time.March)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "<totalVentas>0.00</totalVentas>")
+		assert.NotContains(t, string(data), "<compras>")
+	})
+
+	t.Run("Reports the ICE and adds it to the taxed base", func(t *testing.T) {
+		svc, repo, issuerRepo := newService()
+		issuerRepo.On("GetActive", ctx).Return(issuer, nil)
+		repo.On("GetSaleDocuments", ctx, mock.Anything, mock.Anything).Return([]domain.TaxSaleDocument{{
+			AccessKey: ownKey("01", "001", "000000013", 10), ReceiptType: "01", SRIStatus: "AUTORIZADO", TransactionID: 6,
+			CustomerIdentification: "1712345678", CustomerIdentificationType: "05", CustomerName: "Juan Pérez",
+			Subtotal15: 100, ICEAmount: 20, TaxAmount: 18, Amount: 138,
+		}}, nil)
+		repo.On("GetPurchaseDocuments", ctx, mock.Anything, mock.Anything).Return(nil, nil)
+
+		data, err := svc.BuildATS(ctx, 2026, time.February)
+		require.NoError(t, err)
+		var ats sri.ATS
+		require.NoError(t, xml.Unmarshal(data, &ats))
+
+		require.Len(t, ats.Ventas.DetalleVentas, 1)
+		assert.Equal(t, "120.00", ats.Ventas.DetalleVentas[0].BaseImpGrav)
+		assert.Equal(t, "20.00", ats.Ventas.DetalleVentas[0].MontoIce)
+		assert.Equal(t, "18.00", ats.Ventas.DetalleVentas[0].MontoIva)
+	})
+
+	t.Run("Reports the purchase ICE", func(t *testing.T) {
+		svc, repo, issuerRepo := newService()
+		issuerRepo.On("GetActive", ctx).Return(issuer, nil)
+		repo.On("GetSaleDocuments", ctx, mock.Anything, mock.Anything).Return(nil, nil)
+		repo.On("GetPurchaseDocuments", ctx, mock.Anything, mock.Anything).Return([]domain.TaxPurchaseDocument{{
+			TransactionID: 22, TransactionDate: day(11), SupplierIdentification: "0991234567001", SupplierIdentificationType: "04",
+			SupplierName: "Distribuidora del Pacífico S.A.", AccessKey: supplierInvoiceKey,
+			Subtotal15: 100, ICEAmount: 20, TaxAmount: 18, Amount: 138,
+		}}, nil)
+
+		data, err := svc.BuildATS(ctx, 2026, time.February)
+		require.NoError(t, err)
+		var ats sri.ATS
+		require.NoError(t, xml.Unmarshal(data, &ats))
+
+		require.Len(t, ats.Compras.DetalleCompras, 1)
+		assert.Equal(t, "120.00", ats.Compras.DetalleCompras[0].BaseImpGrav)
+		assert.Equal(t, "20.00", ats.Compras.DetalleCompras[0].MontoIce)
+		assert.Equal(t, "18.00", ats.Compras.DetalleCompras[0].MontoIva)
+	})
+
+	t.Run("Fails without an active issuer", func(t *testing.T) {
+		svc, _, issuerRepo := newService()
+		issuerRepo.On("GetActive", ctx).Return(nil, nil)
+
+		_, err := svc.BuildATS(ctx, 2026, time.February)
+		assert.ErrorContains(t, err, "emisor activo")
+	})
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"time"
@@ -24,17 +25,20 @@ type TransactionServiceImpl struct {
 	repo           TransactionRepository
 	storage        StorageService
 	accountService AccountService
+	taxRateRepo    TaxRateRepository
 }
 
 func NewTransactionService(
 	repo TransactionRepository,
 	storage StorageService,
 	accountService AccountService,
+	taxRateRepo TaxRateRepository,
 ) *TransactionServiceImpl {
 	return &TransactionServiceImpl{
 		repo:           repo,
 		storage:        storage,
 		accountService: accountService,
+		taxRateRepo:    taxRateRepo,
 	}
 }
 
@@ -101,7 +105,7 @@ func (s *TransactionServiceImpl) CreateTransaction(ctx context.Context, tx *doma
 	}
 	txValidator := validator.New().For(tx)
 	txValidator.Required("Amount", "Description", "TransactionDate", "AccountID", "CategoryID")
-	txValidator.NumberMin(0, "Amount")
+	txValidator.NumberMin(0, "Amount", "Tip")
 	txValidator.MaxDate(time.Now(), "TransactionDate")
 
 	err := txValidator.ConsolidateErrors()
@@ -111,12 +115,24 @@ func (s *TransactionServiceImpl) CreateTransaction(ctx context.Context, tx *doma
 
 	tx.CreatedByID = currentUser.ID
 	tx.UpdatedByID = currentUser.ID
+	// Si el formulario no eligió punto de emisión se usa el del usuario
+	tx.SetEmissionPoint(currentUser.DefaultEstablishmentCode, currentUser.DefaultEmissionPointCode)
 
 	// Fallback para desgloses vacíos (ej: transacciones antiguas o automáticas)
 	if tx.Amount > 0 && tx.Subtotal15 == 0 && tx.Subtotal0 == 0 {
 		tx.Subtotal0 = tx.Amount
 	}
 
+	if err := validateDiscounts(tx); err != nil {
+		return err
+	}
+	if err := validatePayments(tx.Payments, tx.Amount); err != nil {
+		return err
+	}
+	if err := domain.ValidateAdditionalFields(tx.AdditionalFields); err != nil {
+		return err
+	}
+
 	var sourcePath string
 	if tx.AttachmentPath != nil {
 		sourcePath = *tx.AttachmentPath
@@ -161,6 +177,97 @@ func (s *TransactionServiceImpl) VoidTransaction(ctx context.Context, transactio
 	return s.repo.VoidTransaction(ctx, transactionID, currentUser)
 }
 
+// PartialVoidTransaction registra la devolución de algunos ítems de una factura como un egreso
+// vinculado, listo para emitir su Nota de Crédito parcial. Devuelve el ID del egreso creado.
+func (s *TransactionServiceImpl) PartialVoidTransaction(
+	ctx context.Context,
+	originalID int,
+	returned []domain.ReturnedItem,
+	currentUser domain.User,
+) (int, error) {
+	if len(returned) == 0 {
+		return 0, fmt.Errorf("seleccione al menos un ítem a devolver")
+	}
+
+	original, err := s.repo.GetTransactionByID(ctx, originalID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener la transacción: %w", err)
+	}
+	if original.IsVoided {
+		return 0, fmt.Errorf("la transacción ya ha sido anulada")
+	}
+
+	items, err := s.repo.GetItemsByTransactionID(ctx, originalID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener los ítems de la factura: %w", err)
+	}
+	// El descuento de cada ítem (línea + parte del global) se devuelve en proporción a la cantidad
+	original.Items = items
+	discounts := original.ItemDiscounts()
+	byID := make(map[int]int, len(items))
+	for i, item := range items {
+		byID[item.ID] = i
+	}
+
+	reversal := &domain.Transaction{
+		Description: fmt.Sprintf("Devolución parcial de la transacción #%s", original.TransactionNumber),
+		CreatedByID: currentUser.ID,
+		UpdatedByID: currentUser.ID,
+	}
+	// La nota de crédito parcial sale del punto del usuario o, si no tiene, del de la factura
+	reversal.SetEmissionPoint(currentUser.DefaultEstablishmentCode, currentUser.DefaultEmissionPointCode)
+	for _, r := range returned {
+		idx, ok := byID[r.ItemID]
+		if !ok {
+			return 0, fmt.Errorf("el ítem %d no pertenece a la factura", r.ItemID)
+		}
+		item := items[idx]
+		if r.Quantity <= 0 || r.Quantity > item.Quantity {
+			return 0, fmt.Errorf("cantidad inválida para %q: máximo %g", item.Description, item.Quantity)
+		}
+
+		returned := domain.TransactionItem{
+			Description:    item.Description,
+			Quantity:       r.Quantity,
+			UnitPrice:      item.UnitPrice,
+			TaxRate:        item.TaxRate,
+			Discount:       math.Round(discounts[idx]*r.Quantity/item.Quantity*100) / 100,
+			ReturnedItemID: &items[idx].ID,
+		}
+		returned.Subtotal = returned.GrossAmount() - returned.Discount
+		// El ICE y el IRBPNR se devuelven con la misma tarifa; RecalculateTotals los recalcula
+		for _, tax := range item.Taxes {
+			returned.Taxes = append(returned.Taxes, domain.ItemTax{
+				TaxCode:        tax.TaxCode,
+				PercentageCode: tax.PercentageCode,
+				Rate:           tax.Rate,
+				Specific:       tax.Specific,
+			})
+		}
+		reversal.Items = append(reversal.Items, returned)
+	}
+	// El IVA devuelto es el que se cobró: se calcula con las tarifas vigentes a la fecha de la factura.
+	// El repositorio registra la devolución con la fecha actual.
+	rates, err := loadTaxRates(ctx, s.taxRateRepo)
+	if err != nil {
+		return 0, err
+	}
+	reversal.TransactionDate = original.TransactionDate
+	if err := reversal.RecalculateTotals(rates); err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.PartialVoidTransaction(ctx, originalID, reversal); err != nil {
+		return 0, err
+	}
+	return reversal.ID, nil
+}
+
+// GetCreditedAmount devuelve el total ya acreditado por devoluciones parciales de una factura.
+func (s *TransactionServiceImpl) GetCreditedAmount(ctx context.Context, transactionID int) (float64, error) {
+	return s.repo.GetCreditedAmount(ctx, transactionID)
+}
+
 func (s *TransactionServiceImpl) RevertVoidTransaction(ctx context.Context, voidTransactionID int) error {
 	return s.repo.RevertVoidTransaction(ctx, voidTransactionID)
 }
@@ -249,3 +356,51 @@ func (s *TransactionServiceImpl) ReconcileAccount(
 
 	return reconciliation, nil
 }
+
+// validatePayments comprueba que el desglose de formas de pago use códigos del SRI y sume
+// exactamente el total de la factura. Un desglose vacío es válido (se emite 01 por el total).
+func validatePayments(payments []domain.TransactionPayment, total float64) error {
+	if len(payments) == 0 {
+		return nil
+	}
+
+	var sum float64
+	for _, p := range payments {
+		if !domain.IsValidPaymentMethod(p.PaymentMethod) {
+			return fmt.Errorf("forma de pago %q no válida", p.PaymentMethod)
+		}
+		if p.Amount <= 0 {
+			return fmt.Errorf("el valor de cada forma de pago debe ser mayor a cero")
+		}
+		if p.Term < 0 {
+			return fmt.Errorf("el plazo no puede ser negativo")
+		}
+		if p.Term > 0 && p.TimeUnit == "" {
+			return fmt.Errorf("indique la unidad de tiempo del plazo")
+		}
+		sum += p.Amount
+	}
+
+	if math.Abs(math.Round(sum*100)-math.Round(total*100)) >= 1 {
+		return fmt.Errorf("las formas de pago suman $%.2f y el total es $%.2f", sum, total)
+	}
+	return nil
+}
+
+// validateDiscounts impide descuentos negativos o mayores al valor sobre el que se aplican.
+func validateDiscounts(tx *domain.Transaction) error {
+	var base float64
+	for _, item := range tx.Items {
+		if item.Discount < 0 || item.Discount > item.GrossAmount() {
+			return fmt.Errorf("el descuento de %q no es válido", item.Description)
+		}
+		base += item.Subtotal
+	}
+	if tx.Discount < 0 {
+		return fmt.Errorf("el descuento global no puede ser negativo")
+	}
+	if tx.Discount > 0 && tx.Discount > math.Round(base*100)/100 {
+		return fmt.Errorf("el descuento global ($%.2f) supera el subtotal de los ítems ($%.2f)", tx.Discount, base)
+	}
+	return nil
+}
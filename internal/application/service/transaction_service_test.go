@@ -20,7 +20,7 @@ func TestCreateTransaction(t *testing.T) {
 	// AccountService is not used in CreateTransaction logic currently
 	mockAccService := new(mocks.MockAccountService)
 
-	svc := service.NewTransactionService(mockTxRepo, mockStorage, mockAccService)
+	svc := service.NewTransactionService(mockTxRepo, mockStorage, mockAccService, nil)
 	ctx := context.Background()
 	user := domain.User{BaseEntity: domain.BaseEntity{ID: 1}}
 
@@ -81,9 +81,71 @@ func TestCreateTransaction(t *testing.T) {
 	})
 }
 
+func TestCreateTransaction_Payments(t *testing.T) {
+	mockTxRepo := new(mocks.MockTransactionRepository)
+	svc := service.NewTransactionService(mockTxRepo, nil, nil, nil)
+	ctx := context.Background()
+	user := domain.User{BaseEntity: domain.BaseEntity{ID: 1}}
+
+	newTx := func(payments ...domain.TransactionPayment) *domain.Transaction {
+		return &domain.Transaction{
+			AccountID:       1,
+			CategoryID:      2,
+			Amount:          115.0,
+			Subtotal15:      100.0,
+			TaxAmount:       15.0,
+			TransactionDate: time.Now(),
+			Description:     "Venta",
+			Payments:        payments,
+		}
+	}
+
+	t.Run("Success - Breakdown Matches Total", func(t *testing.T) {
+		tx := newTx(
+			domain.TransactionPayment{PaymentMethod: "19", Amount: 100},
+			domain.TransactionPayment{PaymentMethod: "01", Amount: 15},
+		)
+		mockTxRepo.On("CreateTransaction", ctx, tx).Return(nil).Once()
+
+		assert.NoError(t, svc.CreateTransaction(ctx, tx, user))
+		mockTxRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Breakdown Does Not Match Total", func(t *testing.T) {
+		tx := newTx(domain.TransactionPayment{PaymentMethod: "19", Amount: 100})
+
+		err := svc.CreateTransaction(ctx, tx, user)
+		assert.ErrorContains(t, err, "el total es $115.00")
+		mockTxRepo.AssertNotCalled(t, "CreateTransaction", ctx, tx)
+	})
+
+	t.Run("Fail - Unknown Payment Method", func(t *testing.T) {
+		tx := newTx(domain.TransactionPayment{PaymentMethod: "99", Amount: 115})
+
+		err := svc.CreateTransaction(ctx, tx, user)
+		assert.ErrorContains(t, err, "forma de pago")
+	})
+
+	t.Run("Fail - Global Discount Exceeds Items", func(t *testing.T) {
+		tx := newTx()
+		tx.Items = []domain.TransactionItem{{Description: "Silla", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 4}}
+		tx.Discount = 150
+
+		err := svc.CreateTransaction(ctx, tx, user)
+		assert.ErrorContains(t, err, "descuento global")
+	})
+
+	t.Run("Fail - Term Without Time Unit", func(t *testing.T) {
+		tx := newTx(domain.TransactionPayment{PaymentMethod: "20", Amount: 115, Term: 30})
+
+		err := svc.CreateTransaction(ctx, tx, user)
+		assert.ErrorContains(t, err, "unidad de tiempo")
+	})
+}
+
 func TestVoidTransaction(t *testing.T) {
 	mockTxRepo := new(mocks.MockTransactionRepository)
-	svc := service.NewTransactionService(mockTxRepo, nil, nil)
+	svc := service.NewTransactionService(mockTxRepo, nil, nil, nil)
 	ctx := context.Background()
 	user := domain.User{BaseEntity: domain.BaseEntity{ID: 1}}
 
@@ -115,9 +177,79 @@ func TestVoidTransaction(t *testing.T) {
 	})
 }
 
+func TestPartialVoidTransaction(t *testing.T) {
+	mockTxRepo := new(mocks.MockTransactionRepository)
+	svc := service.NewTransactionService(mockTxRepo, nil, nil, newTestTaxRateRepo())
+	ctx := context.Background()
+	user := domain.User{BaseEntity: domain.BaseEntity{ID: 1}}
+
+	invoiceDate := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	original := &domain.Transaction{BaseEntity: domain.BaseEntity{ID: 20}, TransactionNumber: "TX-20", Amount: 230, TransactionDate: invoiceDate}
+	items := []domain.TransactionItem{
+		{BaseEntity: domain.BaseEntity{ID: 1}, Description: "Silla", Quantity: 2, UnitPrice: 100, Subtotal: 200, TaxRate: 4},
+		{BaseEntity: domain.BaseEntity{ID: 2}, Description: "Servicio", Quantity: 1, UnitPrice: 30, Subtotal: 30, TaxRate: 0},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockTxRepo.On("GetTransactionByID", ctx, 20).Return(original, nil).Once()
+		mockTxRepo.On("GetItemsByTransactionID", ctx, 20).Return(items, nil).Once()
+		mockTxRepo.On("PartialVoidTransaction", ctx, 20, mock.MatchedBy(func(r *domain.Transaction) bool {
+			return len(r.Items) == 1 && r.Subtotal15 == 100 && r.TaxAmount == 15 && r.Amount == 115 &&
+				r.Items[0].ReturnedItemID != nil && *r.Items[0].ReturnedItemID == 1
+		})).Run(func(args mock.Arguments) {
+			args.Get(2).(*domain.Transaction).ID = 21
+		}).Return(nil).Once()
+
+		id, err := svc.PartialVoidTransaction(ctx, 20, []domain.ReturnedItem{{ItemID: 1, Quantity: 1}}, user)
+		assert.NoError(t, err)
+		assert.Equal(t, 21, id)
+		mockTxRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Returns Proportional Discount", func(t *testing.T) {
+		discounted := &domain.Transaction{BaseEntity: domain.BaseEntity{ID: 30}, TransactionNumber: "TX-30", Discount: 20, TransactionDate: invoiceDate}
+		mockTxRepo.On("GetTransactionByID", ctx, 30).Return(discounted, nil).Once()
+		mockTxRepo.On("GetItemsByTransactionID", ctx, 30).Return([]domain.TransactionItem{
+			{BaseEntity: domain.BaseEntity{ID: 5}, Description: "Silla", Quantity: 4, UnitPrice: 50, Subtotal: 200, TaxRate: 4},
+		}, nil).Once()
+		// 1 de 4 sillas: 50 - 5 (un cuarto del descuento global) = 45 de base
+		mockTxRepo.On("PartialVoidTransaction", ctx, 30, mock.MatchedBy(func(r *domain.Transaction) bool {
+			return r.Items[0].Discount == 5 && r.Subtotal15 == 45 && r.TaxAmount == 6.75 && r.Amount == 51.75
+		})).Return(nil).Once()
+
+		_, err := svc.PartialVoidTransaction(ctx, 30, []domain.ReturnedItem{{ItemID: 5, Quantity: 1}}, user)
+		assert.NoError(t, err)
+		mockTxRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Returns the Rate in Force on the Invoice Date", func(t *testing.T) {
+		// Factura de 2023 con la tarifa general de entonces (código 2, 12%)
+		historic := &domain.Transaction{BaseEntity: domain.BaseEntity{ID: 40}, TransactionNumber: "TX-40", TransactionDate: time.Date(2023, 8, 1, 0, 0, 0, 0, time.Local)}
+		mockTxRepo.On("GetTransactionByID", ctx, 40).Return(historic, nil).Once()
+		mockTxRepo.On("GetItemsByTransactionID", ctx, 40).Return([]domain.TransactionItem{
+			{BaseEntity: domain.BaseEntity{ID: 7}, Description: "Mesa", Quantity: 1, UnitPrice: 100, Subtotal: 100, TaxRate: 2},
+		}, nil).Once()
+		mockTxRepo.On("PartialVoidTransaction", ctx, 40, mock.MatchedBy(func(r *domain.Transaction) bool {
+			return r.Subtotal15 == 100 && r.TaxAmount == 12 && r.Amount == 112
+		})).Return(nil).Once()
+
+		_, err := svc.PartialVoidTransaction(ctx, 40, []domain.ReturnedItem{{ItemID: 7, Quantity: 1}}, user)
+		assert.NoError(t, err)
+		mockTxRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Quantity Exceeds Sold", func(t *testing.T) {
+		mockTxRepo.On("GetTransactionByID", ctx, 20).Return(original, nil).Once()
+		mockTxRepo.On("GetItemsByTransactionID", ctx, 20).Return(items, nil).Once()
+
+		_, err := svc.PartialVoidTransaction(ctx, 20, []domain.ReturnedItem{{ItemID: 2, Quantity: 3}}, user)
+		assert.ErrorContains(t, err, "cantidad inválida")
+	})
+}
+
 func TestReconcileAccount(t *testing.T) {
 	mockTxRepo := new(mocks.MockTransactionRepository)
-	svc := service.NewTransactionService(mockTxRepo, nil, nil)
+	svc := service.NewTransactionService(mockTxRepo, nil, nil, nil)
 	ctx := context.Background()
 
 	t.Run("Match", func(t *testing.T) {
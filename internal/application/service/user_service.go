@@ -98,6 +98,34 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, id int, username, pass
 	return s.repo.UpdateUser(ctx, user)
 }
 
+// SetDefaultEmissionPoint sets the emission point proposed on the user's invoices and credit notes.
+// Empty codes clear it, so the issuer's point is used.
+func (s *UserServiceImpl) SetDefaultEmissionPoint(ctx context.Context, id int, establishmentCode, emissionPointCode string, currentUser *domain.User) error {
+	if !currentUser.CanManageUsers() && currentUser.ID != id {
+		return fmt.Errorf("unauthorized: insufficient permissions")
+	}
+
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if establishmentCode == "" && emissionPointCode == "" {
+		user.DefaultEstablishmentCode = nil
+		user.DefaultEmissionPointCode = nil
+		return s.repo.UpdateUser(ctx, user)
+	}
+	if err := domain.ValidateEmissionCode("establecimiento", establishmentCode); err != nil {
+		return err
+	}
+	if err := domain.ValidateEmissionCode("punto de emisión", emissionPointCode); err != nil {
+		return err
+	}
+	user.DefaultEstablishmentCode = &establishmentCode
+	user.DefaultEmissionPointCode = &emissionPointCode
+	return s.repo.UpdateUser(ctx, user)
+}
+
 // DeleteUser deletes a user.
 func (s *UserServiceImpl) DeleteUser(ctx context.Context, id int, currentUser *domain.User) error {
 	if !currentUser.CanManageUsers() {
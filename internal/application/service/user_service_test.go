@@ -93,3 +93,45 @@ func TestUserService_ResetPassword(t *testing.T) {
 		assert.NoError(t, err, "Password hash should be valid for the input password")
 	})
 }
+
+func TestUserService_SetDefaultEmissionPoint(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := NewUserService(mockRepo)
+	ctx := context.Background()
+	admin := &domain.User{BaseEntity: domain.BaseEntity{ID: 1}, Role: domain.RoleAdmin}
+
+	t.Run("Assigns the point", func(t *testing.T) {
+		mockRepo.On("GetUserByID", ctx, 5).Return(&domain.User{BaseEntity: domain.BaseEntity{ID: 5}}, nil).Once()
+		mockRepo.On("UpdateUser", ctx, mock.MatchedBy(func(u *domain.User) bool {
+			return u.DefaultEstablishmentCode != nil && *u.DefaultEstablishmentCode == "002" &&
+				u.DefaultEmissionPointCode != nil && *u.DefaultEmissionPointCode == "003"
+		})).Return(nil).Once()
+
+		assert.NoError(t, service.SetDefaultEmissionPoint(ctx, 5, "002", "003", admin))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty codes clear it", func(t *testing.T) {
+		estab, point := "002", "003"
+		mockRepo.On("GetUserByID", ctx, 5).Return(&domain.User{BaseEntity: domain.BaseEntity{ID: 5}, DefaultEstablishmentCode: &estab, DefaultEmissionPointCode: &point}, nil).Once()
+		mockRepo.On("UpdateUser", ctx, mock.MatchedBy(func(u *domain.User) bool {
+			return u.DefaultEstablishmentCode == nil && u.DefaultEmissionPointCode == nil
+		})).Return(nil).Once()
+
+		assert.NoError(t, service.SetDefaultEmissionPoint(ctx, 5, "", "", admin))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		mockRepo.On("GetUserByID", ctx, 5).Return(&domain.User{BaseEntity: domain.BaseEntity{ID: 5}}, nil).Once()
+
+		assert.Error(t, service.SetDefaultEmissionPoint(ctx, 5, "2", "003", admin))
+	})
+
+	t.Run("Another user's point requires permissions", func(t *testing.T) {
+		seller := &domain.User{BaseEntity: domain.BaseEntity{ID: 7}, Role: domain.RoleCashier}
+
+		err := service.SetDefaultEmissionPoint(ctx, 5, "002", "003", seller)
+		assert.Error(t, err)
+	})
+}
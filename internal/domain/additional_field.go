@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxAdditionalFields es el máximo de campoAdicional que admite el XSD del SRI en infoAdicional.
+	MaxAdditionalFields = 15
+	// MaxIssuerAdditionalFields deja lugar a los datos de contacto del cliente (dirección, teléfono y email).
+	MaxIssuerAdditionalFields = MaxAdditionalFields - 3
+	// MaxAdditionalFieldLength es el largo máximo de nombre y valor de un campoAdicional según el XSD.
+	MaxAdditionalFieldLength = 300
+)
+
+// AdditionalField es un par nombre/valor que se imprime en la sección infoAdicional del comprobante.
+// El emisor define campos por defecto y cada transacción puede agregar los suyos.
+type AdditionalField struct {
+	Name  string `db:"name"`
+	Value string `db:"value"`
+}
+
+// ValidateAdditionalFields verifica que cada campo tenga nombre y valor dentro del largo permitido.
+func ValidateAdditionalFields(fields []AdditionalField) error {
+	for _, f := range fields {
+		name := strings.TrimSpace(f.Name)
+		if name == "" || strings.TrimSpace(f.Value) == "" {
+			return fmt.Errorf("los campos adicionales requieren nombre y valor")
+		}
+		if utf8.RuneCountInString(name) > MaxAdditionalFieldLength || utf8.RuneCountInString(f.Value) > MaxAdditionalFieldLength {
+			return fmt.Errorf("el campo adicional %q supera los %d caracteres", name, MaxAdditionalFieldLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrNoCompanySelected se devuelve cuando se consultan datos sin haber elegido una empresa.
+var ErrNoCompanySelected = errors.New("no se ha seleccionado una empresa")
+
+// Company agrupa los libros de un negocio: su emisor, cuentas, categorías, transacciones y clientes.
+// Una instalación puede llevar varias empresas; los usuarios son comunes a todas.
+type Company struct {
+	BaseEntity
+	Name     string `db:"name"`
+	IsActive bool   `db:"is_active"`
+}
+
+// Validate revisa los datos de la empresa.
+func (c Company) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("ingrese el nombre de la empresa")
+	}
+	return nil
+}
+
+// CompanyScope es la empresa con la que trabaja la sesión. Los repositorios la consultan en cada
+// operación para que los datos de una empresa nunca se mezclen con los de otra.
+type CompanyScope struct {
+	mu sync.RWMutex
+	id int
+}
+
+// NewCompanyScope crea el alcance con la empresa indicada; 0 deja la sesión sin empresa.
+func NewCompanyScope(companyID int) *CompanyScope {
+	return &CompanyScope{id: companyID}
+}
+
+// Select cambia la empresa de la sesión.
+func (s *CompanyScope) Select(companyID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id = companyID
+}
+
+// ID devuelve la empresa de la sesión, o ErrNoCompanySelected si aún no se eligió una.
+func (s *CompanyScope) ID() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.id == 0 {
+		return 0, ErrNoCompanySelected
+	}
+	return s.id, nil
+}
@@ -1,21 +1,105 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ReceiptStatus es el estado de un comprobante electrónico en su ciclo con el SRI.
+type ReceiptStatus string
+
+const (
+	ReceiptPending      ReceiptStatus = "PENDIENTE"   // Firmado, todavía sin enviar
+	ReceiptSendError    ReceiptStatus = "ERROR_ENVIO" // El SRI rechazó la petición (SOAP Fault)
+	ReceiptNetworkError ReceiptStatus = "ERROR_RED"   // El SRI no respondió
+	ReceiptReceived     ReceiptStatus = "RECIBIDA"
+	ReceiptReturned     ReceiptStatus = "DEVUELTA" // Rechazado en recepción; se corrige y se re-emite
+	ReceiptProcessing   ReceiptStatus = "EN PROCESO"
+	ReceiptAuthorized   ReceiptStatus = "AUTORIZADO"
+	ReceiptRejected     ReceiptStatus = "RECHAZADA" // NO AUTORIZADO en la autorización
+)
+
+// ErrInvalidReceiptTransition se devuelve al intentar un cambio de estado que el ciclo no permite.
+var ErrInvalidReceiptTransition = errors.New("cambio de estado del comprobante no permitido")
+
+// receiptTransitions son los estados a los que puede pasar cada estado. Repetir el estado actual
+// siempre se permite: registra un nuevo intento o una nueva respuesta del SRI.
+var receiptTransitions = map[ReceiptStatus][]ReceiptStatus{
+	ReceiptPending:      {ReceiptReceived, ReceiptReturned, ReceiptProcessing, ReceiptSendError, ReceiptNetworkError},
+	ReceiptSendError:    {ReceiptPending, ReceiptReceived, ReceiptReturned, ReceiptProcessing, ReceiptNetworkError},
+	ReceiptNetworkError: {ReceiptPending, ReceiptReceived, ReceiptReturned, ReceiptProcessing, ReceiptSendError},
+	ReceiptReceived:     {ReceiptProcessing, ReceiptAuthorized, ReceiptRejected},
+	ReceiptProcessing:   {ReceiptAuthorized, ReceiptRejected, ReceiptPending}, // Pending: re-emisión de uno trabado
+	ReceiptReturned:     {ReceiptPending},
+	ReceiptRejected:     {ReceiptPending},
+	ReceiptAuthorized:   {},
+}
+
+// Valid indica si el estado es uno de los del ciclo.
+func (s ReceiptStatus) Valid() bool {
+	_, ok := receiptTransitions[s]
+	return ok
+}
+
+// CanTransitionTo indica si el comprobante puede pasar de s a next. Un comprobante nuevo (s vacío)
+// solo puede nacer PENDIENTE.
+func (s ReceiptStatus) CanTransitionTo(next ReceiptStatus) bool {
+	if s == "" {
+		return next == ReceiptPending
+	}
+	if s == next {
+		return s.Valid()
+	}
+	return slices.Contains(receiptTransitions[s], next)
+}
+
+// ValidateTransition devuelve ErrInvalidReceiptTransition si el cambio de from a to no está permitido.
+func ValidateTransition(from, to ReceiptStatus) error {
+	if !from.CanTransitionTo(to) {
+		if from == "" {
+			return fmt.Errorf("%w: un comprobante nuevo no puede quedar %s", ErrInvalidReceiptTransition, to)
+		}
+		return fmt.Errorf("%w: de %s a %s", ErrInvalidReceiptTransition, from, to)
+	}
+	return nil
+}
+
+// IsFinal indica si el SRI ya emitió su decisión sobre el comprobante.
+func (s ReceiptStatus) IsFinal() bool {
+	return s == ReceiptAuthorized || s == ReceiptRejected
+}
+
+// ReceiptStatusFromSRI traduce el estado de una autorización del SRI al estado del comprobante.
+func ReceiptStatusFromSRI(estado string) (ReceiptStatus, bool) {
+	switch estado {
+	case "AUTORIZADO":
+		return ReceiptAuthorized, true
+	case "NO AUTORIZADO", "RECHAZADA", "RECHAZADO":
+		return ReceiptRejected, true
+	case "EN PROCESO":
+		return ReceiptProcessing, true
+	}
+	return "", false
+}
 
 type ElectronicReceipt struct {
 	BaseEntity
-	TransactionID     int        `db:"transaction_id"`
-	IssuerID          int        `db:"issuer_id"`
-	TaxPayerID        int        `db:"tax_payer_id"`
-	AccessKey         string     `db:"access_key"`
-	ReceiptType       string     `db:"receipt_type"`
-	XMLContent        string     `db:"xml_content"`
-	AuthorizationDate *time.Time `db:"authorization_date"`
-	SRIStatus         string     `db:"sri_status"`
-	SRIMessage        string     `db:"sri_message"`
-	RidePath          string     `db:"ride_path"`
-	Environment       int        `db:"environment"`
-	EmailSent         bool       `db:"email_sent"`
+	TransactionID     int           `db:"transaction_id"` // 0 cuando el comprobante no es de una transacción (Guía de Remisión)
+	ShipmentID        *int          `db:"shipment_id"`
+	IssuerID          int           `db:"issuer_id"`
+	TaxPayerID        int           `db:"tax_payer_id"`
+	AccessKey         string        `db:"access_key"`
+	ReceiptType       string        `db:"receipt_type"`
+	XMLContent        string        `db:"xml_content"`
+	AuthorizationDate *time.Time    `db:"authorization_date"`
+	SRIStatus         ReceiptStatus `db:"sri_status"`
+	SRIMessage        string        `db:"sri_message"`
+	RidePath          string        `db:"ride_path"`
+	Environment       int           `db:"environment"`
+	EmailSent         bool          `db:"email_sent"`
 
 	// Campos enriquecidos para UI (Joins)
 	ClientName        string  `db:"-"`
@@ -26,3 +110,49 @@ type ElectronicReceipt struct {
 	Issuer   *Issuer   `db:"-"`
 	TaxPayer *TaxPayer `db:"-"`
 }
+
+// receiptStuckAfter es el tiempo tras el cual un comprobante EN PROCESO se considera trabado.
+const receiptStuckAfter = 2 * time.Hour
+
+// IsStuck indica si el SRI dejó el comprobante EN PROCESO por más de dos horas.
+func (r *ElectronicReceipt) IsStuck(now time.Time) bool {
+	return r.SRIStatus == ReceiptProcessing && r.CreatedAt.Add(receiptStuckAfter).Before(now)
+}
+
+// CanReissue indica si el comprobante falló definitivamente (DEVUELTA o RECHAZADA) o quedó trabado,
+// y por lo tanto puede reemplazarse por uno nuevo.
+func (r *ElectronicReceipt) CanReissue(now time.Time) bool {
+	return r.SRIStatus == ReceiptReturned || r.SRIStatus == ReceiptRejected || r.IsStuck(now)
+}
+
+// ReceiptEvent es un paso en la historia de un comprobante: su creación, cada envío al SRI con la
+// respuesta obtenida y cada re-emisión.
+type ReceiptEvent struct {
+	ID         int           `db:"id"`
+	ReceiptID  int           `db:"receipt_id"`
+	AccessKey  string        `db:"access_key"`
+	FromStatus ReceiptStatus `db:"from_status"` // Vacío en la creación
+	ToStatus   ReceiptStatus `db:"to_status"`
+	Message    string        `db:"message"`
+	UserID     *int          `db:"user_id"` // nil cuando lo originó la sincronización automática
+	CreatedAt  time.Time     `db:"created_at"`
+
+	UserName string `db:"-"`
+}
+
+type userContextKey struct{}
+
+// ContextWithUser marca el contexto con el usuario que origina la operación, para registrarlo en
+// la historia de los comprobantes.
+func ContextWithUser(ctx context.Context, user *User) context.Context {
+	if user == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext devuelve el usuario marcado con ContextWithUser, o nil.
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userContextKey{}).(*User)
+	return user
+}
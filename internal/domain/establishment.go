@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var emissionCodePattern = regexp.MustCompile(`^\d{3}$`)
+
+// Establishment es un local adicional del emisor con su propio código y dirección, que se imprime
+// como dirEstablecimiento en los comprobantes emitidos desde sus puntos. El establecimiento principal
+// se configura en los datos del emisor.
+type Establishment struct {
+	BaseEntity
+	IssuerID int    `db:"issuer_id"`
+	Code     string `db:"code"`
+	Name     string `db:"name"`
+	Address  string `db:"address"`
+	IsActive bool   `db:"is_active"`
+}
+
+// Validate revisa el código y la dirección del establecimiento.
+func (e Establishment) Validate() error {
+	if err := ValidateEmissionCode("establecimiento", e.Code); err != nil {
+		return err
+	}
+	if strings.TrimSpace(e.Name) == "" {
+		return errors.New("ingrese el nombre del establecimiento")
+	}
+	if strings.TrimSpace(e.Address) == "" {
+		return errors.New("ingrese la dirección del establecimiento")
+	}
+	return nil
+}
+
+// ValidateEmissionCode revisa un código de establecimiento o de punto de emisión: tres dígitos
+// distintos de 000.
+func ValidateEmissionCode(field, code string) error {
+	if !emissionCodePattern.MatchString(code) || code == "000" {
+		return fmt.Errorf("el código de %s debe tener 3 dígitos (001 a 999)", field)
+	}
+	return nil
+}
+
+// EmissionPointLabel es la forma corta de un punto de emisión en la interfaz (ej. 001-002).
+func EmissionPointLabel(establishmentCode, emissionPointCode string) string {
+	return establishmentCode + "-" + emissionPointCode
+}
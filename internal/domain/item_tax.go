@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Códigos de impuesto del SRI (tabla 16) que se declaran por ítem además del IVA.
+const (
+	ItemTaxICE    = 3 // Impuesto a los Consumos Especiales
+	ItemTaxIRBPNR = 5 // Impuesto Redimible a las Botellas Plásticas No Retornables
+)
+
+// IRBPNRRate es la tarifa del IRBPNR: dos centavos por botella.
+const IRBPNRRate = 0.02
+
+var percentageCodePattern = regexp.MustCompile(`^\d{4}$`)
+
+// ItemTax es un impuesto distinto del IVA aplicado a un ítem (ICE o IRBPNR). El ICE forma parte de
+// la base imponible del IVA; el IRBPNR no.
+type ItemTax struct {
+	BaseEntity
+	TransactionItemID int     `db:"transaction_item_id"`
+	TaxCode           int     `db:"tax_code"`        // 3 = ICE, 5 = IRBPNR
+	PercentageCode    string  `db:"percentage_code"` // codigoPorcentaje del SRI (ej. 3011 cigarrillos, 5001 botellas)
+	Rate              float64 `db:"rate"`            // Porcentaje ad valorem, o valor por unidad si Specific
+	Specific          bool    `db:"is_specific"`     // Tarifa específica por unidad en vez de porcentaje
+	Base              float64 `db:"base"`            // Valor neto del ítem, o número de unidades si Specific
+	Amount            float64 `db:"amount"`
+}
+
+// Name es el nombre corto del impuesto para la interfaz y los reportes.
+func (t ItemTax) Name() string {
+	switch t.TaxCode {
+	case ItemTaxICE:
+		return "ICE"
+	case ItemTaxIRBPNR:
+		return "IRBPNR"
+	}
+	return fmt.Sprintf("Impuesto %d", t.TaxCode)
+}
+
+// Validate revisa el código del impuesto, su código de porcentaje y la tarifa.
+func (t ItemTax) Validate() error {
+	switch t.TaxCode {
+	case ItemTaxICE:
+	case ItemTaxIRBPNR:
+		if !t.Specific {
+			return errors.New("el IRBPNR se calcula por botella")
+		}
+	default:
+		return fmt.Errorf("el impuesto con código %d no está soportado", t.TaxCode)
+	}
+	if !percentageCodePattern.MatchString(t.PercentageCode) {
+		return fmt.Errorf("%s: el código de porcentaje debe tener 4 dígitos", t.Name())
+	}
+	if t.Rate <= 0 {
+		return fmt.Errorf("%s: la tarifa debe ser mayor a cero", t.Name())
+	}
+	return nil
+}
+
+// Compute devuelve el impuesto calculado sobre el valor neto del ítem (ad valorem) o sobre su
+// cantidad (tarifa específica).
+func (t ItemTax) Compute(quantity, net float64) ItemTax {
+	if t.Specific {
+		t.Base = quantity
+		t.Amount = roundCents(quantity * t.Rate)
+	} else {
+		t.Base = roundCents(net)
+		t.Amount = roundCents(net * t.Rate / 100)
+	}
+	return t
+}
@@ -0,0 +1,10 @@
+package domain
+
+// PurchaseSettlement agrupa los datos para emitir una Liquidación de Compra (03)
+// cuando se compra a una persona que no puede emitir facturas. Igual que la
+// retención, el registro legal es el XML firmado en electronic_receipts.
+type PurchaseSettlement struct {
+	TransactionID int    // Egreso que registra la compra
+	SupplierID    int    // TaxPayer del proveedor (persona sin RUC)
+	PaymentMethod string // formaPago del SRI; vacío = 01 sin utilización del sistema financiero
+}
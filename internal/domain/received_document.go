@@ -0,0 +1,21 @@
+package domain
+
+// ReceivedDocumentStatus es el resultado de descargar e importar un comprobante recibido.
+type ReceivedDocumentStatus string
+
+const (
+	ReceivedNew             ReceivedDocumentStatus = "NUEVO"
+	ReceivedAlreadyImported ReceivedDocumentStatus = "YA IMPORTADO"
+	ReceivedSkipped         ReceivedDocumentStatus = "OMITIDO" // Comprobantes que no son facturas (retenciones, notas de crédito...)
+	ReceivedFailed          ReceivedDocumentStatus = "FALLIDO"
+)
+
+// ReceivedDocumentResult es una línea del informe de la descarga masiva de comprobantes recibidos.
+type ReceivedDocumentResult struct {
+	AccessKey      string
+	DocumentNumber string
+	SupplierName   string
+	Status         ReceivedDocumentStatus
+	Message        string
+	TransactionID  int // Egreso creado cuando el estado es NUEVO
+}
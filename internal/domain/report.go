@@ -22,6 +22,7 @@ type FinancialSummary struct {
 	TotalIncome        decimal.Decimal
 	TotalExpenses      decimal.Decimal
 	NetProfitLoss      decimal.Decimal
+	TotalTips          decimal.Decimal // Propinas cobradas en ventas vigentes (incluidas en TotalIncome)
 	IncomeByCategory   []CategoryAmount
 	ExpensesByCategory []CategoryAmount
 }
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// Shipment es un traslado de mercadería que se respalda con una Guía de Remisión (06).
+// No mueve dinero: su comprobante electrónico se vincula al envío y no a una transacción.
+type Shipment struct {
+	BaseEntity
+	TransactionID    *int      `db:"transaction_id"` // Factura de origen (opcional)
+	CarrierID        int       `db:"carrier_id"`     // TaxPayer del transportista
+	Plate            string    `db:"plate"`
+	DepartureAddress string    `db:"departure_address"` // dirPartida
+	StartDate        time.Time `db:"start_date"`        // fechaIniTransporte
+	EndDate          time.Time `db:"end_date"`          // fechaFinTransporte
+	CreatedByID      int       `db:"created_by_id"`
+
+	// Relaciones
+	Carrier           *TaxPayer           `db:"-"`
+	Recipients        []ShipmentRecipient `db:"-"`
+	ElectronicReceipt *ElectronicReceipt  `db:"-"`
+}
+
+// ShipmentRecipient es un destinatario de la guía, con su dirección de llegada y mercadería.
+type ShipmentRecipient struct {
+	BaseEntity
+	ShipmentID     int    `db:"shipment_id"`
+	TaxPayerID     int    `db:"tax_payer_id"`
+	ArrivalAddress string `db:"arrival_address"` // dirDestinatario
+	Reason         string `db:"reason"`          // motivoTraslado (ej. Venta, Devolución)
+	Route          string `db:"route"`
+
+	// Documento sustento del traslado (factura), cuando existe
+	SupportDocNumber  string     `db:"support_doc_number"` // 001-001-000000123
+	SupportDocDate    *time.Time `db:"support_doc_date"`
+	SupportDocAuthKey string     `db:"support_doc_auth_key"`
+
+	TaxPayer *TaxPayer      `db:"-"`
+	Items    []ShipmentItem `db:"-"`
+}
+
+type ShipmentItem struct {
+	BaseEntity
+	RecipientID int     `db:"recipient_id"`
+	Code        string  `db:"code"`
+	Description string  `db:"description"`
+	Quantity    float64 `db:"quantity"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// TaxSaleDocument es un comprobante de venta emitido en el período (factura, nota de crédito o
+// nota de débito) con los valores de su transacción y los datos del cliente.
+type TaxSaleDocument struct {
+	AccessKey                  string
+	ReceiptType                string // 01, 04, 05
+	SRIStatus                  string // AUTORIZADO o ANULADO (anulado en el portal del SRI)
+	TransactionID              int
+	TransactionDate            time.Time
+	CustomerIdentification     string
+	CustomerIdentificationType string // 04 RUC, 05 Cédula, 06 Pasaporte, 07 Consumidor Final
+	CustomerName               string
+	Subtotal15                 float64
+	Subtotal0                  float64
+	TaxAmount                  float64
+	ICEAmount                  float64 // ICE de los ítems; grava IVA junto con Subtotal15
+	Amount                     float64
+	PaymentMethods             []string // Códigos formaPago de la transacción; vacío = 01
+}
+
+// TaxPurchaseDocument es un egreso del período respaldado por un comprobante del proveedor: una
+// factura importada desde su XML o una liquidación de compra emitida por nosotros.
+type TaxPurchaseDocument struct {
+	TransactionID              int
+	TransactionDate            time.Time
+	SupplierIdentification     string
+	SupplierIdentificationType string
+	SupplierName               string
+	AccessKey                  string // Clave de acceso del comprobante del proveedor o de la liquidación (03)
+	Subtotal15                 float64
+	Subtotal0                  float64
+	TaxAmount                  float64
+	ICEAmount                  float64 // ICE facturado por el proveedor
+	Amount                     float64
+	WithholdingAccessKey       string // Retención (07) autorizada sobre esta compra, si existe
+	WithholdingXML             string
+}
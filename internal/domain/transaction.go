@@ -1,6 +1,15 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrPurchaseAlreadyImported indica que la factura del proveedor ya está registrada como compra.
+var ErrPurchaseAlreadyImported = errors.New("la factura ya fue importada")
 
 type Transaction struct {
 	BaseEntity
@@ -12,10 +21,19 @@ type Transaction struct {
 	CategoryID        int       `db:"category_id"`
 
 	// Campos para Facturación Electrónica (SRI)
-	Subtotal15 float64 `db:"subtotal_15"`
-	Subtotal0  float64 `db:"subtotal_0"`
+	Subtotal15 float64 `db:"subtotal_15"` // Base gravada con cualquier tarifa de IVA distinta de cero
+	Subtotal0  float64 `db:"subtotal_0"`  // Base 0%, no objeto y exenta
 	TaxAmount  float64 `db:"tax_amount"`
 	TaxPayerID *int    `db:"tax_payer_id"` // Puntero para soportar NULL
+	Discount   float64 `db:"discount"`     // Descuento global en dólares; se prorratea entre los ítems
+	Tip        float64 `db:"tip"`          // Propina por servicio; no forma parte de la base del IVA
+
+	ICEAmount    float64 `db:"ice_amount"`    // ICE de los ítems; forma parte de la base del IVA
+	IRBPNRAmount float64 `db:"irbpnr_amount"` // Impuesto a las botellas plásticas de los ítems
+
+	// Punto de emisión de la factura o nota de crédito; nil = el configurado en el emisor
+	EstablishmentCode *string `db:"establishment_code"`
+	EmissionPointCode *string `db:"emission_point_code"`
 
 	// Otros campos existentes...
 	AttachmentPath        *string `db:"attachment_path"`
@@ -23,6 +41,8 @@ type Transaction struct {
 	IsVoided              bool    `db:"is_active"`
 	VoidedByTransactionID *int    `db:"voided_by_transaction_id"`
 	VoidsTransactionID    *int    `db:"voids_transaction_id"`
+	RelatedTransactionID  *int    `db:"related_transaction_id"` // Factura que modifica (Nota de Débito o devolución parcial)
+	SupplierAccessKey     *string `db:"supplier_access_key"`    // Clave de acceso de la factura del proveedor (compras importadas)
 
 	// Relaciones
 	Category      *Category `db:"-"`
@@ -31,19 +51,260 @@ type Transaction struct {
 	CreatedByID   int       `db:"created_by_id"`
 	UpdatedByID   int       `db:"updated_by_id"`
 
-	RunningBalance float64           `db:"running_balance"`
-	Items          []TransactionItem `db:"-"` // Detalle de la transacción
+	RunningBalance   float64              `db:"running_balance"`
+	Items            []TransactionItem    `db:"-"` // Detalle de la transacción
+	Payments         []TransactionPayment `db:"-"` // Desglose de formas de pago; vacío = 01 por el total
+	AdditionalFields []AdditionalField    `db:"-"` // Campos propios de esta venta para infoAdicional
 
 	// Relación con SRI
 	ElectronicReceipt *ElectronicReceipt `db:"-"`
 }
 
+// IsPartialCredit indica si el egreso es la devolución parcial de una factura (Nota de Crédito parcial).
+func (t *Transaction) IsPartialCredit() bool {
+	return t.RelatedTransactionID != nil && t.Category != nil && t.Category.Type == Outcome
+}
+
+// SetEmissionPoint asigna el punto de emisión del comprobante si la transacción aún no tiene uno.
+func (t *Transaction) SetEmissionPoint(establishmentCode, emissionPointCode *string) {
+	if t.EstablishmentCode != nil || establishmentCode == nil || emissionPointCode == nil {
+		return
+	}
+	estab, point := *establishmentCode, *emissionPointCode
+	t.EstablishmentCode = &estab
+	t.EmissionPointCode = &point
+}
+
+// IsDebitNote indica si el ingreso es un cargo adicional sobre una factura (Nota de Débito).
+func (t *Transaction) IsDebitNote() bool {
+	return t.RelatedTransactionID != nil && (t.Category == nil || t.Category.Type == Income)
+}
+
 type TransactionItem struct {
 	BaseEntity
 	TransactionID int     `db:"transaction_id"`
 	Description   string  `db:"description"`
 	Quantity      float64 `db:"quantity"`
 	UnitPrice     float64 `db:"unit_price"`
-	TaxRate       int     `db:"tax_rate"` // 0, 2, 4
-	Subtotal      float64 `db:"subtotal"` // unit_price * quantity
+	TaxRate       int     `db:"tax_rate"` // Código de porcentaje de IVA del SRI (ver TaxRate)
+	Discount      float64 `db:"discount"` // Descuento de la línea en dólares
+	Subtotal      float64 `db:"subtotal"` // unit_price * quantity - discount
+
+	// ReturnedItemID es el ítem de la factura que devuelve una línea de devolución parcial.
+	ReturnedItemID *int `db:"returned_item_id"`
+
+	Taxes []ItemTax `db:"-"` // ICE e IRBPNR del ítem; el IVA va en TaxRate
+}
+
+// GrossAmount es cantidad por precio unitario antes de cualquier descuento.
+func (i TransactionItem) GrossAmount() float64 {
+	return roundCents(i.Quantity * i.UnitPrice)
+}
+
+// ItemDiscounts devuelve el descuento total de cada ítem: el de su línea más la parte del descuento
+// global que le corresponde según su subtotal. El último ítem absorbe la diferencia de redondeo.
+func (t *Transaction) ItemDiscounts() []float64 {
+	discounts := make([]float64, len(t.Items))
+	var base float64
+	last := -1
+	for i, item := range t.Items {
+		discounts[i] = item.Discount
+		if item.Subtotal > 0 {
+			base += item.Subtotal
+			last = i
+		}
+	}
+	if t.Discount <= 0 || base <= 0 {
+		return discounts
+	}
+
+	remaining := roundCents(t.Discount)
+	for i, item := range t.Items {
+		if item.Subtotal <= 0 {
+			continue
+		}
+		share := roundCents(t.Discount * item.Subtotal / base)
+		if i == last {
+			share = remaining
+		}
+		remaining = roundCents(remaining - share)
+		discounts[i] = roundCents(discounts[i] + share)
+	}
+	return discounts
+}
+
+// TaxLine es una base imponible con la tarifa de IVA que se le aplica y el impuesto resultante.
+// La base incluye el ICE de los ítems, que grava IVA.
+type TaxLine struct {
+	Rate TaxRate
+	Base float64
+	ICE  float64
+	Tax  float64
+}
+
+// Net es el valor de la línea sin impuestos (la base del IVA sin el ICE).
+func (l TaxLine) Net() float64 {
+	return roundCents(l.Base - l.ICE)
+}
+
+// TaxSummary desglosa el IVA de una transacción con las tarifas vigentes a su fecha, junto con el
+// ICE y el IRBPNR de sus ítems.
+type TaxSummary struct {
+	Items       []TaxLine   // Uno por ítem, en el mismo orden que Transaction.Items
+	Totals      []TaxLine   // Uno por tarifa, ordenados por código
+	ItemTaxes   [][]ItemTax // ICE e IRBPNR calculados de cada ítem, en el mismo orden que Items
+	OtherTotals []ItemTax   // ICE e IRBPNR agrupados por código de impuesto y de porcentaje
+}
+
+// OtherTax suma los impuestos distintos del IVA con el código dado.
+func (s *TaxSummary) OtherTax(taxCode int) float64 {
+	var total float64
+	for _, t := range s.OtherTotals {
+		if t.TaxCode == taxCode {
+			total += t.Amount
+		}
+	}
+	return roundCents(total)
+}
+
+// TaxSummary resuelve la tarifa de cada ítem vigente en la fecha dada (la de la transacción, o la de
+// la factura original en una devolución) y agrupa las bases por tarifa; el IVA de cada grupo se
+// redondea una sola vez sobre su base. El ICE y el IRBPNR se calculan por ítem sobre su valor neto y
+// el ICE se suma a la base del IVA. Sin ítems se usan los subtotales guardados: Subtotal15 en la
+// tarifa general vigente y Subtotal0 en la de 0%.
+func (t *Transaction) TaxSummary(rates TaxRateTable, date time.Time) (*TaxSummary, error) {
+	summary := &TaxSummary{}
+	if len(t.Items) == 0 {
+		if t.Subtotal15 > 0 {
+			rate, err := rates.General(date)
+			if err != nil {
+				return nil, err
+			}
+			summary.Totals = append(summary.Totals, TaxLine{Rate: rate, Base: t.Subtotal15, Tax: t.TaxAmount})
+		}
+		if t.Subtotal0 > 0 {
+			rate, err := rates.Find(TaxRateCodeZero, date)
+			if err != nil {
+				return nil, err
+			}
+			summary.Totals = append(summary.Totals, TaxLine{Rate: rate, Base: t.Subtotal0})
+		}
+		return summary, nil
+	}
+
+	discounts := t.ItemDiscounts()
+	byCode := map[int]*TaxLine{}
+	others := map[string]*ItemTax{}
+	var otherKeys []string
+	for i, item := range t.Items {
+		rate, err := rates.Find(item.TaxRate, date)
+		if err != nil {
+			return nil, fmt.Errorf("ítem %q: %w", item.Description, err)
+		}
+		net := roundCents(item.GrossAmount() - discounts[i])
+
+		var ice float64
+		itemTaxes := make([]ItemTax, 0, len(item.Taxes))
+		for _, tax := range item.Taxes {
+			if err := tax.Validate(); err != nil {
+				return nil, fmt.Errorf("ítem %q: %w", item.Description, err)
+			}
+			tax = tax.Compute(item.Quantity, net)
+			itemTaxes = append(itemTaxes, tax)
+			if tax.TaxCode == ItemTaxICE {
+				ice = roundCents(ice + tax.Amount)
+			}
+
+			key := fmt.Sprintf("%d|%s", tax.TaxCode, tax.PercentageCode)
+			other, ok := others[key]
+			if !ok {
+				other = &ItemTax{TaxCode: tax.TaxCode, PercentageCode: tax.PercentageCode, Rate: tax.Rate, Specific: tax.Specific}
+				others[key] = other
+				otherKeys = append(otherKeys, key)
+			}
+			other.Base = roundCents(other.Base + tax.Base)
+			other.Amount = roundCents(other.Amount + tax.Amount)
+		}
+		summary.ItemTaxes = append(summary.ItemTaxes, itemTaxes)
+
+		base := roundCents(net + ice)
+		summary.Items = append(summary.Items, TaxLine{Rate: rate, Base: base, ICE: ice, Tax: roundCents(base * rate.Percentage / 100)})
+
+		total, ok := byCode[rate.Code]
+		if !ok {
+			total = &TaxLine{Rate: rate}
+			byCode[rate.Code] = total
+		}
+		total.Base = roundCents(total.Base + base)
+		total.ICE = roundCents(total.ICE + ice)
+	}
+
+	sort.Strings(otherKeys)
+	for _, key := range otherKeys {
+		summary.OtherTotals = append(summary.OtherTotals, *others[key])
+	}
+
+	for _, total := range byCode {
+		total.Tax = roundCents(total.Base * total.Rate.Percentage / 100)
+		summary.Totals = append(summary.Totals, *total)
+	}
+	sort.Slice(summary.Totals, func(i, j int) bool { return summary.Totals[i].Rate.Code < summary.Totals[j].Rate.Code })
+	return summary, nil
+}
+
+// RecalculateTotals recalcula las bases imponibles, el IVA y el total a partir de los ítems,
+// aplicando los descuentos de línea y el global y las tarifas vigentes a la fecha de la transacción.
+// Subtotal15 acumula el valor neto de los ítems gravados con cualquier tarifa distinta de cero; el ICE
+// y el IRBPNR calculados se guardan en cada ítem y en la transacción. La propina se suma al total
+// sin gravar IVA.
+func (t *Transaction) RecalculateTotals(rates TaxRateTable) error {
+	var sub15, sub0, tax, ice, irbpnr float64
+	if len(t.Items) > 0 {
+		summary, err := t.TaxSummary(rates, t.TransactionDate)
+		if err != nil {
+			return err
+		}
+		for _, total := range summary.Totals {
+			if total.Rate.Taxed() {
+				sub15 += total.Net()
+				tax += total.Tax
+			} else {
+				sub0 += total.Net()
+			}
+		}
+		for i := range t.Items {
+			t.Items[i].Taxes = summary.ItemTaxes[i]
+		}
+		ice = summary.OtherTax(ItemTaxICE)
+		irbpnr = summary.OtherTax(ItemTaxIRBPNR)
+	}
+	t.Subtotal15 = roundCents(sub15)
+	t.Subtotal0 = roundCents(sub0)
+	t.TaxAmount = roundCents(tax)
+	t.ICEAmount = ice
+	t.IRBPNRAmount = irbpnr
+	t.Amount = roundCents(t.Subtotal15 + t.Subtotal0 + t.TaxAmount + t.ICEAmount + t.IRBPNRAmount + t.Tip)
+	return nil
+}
+
+// LegalServiceTipRate es el recargo por servicio del 10% que cobran restaurantes y hoteles.
+const LegalServiceTipRate = 10.0
+
+// TipFor calcula la propina para un porcentaje sobre el subtotal neto (sin IVA) de la transacción.
+// Debe llamarse después de RecalculateTotals.
+func (t *Transaction) TipFor(rate float64) float64 {
+	if rate <= 0 {
+		return 0
+	}
+	return roundCents((t.Subtotal15 + t.Subtotal0) * rate / 100)
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ReturnedItem indica cuántas unidades de un ítem de la factura devuelve el cliente.
+type ReturnedItem struct {
+	ItemID   int
+	Quantity float64
 }
@@ -0,0 +1,41 @@
+package domain
+
+// PaymentMethodNoFinancialSystem es la forma de pago por defecto de una factura sin desglose.
+const PaymentMethodNoFinancialSystem = "01"
+
+// PaymentMethod es una forma de pago de la tabla 24 de la ficha técnica del SRI.
+type PaymentMethod struct {
+	Code string
+	Name string
+}
+
+// PaymentMethods lista las formas de pago vigentes que acepta el SRI.
+var PaymentMethods = []PaymentMethod{
+	{Code: "01", Name: "Sin utilización del sistema financiero"},
+	{Code: "15", Name: "Compensación de deudas"},
+	{Code: "16", Name: "Tarjeta de débito"},
+	{Code: "17", Name: "Dinero electrónico"},
+	{Code: "19", Name: "Tarjeta de crédito"},
+	{Code: "20", Name: "Otros con utilización del sistema financiero"},
+	{Code: "21", Name: "Endoso de títulos"},
+}
+
+// IsValidPaymentMethod indica si el código pertenece a PaymentMethods.
+func IsValidPaymentMethod(code string) bool {
+	for _, pm := range PaymentMethods {
+		if pm.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionPayment es una línea del desglose de pagos de una factura (formaPago en el XML).
+type TransactionPayment struct {
+	BaseEntity
+	TransactionID int     `db:"transaction_id"`
+	PaymentMethod string  `db:"payment_method"` // Código de PaymentMethods
+	Amount        float64 `db:"amount"`
+	Term          int     `db:"term"`      // plazo; 0 = contado
+	TimeUnit      string  `db:"time_unit"` // unidadTiempo: dias, meses
+}
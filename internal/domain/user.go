@@ -17,6 +17,10 @@ type User struct {
 	FirstName    string   `db:"first_name"`
 	LastName     string   `db:"last_name"`
 	Role         UserRole `db:"role"`
+
+	// Punto de emisión que se propone en sus facturas y notas de crédito; nil = el del emisor
+	DefaultEstablishmentCode *string `db:"default_establishment_code"`
+	DefaultEmissionPointCode *string `db:"default_emission_point_code"`
 }
 
 // --- Permission Helpers (Encapsulación) ---
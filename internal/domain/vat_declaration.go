@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// VATDeclaration es la hoja de trabajo de la declaración mensual de IVA (formulario 104). Cada campo
+// corresponde a un casillero del formulario; los valores netos ya descuentan las notas de crédito.
+type VATDeclaration struct {
+	Year       int
+	Month      time.Month
+	IssuerRUC  string
+	IssuerName string
+
+	SalesCount       int
+	CreditNoteCount  int
+	PurchaseCount    int
+	WithholdingCount int
+
+	// Ventas
+	SalesGross15 decimal.Decimal // 401
+	SalesNet15   decimal.Decimal // 411
+	SalesTax15   decimal.Decimal // 421
+	SalesGross0  decimal.Decimal // 403
+	SalesNet0    decimal.Decimal // 413
+	SalesGross   decimal.Decimal // 409
+	SalesNet     decimal.Decimal // 419
+	SalesTax     decimal.Decimal // 429
+
+	// Compras
+	PurchasesGross15 decimal.Decimal // 500
+	PurchasesNet15   decimal.Decimal // 510
+	PurchasesTax15   decimal.Decimal // 520
+	PurchasesGross0  decimal.Decimal // 507
+	PurchasesNet0    decimal.Decimal // 517
+	PurchasesGross   decimal.Decimal // 509
+	PurchasesNet     decimal.Decimal // 519
+	PurchasesTax     decimal.Decimal // 529
+
+	// Liquidación
+	TaxToSettle          decimal.Decimal // 499
+	ApplicableTaxCredit  decimal.Decimal // 564
+	TaxCaused            decimal.Decimal // 601
+	TaxCredit            decimal.Decimal // 602
+	PreviousCredit       decimal.Decimal // 605
+	CreditCarriedForward decimal.Decimal // 615
+	VATPayable           decimal.Decimal // 620
+
+	// Retenciones de IVA efectuadas, por porcentaje ("10", "20", "30", "50", "70", "100")
+	Withholdings      map[string]decimal.Decimal // 721 a 731
+	TotalWithholdings decimal.Decimal            // 799
+	TotalPayable      decimal.Decimal            // 859
+}
+
+// VATBox es un casillero del formulario 104.
+type VATBox struct {
+	Code  string
+	Label string
+	Value decimal.Decimal
+}
+
+// VATSection agrupa los casilleros de una sección del formulario.
+type VATSection struct {
+	Title string
+	Boxes []VATBox
+}
+
+// VATWithholdingBoxes relaciona cada porcentaje de retención de IVA con su casillero.
+var VATWithholdingBoxes = []struct {
+	Rate string
+	Code string
+}{
+	{"10", "721"}, {"20", "723"}, {"30", "725"}, {"50", "727"}, {"70", "729"}, {"100", "731"},
+}
+
+// Sections devuelve los casilleros en el orden del formulario, para los generadores de reportes.
+func (d *VATDeclaration) Sections() []VATSection {
+	withholdings := make([]VATBox, 0, len(VATWithholdingBoxes)+1)
+	for _, w := range VATWithholdingBoxes {
+		withholdings = append(withholdings, VATBox{w.Code, "Retención del " + w.Rate + "%", d.Withholdings[w.Rate]})
+	}
+	withholdings = append(withholdings, VATBox{"799", "Total impuesto retenido", d.TotalWithholdings})
+
+	return []VATSection{
+		{Title: "Ventas", Boxes: []VATBox{
+			{"401", "Ventas locales gravadas tarifa 15% (valor bruto)", d.SalesGross15},
+			{"411", "Ventas locales gravadas tarifa 15% (valor neto)", d.SalesNet15},
+			{"421", "Impuesto generado ventas tarifa 15%", d.SalesTax15},
+			{"403", "Ventas locales tarifa 0% (valor bruto)", d.SalesGross0},
+			{"413", "Ventas locales tarifa 0% (valor neto)", d.SalesNet0},
+			{"409", "Total ventas (valor bruto)", d.SalesGross},
+			{"419", "Total ventas (valor neto)", d.SalesNet},
+			{"429", "Total impuesto generado", d.SalesTax},
+		}},
+		{Title: "Compras", Boxes: []VATBox{
+			{"500", "Adquisiciones gravadas tarifa 15% con derecho a crédito (valor bruto)", d.PurchasesGross15},
+			{"510", "Adquisiciones gravadas tarifa 15% con derecho a crédito (valor neto)", d.PurchasesNet15},
+			{"520", "Impuesto generado adquisiciones tarifa 15%", d.PurchasesTax15},
+			{"507", "Adquisiciones tarifa 0% (valor bruto)", d.PurchasesGross0},
+			{"517", "Adquisiciones tarifa 0% (valor neto)", d.PurchasesNet0},
+			{"509", "Total adquisiciones (valor bruto)", d.PurchasesGross},
+			{"519", "Total adquisiciones (valor neto)", d.PurchasesNet},
+			{"529", "Total impuesto adquisiciones", d.PurchasesTax},
+		}},
+		{Title: "Resumen Impositivo", Boxes: []VATBox{
+			{"499", "Total impuesto a liquidar en este mes", d.TaxToSettle},
+			{"564", "Crédito tributario aplicable en este período", d.ApplicableTaxCredit},
+			{"601", "Impuesto causado", d.TaxCaused},
+			{"602", "Crédito tributario aplicable en este período", d.TaxCredit},
+			{"605", "Saldo crédito tributario del mes anterior", d.PreviousCredit},
+			{"615", "Saldo crédito tributario para el próximo mes", d.CreditCarriedForward},
+			{"620", "Subtotal a pagar", d.VATPayable},
+		}},
+		{Title: "Retenciones de IVA Efectuadas", Boxes: withholdings},
+		{Title: "Total", Boxes: []VATBox{
+			{"859", "Total consolidado de IVA a pagar", d.TotalPayable},
+		}},
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Códigos de impuesto usados en las líneas de retención (tabla 19 de la ficha técnica del SRI)
+const (
+	WithholdingTaxRenta = "1"
+	WithholdingTaxIVA   = "2"
+)
+
+// Withholding agrupa los datos para emitir un Comprobante de Retención (07)
+// sobre la factura de un proveedor. No se persiste por separado: el XML firmado
+// en electronic_receipts es el registro legal.
+type Withholding struct {
+	TransactionID int // Egreso al que corresponde la factura del proveedor
+	SupplierID    int // TaxPayer del proveedor (sujeto retenido)
+
+	SupportCode       string    // codSustento: 01 crédito tributario IVA, 02 costo/gasto...
+	SupportDocType    string    // codDocSustento: 01 Factura, 03 Liquidación de compra
+	SupportDocNumber  string    // 001-001-000000123
+	SupportDocDate    time.Time // Fecha de emisión de la factura del proveedor
+	SupportDocAuthKey string    // Número de autorización de la factura del proveedor
+
+	Lines []WithholdingLine
+}
+
+// WithholdingLine es una retención individual de Renta o IVA.
+type WithholdingLine struct {
+	TaxCode       string  // WithholdingTaxRenta o WithholdingTaxIVA
+	RetentionCode string  // Código del SRI (ej. 312 Renta, 1 = 30% IVA)
+	TaxBase       float64 // Base imponible sobre la que se retiene
+	Percentage    float64 // Porcentaje a retener (ej. 1.75, 30)
+}
+
+// Amount calcula el valor retenido redondeado a dos decimales.
+func (l WithholdingLine) Amount() float64 {
+	return math.Round(l.TaxBase*l.Percentage) / 100
+}
+
+// Total suma el valor retenido de todas las líneas.
+func (w *Withholding) Total() float64 {
+	total := 0.0
+	for _, l := range w.Lines {
+		total += l.Amount()
+	}
+	return total
+}
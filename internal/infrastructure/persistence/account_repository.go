@@ -11,19 +11,26 @@ import (
 )
 
 type AccountRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
-func NewAccountRepository(db *pgxpool.Pool) *AccountRepositoryImpl {
-	return &AccountRepositoryImpl{db: db}
+func NewAccountRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *AccountRepositoryImpl {
+	return &AccountRepositoryImpl{db: db, scope: scope}
 }
 
 func (r *AccountRepositoryImpl) GetAllAccounts(ctx context.Context) ([]domain.Account, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `select id, name, COALESCE(number, ''), type, initial_balance, created_at, updated_at 
 	          from accounts
+	          where company_id = $1
 	          order by name asc`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get accounts: %w", err)
 	}
@@ -48,14 +55,19 @@ func (r *AccountRepositoryImpl) GetAllAccounts(ctx context.Context) ([]domain.Ac
 }
 
 func (r *AccountRepositoryImpl) GetAccountByID(ctx context.Context, id int) (*domain.Account, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `select id, name, COALESCE(number, ''), type, initial_balance, created_at, updated_at
 	          from accounts
-	          where id = $1`
+	          where id = $1 and company_id = $2`
 
 	var acc domain.Account
 
-	row := r.db.QueryRow(ctx, query, id)
-	err := row.Scan(
+	row := r.db.QueryRow(ctx, query, id, companyID)
+	err = row.Scan(
 		&acc.ID,
 		&acc.Name,
 		&acc.Number,
@@ -72,10 +84,15 @@ func (r *AccountRepositoryImpl) GetAccountByID(ctx context.Context, id int) (*do
 }
 
 func (r *AccountRepositoryImpl) AccountExists(ctx context.Context, name, number string, id int) (bool, error) {
-	query := `select exists(select 1 from accounts where (name = $1 or number = $2) and id != $3)`
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return false, err
+	}
+
+	query := `select exists(select 1 from accounts where (name = $1 or number = $2) and id != $3 and company_id = $4)`
 	var exists bool
 
-	err := r.db.QueryRow(ctx, query, name, number, id).Scan(&exists)
+	err = r.db.QueryRow(ctx, query, name, number, id, companyID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if account exists: %w", err)
 	}
@@ -83,9 +100,14 @@ func (r *AccountRepositoryImpl) AccountExists(ctx context.Context, name, number
 }
 
 func (r *AccountRepositoryImpl) CreateAccount(ctx context.Context, acc *domain.Account) error {
-	query := `insert into accounts (name, number, type, initial_balance, created_at, updated_at) values ($1, $2, $3, $4, $5, $6) returning id, created_at, updated_at`
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	query := `insert into accounts (name, number, type, initial_balance, created_at, updated_at, company_id) values ($1, $2, $3, $4, $5, $6, $7) returning id, created_at, updated_at`
 
-	err := r.db.QueryRow(
+	err = r.db.QueryRow(
 		ctx, query,
 		acc.Name,
 		acc.Number,
@@ -93,6 +115,7 @@ func (r *AccountRepositoryImpl) CreateAccount(ctx context.Context, acc *domain.A
 		acc.InitialBalance,
 		time.Now(),
 		time.Now(),
+		companyID,
 	).Scan(&acc.ID, &acc.CreatedAt, &acc.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create account: %w", err)
@@ -102,9 +125,14 @@ func (r *AccountRepositoryImpl) CreateAccount(ctx context.Context, acc *domain.A
 }
 
 func (r *AccountRepositoryImpl) DeleteAccount(ctx context.Context, id int) error {
-	query := `delete from accounts where id = $1`
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
 
-	result, err := r.db.Exec(ctx, query, id)
+	query := `delete from accounts where id = $1 and company_id = $2`
+
+	result, err := r.db.Exec(ctx, query, id, companyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete account: %w", err)
 	}
@@ -117,7 +145,12 @@ func (r *AccountRepositoryImpl) DeleteAccount(ctx context.Context, id int) error
 }
 
 func (r *AccountRepositoryImpl) UpdateAccount(ctx context.Context, acc *domain.Account) error {
-	query := `update accounts set name = $1, number = $2, type = $3, updated_at = $4 where id = $5`
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	query := `update accounts set name = $1, number = $2, type = $3, updated_at = $4 where id = $5 and company_id = $6`
 
 	result, err := r.db.Exec(
 		ctx,
@@ -127,6 +160,7 @@ func (r *AccountRepositoryImpl) UpdateAccount(ctx context.Context, acc *domain.A
 		acc.Type,
 		time.Now(),
 		acc.ID,
+		companyID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update account: %w", err)
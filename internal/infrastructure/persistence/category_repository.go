@@ -12,11 +12,12 @@ import (
 )
 
 type CategoryRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
-func NewCategoryRepository(db *pgxpool.Pool) *CategoryRepositoryImpl {
-	return &CategoryRepositoryImpl{db: db}
+func NewCategoryRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *CategoryRepositoryImpl {
+	return &CategoryRepositoryImpl{db: db, scope: scope}
 }
 
 // getPaginatedCategories is the private helper function that contains the common logic.
@@ -26,9 +27,15 @@ func (r *CategoryRepositoryImpl) getPaginatedCategories(
 	baseWhereClause string,
 	filter ...string,
 ) (*domain.PaginatedResult[domain.Category], error) {
-	var queryArgs []any
-	var countQueryArgs []any
-	whereClauses := []string{}
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Every query is limited to the selected company
+	queryArgs := []any{companyID}
+	countQueryArgs := []any{companyID}
+	whereClauses := []string{"company_id = $1"}
 
 	// Start with the base WHERE clause if it exists
 	if baseWhereClause != "" {
@@ -48,15 +55,12 @@ func (r *CategoryRepositoryImpl) getPaginatedCategories(
 	}
 
 	// Join all WHERE clauses with " AND "
-	fullWhereClause := ""
-	if len(whereClauses) > 0 {
-		fullWhereClause = " WHERE " + strings.Join(whereClauses, " AND ")
-	}
+	fullWhereClause := " WHERE " + strings.Join(whereClauses, " AND ")
 
 	// --- Count Query ---
 	countQuery := `SELECT count(*) FROM categories` + fullWhereClause
 	var totalCount int64
-	err := r.db.QueryRow(ctx, countQuery, countQueryArgs...).Scan(&totalCount)
+	err = r.db.QueryRow(ctx, countQuery, countQueryArgs...).Scan(&totalCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total category count: %w", err)
 	}
@@ -127,11 +131,17 @@ func (r *CategoryRepositoryImpl) GetSelectablePaginatedCategories(ctx context.Co
 }
 
 func (r *CategoryRepositoryImpl) GetAllCategories(ctx context.Context) ([]domain.Category, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `select id, name, type, monthly_budget 
 	          from categories 
+	          where company_id = $1
 	          order by name asc`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get categories: %w", err)
 	}
@@ -149,12 +159,17 @@ func (r *CategoryRepositoryImpl) GetAllCategories(ctx context.Context) ([]domain
 }
 
 func (r *CategoryRepositoryImpl) GetCategoryByID(ctx context.Context, id int) (*domain.Category, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `select id, name, type, monthly_budget, created_at, updated_at 
 	          from categories 
-	          where id = $1`
+	          where id = $1 and company_id = $2`
 
 	var cat domain.Category
-	row := r.db.QueryRow(ctx, query, id)
+	row := r.db.QueryRow(ctx, query, id, companyID)
 	if err := row.Scan(&cat.ID, &cat.Name, &cat.Type, &cat.MonthlyBudget, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
 		return nil, fmt.Errorf("failed to get category by ID: %w", err)
 	}
@@ -162,9 +177,14 @@ func (r *CategoryRepositoryImpl) GetCategoryByID(ctx context.Context, id int) (*
 }
 
 func (r *CategoryRepositoryImpl) CategoryExists(ctx context.Context, name string, id int) (bool, error) {
-	query := `select exists(select 1 from categories where name = $1 and id != $2)`
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return false, err
+	}
+
+	query := `select exists(select 1 from categories where name = $1 and id != $2 and company_id = $3)`
 	var exists bool
-	err := r.db.QueryRow(ctx, query, name, id).Scan(&exists)
+	err = r.db.QueryRow(ctx, query, name, id, companyID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if category exists: %w", err)
 	}
@@ -172,12 +192,17 @@ func (r *CategoryRepositoryImpl) CategoryExists(ctx context.Context, name string
 }
 
 func (r *CategoryRepositoryImpl) CreateCategory(ctx context.Context, category *domain.Category) error {
-	query := `insert into categories (name, type, monthly_budget, created_at, updated_at) 
-	                          values ($1, $2, $3, $4, $5) 
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	query := `insert into categories (name, type, monthly_budget, created_at, updated_at, company_id) 
+	                          values ($1, $2, $3, $4, $5, $6) 
 	                          returning id, created_at, updated_at`
 
 	now := time.Now()
-	err := r.db.QueryRow(ctx, query, category.Name, category.Type, category.MonthlyBudget, now, now).
+	err = r.db.QueryRow(ctx, query, category.Name, category.Type, category.MonthlyBudget, now, now, companyID).
 		Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create category: %w", err)
@@ -187,9 +212,14 @@ func (r *CategoryRepositoryImpl) CreateCategory(ctx context.Context, category *d
 }
 
 func (r *CategoryRepositoryImpl) FindByNameAndType(ctx context.Context, name string, catType domain.CategoryType) (*domain.Category, error) {
-	query := `SELECT id, name, type, monthly_budget, created_at, updated_at FROM categories WHERE name = $1 AND type = $2`
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, name, type, monthly_budget, created_at, updated_at FROM categories WHERE name = $1 AND type = $2 AND company_id = $3`
 	var category domain.Category
-	err := r.db.QueryRow(ctx, query, name, catType).Scan(&category.ID, &category.Name, &category.Type, &category.MonthlyBudget, &category.CreatedAt, &category.UpdatedAt)
+	err = r.db.QueryRow(ctx, query, name, catType, companyID).Scan(&category.ID, &category.Name, &category.Type, &category.MonthlyBudget, &category.CreatedAt, &category.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("category with name '%s' and type '%s' not found", name, catType)
@@ -200,12 +230,17 @@ func (r *CategoryRepositoryImpl) FindByNameAndType(ctx context.Context, name str
 }
 
 func (r *CategoryRepositoryImpl) UpdateCategory(ctx context.Context, category *domain.Category) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	query := `update categories 
 	          set name = $1, type = $2, monthly_budget = $3, updated_at = $4 
-	          where id = $5`
+	          where id = $5 and company_id = $6`
 	now := time.Now()
 
-	_, err := r.db.Exec(ctx, query, category.Name, category.Type, category.MonthlyBudget, now, category.ID)
+	_, err = r.db.Exec(ctx, query, category.Name, category.Type, category.MonthlyBudget, now, category.ID, companyID)
 	if err != nil {
 		return fmt.Errorf("failed to update category: %w", err)
 	}
@@ -215,9 +250,14 @@ func (r *CategoryRepositoryImpl) UpdateCategory(ctx context.Context, category *d
 }
 
 func (r *CategoryRepositoryImpl) DeleteCategory(ctx context.Context, id int) error {
-	query := `delete from categories where id = $1`
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	query := `delete from categories where id = $1 and company_id = $2`
 
-	_, err := r.db.Exec(ctx, query, id)
+	_, err = r.db.Exec(ctx, query, id, companyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
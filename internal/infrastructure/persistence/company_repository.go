@@ -0,0 +1,118 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+type CompanyRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+func NewCompanyRepository(db *pgxpool.Pool) *CompanyRepositoryImpl {
+	return &CompanyRepositoryImpl{db: db}
+}
+
+// GetAll devuelve todas las empresas de la instalación ordenadas por nombre.
+func (r *CompanyRepositoryImpl) GetAll(ctx context.Context) ([]domain.Company, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, is_active, created_at, updated_at
+		FROM companies
+		ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get companies: %w", err)
+	}
+	defer rows.Close()
+
+	var companies []domain.Company
+	for rows.Next() {
+		var c domain.Company
+		if err := rows.Scan(&c.ID, &c.Name, &c.IsActive, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		companies = append(companies, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over company rows: %w", err)
+	}
+	return companies, nil
+}
+
+// GetByID devuelve la empresa o nil si no existe.
+func (r *CompanyRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Company, error) {
+	var c domain.Company
+	err := r.db.QueryRow(ctx, `
+		SELECT id, name, is_active, created_at, updated_at
+		FROM companies
+		WHERE id = $1`, id).Scan(&c.ID, &c.Name, &c.IsActive, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	return &c, nil
+}
+
+// Create registra la empresa junto con las categorías de sistema y el consumidor final que
+// las anulaciones, la conciliación y la facturación necesitan en cada libro.
+func (r *CompanyRepositoryImpl) Create(ctx context.Context, company *domain.Company) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	now := time.Now()
+	err = tx.QueryRow(ctx, `
+		INSERT INTO companies (name, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`,
+		company.Name, company.IsActive, now, now,
+	).Scan(&company.ID, &company.CreatedAt, &company.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create company: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO categories (name, type, company_id, created_at, updated_at) VALUES
+		('Anular Transacción E', 'Egreso', $1, $2, $2),
+		('Anular Transacción I', 'Ingreso', $1, $2, $2),
+		('Ajuste por Reconciliación', 'Ingreso', $1, $2, $2),
+		('Ajuste por Reconciliación', 'Egreso', $1, $2, $2)`,
+		company.ID, now)
+	if err != nil {
+		return fmt.Errorf("failed to create system categories: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO tax_payers (identification, identification_type, name, email, company_id, created_at, updated_at)
+		VALUES ('9999999999999', '07', 'CONSUMIDOR FINAL', 'consumidorfinal@verith.com', $1, $2, $2)`,
+		company.ID, now)
+	if err != nil {
+		return fmt.Errorf("failed to create final consumer: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Update cambia el nombre y el estado de la empresa.
+func (r *CompanyRepositoryImpl) Update(ctx context.Context, company *domain.Company) error {
+	company.UpdatedAt = time.Now()
+	tag, err := r.db.Exec(ctx, `
+		UPDATE companies SET name = $1, is_active = $2, updated_at = $3
+		WHERE id = $4`,
+		company.Name, company.IsActive, company.UpdatedAt, company.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update company: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("company with ID %d not found", company.ID)
+	}
+	return nil
+}
@@ -10,96 +10,233 @@ import (
 	"github.com/nelsonmarro/verith/internal/domain"
 )
 
+// ElectronicReceiptRepositoryImpl guarda los comprobantes electrónicos. Cada comprobante pertenece a
+// la empresa de su emisor; las claves de acceso son únicas en todo el SRI.
 type ElectronicReceiptRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
-func NewElectronicReceiptRepository(db *pgxpool.Pool) *ElectronicReceiptRepositoryImpl {
-	return &ElectronicReceiptRepositoryImpl{db: db}
+func NewElectronicReceiptRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *ElectronicReceiptRepositoryImpl {
+	return &ElectronicReceiptRepositoryImpl{db: db, scope: scope}
 }
 
+// Create guarda el comprobante junto con el primer evento de su historia.
 func (r *ElectronicReceiptRepositoryImpl) Create(ctx context.Context, er *domain.ElectronicReceipt) error {
+	if err := domain.ValidateTransition("", er.SRIStatus); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	query := `
 		INSERT INTO electronic_receipts (
 			transaction_id, issuer_id, tax_payer_id, access_key, receipt_type, 
-			xml_content, sri_status, sri_message, environment, email_sent, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			xml_content, sri_status, sri_message, environment, email_sent, created_at, updated_at, shipment_id
+		) VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at, updated_at
 	`
 	now := time.Now()
-	err := r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		er.TransactionID, er.IssuerID, er.TaxPayerID, er.AccessKey, er.ReceiptType,
-		er.XMLContent, er.SRIStatus, er.SRIMessage, er.Environment, er.EmailSent, now, now,
+		er.XMLContent, er.SRIStatus, er.SRIMessage, er.Environment, er.EmailSent, now, now, er.ShipmentID,
 	).Scan(&er.ID, &er.CreatedAt, &er.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create electronic receipt: %w", err)
 	}
-	return nil
+
+	if err := insertReceiptEvent(ctx, tx, er.ID, er.AccessKey, "", er.SRIStatus, "Comprobante generado", now); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
+// Update reemplaza el comprobante (re-emisión sobre la misma fila) si su estado actual permite el
+// nuevo, y lo registra en la historia. El cliente solo se cambia si viene informado.
 func (r *ElectronicReceiptRepositoryImpl) Update(ctx context.Context, er *domain.ElectronicReceipt) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var current domain.ReceiptStatus
+	err = tx.QueryRow(ctx, `
+		SELECT sri_status FROM electronic_receipts
+		WHERE id = $1
+		  AND issuer_id IN (SELECT id FROM issuers WHERE company_id = $2)
+		FOR UPDATE`, er.ID, companyID).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to get electronic receipt: %w", err)
+	}
+	if err := domain.ValidateTransition(current, er.SRIStatus); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE electronic_receipts SET 
 			access_key = $1, xml_content = $2, sri_status = $3, sri_message = $4, 
-			environment = $5, authorization_date = $6, updated_at = $7
+			environment = $5, authorization_date = $6, updated_at = $7,
+			tax_payer_id = COALESCE(NULLIF($9, 0), tax_payer_id)
 		WHERE id = $8
 	`
-	_, err := r.db.Exec(ctx, query,
+	now := time.Now()
+	_, err = tx.Exec(ctx, query,
 		er.AccessKey, er.XMLContent, er.SRIStatus, er.SRIMessage,
-		er.Environment, er.AuthorizationDate, time.Now(), er.ID,
+		er.Environment, er.AuthorizationDate, now, er.ID, er.TaxPayerID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update electronic receipt: %w", err)
 	}
-	return nil
+
+	if err := insertReceiptEvent(ctx, tx, er.ID, er.AccessKey, current, er.SRIStatus, "Re-emisión", now); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *ElectronicReceiptRepositoryImpl) UpdateStatus(ctx context.Context, accessKey string, status string, message string, authDate *time.Time) error {
+// UpdateStatus cambia el estado del comprobante si el ciclo lo permite y registra el cambio, con el
+// mensaje del SRI y el usuario del contexto, en la historia.
+func (r *ElectronicReceiptRepositoryImpl) UpdateStatus(ctx context.Context, accessKey string, status domain.ReceiptStatus, message string, authDate *time.Time) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id int
+	var current domain.ReceiptStatus
+	err = tx.QueryRow(ctx, `
+		SELECT id, sri_status FROM electronic_receipts
+		WHERE access_key = $1
+		  AND issuer_id IN (SELECT id FROM issuers WHERE company_id = $2)
+		FOR UPDATE`, accessKey, companyID).Scan(&id, &current)
+	if err != nil {
+		return fmt.Errorf("failed to get receipt %s: %w", accessKey, err)
+	}
+	if err := domain.ValidateTransition(current, status); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE electronic_receipts SET 
 			sri_status = $1, sri_message = $2, authorization_date = $3, updated_at = $4
-		WHERE access_key = $5
+		WHERE id = $5
 	`
-	_, err := r.db.Exec(ctx, query, status, message, authDate, time.Now(), accessKey)
-	if err != nil {
+	now := time.Now()
+	if _, err := tx.Exec(ctx, query, status, message, authDate, now, id); err != nil {
 		return fmt.Errorf("failed to update receipt status: %w", err)
 	}
-	return nil
+
+	if err := insertReceiptEvent(ctx, tx, id, accessKey, current, status, message, now); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *ElectronicReceiptRepositoryImpl) UpdateXML(ctx context.Context, accessKey string, xmlContent string) error {
-	query := `UPDATE electronic_receipts SET xml_content = $1, updated_at = $2 WHERE access_key = $3`
-	_, err := r.db.Exec(ctx, query, xmlContent, time.Now(), accessKey)
-	return err
+func insertReceiptEvent(ctx context.Context, tx pgx.Tx, receiptID int, accessKey string, from, to domain.ReceiptStatus, message string, at time.Time) error {
+	var userID *int
+	if user := domain.UserFromContext(ctx); user != nil {
+		userID = &user.ID
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO electronic_receipt_events (receipt_id, access_key, from_status, to_status, message, user_id, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)`,
+		receiptID, accessKey, string(from), to, message, userID, at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record receipt event: %w", err)
+	}
+	return nil
 }
 
-func (r *ElectronicReceiptRepositoryImpl) UpdateTaxPayerID(ctx context.Context, accessKey string, taxPayerID int) error {
-	query := `UPDATE electronic_receipts SET tax_payer_id = $1, updated_at = $2 WHERE access_key = $3`
-	_, err := r.db.Exec(ctx, query, taxPayerID, time.Now(), accessKey)
-	return err
+// GetEvents devuelve la historia del comprobante en orden cronológico.
+func (r *ElectronicReceiptRepositoryImpl) GetEvents(ctx context.Context, receiptID int) ([]domain.ReceiptEvent, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT e.id, e.receipt_id, e.access_key, COALESCE(e.from_status, ''), e.to_status, COALESCE(e.message, ''),
+		       e.user_id, e.created_at, COALESCE(NULLIF(TRIM(u.first_name || ' ' || u.last_name), ''), u.username, '')
+		FROM electronic_receipt_events e
+		JOIN electronic_receipts r ON r.id = e.receipt_id
+		LEFT JOIN users u ON u.id = e.user_id
+		WHERE e.receipt_id = $1
+		  AND r.issuer_id IN (SELECT id FROM issuers WHERE company_id = $2)
+		ORDER BY e.created_at, e.id
+	`
+	rows, err := r.db.Query(ctx, query, receiptID, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipt events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.ReceiptEvent
+	for rows.Next() {
+		var e domain.ReceiptEvent
+		err := rows.Scan(&e.ID, &e.ReceiptID, &e.AccessKey, &e.FromStatus, &e.ToStatus, &e.Message, &e.UserID, &e.CreatedAt, &e.UserName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan receipt event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
 }
 
 func (r *ElectronicReceiptRepositoryImpl) UpdateEmailSent(ctx context.Context, accessKey string, sent bool) error {
-	query := `UPDATE electronic_receipts SET email_sent = $1, updated_at = $2 WHERE access_key = $3`
-	_, err := r.db.Exec(ctx, query, sent, time.Now(), accessKey)
-	return err
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE electronic_receipts SET email_sent = $1, updated_at = $2
+		WHERE access_key = $3
+		  AND issuer_id IN (SELECT id FROM issuers WHERE company_id = $4)
+	`
+	if _, err := r.db.Exec(ctx, query, sent, time.Now(), accessKey, companyID); err != nil {
+		return fmt.Errorf("failed to update email sent: %w", err)
+	}
+	return nil
 }
 
 func (r *ElectronicReceiptRepositoryImpl) GetByAccessKey(ctx context.Context, accessKey string) (*domain.ElectronicReceipt, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, transaction_id, issuer_id, tax_payer_id, access_key, receipt_type, 
+		SELECT id, COALESCE(transaction_id, 0), shipment_id, issuer_id, tax_payer_id, access_key, receipt_type, 
 		       xml_content, authorization_date, sri_status, sri_message, ride_path, environment, email_sent, created_at, updated_at
 		FROM electronic_receipts
 		WHERE access_key = $1
+		  AND issuer_id IN (SELECT id FROM issuers WHERE company_id = $2)
 	`
 	var er domain.ElectronicReceipt
 	// Manejo de nulos si authorization_date o ride_path son nulos
 	var authDate *time.Time
 	var ridePath *string
 
-	err := r.db.QueryRow(ctx, query, accessKey).Scan(
-		&er.ID, &er.TransactionID, &er.IssuerID, &er.TaxPayerID, &er.AccessKey, &er.ReceiptType,
+	err = r.db.QueryRow(ctx, query, accessKey, companyID).Scan(
+		&er.ID, &er.TransactionID, &er.ShipmentID, &er.IssuerID, &er.TaxPayerID, &er.AccessKey, &er.ReceiptType,
 		&er.XMLContent, &authDate, &er.SRIStatus, &er.SRIMessage, &ridePath, &er.Environment, &er.EmailSent, &er.CreatedAt, &er.UpdatedAt,
 	)
 
@@ -119,20 +256,26 @@ func (r *ElectronicReceiptRepositoryImpl) GetByAccessKey(ctx context.Context, ac
 }
 
 func (r *ElectronicReceiptRepositoryImpl) FindPendingReceipts(ctx context.Context) ([]domain.ElectronicReceipt, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	// Solo intentamos sincronizar comprobantes recientes (últimos 2 días).
 	// Hacemos JOIN para mostrar datos útiles al usuario (Cliente, Monto, Nro Factura)
 	query := `
-		SELECT r.id, r.transaction_id, r.issuer_id, r.tax_payer_id, r.access_key, r.receipt_type, 
+		SELECT r.id, COALESCE(r.transaction_id, 0), r.shipment_id, r.issuer_id, r.tax_payer_id, r.access_key, r.receipt_type, 
 		       r.xml_content, r.authorization_date, r.sri_status, r.sri_message, r.environment, r.email_sent, r.created_at, r.updated_at,
-			   t.transaction_number, t.amount, COALESCE(tp.name, 'CONSUMIDOR FINAL')
+			   COALESCE(t.transaction_number, 'GUÍA DE REMISIÓN'), COALESCE(t.amount, 0), COALESCE(tp.name, 'CONSUMIDOR FINAL')
 		FROM electronic_receipts r
-		JOIN transactions t ON r.transaction_id = t.id
+		LEFT JOIN transactions t ON r.transaction_id = t.id
 		LEFT JOIN tax_payers tp ON r.tax_payer_id = tp.id
 		WHERE r.sri_status IN ('PENDIENTE', 'RECIBIDA', 'EN PROCESO', 'ERROR_ENVIO', 'ERROR_RED')
 		AND r.created_at > NOW() - INTERVAL '2 days'
+		AND r.issuer_id IN (SELECT id FROM issuers WHERE company_id = $1)
 		ORDER BY r.created_at DESC
 	`
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query pending receipts: %w", err)
 	}
@@ -144,7 +287,7 @@ func (r *ElectronicReceiptRepositoryImpl) FindPendingReceipts(ctx context.Contex
 		var authDate *time.Time
 		
 		err := rows.Scan(
-			&er.ID, &er.TransactionID, &er.IssuerID, &er.TaxPayerID, &er.AccessKey, &er.ReceiptType,
+			&er.ID, &er.TransactionID, &er.ShipmentID, &er.IssuerID, &er.TaxPayerID, &er.AccessKey, &er.ReceiptType,
 			&er.XMLContent, &authDate, &er.SRIStatus, &er.SRIMessage, &er.Environment, &er.EmailSent, &er.CreatedAt, &er.UpdatedAt,
 			&er.TransactionNumber, &er.TotalAmount, &er.ClientName,
 		)
@@ -156,3 +299,50 @@ func (r *ElectronicReceiptRepositoryImpl) FindPendingReceipts(ctx context.Contex
 	}
 	return receipts, nil
 }
+
+// FindAuthorized devuelve los comprobantes autorizados de la empresa emitidos entre from y to
+// (inclusive) de los tipos indicados, con su XML. La fecha de emisión se toma de la clave de
+// acceso, que la lleva en sus primeros ocho dígitos.
+func (r *ElectronicReceiptRepositoryImpl) FindAuthorized(ctx context.Context, from, to time.Time, receiptTypes []string) ([]domain.ElectronicReceipt, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT r.id, COALESCE(r.transaction_id, 0), r.shipment_id, r.issuer_id, r.tax_payer_id, r.access_key, r.receipt_type,
+		       r.xml_content, r.authorization_date, r.sri_status, r.sri_message, r.environment, r.email_sent, r.created_at, r.updated_at,
+		       COALESCE(t.transaction_number, ''), COALESCE(t.amount, 0), COALESCE(tp.name, 'CONSUMIDOR FINAL')
+		FROM electronic_receipts r
+		LEFT JOIN transactions t ON r.transaction_id = t.id
+		LEFT JOIN tax_payers tp ON r.tax_payer_id = tp.id
+		WHERE r.sri_status = 'AUTORIZADO'
+		  AND r.receipt_type = ANY($3)
+		  AND to_date(substring(r.access_key from 1 for 8), 'DDMMYYYY') BETWEEN $1::date AND $2::date
+		  AND r.issuer_id IN (SELECT id FROM issuers WHERE company_id = $4)
+		ORDER BY r.receipt_type, r.access_key
+	`
+	rows, err := r.db.Query(ctx, query, from, to, receiptTypes, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authorized receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var receipts []domain.ElectronicReceipt
+	for rows.Next() {
+		var er domain.ElectronicReceipt
+		var authDate *time.Time
+
+		err := rows.Scan(
+			&er.ID, &er.TransactionID, &er.ShipmentID, &er.IssuerID, &er.TaxPayerID, &er.AccessKey, &er.ReceiptType,
+			&er.XMLContent, &authDate, &er.SRIStatus, &er.SRIMessage, &er.Environment, &er.EmailSent, &er.CreatedAt, &er.UpdatedAt,
+			&er.TransactionNumber, &er.TotalAmount, &er.ClientName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		er.AuthorizationDate = authDate
+		receipts = append(receipts, er)
+	}
+	return receipts, rows.Err()
+}
@@ -0,0 +1,124 @@
+//go:build integration
+
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElectronicReceiptRepository_Events(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	truncateTables(t)
+
+	user := createTestUser(t, testUserRepo, "facturador", domain.RoleAdmin)
+	repo := NewElectronicReceiptRepository(dbPool, testScope)
+
+	var issuerID, taxPayerID int
+	err := dbPool.QueryRow(ctx, `
+		INSERT INTO issuers (ruc, business_name, trade_name, establishment_address, main_address, establishment_code, emission_point_code, environment, keep_accounting, signature_path, company_id, created_at, updated_at)
+		VALUES ('1790000000001', 'Test Issuer', 'Test Trade', 'Addr', 'Main Addr', '001', '001', 1, TRUE, '/tmp/dummy.p12', 1, NOW(), NOW())
+		RETURNING id
+	`).Scan(&issuerID)
+	require.NoError(t, err)
+	err = dbPool.QueryRow(ctx, `
+		INSERT INTO tax_payers (identification, name, email, identification_type, created_at, updated_at)
+		VALUES ('9999999999999', 'Consumer', 'test@test.com', '07', NOW(), NOW())
+		RETURNING id
+	`).Scan(&taxPayerID)
+	require.NoError(t, err)
+
+	accessKey := "1710202601179001234500110010010000000011234567811"
+	receipt := &domain.ElectronicReceipt{
+		IssuerID: issuerID, TaxPayerID: taxPayerID, AccessKey: accessKey, ReceiptType: "01",
+		XMLContent: "<factura/>", SRIStatus: domain.ReceiptPending, Environment: 1,
+	}
+
+	t.Run("A new receipt must start PENDIENTE", func(t *testing.T) {
+		bad := *receipt
+		bad.SRIStatus = domain.ReceiptAuthorized
+		err := repo.Create(ctx, &bad)
+		assert.ErrorIs(t, err, domain.ErrInvalidReceiptTransition)
+	})
+
+	t.Run("Create and UpdateStatus record the history", func(t *testing.T) {
+		require.NoError(t, repo.Create(ctx, receipt))
+
+		require.NoError(t, repo.UpdateStatus(domain.ContextWithUser(ctx, user), accessKey, domain.ReceiptReceived, "Recibido por SRI", nil))
+		authDate := time.Now()
+		require.NoError(t, repo.UpdateStatus(ctx, accessKey, domain.ReceiptAuthorized, "Autorizado", &authDate))
+
+		events, err := repo.GetEvents(ctx, receipt.ID)
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+
+		assert.Empty(t, events[0].FromStatus)
+		assert.Equal(t, domain.ReceiptPending, events[0].ToStatus)
+		assert.Equal(t, domain.ReceiptPending, events[1].FromStatus)
+		assert.Equal(t, domain.ReceiptReceived, events[1].ToStatus)
+		require.NotNil(t, events[1].UserID)
+		assert.Equal(t, user.ID, *events[1].UserID)
+		assert.Equal(t, "Test User", events[1].UserName)
+		assert.Equal(t, domain.ReceiptAuthorized, events[2].ToStatus)
+		assert.Nil(t, events[2].UserID, "the background sync has no user")
+	})
+
+	t.Run("An authorized receipt cannot change", func(t *testing.T) {
+		err := repo.UpdateStatus(ctx, accessKey, domain.ReceiptPending, "", nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidReceiptTransition)
+
+		fetched, err := repo.GetByAccessKey(ctx, accessKey)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReceiptAuthorized, fetched.SRIStatus)
+
+		events, err := repo.GetEvents(ctx, receipt.ID)
+		require.NoError(t, err)
+		assert.Len(t, events, 3)
+	})
+
+	t.Run("FindAuthorized filters by emission date and type", func(t *testing.T) {
+		day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+		found, err := repo.FindAuthorized(ctx, day, day, []string{"01"})
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, accessKey, found[0].AccessKey)
+		assert.Equal(t, "<factura/>", found[0].XMLContent)
+		assert.Equal(t, "Consumer", found[0].ClientName)
+
+		found, err = repo.FindAuthorized(ctx, day, day, []string{"04", "07"})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+
+		found, err = repo.FindAuthorized(ctx, day.AddDate(0, 0, 1), day.AddDate(0, 1, 0), []string{"01"})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("Another company cannot write the receipt", func(t *testing.T) {
+		other := NewElectronicReceiptRepository(dbPool, domain.NewCompanyScope(2))
+
+		err := other.UpdateStatus(ctx, accessKey, domain.ReceiptRejected, "", nil)
+		assert.Error(t, err)
+
+		foreign := *receipt
+		foreign.SRIStatus = domain.ReceiptPending
+		assert.Error(t, other.Update(ctx, &foreign))
+
+		require.NoError(t, other.UpdateEmailSent(ctx, accessKey, true))
+
+		fetched, err := repo.GetByAccessKey(ctx, accessKey)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReceiptAuthorized, fetched.SRIStatus)
+		assert.False(t, fetched.EmailSent)
+	})
+}
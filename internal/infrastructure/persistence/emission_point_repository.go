@@ -126,3 +126,73 @@ func (r *EmissionPointRepositoryImpl) IncrementSequence(ctx context.Context, id
 	}
 	return nil
 }
+
+// GetEstablishments devuelve los establecimientos adicionales del emisor ordenados por código.
+func (r *EmissionPointRepositoryImpl) GetEstablishments(ctx context.Context, issuerID int) ([]domain.Establishment, error) {
+	query := `
+		SELECT id, issuer_id, code, name, address, is_active, created_at, updated_at
+		FROM establishments
+		WHERE issuer_id = $1
+		ORDER BY code ASC
+	`
+	rows, err := r.db.Query(ctx, query, issuerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query establishments: %w", err)
+	}
+	defer rows.Close()
+
+	var results []domain.Establishment
+	for rows.Next() {
+		var e domain.Establishment
+		if err := rows.Scan(&e.ID, &e.IssuerID, &e.Code, &e.Name, &e.Address, &e.IsActive, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan establishment: %w", err)
+		}
+		results = append(results, e)
+	}
+	return results, rows.Err()
+}
+
+func (r *EmissionPointRepositoryImpl) GetEstablishment(ctx context.Context, issuerID int, code string) (*domain.Establishment, error) {
+	query := `
+		SELECT id, issuer_id, code, name, address, is_active, created_at, updated_at
+		FROM establishments
+		WHERE issuer_id = $1 AND code = $2
+	`
+	var e domain.Establishment
+	err := r.db.QueryRow(ctx, query, issuerID, code).Scan(
+		&e.ID, &e.IssuerID, &e.Code, &e.Name, &e.Address, &e.IsActive, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get establishment: %w", err)
+	}
+	return &e, nil
+}
+
+// SaveEstablishment crea el establecimiento o actualiza su nombre, dirección y estado; el código no
+// cambia porque ya forma parte de las claves de acceso emitidas.
+func (r *EmissionPointRepositoryImpl) SaveEstablishment(ctx context.Context, e *domain.Establishment) error {
+	now := time.Now()
+	if e.ID == 0 {
+		query := `
+			INSERT INTO establishments (issuer_id, code, name, address, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at, updated_at
+		`
+		err := r.db.QueryRow(ctx, query, e.IssuerID, e.Code, e.Name, e.Address, e.IsActive, now, now).
+			Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create establishment: %w", err)
+		}
+		return nil
+	}
+
+	query := `UPDATE establishments SET name = $1, address = $2, is_active = $3, updated_at = $4 WHERE id = $5`
+	if _, err := r.db.Exec(ctx, query, e.Name, e.Address, e.IsActive, now, e.ID); err != nil {
+		return fmt.Errorf("failed to update establishment: %w", err)
+	}
+	e.UpdatedAt = now
+	return nil
+}
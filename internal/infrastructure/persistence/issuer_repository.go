@@ -11,31 +11,37 @@ import (
 )
 
 type IssuerRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
-func NewIssuerRepository(db *pgxpool.Pool) *IssuerRepositoryImpl {
-	return &IssuerRepositoryImpl{db: db}
+func NewIssuerRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *IssuerRepositoryImpl {
+	return &IssuerRepositoryImpl{db: db, scope: scope}
 }
 
 func (r *IssuerRepositoryImpl) GetActive(ctx context.Context) (*domain.Issuer, error) {
-	// Buscamos el emisor que esté marcado como activo. Asumimos uno solo por instalación Desktop.
+	// Buscamos el emisor activo de la empresa seleccionada; cada empresa tiene uno solo.
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	var i domain.Issuer
 	safeQuery := `
 		SELECT id, ruc, business_name, COALESCE(trade_name, ''), main_address, establishment_address,
 		       establishment_code, emission_point_code, COALESCE(contribution_class, ''), COALESCE(withholding_agent, ''),
 		       COALESCE(rimpe_type, ''), environment, keep_accounting, signature_path, COALESCE(logo_path, ''), is_active, created_at, updated_at,
-		       smtp_server, smtp_port, smtp_user, smtp_password, smtp_ssl, COALESCE(default_tax_rate, 4)
+		       smtp_server, smtp_port, smtp_user, smtp_password, smtp_ssl, COALESCE(default_tax_rate, 4), COALESCE(default_tip_rate, 0)
 		FROM issuers
-		WHERE is_active = TRUE
+		WHERE is_active = TRUE AND company_id = $1
 		LIMIT 1
 	`
 
-	err := r.db.QueryRow(ctx, safeQuery).Scan(
+	err = r.db.QueryRow(ctx, safeQuery, companyID).Scan(
 		&i.ID, &i.RUC, &i.BusinessName, &i.TradeName, &i.MainAddress, &i.EstablishmentAddress,
 		&i.EstablishmentCode, &i.EmissionPointCode, &i.ContributionClass, &i.WithholdingAgent,
 		&i.RimpeType, &i.Environment, &i.KeepAccounting, &i.SignaturePath, &i.LogoPath, &i.IsActive, &i.CreatedAt, &i.UpdatedAt,
-		&i.SMTPServer, &i.SMTPPort, &i.SMTPUser, &i.SMTPPassword, &i.SMTPSSL, &i.DefaultTaxRate,
+		&i.SMTPServer, &i.SMTPPort, &i.SMTPUser, &i.SMTPPassword, &i.SMTPSSL, &i.DefaultTaxRate, &i.DefaultTipRate,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -44,52 +50,115 @@ func (r *IssuerRepositoryImpl) GetActive(ctx context.Context) (*domain.Issuer, e
 		return nil, fmt.Errorf("failed to get active issuer: %w", err)
 	}
 
+	rows, err := r.db.Query(ctx, `SELECT name, value FROM issuer_additional_fields WHERE issuer_id = $1 ORDER BY id ASC`, i.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issuer additional fields: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f domain.AdditionalField
+		if err := rows.Scan(&f.Name, &f.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan issuer additional field: %w", err)
+		}
+		i.AdditionalFields = append(i.AdditionalFields, f)
+	}
+
 	return &i, nil
 }
 
 func (r *IssuerRepositoryImpl) Create(ctx context.Context, issuer *domain.Issuer) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO issuers (
 			ruc, business_name, trade_name, main_address, establishment_address,
 			establishment_code, emission_point_code, contribution_class, withholding_agent,
 			rimpe_type, environment, keep_accounting, signature_path, logo_path, is_active, created_at, updated_at,
-			smtp_server, smtp_port, smtp_user, smtp_password, smtp_ssl, default_tax_rate
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+			smtp_server, smtp_port, smtp_user, smtp_password, smtp_ssl, default_tax_rate, default_tip_rate, company_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
 		RETURNING id, created_at, updated_at
 	`
+	dbTx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = dbTx.Rollback(ctx) }()
+
 	now := time.Now()
-	err := r.db.QueryRow(ctx, query,
+	err = dbTx.QueryRow(ctx, query,
 		issuer.RUC, issuer.BusinessName, issuer.TradeName, issuer.MainAddress, issuer.EstablishmentAddress,
 		issuer.EstablishmentCode, issuer.EmissionPointCode, issuer.ContributionClass, issuer.WithholdingAgent,
 		issuer.RimpeType, issuer.Environment, issuer.KeepAccounting, issuer.SignaturePath, issuer.LogoPath, issuer.IsActive, now, now,
-		issuer.SMTPServer, issuer.SMTPPort, issuer.SMTPUser, issuer.SMTPPassword, issuer.SMTPSSL, issuer.DefaultTaxRate,
+		issuer.SMTPServer, issuer.SMTPPort, issuer.SMTPUser, issuer.SMTPPassword, issuer.SMTPSSL, issuer.DefaultTaxRate, issuer.DefaultTipRate,
+		companyID,
 	).Scan(&issuer.ID, &issuer.CreatedAt, &issuer.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create issuer: %w", err)
 	}
-	return nil
+	if err := replaceIssuerAdditionalFields(ctx, dbTx, issuer.ID, issuer.AdditionalFields, now); err != nil {
+		return err
+	}
+	return dbTx.Commit(ctx)
 }
 
 func (r *IssuerRepositoryImpl) Update(ctx context.Context, issuer *domain.Issuer) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE issuers SET
 			ruc=$1, business_name=$2, trade_name=$3, main_address=$4, establishment_address=$5,
 			establishment_code=$6, emission_point_code=$7, contribution_class=$8, withholding_agent=$9,
 			rimpe_type=$10, environment=$11, signature_path=$12, logo_path=$13, updated_at=$14,
-			smtp_server=$15, smtp_port=$16, smtp_user=$17, smtp_password=$18, smtp_ssl=$19, default_tax_rate=$20
-		WHERE id=$21
+			smtp_server=$15, smtp_port=$16, smtp_user=$17, smtp_password=$18, smtp_ssl=$19, default_tax_rate=$20, default_tip_rate=$21
+		WHERE id=$22 AND company_id=$23
 	`
+	dbTx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = dbTx.Rollback(ctx) }()
+
 	now := time.Now()
-	_, err := r.db.Exec(ctx, query,
+	_, err = dbTx.Exec(ctx, query,
 		issuer.RUC, issuer.BusinessName, issuer.TradeName, issuer.MainAddress, issuer.EstablishmentAddress,
 		issuer.EstablishmentCode, issuer.EmissionPointCode, issuer.ContributionClass, issuer.WithholdingAgent,
 		issuer.RimpeType, issuer.Environment, issuer.SignaturePath, issuer.LogoPath, now,
-		issuer.SMTPServer, issuer.SMTPPort, issuer.SMTPUser, issuer.SMTPPassword, issuer.SMTPSSL, issuer.DefaultTaxRate,
-		issuer.ID,
+		issuer.SMTPServer, issuer.SMTPPort, issuer.SMTPUser, issuer.SMTPPassword, issuer.SMTPSSL, issuer.DefaultTaxRate, issuer.DefaultTipRate,
+		issuer.ID, companyID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update issuer: %w", err)
 	}
+	if err := replaceIssuerAdditionalFields(ctx, dbTx, issuer.ID, issuer.AdditionalFields, now); err != nil {
+		return err
+	}
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit issuer update: %w", err)
+	}
 	issuer.UpdatedAt = now
 	return nil
 }
+
+// replaceIssuerAdditionalFields reemplaza los campos adicionales por defecto del emisor.
+func replaceIssuerAdditionalFields(ctx context.Context, dbTx pgx.Tx, issuerID int, fields []domain.AdditionalField, now time.Time) error {
+	if _, err := dbTx.Exec(ctx, `DELETE FROM issuer_additional_fields WHERE issuer_id = $1`, issuerID); err != nil {
+		return fmt.Errorf("failed to clear issuer additional fields: %w", err)
+	}
+	for _, f := range fields {
+		_, err := dbTx.Exec(ctx, `
+			INSERT INTO issuer_additional_fields (issuer_id, name, value, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)`,
+			issuerID, f.Name, f.Value, now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create issuer additional field: %w", err)
+		}
+	}
+	return nil
+}
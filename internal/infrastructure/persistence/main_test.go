@@ -29,6 +29,9 @@ var (
 	testUser          *domain.User  // Global test user
 )
 
+// testScope points the repositories at the company seeded by the migrations.
+var testScope = domain.NewCompanyScope(1)
+
 // TestMain is the entry point for all tests in this package.
 func TestMain(m *testing.M) {
 	// --- Setup: Start the PostgreSQL container ---
@@ -92,11 +95,11 @@ func TestMain(m *testing.M) {
 	}
 
 	// Create the repository instance that all tests will use.
-	testRepo = NewAccountRepository(dbPool)
-	testCatRepo = NewCategoryRepository(dbPool)
-	testReportRepo = NewReportRepository(dbPool)
+	testRepo = NewAccountRepository(dbPool, testScope)
+	testCatRepo = NewCategoryRepository(dbPool, testScope)
+	testReportRepo = NewReportRepository(dbPool, testScope)
 	testUserRepo = NewUserRepository(dbPool)
-	testRecurringRepo = NewRecurringTransactionRepository(dbPool)
+	testRecurringRepo = NewRecurringTransactionRepository(dbPool, testScope)
 
 	// --- Run the tests ---
 	code := m.Run()
@@ -111,7 +114,7 @@ func TestMain(m *testing.M) {
 
 // truncateTables cleans the database tables between test runs for isolation.
 func truncateTables(t *testing.T) {
-	_, err := dbPool.Exec(context.Background(), "TRUNCATE TABLE accounts, categories, transactions, users, tax_payers, issuers, emission_points, electronic_receipts, transaction_items, recurring_transactions RESTART IDENTITY CASCADE")
+	_, err := dbPool.Exec(context.Background(), "TRUNCATE TABLE accounts, categories, transactions, users, tax_payers, issuers, emission_points, electronic_receipts, electronic_receipt_events, transaction_items, recurring_transactions, shipments, shipment_recipients, shipment_items RESTART IDENTITY CASCADE")
 	if err != nil {
 		t.Fatalf("Failed to truncate tables: %v", err)
 	}
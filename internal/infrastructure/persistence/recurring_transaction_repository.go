@@ -10,22 +10,27 @@ import (
 )
 
 type RecurringTransactionRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
-func NewRecurringTransactionRepository(db *pgxpool.Pool) *RecurringTransactionRepositoryImpl {
-	return &RecurringTransactionRepositoryImpl{db: db}
+func NewRecurringTransactionRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *RecurringTransactionRepositoryImpl {
+	return &RecurringTransactionRepositoryImpl{db: db, scope: scope}
 }
 
 func (r *RecurringTransactionRepositoryImpl) Create(ctx context.Context, rt *domain.RecurringTransaction) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
 	query := `
 		INSERT INTO recurring_transactions 
-		(description, amount, account_id, category_id, interval, start_date, next_run_date, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		(description, amount, account_id, category_id, interval, start_date, next_run_date, is_active, created_at, updated_at, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at, updated_at
 	`
 	now := time.Now()
-	err := r.db.QueryRow(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		rt.Description,
 		rt.Amount,
 		rt.AccountID,
@@ -36,6 +41,7 @@ func (r *RecurringTransactionRepositoryImpl) Create(ctx context.Context, rt *dom
 		rt.IsActive,
 		now,
 		now,
+		companyID,
 	).Scan(&rt.ID, &rt.CreatedAt, &rt.UpdatedAt)
 
 	if err != nil {
@@ -45,12 +51,17 @@ func (r *RecurringTransactionRepositoryImpl) Create(ctx context.Context, rt *dom
 }
 
 func (r *RecurringTransactionRepositoryImpl) GetAll(ctx context.Context) ([]domain.RecurringTransaction, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
 	query := `
 		SELECT id, description, amount, account_id, category_id, interval, start_date, next_run_date, is_active
 		FROM recurring_transactions
+		WHERE company_id = $1
 		ORDER BY id ASC
 	`
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active recurring transactions: %w", err)
 	}
@@ -72,12 +83,16 @@ func (r *RecurringTransactionRepositoryImpl) GetAll(ctx context.Context) ([]doma
 }
 
 func (r *RecurringTransactionRepositoryImpl) GetAllActive(ctx context.Context) ([]domain.RecurringTransaction, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
 	query := `
 		SELECT id, description, amount, account_id, category_id, interval, start_date, next_run_date, is_active
 		FROM recurring_transactions
-		WHERE is_active = TRUE
+		WHERE is_active = TRUE AND company_id = $1
 	`
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active recurring transactions: %w", err)
 	}
@@ -99,12 +114,16 @@ func (r *RecurringTransactionRepositoryImpl) GetAllActive(ctx context.Context) (
 }
 
 func (r *RecurringTransactionRepositoryImpl) Update(ctx context.Context, rt *domain.RecurringTransaction) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
 	query := `
 		UPDATE recurring_transactions
 		SET description = $1, amount = $2, interval = $3, start_date = $4, next_run_date = $5, is_active = $6, updated_at = $7
-		WHERE id = $8
+		WHERE id = $8 AND company_id = $9
 	`
-	_, err := r.db.Exec(ctx, query,
+	_, err = r.db.Exec(ctx, query,
 		rt.Description,
 		rt.Amount,
 		rt.Interval,
@@ -113,6 +132,7 @@ func (r *RecurringTransactionRepositoryImpl) Update(ctx context.Context, rt *dom
 		rt.IsActive,
 		time.Now(),
 		rt.ID,
+		companyID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update recurring transaction: %w", err)
@@ -121,7 +141,11 @@ func (r *RecurringTransactionRepositoryImpl) Update(ctx context.Context, rt *dom
 }
 
 func (r *RecurringTransactionRepositoryImpl) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM recurring_transactions WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+	query := `DELETE FROM recurring_transactions WHERE id = $1 AND company_id = $2`
+	_, err = r.db.Exec(ctx, query, id, companyID)
 	return err
 }
\ No newline at end of file
@@ -13,37 +13,44 @@ import (
 
 // ReportRepositoryImpl implements the ReportRepository interface for generating financial reports.
 type ReportRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
 // NewReportRepository creates a new instance of ReportRepositoryImpl with the provided database connection pool.
-func NewReportRepository(db *pgxpool.Pool) *ReportRepositoryImpl {
-	return &ReportRepositoryImpl{db: db}
+func NewReportRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *ReportRepositoryImpl {
+	return &ReportRepositoryImpl{db: db, scope: scope}
 }
 
 // GetFinancialSummary retrieves a financial summary report for the specified date range, optionally filtered by account ID.
 func (r *ReportRepositoryImpl) GetFinancialSummary(ctx context.Context, startDate, endDate time.Time, accountID *int) (domain.FinancialSummary, error) {
 	var summary domain.FinancialSummary
 
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return summary, err
+	}
+
 	query := `
 	SELECT
 		COALESCE(SUM(CASE WHEN c.type = 'Ingreso' THEN t.amount ELSE 0 END), 0) AS total_income,
-		COALESCE(SUM(CASE WHEN c.type = 'Egreso' THEN t.amount ELSE 0 END), 0) AS total_expenses
+		COALESCE(SUM(CASE WHEN c.type = 'Egreso' THEN t.amount ELSE 0 END), 0) AS total_expenses,
+		COALESCE(SUM(CASE WHEN c.type = 'Ingreso' AND NOT t.is_voided THEN t.tip ELSE 0 END), 0) AS total_tips
 	FROM
 		transactions t
 	JOIN
 		categories c ON t.category_id = c.id
 	WHERE
-		t.transaction_date >= $1 AND t.transaction_date <= $2`
+		t.transaction_date >= $1 AND t.transaction_date <= $2 AND t.company_id = $3`
 
-	args := []interface{}{startDate, endDate}
+	args := []interface{}{startDate, endDate, companyID}
 
 	if accountID != nil {
 		query += fmt.Sprintf(" AND t.account_id = $%d", len(args)+1)
 		args = append(args, *accountID)
 	}
 
-	err := r.db.QueryRow(ctx, query, args...).Scan(&summary.TotalIncome, &summary.TotalExpenses)
+	err = r.db.QueryRow(ctx, query, args...).Scan(&summary.TotalIncome, &summary.TotalExpenses, &summary.TotalTips)
 	if err != nil {
 		return summary, fmt.Errorf("failed to get financial summary: %w", err)
 	}
@@ -54,20 +61,25 @@ func (r *ReportRepositoryImpl) GetFinancialSummary(ctx context.Context, startDat
 }
 
 func (r *ReportRepositoryImpl) GetReconciliation(ctx context.Context, accountID int, startDate, endDate time.Time) (*domain.Reconciliation, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 	WITH initial_balance AS (
 		SELECT 
-			(SELECT initial_balance FROM accounts WHERE id = $1) + 
+			(SELECT initial_balance FROM accounts WHERE id = $1 AND company_id = $4) + 
 			COALESCE(SUM(CASE WHEN c.type = 'Ingreso' THEN t.amount ELSE -t.amount END), 0) as balance
 		FROM transactions t
 		JOIN categories c ON c.id = t.category_id
-		WHERE t.account_id = $1 AND t.transaction_date < $2
+		WHERE t.account_id = $1 AND t.transaction_date < $2 AND t.company_id = $4
 	),
 	transactions_in_period AS (
 		SELECT t.*, c.name as category_name, c.type as category_type
 		FROM transactions t
 		JOIN categories c ON c.id = t.category_id
-		WHERE t.account_id = $1 AND t.transaction_date >= $2 AND t.transaction_date <= $3
+		WHERE t.account_id = $1 AND t.transaction_date >= $2 AND t.transaction_date <= $3 AND t.company_id = $4
 	)
 	SELECT 
 		ib.balance as starting_balance,
@@ -78,7 +90,7 @@ func (r *ReportRepositoryImpl) GetReconciliation(ctx context.Context, accountID
 	GROUP BY ib.balance
 	`
 
-	rows, err := r.db.Query(ctx, query, accountID, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, accountID, startDate, endDate, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query for reconciliation: %w", err)
 	}
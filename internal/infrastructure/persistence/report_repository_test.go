@@ -15,10 +15,10 @@ import (
 
 func TestGetFinancialSummary(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
-	reportRepo := NewReportRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
+	reportRepo := NewReportRepository(dbPool, testScope)
 	ctx := context.Background()
 
 	// --- Test Data Setup ---
@@ -70,10 +70,10 @@ func TestGetFinancialSummary(t *testing.T) {
 
 func TestGetReconciliation(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
-	reportRepo := NewReportRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
+	reportRepo := NewReportRepository(dbPool, testScope)
 	ctx := context.Background()
 
 	// --- Test Data Setup ---
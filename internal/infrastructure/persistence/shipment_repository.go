@@ -0,0 +1,245 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+type ShipmentRepositoryImpl struct {
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
+}
+
+func NewShipmentRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *ShipmentRepositoryImpl {
+	return &ShipmentRepositoryImpl{db: db, scope: scope}
+}
+
+// Create guarda el envío con sus destinatarios e ítems en una sola transacción.
+func (r *ShipmentRepositoryImpl) Create(ctx context.Context, s *domain.Shipment) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	now := time.Now()
+	err = tx.QueryRow(ctx, `
+		INSERT INTO shipments (transaction_id, carrier_id, plate, departure_address, start_date, end_date, created_by_id, created_at, updated_at, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`,
+		s.TransactionID, s.CarrierID, s.Plate, s.DepartureAddress, s.StartDate, s.EndDate, s.CreatedByID, now, now, companyID,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create shipment: %w", err)
+	}
+
+	for i := range s.Recipients {
+		rcp := &s.Recipients[i]
+		rcp.ShipmentID = s.ID
+		err = tx.QueryRow(ctx, `
+			INSERT INTO shipment_recipients (shipment_id, tax_payer_id, arrival_address, reason, route,
+			                                 support_doc_number, support_doc_date, support_doc_auth_key, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id`,
+			rcp.ShipmentID, rcp.TaxPayerID, rcp.ArrivalAddress, rcp.Reason, rcp.Route,
+			rcp.SupportDocNumber, rcp.SupportDocDate, rcp.SupportDocAuthKey, now, now,
+		).Scan(&rcp.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create shipment recipient: %w", err)
+		}
+
+		for j := range rcp.Items {
+			item := &rcp.Items[j]
+			item.RecipientID = rcp.ID
+			err = tx.QueryRow(ctx, `
+				INSERT INTO shipment_items (recipient_id, code, description, quantity, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+				item.RecipientID, item.Code, item.Description, item.Quantity, now, now,
+			).Scan(&item.ID)
+			if err != nil {
+				return fmt.Errorf("failed to create shipment item: %w", err)
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByID carga el envío completo: transportista, destinatarios, ítems y su último comprobante.
+func (r *ShipmentRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Shipment, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, shipmentSelect+` WHERE s.id = $1 AND s.company_id = $2`, id, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shipment: %w", err)
+	}
+	shipments, err := scanShipments(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(shipments) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	s := &shipments[0]
+
+	recipients, err := r.getRecipients(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.Recipients = recipients
+	return s, nil
+}
+
+// GetAll lista los envíos más recientes primero (sin destinatarios).
+func (r *ShipmentRepositoryImpl) GetAll(ctx context.Context) ([]domain.Shipment, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, shipmentSelect+` WHERE s.company_id = $1 ORDER BY s.start_date DESC, s.id DESC`, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shipments: %w", err)
+	}
+	return scanShipments(rows)
+}
+
+const shipmentSelect = `
+	SELECT s.id, s.transaction_id, s.carrier_id, s.plate, s.departure_address, s.start_date, s.end_date,
+	       s.created_by_id, s.created_at, s.updated_at,
+	       c.identification, c.identification_type, c.name, c.email,
+	       er.sri_status, er.access_key, er.authorization_date, er.created_at, er.email_sent
+	FROM shipments s
+	JOIN tax_payers c ON s.carrier_id = c.id
+	LEFT JOIN (
+	    SELECT DISTINCT ON (shipment_id)
+	        shipment_id, sri_status, access_key, authorization_date, created_at, email_sent
+	    FROM electronic_receipts
+	    WHERE shipment_id IS NOT NULL
+	    ORDER BY shipment_id, created_at DESC
+	) AS er ON s.id = er.shipment_id`
+
+func scanShipments(rows pgx.Rows) ([]domain.Shipment, error) {
+	defer rows.Close()
+
+	shipments := make([]domain.Shipment, 0)
+	for rows.Next() {
+		var s domain.Shipment
+		carrier := &domain.TaxPayer{}
+		var sriStatus, accessKey sql.NullString
+		var authDate, receiptCreatedAt sql.NullTime
+		var emailSent sql.NullBool
+
+		err := rows.Scan(
+			&s.ID, &s.TransactionID, &s.CarrierID, &s.Plate, &s.DepartureAddress, &s.StartDate, &s.EndDate,
+			&s.CreatedByID, &s.CreatedAt, &s.UpdatedAt,
+			&carrier.Identification, &carrier.IdentificationType, &carrier.Name, &carrier.Email,
+			&sriStatus, &accessKey, &authDate, &receiptCreatedAt, &emailSent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shipment: %w", err)
+		}
+		carrier.ID = s.CarrierID
+		s.Carrier = carrier
+
+		if accessKey.Valid {
+			shipmentID := s.ID
+			s.ElectronicReceipt = &domain.ElectronicReceipt{
+				ShipmentID:  &shipmentID,
+				SRIStatus:   domain.ReceiptStatus(sriStatus.String),
+				AccessKey:   accessKey.String,
+				ReceiptType: "06",
+				EmailSent:   emailSent.Bool,
+			}
+			s.ElectronicReceipt.CreatedAt = receiptCreatedAt.Time
+			if authDate.Valid {
+				s.ElectronicReceipt.AuthorizationDate = &authDate.Time
+			}
+		}
+		shipments = append(shipments, s)
+	}
+	return shipments, rows.Err()
+}
+
+func (r *ShipmentRepositoryImpl) getRecipients(ctx context.Context, shipmentID int) ([]domain.ShipmentRecipient, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT sr.id, sr.shipment_id, sr.tax_payer_id, sr.arrival_address, sr.reason, COALESCE(sr.route, ''),
+		       COALESCE(sr.support_doc_number, ''), sr.support_doc_date, COALESCE(sr.support_doc_auth_key, ''),
+		       sr.created_at, sr.updated_at,
+		       tp.identification, tp.identification_type, tp.name, tp.email
+		FROM shipment_recipients sr
+		JOIN tax_payers tp ON sr.tax_payer_id = tp.id
+		WHERE sr.shipment_id = $1
+		ORDER BY sr.id ASC`, shipmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shipment recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []domain.ShipmentRecipient
+	for rows.Next() {
+		var rcp domain.ShipmentRecipient
+		tp := &domain.TaxPayer{}
+		err := rows.Scan(
+			&rcp.ID, &rcp.ShipmentID, &rcp.TaxPayerID, &rcp.ArrivalAddress, &rcp.Reason, &rcp.Route,
+			&rcp.SupportDocNumber, &rcp.SupportDocDate, &rcp.SupportDocAuthKey,
+			&rcp.CreatedAt, &rcp.UpdatedAt,
+			&tp.Identification, &tp.IdentificationType, &tp.Name, &tp.Email,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shipment recipient: %w", err)
+		}
+		tp.ID = rcp.TaxPayerID
+		rcp.TaxPayer = tp
+		recipients = append(recipients, rcp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range recipients {
+		items, err := r.getItems(ctx, recipients[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		recipients[i].Items = items
+	}
+	return recipients, nil
+}
+
+func (r *ShipmentRepositoryImpl) getItems(ctx context.Context, recipientID int) ([]domain.ShipmentItem, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, recipient_id, COALESCE(code, ''), description, quantity, created_at, updated_at
+		FROM shipment_items
+		WHERE recipient_id = $1
+		ORDER BY id ASC`, recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shipment items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []domain.ShipmentItem
+	for rows.Next() {
+		var item domain.ShipmentItem
+		if err := rows.Scan(&item.ID, &item.RecipientID, &item.Code, &item.Description, &item.Quantity, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan shipment item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,78 @@
+//go:build integration
+
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShipmentRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	truncateTables(t)
+
+	user := createTestUser(t, testUserRepo, "bodeguero", domain.RoleAdmin)
+	tpRepo := NewTaxPayerRepository(dbPool, testScope)
+	repo := NewShipmentRepository(dbPool, testScope)
+
+	carrier := &domain.TaxPayer{Identification: "1790011122001", IdentificationType: "04", Name: "Transportes Andinos", Email: "t@andinos.com"}
+	require.NoError(t, tpRepo.Create(ctx, carrier))
+	customer := &domain.TaxPayer{Identification: "1712345678", IdentificationType: "05", Name: "Cliente Destino", Email: "c@destino.com"}
+	require.NoError(t, tpRepo.Create(ctx, customer))
+
+	docDate := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	shipment := &domain.Shipment{
+		CarrierID:        carrier.ID,
+		Plate:            "PBA-1234",
+		DepartureAddress: "Bodega Norte",
+		StartDate:        time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		EndDate:          time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+		CreatedByID:      user.ID,
+		Recipients: []domain.ShipmentRecipient{
+			{
+				TaxPayerID:       customer.ID,
+				ArrivalAddress:   "Av. Principal 123",
+				Reason:           "Venta",
+				SupportDocNumber: "001-001-000000010",
+				SupportDocDate:   &docDate,
+				Items: []domain.ShipmentItem{
+					{Code: "P-1", Description: "Cemento", Quantity: 10},
+					{Description: "Varilla", Quantity: 25.5},
+				},
+			},
+		},
+	}
+
+	t.Run("Create and GetByID", func(t *testing.T) {
+		require.NoError(t, repo.Create(ctx, shipment))
+		assert.NotZero(t, shipment.ID)
+		assert.NotZero(t, shipment.Recipients[0].ID)
+
+		fetched, err := repo.GetByID(ctx, shipment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "PBA-1234", fetched.Plate)
+		assert.Equal(t, "Transportes Andinos", fetched.Carrier.Name)
+		assert.Nil(t, fetched.ElectronicReceipt)
+		require.Len(t, fetched.Recipients, 1)
+		assert.Equal(t, "Cliente Destino", fetched.Recipients[0].TaxPayer.Name)
+		assert.Equal(t, "001-001-000000010", fetched.Recipients[0].SupportDocNumber)
+		require.Len(t, fetched.Recipients[0].Items, 2)
+		assert.Equal(t, 25.5, fetched.Recipients[0].Items[1].Quantity)
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		all, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, shipment.ID, all[0].ID)
+	})
+}
@@ -11,21 +11,27 @@ import (
 )
 
 type TaxPayerRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
-func NewTaxPayerRepository(db *pgxpool.Pool) *TaxPayerRepositoryImpl {
-	return &TaxPayerRepositoryImpl{db: db}
+func NewTaxPayerRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *TaxPayerRepositoryImpl {
+	return &TaxPayerRepositoryImpl{db: db, scope: scope}
 }
 
 func (r *TaxPayerRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.TaxPayer, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT id, identification, identification_type, name, email, COALESCE(address, ''), COALESCE(phone, ''), created_at, updated_at
 		FROM tax_payers
-		WHERE id = $1
+		WHERE id = $1 AND company_id = $2
 	`
 	var tp domain.TaxPayer
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err = r.db.QueryRow(ctx, query, id, companyID).Scan(
 		&tp.ID, &tp.Identification, &tp.IdentificationType, &tp.Name, &tp.Email, &tp.Address, &tp.Phone, &tp.CreatedAt, &tp.UpdatedAt,
 	)
 	if err != nil {
@@ -38,13 +44,18 @@ func (r *TaxPayerRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.T
 }
 
 func (r *TaxPayerRepositoryImpl) GetByIdentification(ctx context.Context, identification string) (*domain.TaxPayer, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT id, identification, identification_type, name, email, COALESCE(address, ''), COALESCE(phone, ''), created_at, updated_at
 		FROM tax_payers
-		WHERE identification = $1
+		WHERE identification = $1 AND company_id = $2
 	`
 	var tp domain.TaxPayer
-	err := r.db.QueryRow(ctx, query, identification).Scan(
+	err = r.db.QueryRow(ctx, query, identification, companyID).Scan(
 		&tp.ID, &tp.Identification, &tp.IdentificationType, &tp.Name, &tp.Email, &tp.Address, &tp.Phone, &tp.CreatedAt, &tp.UpdatedAt,
 	)
 	if err != nil {
@@ -57,14 +68,19 @@ func (r *TaxPayerRepositoryImpl) GetByIdentification(ctx context.Context, identi
 }
 
 func (r *TaxPayerRepositoryImpl) Create(ctx context.Context, tp *domain.TaxPayer) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO tax_payers (identification, identification_type, name, email, address, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO tax_payers (identification, identification_type, name, email, address, phone, created_at, updated_at, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 	`
 	now := time.Now()
-	err := r.db.QueryRow(ctx, query,
-		tp.Identification, tp.IdentificationType, tp.Name, tp.Email, tp.Address, tp.Phone, now, now,
+	err = r.db.QueryRow(ctx, query,
+		tp.Identification, tp.IdentificationType, tp.Name, tp.Email, tp.Address, tp.Phone, now, now, companyID,
 	).Scan(&tp.ID, &tp.CreatedAt, &tp.UpdatedAt)
 
 	if err != nil {
@@ -74,13 +90,18 @@ func (r *TaxPayerRepositoryImpl) Create(ctx context.Context, tp *domain.TaxPayer
 }
 
 func (r *TaxPayerRepositoryImpl) Update(ctx context.Context, tp *domain.TaxPayer) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE tax_payers SET
 			name=$1, email=$2, address=$3, phone=$4, identification_type=$5, updated_at=$6
-		WHERE id=$7
+		WHERE id=$7 AND company_id=$8
 	`
 	now := time.Now()
-	_, err := r.db.Exec(ctx, query, tp.Name, tp.Email, tp.Address, tp.Phone, tp.IdentificationType, now, tp.ID)
+	_, err = r.db.Exec(ctx, query, tp.Name, tp.Email, tp.Address, tp.Phone, tp.IdentificationType, now, tp.ID, companyID)
 	if err != nil {
 		return fmt.Errorf("failed to update taxpayer: %w", err)
 	}
@@ -89,12 +110,18 @@ func (r *TaxPayerRepositoryImpl) Update(ctx context.Context, tp *domain.TaxPayer
 }
 
 func (r *TaxPayerRepositoryImpl) GetAll(ctx context.Context) ([]domain.TaxPayer, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT id, identification, identification_type, name, email, COALESCE(address, ''), COALESCE(phone, ''), created_at, updated_at
 		FROM tax_payers
+		WHERE company_id = $1
 		ORDER BY name ASC
 	`
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list taxpayers: %w", err)
 	}
@@ -112,13 +139,17 @@ func (r *TaxPayerRepositoryImpl) GetAll(ctx context.Context) ([]domain.TaxPayer,
 }
 
 func (r *TaxPayerRepositoryImpl) GetPaginated(ctx context.Context, page, pageSize int, search string) (*domain.PaginatedResult[domain.TaxPayer], error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
 	offset := (page - 1) * pageSize
 	searchPattern := "%" + search + "%"
 
 	// 1. Count Total
-	countQuery := `SELECT COUNT(*) FROM tax_payers WHERE name ILIKE $1 OR identification ILIKE $1`
+	countQuery := `SELECT COUNT(*) FROM tax_payers WHERE (name ILIKE $1 OR identification ILIKE $1) AND company_id = $2`
 	var total int64
-	err := r.db.QueryRow(ctx, countQuery, searchPattern).Scan(&total)
+	err = r.db.QueryRow(ctx, countQuery, searchPattern, companyID).Scan(&total)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count taxpayers: %w", err)
 	}
@@ -127,11 +158,11 @@ func (r *TaxPayerRepositoryImpl) GetPaginated(ctx context.Context, page, pageSiz
 	query := `
 		SELECT id, identification, identification_type, name, email, COALESCE(address, ''), COALESCE(phone, ''), created_at, updated_at
 		FROM tax_payers
-		WHERE name ILIKE $1 OR identification ILIKE $1
+		WHERE (name ILIKE $1 OR identification ILIKE $1) AND company_id = $4
 		ORDER BY name ASC
 		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.Query(ctx, query, searchPattern, pageSize, offset)
+	rows, err := r.db.Query(ctx, query, searchPattern, pageSize, offset, companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list taxpayers paginated: %w", err)
 	}
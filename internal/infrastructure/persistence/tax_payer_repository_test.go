@@ -17,7 +17,7 @@ func TestTaxPayerRepository(t *testing.T) {
 	}
 
 	// Use global dbPool initialized in TestMain
-	repo := NewTaxPayerRepository(dbPool)
+	repo := NewTaxPayerRepository(dbPool, testScope)
 	ctx := context.Background()
 	
 	// Clean slate
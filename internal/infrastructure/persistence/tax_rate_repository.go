@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+// TaxRateRepositoryImpl lee el catálogo de tarifas de IVA con sus vigencias.
+type TaxRateRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+func NewTaxRateRepository(db *pgxpool.Pool) *TaxRateRepositoryImpl {
+	return &TaxRateRepositoryImpl{db: db}
+}
+
+// GetAll devuelve todas las tarifas, incluidas las que ya no están vigentes, para poder resolver
+// el porcentaje de comprobantes de fechas pasadas.
+func (r *TaxRateRepositoryImpl) GetAll(ctx context.Context) (domain.TaxRateTable, error) {
+	query := `
+		SELECT id, code, percentage, description, is_general, valid_from, valid_to, created_at, updated_at
+		FROM tax_rates
+		ORDER BY code, valid_from
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tax rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates domain.TaxRateTable
+	for rows.Next() {
+		var rate domain.TaxRate
+		if err := rows.Scan(
+			&rate.ID, &rate.Code, &rate.Percentage, &rate.Description, &rate.General,
+			&rate.ValidFrom, &rate.ValidTo, &rate.CreatedAt, &rate.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tax rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
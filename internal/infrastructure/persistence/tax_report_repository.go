@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+// TaxReportRepositoryImpl lee los comprobantes de un período para los anexos tributarios (ATS).
+type TaxReportRepositoryImpl struct {
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
+}
+
+func NewTaxReportRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *TaxReportRepositoryImpl {
+	return &TaxReportRepositoryImpl{db: db, scope: scope}
+}
+
+// GetSaleDocuments devuelve las facturas, notas de crédito y notas de débito autorizadas cuya
+// transacción cae en el período, incluidas las anuladas en el sistema (la factura y su nota de
+// crédito se reportan ambas) y las anuladas en el portal del SRI.
+func (r *TaxReportRepositoryImpl) GetSaleDocuments(ctx context.Context, startDate, endDate time.Time) ([]domain.TaxSaleDocument, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT er.access_key, er.receipt_type, er.sri_status, t.id, t.transaction_date,
+		       COALESCE(tp.identification, '9999999999999'), COALESCE(tp.identification_type, '07'),
+		       COALESCE(tp.name, 'CONSUMIDOR FINAL'),
+		       t.subtotal_15, t.subtotal_0, t.tax_amount, t.ice_amount, t.amount,
+		       COALESCE((SELECT array_agg(DISTINCT p.payment_method ORDER BY p.payment_method)
+		                 FROM transaction_payments p WHERE p.transaction_id = t.id), '{}')
+		FROM electronic_receipts er
+		JOIN transactions t ON t.id = er.transaction_id
+		LEFT JOIN tax_payers tp ON tp.id = er.tax_payer_id
+		WHERE er.receipt_type IN ('01', '04', '05')
+		  AND er.sri_status IN ('AUTORIZADO', 'ANULADO')
+		  AND t.transaction_date >= $1 AND t.transaction_date <= $2
+		  AND t.company_id = $3
+		ORDER BY t.transaction_date, er.access_key
+	`
+	rows, err := r.db.Query(ctx, query, startDate, endDate, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sale documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []domain.TaxSaleDocument
+	for rows.Next() {
+		var d domain.TaxSaleDocument
+		if err := rows.Scan(
+			&d.AccessKey, &d.ReceiptType, &d.SRIStatus, &d.TransactionID, &d.TransactionDate,
+			&d.CustomerIdentification, &d.CustomerIdentificationType, &d.CustomerName,
+			&d.Subtotal15, &d.Subtotal0, &d.TaxAmount, &d.ICEAmount, &d.Amount, &d.PaymentMethods,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan sale document: %w", err)
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// GetPurchaseDocuments devuelve los egresos vigentes del período que tienen un comprobante de compra:
+// la factura importada del proveedor o una liquidación de compra (03) autorizada. Se adjunta la
+// retención (07) autorizada sobre cada compra para reportar los valores retenidos.
+func (r *TaxReportRepositoryImpl) GetPurchaseDocuments(ctx context.Context, startDate, endDate time.Time) ([]domain.TaxPurchaseDocument, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT t.id, t.transaction_date, tp.identification, tp.identification_type, tp.name,
+		       COALESCE(t.supplier_access_key, ls.access_key),
+		       t.subtotal_15, t.subtotal_0, t.tax_amount, t.ice_amount, t.amount,
+		       COALESCE(ret.access_key, ''), COALESCE(ret.xml_content, '')
+		FROM transactions t
+		JOIN categories c ON c.id = t.category_id
+		JOIN tax_payers tp ON tp.id = t.tax_payer_id
+		LEFT JOIN LATERAL (
+			SELECT access_key FROM electronic_receipts
+			WHERE transaction_id = t.id AND receipt_type = '03' AND sri_status = 'AUTORIZADO'
+			ORDER BY id DESC LIMIT 1
+		) ls ON true
+		LEFT JOIN LATERAL (
+			SELECT access_key, xml_content FROM electronic_receipts
+			WHERE transaction_id = t.id AND receipt_type = '07' AND sri_status = 'AUTORIZADO'
+			ORDER BY id DESC LIMIT 1
+		) ret ON true
+		WHERE c.type = 'Egreso'
+		  AND NOT t.is_voided AND t.voids_transaction_id IS NULL
+		  AND (t.supplier_access_key IS NOT NULL OR ls.access_key IS NOT NULL)
+		  AND t.transaction_date >= $1 AND t.transaction_date <= $2
+		  AND t.company_id = $3
+		ORDER BY t.transaction_date, t.id
+	`
+	rows, err := r.db.Query(ctx, query, startDate, endDate, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purchase documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []domain.TaxPurchaseDocument
+	for rows.Next() {
+		var d domain.TaxPurchaseDocument
+		if err := rows.Scan(
+			&d.TransactionID, &d.TransactionDate, &d.SupplierIdentification, &d.SupplierIdentificationType, &d.SupplierName,
+			&d.AccessKey, &d.Subtotal15, &d.Subtotal0, &d.TaxAmount, &d.ICEAmount, &d.Amount,
+			&d.WithholdingAccessKey, &d.WithholdingXML,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase document: %w", err)
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
@@ -14,14 +14,20 @@ import (
 )
 
 type TransactionRepositoryImpl struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	scope *domain.CompanyScope
 }
 
-func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepositoryImpl {
-	return &TransactionRepositoryImpl{db: db}
+func NewTransactionRepository(db *pgxpool.Pool, scope *domain.CompanyScope) *TransactionRepositoryImpl {
+	return &TransactionRepositoryImpl{db: db, scope: scope}
 }
 
 func (r *TransactionRepositoryImpl) CreateTransaction(ctx context.Context, transaction *domain.Transaction) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -29,13 +35,16 @@ func (r *TransactionRepositoryImpl) CreateTransaction(ctx context.Context, trans
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	var cat domain.Category
-	err = tx.QueryRow(ctx, "SELECT name, type FROM categories WHERE id = $1", transaction.CategoryID).
+	err = tx.QueryRow(ctx, "SELECT name, type FROM categories WHERE id = $1 AND company_id = $2", transaction.CategoryID, companyID).
 		Scan(&cat.Name, &cat.Type)
 	if err != nil {
 		return err
 	}
+	if err := checkAccountCompany(ctx, tx, transaction.AccountID, companyID); err != nil {
+		return err
+	}
 
-	newTxNumber, err := r.generateTransactionNumber(ctx, tx, cat.Type, cat.Name, transaction.TransactionDate)
+	newTxNumber, err := r.generateTransactionNumber(ctx, tx, companyID, cat.Type, cat.Name, transaction.TransactionDate)
 	if err != nil {
 		return fmt.Errorf("failed to generate transaction number: %w", err)
 	}
@@ -45,8 +54,9 @@ func (r *TransactionRepositoryImpl) CreateTransaction(ctx context.Context, trans
 	query := `
 		insert into transactions (transaction_number, description, amount, transaction_date, account_id, category_id, 
 		                          attachment_path, created_by_id, updated_by_id, created_at, updated_at,
-		                          subtotal_15, subtotal_0, tax_amount, tax_payer_id)
-				 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		                          subtotal_15, subtotal_0, tax_amount, tax_payer_id, related_transaction_id, discount, tip,
+		                          supplier_access_key, ice_amount, irbpnr_amount, establishment_code, emission_point_code, company_id)
+				 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 				  returning id, created_at, updated_at`
 
 	now := time.Now()
@@ -66,6 +76,15 @@ func (r *TransactionRepositoryImpl) CreateTransaction(ctx context.Context, trans
 		transaction.Subtotal0,
 		transaction.TaxAmount,
 		transaction.TaxPayerID,
+		transaction.RelatedTransactionID,
+		transaction.Discount,
+		transaction.Tip,
+		transaction.SupplierAccessKey,
+		transaction.ICEAmount,
+		transaction.IRBPNRAmount,
+		transaction.EstablishmentCode,
+		transaction.EmissionPointCode,
+		companyID,
 	).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
@@ -75,15 +94,46 @@ func (r *TransactionRepositoryImpl) CreateTransaction(ctx context.Context, trans
 	for i := range transaction.Items {
 		item := &transaction.Items[i]
 		itemQuery := `
-			INSERT INTO transaction_items (transaction_id, description, quantity, unit_price, tax_rate, subtotal, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+			INSERT INTO transaction_items (transaction_id, description, quantity, unit_price, tax_rate, discount, subtotal, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id
 		`
 		err = tx.QueryRow(ctx, itemQuery,
-			transaction.ID, item.Description, item.Quantity, item.UnitPrice, item.TaxRate, item.Subtotal, now, now,
+			transaction.ID, item.Description, item.Quantity, item.UnitPrice, item.TaxRate, item.Discount, item.Subtotal, now, now,
 		).Scan(&item.ID)
 		if err != nil {
 			return fmt.Errorf("failed to create transaction item: %w", err)
 		}
+		if err := insertItemTaxes(ctx, tx, item, now); err != nil {
+			return err
+		}
+	}
+
+	// 4. Insertar el desglose de formas de pago
+	for i := range transaction.Payments {
+		payment := &transaction.Payments[i]
+		paymentQuery := `
+			INSERT INTO transaction_payments (transaction_id, payment_method, amount, term, time_unit, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		`
+		err = tx.QueryRow(ctx, paymentQuery,
+			transaction.ID, payment.PaymentMethod, payment.Amount, payment.Term, payment.TimeUnit, now, now,
+		).Scan(&payment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create transaction payment: %w", err)
+		}
+		payment.TransactionID = transaction.ID
+	}
+
+	// 5. Insertar los campos adicionales propios de la transacción
+	for _, field := range transaction.AdditionalFields {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO transaction_additional_fields (transaction_id, name, value, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)`,
+			transaction.ID, field.Name, field.Value, now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create transaction additional field: %w", err)
+		}
 	}
 
 	return tx.Commit(ctx)
@@ -97,8 +147,14 @@ func (r *TransactionRepositoryImpl) FindTransactionsByAccount(
 	filters domain.TransactionFilters,
 	searchString *string,
 ) (*domain.PaginatedResult[domain.Transaction], error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	// --- Build the base query and arguments ---
 	whereCondition, args := r.buildQueryConditions(filters, searchString, &accountID)
+	whereCondition, args = scopeConditions(whereCondition, args, companyID)
 
 	// --- Get the total count for pagination ---
 	countQuery := `
@@ -108,7 +164,7 @@ func (r *TransactionRepositoryImpl) FindTransactionsByAccount(
               WHERE ` + whereCondition
 
 	var totalCount int64
-	err := r.db.QueryRow(ctx, countQuery, args...).Scan(&totalCount)
+	err = r.db.QueryRow(ctx, countQuery, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count transactions: %w",
 			err)
@@ -143,9 +199,16 @@ func (r *TransactionRepositoryImpl) FindTransactionsByAccount(
         t.is_voided,
         t.voided_by_transaction_id,
         t.voids_transaction_id,
+        t.related_transaction_id,
         t.subtotal_15,
         t.subtotal_0,
         t.tax_amount,
+        t.discount,
+        t.tip,
+        t.ice_amount,
+        t.irbpnr_amount,
+        t.establishment_code,
+        t.emission_point_code,
         t.tax_payer_id,
         c.name AS category_name,
         c.type AS category_type,
@@ -217,8 +280,14 @@ func (r *TransactionRepositoryImpl) FindAllTransactionsByAccount(
 	filters domain.TransactionFilters,
 	searchString *string,
 ) ([]domain.Transaction, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	// --- Build the base query and arguments ---
 	whereCondition, args := r.buildQueryConditions(filters, searchString, &accountID)
+	whereCondition, args = scopeConditions(whereCondition, args, companyID)
 	// --- Build the main query for fetching the paginated data ---
 	finalQuery := fmt.Sprintf(`
     SELECT
@@ -233,9 +302,16 @@ func (r *TransactionRepositoryImpl) FindAllTransactionsByAccount(
         t.is_voided,
         t.voided_by_transaction_id,
         t.voids_transaction_id,
+        t.related_transaction_id,
         t.subtotal_15,
         t.subtotal_0,
         t.tax_amount,
+        t.discount,
+        t.tip,
+        t.ice_amount,
+        t.irbpnr_amount,
+        t.establishment_code,
+        t.emission_point_code,
         t.tax_payer_id,
         c.name AS category_name,
         c.type AS category_type,
@@ -293,8 +369,14 @@ func (r *TransactionRepositoryImpl) FindAllTransactions(
 	filters domain.TransactionFilters,
 	searchString *string,
 ) ([]domain.Transaction, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	// --- Build the base query and arguments ---
 	whereCondition, args := r.buildQueryConditions(filters, searchString, nil)
+	whereCondition, args = scopeConditions(whereCondition, args, companyID)
 
 	// --- Build the main query for fetching the paginated data ---
 	finalQuery := fmt.Sprintf(`
@@ -310,9 +392,16 @@ func (r *TransactionRepositoryImpl) FindAllTransactions(
         t.is_voided,
         t.voided_by_transaction_id,
         t.voids_transaction_id,
+        t.related_transaction_id,
         t.subtotal_15,
         t.subtotal_0,
         t.tax_amount,
+        t.discount,
+        t.tip,
+        t.ice_amount,
+        t.irbpnr_amount,
+        t.establishment_code,
+        t.emission_point_code,
         t.tax_payer_id,
         c.name AS category_name,
         c.type AS category_type,
@@ -366,21 +455,26 @@ func (r *TransactionRepositoryImpl) FindAllTransactions(
 }
 
 func (r *TransactionRepositoryImpl) GetBalanceAsOf(ctx context.Context, accountID int, date time.Time) (decimal.Decimal, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return decimal.Zero, err
+	}
+
 	query := `
 		       SELECT a.initial_balance + COALESCE(SUM(CASE WHEN c.type = 'Ingreso' THEN t.amount ELSE -t.amount END), 0)
            FROM accounts a
            LEFT JOIN transactions t ON a.id = t.account_id AND t.transaction_date < $2
            LEFT JOIN categories c ON t.category_id = c.id
-           WHERE a.id = $1
+           WHERE a.id = $1 AND a.company_id = $3
            GROUP BY a.initial_balance;
 	`
 
 	var balance decimal.Decimal
-	err := r.db.QueryRow(ctx, query, accountID, date).Scan(&balance)
+	err = r.db.QueryRow(ctx, query, accountID, date, companyID).Scan(&balance)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			var initialBalance decimal.Decimal
-			err = r.db.QueryRow(ctx, "SELECT initial_balance FROM accounts WHERE id = $1", accountID).Scan(&initialBalance)
+			err = r.db.QueryRow(ctx, "SELECT initial_balance FROM accounts WHERE id = $1 AND company_id = $2", accountID, companyID).Scan(&initialBalance)
 			if err != nil {
 				return decimal.Zero, fmt.Errorf("failed to get initial balance for account %d: %w", accountID, err)
 			}
@@ -397,6 +491,11 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 	transactionID int,
 	currentUser domain.User,
 ) (int, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return 0, err
+	}
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to begin transaction: %w", err)
@@ -414,20 +513,25 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 			 t.is_voided,
 			 t.voided_by_transaction_id,
 			 t.voids_transaction_id,
+			 t.related_transaction_id,
 			 t.subtotal_15,
 			 t.subtotal_0,
 			 t.tax_amount,
 			 t.tax_payer_id,
+			 t.ice_amount,
+			 t.irbpnr_amount,
+			 t.establishment_code,
+			 t.emission_point_code,
 			 c.type
 		 FROM transactions t
 		JOIN categories c ON t.category_id = c.id
-		WHERE t.id = $1
+		WHERE t.id = $1 AND t.company_id = $2
 		FOR UPDATE;
 	`
 	var originalTransaction domain.Transaction
 	var originalCatType domain.CategoryType
 
-	row := tx.QueryRow(ctx, originalTransactionQuery, transactionID)
+	row := tx.QueryRow(ctx, originalTransactionQuery, transactionID, companyID)
 	err = row.Scan(
 		&originalTransaction.ID,
 		&originalTransaction.TransactionNumber,
@@ -438,10 +542,15 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 		&originalTransaction.IsVoided,
 		&originalTransaction.VoidedByTransactionID,
 		&originalTransaction.VoidsTransactionID,
+		&originalTransaction.RelatedTransactionID,
 		&originalTransaction.Subtotal15,
 		&originalTransaction.Subtotal0,
 		&originalTransaction.TaxAmount,
 		&originalTransaction.TaxPayerID,
+		&originalTransaction.ICEAmount,
+		&originalTransaction.IRBPNRAmount,
+		&originalTransaction.EstablishmentCode,
+		&originalTransaction.EmissionPointCode,
 		&originalCatType,
 	)
 	if err != nil {
@@ -455,6 +564,30 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 		return 0, fmt.Errorf("no se puede anular una transacción previamente anulada o una transacción que anule a otra")
 	}
 
+	// Una devolución parcial con nota de crédito emitida ya quedó reportada al SRI; anular el egreso
+	// dejaría la nota vigente sin su movimiento y liberaría sus cantidades para otra devolución
+	originalTransaction.Category = &domain.Category{Type: originalCatType}
+	if originalTransaction.IsPartialCredit() {
+		var hasReceipt bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM electronic_receipts WHERE transaction_id = $1)`, originalTransaction.ID).
+			Scan(&hasReceipt)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check credit note receipt: %w", err)
+		}
+		if hasReceipt {
+			return 0, fmt.Errorf("no se puede anular una devolución parcial que ya tiene nota de crédito electrónica")
+		}
+	}
+
+	// Con devoluciones parciales la anulación total acreditaría dos veces lo ya devuelto
+	credited, err := creditedAmount(ctx, tx, originalTransaction.ID)
+	if err != nil {
+		return 0, err
+	}
+	if credited > 0 {
+		return 0, fmt.Errorf("la factura tiene devoluciones parciales por $%.2f; registre la devolución de los ítems restantes", credited)
+	}
+
 	var opposingCatType domain.CategoryType
 	if originalCatType == domain.Income {
 		opposingCatType = domain.Outcome
@@ -465,12 +598,12 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 	adjustmentCatQuery := `
 		 select id, name
 		 from categories
-		where name like '%Anular Transacción%' and type = $1
+		where name like '%Anular Transacción%' and type = $1 and company_id = $2
 	`
 
 	var opposingCatID int
 	var opposingCatName string
-	err = tx.QueryRow(ctx, adjustmentCatQuery, opposingCatType).
+	err = tx.QueryRow(ctx, adjustmentCatQuery, opposingCatType, companyID).
 		Scan(&opposingCatID, &opposingCatName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get the opposing category: %w", err)
@@ -481,6 +614,7 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 
 	voidTransactionNumber, err := r.generateTransactionNumber(ctx,
 		tx,
+		companyID,
 		opposingCatType,
 		opposingCatName,
 		newTransactionDate,
@@ -489,12 +623,18 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 		return 0, fmt.Errorf("failed to generate void transaction number: %w", err)
 	}
 
+	// La nota de crédito se emite desde el punto del usuario que anula o, si no tiene, desde el de la factura
+	voidTx := domain.Transaction{}
+	voidTx.SetEmissionPoint(currentUser.DefaultEstablishmentCode, currentUser.DefaultEmissionPointCode)
+	voidTx.SetEmissionPoint(originalTransaction.EstablishmentCode, originalTransaction.EmissionPointCode)
+
 	voidTransactionQuery := `
          insert into transactions
            (description, amount, transaction_date, account_id,
             category_id, voids_transaction_id, created_at, updated_at, transaction_number,
-            created_by_id, updated_by_id, subtotal_15, subtotal_0, tax_amount, tax_payer_id)
-            values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) returning id
+            created_by_id, updated_by_id, subtotal_15, subtotal_0, tax_amount, tax_payer_id,
+            ice_amount, irbpnr_amount, establishment_code, emission_point_code, company_id)
+            values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20) returning id
      `
 
 	var voidTransactionID int
@@ -516,6 +656,11 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 		originalTransaction.Subtotal0,
 		originalTransaction.TaxAmount,
 		originalTransaction.TaxPayerID,
+		originalTransaction.ICEAmount,
+		originalTransaction.IRBPNRAmount,
+		voidTx.EstablishmentCode,
+		voidTx.EmissionPointCode,
+		companyID,
 	).Scan(&voidTransactionID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create void transaction: %w", err)
@@ -551,7 +696,180 @@ func (r *TransactionRepositoryImpl) VoidTransaction(
 	return voidTransactionID, nil
 }
 
+// PartialVoidTransaction registra la devolución parcial de un ingreso como un egreso vinculado
+// (related_transaction_id) con los ítems devueltos. La suma de devoluciones no puede superar
+// el total de la factura original, ni lo devuelto de cada ítem la cantidad facturada.
+func (r *TransactionRepositoryImpl) PartialVoidTransaction(
+	ctx context.Context,
+	originalID int,
+	reversal *domain.Transaction,
+) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Bloqueamos la original para que dos devoluciones simultáneas no superen el total
+	var original domain.Transaction
+	var catType domain.CategoryType
+	err = tx.QueryRow(ctx, `
+		SELECT t.id, t.transaction_number, t.amount, t.account_id, t.is_voided, t.voids_transaction_id, t.tax_payer_id,
+		       t.establishment_code, t.emission_point_code, c.type
+		FROM transactions t
+		JOIN categories c ON t.category_id = c.id
+		WHERE t.id = $1 AND t.company_id = $2
+		FOR UPDATE OF t`, originalID, companyID,
+	).Scan(&original.ID, &original.TransactionNumber, &original.Amount, &original.AccountID,
+		&original.IsVoided, &original.VoidsTransactionID, &original.TaxPayerID,
+		&original.EstablishmentCode, &original.EmissionPointCode, &catType)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("transaction with ID %d not found", originalID)
+		}
+		return fmt.Errorf("failed to get original transaction: %w", err)
+	}
+
+	if catType != domain.Income {
+		return fmt.Errorf("solo se pueden registrar devoluciones sobre transacciones de ingreso")
+	}
+	if original.IsVoided || original.VoidsTransactionID != nil {
+		return fmt.Errorf("no se puede registrar una devolución sobre una transacción anulada")
+	}
+
+	credited, err := creditedAmount(ctx, tx, original.ID)
+	if err != nil {
+		return err
+	}
+	// Tolerancia de medio centavo por redondeo
+	if credited+reversal.Amount > original.Amount+0.005 {
+		return fmt.Errorf("la devolución ($%.2f) supera el saldo acreditable de la factura ($%.2f)",
+			reversal.Amount, original.Amount-credited)
+	}
+
+	for _, item := range reversal.Items {
+		if item.ReturnedItemID == nil {
+			continue
+		}
+		var description string
+		var sold, returned float64
+		err = tx.QueryRow(ctx, `
+			SELECT oi.description, oi.quantity, COALESCE((
+				SELECT SUM(ri.quantity)
+				FROM transaction_items ri
+				JOIN transactions rt ON ri.transaction_id = rt.id
+				WHERE ri.returned_item_id = oi.id AND rt.related_transaction_id = oi.transaction_id AND rt.is_voided = FALSE
+			), 0)
+			FROM transaction_items oi
+			WHERE oi.id = $1 AND oi.transaction_id = $2`, *item.ReturnedItemID, original.ID,
+		).Scan(&description, &sold, &returned)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return fmt.Errorf("el ítem %d no pertenece a la factura", *item.ReturnedItemID)
+			}
+			return fmt.Errorf("failed to get returned quantity: %w", err)
+		}
+		if returned+item.Quantity > sold+1e-9 {
+			return fmt.Errorf("la devolución de %q (%g) supera lo que queda por devolver (%g de %g)",
+				description, item.Quantity, sold-returned, sold)
+		}
+	}
+
+	var catID int
+	var catName string
+	err = tx.QueryRow(ctx, `
+		SELECT id, name FROM categories
+		WHERE name LIKE '%Anular Transacción%' AND type = $1 AND company_id = $2`, domain.Outcome, companyID,
+	).Scan(&catID, &catName)
+	if err != nil {
+		return fmt.Errorf("failed to get the opposing category: %w", err)
+	}
+
+	reversal.TransactionDate = time.Now()
+	reversal.TransactionNumber, err = r.generateTransactionNumber(ctx, tx, companyID, domain.Outcome, catName, reversal.TransactionDate)
+	if err != nil {
+		return fmt.Errorf("failed to generate reversal transaction number: %w", err)
+	}
+	reversal.AccountID = original.AccountID
+	reversal.CategoryID = catID
+	reversal.TaxPayerID = original.TaxPayerID
+	reversal.RelatedTransactionID = &original.ID
+	reversal.SetEmissionPoint(original.EstablishmentCode, original.EmissionPointCode)
+	if reversal.Description == "" {
+		reversal.Description = "Devolución parcial de la transacción #" + original.TransactionNumber
+	}
+
+	now := time.Now()
+	err = tx.QueryRow(ctx, `
+		INSERT INTO transactions (transaction_number, description, amount, transaction_date, account_id, category_id,
+		                          created_by_id, updated_by_id, created_at, updated_at,
+		                          subtotal_15, subtotal_0, tax_amount, tax_payer_id, related_transaction_id,
+		                          ice_amount, irbpnr_amount, establishment_code, emission_point_code, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		RETURNING id, created_at, updated_at`,
+		reversal.TransactionNumber, reversal.Description, reversal.Amount, reversal.TransactionDate,
+		reversal.AccountID, reversal.CategoryID, reversal.CreatedByID, reversal.UpdatedByID, now, now,
+		reversal.Subtotal15, reversal.Subtotal0, reversal.TaxAmount, reversal.TaxPayerID, reversal.RelatedTransactionID,
+		reversal.ICEAmount, reversal.IRBPNRAmount, reversal.EstablishmentCode, reversal.EmissionPointCode, companyID,
+	).Scan(&reversal.ID, &reversal.CreatedAt, &reversal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create reversal transaction: %w", err)
+	}
+
+	for i := range reversal.Items {
+		item := &reversal.Items[i]
+		err = tx.QueryRow(ctx, `
+			INSERT INTO transaction_items (transaction_id, description, quantity, unit_price, tax_rate, discount, subtotal, returned_item_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+			reversal.ID, item.Description, item.Quantity, item.UnitPrice, item.TaxRate, item.Discount, item.Subtotal, item.ReturnedItemID, now, now,
+		).Scan(&item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create reversal item: %w", err)
+		}
+		if err := insertItemTaxes(ctx, tx, item, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetCreditedAmount suma las devoluciones parciales activas registradas sobre una factura.
+func (r *TransactionRepositoryImpl) GetCreditedAmount(ctx context.Context, transactionID int) (float64, error) {
+	return creditedAmount(ctx, r.db, transactionID)
+}
+
+// rowQuerier lo cumplen tanto el pool como una transacción abierta.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func creditedAmount(ctx context.Context, q rowQuerier, transactionID int) (float64, error) {
+	var total float64
+	err := q.QueryRow(ctx, `
+		SELECT COALESCE(SUM(t.amount), 0)
+		FROM transactions t
+		JOIN categories c ON t.category_id = c.id
+		WHERE t.related_transaction_id = $1 AND c.type = $2 AND t.is_voided = FALSE`,
+		transactionID, domain.Outcome,
+	).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get credited amount: %w", err)
+	}
+	return total, nil
+}
+
 func (r *TransactionRepositoryImpl) RevertVoidTransaction(ctx context.Context, voidTransactionID int) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -560,7 +878,7 @@ func (r *TransactionRepositoryImpl) RevertVoidTransaction(ctx context.Context, v
 
 	// 1. Get Original Transaction ID
 	var originalTxID int
-	err = tx.QueryRow(ctx, "SELECT voids_transaction_id FROM transactions WHERE id = $1", voidTransactionID).Scan(&originalTxID)
+	err = tx.QueryRow(ctx, "SELECT voids_transaction_id FROM transactions WHERE id = $1 AND company_id = $2", voidTransactionID, companyID).Scan(&originalTxID)
 	if err != nil {
 		return fmt.Errorf("failed to find original transaction from void id %d: %w", voidTransactionID, err)
 	}
@@ -593,6 +911,11 @@ func (r *TransactionRepositoryImpl) RevertVoidTransaction(ctx context.Context, v
 }
 
 func (r *TransactionRepositoryImpl) GetTransactionByID(ctx context.Context, transactionID int) (*domain.Transaction, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return nil, err
+	}
+
 	queryJoin := `
 		SELECT
 			t.id,
@@ -606,9 +929,16 @@ func (r *TransactionRepositoryImpl) GetTransactionByID(ctx context.Context, tran
 			t.is_voided,
 			t.voided_by_transaction_id,
 			t.voids_transaction_id,
+			t.related_transaction_id,
 			t.subtotal_15,
 			t.subtotal_0,
 			t.tax_amount,
+			t.discount,
+			t.tip,
+			t.ice_amount,
+			t.irbpnr_amount,
+			t.establishment_code,
+			t.emission_point_code,
 			t.tax_payer_id,
 			c.name,
 			c.type,
@@ -632,10 +962,10 @@ func (r *TransactionRepositoryImpl) GetTransactionByID(ctx context.Context, tran
       FROM electronic_receipts
       ORDER BY transaction_id, created_at DESC
     ) AS er ON t.id = er.transaction_id
-		WHERE t.id = $1
+		WHERE t.id = $1 AND t.company_id = $2
 	`
 
-	rows, err := r.db.Query(ctx, queryJoin, transactionID)
+	rows, err := r.db.Query(ctx, queryJoin, transactionID, companyID)
 	if err != nil {
 		return nil, err
 	}
@@ -654,7 +984,7 @@ func (r *TransactionRepositoryImpl) GetTransactionByID(ctx context.Context, tran
 
 func (r *TransactionRepositoryImpl) GetItemsByTransactionID(ctx context.Context, transactionID int) ([]domain.TransactionItem, error) {
 	query := `
-		SELECT id, transaction_id, description, quantity, unit_price, tax_rate, subtotal, created_at, updated_at
+		SELECT id, transaction_id, description, quantity, unit_price, tax_rate, discount, subtotal, returned_item_id, created_at, updated_at
 		FROM transaction_items
 		WHERE transaction_id = $1
 		ORDER BY id ASC
@@ -669,17 +999,132 @@ func (r *TransactionRepositoryImpl) GetItemsByTransactionID(ctx context.Context,
 	for rows.Next() {
 		var item domain.TransactionItem
 		err := rows.Scan(
-			&item.ID, &item.TransactionID, &item.Description, &item.Quantity, &item.UnitPrice, &item.TaxRate, &item.Subtotal, &item.CreatedAt, &item.UpdatedAt,
+			&item.ID, &item.TransactionID, &item.Description, &item.Quantity, &item.UnitPrice, &item.TaxRate, &item.Discount, &item.Subtotal, &item.ReturnedItemID, &item.CreatedAt, &item.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan item: %w", err)
 		}
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transaction items: %w", err)
+	}
+	rows.Close()
+
+	if err := r.loadItemTaxes(ctx, items); err != nil {
+		return nil, err
+	}
 	return items, nil
 }
 
+// loadItemTaxes completa el ICE y el IRBPNR de cada ítem.
+func (r *TransactionRepositoryImpl) loadItemTaxes(ctx context.Context, items []domain.TransactionItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]int, len(items))
+	byID := make(map[int]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+		byID[item.ID] = i
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, transaction_item_id, tax_code, percentage_code, rate, is_specific, base, amount, created_at, updated_at
+		FROM transaction_item_taxes
+		WHERE transaction_item_id = ANY($1)
+		ORDER BY id ASC`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query transaction item taxes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t domain.ItemTax
+		err := rows.Scan(
+			&t.ID, &t.TransactionItemID, &t.TaxCode, &t.PercentageCode, &t.Rate, &t.Specific, &t.Base, &t.Amount, &t.CreatedAt, &t.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan item tax: %w", err)
+		}
+		i := byID[t.TransactionItemID]
+		items[i].Taxes = append(items[i].Taxes, t)
+	}
+	return rows.Err()
+}
+
+// insertItemTaxes guarda el ICE y el IRBPNR de un ítem recién insertado.
+func insertItemTaxes(ctx context.Context, tx pgx.Tx, item *domain.TransactionItem, now time.Time) error {
+	for i := range item.Taxes {
+		t := &item.Taxes[i]
+		t.TransactionItemID = item.ID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO transaction_item_taxes (transaction_item_id, tax_code, percentage_code, rate, is_specific, base, amount, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+			t.TransactionItemID, t.TaxCode, t.PercentageCode, t.Rate, t.Specific, t.Base, t.Amount, now, now,
+		).Scan(&t.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create transaction item tax: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *TransactionRepositoryImpl) GetPaymentsByTransactionID(ctx context.Context, transactionID int) ([]domain.TransactionPayment, error) {
+	query := `
+		SELECT id, transaction_id, payment_method, amount, term, time_unit, created_at, updated_at
+		FROM transaction_payments
+		WHERE transaction_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(ctx, query, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []domain.TransactionPayment
+	for rows.Next() {
+		var p domain.TransactionPayment
+		err := rows.Scan(
+			&p.ID, &p.TransactionID, &p.PaymentMethod, &p.Amount, &p.Term, &p.TimeUnit, &p.CreatedAt, &p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, nil
+}
+
+func (r *TransactionRepositoryImpl) GetAdditionalFieldsByTransactionID(ctx context.Context, transactionID int) ([]domain.AdditionalField, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT name, value
+		FROM transaction_additional_fields
+		WHERE transaction_id = $1
+		ORDER BY id ASC`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction additional fields: %w", err)
+	}
+	defer rows.Close()
+
+	var fields []domain.AdditionalField
+	for rows.Next() {
+		var f domain.AdditionalField
+		if err := rows.Scan(&f.Name, &f.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan additional field: %w", err)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
 func (r *TransactionRepositoryImpl) UpdateTransaction(ctx context.Context, tx *domain.Transaction) error {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
 	dbTx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -693,8 +1138,8 @@ func (r *TransactionRepositoryImpl) UpdateTransaction(ctx context.Context, tx *d
 		transaction_date,
 		is_voided,
 		voids_transaction_id
-		FROM transactions WHERE id = $1`,
-		tx.ID).
+		FROM transactions WHERE id = $1 AND company_id = $2`,
+		tx.ID, companyID).
 		Scan(
 			&originalTx.CategoryID,
 			&originalTx.TransactionDate,
@@ -711,7 +1156,7 @@ func (r *TransactionRepositoryImpl) UpdateTransaction(ctx context.Context, tx *d
 
 	// Get new category info
 	var newCat domain.Category
-	err = dbTx.QueryRow(ctx, "SELECT name, type FROM categories WHERE id = $1", tx.CategoryID).
+	err = dbTx.QueryRow(ctx, "SELECT name, type FROM categories WHERE id = $1 AND company_id = $2", tx.CategoryID, companyID).
 		Scan(&newCat.Name, &newCat.Type)
 	if err != nil {
 		return fmt.Errorf("failed to get new category info: %w", err)
@@ -733,7 +1178,7 @@ func (r *TransactionRepositoryImpl) UpdateTransaction(ctx context.Context, tx *d
 	}
 
 	if regenerateNumber {
-		newTxNumber, err := r.generateTransactionNumber(ctx, dbTx, newCat.Type, newCat.Name, tx.TransactionDate)
+		newTxNumber, err := r.generateTransactionNumber(ctx, dbTx, companyID, newCat.Type, newCat.Name, tx.TransactionDate)
 		if err != nil {
 			return fmt.Errorf("failed to generate new transaction number during update: %w", err)
 		}
@@ -742,9 +1187,9 @@ func (r *TransactionRepositoryImpl) UpdateTransaction(ctx context.Context, tx *d
 		query := `
 			UPDATE transactions
 			SET description = $1, transaction_date = $2, category_id = $3, transaction_number = $4, attachment_path = $5, updated_at = $6, updated_by_id = $7
-			WHERE id = $8
+			WHERE id = $8 AND company_id = $9
 		`
-		_, err = dbTx.Exec(ctx, query, tx.Description, tx.TransactionDate, tx.CategoryID, tx.TransactionNumber, tx.AttachmentPath, time.Now(), tx.UpdatedByID, tx.ID)
+		_, err = dbTx.Exec(ctx, query, tx.Description, tx.TransactionDate, tx.CategoryID, tx.TransactionNumber, tx.AttachmentPath, time.Now(), tx.UpdatedByID, tx.ID, companyID)
 		if err != nil {
 			return fmt.Errorf("failed to update transaction with new number: %w", err)
 		}
@@ -753,9 +1198,9 @@ func (r *TransactionRepositoryImpl) UpdateTransaction(ctx context.Context, tx *d
 		query := `
 			UPDATE transactions
 			SET description = $1, transaction_date = $2, category_id = $3, attachment_path = $4, updated_at = $5, updated_by_id = $6
-			WHERE id = $7
+			WHERE id = $7 AND company_id = $8
 		`
-		_, err := dbTx.Exec(ctx, query, tx.Description, tx.TransactionDate, tx.CategoryID, tx.AttachmentPath, time.Now(), tx.UpdatedByID, tx.ID)
+		_, err := dbTx.Exec(ctx, query, tx.Description, tx.TransactionDate, tx.CategoryID, tx.AttachmentPath, time.Now(), tx.UpdatedByID, tx.ID, companyID)
 		if err != nil {
 			return fmt.Errorf("failed to update transaction: %w", err)
 		}
@@ -764,16 +1209,36 @@ func (r *TransactionRepositoryImpl) UpdateTransaction(ctx context.Context, tx *d
 	return dbTx.Commit(ctx)
 }
 
+// SupplierAccessKeyExists indica si ya se registró una compra con la factura de proveedor de esa clave de acceso.
+func (r *TransactionRepositoryImpl) SupplierAccessKeyExists(ctx context.Context, accessKey string) (bool, error) {
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM transactions WHERE supplier_access_key = $1 AND company_id = $2)`, accessKey, companyID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check supplier access key: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *TransactionRepositoryImpl) UpdateAttachmentPath(ctx context.Context, transactionID int, attachmentPath string) error {
-	query := `UPDATE transactions SET attachment_path = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.Exec(ctx, query, attachmentPath, time.Now(), transactionID)
+	companyID, err := r.scope.ID()
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE transactions SET attachment_path = $1, updated_at = $2 WHERE id = $3 AND company_id = $4`
+	_, err = r.db.Exec(ctx, query, attachmentPath, time.Now(), transactionID, companyID)
 	if err != nil {
 		return fmt.Errorf("failed to update attachment path: %w", err)
 	}
 	return nil
 }
 
-func (r *TransactionRepositoryImpl) generateTransactionNumber(ctx context.Context, tx pgx.Tx, catType domain.CategoryType, catName string, date time.Time) (string, error) {
+func (r *TransactionRepositoryImpl) generateTransactionNumber(ctx context.Context, tx pgx.Tx, companyID int, catType domain.CategoryType, catName string, date time.Time) (string, error) {
 	var prefix string
 	if catType == domain.Income {
 		prefix = "ING"
@@ -788,10 +1253,10 @@ func (r *TransactionRepositoryImpl) generateTransactionNumber(ctx context.Contex
 	sequenceQuery := `
 		 SELECT COUNT(*) + 1
 		  FROM transactions
-		WHERE to_char(transaction_date, 'YYYYMM') = $1
+		WHERE to_char(transaction_date, 'YYYYMM') = $1 AND company_id = $2
 	`
 	var sequence int
-	err := tx.QueryRow(ctx, sequenceQuery, dateComp).Scan(&sequence)
+	err := tx.QueryRow(ctx, sequenceQuery, dateComp, companyID).Scan(&sequence)
 	if err != nil {
 		return "", fmt.Errorf("failed to get transaction sequence number: %w", err)
 	}
@@ -799,6 +1264,24 @@ func (r *TransactionRepositoryImpl) generateTransactionNumber(ctx context.Contex
 	return fmt.Sprintf("%s-%s-%04d", prefix, dateComp, sequence), nil
 }
 
+// checkAccountCompany verifies that the account belongs to the selected company.
+func checkAccountCompany(ctx context.Context, q rowQuerier, accountID, companyID int) error {
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM accounts WHERE id = $1 AND company_id = $2)`, accountID, companyID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check account: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("account with ID %d not found", accountID)
+	}
+	return nil
+}
+
+// scopeConditions limits the filter conditions to the selected company.
+func scopeConditions(whereCondition string, args []any, companyID int) (string, []any) {
+	return fmt.Sprintf("t.company_id = $%d AND (%s)", len(args)+1, whereCondition), append(args, companyID)
+}
+
 func (r *TransactionRepositoryImpl) buildQueryConditions(
 	filters domain.TransactionFilters,
 	searchString *string,
@@ -907,9 +1390,16 @@ func (r *TransactionRepositoryImpl) scanTransactions(rows pgx.Rows) ([]domain.Tr
 			&tx.IsVoided,
 			&tx.VoidedByTransactionID,
 			&tx.VoidsTransactionID,
+			&tx.RelatedTransactionID,
 			&tx.Subtotal15,
 			&tx.Subtotal0,
 			&tx.TaxAmount,
+			&tx.Discount,
+			&tx.Tip,
+			&tx.ICEAmount,
+			&tx.IRBPNRAmount,
+			&tx.EstablishmentCode,
+			&tx.EmissionPointCode,
 			&tx.TaxPayerID,
 			&categoryName,
 			&categoryType,
@@ -954,7 +1444,7 @@ func (r *TransactionRepositoryImpl) scanTransactions(rows pgx.Rows) ([]domain.Tr
 		// Map SRI Receipt
 		if sriStatus.Valid {
 			tx.ElectronicReceipt = &domain.ElectronicReceipt{
-				SRIStatus:   sriStatus.String,
+				SRIStatus:   domain.ReceiptStatus(sriStatus.String),
 				AccessKey:   accessKey.String,
 				RidePath:    ridePath.String,
 				ReceiptType: receiptType.String,
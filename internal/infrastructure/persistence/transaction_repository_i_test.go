@@ -30,9 +30,9 @@ func createTestTransaction(t *testing.T, txRepo *TransactionRepositoryImpl, accI
 
 func TestUpdateTransaction(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
 
 	// --- Test Scenarios ---
 	t.Run("should update description without changing transaction number", func(t *testing.T) {
@@ -129,9 +129,9 @@ func TestUpdateTransaction(t *testing.T) {
 
 func TestFindTransactionsByAccount(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
 	ctx := context.Background()
 
 	// --- Test Data Setup ---
@@ -329,9 +329,9 @@ func TestFindTransactionsByAccount(t *testing.T) {
 
 func TestFindAllTransactionsByAccount(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
 	ctx := context.Background()
 
 	// --- Test Data Setup ---
@@ -442,9 +442,9 @@ func TestFindAllTransactionsByAccount(t *testing.T) {
 
 func TestFindAllTransactions(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
 	ctx := context.Background()
 
 	// --- Test Data Setup ---
@@ -550,9 +550,9 @@ func TestFindAllTransactions(t *testing.T) {
 
 func TestGetBalanceAsOf(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
 	ctx := context.Background()
 
 	truncateTables(t)
@@ -602,9 +602,9 @@ func TestGetBalanceAsOf(t *testing.T) {
 
 func TestFindTransactionsWithMultipleReceipts(t *testing.T) {
 	// Setup Repositories
-	accountRepo := NewAccountRepository(dbPool)
-	categoryRepo := NewCategoryRepository(dbPool)
-	txRepo := NewTransactionRepository(dbPool)
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
 	ctx := context.Background()
 
 	// --- Test Data Setup ---
@@ -661,6 +661,126 @@ func TestFindTransactionsWithMultipleReceipts(t *testing.T) {
 	returnedTx := result.Data[0]
 	assert.Equal(t, tx.ID, returnedTx.ID)
 	require.NotNil(t, returnedTx.ElectronicReceipt, "Should have receipt info")
-	assert.Equal(t, "AUTORIZADO", returnedTx.ElectronicReceipt.SRIStatus, "Should show the status of the LATEST receipt")
+	assert.Equal(t, domain.ReceiptAuthorized, returnedTx.ElectronicReceipt.SRIStatus, "Should show the status of the LATEST receipt")
 	assert.Equal(t, "2222222222222222222222222222222222222222222222222", returnedTx.ElectronicReceipt.AccessKey)
-}
\ No newline at end of file
+}
+func TestPartialVoidTransaction_CumulativeItemReturns(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
+
+	truncateTables(t)
+	user := createTestUser(t, testUserRepo, "testuser_partialvoid", domain.RoleAdmin)
+	acc := createTestAccount(t, accountRepo)
+	cat := createTestCategory(t, categoryRepo, "Ventas", domain.Income)
+	_ = createTestCategory(t, categoryRepo, "Anular Transacción Ingreso", domain.Outcome)
+
+	original := &domain.Transaction{
+		Description:     "Venta de sillas",
+		Amount:          115.0,
+		TransactionDate: time.Now(),
+		AccountID:       acc.ID,
+		CategoryID:      cat.ID,
+		CreatedByID:     user.ID,
+		UpdatedByID:     user.ID,
+		Items: []domain.TransactionItem{
+			{Description: "Silla", Quantity: 2, UnitPrice: 50, TaxRate: 4, Subtotal: 100},
+		},
+	}
+	require.NoError(t, txRepo.CreateTransaction(ctx, original))
+	itemID := original.Items[0].ID
+
+	returnOne := func() *domain.Transaction {
+		return &domain.Transaction{
+			Amount:      57.5,
+			CreatedByID: user.ID,
+			UpdatedByID: user.ID,
+			Items: []domain.TransactionItem{
+				{Description: "Silla", Quantity: 1, UnitPrice: 50, TaxRate: 4, Subtotal: 50, ReturnedItemID: &itemID},
+			},
+		}
+	}
+
+	// Dos notas de crédito de una silla cada una agotan la línea
+	require.NoError(t, txRepo.PartialVoidTransaction(ctx, original.ID, returnOne()))
+	second := returnOne()
+	require.NoError(t, txRepo.PartialVoidTransaction(ctx, original.ID, second))
+
+	items, err := txRepo.GetItemsByTransactionID(ctx, second.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].ReturnedItemID)
+	assert.Equal(t, itemID, *items[0].ReturnedItemID)
+
+	// Una tercera devolución de la misma silla supera lo facturado aunque el monto sea pequeño
+	third := returnOne()
+	third.Amount = 0.01
+	err = txRepo.PartialVoidTransaction(ctx, original.ID, third)
+	assert.ErrorContains(t, err, "supera lo que queda por devolver")
+}
+
+func TestVoidTransaction_RejectsPartialCreditWithReceipt(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := NewAccountRepository(dbPool, testScope)
+	categoryRepo := NewCategoryRepository(dbPool, testScope)
+	txRepo := NewTransactionRepository(dbPool, testScope)
+
+	truncateTables(t)
+	user := createTestUser(t, testUserRepo, "testuser_voidpartial", domain.RoleAdmin)
+	acc := createTestAccount(t, accountRepo)
+	cat := createTestCategory(t, categoryRepo, "Ventas", domain.Income)
+	_ = createTestCategory(t, categoryRepo, "Anular Transacción Ingreso", domain.Outcome)
+	_ = createTestCategory(t, categoryRepo, "Anular Transacción Egreso", domain.Income)
+
+	original := &domain.Transaction{
+		Description:     "Venta de sillas",
+		Amount:          115.0,
+		TransactionDate: time.Now(),
+		AccountID:       acc.ID,
+		CategoryID:      cat.ID,
+		CreatedByID:     user.ID,
+		UpdatedByID:     user.ID,
+		Items: []domain.TransactionItem{
+			{Description: "Silla", Quantity: 2, UnitPrice: 50, TaxRate: 4, Subtotal: 100},
+		},
+	}
+	require.NoError(t, txRepo.CreateTransaction(ctx, original))
+	itemID := original.Items[0].ID
+
+	credit := &domain.Transaction{
+		Amount:      57.5,
+		CreatedByID: user.ID,
+		UpdatedByID: user.ID,
+		Items: []domain.TransactionItem{
+			{Description: "Silla", Quantity: 1, UnitPrice: 50, TaxRate: 4, Subtotal: 50, ReturnedItemID: &itemID},
+		},
+	}
+	require.NoError(t, txRepo.PartialVoidTransaction(ctx, original.ID, credit))
+
+	var issuerID int
+	err := dbPool.QueryRow(ctx, `
+		INSERT INTO issuers (ruc, business_name, trade_name, establishment_address, main_address, establishment_code, emission_point_code, environment, keep_accounting, signature_path, created_at, updated_at)
+		VALUES ('1790000000001', 'Test Issuer', 'Test Trade', 'Addr', 'Main Addr', '001', '001', 1, TRUE, '/tmp/dummy.p12', NOW(), NOW())
+		RETURNING id
+	`).Scan(&issuerID)
+	require.NoError(t, err)
+
+	var taxPayerID int
+	err = dbPool.QueryRow(ctx, `
+		INSERT INTO tax_payers (identification, name, email, identification_type, created_at, updated_at)
+		VALUES ('9999999999999', 'Consumer', 'test@test.com', '07', NOW(), NOW())
+		RETURNING id
+	`).Scan(&taxPayerID)
+	require.NoError(t, err)
+
+	_, err = dbPool.Exec(ctx, `
+		INSERT INTO electronic_receipts (transaction_id, issuer_id, tax_payer_id, access_key, receipt_type, xml_content, sri_status, environment, created_at, updated_at)
+		VALUES ($1, $2, $3, '3333333333333333333333333333333333333333333333333', '04', '<xml>nc</xml>', 'AUTORIZADO', 1, NOW(), NOW())
+	`, credit.ID, issuerID, taxPayerID)
+	require.NoError(t, err)
+
+	// La nota de crédito parcial ya está en el SRI: su egreso no se puede anular
+	_, err = txRepo.VoidTransaction(ctx, credit.ID, *user)
+	assert.ErrorContains(t, err, "devolución parcial que ya tiene nota de crédito")
+}
@@ -129,3 +129,29 @@ func TestBuildQueryConditions(t *testing.T) {
 	})
 }
 
+
+func TestScopeConditions(t *testing.T) {
+	repo := &TransactionRepositoryImpl{}
+
+	t.Run("should prepend the company to the filter conditions", func(t *testing.T) {
+		// Arrange
+		accountID := 123
+		where, args := repo.buildQueryConditions(domain.TransactionFilters{}, nil, &accountID)
+
+		// Act
+		where, args = scopeConditions(where, args, 7)
+
+		// Assert
+		assert.Equal(t, "t.company_id = $2 AND (t.account_id = $1)", where)
+		assert.Equal(t, []any{123, 7}, args)
+	})
+
+	t.Run("should scope a query without filters", func(t *testing.T) {
+		// Act
+		where, args := scopeConditions("1 = 1", []any{}, 7)
+
+		// Assert
+		assert.Equal(t, "t.company_id = $1 AND (1 = 1)", where)
+		assert.Equal(t, []any{7}, args)
+	})
+}
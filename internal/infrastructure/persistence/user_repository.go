@@ -35,9 +35,9 @@ func (r *UserRepositoryImpl) CreateUser(ctx context.Context, user *domain.User)
 
 // GetUserByUsername retrieves a user from the database by their username.
 func (r *UserRepositoryImpl) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
-	query := `SELECT id, username, password_hash, first_name, last_name, role, created_at, updated_at FROM users WHERE username = $1`
+	query := `SELECT id, username, password_hash, first_name, last_name, role, default_establishment_code, default_emission_point_code, created_at, updated_at FROM users WHERE username = $1`
 	var user domain.User
-	err := r.db.QueryRow(ctx, query, username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.QueryRow(ctx, query, username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.DefaultEstablishmentCode, &user.DefaultEmissionPointCode, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -49,9 +49,9 @@ func (r *UserRepositoryImpl) GetUserByUsername(ctx context.Context, username str
 
 // GetUserByID retrieves a user from the database by their ID.
 func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
-	query := `SELECT id, username, password_hash, first_name, last_name, role, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, username, password_hash, first_name, last_name, role, default_establishment_code, default_emission_point_code, created_at, updated_at FROM users WHERE id = $1`
 	var user domain.User
-	err := r.db.QueryRow(ctx, query, id).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.QueryRow(ctx, query, id).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.DefaultEstablishmentCode, &user.DefaultEmissionPointCode, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -63,8 +63,10 @@ func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int) (*domain.U
 
 // UpdateUser updates an existing user in the database.
 func (r *UserRepositoryImpl) UpdateUser(ctx context.Context, user *domain.User) error {
-	query := `UPDATE users SET username = $1, password_hash = $2, first_name = $3, last_name = $4, role = $5, updated_at = $6 WHERE id = $7`
-	_, err := r.db.Exec(ctx, query, user.Username, user.PasswordHash, user.FirstName, user.LastName, user.Role, time.Now(), user.ID)
+	query := `UPDATE users SET username = $1, password_hash = $2, first_name = $3, last_name = $4, role = $5,
+			  default_establishment_code = $6, default_emission_point_code = $7, updated_at = $8 WHERE id = $9`
+	_, err := r.db.Exec(ctx, query, user.Username, user.PasswordHash, user.FirstName, user.LastName, user.Role,
+		user.DefaultEstablishmentCode, user.DefaultEmissionPointCode, time.Now(), user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -83,7 +85,7 @@ func (r *UserRepositoryImpl) DeleteUser(ctx context.Context, id int) error {
 
 // GetAllUsers retrieves all users from the database.
 func (r *UserRepositoryImpl) GetAllUsers(ctx context.Context) ([]domain.User, error) {
-	query := `SELECT id, username, role, created_at, updated_at FROM users ORDER BY username ASC`
+	query := `SELECT id, username, role, default_establishment_code, default_emission_point_code, created_at, updated_at FROM users ORDER BY username ASC`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all users: %w", err)
@@ -93,7 +95,7 @@ func (r *UserRepositoryImpl) GetAllUsers(ctx context.Context) ([]domain.User, er
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.DefaultEstablishmentCode, &user.DefaultEmissionPointCode, &user.CreatedAt, &user.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan user row: %w", err)
 		}
 		users = append(users, user)
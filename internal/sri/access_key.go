@@ -2,11 +2,108 @@
 package sri
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// AccessKeyLength es la longitud fija de la clave de acceso de un comprobante electrónico.
+const AccessKeyLength = 49
+
+// ErrInvalidAccessKey envuelve todos los errores de ParseAccessKey.
+var ErrInvalidAccessKey = errors.New("clave de acceso inválida")
+
+// accessKeyDocTypes son los códigos de comprobante (codDoc) que pueden aparecer en una clave de acceso.
+var accessKeyDocTypes = map[string]string{
+	"01": "Factura",
+	"03": "Liquidación de Compra",
+	"04": "Nota de Crédito",
+	"05": "Nota de Débito",
+	"06": "Guía de Remisión",
+	"07": "Comprobante de Retención",
+}
+
+// AccessKey es una clave de acceso descompuesta en sus campos:
+// fecha (8) + codDoc (2) + RUC (13) + ambiente (1) + estab (3) + ptoEmi (3) + secuencial (9) +
+// código numérico (8) + tipo de emisión (1) + dígito verificador (1).
+type AccessKey struct {
+	Raw           string
+	Date          time.Time
+	DocType       string
+	RUC           string
+	Environment   int
+	Establishment string
+	EmissionPoint string
+	Sequential    string
+	NumericCode   string
+	EmissionType  int
+	CheckDigit    int
+}
+
+// DocumentNumber devuelve el número del comprobante con el formato 001-001-000000123.
+func (k *AccessKey) DocumentNumber() string {
+	return fmt.Sprintf("%s-%s-%s", k.Establishment, k.EmissionPoint, k.Sequential)
+}
+
+// DocTypeName devuelve el nombre del tipo de comprobante (ej. "Factura").
+func (k *AccessKey) DocTypeName() string {
+	return accessKeyDocTypes[k.DocType]
+}
+
+// ParseAccessKey valida una clave de acceso (longitud, dígitos, fecha, tipo de comprobante, RUC,
+// ambiente, tipo de emisión y dígito verificador módulo 11) y la descompone en sus campos.
+func ParseAccessKey(key string) (*AccessKey, error) {
+	key = strings.TrimSpace(key)
+	if len(key) != AccessKeyLength {
+		return nil, fmt.Errorf("%w: debe tener %d dígitos y tiene %d", ErrInvalidAccessKey, AccessKeyLength, len(key))
+	}
+	for i, r := range key {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("%w: el carácter %q en la posición %d no es un dígito", ErrInvalidAccessKey, r, i+1)
+		}
+	}
+
+	k := &AccessKey{
+		Raw:           key,
+		DocType:       key[8:10],
+		RUC:           key[10:23],
+		Establishment: key[24:27],
+		EmissionPoint: key[27:30],
+		Sequential:    key[30:39],
+		NumericCode:   key[39:47],
+	}
+
+	date, err := time.Parse("02012006", key[0:8])
+	if err != nil {
+		return nil, fmt.Errorf("%w: la fecha de emisión %s no existe", ErrInvalidAccessKey, key[0:8])
+	}
+	k.Date = date
+
+	if _, ok := accessKeyDocTypes[k.DocType]; !ok {
+		return nil, fmt.Errorf("%w: el tipo de comprobante %s no es válido", ErrInvalidAccessKey, k.DocType)
+	}
+	if !strings.HasSuffix(k.RUC, "001") {
+		return nil, fmt.Errorf("%w: el RUC %s debe terminar en 001", ErrInvalidAccessKey, k.RUC)
+	}
+
+	k.Environment = int(key[23] - '0')
+	if k.Environment != 1 && k.Environment != 2 {
+		return nil, fmt.Errorf("%w: el ambiente %d no es válido (1 = pruebas, 2 = producción)", ErrInvalidAccessKey, k.Environment)
+	}
+	k.EmissionType = int(key[47] - '0')
+	if k.EmissionType != 1 {
+		return nil, fmt.Errorf("%w: el tipo de emisión %d no es válido (debe ser 1 = normal)", ErrInvalidAccessKey, k.EmissionType)
+	}
+
+	k.CheckDigit = int(key[48] - '0')
+	if expected := computeMod11(key[:48]); expected != k.CheckDigit {
+		return nil, fmt.Errorf("%w: el dígito verificador es %d y debería ser %d", ErrInvalidAccessKey, k.CheckDigit, expected)
+	}
+	return k, nil
+}
+
 func GenerateAccessKey(
 	date time.Time,
 	codDoc string,
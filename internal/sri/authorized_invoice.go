@@ -0,0 +1,157 @@
+package sri
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ReceivedInvoice es una factura de proveedor leída de su XML autorizado.
+type ReceivedInvoice struct {
+	Factura *Factura
+	Key     *AccessKey
+	// NumeroAutorizacion y FechaAutorizacion solo vienen cuando el archivo trae la envoltura <autorizacion>.
+	NumeroAutorizacion string
+	FechaAutorizacion  string
+}
+
+// ParseAuthorizedInvoice lee el XML de una factura recibida. Acepta el formato con el que el SRI y
+// los proveedores entregan los comprobantes (<autorizacion> con la factura dentro de <comprobante>),
+// la respuesta completa del servicio de autorización o la <factura> sin envoltura.
+func ParseAuthorizedInvoice(data []byte) (*ReceivedInvoice, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReceivedInvoice{}
+	var comprobante []byte
+	switch root {
+	case "factura":
+		comprobante = data
+	case "autorizacion", "RespuestaAutorizacionComprobante":
+		var auth Autorizacion
+		if root == "autorizacion" {
+			err = xml.Unmarshal(data, &auth)
+		} else {
+			auth, err = authorizedFromResponse(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if auth.Estado != "AUTORIZADO" {
+			return nil, fmt.Errorf("el comprobante no está autorizado (estado: %s)", auth.Estado)
+		}
+		result.NumeroAutorizacion = strings.TrimSpace(auth.NumeroAutorizacion)
+		result.FechaAutorizacion = strings.TrimSpace(auth.FechaAutorizacion)
+		comprobante = []byte(strings.TrimSpace(auth.Comprobante))
+
+		inner, err := rootElement(comprobante)
+		if err != nil {
+			return nil, fmt.Errorf("el comprobante autorizado no es un XML válido: %w", err)
+		}
+		if inner != "factura" {
+			return nil, fmt.Errorf("el comprobante autorizado es un %s; solo se pueden importar facturas", inner)
+		}
+	default:
+		return nil, fmt.Errorf("el archivo no es una factura electrónica (elemento raíz <%s>)", root)
+	}
+
+	var factura Factura
+	if err := xml.Unmarshal(comprobante, &factura); err != nil {
+		return nil, fmt.Errorf("error leyendo la factura: %w", err)
+	}
+	if factura.InfoTributaria.CodDoc != "01" {
+		return nil, fmt.Errorf("el comprobante tiene codDoc %s; solo se pueden importar facturas (01)", factura.InfoTributaria.CodDoc)
+	}
+
+	key, err := ParseAccessKey(factura.InfoTributaria.ClaveAcceso)
+	if err != nil {
+		return nil, err
+	}
+	if key.RUC != factura.InfoTributaria.Ruc {
+		return nil, fmt.Errorf("la clave de acceso pertenece al RUC %s y la factura al %s", key.RUC, factura.InfoTributaria.Ruc)
+	}
+	// En el esquema offline el número de autorización es la misma clave de acceso
+	if len(result.NumeroAutorizacion) == AccessKeyLength && result.NumeroAutorizacion != key.Raw {
+		return nil, errors.New("el número de autorización no coincide con la clave de acceso de la factura")
+	}
+
+	result.Factura = &factura
+	result.Key = key
+	return result, nil
+}
+
+// autorizacionArchivo es el <autorizacion> con el que el SRI entrega un comprobante autorizado.
+type autorizacionArchivo struct {
+	XMLName            xml.Name `xml:"autorizacion"`
+	Estado             string   `xml:"estado"`
+	NumeroAutorizacion string   `xml:"numeroAutorizacion"`
+	FechaAutorizacion  string   `xml:"fechaAutorizacion"`
+	Ambiente           string   `xml:"ambiente"`
+	Comprobante        struct {
+		XML string `xml:",cdata"`
+	} `xml:"comprobante"`
+	Mensajes struct{} `xml:"mensajes"`
+}
+
+// MarshalAutorizacion envuelve un comprobante firmado en el <autorizacion> con el que el SRI lo
+// entrega una vez autorizado. Es el archivo que recibe el cliente y el que se conserva como
+// respaldo legal; en el esquema offline el número de autorización es la clave de acceso.
+func MarshalAutorizacion(claveAcceso string, fechaAutorizacion time.Time, environment int, comprobante string) ([]byte, error) {
+	auth := autorizacionArchivo{
+		Estado:             "AUTORIZADO",
+		NumeroAutorizacion: claveAcceso,
+		FechaAutorizacion:  fechaAutorizacion.Format(time.RFC3339),
+		Ambiente:           "PRUEBAS",
+	}
+	if environment == 2 {
+		auth.Ambiente = "PRODUCCIÓN"
+	}
+	auth.Comprobante.XML = comprobante
+
+	xmlBytes, err := xml.MarshalIndent(auth, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar la autorización: %w", err)
+	}
+	return append([]byte(xml.Header), xmlBytes...), nil
+}
+
+// authorizedFromResponse toma la primera autorización aprobada de una respuesta del servicio de autorización.
+func authorizedFromResponse(data []byte) (Autorizacion, error) {
+	var resp RespuestaAutorizacion
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return Autorizacion{}, fmt.Errorf("error leyendo la respuesta de autorización: %w", err)
+	}
+	auths := resp.Autorizaciones.Autorizacion
+	if len(auths) == 0 {
+		return Autorizacion{}, errors.New("la respuesta de autorización no contiene comprobantes")
+	}
+	for _, a := range auths {
+		if a.Estado == "AUTORIZADO" {
+			return a, nil
+		}
+	}
+	return auths[0], nil
+}
+
+// rootElement devuelve el nombre local del primer elemento del documento.
+func rootElement(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return "", errors.New("el archivo está vacío o no contiene XML")
+		}
+		if err != nil {
+			return "", fmt.Errorf("XML mal formado: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package sri
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func supplierFacturaXML(t *testing.T) (string, string) {
+	t.Helper()
+	f := validFactura()
+	f.InfoTributaria.ClaveAcceso = GenerateAccessKey(
+		time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local), "01", f.InfoTributaria.Ruc, 2, "001", "002", "000000077", "12345678", 1,
+	)
+	xmlBytes, err := MarshalFactura(f)
+	require.NoError(t, err)
+	return string(xmlBytes), f.InfoTributaria.ClaveAcceso
+}
+
+func TestParseAuthorizedInvoice(t *testing.T) {
+	facturaXML, key := supplierFacturaXML(t)
+
+	t.Run("Reads the authorization wrapper with CDATA", func(t *testing.T) {
+		data := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<autorizacion>
+  <estado>AUTORIZADO</estado>
+  <numeroAutorizacion>%s</numeroAutorizacion>
+  <fechaAutorizacion>2026-01-10T10:15:00-05:00</fechaAutorizacion>
+  <ambiente>PRODUCCIÓN</ambiente>
+  <comprobante><![CDATA[%s]]></comprobante>
+</autorizacion>`, key, facturaXML)
+
+		inv, err := ParseAuthorizedInvoice([]byte(data))
+		require.NoError(t, err)
+		assert.Equal(t, "001-002-000000077", inv.Key.DocumentNumber())
+		assert.Equal(t, key, inv.NumeroAutorizacion)
+		assert.Equal(t, "2026-01-10T10:15:00-05:00", inv.FechaAutorizacion)
+		assert.Equal(t, "11.50", inv.Factura.InfoFactura.ImporteTotal)
+		require.Len(t, inv.Factura.Detalles.Detalle, 1)
+	})
+
+	t.Run("Reads a bare invoice", func(t *testing.T) {
+		inv, err := ParseAuthorizedInvoice([]byte(facturaXML))
+		require.NoError(t, err)
+		assert.Equal(t, key, inv.Key.Raw)
+		assert.Empty(t, inv.NumeroAutorizacion)
+	})
+
+	t.Run("Reads the authorization service response", func(t *testing.T) {
+		data := fmt.Sprintf(`<RespuestaAutorizacionComprobante>
+  <claveAccesoConsultada>%s</claveAccesoConsultada>
+  <numeroComprobantes>1</numeroComprobantes>
+  <autorizaciones><autorizacion><estado>AUTORIZADO</estado><comprobante>%s</comprobante></autorizacion></autorizaciones>
+</RespuestaAutorizacionComprobante>`, key, escapeXMLText(facturaXML))
+
+		inv, err := ParseAuthorizedInvoice([]byte(data))
+		require.NoError(t, err)
+		assert.Equal(t, key, inv.Key.Raw)
+	})
+
+	t.Run("Rejects documents that cannot be imported", func(t *testing.T) {
+		cases := map[string]string{
+			"no está autorizado":               `<autorizacion><estado>NO AUTORIZADO</estado><comprobante>x</comprobante></autorizacion>`,
+			"solo se pueden importar facturas": `<autorizacion><estado>AUTORIZADO</estado><comprobante><![CDATA[<notaCredito id="comprobante"/>]]></comprobante></autorizacion>`,
+			"no es una factura electrónica":    `<comprobanteRetencion/>`,
+			"pertenece al RUC 1790012345001":   strings.Replace(facturaXML, "<ruc>1790012345001</ruc>", "<ruc>0990012345001</ruc>", 1),
+			"clave de acceso inválida":         strings.Replace(facturaXML, key, key[:48]+"0", 1),
+			"error leyendo la factura":         `<factura><infoTributaria>`,
+		}
+		for want, data := range cases {
+			_, err := ParseAuthorizedInvoice([]byte(data))
+			require.Error(t, err, want)
+			assert.Contains(t, err.Error(), want)
+		}
+	})
+}
+
+func escapeXMLText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func TestMarshalAutorizacion(t *testing.T) {
+	facturaXML, key := supplierFacturaXML(t)
+	fecha := time.Date(2026, 1, 10, 10, 15, 0, 0, time.FixedZone("ECT", -5*60*60))
+
+	data, err := MarshalAutorizacion(key, fecha, 2, facturaXML)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<ambiente>PRODUCCIÓN</ambiente>")
+	assert.Contains(t, string(data), "<comprobante><![CDATA[")
+
+	// Lo que se archiva se vuelve a leer igual que un XML autorizado recibido de un proveedor
+	inv, err := ParseAuthorizedInvoice(data)
+	require.NoError(t, err)
+	assert.Equal(t, key, inv.NumeroAutorizacion)
+	assert.Equal(t, "2026-01-10T10:15:00-05:00", inv.FechaAutorizacion)
+	assert.Equal(t, "11.50", inv.Factura.InfoFactura.ImporteTotal)
+}
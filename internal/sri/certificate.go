@@ -0,0 +1,199 @@
+package sri
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// CertificateWarningDays es la anticipación con la que se avisa que el certificado de firma vence.
+const CertificateWarningDays = 30
+
+var (
+	// ErrCertificateExpired indica que el certificado de firma ya no sirve para firmar comprobantes.
+	ErrCertificateExpired = errors.New("el certificado de firma electrónica está vencido")
+	// ErrCertificateRUC indica que el certificado de firma es de otro contribuyente.
+	ErrCertificateRUC = errors.New("el certificado de firma electrónica no corresponde al RUC del emisor")
+)
+
+// Extensiones en las que las entidades de certificación del Ecuador guardan la identificación del
+// titular. El RUC va primero: en los certificados de persona jurídica la cédula es la del
+// representante legal.
+var identificationOIDs = []asn1.ObjectIdentifier{
+	{1, 3, 6, 1, 4, 1, 37746, 3, 11}, // Security Data: RUC
+	{1, 3, 6, 1, 4, 1, 37947, 3, 11}, // Banco Central: RUC
+	{1, 3, 6, 1, 4, 1, 37746, 3, 1},  // Security Data: cédula
+	{1, 3, 6, 1, 4, 1, 37947, 3, 1},  // Banco Central: cédula
+}
+
+var identificationPattern = regexp.MustCompile(`\d{13}|\d{10}`)
+
+// CertificateInfo son los datos del certificado de firma electrónica de un emisor.
+type CertificateInfo struct {
+	Subject        string // Titular
+	Identification string // RUC o cédula del titular
+	Issuer         string // Entidad de certificación
+	SerialNumber   string
+	NotBefore      time.Time
+	NotAfter       time.Time
+}
+
+// InspectCertificate abre el archivo .p12 con su contraseña y lee los datos del certificado de firma.
+func InspectCertificate(p12Path, password string) (*CertificateInfo, error) {
+	p12Bytes, err := os.ReadFile(p12Path)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el certificado .p12: %w", err)
+	}
+	return ParseCertificate(p12Bytes, password)
+}
+
+// ParseCertificate lee los datos del certificado de firma contenido en un .p12.
+func ParseCertificate(p12Bytes []byte, password string) (*CertificateInfo, error) {
+	blocks, err := p12Blocks(p12Bytes, password)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := signingCertificate(blocks)
+	if err != nil {
+		return nil, err
+	}
+	return newCertificateInfo(cert), nil
+}
+
+// p12Blocks descifra el .p12 con su contraseña y devuelve su contenido como bloques PEM.
+func p12Blocks(p12Bytes []byte, password string) ([]*pem.Block, error) {
+	blocks, err := pkcs12.ToPEM(p12Bytes, password)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return nil, errors.New("la contraseña del certificado .p12 es incorrecta")
+		}
+		return nil, fmt.Errorf("error al abrir el certificado .p12: %w", err)
+	}
+	return blocks, nil
+}
+
+// signingCertificate elige el certificado de firma entre los del .p12, que suele traer también la
+// cadena de la entidad de certificación: el que comparte localKeyId con la clave privada o, si no
+// hay esa marca, el primero que no es de una entidad.
+func signingCertificate(blocks []*pem.Block) (*x509.Certificate, error) {
+	var keyID string
+	for _, b := range blocks {
+		if b.Type == "PRIVATE KEY" {
+			keyID = b.Headers["localKeyId"]
+		}
+	}
+
+	var fallback *x509.Certificate
+	for _, b := range blocks {
+		if b.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(b.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer el certificado: %w", err)
+		}
+		if keyID != "" && b.Headers["localKeyId"] == keyID {
+			return cert, nil
+		}
+		if fallback == nil && !cert.IsCA {
+			fallback = cert
+		}
+	}
+	if fallback == nil {
+		return nil, errors.New("el archivo .p12 no contiene un certificado de firma")
+	}
+	return fallback, nil
+}
+
+func newCertificateInfo(cert *x509.Certificate) *CertificateInfo {
+	issuer := cert.Issuer.CommonName
+	if issuer == "" && len(cert.Issuer.Organization) > 0 {
+		issuer = cert.Issuer.Organization[0]
+	}
+	return &CertificateInfo{
+		Subject:        cert.Subject.CommonName,
+		Identification: certificateIdentification(cert),
+		Issuer:         issuer,
+		SerialNumber:   cert.SerialNumber.Text(16),
+		NotBefore:      cert.NotBefore,
+		NotAfter:       cert.NotAfter,
+	}
+}
+
+// certificateIdentification busca el RUC o la cédula del titular en las extensiones de las
+// entidades ecuatorianas y, si no están, en el número de serie del titular (ej. "IDCEC-1712345678").
+func certificateIdentification(cert *x509.Certificate) string {
+	for _, oid := range identificationOIDs {
+		for _, ext := range cert.Extensions {
+			if !ext.Id.Equal(oid) {
+				continue
+			}
+			if id := identificationPattern.FindString(extensionText(ext.Value)); id != "" {
+				return id
+			}
+		}
+	}
+	return identificationPattern.FindString(cert.Subject.SerialNumber)
+}
+
+// extensionText devuelve el texto de una extensión, que según la entidad viene como cadena ASN.1 o
+// como texto plano.
+func extensionText(value []byte) string {
+	var s string
+	if rest, err := asn1.Unmarshal(value, &s); err == nil && len(rest) == 0 {
+		return s
+	}
+	return string(value)
+}
+
+// Expired indica si el certificado ya venció.
+func (c *CertificateInfo) Expired(now time.Time) bool {
+	return now.After(c.NotAfter)
+}
+
+// DaysToExpiry son los días completos que le quedan al certificado.
+func (c *CertificateInfo) DaysToExpiry(now time.Time) int {
+	return int(c.NotAfter.Sub(now).Hours() / 24)
+}
+
+// ExpiresSoon indica si el certificado vence dentro de los próximos CertificateWarningDays días.
+func (c *CertificateInfo) ExpiresSoon(now time.Time) bool {
+	return !c.Expired(now) && c.NotAfter.Before(now.AddDate(0, 0, CertificateWarningDays))
+}
+
+// MatchesRUC indica si el certificado es del contribuyente con ese RUC. El RUC de una persona
+// natural es su cédula seguida de 001.
+func (c *CertificateInfo) MatchesRUC(ruc string) bool {
+	switch len(c.Identification) {
+	case 13:
+		return c.Identification == ruc
+	case 10:
+		return len(ruc) == 13 && strings.HasPrefix(ruc, c.Identification)
+	}
+	return false
+}
+
+// Validate verifica que el certificado esté vigente y sea del emisor con ese RUC.
+func (c *CertificateInfo) Validate(ruc string, now time.Time) error {
+	if c.Expired(now) {
+		return fmt.Errorf("%w desde el %s", ErrCertificateExpired, c.NotAfter.Format("02/01/2006"))
+	}
+	if now.Before(c.NotBefore) {
+		return fmt.Errorf("el certificado de firma electrónica es válido recién desde el %s", c.NotBefore.Format("02/01/2006"))
+	}
+	if c.Identification == "" {
+		return fmt.Errorf("%w: no se encontró el RUC ni la cédula del titular en el certificado", ErrCertificateRUC)
+	}
+	if !c.MatchesRUC(ruc) {
+		return fmt.Errorf("%w: el certificado es de %s (%s) y el RUC del emisor es %s", ErrCertificateRUC, c.Subject, c.Identification, ruc)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package sri
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectCertificate(t *testing.T) {
+	// testdata/firma_prueba.p12: certificado de JUAN PEREZ con RUC 1790012345001 en la extensión de
+	// Security Data, emitido por una entidad de prueba cuya cadena viene en el mismo archivo.
+	t.Run("Reads the signing certificate of the .p12", func(t *testing.T) {
+		info, err := InspectCertificate("testdata/firma_prueba.p12", "Prueba123")
+		require.NoError(t, err)
+		assert.Equal(t, "JUAN PEREZ", info.Subject)
+		assert.Equal(t, "1790012345001", info.Identification)
+		assert.Equal(t, "AC PRUEBAS VERITH", info.Issuer)
+		assert.NotEmpty(t, info.SerialNumber)
+		assert.True(t, info.NotBefore.Before(info.NotAfter))
+	})
+
+	t.Run("Rejects a wrong password", func(t *testing.T) {
+		_, err := InspectCertificate("testdata/firma_prueba.p12", "otra")
+		assert.ErrorContains(t, err, "contraseña del certificado .p12 es incorrecta")
+	})
+
+	t.Run("Rejects a file that is not a .p12", func(t *testing.T) {
+		path := t.TempDir() + "/firma.p12"
+		require.NoError(t, os.WriteFile(path, []byte("no es un certificado"), 0o600))
+		_, err := InspectCertificate(path, "Prueba123")
+		assert.ErrorContains(t, err, "error al abrir el certificado .p12")
+	})
+}
+
+func TestCertificateInfo(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Takes the identification from the subject serial number", func(t *testing.T) {
+		cert := selfSignedCertificate(t, pkix.Name{CommonName: "MARIA LOPEZ", SerialNumber: "IDCEC-1712345678"})
+		assert.Equal(t, "1712345678", newCertificateInfo(cert).Identification)
+	})
+
+	t.Run("Matches the RUC of a natural person by its cédula", func(t *testing.T) {
+		info := &CertificateInfo{Identification: "1712345678"}
+		assert.True(t, info.MatchesRUC("1712345678001"))
+		assert.False(t, info.MatchesRUC("1790012345001"))
+
+		info.Identification = "1790012345001"
+		assert.True(t, info.MatchesRUC("1790012345001"))
+		assert.False(t, info.MatchesRUC("1790012345"))
+	})
+
+	t.Run("Validates expiry and owner", func(t *testing.T) {
+		info := &CertificateInfo{Subject: "JUAN PEREZ", Identification: "1790012345001", NotBefore: now.AddDate(-1, 0, 0), NotAfter: now.AddDate(0, 0, 10)}
+		assert.NoError(t, info.Validate("1790012345001", now))
+		assert.True(t, info.ExpiresSoon(now))
+		assert.Equal(t, 10, info.DaysToExpiry(now))
+		assert.ErrorIs(t, info.Validate("0990012345001", now), ErrCertificateRUC)
+
+		info.NotAfter = now.AddDate(0, 0, CertificateWarningDays+1)
+		assert.False(t, info.ExpiresSoon(now))
+
+		info.NotAfter = now.Add(-time.Hour)
+		assert.True(t, info.Expired(now))
+		assert.False(t, info.ExpiresSoon(now))
+		assert.ErrorIs(t, info.Validate("1790012345001", now), ErrCertificateExpired)
+
+		assert.ErrorIs(t, (&CertificateInfo{NotAfter: now.AddDate(1, 0, 0)}).Validate("1790012345001", now), ErrCertificateRUC)
+	})
+}
+
+func selfSignedCertificate(t *testing.T, subject pkix.Name) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
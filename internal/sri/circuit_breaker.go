@@ -0,0 +1,62 @@
+package sri
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker deja de llamar al SRI tras varias fallas transitorias seguidas, para que una caída
+// del servicio no bloquee la aplicación esperando timeouts. Pasado el enfriamiento deja pasar una
+// llamada de prueba: si funciona el circuito se cierra, si falla se abre de nuevo.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+// NewCircuitBreaker crea un circuito que se abre tras threshold fallas seguidas durante cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Allow indica si se puede llamar al SRI. Con el circuito abierto solo deja pasar una llamada de
+// prueba por cada período de enfriamiento.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.openedAt = b.now() // Las demás llamadas esperan el resultado de esta
+	return true
+}
+
+// Success cierra el circuito.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+// Failure registra una falla transitoria y abre el circuito al llegar al umbral.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// Open indica si el circuito está abierto.
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold
+}
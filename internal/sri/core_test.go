@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateAccessKey(t *testing.T) {
@@ -34,6 +35,51 @@ func TestGenerateAccessKey(t *testing.T) {
 	})
 }
 
+func TestParseAccessKey(t *testing.T) {
+	date := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	key := GenerateAccessKey(date, "01", "1790012345001", 2, "001", "002", "000000077", "12345678", 1)
+
+	t.Run("Parses every field", func(t *testing.T) {
+		k, err := ParseAccessKey(" " + key + "\n")
+		require.NoError(t, err)
+
+		assert.Equal(t, key, k.Raw)
+		assert.True(t, date.Equal(k.Date))
+		assert.Equal(t, "01", k.DocType)
+		assert.Equal(t, "Factura", k.DocTypeName())
+		assert.Equal(t, "1790012345001", k.RUC)
+		assert.Equal(t, 2, k.Environment)
+		assert.Equal(t, "001", k.Establishment)
+		assert.Equal(t, "002", k.EmissionPoint)
+		assert.Equal(t, "000000077", k.Sequential)
+		assert.Equal(t, "12345678", k.NumericCode)
+		assert.Equal(t, 1, k.EmissionType)
+		assert.Equal(t, "001-002-000000077", k.DocumentNumber())
+	})
+
+	t.Run("Rejects malformed keys", func(t *testing.T) {
+		wrongDigit := (int(key[48]-'0') + 1) % 10
+		cases := map[string]struct {
+			key     string
+			message string
+		}{
+			"short":       {key[:48], "debe tener 49 dígitos y tiene 48"},
+			"letters":     {key[:10] + "A" + key[11:], "posición 11"},
+			"check digit": {key[:48] + string(rune('0'+wrongDigit)), "dígito verificador"},
+			"date":        {"3102" + key[4:], "fecha de emisión 31022026"},
+			"doc type":    {GenerateAccessKey(date, "99", "1790012345001", 2, "001", "002", "000000077", "12345678", 1), "tipo de comprobante 99"},
+			"environment": {GenerateAccessKey(date, "01", "1790012345001", 3, "001", "002", "000000077", "12345678", 1), "ambiente 3"},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				_, err := ParseAccessKey(tc.key)
+				assert.ErrorIs(t, err, ErrInvalidAccessKey)
+				assert.ErrorContains(t, err, tc.message)
+			})
+		}
+	})
+}
+
 func TestMarshalFactura(t *testing.T) {
 	t.Run("Should include XML declaration", func(t *testing.T) {
 		f := &Factura{
@@ -48,4 +94,18 @@ func TestMarshalFactura(t *testing.T) {
 		assert.Contains(t, xmlStr, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>")
 		assert.Contains(t, xmlStr, "<factura id=\"comprobante\" version=\"2.1.0\">")
 	})
-}
\ No newline at end of file
+}
+
+func TestMarshalComprobanteRetencion(t *testing.T) {
+	t.Run("Should default to version 2.0.0 with docsSustento", func(t *testing.T) {
+		cr := &ComprobanteRetencion{}
+		cr.DocsSustento.DocSustento = append(cr.DocsSustento.DocSustento, DocSustento{CodDocSustento: "01"})
+
+		bytes, err := MarshalComprobanteRetencion(cr)
+		assert.NoError(t, err)
+
+		xmlStr := string(bytes)
+		assert.Contains(t, xmlStr, "<comprobanteRetencion id=\"comprobante\" version=\"2.0.0\">")
+		assert.Contains(t, xmlStr, "<docsSustento><docSustento>")
+	})
+}
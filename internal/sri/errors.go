@@ -0,0 +1,115 @@
+package sri
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrCircuitOpen se devuelve sin llamar al SRI mientras el circuito está abierto por fallas seguidas.
+var ErrCircuitOpen = errors.New("el SRI no responde; se reintentará en unos minutos")
+
+// NetworkError es una llamada que no obtuvo una respuesta válida del SRI: falla de conexión, timeout,
+// respuesta ilegible o un estado HTTP de error sin SOAP Fault.
+type NetworkError struct {
+	Op         string // "recepción" o "autorización"
+	StatusCode int    // 0 si no hubo respuesta HTTP
+	Err        error
+}
+
+func (e *NetworkError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("el SRI (%s) respondió con estado HTTP: %d", e.Op, e.StatusCode)
+	}
+	return fmt.Sprintf("error de conexión con el SRI (%s): %v", e.Op, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// Transient indica si reintentar puede funcionar: sin respuesta, HTTP 5xx o 429.
+func (e *NetworkError) Transient() bool {
+	return e.StatusCode == 0 || e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// SOAPFaultError es un soap:Fault del SRI: el servicio respondió pero rechazó la petición en sí,
+// así que reintentarla no sirve.
+type SOAPFaultError struct {
+	Code    string `xml:"faultcode"`
+	Message string `xml:"faultstring"`
+}
+
+func (e *SOAPFaultError) Error() string {
+	return fmt.Sprintf("el SRI rechazó la petición SOAP (%s): %s", e.Code, e.Message)
+}
+
+// RejectionError es un comprobante que el SRI procesó y rechazó: DEVUELTA en recepción o
+// NO AUTORIZADO en autorización. Los clientes lo entregan como parte de la respuesta; se obtiene
+// con RespuestaRecepcion.Rechazo y Autorizacion.Rechazo.
+type RejectionError struct {
+	ClaveAcceso string
+	Estado      string
+	Mensajes    []Mensaje
+}
+
+func (e *RejectionError) Error() string {
+	if len(e.Mensajes) == 0 {
+		return fmt.Sprintf("comprobante %s", e.Estado)
+	}
+	m := e.Mensajes[0]
+	msg := fmt.Sprintf("comprobante %s: %s %s", e.Estado, m.Identificador, m.Mensaje)
+	if m.InformacionAdicional != "" {
+		msg += " (" + m.InformacionAdicional + ")"
+	}
+	return msg
+}
+
+// Message devuelve el texto del primer mensaje del SRI, o el estado si no trae mensajes.
+func (e *RejectionError) Message() string {
+	if len(e.Mensajes) == 0 {
+		return e.Estado
+	}
+	return e.Mensajes[0].Mensaje
+}
+
+// EnProcesamiento indica una devolución porque la clave ya se está procesando (código 70): el
+// comprobante llegó en un envío anterior y solo falta consultar su autorización.
+func (e *RejectionError) EnProcesamiento() bool {
+	for _, m := range e.Mensajes {
+		if m.Identificador == "70" || strings.Contains(strings.ToUpper(m.Mensaje), "EN PROCESAMIENTO") {
+			return true
+		}
+	}
+	return false
+}
+
+// Rechazo devuelve el rechazo si el comprobante fue DEVUELTO, o nil si se recibió.
+func (r *RespuestaRecepcion) Rechazo() *RejectionError {
+	if r.Estado != "DEVUELTA" {
+		return nil
+	}
+	rej := &RejectionError{Estado: r.Estado}
+	if len(r.Comprobantes.Comprobante) > 0 {
+		rej.ClaveAcceso = r.Comprobantes.Comprobante[0].ClaveAcceso
+		rej.Mensajes = r.Comprobantes.Comprobante[0].Mensajes.Mensaje
+	}
+	return rej
+}
+
+// Rechazo devuelve el rechazo si la autorización fue negada, o nil si está autorizada o en proceso.
+func (a *Autorizacion) Rechazo() *RejectionError {
+	switch a.Estado {
+	case "NO AUTORIZADO", "RECHAZADA", "RECHAZADO":
+		return &RejectionError{ClaveAcceso: a.NumeroAutorizacion, Estado: a.Estado, Mensajes: a.Mensajes.Mensaje}
+	}
+	return nil
+}
+
+// IsTransient indica si el error se debe a que el SRI no está disponible y conviene reintentar más tarde.
+func IsTransient(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var netErr *NetworkError
+	return errors.As(err, &netErr) && netErr.Transient()
+}
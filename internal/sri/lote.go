@@ -0,0 +1,115 @@
+package sri
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// MaxLoteComprobantes es la cantidad máxima de comprobantes que se envían en un mismo lote.
+const MaxLoteComprobantes = 50
+
+// Lote agrupa comprobantes firmados del mismo tipo y emisor para enviarlos al SRI en una sola
+// petición de recepción. Cada comprobante conserva su propia firma; el lote no se firma.
+type Lote struct {
+	XMLName      xml.Name          `xml:"lote"`
+	Version      string            `xml:"version,attr"`
+	ClaveAcceso  string            `xml:"claveAcceso"`
+	Ruc          string            `xml:"ruc"`
+	Comprobantes []LoteComprobante `xml:"comprobantes>comprobante"`
+}
+
+// LoteComprobante es el XML firmado de un comprobante, que viaja como CDATA.
+type LoteComprobante struct {
+	XML string `xml:",cdata"`
+}
+
+// GenerateLoteAccessKey arma la clave de acceso del lote con los datos de uno de sus comprobantes.
+// El SRI la trata como la de un comprobante más, así que el secuencial y el código numérico solo
+// tienen que hacerla única.
+func GenerateLoteAccessKey(date time.Time, key *AccessKey, sequential, numericCode string) string {
+	return GenerateAccessKey(date, key.DocType, key.RUC, key.Environment, key.Establishment, key.EmissionPoint, sequential, numericCode, 1)
+}
+
+// MarshalLote genera el XML del lote con los comprobantes firmados.
+func MarshalLote(claveAcceso, ruc string, comprobantes []string) ([]byte, error) {
+	if len(comprobantes) == 0 {
+		return nil, fmt.Errorf("el lote no tiene comprobantes")
+	}
+	if len(comprobantes) > MaxLoteComprobantes {
+		return nil, fmt.Errorf("el lote tiene %d comprobantes y el máximo es %d", len(comprobantes), MaxLoteComprobantes)
+	}
+
+	lote := Lote{Version: "1.0.0", ClaveAcceso: claveAcceso, Ruc: ruc}
+	for _, c := range comprobantes {
+		lote.Comprobantes = append(lote.Comprobantes, LoteComprobante{XML: c})
+	}
+
+	xmlBytes, err := xml.Marshal(lote)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar el lote: %w", err)
+	}
+	return append([]byte(xml.Header), xmlBytes...), nil
+}
+
+// --- AUTORIZACIÓN DE LOTE ---
+
+type RespuestaAutorizacionLote struct {
+	ClaveAccesoLoteConsultada string `xml:"claveAccesoLoteConsultada"`
+	NumeroComprobantesLote    string `xml:"numeroComprobantesLote"`
+	Autorizaciones            struct {
+		Autorizacion []Autorizacion `xml:"autorizacion"`
+	} `xml:"autorizaciones"`
+}
+
+type SoapAutorizacionLoteEnvelope struct {
+	Body struct {
+		AutorizacionComprobanteLoteResponse struct {
+			RespuestaAutorizacionLote RespuestaAutorizacionLote `xml:"RespuestaAutorizacionLote"`
+		} `xml:"autorizacionComprobanteLoteResponse"`
+	} `xml:"Body"`
+}
+
+var claveEnComprobante = regexp.MustCompile(`<claveAcceso>\s*(\d{49})\s*</claveAcceso>`)
+
+// ClaveAcceso devuelve la clave del comprobante autorizado. Un comprobante no autorizado no tiene
+// número de autorización, así que se toma de su XML.
+func (a *Autorizacion) ClaveAcceso() string {
+	if len(a.NumeroAutorizacion) == AccessKeyLength {
+		return a.NumeroAutorizacion
+	}
+	if m := claveEnComprobante.FindStringSubmatch(a.Comprobante); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// AutorizarLote consulta la autorización de todos los comprobantes de un lote con su clave de acceso.
+// El lote se envía con EnviarComprobante: la recepción del SRI acepta lotes en la misma operación.
+func (c *SoapClient) AutorizarLote(ctx context.Context, claveAccesoLote string, environment int) (*RespuestaAutorizacionLote, error) {
+	url := URLAutorizacionPruebas
+	if environment == 2 {
+		url = URLAutorizacionProduccion
+	}
+	if c.AutorizacionURL != "" {
+		url = c.AutorizacionURL
+	}
+
+	soapEnvelope := fmt.Sprintf(`
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ec="http://ec.gob.sri.ws.autorizacion">
+   <soapenv:Header/>
+   <soapenv:Body>
+      <ec:autorizacionComprobanteLote>
+         <claveAccesoLote>%s</claveAccesoLote>
+      </ec:autorizacionComprobanteLote>
+   </soapenv:Body>
+</soapenv:Envelope>`, claveAccesoLote)
+
+	var envelope SoapAutorizacionLoteEnvelope
+	if err := c.call(ctx, "autorización", url, soapEnvelope, &envelope); err != nil {
+		return nil, err
+	}
+	return &envelope.Body.AutorizacionComprobanteLoteResponse.RespuestaAutorizacionLote, nil
+}
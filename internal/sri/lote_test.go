@@ -0,0 +1,50 @@
+package sri
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalLote(t *testing.T) {
+	facturaXML, key := supplierFacturaXML(t)
+	parsed, err := ParseAccessKey(key)
+	require.NoError(t, err)
+
+	t.Run("Wraps the signed receipts as CDATA", func(t *testing.T) {
+		claveLote := GenerateLoteAccessKey(time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local), parsed, "000000001", "12345678")
+		_, err := ParseAccessKey(claveLote)
+		require.NoError(t, err)
+
+		data, err := MarshalLote(claveLote, parsed.RUC, []string{facturaXML, facturaXML})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "<comprobante><![CDATA[")
+
+		var lote Lote
+		require.NoError(t, xml.Unmarshal(data, &lote))
+		assert.Equal(t, "1.0.0", lote.Version)
+		assert.Equal(t, claveLote, lote.ClaveAcceso)
+		assert.Equal(t, parsed.RUC, lote.Ruc)
+		require.Len(t, lote.Comprobantes, 2)
+		assert.Equal(t, facturaXML, lote.Comprobantes[0].XML)
+	})
+
+	t.Run("Rejects empty and oversized lotes", func(t *testing.T) {
+		_, err := MarshalLote(key, parsed.RUC, nil)
+		assert.Error(t, err)
+
+		many := strings.Split(strings.Repeat("x,", MaxLoteComprobantes+1), ",")[:MaxLoteComprobantes+1]
+		_, err = MarshalLote(key, parsed.RUC, many)
+		assert.ErrorContains(t, err, "el máximo es")
+	})
+
+	t.Run("Reads the access key of an unauthorized receipt from its XML", func(t *testing.T) {
+		a := Autorizacion{Estado: "NO AUTORIZADO", Comprobante: facturaXML}
+		assert.Equal(t, key, a.ClaveAcceso())
+		assert.Empty(t, (&Autorizacion{}).ClaveAcceso())
+	})
+}
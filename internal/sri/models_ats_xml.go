@@ -0,0 +1,134 @@
+package sri
+
+import "encoding/xml"
+
+// ATS representa el Anexo Transaccional Simplificado (raíz <iva>) que se carga mensualmente en el
+// DIMM del SRI. A diferencia de los comprobantes no se firma: los montos van como texto con dos decimales.
+type ATS struct {
+	XMLName               xml.Name                  `xml:"iva"`
+	TipoIDInformante      string                    `xml:"TipoIDInformante"` // R = RUC
+	IdInformante          string                    `xml:"IdInformante"`
+	RazonSocial           string                    `xml:"razonSocial"` // Sin tildes ni signos de puntuación
+	Anio                  string                    `xml:"Anio"`
+	Mes                   string                    `xml:"Mes"`
+	NumEstabRuc           string                    `xml:"numEstabRuc"`
+	TotalVentas           string                    `xml:"totalVentas"`
+	CodigoOperativo       string                    `xml:"codigoOperativo"` // IVA
+	Compras               *ATSCompras               `xml:"compras,omitempty"`
+	Ventas                *ATSVentas                `xml:"ventas,omitempty"`
+	VentasEstablecimiento *ATSVentasEstablecimiento `xml:"ventasEstablecimiento,omitempty"`
+	Anulados              *ATSAnulados              `xml:"anulados,omitempty"`
+}
+
+type ATSCompras struct {
+	DetalleCompras []DetalleCompras `xml:"detalleCompras"`
+}
+
+// DetalleCompras es un comprobante de compra con las retenciones que se hicieron sobre él.
+type DetalleCompras struct {
+	CodSustento       string           `xml:"codSustento"`
+	TpIdProv          string           `xml:"tpIdProv"` // 01 RUC, 02 Cédula, 03 Pasaporte
+	IdProv            string           `xml:"idProv"`
+	TipoComprobante   string           `xml:"tipoComprobante"`
+	TipoProv          string           `xml:"tipoProv,omitempty"` // Solo pasaporte: 01 persona natural, 02 sociedad
+	DenoProv          string           `xml:"denoProv,omitempty"`
+	ParteRel          string           `xml:"parteRel"`
+	FechaRegistro     string           `xml:"fechaRegistro"`
+	Establecimiento   string           `xml:"establecimiento"`
+	PuntoEmision      string           `xml:"puntoEmision"`
+	Secuencial        string           `xml:"secuencial"` // Sin ceros a la izquierda
+	FechaEmision      string           `xml:"fechaEmision"`
+	Autorizacion      string           `xml:"autorizacion"`
+	BaseNoGraIva      string           `xml:"baseNoGraIva"`
+	BaseImponible     string           `xml:"baseImponible"` // Base 0%
+	BaseImpGrav       string           `xml:"baseImpGrav"`   // Base gravada con IVA
+	BaseImpExe        string           `xml:"baseImpExe"`
+	MontoIce          string           `xml:"montoIce"`
+	MontoIva          string           `xml:"montoIva"`
+	ValRetBien10      string           `xml:"valRetBien10"`
+	ValRetServ20      string           `xml:"valRetServ20"`
+	ValorRetBienes    string           `xml:"valorRetBienes"` // 30%
+	ValRetServ50      string           `xml:"valRetServ50"`
+	ValorRetServicios string           `xml:"valorRetServicios"` // 70%
+	ValRetServ100     string           `xml:"valRetServ100"`
+	TotbasesImpReemb  string           `xml:"totbasesImpReemb"`
+	PagoExterior      ATSPagoExterior  `xml:"pagoExterior"`
+	FormasDePago      *ATSFormasDePago `xml:"formasDePago,omitempty"`
+	Air               *ATSAir          `xml:"air,omitempty"`
+	EstabRetencion1   string           `xml:"estabRetencion1,omitempty"`
+	PtoEmiRetencion1  string           `xml:"ptoEmiRetencion1,omitempty"`
+	SecRetencion1     string           `xml:"secRetencion1,omitempty"`
+	AutRetencion1     string           `xml:"autRetencion1,omitempty"`
+	FechaEmiRet1      string           `xml:"fechaEmiRet1,omitempty"`
+}
+
+type ATSPagoExterior struct {
+	PagoLocExt         string `xml:"pagoLocExt"` // 01 Local
+	PaisEfecPago       string `xml:"paisEfecPago"`
+	AplicConvDobTrib   string `xml:"aplicConvDobTrib"`
+	PagExtSujRetNorLeg string `xml:"pagExtSujRetNorLeg"`
+}
+
+type ATSFormasDePago struct {
+	FormaPago []string `xml:"formaPago"`
+}
+
+// ATSAir agrupa las retenciones en la fuente de Impuesto a la Renta.
+type ATSAir struct {
+	DetalleAir []DetalleAir `xml:"detalleAir"`
+}
+
+type DetalleAir struct {
+	CodRetAir     string `xml:"codRetAir"`
+	BaseImpAir    string `xml:"baseImpAir"`
+	PorcentajeAir string `xml:"porcentajeAir"`
+	ValRetAir     string `xml:"valRetAir"`
+}
+
+type ATSVentas struct {
+	DetalleVentas []DetalleVentas `xml:"detalleVentas"`
+}
+
+// DetalleVentas totaliza los comprobantes de un mismo tipo emitidos a un cliente en el mes.
+type DetalleVentas struct {
+	TpIdCliente        string           `xml:"tpIdCliente"` // 04 RUC, 05 Cédula, 06 Pasaporte, 07 Consumidor Final
+	IdCliente          string           `xml:"idCliente"`
+	ParteRelVtas       string           `xml:"parteRelVtas,omitempty"` // No aplica a consumidor final
+	TipoCliente        string           `xml:"tipoCliente,omitempty"`
+	DenoCli            string           `xml:"denoCli,omitempty"`
+	TipoComprobante    string           `xml:"tipoComprobante"` // 18 Factura, 04 Nota de Crédito, 05 Nota de Débito
+	TipoEmision        string           `xml:"tipoEmision"`     // E electrónica, F física
+	NumeroComprobantes int              `xml:"numeroComprobantes"`
+	BaseNoGraIva       string           `xml:"baseNoGraIva"`
+	BaseImponible      string           `xml:"baseImponible"`
+	BaseImpGrav        string           `xml:"baseImpGrav"`
+	MontoIva           string           `xml:"montoIva"`
+	MontoIce           string           `xml:"montoIce"`
+	ValorRetIva        string           `xml:"valorRetIva"`
+	ValorRetRenta      string           `xml:"valorRetRenta"`
+	FormasDePago       *ATSFormasDePago `xml:"formasDePago,omitempty"` // No aplica a notas de crédito
+}
+
+type ATSVentasEstablecimiento struct {
+	VentaEst []VentaEst `xml:"ventaEst"`
+}
+
+type VentaEst struct {
+	CodEstab    string `xml:"codEstab"`
+	VentasEstab string `xml:"ventasEstab"`
+	IvaComp     string `xml:"ivaComp"`
+}
+
+type ATSAnulados struct {
+	DetalleAnulados []DetalleAnulados `xml:"detalleAnulados"`
+}
+
+// DetalleAnulados es un comprobante autorizado que luego se anuló en el portal del SRI.
+type DetalleAnulados struct {
+	TipoComprobante  string `xml:"tipoComprobante"`
+	Establecimiento  string `xml:"establecimiento"`
+	PuntoEmision     string `xml:"puntoEmision"`
+	SecuencialInicio string `xml:"secuencialInicio"`
+	SecuencialFin    string `xml:"secuencialFin"`
+	Autorizacion     string `xml:"autorizacion"`
+}
@@ -0,0 +1,57 @@
+package sri
+
+import "encoding/xml"
+
+// GuiaRemision representa la estructura XML completa de una Guía de Remisión (Tipo 06)
+type GuiaRemision struct {
+	XMLName          xml.Name         `xml:"guiaRemision"`
+	ID               string           `xml:"id,attr"`
+	Version          string           `xml:"version,attr"`
+	InfoTributaria   InfoTributaria   `xml:"infoTributaria"`
+	InfoGuiaRemision InfoGuiaRemision `xml:"infoGuiaRemision"`
+	Destinatarios    Destinatarios    `xml:"destinatarios"`
+}
+
+type InfoGuiaRemision struct {
+	DirEstablecimiento              string `xml:"dirEstablecimiento,omitempty"`
+	DirPartida                      string `xml:"dirPartida"`
+	RazonSocialTransportista        string `xml:"razonSocialTransportista"`
+	TipoIdentificacionTransportista string `xml:"tipoIdentificacionTransportista"`
+	RucTransportista                string `xml:"rucTransportista"`
+	Rise                            string `xml:"rise,omitempty"`
+	ObligadoContabilidad            string `xml:"obligadoContabilidad,omitempty"`
+	ContribuyenteEspecial           string `xml:"contribuyenteEspecial,omitempty"`
+	FechaIniTransporte              string `xml:"fechaIniTransporte"`
+	FechaFinTransporte              string `xml:"fechaFinTransporte"`
+	Placa                           string `xml:"placa"`
+}
+
+type Destinatarios struct {
+	Destinatario []Destinatario `xml:"destinatario"`
+}
+
+// Destinatario agrupa la mercadería que se entrega en una misma dirección.
+type Destinatario struct {
+	IdentificacionDestinatario string `xml:"identificacionDestinatario"`
+	RazonSocialDestinatario    string `xml:"razonSocialDestinatario"`
+	DirDestinatario            string `xml:"dirDestinatario"`
+	MotivoTraslado             string `xml:"motivoTraslado"`
+	DocAduaneroUnico           string `xml:"docAduaneroUnico,omitempty"`
+	CodEstabDestino            string `xml:"codEstabDestino,omitempty"`
+	Ruta                       string `xml:"ruta,omitempty"`
+	CodDocSustento             string `xml:"codDocSustento,omitempty"`
+	NumDocSustento             string `xml:"numDocSustento,omitempty"` // 001-001-000000123
+	NumAutDocSustento          string `xml:"numAutDocSustento,omitempty"`
+	FechaEmisionDocSustento    string `xml:"fechaEmisionDocSustento,omitempty"`
+	Detalles                   struct {
+		Detalle []DetalleGuia `xml:"detalle"`
+	} `xml:"detalles"`
+}
+
+// DetalleGuia es una línea de mercadería trasladada (sin precios).
+type DetalleGuia struct {
+	CodigoInterno   string `xml:"codigoInterno,omitempty"`
+	CodigoAdicional string `xml:"codigoAdicional,omitempty"`
+	Descripcion     string `xml:"descripcion"`
+	Cantidad        string `xml:"cantidad"`
+}
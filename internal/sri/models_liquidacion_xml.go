@@ -0,0 +1,38 @@
+package sri
+
+import "encoding/xml"
+
+// LiquidacionCompra representa el XML de una Liquidación de Compra de Bienes y
+// Prestación de Servicios (codDoc 03) v1.1.0.
+type LiquidacionCompra struct {
+	XMLName xml.Name `xml:"liquidacionCompra"`
+	ID      string   `xml:"id,attr"`
+	Version string   `xml:"version,attr"`
+
+	InfoTributaria        InfoTributaria        `xml:"infoTributaria"`
+	InfoLiquidacionCompra InfoLiquidacionCompra `xml:"infoLiquidacionCompra"`
+	Detalles              Detalles              `xml:"detalles"`
+}
+
+// InfoLiquidacionCompra contiene los datos del proveedor y los totales de la compra.
+type InfoLiquidacionCompra struct {
+	FechaEmision                string `xml:"fechaEmision"`
+	DirEstablecimiento          string `xml:"dirEstablecimiento,omitempty"`
+	ContribuyenteEspecial       string `xml:"contribuyenteEspecial,omitempty"`
+	ObligadoContabilidad        string `xml:"obligadoContabilidad,omitempty"`
+	TipoIdentificacionProveedor string `xml:"tipoIdentificacionProveedor"`
+	RazonSocialProveedor        string `xml:"razonSocialProveedor"`
+	IdentificacionProveedor     string `xml:"identificacionProveedor"`
+	DireccionProveedor          string `xml:"direccionProveedor,omitempty"`
+	TotalSinImpuestos           string `xml:"totalSinImpuestos"`
+	TotalDescuento              string `xml:"totalDescuento"`
+
+	TotalConImpuestos TotalConImpuestos `xml:"totalConImpuestos"`
+
+	ImporteTotal string `xml:"importeTotal"`
+	Moneda       string `xml:"moneda,omitempty"`
+
+	Pagos struct {
+		Pago []Pago `xml:"pago"`
+	} `xml:"pagos"`
+}
@@ -10,11 +10,12 @@ type NotaCredito struct {
 	InfoTributaria  InfoTributaria  `xml:"infoTributaria"`
 	InfoNotaCredito InfoNotaCredito `xml:"infoNotaCredito"`
 	Detalles        DetallesNC      `xml:"detalles"`
+	InfoAdicional   *InfoAdicional  `xml:"infoAdicional,omitempty"`
 }
 
 type InfoNotaCredito struct {
 	FechaEmision                string            `xml:"fechaEmision"`
-	DirEstablecimiento          string            `xml:"dirEstablecimiento"`
+	DirEstablecimiento          string            `xml:"dirEstablecimiento,omitempty"`
 	TipoIdentificacionComprador string            `xml:"tipoIdentificacionComprador"`
 	RazonSocialComprador        string            `xml:"razonSocialComprador"`
 	IdentificacionComprador     string            `xml:"identificacionComprador"`
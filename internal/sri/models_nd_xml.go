@@ -0,0 +1,45 @@
+package sri
+
+import "encoding/xml"
+
+// NotaDebito representa la estructura XML completa de una Nota de Débito (Tipo 05)
+type NotaDebito struct {
+	XMLName        xml.Name       `xml:"notaDebito"`
+	ID             string         `xml:"id,attr"`
+	Version        string         `xml:"version,attr"`
+	InfoTributaria InfoTributaria `xml:"infoTributaria"`
+	InfoNotaDebito InfoNotaDebito `xml:"infoNotaDebito"`
+	Motivos        Motivos        `xml:"motivos"`
+}
+
+type InfoNotaDebito struct {
+	FechaEmision                string `xml:"fechaEmision"`
+	DirEstablecimiento          string `xml:"dirEstablecimiento"`
+	TipoIdentificacionComprador string `xml:"tipoIdentificacionComprador"`
+	RazonSocialComprador        string `xml:"razonSocialComprador"`
+	IdentificacionComprador     string `xml:"identificacionComprador"`
+	ContribuyenteEspecial       string `xml:"contribuyenteEspecial,omitempty"`
+	ObligadoContabilidad        string `xml:"obligadoContabilidad"`
+	Rise                        string `xml:"rise,omitempty"`
+	CodDocModificado            string `xml:"codDocModificado"`        // Siempre "01" para facturas
+	NumDocModificado            string `xml:"numDocModificado"`        // 001-001-000000123
+	FechaEmisionDocSustento     string `xml:"fechaEmisionDocSustento"` // Fecha de la factura original
+	TotalSinImpuestos           string `xml:"totalSinImpuestos"`
+	Impuestos                   struct {
+		Impuesto []Impuesto `xml:"impuesto"`
+	} `xml:"impuestos"`
+	ValorTotal string `xml:"valorTotal"` // Total a cobrar (con impuestos)
+	Pagos      struct {
+		Pago []Pago `xml:"pago"`
+	} `xml:"pagos"`
+}
+
+// Motivos contiene las razones del cargo; a diferencia de la NC no hay detalle de ítems.
+type Motivos struct {
+	Motivo []Motivo `xml:"motivo"`
+}
+
+type Motivo struct {
+	Razon string `xml:"razon"`
+	Valor string `xml:"valor"` // Valor sin impuestos
+}
@@ -0,0 +1,72 @@
+package sri
+
+import "encoding/xml"
+
+// ComprobanteRetencion representa la estructura XML de un Comprobante de Retención (Tipo 07).
+// Corresponde al esquema versión 2.0.0, que agrupa las retenciones por documento sustento.
+type ComprobanteRetencion struct {
+	XMLName           xml.Name          `xml:"comprobanteRetencion"`
+	ID                string            `xml:"id,attr"`
+	Version           string            `xml:"version,attr"`
+	InfoTributaria    InfoTributaria    `xml:"infoTributaria"`
+	InfoCompRetencion InfoCompRetencion `xml:"infoCompRetencion"`
+	DocsSustento      DocsSustento      `xml:"docsSustento"`
+}
+
+type InfoCompRetencion struct {
+	FechaEmision                     string `xml:"fechaEmision"`
+	DirEstablecimiento               string `xml:"dirEstablecimiento,omitempty"`
+	ContribuyenteEspecial            string `xml:"contribuyenteEspecial,omitempty"`
+	ObligadoContabilidad             string `xml:"obligadoContabilidad,omitempty"`
+	TipoIdentificacionSujetoRetenido string `xml:"tipoIdentificacionSujetoRetenido"`
+	TipoSujetoRetenido               string `xml:"tipoSujetoRetenido,omitempty"` // Solo para identificación del exterior (08)
+	ParteRel                         string `xml:"parteRel"`                     // SI / NO
+	RazonSocialSujetoRetenido        string `xml:"razonSocialSujetoRetenido"`
+	IdentificacionSujetoRetenido     string `xml:"identificacionSujetoRetenido"`
+	PeriodoFiscal                    string `xml:"periodoFiscal"` // mm/aaaa
+}
+
+// DocsSustento estructura contenedora de los documentos que sustentan la retención
+type DocsSustento struct {
+	DocSustento []DocSustento `xml:"docSustento"`
+}
+
+// DocSustento es el comprobante del proveedor (normalmente su factura) sobre el que se retiene.
+type DocSustento struct {
+	CodSustento             string `xml:"codSustento"`
+	CodDocSustento          string `xml:"codDocSustento"` // 01 Factura, 03 Liquidación de compra...
+	NumDocSustento          string `xml:"numDocSustento"` // 001001000000123 (sin guiones)
+	FechaEmisionDocSustento string `xml:"fechaEmisionDocSustento"`
+	FechaRegistroContable   string `xml:"fechaRegistroContable,omitempty"`
+	NumAutDocSustento       string `xml:"numAutDocSustento,omitempty"`
+	PagoLocExt              string `xml:"pagoLocExt"` // 01 Local, 02 Exterior
+	TotalSinImpuestos       string `xml:"totalSinImpuestos"`
+	ImporteTotal            string `xml:"importeTotal"`
+	ImpuestosDocSustento    struct {
+		ImpuestoDocSustento []ImpuestoDocSustento `xml:"impuestoDocSustento"`
+	} `xml:"impuestosDocSustento"`
+	Retenciones struct {
+		Retencion []Retencion `xml:"retencion"`
+	} `xml:"retenciones"`
+	Pagos struct {
+		Pago []Pago `xml:"pago"`
+	} `xml:"pagos"`
+}
+
+// ImpuestoDocSustento replica los impuestos que constan en el documento del proveedor
+type ImpuestoDocSustento struct {
+	CodImpuestoDocSustento string `xml:"codImpuestoDocSustento"`
+	CodigoPorcentaje       string `xml:"codigoPorcentaje"`
+	BaseImponible          string `xml:"baseImponible"`
+	Tarifa                 string `xml:"tarifa"`
+	ValorImpuesto          string `xml:"valorImpuesto"`
+}
+
+// Retencion representa una línea de retención (Renta o IVA)
+type Retencion struct {
+	Codigo            string `xml:"codigo"`          // 1 Renta, 2 IVA, 6 ISD
+	CodigoRetencion   string `xml:"codigoRetencion"` // Código de la tabla del SRI (ej. 312, 1, 2)
+	BaseImponible     string `xml:"baseImponible"`
+	PorcentajeRetener string `xml:"porcentajeRetener"`
+	ValorRetenido     string `xml:"valorRetenido"`
+}
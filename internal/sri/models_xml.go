@@ -34,6 +34,18 @@ type Pago struct {
 	UnidadTiempo string `xml:"unidadTiempo,omitempty"`
 }
 
+// CampoAdicional es un par nombre/valor libre (email, teléfono, número de orden, notas...).
+type CampoAdicional struct {
+	Nombre string `xml:"nombre,attr"`
+	Valor  string `xml:",chardata"`
+}
+
+// InfoAdicional agrupa los campos adicionales del comprobante (de 1 a 15). El XSD exige al menos un
+// campo, por eso los comprobantes la referencian como puntero y la omiten si está vacía.
+type InfoAdicional struct {
+	CampoAdicional []CampoAdicional `xml:"campoAdicional"`
+}
+
 // TotalConImpuestos estructura contenedora para impuestos globales
 type TotalConImpuestos struct {
 	TotalImpuesto []TotalImpuesto `xml:"totalImpuesto"`
@@ -99,5 +111,6 @@ type Factura struct {
 	InfoTributaria InfoTributaria `xml:"infoTributaria"`
 	InfoFactura    InfoFactura    `xml:"infoFactura"`
 	Detalles       Detalles       `xml:"detalles"`
+	InfoAdicional  *InfoAdicional `xml:"infoAdicional,omitempty"`
 }
 
@@ -0,0 +1,109 @@
+package sri
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var accessKeyField = regexp.MustCompile(`^\d{49}$`)
+
+// ReceivedReportEntry es una línea del reporte de comprobantes recibidos que descarga el portal del SRI.
+type ReceivedReportEntry struct {
+	Line         int
+	AccessKey    string
+	SupplierName string
+}
+
+// ParseReceivedReport lee el TXT de "Comprobantes electrónicos recibidos" del portal SRI en línea.
+// El SRI ha cambiado el orden de las columnas entre versiones, así que la clave de acceso se toma del
+// primer campo de 49 dígitos de cada línea y el nombre del emisor de la columna RAZON_SOCIAL_EMISOR
+// si el archivo trae encabezado. Las claves repetidas se devuelven una sola vez.
+func ParseReceivedReport(r io.Reader) ([]ReceivedReportEntry, error) {
+	var entries []ReceivedReportEntry
+	seen := map[string]bool{}
+	nameCol := -1
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := strings.Split(latin1ToUTF8(scanner.Bytes()), "\t")
+
+		var key string
+		for i, f := range fields {
+			f = strings.TrimSpace(f)
+			fields[i] = f
+			if key == "" && accessKeyField.MatchString(f) {
+				key = f
+			}
+		}
+		if key == "" {
+			for i, f := range fields {
+				if strings.EqualFold(f, "RAZON_SOCIAL_EMISOR") {
+					nameCol = i
+				}
+			}
+			continue
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		entry := ReceivedReportEntry{Line: lineNum, AccessKey: key}
+		if nameCol >= 0 && nameCol < len(fields) {
+			entry.SupplierName = fields[nameCol]
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo el reporte de comprobantes recibidos: %w", err)
+	}
+	return entries, nil
+}
+
+// latin1ToUTF8 convierte las líneas que el portal exporta en ISO-8859-1 (tildes y eñes en los nombres).
+func latin1ToUTF8(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// authorizedXML es la envoltura con la que el SRI entrega un comprobante autorizado.
+type authorizedXML struct {
+	XMLName            xml.Name `xml:"autorizacion"`
+	Estado             string   `xml:"estado"`
+	NumeroAutorizacion string   `xml:"numeroAutorizacion"`
+	FechaAutorizacion  string   `xml:"fechaAutorizacion"`
+	Ambiente           string   `xml:"ambiente"`
+	Comprobante        struct {
+		Text string `xml:",cdata"`
+	} `xml:"comprobante"`
+}
+
+// AuthorizedXML arma el archivo <autorizacion> de un comprobante consultado al SRI, el mismo formato
+// que el portal y los proveedores entregan y que lee ParseAuthorizedInvoice.
+func (a *Autorizacion) AuthorizedXML() ([]byte, error) {
+	doc := authorizedXML{
+		Estado:             a.Estado,
+		NumeroAutorizacion: a.NumeroAutorizacion,
+		FechaAutorizacion:  a.FechaAutorizacion,
+		Ambiente:           a.Ambiente,
+	}
+	doc.Comprobante.Text = a.Comprobante
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,60 @@
+package sri
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReceivedReport(t *testing.T) {
+	keyA := strings.Repeat("1", 49)
+	keyB := strings.Repeat("2", 49)
+
+	t.Run("Reads the legacy layout in ISO-8859-1", func(t *testing.T) {
+		// Formato anterior: la clave aparece dos veces (CLAVE_ACCESO y NUMERO_AUTORIZACION)
+		var report bytes.Buffer
+		report.WriteString("COMPROBANTE\tSERIE_COMPROBANTE\tRUC_EMISOR\tRAZON_SOCIAL_EMISOR\tFECHA_EMISION\tFECHA_AUTORIZACION\tTIPO_EMISION\tIDENTIFICACION_RECEPTOR\tCLAVE_ACCESO\tNUMERO_AUTORIZACION\r\n")
+		report.WriteString("Factura\t001-001-000000010\t0991234567001\tFERRETER\xcdA LA PE\xd1A\t03/02/2026\t03/02/2026 10:00:00\tNORMAL\t1790012345001\t" + keyA + "\t" + keyA + "\r\n")
+		report.WriteString("\r\n")
+		report.WriteString("Comprobante de Retención\t001-001-000000004\t1790011111001\tCLIENTE S.A.\t04/02/2026\t04/02/2026 11:00:00\tNORMAL\t1790012345001\t" + keyB + "\t" + keyB + "\r\n")
+		report.WriteString("Factura\t001-001-000000010\t0991234567001\tFERRETER\xcdA LA PE\xd1A\t03/02/2026\t03/02/2026 10:00:00\tNORMAL\t1790012345001\t" + keyA + "\t" + keyA + "\r\n")
+
+		entries, err := ParseReceivedReport(&report)
+		require.NoError(t, err)
+		assert.Equal(t, []ReceivedReportEntry{
+			{Line: 2, AccessKey: keyA, SupplierName: "FERRETERÍA LA PEÑA"},
+			{Line: 4, AccessKey: keyB, SupplierName: "CLIENTE S.A."},
+		}, entries)
+	})
+
+	t.Run("Reads files without header", func(t *testing.T) {
+		entries, err := ParseReceivedReport(strings.NewReader("x\t" + keyA + "\n" + "sin clave\n"))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, keyA, entries[0].AccessKey)
+		assert.Empty(t, entries[0].SupplierName)
+	})
+}
+
+func TestAutorizacionAuthorizedXML(t *testing.T) {
+	facturaXML, key := supplierFacturaXML(t)
+
+	auth := &Autorizacion{
+		Estado:             "AUTORIZADO",
+		NumeroAutorizacion: key,
+		FechaAutorizacion:  "2026-01-10T10:15:00-05:00",
+		Ambiente:           "PRODUCCIÓN",
+		Comprobante:        facturaXML,
+	}
+	data, err := auth.AuthorizedXML()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<comprobante><![CDATA[")
+
+	inv, err := ParseAuthorizedInvoice(data)
+	require.NoError(t, err)
+	assert.Equal(t, auth.NumeroAutorizacion, inv.Key.Raw)
+	assert.Equal(t, auth.FechaAutorizacion, inv.FechaAutorizacion)
+}
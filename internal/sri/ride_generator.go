@@ -165,58 +165,53 @@ func (g *RideGenerator) buildDetailsRows(f *Factura) []core.Row {
 }
 
 func (g *RideGenerator) buildFooter(f *Factura) []core.Row {
-	sub15, sub0, totalIVA := "0.00", "0.00", "0.00"
-	for _, tax := range f.InfoFactura.TotalConImpuestos.TotalImpuesto {
-		switch tax.CodigoPorcentaje {
-		case "4":
-			sub15 = tax.BaseImponible
-			totalIVA = tax.Valor
-		case "0":
-			sub0 = tax.BaseImponible
-		}
+	vat := rideVATFromTotals(f.InfoFactura.TotalConImpuestos.TotalImpuesto)
+
+	// ALTURA TOTAL DE LA TABLA DE TOTALES = 15 filas * 5mm aprox = 75-80mm
+	totalHeight := 77.0
+
+	// Comprobantes anteriores a infoAdicional solo muestran la dirección del comprador
+	info := f.InfoAdicional
+	if info == nil && f.InfoFactura.DireccionComprador != "" {
+		info = &InfoAdicional{CampoAdicional: []CampoAdicional{{Nombre: "Dirección", Valor: f.InfoFactura.DireccionComprador}}}
 	}
+	campos, next := camposRows(info, 8)
 
-	formaPago := "Sin Utilización del Sistema Financiero"
-	if len(f.InfoFactura.Pagos.Pago) > 0 {
-		formaPago = f.InfoFactura.Pagos.Pago[0].FormaPago
+	infoCol := []core.Component{
+		text.New("Información Adicional", props.Text{Style: fontstyle.Bold, Size: 8, Top: 2, Left: 2}),
 	}
-	valorPago := f.InfoFactura.ImporteTotal
+	infoCol = append(infoCol, campos...)
 
-	// ALTURA TOTAL DE LA TABLA DE TOTALES = 14 filas * 5mm aprox = 70-75mm
-	totalHeight := 72.0
+	// Tabla de Pagos
+	pagosTop := next + 4
+	infoCol = append(infoCol,
+		text.New("Forma de Pago", props.Text{Style: fontstyle.Bold, Size: 7, Top: pagosTop, Left: 2}),
+		text.New("Valor", props.Text{Style: fontstyle.Bold, Size: 7, Top: pagosTop, Align: align.Right, Right: 10}),
+	)
+	infoCol = append(infoCol, pagosRows(f.InfoFactura.Pagos.Pago, f.InfoFactura.ImporteTotal, pagosTop+5)...)
+	totalHeight = max(totalHeight, pagosTop+5+float64(max(len(f.InfoFactura.Pagos.Pago), 1))*5+2)
 
 	return []core.Row{
 		row.New(totalHeight).Add(
 			// Izquierda: Info Adicional y Pagos (Con borde completo)
-			col.New(7).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
-				text.New("Información Adicional", props.Text{Style: fontstyle.Bold, Size: 8, Top: 2, Left: 2}),
-				text.New("Dirección: "+f.InfoFactura.DireccionComprador, props.Text{Size: 7, Top: 8, Left: 2}),
-				text.New("Email: "+f.InfoFactura.DirEstablecimiento, props.Text{Size: 7, Top: 14, Left: 2}),
-
-				// Tabla de Pagos Simulada
-				text.New("Forma de Pago", props.Text{Style: fontstyle.Bold, Size: 7, Top: 30, Left: 2}),
-				text.New("Valor", props.Text{Style: fontstyle.Bold, Size: 7, Top: 30, Align: align.Right, Right: 10}),
-
-				text.New(formaPago, props.Text{Size: 6, Top: 35, Left: 2}),
-				text.New(valorPago, props.Text{Size: 7, Top: 35, Align: align.Right, Right: 10}),
-			),
+			col.New(7).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(infoCol...),
 
 			col.New(5).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
-				// Fila 1: Subtotal 12%
-				text.New("SUBTOTAL 12%", props.Text{Size: 7, Top: 2, Left: 2}),
-				text.New(sub15, props.Text{Size: 7, Align: align.Right, Top: 2, Right: 2}),
+				// Fila 1: Subtotal gravado (con la tarifa del comprobante)
+				text.New(vat.label("SUBTOTAL"), props.Text{Size: 7, Top: 2, Left: 2}),
+				text.New(rideAmount(vat.Taxed), props.Text{Size: 7, Align: align.Right, Top: 2, Right: 2}),
 
 				// Fila 2: Subtotal 0%
 				text.New("SUBTOTAL 0%", props.Text{Size: 7, Top: 7, Left: 2}),
-				text.New(sub0, props.Text{Size: 7, Align: align.Right, Top: 7, Right: 2}),
+				text.New(rideAmount(vat.Zero), props.Text{Size: 7, Align: align.Right, Top: 7, Right: 2}),
 
 				// Fila 3: No Objeto
 				text.New("SUBTOTAL NO OBJETO DE IVA", props.Text{Size: 7, Top: 12, Left: 2}),
-				text.New("0.00", props.Text{Size: 7, Align: align.Right, Top: 12, Right: 2}),
+				text.New(rideAmount(vat.NotObject), props.Text{Size: 7, Align: align.Right, Top: 12, Right: 2}),
 
 				// Fila 4: Exento
 				text.New("SUBTOTAL EXENTO DE IVA", props.Text{Size: 7, Top: 17, Left: 2}),
-				text.New("0.00", props.Text{Size: 7, Align: align.Right, Top: 17, Right: 2}),
+				text.New(rideAmount(vat.Exempt), props.Text{Size: 7, Align: align.Right, Top: 17, Right: 2}),
 
 				// Fila 5: Sin Impuestos
 				text.New("SUBTOTAL SIN IMPUESTOS", props.Text{Size: 7, Top: 22, Left: 2}),
@@ -228,30 +223,34 @@ func (g *RideGenerator) buildFooter(f *Factura) []core.Row {
 
 				// Fila 7: ICE
 				text.New("ICE", props.Text{Size: 7, Top: 32, Left: 2}),
-				text.New("0.00", props.Text{Size: 7, Align: align.Right, Top: 32, Right: 2}),
+				text.New(rideAmount(vat.ICE), props.Text{Size: 7, Align: align.Right, Top: 32, Right: 2}),
 
 				// Fila 8: IVA
-				text.New("IVA 12%", props.Text{Size: 7, Top: 37, Left: 2}),
-				text.New(totalIVA, props.Text{Size: 7, Align: align.Right, Top: 37, Right: 2}),
+				text.New(vat.label("IVA"), props.Text{Size: 7, Top: 37, Left: 2}),
+				text.New(rideAmount(vat.VAT), props.Text{Size: 7, Align: align.Right, Top: 37, Right: 2}),
 
-				// Fila 9: Propina
-				text.New("PROPINA", props.Text{Size: 7, Top: 42, Left: 2}),
-				text.New("0.00", props.Text{Size: 7, Align: align.Right, Top: 42, Right: 2}),
+				// Fila 9: IRBPNR
+				text.New("IRBPNR", props.Text{Size: 7, Top: 42, Left: 2}),
+				text.New(rideAmount(vat.IRBPNR), props.Text{Size: 7, Align: align.Right, Top: 42, Right: 2}),
 
-				// Fila 10: TOTAL (Negrita)
-				text.New("VALOR TOTAL", props.Text{Style: fontstyle.Bold, Size: 8, Top: 47, Left: 2}),
-				text.New(f.InfoFactura.ImporteTotal, props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right, Top: 47, Right: 2}),
+				// Fila 10: Propina
+				text.New("PROPINA", props.Text{Size: 7, Top: 47, Left: 2}),
+				text.New(f.InfoFactura.Propina, props.Text{Size: 7, Align: align.Right, Top: 47, Right: 2}),
 
-				// Fila 11: Total sin Subsidio
-				text.New("VALOR TOTAL SIN SUBSIDIO", props.Text{Size: 7, Top: 55, Left: 2}),
-				text.New("0.00", props.Text{Size: 7, Align: align.Right, Top: 55, Right: 2}),
+				// Fila 11: TOTAL (Negrita)
+				text.New("VALOR TOTAL", props.Text{Style: fontstyle.Bold, Size: 8, Top: 52, Left: 2}),
+				text.New(f.InfoFactura.ImporteTotal, props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right, Top: 52, Right: 2}),
 
-				// Fila 12: Ahorro Subsidio
-				text.New("AHORRO POR SUBSIDIO:", props.Text{Size: 7, Top: 60, Left: 2}),
+				// Fila 12: Total sin Subsidio
+				text.New("VALOR TOTAL SIN SUBSIDIO", props.Text{Size: 7, Top: 60, Left: 2}),
 				text.New("0.00", props.Text{Size: 7, Align: align.Right, Top: 60, Right: 2}),
 
-				// Fila 13: Nota Subsidio
-				text.New("(Incluye IVA cuando corresponda)", props.Text{Size: 6, Top: 65, Left: 2}),
+				// Fila 13: Ahorro Subsidio
+				text.New("AHORRO POR SUBSIDIO:", props.Text{Size: 7, Top: 65, Left: 2}),
+				text.New("0.00", props.Text{Size: 7, Align: align.Right, Top: 65, Right: 2}),
+
+				// Fila 14: Nota Subsidio
+				text.New("(Incluye IVA cuando corresponda)", props.Text{Size: 6, Top: 70, Left: 2}),
 			),
 		),
 	}
@@ -382,36 +381,166 @@ func (g *RideGenerator) buildDetailsRowsNC(nc *NotaCredito) []core.Row {
 }
 
 func (g *RideGenerator) buildFooterNC(nc *NotaCredito) []core.Row {
-	sub15, sub0, totalIVA := "0.00", "0.00", "0.00"
-	for _, tax := range nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto {
-		switch tax.CodigoPorcentaje {
-		case "4":
-			sub15 = tax.BaseImponible
-			totalIVA = tax.Valor
-		case "0":
-			sub0 = tax.BaseImponible
-		}
-	}
+	vat := rideVATFromTotals(nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto)
 
+	infoCol := []core.Component{
+		text.New("Información Adicional", props.Text{Style: fontstyle.Bold, Size: 8, Top: 2, Left: 2}),
+	}
 	totalHeight := 60.0
+	if nc.InfoAdicional != nil {
+		campos, next := camposRows(nc.InfoAdicional, 8)
+		infoCol = append(infoCol, campos...)
+		totalHeight = max(totalHeight, next+2)
+	} else {
+		infoCol = append(infoCol, text.New("Nota de Crédito generada automáticamente.", props.Text{Size: 7, Top: 8, Left: 2}))
+	}
+
+	totals := []core.Component{
+		text.New(vat.label("SUBTOTAL"), props.Text{Size: 7, Top: 2, Left: 2}),
+		text.New(rideAmount(vat.Taxed), props.Text{Size: 7, Align: align.Right, Top: 2, Right: 2}),
+		text.New("SUBTOTAL 0%", props.Text{Size: 7, Top: 7, Left: 2}),
+		text.New(rideAmount(vat.Zero), props.Text{Size: 7, Align: align.Right, Top: 7, Right: 2}),
+		text.New("SUBTOTAL SIN IMPUESTOS", props.Text{Size: 7, Top: 12, Left: 2}),
+		text.New(nc.InfoNotaCredito.TotalSinImpuestos, props.Text{Size: 7, Align: align.Right, Top: 12, Right: 2}),
+	}
+	// ICE e IRBPNR solo aparecen cuando la nota los devuelve
+	others, top := vat.otherTaxRows(17)
+	totals = append(totals, others...)
+	totals = append(totals,
+		text.New(vat.label("IVA"), props.Text{Size: 7, Top: top, Left: 2}),
+		text.New(rideAmount(vat.VAT), props.Text{Size: 7, Align: align.Right, Top: top, Right: 2}),
+		text.New("VALOR TOTAL", props.Text{Style: fontstyle.Bold, Size: 8, Top: top + 5, Left: 2}),
+		text.New(nc.InfoNotaCredito.ValorModificacion, props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right, Top: top + 5, Right: 2}),
+	)
+
 	return []core.Row{
 		row.New(totalHeight).Add(
-			col.New(7).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
-				text.New("Información Adicional", props.Text{Style: fontstyle.Bold, Size: 8, Top: 2, Left: 2}),
-				text.New("Nota de Crédito generada automáticamente.", props.Text{Size: 7, Top: 8, Left: 2}),
+			col.New(7).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(infoCol...),
+			col.New(5).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(totals...),
+		),
+	}
+}
+
+// rideHeader agrupa los datos de cabecera que comparten todos los RIDE.
+type rideHeader struct {
+	info               InfoTributaria
+	title              string // FACTURA, COMPROBANTE DE RETENCIÓN, etc.
+	dirEstablecimiento string
+	obligado           string
+}
+
+// newRideDocument crea el documento A4 con los márgenes estándar del RIDE.
+func newRideDocument() core.Maroto {
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.A4).
+		WithOrientation(orientation.Vertical).
+		WithLeftMargin(10).
+		WithRightMargin(10).
+		WithTopMargin(10).
+		Build()
+
+	return maroto.New(cfg)
+}
+
+// buildDocumentHeader dibuja la cabecera (logo, emisor y recuadro de autorización)
+// para los tipos de comprobante que no tienen un builder propio.
+func (g *RideGenerator) buildDocumentHeader(h rideHeader, logoPath string, authDate time.Time, authNumber string) []core.Row {
+	ambiente := "PRUEBAS"
+	if h.info.Ambiente == "2" {
+		ambiente = "PRODUCCIÓN"
+	}
+
+	var logo core.Component
+	if logoPath != "" {
+		logo = image.NewFromFile(logoPath)
+	} else {
+		logo = text.New("NO TIENE LOGO", props.Text{
+			Style: fontstyle.Bold, Size: 16, Align: align.Center, Top: 10, Color: &props.Color{Red: 200},
+		})
+	}
+
+	obligado := h.obligado
+	if obligado == "" {
+		obligado = "NO"
+	}
+
+	return []core.Row{
+		row.New(90).Add(
+			col.New(6).Add(
+				logo,
+				text.New(" ", props.Text{Top: 35}), // Spacer
+				text.New(h.info.RazonSocial, props.Text{Style: fontstyle.Bold, Size: 8, Top: 40}),
+				text.New(h.info.NombreComercial, props.Text{Size: 8, Top: 45}),
+				text.New("Dirección Matriz:", props.Text{Style: fontstyle.Bold, Size: 8, Top: 55}),
+				text.New(h.info.DirMatriz, props.Text{Size: 8, Top: 60}),
+				text.New("Dirección Sucursal:", props.Text{Style: fontstyle.Bold, Size: 8, Top: 68}),
+				text.New(h.dirEstablecimiento, props.Text{Size: 8, Top: 73}),
+				text.New("OBLIGADO A LLEVAR CONTABILIDAD: "+obligado, props.Text{Size: 8, Top: 82}),
 			),
-			col.New(5).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
-				text.New("SUBTOTAL 12%", props.Text{Size: 7, Top: 2, Left: 2}),
-				text.New(sub15, props.Text{Size: 7, Align: align.Right, Top: 2, Right: 2}),
-				text.New("SUBTOTAL 0%", props.Text{Size: 7, Top: 7, Left: 2}),
-				text.New(sub0, props.Text{Size: 7, Align: align.Right, Top: 7, Right: 2}),
-				text.New("SUBTOTAL SIN IMPUESTOS", props.Text{Size: 7, Top: 12, Left: 2}),
-				text.New(nc.InfoNotaCredito.TotalSinImpuestos, props.Text{Size: 7, Align: align.Right, Top: 12, Right: 2}),
-				text.New("IVA 12%", props.Text{Size: 7, Top: 17, Left: 2}),
-				text.New(totalIVA, props.Text{Size: 7, Align: align.Right, Top: 17, Right: 2}),
-				text.New("VALOR TOTAL", props.Text{Style: fontstyle.Bold, Size: 8, Top: 22, Left: 2}),
-				text.New(nc.InfoNotaCredito.ValorModificacion, props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right, Top: 22, Right: 2}),
+			col.New(6).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+				text.New("R.U.C.: "+h.info.Ruc, props.Text{Style: fontstyle.Bold, Size: 10, Top: 3, Left: 2}),
+				text.New(h.title, props.Text{Style: fontstyle.Bold, Size: 12, Top: 9, Left: 2}),
+				text.New("No. "+fmt.Sprintf("%s-%s-%s", h.info.Estab, h.info.PtoEmi, h.info.Secuencial), props.Text{Size: 10, Top: 15, Left: 2}),
+				text.New("NÚMERO DE AUTORIZACIÓN", props.Text{Style: fontstyle.Bold, Size: 8, Top: 22, Left: 2}),
+				text.New(authNumber, props.Text{Size: 8, Top: 26, Left: 2}),
+				text.New("FECHA Y HORA DE AUTORIZACIÓN", props.Text{Style: fontstyle.Bold, Size: 8, Top: 32, Left: 2}),
+				text.New(authDate.Format("02/01/2006 15:04:05"), props.Text{Size: 8, Top: 36, Left: 2}),
+				text.New("AMBIENTE: "+ambiente, props.Text{Size: 8, Top: 42, Left: 2}),
+				text.New("EMISIÓN: NORMAL", props.Text{Size: 8, Top: 48, Left: 2}),
+				text.New("CLAVE DE ACCESO", props.Text{Style: fontstyle.Bold, Size: 8, Top: 60, Left: 2}),
+				code.NewBar(h.info.ClaveAcceso, props.Barcode{Percent: 67, Top: 68, Left: 16}),
+				text.New(h.info.ClaveAcceso, props.Text{Size: 7, Align: align.Center, Top: 82}),
 			),
 		),
 	}
 }
+
+// formasPago traduce el código de formaPago a la descripción que se imprime en el RIDE.
+var formasPago = map[string]string{
+	"01": "Sin Utilización del Sistema Financiero",
+	"15": "Compensación de Deudas",
+	"16": "Tarjeta de Débito",
+	"17": "Dinero Electrónico",
+	"19": "Tarjeta de Crédito",
+	"20": "Otros con Utilización del Sistema Financiero",
+	"21": "Endoso de Títulos",
+}
+
+// camposRows imprime un campoAdicional por línea ("Nombre: Valor") desde la posición top (en mm)
+// y devuelve la posición libre siguiente.
+func camposRows(info *InfoAdicional, top float64) ([]core.Component, float64) {
+	if info == nil {
+		return nil, top
+	}
+	var rows []core.Component
+	for _, c := range info.CampoAdicional {
+		rows = append(rows, text.New(c.Nombre+": "+c.Valor, props.Text{Size: 7, Top: top, Left: 2}))
+		top += 4.5
+	}
+	return rows, top
+}
+
+// pagosRows arma una línea por cada forma de pago desde la posición top (en mm), incluyendo el plazo
+// si es a crédito. Sin pagos se imprime el total como pago sin utilización del sistema financiero.
+func pagosRows(pagos []Pago, total string, top float64) []core.Component {
+	if len(pagos) == 0 {
+		pagos = []Pago{{FormaPago: "01", Total: total}}
+	}
+
+	var rows []core.Component
+	for i, p := range pagos {
+		desc, ok := formasPago[p.FormaPago]
+		if !ok {
+			desc = p.FormaPago
+		}
+		if p.Plazo != "" {
+			desc = fmt.Sprintf("%s (%s %s)", desc, p.Plazo, p.UnidadTiempo)
+		}
+		y := top + float64(i)*5
+		rows = append(rows,
+			text.New(desc, props.Text{Size: 6, Top: y, Left: 2}),
+			text.New(p.Total, props.Text{Size: 7, Top: y, Align: align.Right, Right: 10}),
+		)
+	}
+	return rows
+}
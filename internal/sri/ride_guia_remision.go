@@ -0,0 +1,100 @@
+package sri
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateGuiaRemisionRide genera el RIDE de una Guía de Remisión.
+func (g *RideGenerator) GenerateGuiaRemisionRide(gr *GuiaRemision, ridePath string, logoPath string, authDate time.Time, authNumber string) error {
+	m := newRideDocument()
+
+	m.AddRows(g.buildDocumentHeader(rideHeader{
+		info:               gr.InfoTributaria,
+		title:              "GUÍA DE REMISIÓN",
+		dirEstablecimiento: gr.InfoGuiaRemision.DirEstablecimiento,
+		obligado:           gr.InfoGuiaRemision.ObligadoContabilidad,
+	}, logoPath, authDate, authNumber)...)
+	m.AddRow(2)
+	m.AddRows(g.buildTransportInfoGuia(gr)...)
+
+	// Cada destinatario lleva su propio bloque con los datos de entrega y la mercadería
+	for _, dest := range gr.Destinatarios.Destinatario {
+		m.AddRow(2)
+		m.AddRows(g.buildDestinatarioGuia(dest)...)
+	}
+
+	document, err := m.Generate()
+	if err != nil {
+		return fmt.Errorf("error generando RIDE de guía de remisión: %w", err)
+	}
+
+	return document.Save(ridePath)
+}
+
+func (g *RideGenerator) buildTransportInfoGuia(gr *GuiaRemision) []core.Row {
+	info := gr.InfoGuiaRemision
+	return []core.Row{
+		row.New(28).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+			col.New(7).Add(
+				text.New("Identificación (Transportista): "+info.RucTransportista, props.Text{Size: 7, Top: 2, Left: 2}),
+				text.New("Razón Social / Nombres y Apellidos: "+info.RazonSocialTransportista, props.Text{Style: fontstyle.Bold, Size: 7, Top: 8, Left: 2}),
+				text.New("Placa: "+info.Placa, props.Text{Size: 7, Top: 14, Left: 2}),
+				text.New("Punto de Partida: "+info.DirPartida, props.Text{Size: 7, Top: 20, Left: 2}),
+			),
+			col.New(5).Add(
+				text.New("Fecha inicio Transporte: "+info.FechaIniTransporte, props.Text{Size: 7, Top: 8, Align: align.Right, Right: 5}),
+				text.New("Fecha fin Transporte: "+info.FechaFinTransporte, props.Text{Size: 7, Top: 14, Align: align.Right, Right: 5}),
+			),
+		),
+	}
+}
+
+func (g *RideGenerator) buildDestinatarioGuia(dest Destinatario) []core.Row {
+	var rows []core.Row
+
+	docSustento := "-"
+	if dest.NumDocSustento != "" {
+		docSustento = docSustentoName(dest.CodDocSustento) + " " + dest.NumDocSustento
+	}
+
+	rows = append(rows, row.New(34).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+		col.New(12).Add(
+			text.New("Comprobante de Venta: "+docSustento, props.Text{Size: 7, Top: 2, Left: 2}),
+			text.New("Fecha de Emisión: "+dest.FechaEmisionDocSustento, props.Text{Size: 7, Top: 2, Align: align.Right, Right: 5}),
+			text.New("Número de Autorización: "+dest.NumAutDocSustento, props.Text{Size: 7, Top: 7, Left: 2}),
+			text.New("Motivo Traslado: "+dest.MotivoTraslado, props.Text{Size: 7, Top: 12, Left: 2}),
+			text.New("Destino (Punto de llegada): "+dest.DirDestinatario, props.Text{Size: 7, Top: 17, Left: 2}),
+			text.New("Identificación (Destinatario): "+dest.IdentificacionDestinatario, props.Text{Size: 7, Top: 22, Left: 2}),
+			text.New("Razón Social / Nombres Apellidos: "+dest.RazonSocialDestinatario, props.Text{Style: fontstyle.Bold, Size: 7, Top: 27, Left: 2}),
+			text.New("Ruta: "+dest.Ruta, props.Text{Size: 7, Top: 27, Align: align.Right, Right: 5}),
+		),
+	))
+
+	headerStyle := &props.Cell{BackgroundColor: &props.Color{Red: 240, Green: 240, Blue: 240}, BorderType: border.Full, BorderThickness: 0.1}
+	rows = append(rows, row.New(8).WithStyle(headerStyle).Add(
+		col.New(2).Add(text.New("Cantidad", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(8).Add(text.New("Descripción", props.Text{Style: fontstyle.Bold, Size: 7, Top: 1.5, Left: 2})),
+		col.New(2).Add(text.New("Código Principal", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+	))
+
+	for _, det := range dest.Detalles.Detalle {
+		cant, _ := strconv.ParseFloat(det.Cantidad, 64)
+		rows = append(rows, row.New(6).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+			col.New(2).Add(text.New(fmt.Sprintf("%.2f", cant), props.Text{Size: 7, Align: align.Center, Top: 1})),
+			col.New(8).Add(text.New(det.Descripcion, props.Text{Size: 7, Align: align.Left, Top: 1, Left: 2})),
+			col.New(2).Add(text.New(det.CodigoInterno, props.Text{Size: 7, Align: align.Center, Top: 1})),
+		))
+	}
+	return rows
+}
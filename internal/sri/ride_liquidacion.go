@@ -0,0 +1,125 @@
+package sri
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateLiquidacionCompraRide genera el RIDE de una Liquidación de Compra.
+func (g *RideGenerator) GenerateLiquidacionCompraRide(lc *LiquidacionCompra, ridePath string, logoPath string, authDate time.Time, authNumber string) error {
+	m := newRideDocument()
+
+	m.AddRows(g.buildDocumentHeader(rideHeader{
+		info:               lc.InfoTributaria,
+		title:              "LIQUIDACIÓN DE COMPRA DE BIENES Y PRESTACIÓN DE SERVICIOS",
+		dirEstablecimiento: lc.InfoLiquidacionCompra.DirEstablecimiento,
+		obligado:           lc.InfoLiquidacionCompra.ObligadoContabilidad,
+	}, logoPath, authDate, authNumber)...)
+	m.AddRow(2)
+	m.AddRows(g.buildSupplierInfoLC(lc)...)
+	m.AddRow(2)
+	m.AddRows(g.buildDetailsRowsLC(lc)...)
+	m.AddRow(2)
+	m.AddRows(g.buildFooterLC(lc)...)
+
+	document, err := m.Generate()
+	if err != nil {
+		return fmt.Errorf("error generando RIDE de liquidación de compra: %w", err)
+	}
+
+	return document.Save(ridePath)
+}
+
+func (g *RideGenerator) buildSupplierInfoLC(lc *LiquidacionCompra) []core.Row {
+	info := lc.InfoLiquidacionCompra
+	return []core.Row{
+		row.New(22).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+			col.New(7).Add(
+				text.New("Razón Social / Nombres y Apellidos: "+info.RazonSocialProveedor, props.Text{Style: fontstyle.Bold, Size: 7, Top: 2, Left: 2}),
+				text.New("Identificación: "+info.IdentificacionProveedor, props.Text{Size: 7, Top: 8, Left: 2}),
+				text.New("Fecha Emisión: "+info.FechaEmision, props.Text{Size: 7, Top: 14, Left: 2}),
+			),
+			col.New(5).Add(
+				text.New("Dirección: "+info.DireccionProveedor, props.Text{Size: 7, Top: 14}),
+			),
+		),
+	}
+}
+
+func (g *RideGenerator) buildDetailsRowsLC(lc *LiquidacionCompra) []core.Row {
+	var rows []core.Row
+	headerStyle := &props.Cell{BackgroundColor: &props.Color{Red: 240, Green: 240, Blue: 240}, BorderType: border.Full, BorderThickness: 0.1}
+
+	rows = append(rows, row.New(8).WithStyle(headerStyle).Add(
+		col.New(2).Add(text.New("Cod. Principal", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(1).Add(text.New("Cant.", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(5).Add(text.New("Descripción", props.Text{Style: fontstyle.Bold, Size: 7, Top: 1.5, Left: 2})),
+		col.New(2).Add(text.New("Precio Unitario", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Right, Top: 1.5, Right: 2})),
+		col.New(1).Add(text.New("Desc.", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Right, Top: 1.5, Right: 2})),
+		col.New(1).Add(text.New("Precio Total", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Right, Top: 1.5, Right: 2})),
+	))
+
+	for _, det := range lc.Detalles.Detalle {
+		cant, _ := strconv.ParseFloat(det.Cantidad, 64)
+		pUnit, _ := strconv.ParseFloat(det.PrecioUnitario, 64)
+
+		rows = append(rows, row.New(6).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+			col.New(2).Add(text.New(det.CodigoPrincipal, props.Text{Size: 7, Align: align.Left, Top: 1, Left: 2})),
+			col.New(1).Add(text.New(fmt.Sprintf("%.2f", cant), props.Text{Size: 7, Align: align.Center, Top: 1})),
+			col.New(5).Add(text.New(det.Descripcion, props.Text{Size: 7, Align: align.Left, Top: 1, Left: 2})),
+			col.New(2).Add(text.New(fmt.Sprintf("%.2f", pUnit), props.Text{Size: 7, Align: align.Right, Top: 1, Right: 2})),
+			col.New(1).Add(text.New(det.Descuento, props.Text{Size: 7, Align: align.Right, Top: 1, Right: 2})),
+			col.New(1).Add(text.New(det.PrecioTotalSinImpuesto, props.Text{Size: 7, Align: align.Right, Top: 1, Right: 2})),
+		))
+	}
+	return rows
+}
+
+func (g *RideGenerator) buildFooterLC(lc *LiquidacionCompra) []core.Row {
+	info := lc.InfoLiquidacionCompra
+	vat := rideVATFromTotals(info.TotalConImpuestos.TotalImpuesto)
+
+	infoCol := []core.Component{
+		text.New("Información Adicional", props.Text{Style: fontstyle.Bold, Size: 8, Top: 2, Left: 2}),
+		text.New("Dirección: "+info.DireccionProveedor, props.Text{Size: 7, Top: 8, Left: 2}),
+		text.New("Forma de Pago", props.Text{Style: fontstyle.Bold, Size: 7, Top: 18, Left: 2}),
+		text.New("Valor", props.Text{Style: fontstyle.Bold, Size: 7, Top: 18, Align: align.Right, Right: 10}),
+	}
+	infoCol = append(infoCol, pagosRows(info.Pagos.Pago, info.ImporteTotal, 23)...)
+
+	totals := []core.Component{
+		text.New(vat.label("SUBTOTAL"), props.Text{Size: 7, Top: 2, Left: 2}),
+		text.New(rideAmount(vat.Taxed), props.Text{Size: 7, Align: align.Right, Top: 2, Right: 2}),
+		text.New("SUBTOTAL 0%", props.Text{Size: 7, Top: 7, Left: 2}),
+		text.New(rideAmount(vat.Zero), props.Text{Size: 7, Align: align.Right, Top: 7, Right: 2}),
+		text.New("SUBTOTAL SIN IMPUESTOS", props.Text{Size: 7, Top: 12, Left: 2}),
+		text.New(info.TotalSinImpuestos, props.Text{Size: 7, Align: align.Right, Top: 12, Right: 2}),
+		text.New("TOTAL DESCUENTO", props.Text{Size: 7, Top: 17, Left: 2}),
+		text.New(info.TotalDescuento, props.Text{Size: 7, Align: align.Right, Top: 17, Right: 2}),
+	}
+	others, top := vat.otherTaxRows(22)
+	totals = append(totals, others...)
+	totals = append(totals,
+		text.New(vat.label("IVA"), props.Text{Size: 7, Top: top, Left: 2}),
+		text.New(rideAmount(vat.VAT), props.Text{Size: 7, Align: align.Right, Top: top, Right: 2}),
+		text.New("VALOR TOTAL", props.Text{Style: fontstyle.Bold, Size: 8, Top: top + 6, Left: 2}),
+		text.New(info.ImporteTotal, props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right, Top: top + 6, Right: 2}),
+	)
+
+	return []core.Row{
+		row.New(max(35, top+13)).Add(
+			col.New(7).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(infoCol...),
+			col.New(5).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(totals...),
+		),
+	}
+}
@@ -0,0 +1,104 @@
+package sri
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateNotaDebitoRide genera el RIDE de una Nota de Débito.
+func (g *RideGenerator) GenerateNotaDebitoRide(nd *NotaDebito, ridePath string, logoPath string, authDate time.Time, authNumber string) error {
+	m := newRideDocument()
+
+	m.AddRows(g.buildDocumentHeader(rideHeader{
+		info:               nd.InfoTributaria,
+		title:              "NOTA DE DÉBITO",
+		dirEstablecimiento: nd.InfoNotaDebito.DirEstablecimiento,
+		obligado:           nd.InfoNotaDebito.ObligadoContabilidad,
+	}, logoPath, authDate, authNumber)...)
+	m.AddRow(2)
+	m.AddRows(g.buildClientInfoND(nd)...)
+	m.AddRow(2)
+	m.AddRows(g.buildMotivosRowsND(nd)...)
+	m.AddRow(2)
+	m.AddRows(g.buildFooterND(nd)...)
+
+	document, err := m.Generate()
+	if err != nil {
+		return fmt.Errorf("error generando RIDE de nota de débito: %w", err)
+	}
+
+	return document.Save(ridePath)
+}
+
+func (g *RideGenerator) buildClientInfoND(nd *NotaDebito) []core.Row {
+	info := nd.InfoNotaDebito
+	return []core.Row{
+		row.New(30).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+			col.New(7).Add(
+				text.New("Razón Social / Nombres y Apellidos: "+info.RazonSocialComprador, props.Text{Style: fontstyle.Bold, Size: 7, Top: 2, Left: 2}),
+				text.New("Identificación: "+info.IdentificacionComprador, props.Text{Size: 7, Top: 8, Left: 2}),
+				text.New("Fecha Emisión: "+info.FechaEmision, props.Text{Size: 7, Top: 14, Left: 2}),
+				text.New("------------------------------------------------------------------------------------------------------", props.Text{Size: 5, Top: 19}),
+				text.New("Comprobante que se modifica:", props.Text{Style: fontstyle.Bold, Size: 7, Top: 22, Left: 2}),
+				text.New(docSustentoName(info.CodDocModificado)+" "+info.NumDocModificado, props.Text{Size: 7, Top: 22, Left: 45}),
+				text.New("Fecha Emisión (Comprobante a modificar):", props.Text{Style: fontstyle.Bold, Size: 7, Top: 27, Left: 2}),
+				text.New(info.FechaEmisionDocSustento, props.Text{Size: 7, Top: 27, Left: 60}),
+			),
+			col.New(5).Add(
+				text.New("R.U.C. / C.I.: "+info.IdentificacionComprador, props.Text{Size: 7, Top: 8, Align: align.Right, Right: 5}),
+			),
+		),
+	}
+}
+
+func (g *RideGenerator) buildMotivosRowsND(nd *NotaDebito) []core.Row {
+	var rows []core.Row
+	headerStyle := &props.Cell{BackgroundColor: &props.Color{Red: 240, Green: 240, Blue: 240}, BorderType: border.Full, BorderThickness: 0.1}
+
+	rows = append(rows, row.New(8).WithStyle(headerStyle).Add(
+		col.New(9).Add(text.New("Razón de la Modificación", props.Text{Style: fontstyle.Bold, Size: 7, Top: 1.5, Left: 2})),
+		col.New(3).Add(text.New("Valor de la Modificación", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Right, Top: 1.5, Right: 2})),
+	))
+
+	for _, motivo := range nd.Motivos.Motivo {
+		rows = append(rows, row.New(6).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+			col.New(9).Add(text.New(motivo.Razon, props.Text{Size: 7, Align: align.Left, Top: 1, Left: 2})),
+			col.New(3).Add(text.New(motivo.Valor, props.Text{Size: 7, Align: align.Right, Top: 1, Right: 2})),
+		))
+	}
+	return rows
+}
+
+func (g *RideGenerator) buildFooterND(nd *NotaDebito) []core.Row {
+	vat := rideVATFromTaxes(nd.InfoNotaDebito.Impuestos.Impuesto)
+
+	return []core.Row{
+		row.New(30).Add(
+			col.New(7).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+				text.New("Información Adicional", props.Text{Style: fontstyle.Bold, Size: 8, Top: 2, Left: 2}),
+				text.New("Cargo adicional sobre la factura "+nd.InfoNotaDebito.NumDocModificado, props.Text{Size: 7, Top: 8, Left: 2}),
+			),
+			col.New(5).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+				text.New(vat.label("SUBTOTAL"), props.Text{Size: 7, Top: 2, Left: 2}),
+				text.New(rideAmount(vat.Taxed), props.Text{Size: 7, Align: align.Right, Top: 2, Right: 2}),
+				text.New("SUBTOTAL 0%", props.Text{Size: 7, Top: 7, Left: 2}),
+				text.New(rideAmount(vat.Zero), props.Text{Size: 7, Align: align.Right, Top: 7, Right: 2}),
+				text.New("SUBTOTAL SIN IMPUESTOS", props.Text{Size: 7, Top: 12, Left: 2}),
+				text.New(nd.InfoNotaDebito.TotalSinImpuestos, props.Text{Size: 7, Align: align.Right, Top: 12, Right: 2}),
+				text.New(vat.label("IVA"), props.Text{Size: 7, Top: 17, Left: 2}),
+				text.New(rideAmount(vat.VAT), props.Text{Size: 7, Align: align.Right, Top: 17, Right: 2}),
+				text.New("VALOR TOTAL", props.Text{Style: fontstyle.Bold, Size: 8, Top: 22, Left: 2}),
+				text.New(nd.InfoNotaDebito.ValorTotal, props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right, Top: 22, Right: 2}),
+			),
+		),
+	}
+}
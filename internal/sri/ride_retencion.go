@@ -0,0 +1,155 @@
+package sri
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateRetencionRide genera el RIDE de un Comprobante de Retención.
+func (g *RideGenerator) GenerateRetencionRide(cr *ComprobanteRetencion, ridePath string, logoPath string, authDate time.Time, authNumber string) error {
+	m := newRideDocument()
+
+	m.AddRows(g.buildDocumentHeader(rideHeader{
+		info:               cr.InfoTributaria,
+		title:              "COMPROBANTE DE RETENCIÓN",
+		dirEstablecimiento: cr.InfoCompRetencion.DirEstablecimiento,
+		obligado:           cr.InfoCompRetencion.ObligadoContabilidad,
+	}, logoPath, authDate, authNumber)...)
+	m.AddRow(2)
+	m.AddRows(g.buildSubjectInfoRetencion(cr)...)
+	m.AddRow(2)
+	m.AddRows(g.buildDetailsRowsRetencion(cr)...)
+	m.AddRow(2)
+	m.AddRows(g.buildFooterRetencion(cr)...)
+
+	document, err := m.Generate()
+	if err != nil {
+		return fmt.Errorf("error generando RIDE de retención: %w", err)
+	}
+
+	return document.Save(ridePath)
+}
+
+func (g *RideGenerator) buildSubjectInfoRetencion(cr *ComprobanteRetencion) []core.Row {
+	info := cr.InfoCompRetencion
+	return []core.Row{
+		row.New(22).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+			col.New(7).Add(
+				text.New("Razón Social / Nombres y Apellidos: "+info.RazonSocialSujetoRetenido, props.Text{Style: fontstyle.Bold, Size: 7, Top: 2, Left: 2}),
+				text.New("Identificación: "+info.IdentificacionSujetoRetenido, props.Text{Size: 7, Top: 8, Left: 2}),
+				text.New("Fecha Emisión: "+info.FechaEmision, props.Text{Size: 7, Top: 14, Left: 2}),
+			),
+			col.New(5).Add(
+				text.New("Periodo Fiscal: "+info.PeriodoFiscal, props.Text{Size: 7, Top: 14, Align: align.Right, Right: 5}),
+			),
+		),
+	}
+}
+
+func (g *RideGenerator) buildDetailsRowsRetencion(cr *ComprobanteRetencion) []core.Row {
+	var rows []core.Row
+	headerStyle := &props.Cell{BackgroundColor: &props.Color{Red: 240, Green: 240, Blue: 240}, BorderType: border.Full, BorderThickness: 0.1}
+
+	rows = append(rows, row.New(8).WithStyle(headerStyle).Add(
+		col.New(2).Add(text.New("Comprobante", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(2).Add(text.New("Número", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(2).Add(text.New("Fecha Emisión", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(2).Add(text.New("Base Imponible", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Right, Top: 1.5, Right: 2})),
+		col.New(1).Add(text.New("Impuesto", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(1).Add(text.New("Código", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Center, Top: 1.5})),
+		col.New(1).Add(text.New("%", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Right, Top: 1.5, Right: 2})),
+		col.New(1).Add(text.New("Valor", props.Text{Style: fontstyle.Bold, Size: 7, Align: align.Right, Top: 1.5, Right: 2})),
+	))
+
+	for _, doc := range cr.DocsSustento.DocSustento {
+		for _, ret := range doc.Retenciones.Retencion {
+			rows = append(rows, row.New(6).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+				col.New(2).Add(text.New(docSustentoName(doc.CodDocSustento), props.Text{Size: 7, Align: align.Center, Top: 1})),
+				col.New(2).Add(text.New(formatNumDoc(doc.NumDocSustento), props.Text{Size: 7, Align: align.Center, Top: 1})),
+				col.New(2).Add(text.New(doc.FechaEmisionDocSustento, props.Text{Size: 7, Align: align.Center, Top: 1})),
+				col.New(2).Add(text.New(ret.BaseImponible, props.Text{Size: 7, Align: align.Right, Top: 1, Right: 2})),
+				col.New(1).Add(text.New(retencionTaxName(ret.Codigo), props.Text{Size: 7, Align: align.Center, Top: 1})),
+				col.New(1).Add(text.New(ret.CodigoRetencion, props.Text{Size: 7, Align: align.Center, Top: 1})),
+				col.New(1).Add(text.New(ret.PorcentajeRetener, props.Text{Size: 7, Align: align.Right, Top: 1, Right: 2})),
+				col.New(1).Add(text.New(ret.ValorRetenido, props.Text{Size: 7, Align: align.Right, Top: 1, Right: 2})),
+			))
+		}
+	}
+	return rows
+}
+
+func (g *RideGenerator) buildFooterRetencion(cr *ComprobanteRetencion) []core.Row {
+	totalRenta, totalIVA := 0.0, 0.0
+	for _, doc := range cr.DocsSustento.DocSustento {
+		for _, ret := range doc.Retenciones.Retencion {
+			val, _ := strconv.ParseFloat(ret.ValorRetenido, 64)
+			if ret.Codigo == "2" {
+				totalIVA += val
+			} else {
+				totalRenta += val
+			}
+		}
+	}
+
+	return []core.Row{
+		row.New(25).Add(
+			col.New(7).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+				text.New("Información Adicional", props.Text{Style: fontstyle.Bold, Size: 8, Top: 2, Left: 2}),
+				text.New("Sujeto retenido: "+cr.InfoCompRetencion.RazonSocialSujetoRetenido, props.Text{Size: 7, Top: 8, Left: 2}),
+			),
+			col.New(5).WithStyle(&props.Cell{BorderType: border.Full, BorderThickness: 0.1}).Add(
+				text.New("TOTAL RETENIDO RENTA", props.Text{Size: 7, Top: 2, Left: 2}),
+				text.New(fmt.Sprintf("%.2f", totalRenta), props.Text{Size: 7, Align: align.Right, Top: 2, Right: 2}),
+				text.New("TOTAL RETENIDO IVA", props.Text{Size: 7, Top: 7, Left: 2}),
+				text.New(fmt.Sprintf("%.2f", totalIVA), props.Text{Size: 7, Align: align.Right, Top: 7, Right: 2}),
+				text.New("TOTAL RETENIDO", props.Text{Style: fontstyle.Bold, Size: 8, Top: 13, Left: 2}),
+				text.New(fmt.Sprintf("%.2f", totalRenta+totalIVA), props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Right, Top: 13, Right: 2}),
+			),
+		),
+	}
+}
+
+// docSustentoName traduce el código de documento sustento a su nombre para el RIDE.
+func docSustentoName(codDoc string) string {
+	switch codDoc {
+	case "01":
+		return "FACTURA"
+	case "03":
+		return "LIQ. COMPRA"
+	case "05":
+		return "NOTA DÉBITO"
+	default:
+		return codDoc
+	}
+}
+
+func retencionTaxName(codigo string) string {
+	switch codigo {
+	case "1":
+		return "RENTA"
+	case "2":
+		return "IVA"
+	case "6":
+		return "ISD"
+	default:
+		return codigo
+	}
+}
+
+// formatNumDoc convierte 001001000000123 en 001-001-000000123 para lectura humana.
+func formatNumDoc(num string) string {
+	if len(num) != 15 {
+		return num
+	}
+	return fmt.Sprintf("%s-%s-%s", num[0:3], num[3:6], num[6:15])
+}
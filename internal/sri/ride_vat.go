@@ -0,0 +1,103 @@
+package sri
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// rideVAT resume los impuestos del comprobante para el pie del RIDE. Las bases gravadas de cualquier
+// tarifa se suman en Taxed; Rate es su porcentaje cuando todas comparten la misma tarifa (el 12% de
+// una factura histórica o el 15% actual) y queda vacío si hay varias. ICE e IRBPNR suman los valores
+// de los impuestos con código 3 y 5.
+type rideVAT struct {
+	Taxed, Zero, NotObject, Exempt, VAT float64
+	ICE, IRBPNR                         float64
+	Rate                                string
+}
+
+// rideVATFromTotals resume los totalImpuesto de facturas, notas de crédito y liquidaciones, que no
+// llevan tarifa: el porcentaje se deduce del valor sobre la base.
+func rideVATFromTotals(totals []TotalImpuesto) rideVAT {
+	taxes := make([]Impuesto, len(totals))
+	for i, t := range totals {
+		taxes[i] = Impuesto{Codigo: t.Codigo, CodigoPorcentaje: t.CodigoPorcentaje, BaseImponible: t.BaseImponible, Valor: t.Valor}
+	}
+	return rideVATFromTaxes(taxes)
+}
+
+func rideVATFromTaxes(taxes []Impuesto) rideVAT {
+	var v rideVAT
+	rates := map[string]bool{}
+	for _, t := range taxes {
+		base, _ := strconv.ParseFloat(t.BaseImponible, 64)
+		valor, _ := strconv.ParseFloat(t.Valor, 64)
+		switch t.Codigo {
+		case "3":
+			v.ICE += valor
+			continue
+		case "5":
+			v.IRBPNR += valor
+			continue
+		}
+		switch t.CodigoPorcentaje {
+		case "0":
+			v.Zero += base
+		case "6":
+			v.NotObject += base
+		case "7":
+			v.Exempt += base
+		default:
+			v.Taxed += base
+			v.VAT += valor
+			rate := t.Tarifa
+			if rate == "" && base > 0 {
+				rate = fmt.Sprintf("%g", math.Round(valor/base*100))
+			}
+			rates[rate] = true
+		}
+	}
+	if len(rates) == 1 {
+		for rate := range rates {
+			v.Rate = rate
+		}
+	}
+	return v
+}
+
+// label devuelve el título de la fila con el porcentaje gravado, por ejemplo "SUBTOTAL 15%".
+func (v rideVAT) label(prefix string) string {
+	if v.Rate == "" {
+		return prefix + " GRAVADO"
+	}
+	return fmt.Sprintf("%s %s%%", prefix, v.Rate)
+}
+
+// otherTaxRows imprime las filas de ICE e IRBPNR que tengan valor desde la posición top (en mm) y
+// devuelve la posición libre siguiente.
+func (v rideVAT) otherTaxRows(top float64) ([]core.Component, float64) {
+	var rows []core.Component
+	for _, tax := range []struct {
+		name  string
+		value float64
+	}{{"ICE", v.ICE}, {"IRBPNR", v.IRBPNR}} {
+		if tax.value == 0 {
+			continue
+		}
+		rows = append(rows,
+			text.New(tax.name, props.Text{Size: 7, Top: top, Left: 2}),
+			text.New(rideAmount(tax.value), props.Text{Size: 7, Align: align.Right, Top: top, Right: 2}),
+		)
+		top += 5
+	}
+	return rows, top
+}
+
+func rideAmount(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
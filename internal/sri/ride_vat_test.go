@@ -0,0 +1,48 @@
+package sri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRideVATFromTotals(t *testing.T) {
+	t.Run("Factura histórica con tarifa 12%", func(t *testing.T) {
+		vat := rideVATFromTotals([]TotalImpuesto{
+			{Codigo: "2", CodigoPorcentaje: "2", BaseImponible: "100.00", Valor: "12.00"},
+			{Codigo: "2", CodigoPorcentaje: "0", BaseImponible: "10.00", Valor: "0.00"},
+		})
+		assert.Equal(t, "SUBTOTAL 12%", vat.label("SUBTOTAL"))
+		assert.Equal(t, "12.00", rideAmount(vat.VAT))
+		assert.Equal(t, "10.00", rideAmount(vat.Zero))
+	})
+
+	t.Run("Varias tarifas gravadas se suman", func(t *testing.T) {
+		vat := rideVATFromTotals([]TotalImpuesto{
+			{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "100.00", Valor: "15.00"},
+			{Codigo: "2", CodigoPorcentaje: "5", BaseImponible: "40.00", Valor: "2.00"},
+			{Codigo: "2", CodigoPorcentaje: "7", BaseImponible: "5.00", Valor: "0.00"},
+		})
+		assert.Equal(t, "IVA GRAVADO", vat.label("IVA"))
+		assert.Equal(t, "140.00", rideAmount(vat.Taxed))
+		assert.Equal(t, "17.00", rideAmount(vat.VAT))
+		assert.Equal(t, "5.00", rideAmount(vat.Exempt))
+	})
+}
+
+func TestRideVATFromTotals_ICEIRBPNR(t *testing.T) {
+	vat := rideVATFromTotals([]TotalImpuesto{
+		{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "115.00", Valor: "17.25"},
+		{Codigo: "3", CodigoPorcentaje: "3011", BaseImponible: "100.00", Valor: "15.00"},
+		{Codigo: "5", CodigoPorcentaje: "5001", BaseImponible: "10", Valor: "0.20"},
+	})
+	assert.Equal(t, "SUBTOTAL 15%", vat.label("SUBTOTAL"))
+	assert.Equal(t, "115.00", rideAmount(vat.Taxed))
+	assert.Equal(t, "17.25", rideAmount(vat.VAT))
+	assert.Equal(t, "15.00", rideAmount(vat.ICE))
+	assert.Equal(t, "0.20", rideAmount(vat.IRBPNR))
+
+	rows, next := vat.otherTaxRows(17)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, 27.0, next)
+}
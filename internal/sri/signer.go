@@ -65,4 +65,21 @@ func (s *DocumentSigner) SignCreditNote(xmlBytes []byte, algo signer.HashAlgorit
 	}
 
 	return []byte(signedXML), nil
-}
\ No newline at end of file
+}
+
+// SignDocument firma los demás comprobantes (retención, nota de débito, guía de remisión y
+// liquidación de compra). La librería solo expone funciones para factura y nota de crédito, así que
+// se firman con SignXAdES, que no depende del nodo raíz.
+func (s *DocumentSigner) SignDocument(xmlBytes []byte, algo signer.HashAlgorithm) ([]byte, error) {
+	p12Bytes, err := os.ReadFile(s.p12Path)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el certificado .p12: %w", err)
+	}
+
+	signedXML, err := SignXAdES(xmlBytes, p12Bytes, s.password, algo)
+	if err != nil {
+		return nil, fmt.Errorf("error al firmar el comprobante: %w", err)
+	}
+
+	return signedXML, nil
+}
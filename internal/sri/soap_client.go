@@ -2,10 +2,14 @@ package sri
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -17,26 +21,67 @@ const (
 	// Ambiente de Producción (Real)
 	URLRecepcionProduccion    = "https://cel.sri.gob.ec/comprobantes-electronicos-ws/RecepcionComprobantesOffline"
 	URLAutorizacionProduccion = "https://cel.sri.gob.ec/comprobantes-electronicos-ws/AutorizacionComprobantesOffline"
+
+	// Rutas de los servicios bajo la URL base del SRI, compartidas por ambos ambientes y el simulador.
+	PathRecepcion    = "/comprobantes-electronicos-ws/RecepcionComprobantesOffline"
+	PathAutorizacion = "/comprobantes-electronicos-ws/AutorizacionComprobantesOffline"
 )
 
+// maxResponseSize limita la lectura de una respuesta; un comprobante autorizado ocupa unos cientos de KB.
+const maxResponseSize = 10 << 20
+
 // Client defines the interface for interacting with SRI Web Services.
+//
+// Any answer from the SRI, including DEVUELTA or NO AUTORIZADO, comes back as a response; use
+// Rechazo to get it as a *RejectionError. Errors are *NetworkError, *SOAPFaultError or ErrCircuitOpen.
 type Client interface {
-	EnviarComprobante(xmlFirmado []byte, environment int) (*RespuestaRecepcion, error)
-	AutorizarComprobante(claveAcceso string, environment int) (*RespuestaAutorizacion, error)
+	EnviarComprobante(ctx context.Context, xmlFirmado []byte, environment int) (*RespuestaRecepcion, error)
+	AutorizarComprobante(ctx context.Context, claveAcceso string, environment int) (*RespuestaAutorizacion, error)
+	AutorizarLote(ctx context.Context, claveAccesoLote string, environment int) (*RespuestaAutorizacionLote, error)
 }
 
 // SoapClient implements the Client interface using standard HTTP.
 type SoapClient struct {
+	// Timeout limita cada intento; el contexto de la llamada limita el total con reintentos.
 	Timeout time.Duration
+	// MaxRetries es la cantidad de reintentos ante errores transitorios.
+	MaxRetries int
+	// BaseBackoff es la espera antes del primer reintento; se duplica en cada uno hasta MaxBackoff,
+	// con una variación aleatoria para que varios clientes no reintenten a la vez.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Breaker corta las llamadas mientras el SRI está caído. Nil lo desactiva.
+	Breaker *CircuitBreaker
+	// RecepcionURL reemplaza la URL oficial del servicio de recepción en ambos ambientes. Vacío usa la del SRI.
+	RecepcionURL string
+	// AutorizacionURL reemplaza la URL oficial del servicio de autorización en ambos ambientes
+	// (ej. un servidor local en las pruebas). Vacío usa la del SRI.
+	AutorizacionURL string
 }
 
 // NewSoapClient creates a new SRI client.
 func NewSoapClient() *SoapClient {
 	return &SoapClient{
-		Timeout: 30 * time.Second,
+		Timeout:     30 * time.Second,
+		MaxRetries:  3,
+		BaseBackoff: time.Second,
+		MaxBackoff:  8 * time.Second,
+		Breaker:     NewCircuitBreaker(5, time.Minute),
 	}
 }
 
+// WithBaseURL apunta ambos servicios a otro servidor, como el simulador local, conservando las rutas
+// oficiales. Una URL vacía deja las del SRI.
+func (c *SoapClient) WithBaseURL(baseURL string) *SoapClient {
+	if baseURL == "" {
+		return c
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	c.RecepcionURL = baseURL + PathRecepcion
+	c.AutorizacionURL = baseURL + PathAutorizacion
+	return c
+}
+
 // --- RECEPCIÓN ---
 
 type RespuestaRecepcion struct {
@@ -98,12 +143,15 @@ type SoapAutorizacionEnvelope struct {
 }
 
 // EnviarComprobante envía el XML firmado al Web Service de Recepción del SRI.
-func (c *SoapClient) EnviarComprobante(xmlFirmado []byte, environment int) (*RespuestaRecepcion, error) {
+func (c *SoapClient) EnviarComprobante(ctx context.Context, xmlFirmado []byte, environment int) (*RespuestaRecepcion, error) {
 	// 1. Seleccionar URL según ambiente
 	url := URLRecepcionPruebas
 	if environment == 2 {
 		url = URLRecepcionProduccion
 	}
+	if c.RecepcionURL != "" {
+		url = c.RecepcionURL
+	}
 
 	// 2. Codificar XML a Base64
 	xmlBase64 := base64.StdEncoding.EncodeToString(xmlFirmado)
@@ -119,41 +167,22 @@ func (c *SoapClient) EnviarComprobante(xmlFirmado []byte, environment int) (*Res
    </soapenv:Body>
 </soapenv:Envelope>`, xmlBase64)
 
-	return c.ejecutarRecepcion(url, soapEnvelope)
-}
-
-func (c *SoapClient) ejecutarRecepcion(url, soapEnvelope string) (*RespuestaRecepcion, error) {
-	req, err := http.NewRequest("POST", url, bytes.NewBufferString(soapEnvelope))
-	if err != nil {
-		return nil, fmt.Errorf("error creando petición SOAP: %w", err)
-	}
-	req.Header.Set("Content-Type", "text/xml; charset=UTF-8")
-
-	client := &http.Client{Timeout: c.Timeout}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error de conexión con el SRI: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("el SRI respondió con estado HTTP: %d", resp.StatusCode)
-	}
-
 	var envelope SoapRecepcionEnvelope
-	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
-		return nil, fmt.Errorf("error leyendo respuesta del SRI: %w", err)
+	if err := c.call(ctx, "recepción", url, soapEnvelope, &envelope); err != nil {
+		return nil, err
 	}
-
 	return &envelope.Body.ValidarComprobanteResponse.RespuestaRecepcion, nil
 }
 
 // AutorizarComprobante consulta el estado de autorización usando la Clave de Acceso.
-func (c *SoapClient) AutorizarComprobante(claveAcceso string, environment int) (*RespuestaAutorizacion, error) {
+func (c *SoapClient) AutorizarComprobante(ctx context.Context, claveAcceso string, environment int) (*RespuestaAutorizacion, error) {
 	url := URLAutorizacionPruebas
 	if environment == 2 {
 		url = URLAutorizacionProduccion
 	}
+	if c.AutorizacionURL != "" {
+		url = c.AutorizacionURL
+	}
 
 	soapEnvelope := fmt.Sprintf(`
 <soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ec="http://ec.gob.sri.ws.autorizacion">
@@ -165,27 +194,98 @@ func (c *SoapClient) AutorizarComprobante(claveAcceso string, environment int) (
    </soapenv:Body>
 </soapenv:Envelope>`, claveAcceso)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBufferString(soapEnvelope))
+	var envelope SoapAutorizacionEnvelope
+	if err := c.call(ctx, "autorización", url, soapEnvelope, &envelope); err != nil {
+		return nil, err
+	}
+	return &envelope.Body.AutorizacionComprobanteResponse.RespuestaAutorizacion, nil
+}
+
+// call envía la petición con reintentos y registra el resultado en el circuito.
+func (c *SoapClient) call(ctx context.Context, op, url, soapEnvelope string, out any) error {
+	if c.Breaker != nil && !c.Breaker.Allow() {
+		return ErrCircuitOpen
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.post(ctx, op, url, soapEnvelope, out)
+		if err == nil || !IsTransient(err) || attempt >= c.MaxRetries || ctx.Err() != nil {
+			break
+		}
+		if waitErr := sleepContext(ctx, c.backoff(attempt)); waitErr != nil {
+			break
+		}
+	}
+
+	// Una cancelación del llamador no dice nada sobre el estado del SRI
+	if c.Breaker != nil && ctx.Err() == nil {
+		if IsTransient(err) {
+			c.Breaker.Failure()
+		} else {
+			c.Breaker.Success()
+		}
+	}
+	return err
+}
+
+// backoff devuelve la espera antes del reintento attempt+1: entre la mitad y el total del retardo exponencial.
+func (c *SoapClient) backoff(attempt int) time.Duration {
+	d := c.BaseBackoff << attempt
+	if c.MaxBackoff > 0 && (d > c.MaxBackoff || d <= 0) {
+		d = c.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(d-half)+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// post hace un intento y decodifica el cuerpo SOAP en out.
+func (c *SoapClient) post(ctx context.Context, op, url, soapEnvelope string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBufferString(soapEnvelope))
 	if err != nil {
-		return nil, fmt.Errorf("error creando petición Autorización: %w", err)
+		return fmt.Errorf("error creando petición SOAP: %w", err)
 	}
 	req.Header.Set("Content-Type", "text/xml; charset=UTF-8")
 
 	client := &http.Client{Timeout: c.Timeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error conectando a Autorización SRI: %w", err)
+		return &NetworkError{Op: op, Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("SRI Autorización error HTTP: %d", resp.StatusCode)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return &NetworkError{Op: op, Err: err}
 	}
 
-	var envelope SoapAutorizacionEnvelope
-	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
-		return nil, fmt.Errorf("error decodificando respuesta Autorización: %w", err)
+	// Un SOAP Fault llega con HTTP 500; es una respuesta del servicio, no una caída
+	var fault struct {
+		Fault *SOAPFaultError `xml:"Body>Fault"`
+	}
+	if xml.Unmarshal(body, &fault) == nil && fault.Fault != nil {
+		return fault.Fault
 	}
 
-	return &envelope.Body.AutorizacionComprobanteResponse.RespuestaAutorizacion, nil
-}
\ No newline at end of file
+	if resp.StatusCode != http.StatusOK {
+		return &NetworkError{Op: op, StatusCode: resp.StatusCode}
+	}
+	if err := xml.Unmarshal(body, out); err != nil {
+		return &NetworkError{Op: op, Err: fmt.Errorf("respuesta ilegible: %w", err)}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package sri
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const recibidaResponse = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+	`<ns2:validarComprobanteResponse xmlns:ns2="http://ec.gob.sri.ws.recepcion">` +
+	`<RespuestaRecepcionComprobante><estado>RECIBIDA</estado><comprobantes/></RespuestaRecepcionComprobante>` +
+	`</ns2:validarComprobanteResponse></soap:Body></soap:Envelope>`
+
+const faultResponse = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+	`<soap:Fault><faultcode>soap:Client</faultcode><faultstring>Unmarshalling Error</faultstring></soap:Fault>` +
+	`</soap:Body></soap:Envelope>`
+
+// testClient apunta un cliente sin esperas entre reintentos al handler indicado.
+func testClient(t *testing.T, handler http.HandlerFunc) *SoapClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client := NewSoapClient().WithBaseURL(srv.URL)
+	client.Timeout = 2 * time.Second
+	client.BaseBackoff = time.Millisecond
+	client.MaxBackoff = 5 * time.Millisecond
+	return client
+}
+
+func TestSoapClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Retries transient failures until the SRI answers", func(t *testing.T) {
+		var calls atomic.Int32
+		client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(recibidaResponse))
+		})
+
+		resp, err := client.EnviarComprobante(ctx, []byte("<factura/>"), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "RECIBIDA", resp.Estado)
+		assert.Equal(t, int32(3), calls.Load())
+		assert.False(t, client.Breaker.Open())
+	})
+
+	t.Run("Gives up after MaxRetries with a network error", func(t *testing.T) {
+		var calls atomic.Int32
+		client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		})
+		client.MaxRetries = 2
+
+		_, err := client.EnviarComprobante(ctx, []byte("<factura/>"), 1)
+		var netErr *NetworkError
+		require.ErrorAs(t, err, &netErr)
+		assert.Equal(t, http.StatusBadGateway, netErr.StatusCode)
+		assert.True(t, IsTransient(err))
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		_, err := client.AutorizarComprobante(ctx, "123", 1)
+		require.Error(t, err)
+		assert.False(t, IsTransient(err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Returns a SOAP fault without retrying", func(t *testing.T) {
+		var calls atomic.Int32
+		client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(faultResponse))
+		})
+
+		_, err := client.EnviarComprobante(ctx, []byte("<factura/>"), 1)
+		var fault *SOAPFaultError
+		require.ErrorAs(t, err, &fault)
+		assert.Equal(t, "soap:Client", fault.Code)
+		assert.Equal(t, "Unmarshalling Error", fault.Message)
+		assert.False(t, IsTransient(err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Opens the circuit after repeated failures", func(t *testing.T) {
+		var calls atomic.Int32
+		client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		client.MaxRetries = 0
+		client.Breaker = NewCircuitBreaker(2, time.Hour)
+
+		for range 2 {
+			_, err := client.EnviarComprobante(ctx, []byte("<factura/>"), 1)
+			require.Error(t, err)
+		}
+		_, err := client.EnviarComprobante(ctx, []byte("<factura/>"), 1)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.True(t, IsTransient(err))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		client.BaseBackoff = time.Hour
+		client.MaxBackoff = time.Hour
+
+		cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := client.EnviarComprobante(cctx, []byte("<factura/>"), 1)
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+		// Una cancelación no cuenta como falla del SRI
+		assert.False(t, client.Breaker.Open())
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	assert.True(t, b.Allow())
+	b.Failure()
+	assert.True(t, b.Open())
+	assert.False(t, b.Allow())
+
+	// Pasado el enfriamiento deja pasar una sola llamada de prueba
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	b.Success()
+	assert.False(t, b.Open())
+	assert.True(t, b.Allow())
+}
+
+func TestRechazo(t *testing.T) {
+	var rec RespuestaRecepcion
+	rec.Estado = "DEVUELTA"
+	rec.Comprobantes.Comprobante = []ComprobanteRecepcion{{ClaveAcceso: "123"}}
+	rec.Comprobantes.Comprobante[0].Mensajes.Mensaje = []Mensaje{{Identificador: "70", Mensaje: "CLAVE DE ACCESO EN PROCESAMIENTO"}}
+
+	rej := rec.Rechazo()
+	require.NotNil(t, rej)
+	assert.True(t, rej.EnProcesamiento())
+	assert.Equal(t, "CLAVE DE ACCESO EN PROCESAMIENTO", rej.Message())
+	assert.Equal(t, "comprobante DEVUELTA: 70 CLAVE DE ACCESO EN PROCESAMIENTO", rej.Error())
+
+	rec.Estado = "RECIBIDA"
+	assert.Nil(t, rec.Rechazo())
+
+	auth := Autorizacion{Estado: "NO AUTORIZADO"}
+	auth.Mensajes.Mensaje = []Mensaje{{Identificador: "45", Mensaje: "SECUENCIAL REGISTRADO"}}
+	var target *RejectionError
+	assert.True(t, errors.As(error(auth.Rechazo()), &target))
+	assert.False(t, target.EnProcesamiento())
+	assert.Nil(t, (&Autorizacion{Estado: "AUTORIZADO"}).Rechazo())
+}
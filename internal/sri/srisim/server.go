@@ -0,0 +1,361 @@
+// Package srisim simula los servicios web offline del SRI (RecepcionComprobantesOffline y
+// AutorizacionComprobantesOffline) para desarrollo y pruebas sin depender del ambiente celcer.
+//
+// El servidor se monta con httptest o con el comando sri_simulator y aplica escenarios
+// configurables por clave de acceso: recepción, devolución con códigos de error, autorización
+// demorada, no autorización y demoras que superan el timeout del cliente.
+package srisim
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+// Códigos de error que el SRI devuelve con más frecuencia en recepción y autorización.
+const (
+	ErrEstructuraXML      = "35"
+	ErrFirmaInvalida      = "39"
+	ErrClaveRegistrada    = "43"
+	ErrSecuencialRepetido = "45"
+	ErrEnProcesamiento    = "70"
+)
+
+var mensajesError = map[string]string{
+	ErrEstructuraXML:      "ARCHIVO NO CUMPLE ESTRUCTURA XML",
+	ErrFirmaInvalida:      "FIRMA INVALIDA",
+	ErrClaveRegistrada:    "CLAVE ACCESO REGISTRADA",
+	ErrSecuencialRepetido: "SECUENCIAL REGISTRADO",
+	ErrEnProcesamiento:    "CLAVE DE ACCESO EN PROCESAMIENTO",
+}
+
+// Error arma el mensaje del SRI para el código indicado, con el texto oficial si se conoce.
+func Error(code string) sri.Mensaje {
+	texto, ok := mensajesError[code]
+	if !ok {
+		texto = "ERROR " + code
+	}
+	return sri.Mensaje{Identificador: code, Mensaje: texto, Tipo: "ERROR"}
+}
+
+// Scenario describe cómo responde el simulador a un comprobante. El valor cero lo recibe y lo
+// autoriza en la primera consulta.
+type Scenario struct {
+	// Devuelta rechaza el comprobante en recepción con estos mensajes; no queda registrado.
+	Devuelta []sri.Mensaje
+	// PollsEnProceso es la cantidad de consultas de autorización que responden EN PROCESO antes
+	// del resultado final.
+	PollsEnProceso int
+	// NoAutorizado responde NO AUTORIZADO con estos mensajes en lugar de AUTORIZADO.
+	NoAutorizado []sri.Mensaje
+	// Delay retrasa cada respuesta; uno mayor al timeout del cliente simula un SRI que no contesta.
+	Delay time.Duration
+}
+
+// comprobante es un documento recibido y el avance de su autorización.
+type comprobante struct {
+	xml        string
+	scenario   Scenario
+	polls      int
+	autorizado *time.Time
+	rechazado  bool
+}
+
+// Server implementa ambos servicios en un único http.Handler; distingue la operación por el
+// cuerpo SOAP, así que responde en cualquier ruta.
+type Server struct {
+	mu        sync.Mutex
+	def       Scenario
+	scenarios map[string]Scenario
+	docs      map[string]*comprobante
+	lotes     map[string][]string
+	now       func() time.Time
+}
+
+// NewServer crea un simulador con el escenario por defecto para todas las claves.
+func NewServer(def Scenario) *Server {
+	return &Server{
+		def:       def,
+		scenarios: map[string]Scenario{},
+		docs:      map[string]*comprobante{},
+		lotes:     map[string][]string{},
+		now:       time.Now,
+	}
+}
+
+// SetScenario fija el escenario de una clave de acceso antes de que se envíe.
+func (s *Server) SetScenario(claveAcceso string, sc Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios[claveAcceso] = sc
+}
+
+// SetDefault cambia el escenario de las claves sin uno propio.
+func (s *Server) SetDefault(sc Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.def = sc
+}
+
+// Received indica si la clave fue recibida y cuántas veces se consultó su autorización.
+func (s *Server) Received(claveAcceso string) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.docs[claveAcceso]
+	if !ok {
+		return false, 0
+	}
+	return true, doc.polls
+}
+
+var (
+	xmlRecepcion       = regexp.MustCompile(`(?s)<xml>\s*(.*?)\s*</xml>`)
+	claveAutorizacion  = regexp.MustCompile(`<claveAccesoComprobante>\s*(\d+)\s*</claveAccesoComprobante>`)
+	claveLote          = regexp.MustCompile(`<claveAccesoLote>\s*(\d+)\s*</claveAccesoLote>`)
+	claveEnComprobante = regexp.MustCompile(`<claveAcceso>\s*(\d+)\s*</claveAcceso>`)
+)
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "solo se acepta POST", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "no se pudo leer la petición", http.StatusBadRequest)
+		return
+	}
+
+	if m := claveAutorizacion.FindSubmatch(body); m != nil {
+		clave := string(m[1])
+		if !s.wait(r, s.scenarioFor(clave).Delay) {
+			return
+		}
+		s.write(w, "autorizacionComprobanteResponse", "http://ec.gob.sri.ws.autorizacion", "RespuestaAutorizacionComprobante", s.autorizar(clave))
+		return
+	}
+	if m := claveLote.FindSubmatch(body); m != nil {
+		resp, delay := s.autorizarLote(string(m[1]))
+		if !s.wait(r, delay) {
+			return
+		}
+		s.write(w, "autorizacionComprobanteLoteResponse", "http://ec.gob.sri.ws.autorizacion", "RespuestaAutorizacionLote", resp)
+		return
+	}
+	if m := xmlRecepcion.FindSubmatch(body); m != nil {
+		resp, delay := s.recibir(m[1])
+		if !s.wait(r, delay) {
+			return
+		}
+		s.write(w, "validarComprobanteResponse", "http://ec.gob.sri.ws.recepcion", "RespuestaRecepcionComprobante", resp)
+		return
+	}
+	http.Error(w, "operación SOAP desconocida", http.StatusBadRequest)
+}
+
+func (s *Server) scenarioFor(clave string) Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if doc, ok := s.docs[clave]; ok {
+		return doc.scenario
+	}
+	if sc, ok := s.scenarios[clave]; ok {
+		return sc
+	}
+	return s.def
+}
+
+// wait aplica la demora del escenario; devuelve false si el cliente abandonó la petición.
+func (s *Server) wait(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(d):
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func (s *Server) recibir(payload []byte) (sri.RespuestaRecepcion, time.Duration) {
+	decoded, err := base64.StdEncoding.DecodeString(string(payload))
+	if err != nil {
+		return devuelta("", Error(ErrEstructuraXML)), s.scenarioFor("").Delay
+	}
+	var lote sri.Lote
+	if bytes.Contains(decoded, []byte("<lote")) {
+		if err := xml.Unmarshal(decoded, &lote); err != nil {
+			return devuelta("", Error(ErrEstructuraXML)), s.scenarioFor("").Delay
+		}
+		return s.recibirLote(&lote)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recibirComprobante(decoded)
+}
+
+// recibirLote recibe cada comprobante del lote por separado. Como el SRI, responde DEVUELTA con
+// los comprobantes rechazados y deja recibidos los demás.
+func (s *Server) recibirLote(lote *sri.Lote) (sri.RespuestaRecepcion, time.Duration) {
+	if _, err := sri.ParseAccessKey(lote.ClaveAcceso); err != nil || len(lote.Comprobantes) == 0 {
+		return devuelta(lote.ClaveAcceso, Error(ErrEstructuraXML)), s.scenarioFor("").Delay
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := sri.RespuestaRecepcion{Estado: "RECIBIDA"}
+	var claves []string
+	var delay time.Duration
+	for _, c := range lote.Comprobantes {
+		r, d := s.recibirComprobante([]byte(c.XML))
+		delay = max(delay, d)
+		if m := claveEnComprobante.FindStringSubmatch(c.XML); m != nil {
+			claves = append(claves, m[1])
+		}
+		if r.Estado == "DEVUELTA" {
+			resp.Estado = "DEVUELTA"
+			resp.Comprobantes.Comprobante = append(resp.Comprobantes.Comprobante, r.Comprobantes.Comprobante...)
+		}
+	}
+	s.lotes[lote.ClaveAcceso] = claves
+	return resp, delay
+}
+
+// recibirComprobante registra un comprobante; se llama con el mutex tomado.
+func (s *Server) recibirComprobante(decoded []byte) (sri.RespuestaRecepcion, time.Duration) {
+	m := claveEnComprobante.FindSubmatch(decoded)
+	if m == nil {
+		return devuelta("", Error(ErrEstructuraXML)), s.def.Delay
+	}
+	clave := string(m[1])
+	if _, err := sri.ParseAccessKey(clave); err != nil {
+		return devuelta(clave, Error(ErrEstructuraXML)), s.def.Delay
+	}
+
+	if doc, ok := s.docs[clave]; ok && !doc.rechazado {
+		// Un reenvío se devuelve igual que en el SRI: en procesamiento o ya registrado.
+		// Un comprobante no autorizado sí puede corregirse y enviarse con la misma clave.
+		if doc.autorizado == nil {
+			return devuelta(clave, Error(ErrEnProcesamiento)), doc.scenario.Delay
+		}
+		return devuelta(clave, Error(ErrClaveRegistrada)), doc.scenario.Delay
+	}
+
+	sc, ok := s.scenarios[clave]
+	if !ok {
+		sc = s.def
+	}
+	if len(sc.Devuelta) > 0 {
+		return devuelta(clave, sc.Devuelta...), sc.Delay
+	}
+	s.docs[clave] = &comprobante{xml: string(decoded), scenario: sc}
+	return sri.RespuestaRecepcion{Estado: "RECIBIDA"}, sc.Delay
+}
+
+func devuelta(clave string, mensajes ...sri.Mensaje) sri.RespuestaRecepcion {
+	var c sri.ComprobanteRecepcion
+	c.ClaveAcceso = clave
+	c.Mensajes.Mensaje = mensajes
+	var resp sri.RespuestaRecepcion
+	resp.Estado = "DEVUELTA"
+	resp.Comprobantes.Comprobante = []sri.ComprobanteRecepcion{c}
+	return resp
+}
+
+func (s *Server) autorizar(clave string) sri.RespuestaAutorizacion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := sri.RespuestaAutorizacion{ClaveAccesoConsultada: clave, NumeroComprobantes: "0"}
+	auth, ok := s.autorizacion(clave)
+	if !ok {
+		return resp
+	}
+	resp.NumeroComprobantes = "1"
+	resp.Autorizaciones.Autorizacion = []sri.Autorizacion{auth}
+	return resp
+}
+
+// autorizarLote responde la autorización de cada comprobante recibido en el lote. Cada consulta
+// cuenta como una consulta de autorización de sus comprobantes.
+func (s *Server) autorizarLote(claveLote string) (sri.RespuestaAutorizacionLote, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := sri.RespuestaAutorizacionLote{ClaveAccesoLoteConsultada: claveLote, NumeroComprobantesLote: "0"}
+	var delay time.Duration
+	for _, clave := range s.lotes[claveLote] {
+		auth, ok := s.autorizacion(clave)
+		if !ok {
+			continue
+		}
+		delay = max(delay, s.docs[clave].scenario.Delay)
+		resp.Autorizaciones.Autorizacion = append(resp.Autorizaciones.Autorizacion, auth)
+	}
+	resp.NumeroComprobantesLote = strconv.Itoa(len(resp.Autorizaciones.Autorizacion))
+	return resp, delay
+}
+
+// autorizacion avanza la consulta de un comprobante recibido; se llama con el mutex tomado.
+func (s *Server) autorizacion(clave string) (sri.Autorizacion, bool) {
+	doc, ok := s.docs[clave]
+	if !ok {
+		return sri.Autorizacion{}, false
+	}
+	doc.polls++
+
+	auth := sri.Autorizacion{Ambiente: ambiente(clave)}
+	switch {
+	case doc.polls <= doc.scenario.PollsEnProceso:
+		auth.Estado = "EN PROCESO"
+	case len(doc.scenario.NoAutorizado) > 0:
+		auth.Estado = "NO AUTORIZADO"
+		auth.FechaAutorizacion = s.now().Format(time.RFC3339)
+		auth.Comprobante = doc.xml
+		auth.Mensajes.Mensaje = doc.scenario.NoAutorizado
+		doc.rechazado = true
+	default:
+		if doc.autorizado == nil {
+			t := s.now()
+			doc.autorizado = &t
+		}
+		auth.Estado = "AUTORIZADO"
+		auth.NumeroAutorizacion = clave
+		auth.FechaAutorizacion = doc.autorizado.Format(time.RFC3339)
+		auth.Comprobante = doc.xml
+	}
+	return auth, true
+}
+
+func ambiente(clave string) string {
+	if k, err := sri.ParseAccessKey(clave); err == nil && k.Environment == 2 {
+		return "PRODUCCIÓN"
+	}
+	return "PRUEBAS"
+}
+
+// write envuelve la respuesta en el sobre SOAP con los mismos elementos que publica el SRI.
+func (s *Server) write(w http.ResponseWriter, operation, namespace, element string, respuesta any) {
+	var inner bytes.Buffer
+	err := xml.NewEncoder(&inner).EncodeElement(respuesta, xml.StartElement{Name: xml.Name{Local: element}})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error generando respuesta: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=UTF-8")
+	_, _ = fmt.Fprintf(w, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>`+
+		`<ns2:%s xmlns:ns2="%s">%s</ns2:%s></soap:Body></soap:Envelope>`,
+		operation, namespace, inner.String(), operation)
+}
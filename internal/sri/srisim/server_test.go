@@ -0,0 +1,196 @@
+package srisim
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, sim *Server) *sri.SoapClient {
+	srv := httptest.NewServer(sim)
+	t.Cleanup(srv.Close)
+	client := sri.NewSoapClient().WithBaseURL(srv.URL)
+	client.Timeout = 2 * time.Second
+	return client
+}
+
+func factura(secuencial string) (string, []byte) {
+	clave := sri.GenerateAccessKey(time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local), "01", "1790012345001", 1, "001", "001", secuencial, "12345678", 1)
+	doc := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?><factura id="comprobante" version="1.1.0">`+
+		`<infoTributaria><ambiente>1</ambiente><claveAcceso>%s</claveAcceso></infoTributaria></factura>`, clave)
+	return clave, []byte(doc)
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Recibe y autoriza en la primera consulta", func(t *testing.T) {
+		sim := NewServer(Scenario{})
+		client := newClient(t, sim)
+		clave, doc := factura("000000001")
+
+		rec, err := client.EnviarComprobante(ctx, doc, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "RECIBIDA", rec.Estado)
+
+		auth, err := client.AutorizarComprobante(ctx, clave, 1)
+		require.NoError(t, err)
+		require.Len(t, auth.Autorizaciones.Autorizacion, 1)
+		a := auth.Autorizaciones.Autorizacion[0]
+		assert.Equal(t, "AUTORIZADO", a.Estado)
+		assert.Equal(t, clave, a.NumeroAutorizacion)
+		assert.Equal(t, "PRUEBAS", a.Ambiente)
+		assert.Equal(t, string(doc), a.Comprobante)
+		_, err = time.Parse(time.RFC3339, a.FechaAutorizacion)
+		assert.NoError(t, err)
+
+		// El reenvío de una clave autorizada se devuelve como registrada
+		rec, err = client.EnviarComprobante(ctx, doc, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "DEVUELTA", rec.Estado)
+		assert.Equal(t, ErrClaveRegistrada, rec.Comprobantes.Comprobante[0].Mensajes.Mensaje[0].Identificador)
+	})
+
+	t.Run("Devuelve con los códigos de error del escenario", func(t *testing.T) {
+		sim := NewServer(Scenario{})
+		client := newClient(t, sim)
+		clave, doc := factura("000000002")
+		sim.SetScenario(clave, Scenario{Devuelta: []sri.Mensaje{Error(ErrFirmaInvalida)}})
+
+		rec, err := client.EnviarComprobante(ctx, doc, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "DEVUELTA", rec.Estado)
+		require.Len(t, rec.Comprobantes.Comprobante, 1)
+		assert.Equal(t, clave, rec.Comprobantes.Comprobante[0].ClaveAcceso)
+		assert.Equal(t, "39", rec.Comprobantes.Comprobante[0].Mensajes.Mensaje[0].Identificador)
+		assert.Equal(t, "FIRMA INVALIDA", rec.Comprobantes.Comprobante[0].Mensajes.Mensaje[0].Mensaje)
+
+		received, _ := sim.Received(clave)
+		assert.False(t, received)
+	})
+
+	t.Run("Responde EN PROCESO hasta la consulta indicada", func(t *testing.T) {
+		sim := NewServer(Scenario{PollsEnProceso: 2})
+		client := newClient(t, sim)
+		clave, doc := factura("000000003")
+
+		_, err := client.EnviarComprobante(ctx, doc, 1)
+		require.NoError(t, err)
+
+		var estados []string
+		for range 3 {
+			auth, err := client.AutorizarComprobante(ctx, clave, 1)
+			require.NoError(t, err)
+			estados = append(estados, auth.Autorizaciones.Autorizacion[0].Estado)
+		}
+		assert.Equal(t, []string{"EN PROCESO", "EN PROCESO", "AUTORIZADO"}, estados)
+
+		_, polls := sim.Received(clave)
+		assert.Equal(t, 3, polls)
+
+		// Mientras estuvo en proceso, un reenvío se habría devuelto con el código 70
+		clave2, doc2 := factura("000000004")
+		_, err = client.EnviarComprobante(ctx, doc2, 1)
+		require.NoError(t, err)
+		rec, err := client.EnviarComprobante(ctx, doc2, 1)
+		require.NoError(t, err)
+		assert.Equal(t, ErrEnProcesamiento, rec.Comprobantes.Comprobante[0].Mensajes.Mensaje[0].Identificador)
+		assert.Equal(t, clave2, rec.Comprobantes.Comprobante[0].ClaveAcceso)
+	})
+
+	t.Run("No autoriza y permite reenviar la misma clave", func(t *testing.T) {
+		sim := NewServer(Scenario{})
+		client := newClient(t, sim)
+		clave, doc := factura("000000005")
+		sim.SetScenario(clave, Scenario{NoAutorizado: []sri.Mensaje{Error(ErrSecuencialRepetido)}})
+
+		_, err := client.EnviarComprobante(ctx, doc, 1)
+		require.NoError(t, err)
+		auth, err := client.AutorizarComprobante(ctx, clave, 1)
+		require.NoError(t, err)
+		a := auth.Autorizaciones.Autorizacion[0]
+		assert.Equal(t, "NO AUTORIZADO", a.Estado)
+		assert.Equal(t, "SECUENCIAL REGISTRADO", a.Mensajes.Mensaje[0].Mensaje)
+		assert.Equal(t, clave, a.ClaveAcceso())
+		assert.Empty(t, a.NumeroAutorizacion)
+
+		sim.SetScenario(clave, Scenario{})
+		rec, err := client.EnviarComprobante(ctx, doc, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "RECIBIDA", rec.Estado)
+	})
+
+	t.Run("Una clave desconocida no tiene comprobantes", func(t *testing.T) {
+		client := newClient(t, NewServer(Scenario{}))
+		clave, _ := factura("000000006")
+
+		auth, err := client.AutorizarComprobante(ctx, clave, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "0", auth.NumeroComprobantes)
+		assert.Empty(t, auth.Autorizaciones.Autorizacion)
+	})
+
+	t.Run("Una demora mayor al timeout produce error de conexión", func(t *testing.T) {
+		sim := NewServer(Scenario{Delay: 500 * time.Millisecond})
+		client := newClient(t, sim)
+		client.Timeout = 100 * time.Millisecond
+		client.MaxRetries = 0
+		_, doc := factura("000000007")
+
+		_, err := client.EnviarComprobante(ctx, doc, 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error de conexión con el SRI")
+	})
+
+	t.Run("Recibe un lote y autoriza sus comprobantes con la clave del lote", func(t *testing.T) {
+		sim := NewServer(Scenario{PollsEnProceso: 1})
+		client := newClient(t, sim)
+		clave1, doc1 := factura("000000008")
+		clave2, doc2 := factura("000000009")
+		clave3, doc3 := factura("000000010")
+		sim.SetScenario(clave2, Scenario{Devuelta: []sri.Mensaje{Error(ErrFirmaInvalida)}})
+		sim.SetScenario(clave3, Scenario{NoAutorizado: []sri.Mensaje{Error(ErrSecuencialRepetido)}})
+
+		key, err := sri.ParseAccessKey(clave1)
+		require.NoError(t, err)
+		claveLote := sri.GenerateLoteAccessKey(time.Now(), key, "000000099", "87654321")
+		lote, err := sri.MarshalLote(claveLote, key.RUC, []string{string(doc1), string(doc2), string(doc3)})
+		require.NoError(t, err)
+
+		rec, err := client.EnviarComprobante(ctx, lote, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "DEVUELTA", rec.Estado)
+		require.Len(t, rec.Comprobantes.Comprobante, 1)
+		assert.Equal(t, clave2, rec.Comprobantes.Comprobante[0].ClaveAcceso)
+
+		auth, err := client.AutorizarLote(ctx, claveLote, 1)
+		require.NoError(t, err)
+		assert.Equal(t, claveLote, auth.ClaveAccesoLoteConsultada)
+		require.Len(t, auth.Autorizaciones.Autorizacion, 2)
+		assert.Equal(t, "EN PROCESO", auth.Autorizaciones.Autorizacion[0].Estado)
+
+		auth, err = client.AutorizarLote(ctx, claveLote, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "2", auth.NumeroComprobantesLote)
+		estados := map[string]string{}
+		for _, a := range auth.Autorizaciones.Autorizacion {
+			estados[a.ClaveAcceso()] = a.Estado
+		}
+		assert.Equal(t, map[string]string{clave1: "AUTORIZADO", clave3: "NO AUTORIZADO"}, estados)
+	})
+
+	t.Run("Devuelve un comprobante sin clave de acceso", func(t *testing.T) {
+		client := newClient(t, NewServer(Scenario{}))
+
+		rec, err := client.EnviarComprobante(ctx, []byte("<factura/>"), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "DEVUELTA", rec.Estado)
+		assert.Equal(t, ErrEstructuraXML, rec.Comprobantes.Comprobante[0].Mensajes.Mensaje[0].Identificador)
+	})
+}
@@ -0,0 +1,197 @@
+package sri
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/nelsonmarro/go_ec_sri_invoice_signer/pkg/signer"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const (
+	xmldsigNS = "http://www.w3.org/2000/09/xmldsig#"
+	xadesNS   = "http://uri.etsi.org/01903/v1.3.2#"
+)
+
+// SignXAdES firma un comprobante con una firma XAdES-BES envuelta, con la estructura que exige la
+// ficha técnica del SRI: tres referencias (propiedades firmadas, certificado y el nodo raíz con
+// id="comprobante"), canonicalización C14N 1.0 y RSA. Sirve para cualquier tipo de comprobante,
+// porque solo depende del atributo id del nodo raíz.
+func SignXAdES(xmlBytes, p12Bytes []byte, password string, algo signer.HashAlgorithm) ([]byte, error) {
+	cert, key, err := signingKey(p12Bytes, password)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(xmlBytes); err != nil {
+		return nil, fmt.Errorf("error al leer el XML del comprobante: %w", err)
+	}
+	root := doc.Root()
+	if root == nil || root.SelectAttrValue("id", "") != "comprobante" {
+		return nil, errors.New(`el nodo raíz del comprobante debe tener id="comprobante"`)
+	}
+
+	hash, digestMethod, signatureMethod := crypto.SHA1, "http://www.w3.org/2000/09/xmldsig#sha1", dsig.RSASHA1SignatureMethod
+	if algo == signer.SHA256 {
+		hash, digestMethod, signatureMethod = crypto.SHA256, "http://www.w3.org/2001/04/xmlenc#sha256", dsig.RSASHA256SignatureMethod
+	}
+	c14n := dsig.MakeC14N10RecCanonicalizer()
+	digest := func(el *etree.Element) (string, error) {
+		canonical, err := c14n.Canonicalize(el)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(hashBytes(hash, canonical)), nil
+	}
+
+	// La transformación enveloped-signature excluye la firma: el digest del comprobante es el del
+	// nodo raíz antes de agregarla.
+	documentDigest, err := digest(root)
+	if err != nil {
+		return nil, fmt.Errorf("error al canonicalizar el comprobante: %w", err)
+	}
+
+	n := signatureNumber()
+	signatureID := "Signature" + n
+	signedPropertiesID := signatureID + "-SignedProperties"
+	certificateID := "Certificate" + n
+	referenceID := "Reference-ID-" + n
+
+	signature := root.CreateElement("ds:Signature")
+	signature.CreateAttr("xmlns:ds", xmldsigNS)
+	signature.CreateAttr("xmlns:etsi", xadesNS)
+	signature.CreateAttr("Id", signatureID)
+
+	signedInfo := signature.CreateElement("ds:SignedInfo")
+	signedInfo.CreateAttr("Id", "Signature-SignedInfo"+n)
+	signedInfo.CreateElement("ds:CanonicalizationMethod").CreateAttr("Algorithm", string(dsig.CanonicalXML10RecAlgorithmId))
+	signedInfo.CreateElement("ds:SignatureMethod").CreateAttr("Algorithm", signatureMethod)
+
+	propertiesRef := addReference(signedInfo, "#"+signedPropertiesID, digestMethod)
+	propertiesRef.CreateAttr("Id", "SignedPropertiesID"+n)
+	propertiesRef.CreateAttr("Type", "http://uri.etsi.org/01903#SignedProperties")
+	certificateRef := addReference(signedInfo, "#"+certificateID, digestMethod)
+	documentRef := addReference(signedInfo, "#comprobante", digestMethod, string(dsig.EnvelopedSignatureAltorithmId))
+	documentRef.CreateAttr("Id", referenceID)
+	documentRef.SelectElement("ds:DigestValue").SetText(documentDigest)
+
+	signatureValue := signature.CreateElement("ds:SignatureValue")
+	signatureValue.CreateAttr("Id", "SignatureValue"+n)
+
+	keyInfo := signature.CreateElement("ds:KeyInfo")
+	keyInfo.CreateAttr("Id", certificateID)
+	keyInfo.CreateElement("ds:X509Data").CreateElement("ds:X509Certificate").SetText(base64.StdEncoding.EncodeToString(cert.Raw))
+	rsaKeyValue := keyInfo.CreateElement("ds:KeyValue").CreateElement("ds:RSAKeyValue")
+	rsaKeyValue.CreateElement("ds:Modulus").SetText(base64.StdEncoding.EncodeToString(key.PublicKey.N.Bytes()))
+	rsaKeyValue.CreateElement("ds:Exponent").SetText(base64.StdEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()))
+
+	object := signature.CreateElement("ds:Object")
+	object.CreateAttr("Id", signatureID+"-Object"+n)
+	qualifying := object.CreateElement("etsi:QualifyingProperties")
+	qualifying.CreateAttr("Target", "#"+signatureID)
+	signedProperties := qualifying.CreateElement("etsi:SignedProperties")
+	signedProperties.CreateAttr("Id", signedPropertiesID)
+
+	signatureProperties := signedProperties.CreateElement("etsi:SignedSignatureProperties")
+	signatureProperties.CreateElement("etsi:SigningTime").SetText(time.Now().Format("2006-01-02T15:04:05-07:00"))
+	certRef := signatureProperties.CreateElement("etsi:SigningCertificate").CreateElement("etsi:Cert")
+	certDigest := certRef.CreateElement("etsi:CertDigest")
+	certDigest.CreateElement("ds:DigestMethod").CreateAttr("Algorithm", digestMethod)
+	certDigest.CreateElement("ds:DigestValue").SetText(base64.StdEncoding.EncodeToString(hashBytes(hash, cert.Raw)))
+	issuerSerial := certRef.CreateElement("etsi:IssuerSerial")
+	issuerSerial.CreateElement("ds:X509IssuerName").SetText(cert.Issuer.String())
+	issuerSerial.CreateElement("ds:X509SerialNumber").SetText(cert.SerialNumber.String())
+
+	dataObject := signedProperties.CreateElement("etsi:SignedDataObjectProperties").CreateElement("etsi:DataObjectFormat")
+	dataObject.CreateAttr("ObjectReference", "#"+referenceID)
+	dataObject.CreateElement("etsi:Description").SetText("contenido comprobante")
+	dataObject.CreateElement("etsi:MimeType").SetText("text/xml")
+
+	// Las propiedades firmadas y el certificado se canonicalizan ya dentro de la firma, con los
+	// espacios de nombres que heredan de ella.
+	propertiesDigest, err := digest(signedProperties)
+	if err != nil {
+		return nil, fmt.Errorf("error al canonicalizar las propiedades firmadas: %w", err)
+	}
+	propertiesRef.SelectElement("ds:DigestValue").SetText(propertiesDigest)
+	keyInfoDigest, err := digest(keyInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error al canonicalizar el certificado: %w", err)
+	}
+	certificateRef.SelectElement("ds:DigestValue").SetText(keyInfoDigest)
+
+	canonicalSignedInfo, err := c14n.Canonicalize(signedInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error al canonicalizar SignedInfo: %w", err)
+	}
+	signed, err := rsa.SignPKCS1v15(rand.Reader, key, hash, hashBytes(hash, canonicalSignedInfo))
+	if err != nil {
+		return nil, fmt.Errorf("error al firmar el comprobante: %w", err)
+	}
+	signatureValue.SetText(base64.StdEncoding.EncodeToString(signed))
+
+	return doc.WriteToBytes()
+}
+
+// addReference agrega a SignedInfo una referencia con sus transformaciones y el DigestValue vacío.
+func addReference(signedInfo *etree.Element, uri, digestMethod string, transforms ...string) *etree.Element {
+	ref := signedInfo.CreateElement("ds:Reference")
+	ref.CreateAttr("URI", uri)
+	if len(transforms) > 0 {
+		list := ref.CreateElement("ds:Transforms")
+		for _, algorithm := range transforms {
+			list.CreateElement("ds:Transform").CreateAttr("Algorithm", algorithm)
+		}
+	}
+	ref.CreateElement("ds:DigestMethod").CreateAttr("Algorithm", digestMethod)
+	ref.CreateElement("ds:DigestValue")
+	return ref
+}
+
+func hashBytes(hash crypto.Hash, data []byte) []byte {
+	if hash == crypto.SHA256 {
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+	sum := sha1.Sum(data)
+	return sum[:]
+}
+
+// signatureNumber genera el sufijo numérico de los Id de la firma.
+func signatureNumber() string {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1000000))
+	return fmt.Sprintf("%06d", n.Int64())
+}
+
+// signingKey abre el .p12 y devuelve el certificado de firma con su clave privada.
+func signingKey(p12Bytes []byte, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	blocks, err := p12Blocks(p12Bytes, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := signingCertificate(blocks)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, b := range blocks {
+		if b.Type != "PRIVATE KEY" {
+			continue
+		}
+		key, err := x509.ParsePKCS1PrivateKey(b.Bytes)
+		if err == nil && key.PublicKey.Equal(cert.PublicKey) {
+			return cert, key, nil
+		}
+	}
+	return nil, nil, errors.New("el archivo .p12 no contiene la clave privada RSA del certificado de firma")
+}
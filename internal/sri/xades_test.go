@@ -0,0 +1,119 @@
+package sri
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignXAdES(t *testing.T) {
+	p12Bytes, err := os.ReadFile("testdata/firma_prueba.p12")
+	require.NoError(t, err)
+	cert, _, err := signingKey(p12Bytes, "Prueba123")
+	require.NoError(t, err)
+
+	info := InfoTributaria{
+		Ambiente: "1", TipoEmision: "1", RazonSocial: "Empresa de Prueba S.A.", Ruc: "1790012345001",
+		ClaveAcceso: strings.Repeat("1", 49), Estab: "001", PtoEmi: "001", Secuencial: "000000001", DirMatriz: "Av. Amazonas y Colón",
+	}
+	retencion := func() ([]byte, error) {
+		cr := &ComprobanteRetencion{InfoTributaria: info}
+		cr.InfoTributaria.CodDoc = "07"
+		cr.InfoCompRetencion.RazonSocialSujetoRetenido = "Proveedor & Cía"
+		cr.DocsSustento.DocSustento = []DocSustento{{CodDocSustento: "01"}}
+		return MarshalComprobanteRetencion(cr)
+	}
+	notaDebito := func() ([]byte, error) {
+		nd := &NotaDebito{InfoTributaria: info}
+		nd.InfoTributaria.CodDoc = "05"
+		return MarshalNotaDebito(nd)
+	}
+	guia := func() ([]byte, error) {
+		gr := &GuiaRemision{InfoTributaria: info}
+		gr.InfoTributaria.CodDoc = "06"
+		return MarshalGuiaRemision(gr)
+	}
+	liquidacion := func() ([]byte, error) {
+		lc := &LiquidacionCompra{InfoTributaria: info}
+		lc.InfoTributaria.CodDoc = "03"
+		return MarshalLiquidacionCompra(lc)
+	}
+
+	for name, build := range map[string]func() ([]byte, error){
+		"comprobanteRetencion": retencion,
+		"notaDebito":           notaDebito,
+		"guiaRemision":         guia,
+		"liquidacionCompra":    liquidacion,
+	} {
+		t.Run("Signs and verifies "+name, func(t *testing.T) {
+			xmlBytes, err := build()
+			require.NoError(t, err)
+
+			signed, err := SignXAdES(xmlBytes, p12Bytes, "Prueba123", SHA1)
+			require.NoError(t, err)
+
+			doc := etree.NewDocument()
+			require.NoError(t, doc.ReadFromBytes(signed))
+			root := doc.Root()
+			assert.Equal(t, name, root.Tag)
+
+			// SignatureValue sobre SignedInfo y digest del comprobante con la transformación enveloped
+			ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{cert}})
+			ctx.IdAttribute = "id"
+			ctx.Clock = dsig.NewFakeClockAt(cert.NotBefore.Add(time.Hour))
+			validated, err := ctx.Validate(root)
+			require.NoError(t, err)
+			assert.Equal(t, "000000001", validated.FindElement("./infoTributaria/secuencial").Text())
+
+			// Las otras dos referencias de XAdES-BES: propiedades firmadas y certificado
+			c14n := dsig.MakeC14N10RecCanonicalizer()
+			refs := root.FindElements("./ds:Signature/ds:SignedInfo/ds:Reference")
+			require.Len(t, refs, 3)
+			for _, ref := range refs[:2] {
+				id := strings.TrimPrefix(ref.SelectAttrValue("URI", ""), "#")
+				target := root.FindElement("//[@Id='" + id + "']")
+				require.NotNil(t, target, id)
+				canonical, err := c14n.Canonicalize(target)
+				require.NoError(t, err)
+				assert.Equal(t, ref.FindElement("./ds:DigestValue").Text(), base64.StdEncoding.EncodeToString(hashBytes(crypto.SHA1, canonical)), id)
+			}
+			assert.Equal(t, "http://uri.etsi.org/01903#SignedProperties", refs[0].SelectAttrValue("Type", ""))
+			assert.Equal(t, "#comprobante", refs[2].SelectAttrValue("URI", ""))
+		})
+	}
+
+	t.Run("Detects tampering", func(t *testing.T) {
+		xmlBytes, err := retencion()
+		require.NoError(t, err)
+		signed, err := SignXAdES(xmlBytes, p12Bytes, "Prueba123", SHA1)
+		require.NoError(t, err)
+
+		tampered := strings.Replace(string(signed), "000000001", "000000002", 1)
+		doc := etree.NewDocument()
+		require.NoError(t, doc.ReadFromString(tampered))
+		ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{cert}})
+		ctx.IdAttribute = "id"
+		ctx.Clock = dsig.NewFakeClockAt(cert.NotBefore.Add(time.Hour))
+		_, err = ctx.Validate(doc.Root())
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects a wrong password and a root without id", func(t *testing.T) {
+		xmlBytes, err := retencion()
+		require.NoError(t, err)
+		_, err = SignXAdES(xmlBytes, p12Bytes, "otra", SHA1)
+		assert.ErrorContains(t, err, "contraseña")
+
+		_, err = SignXAdES([]byte(`<comprobanteRetencion version="2.0.0"/>`), p12Bytes, "Prueba123", SHA1)
+		assert.ErrorContains(t, err, `id="comprobante"`)
+	})
+}
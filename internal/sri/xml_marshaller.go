@@ -51,4 +51,110 @@ func MarshalNotaCredito(nc *NotaCredito) ([]byte, error) {
 	buffer.Write(xmlBytes)
 
 	return buffer.Bytes(), nil
-}
\ No newline at end of file
+}
+
+// MarshalComprobanteRetencion serializa el Comprobante de Retención (esquema 2.0.0).
+func MarshalComprobanteRetencion(cr *ComprobanteRetencion) ([]byte, error) {
+	if cr.ID != "comprobante" {
+		cr.ID = "comprobante"
+	}
+	if cr.Version == "" {
+		cr.Version = "2.0.0" // La versión 2.0.0 es la que admite docsSustento
+	}
+
+	xmlBytes, err := xml.Marshal(cr)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar comprobante de retención: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	buffer.Write(xmlBytes)
+
+	return buffer.Bytes(), nil
+}
+
+// MarshalNotaDebito serializa la Nota de Débito.
+func MarshalNotaDebito(nd *NotaDebito) ([]byte, error) {
+	if nd.ID != "comprobante" {
+		nd.ID = "comprobante"
+	}
+	if nd.Version == "" {
+		nd.Version = "1.0.0"
+	}
+
+	xmlBytes, err := xml.Marshal(nd)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar nota de débito: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	buffer.Write(xmlBytes)
+
+	return buffer.Bytes(), nil
+}
+
+// MarshalGuiaRemision serializa la Guía de Remisión.
+func MarshalGuiaRemision(gr *GuiaRemision) ([]byte, error) {
+	if gr.ID != "comprobante" {
+		gr.ID = "comprobante"
+	}
+	if gr.Version == "" {
+		gr.Version = "1.1.0"
+	}
+
+	xmlBytes, err := xml.Marshal(gr)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar guía de remisión: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	buffer.Write(xmlBytes)
+
+	return buffer.Bytes(), nil
+}
+
+// MarshalLiquidacionCompra serializa la Liquidación de Compra.
+func MarshalLiquidacionCompra(lc *LiquidacionCompra) ([]byte, error) {
+	if lc.ID != "comprobante" {
+		lc.ID = "comprobante"
+	}
+	if lc.Version == "" {
+		lc.Version = "1.1.0"
+	}
+
+	xmlBytes, err := xml.Marshal(lc)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar liquidación de compra: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	buffer.Write(xmlBytes)
+
+	return buffer.Bytes(), nil
+}
+
+// MarshalATS serializa el Anexo Transaccional Simplificado. Como no se firma se usa MarshalIndent
+// para que el contador pueda revisarlo antes de cargarlo en el DIMM.
+func MarshalATS(ats *ATS) ([]byte, error) {
+	if ats.TipoIDInformante == "" {
+		ats.TipoIDInformante = "R"
+	}
+	if ats.CodigoOperativo == "" {
+		ats.CodigoOperativo = "IVA"
+	}
+
+	xmlBytes, err := xml.MarshalIndent(ats, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar el ATS: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buffer.Write(xmlBytes)
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,887 @@
+package sri
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Esquemas XSD del SRI embebidos en el binario. La validación es offline: se hace antes de firmar
+// para detectar errores de estructura sin consumir secuenciales ni esperar un DEVUELTA del SRI.
+//
+//go:embed xsd/*.xsd
+var xsdFiles embed.FS
+
+const (
+	facturaXSD     = "xsd/factura_V2.1.0.xsd"
+	notaCreditoXSD = "xsd/NotaCredito_V1.1.0.xsd"
+	atsXSD         = "xsd/ats.xsd"
+
+	xmldsigNamespace = "http://www.w3.org/2000/09/xmldsig#"
+)
+
+// ValidationError describe un incumplimiento del esquema en un campo concreto del comprobante.
+// Path sigue la estructura del XML, ej. "factura/detalles/detalle[2]/cantidad".
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors agrupa todos los errores encontrados en un comprobante.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString("el comprobante no cumple el esquema del SRI:")
+	for _, ve := range e {
+		sb.WriteString("\n- ")
+		sb.WriteString(ve.Error())
+	}
+	return sb.String()
+}
+
+// ValidateFactura valida el XML generado por MarshalFactura contra el XSD de factura v2.1.0.
+// Devuelve ValidationErrors si el documento no cumple el esquema.
+func ValidateFactura(xmlBytes []byte) error {
+	return validateAgainst(facturaXSD, xmlBytes)
+}
+
+// ValidateNotaCredito valida el XML generado por MarshalNotaCredito contra el XSD de nota de crédito v1.1.0.
+func ValidateNotaCredito(xmlBytes []byte) error {
+	return validateAgainst(notaCreditoXSD, xmlBytes)
+}
+
+// ValidateATS valida el anexo generado por MarshalATS antes de entregarlo para la carga en el DIMM.
+func ValidateATS(xmlBytes []byte) error {
+	return validateAgainst(atsXSD, xmlBytes)
+}
+
+var (
+	schemaMu    sync.Mutex
+	schemaCache = map[string]*xsdSchema{}
+)
+
+func validateAgainst(file string, xmlBytes []byte) error {
+	schema, err := loadSchema(file)
+	if err != nil {
+		return err
+	}
+
+	doc, err := parseXMLNode(xmlBytes)
+	if err != nil {
+		return fmt.Errorf("el XML del comprobante no está bien formado: %w", err)
+	}
+
+	v := &xsdValidator{}
+	root, ok := schema.elements[doc.XMLName.Local]
+	if !ok {
+		v.add(doc.XMLName.Local, "elemento raíz no esperado")
+	} else {
+		v.validateElement(root, doc, doc.XMLName.Local)
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+func loadSchema(file string) (*xsdSchema, error) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	if s, ok := schemaCache[file]; ok {
+		return s, nil
+	}
+	data, err := xsdFiles.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("esquema %s no disponible: %w", file, err)
+	}
+	s, err := compileSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("error al compilar el esquema %s: %w", file, err)
+	}
+	schemaCache[file] = s
+	return s, nil
+}
+
+// --- Árbol genérico de XML (se usa tanto para el XSD como para el comprobante) ---
+
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []xmlNode  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+func (n *xmlNode) attr(name string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name && a.Name.Space == "" {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func parseXMLNode(data []byte) (*xmlNode, error) {
+	var root xmlNode
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+	// Solo se admite un elemento raíz
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			return nil, errors.New("hay más de un elemento raíz")
+		}
+	}
+	return &root, nil
+}
+
+// --- Modelo compilado del esquema (subconjunto de XSD usado por las fichas del SRI) ---
+
+type xsdSchema struct {
+	elements map[string]*xsdElement
+	types    map[string]*xsdType
+}
+
+type xsdElement struct {
+	name     string
+	typ      *xsdType
+	opaque   bool // ds:Signature: se acepta sin validar su contenido
+	min, max int  // max < 0 = unbounded
+}
+
+type particleKind int
+
+const (
+	particleElement particleKind = iota
+	particleSequence
+	particleChoice
+)
+
+type xsdParticle struct {
+	kind     particleKind
+	element  *xsdElement
+	children []*xsdParticle
+	min, max int
+}
+
+type xsdAttribute struct {
+	name     string
+	typ      *xsdType
+	required bool
+	fixed    *string
+}
+
+type xsdType struct {
+	name    string
+	complex bool
+
+	// Tipos simples: base (otro tipo simple o un tipo nativo) más las facetas de la restricción
+	base           *xsdType
+	builtin        string
+	patterns       []*regexp.Regexp
+	enumerations   []string
+	minLength      *int
+	maxLength      *int
+	length         *int
+	totalDigits    *int
+	fractionDigits *int
+	minInclusive   *big.Rat
+	maxInclusive   *big.Rat
+
+	// Tipos complejos
+	content     *xsdParticle
+	attributes  []*xsdAttribute
+	textType    *xsdType // simpleContent
+	compileNode *xmlNode
+}
+
+type schemaCompiler struct {
+	schema *xsdSchema
+}
+
+func compileSchema(data []byte) (*xsdSchema, error) {
+	root, err := parseXMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+	c := &schemaCompiler{schema: &xsdSchema{elements: map[string]*xsdElement{}, types: map[string]*xsdType{}}}
+
+	// Primera pasada: registrar los tipos con nombre para resolver referencias en cualquier orden
+	for i := range root.Children {
+		child := &root.Children[i]
+		name, _ := child.attr("name")
+		switch child.XMLName.Local {
+		case "simpleType":
+			c.schema.types[name] = &xsdType{name: name, compileNode: child}
+		case "complexType":
+			c.schema.types[name] = &xsdType{name: name, complex: true, compileNode: child}
+		}
+	}
+	for _, t := range c.schema.types {
+		if err := c.fillType(t); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range root.Children {
+		child := &root.Children[i]
+		if child.XMLName.Local != "element" {
+			continue
+		}
+		el, err := c.compileElement(child)
+		if err != nil {
+			return nil, err
+		}
+		c.schema.elements[el.name] = el
+	}
+	return c.schema, nil
+}
+
+func (c *schemaCompiler) fillType(t *xsdType) error {
+	node := t.compileNode
+	if node == nil {
+		return nil
+	}
+	t.compileNode = nil
+	if t.complex {
+		return c.fillComplexType(t, node)
+	}
+	return c.fillSimpleType(t, node)
+}
+
+// resolveType devuelve un tipo con nombre del esquema o un tipo nativo (xsd:string, xsd:decimal...).
+func (c *schemaCompiler) resolveType(qname string) (*xsdType, error) {
+	local := qname
+	if i := strings.IndexByte(qname, ':'); i >= 0 {
+		prefix := qname[:i]
+		local = qname[i+1:]
+		if prefix == "xsd" || prefix == "xs" {
+			return builtinType(local)
+		}
+	}
+	t, ok := c.schema.types[local]
+	if !ok {
+		return nil, fmt.Errorf("tipo %q no definido", qname)
+	}
+	if err := c.fillType(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+var builtinTypes = map[string]bool{
+	"string": true, "normalizedString": true, "token": true, "NMTOKEN": true,
+	"decimal": true, "integer": true, "date": true,
+}
+
+func builtinType(name string) (*xsdType, error) {
+	if !builtinTypes[name] {
+		return nil, fmt.Errorf("tipo nativo xsd:%s no soportado", name)
+	}
+	return &xsdType{name: name, builtin: name}, nil
+}
+
+func (c *schemaCompiler) fillSimpleType(t *xsdType, node *xmlNode) error {
+	for i := range node.Children {
+		r := &node.Children[i]
+		if r.XMLName.Local != "restriction" {
+			continue
+		}
+		baseName, _ := r.attr("base")
+		base, err := c.resolveType(baseName)
+		if err != nil {
+			return err
+		}
+		t.base = base
+
+		for j := range r.Children {
+			facet := &r.Children[j]
+			value, _ := facet.attr("value")
+			switch facet.XMLName.Local {
+			case "pattern":
+				re, err := regexp.Compile("^(?:" + value + ")$")
+				if err != nil {
+					return fmt.Errorf("patrón %q inválido en %s: %w", value, t.name, err)
+				}
+				t.patterns = append(t.patterns, re)
+			case "enumeration":
+				t.enumerations = append(t.enumerations, value)
+			case "minLength":
+				t.minLength, err = intFacet(value)
+			case "maxLength":
+				t.maxLength, err = intFacet(value)
+			case "length":
+				t.length, err = intFacet(value)
+			case "totalDigits":
+				t.totalDigits, err = intFacet(value)
+			case "fractionDigits":
+				t.fractionDigits, err = intFacet(value)
+			case "minInclusive":
+				t.minInclusive, err = ratFacet(value)
+			case "maxInclusive":
+				t.maxInclusive, err = ratFacet(value)
+			}
+			if err != nil {
+				return fmt.Errorf("faceta %s inválida en %s: %w", facet.XMLName.Local, t.name, err)
+			}
+		}
+	}
+	if t.base == nil {
+		return fmt.Errorf("el tipo simple %q no tiene restricción", t.name)
+	}
+	return nil
+}
+
+func intFacet(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func ratFacet(value string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("valor %q no numérico", value)
+	}
+	return r, nil
+}
+
+func (c *schemaCompiler) fillComplexType(t *xsdType, node *xmlNode) error {
+	for i := range node.Children {
+		child := &node.Children[i]
+		switch child.XMLName.Local {
+		case "sequence", "choice":
+			p, err := c.compileGroup(child)
+			if err != nil {
+				return err
+			}
+			t.content = p
+		case "attribute":
+			a, err := c.compileAttribute(child)
+			if err != nil {
+				return err
+			}
+			t.attributes = append(t.attributes, a)
+		case "simpleContent":
+			for j := range child.Children {
+				ext := &child.Children[j]
+				if ext.XMLName.Local != "extension" {
+					continue
+				}
+				baseName, _ := ext.attr("base")
+				base, err := c.resolveType(baseName)
+				if err != nil {
+					return err
+				}
+				t.textType = base
+				for k := range ext.Children {
+					if ext.Children[k].XMLName.Local != "attribute" {
+						continue
+					}
+					a, err := c.compileAttribute(&ext.Children[k])
+					if err != nil {
+						return err
+					}
+					t.attributes = append(t.attributes, a)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *schemaCompiler) compileGroup(node *xmlNode) (*xsdParticle, error) {
+	p := &xsdParticle{kind: particleSequence}
+	if node.XMLName.Local == "choice" {
+		p.kind = particleChoice
+	}
+	var err error
+	if p.min, p.max, err = occurs(node); err != nil {
+		return nil, err
+	}
+	for i := range node.Children {
+		child := &node.Children[i]
+		switch child.XMLName.Local {
+		case "element":
+			el, err := c.compileElement(child)
+			if err != nil {
+				return nil, err
+			}
+			p.children = append(p.children, &xsdParticle{kind: particleElement, element: el, min: el.min, max: el.max})
+		case "sequence", "choice":
+			sub, err := c.compileGroup(child)
+			if err != nil {
+				return nil, err
+			}
+			p.children = append(p.children, sub)
+		}
+	}
+	return p, nil
+}
+
+func (c *schemaCompiler) compileElement(node *xmlNode) (*xsdElement, error) {
+	el := &xsdElement{}
+	var err error
+	if el.min, el.max, err = occurs(node); err != nil {
+		return nil, err
+	}
+
+	// La firma (ds:Signature) se valida al firmar, aquí solo se reconoce su presencia
+	if ref, ok := node.attr("ref"); ok {
+		if _, local, _ := strings.Cut(ref, ":"); local == "Signature" {
+			el.name = "Signature"
+			el.opaque = true
+			return el, nil
+		}
+		return nil, fmt.Errorf("referencia %q no soportada", ref)
+	}
+
+	el.name, _ = node.attr("name")
+	if typeName, ok := node.attr("type"); ok {
+		if el.typ, err = c.resolveType(typeName); err != nil {
+			return nil, err
+		}
+		return el, nil
+	}
+	for i := range node.Children {
+		child := &node.Children[i]
+		switch child.XMLName.Local {
+		case "complexType":
+			el.typ = &xsdType{name: el.name, complex: true}
+			err = c.fillComplexType(el.typ, child)
+		case "simpleType":
+			el.typ = &xsdType{name: el.name}
+			err = c.fillSimpleType(el.typ, child)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if el.typ == nil {
+		el.typ = &xsdType{name: "string", builtin: "string"}
+	}
+	return el, nil
+}
+
+func (c *schemaCompiler) compileAttribute(node *xmlNode) (*xsdAttribute, error) {
+	a := &xsdAttribute{}
+	a.name, _ = node.attr("name")
+	use, _ := node.attr("use")
+	a.required = use == "required"
+	if fixed, ok := node.attr("fixed"); ok {
+		a.fixed = &fixed
+	}
+
+	var err error
+	if typeName, ok := node.attr("type"); ok {
+		a.typ, err = c.resolveType(typeName)
+		return a, err
+	}
+	for i := range node.Children {
+		if node.Children[i].XMLName.Local == "simpleType" {
+			a.typ = &xsdType{name: a.name}
+			if err := c.fillSimpleType(a.typ, &node.Children[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if a.typ == nil {
+		a.typ = &xsdType{name: "string", builtin: "string"}
+	}
+	return a, nil
+}
+
+func occurs(node *xmlNode) (int, int, error) {
+	minOcc, maxOcc := 1, 1
+	if v, ok := node.attr("minOccurs"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("minOccurs %q inválido", v)
+		}
+		minOcc = n
+	}
+	if v, ok := node.attr("maxOccurs"); ok {
+		if v == "unbounded" {
+			maxOcc = -1
+		} else {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return 0, 0, fmt.Errorf("maxOccurs %q inválido", v)
+			}
+			maxOcc = n
+		}
+	}
+	return minOcc, maxOcc, nil
+}
+
+// --- Validación de instancias ---
+
+type xsdValidator struct {
+	errs ValidationErrors
+}
+
+func (v *xsdValidator) add(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *xsdValidator) validateElement(el *xsdElement, node *xmlNode, path string) {
+	if el.opaque {
+		return
+	}
+	t := el.typ
+	if !t.complex {
+		if len(node.Children) > 0 {
+			v.add(path, "no admite elementos hijos")
+			return
+		}
+		v.validateSimple(t, node.Text, path)
+		return
+	}
+
+	v.validateAttributes(t, node, path)
+
+	if t.textType != nil {
+		if len(node.Children) > 0 {
+			v.add(path, "no admite elementos hijos")
+			return
+		}
+		v.validateSimple(t.textType, node.Text, path)
+		return
+	}
+
+	if strings.TrimSpace(node.Text) != "" {
+		v.add(path, "no admite texto, solo elementos")
+	}
+
+	// Los elementos que el esquema no declara se reportan aparte para que no arrastren a sus hermanos
+	declared := map[string]bool{}
+	if t.content != nil {
+		collectNames(t.content, declared)
+	}
+	var children []xmlNode
+	for _, child := range node.Children {
+		if !declared[child.XMLName.Local] {
+			v.add(path+"/"+child.XMLName.Local, "elemento no permitido")
+			continue
+		}
+		children = append(children, child)
+	}
+
+	idx := 0
+	if t.content != nil {
+		idx = v.matchParticle(t.content, children, 0, path)
+	}
+	for ; idx < len(children); idx++ {
+		v.add(path+"/"+children[idx].XMLName.Local, "elemento fuera de orden o repetido")
+	}
+}
+
+func collectNames(p *xsdParticle, names map[string]bool) {
+	if p.kind == particleElement {
+		names[p.element.name] = true
+		return
+	}
+	for _, child := range p.children {
+		collectNames(child, names)
+	}
+}
+
+func (v *xsdValidator) validateAttributes(t *xsdType, node *xmlNode, path string) {
+	declared := map[string]bool{}
+	for _, a := range t.attributes {
+		declared[a.name] = true
+		attrPath := path + "/@" + a.name
+		value, ok := node.attr(a.name)
+		if !ok {
+			if a.required {
+				v.add(attrPath, "falta el atributo obligatorio")
+			}
+			continue
+		}
+		if a.fixed != nil && value != *a.fixed {
+			v.add(attrPath, "debe ser %q", *a.fixed)
+			continue
+		}
+		v.validateSimple(a.typ, value, attrPath)
+	}
+	for _, a := range node.Attrs {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		if a.Name.Space != "" || !declared[a.Name.Local] {
+			v.add(path+"/@"+a.Name.Local, "atributo no permitido")
+		}
+	}
+}
+
+// matchParticle consume los hijos de forma voraz desde idx y devuelve la posición del primer hijo no consumido.
+func (v *xsdValidator) matchParticle(p *xsdParticle, children []xmlNode, idx int, path string) int {
+	switch p.kind {
+	case particleElement:
+		return v.matchElement(p, children, idx, path)
+	case particleChoice:
+		count := 0
+		for p.max < 0 || count < p.max {
+			alt := choiceAlternative(p, children, idx)
+			if alt == nil {
+				break
+			}
+			idx = v.matchParticle(alt, children, idx, path)
+			count++
+		}
+		if count < p.min {
+			v.add(path, "falta uno de los elementos: %s", strings.Join(firstNames(p), ", "))
+		}
+		return idx
+	default:
+		// Una secuencia opcional o repetida solo se recorre si el siguiente hijo puede iniciarla;
+		// mientras no se alcance minOccurs se recorre igual para reportar lo que falta.
+		for count := 0; p.max < 0 || count < p.max; count++ {
+			if (idx >= len(children) || !startsWith(p, children[idx])) && count >= p.min {
+				break
+			}
+			for _, child := range p.children {
+				idx = v.matchParticle(child, children, idx, path)
+			}
+		}
+		return idx
+	}
+}
+
+func (v *xsdValidator) matchElement(p *xsdParticle, children []xmlNode, idx int, path string) int {
+	el := p.element
+	count := 0
+	for idx < len(children) && matchesElement(el, children[idx]) {
+		count++
+		childPath := path + "/" + el.name
+		if p.max != 1 {
+			childPath = fmt.Sprintf("%s[%d]", childPath, count)
+		}
+		if p.max >= 0 && count > p.max {
+			v.add(childPath, "se admiten máximo %d elementos <%s>", p.max, el.name)
+		} else {
+			v.validateElement(el, &children[idx], childPath)
+		}
+		idx++
+	}
+	if count < p.min {
+		v.add(path+"/"+el.name, "falta el elemento obligatorio")
+	}
+	return idx
+}
+
+func matchesElement(el *xsdElement, node xmlNode) bool {
+	if node.XMLName.Local != el.name {
+		return false
+	}
+	if el.opaque {
+		return node.XMLName.Space == xmldsigNamespace
+	}
+	return node.XMLName.Space == ""
+}
+
+func choiceAlternative(p *xsdParticle, children []xmlNode, idx int) *xsdParticle {
+	if idx >= len(children) {
+		return nil
+	}
+	for _, alt := range p.children {
+		if startsWith(alt, children[idx]) {
+			return alt
+		}
+	}
+	return nil
+}
+
+// startsWith indica si el nodo puede ser el primer elemento de la partícula.
+func startsWith(p *xsdParticle, node xmlNode) bool {
+	switch p.kind {
+	case particleElement:
+		return matchesElement(p.element, node)
+	case particleChoice:
+		for _, alt := range p.children {
+			if startsWith(alt, node) {
+				return true
+			}
+		}
+		return false
+	default:
+		for _, child := range p.children {
+			if startsWith(child, node) {
+				return true
+			}
+			if child.min > 0 {
+				return false
+			}
+		}
+		return false
+	}
+}
+
+func firstNames(p *xsdParticle) []string {
+	if p.kind == particleElement {
+		return []string{p.element.name}
+	}
+	var names []string
+	for _, child := range p.children {
+		names = append(names, firstNames(child)...)
+		if p.kind == particleSequence && child.min > 0 {
+			break
+		}
+	}
+	return names
+}
+
+var (
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	datePattern    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// validateSimple recorre la cadena de derivación desde el tipo nativo: cada restricción debe cumplirse.
+func (v *xsdValidator) validateSimple(t *xsdType, value, path string) {
+	if t.base != nil {
+		v.validateSimple(t.base, value, path)
+	}
+
+	if t.builtin != "" {
+		switch t.builtin {
+		case "decimal":
+			if !decimalPattern.MatchString(strings.TrimSpace(value)) {
+				v.add(path, "%q no es un número decimal válido", value)
+			}
+		case "integer":
+			if !integerPattern.MatchString(strings.TrimSpace(value)) {
+				v.add(path, "%q no es un número entero válido", value)
+			}
+		case "date":
+			if !datePattern.MatchString(strings.TrimSpace(value)) {
+				v.add(path, "%q no es una fecha válida (aaaa-mm-dd)", value)
+			}
+		case "NMTOKEN":
+			if strings.TrimSpace(value) == "" || strings.ContainsAny(strings.TrimSpace(value), " \t\r\n") {
+				v.add(path, "%q no es un NMTOKEN válido", value)
+			}
+		}
+		return
+	}
+
+	lexical := value
+	isNumeric := numericBase(t)
+	if isNumeric || tokenBase(t) {
+		lexical = strings.TrimSpace(value)
+	}
+
+	if len(t.patterns) > 0 {
+		matched := false
+		for _, re := range t.patterns {
+			if re.MatchString(lexical) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.add(path, "valor %q no cumple el formato requerido", value)
+		}
+	}
+
+	if len(t.enumerations) > 0 {
+		found := false
+		for _, e := range t.enumerations {
+			if e == lexical {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.add(path, "valor %q no permitido (se espera: %s)", value, strings.Join(t.enumerations, ", "))
+		}
+	}
+
+	n := utf8.RuneCountInString(lexical)
+	if t.length != nil && n != *t.length {
+		v.add(path, "debe tener exactamente %d caracteres (tiene %d)", *t.length, n)
+	}
+	if t.minLength != nil && n < *t.minLength {
+		if n == 0 {
+			v.add(path, "no puede estar vacío")
+		} else {
+			v.add(path, "debe tener al menos %d caracteres (tiene %d)", *t.minLength, n)
+		}
+	}
+	if t.maxLength != nil && n > *t.maxLength {
+		v.add(path, "admite máximo %d caracteres (tiene %d)", *t.maxLength, n)
+	}
+
+	if !isNumeric || !decimalPattern.MatchString(lexical) {
+		return
+	}
+	intDigits, fracDigits := decimalDigits(lexical)
+	if t.totalDigits != nil && intDigits+fracDigits > *t.totalDigits {
+		v.add(path, "valor %q excede %d dígitos en total", value, *t.totalDigits)
+	}
+	if t.fractionDigits != nil && fracDigits > *t.fractionDigits {
+		v.add(path, "valor %q admite máximo %d decimales", value, *t.fractionDigits)
+	}
+	num, ok := new(big.Rat).SetString(lexical)
+	if !ok {
+		return
+	}
+	if t.minInclusive != nil && num.Cmp(t.minInclusive) < 0 {
+		v.add(path, "valor %q debe ser mayor o igual a %s", value, t.minInclusive.FloatString(2))
+	}
+	if t.maxInclusive != nil && num.Cmp(t.maxInclusive) > 0 {
+		v.add(path, "valor %q debe ser menor o igual a %s", value, t.maxInclusive.FloatString(2))
+	}
+}
+
+func rootBuiltin(t *xsdType) string {
+	for t.base != nil {
+		t = t.base
+	}
+	return t.builtin
+}
+
+func numericBase(t *xsdType) bool {
+	b := rootBuiltin(t)
+	return b == "decimal" || b == "integer"
+}
+
+func tokenBase(t *xsdType) bool {
+	b := rootBuiltin(t)
+	return b == "token" || b == "NMTOKEN" || b == "date"
+}
+
+// decimalDigits cuenta los dígitos significativos de un decimal tal como lo hace XSD
+// (sin ceros a la izquierda de la parte entera ni ceros a la derecha de la fracción).
+func decimalDigits(lexical string) (int, int) {
+	s := strings.TrimLeft(lexical, "+-")
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	fracPart = strings.TrimRight(fracPart, "0")
+	return len(intPart), len(fracPart)
+}
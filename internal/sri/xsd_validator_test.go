@@ -0,0 +1,202 @@
+package sri
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validFactura() *Factura {
+	f := &Factura{}
+	f.InfoTributaria = InfoTributaria{
+		Ambiente: "1", TipoEmision: "1", RazonSocial: "Empresa de Prueba S.A.", Ruc: "1790012345001",
+		ClaveAcceso: strings.Repeat("1", 49), CodDoc: "01", Estab: "001", PtoEmi: "001",
+		Secuencial: "000000001", DirMatriz: "Av. Amazonas y Colón",
+	}
+	f.InfoFactura = InfoFactura{
+		FechaEmision: "10/01/2026", ObligadoContabilidad: "NO", TipoIdentificacionComprador: "05",
+		RazonSocialComprador: "Juan Perez", IdentificacionComprador: "1712345678",
+		TotalSinImpuestos: "10.00", TotalDescuento: "0.00", Propina: "0.00", ImporteTotal: "11.50", Moneda: "DOLAR",
+	}
+	f.InfoFactura.TotalConImpuestos.TotalImpuesto = []TotalImpuesto{{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "10.00", Valor: "1.50"}}
+	f.InfoFactura.Pagos.Pago = []Pago{{FormaPago: "01", Total: "11.50"}}
+
+	det := Detalle{Descripcion: "Servicio", Cantidad: "1.000000", PrecioUnitario: "10.000000", Descuento: "0.00", PrecioTotalSinImpuesto: "10.00"}
+	det.Impuestos.Impuesto = []Impuesto{{Codigo: "2", CodigoPorcentaje: "4", Tarifa: "15", BaseImponible: "10.00", Valor: "1.50"}}
+	f.Detalles.Detalle = []Detalle{det}
+	f.InfoAdicional = &InfoAdicional{CampoAdicional: []CampoAdicional{{Nombre: "Email", Valor: "juan@test.com"}}}
+	return f
+}
+
+func validationPaths(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var verrs ValidationErrors
+	require.True(t, errors.As(err, &verrs), "se esperaba ValidationErrors, se obtuvo %v", err)
+	paths := map[string]string{}
+	for _, ve := range verrs {
+		paths[ve.Path] = ve.Message
+	}
+	return paths
+}
+
+func TestValidateFactura(t *testing.T) {
+	t.Run("Accepts a well formed invoice", func(t *testing.T) {
+		xmlBytes, err := MarshalFactura(validFactura())
+		require.NoError(t, err)
+		assert.NoError(t, ValidateFactura(xmlBytes))
+	})
+
+	t.Run("Reports field paths for invalid values", func(t *testing.T) {
+		f := validFactura()
+		f.InfoTributaria.Ruc = "1790012345"
+		f.InfoFactura.TipoIdentificacionComprador = ""
+		f.InfoFactura.RazonSocialComprador = ""
+		extra := f.Detalles.Detalle[0]
+		extra.Cantidad = "1.1234567"
+		f.Detalles.Detalle = append(f.Detalles.Detalle, extra)
+
+		xmlBytes, err := MarshalFactura(f)
+		require.NoError(t, err)
+
+		paths := validationPaths(t, ValidateFactura(xmlBytes))
+		assert.Contains(t, paths, "factura/infoTributaria/ruc")
+		assert.Contains(t, paths, "factura/infoFactura/tipoIdentificacionComprador")
+		assert.Equal(t, "no puede estar vacío", paths["factura/infoFactura/razonSocialComprador"])
+		assert.Contains(t, paths["factura/detalles/detalle[2]/cantidad"], "máximo 6 decimales")
+		assert.NotContains(t, paths, "factura/detalles/detalle[1]/cantidad")
+	})
+
+	t.Run("Reports missing and unexpected elements", func(t *testing.T) {
+		xmlBytes, err := MarshalFactura(validFactura())
+		require.NoError(t, err)
+		xmlStr := strings.Replace(string(xmlBytes), "<totalDescuento>0.00</totalDescuento>", "", 1)
+		xmlStr = strings.Replace(xmlStr, "<infoAdicional>", "<notas>x</notas><infoAdicional>", 1)
+
+		paths := validationPaths(t, ValidateFactura([]byte(xmlStr)))
+		assert.Len(t, paths, 2)
+		assert.Equal(t, "falta el elemento obligatorio", paths["factura/infoFactura/totalDescuento"])
+		assert.Equal(t, "elemento no permitido", paths["factura/notas"])
+	})
+
+	t.Run("Rejects more than 15 additional fields", func(t *testing.T) {
+		f := validFactura()
+		f.InfoAdicional.CampoAdicional = nil
+		for i := 1; i <= 16; i++ {
+			f.InfoAdicional.CampoAdicional = append(f.InfoAdicional.CampoAdicional, CampoAdicional{Nombre: fmt.Sprintf("Campo %d", i), Valor: "x"})
+		}
+		xmlBytes, err := MarshalFactura(f)
+		require.NoError(t, err)
+
+		paths := validationPaths(t, ValidateFactura(xmlBytes))
+		assert.Len(t, paths, 1)
+		assert.Contains(t, paths, "factura/infoAdicional/campoAdicional[16]")
+	})
+
+	t.Run("Accepts export, reimbursement, withholding and compensation sections", func(t *testing.T) {
+		xmlBytes, err := os.ReadFile("testdata/factura_exportacion_reembolso.xml")
+		require.NoError(t, err)
+		assert.NoError(t, ValidateFactura(xmlBytes))
+
+		// La firma XAdES al final del comprobante también es parte del esquema
+		p12Bytes, err := os.ReadFile("testdata/firma_prueba.p12")
+		require.NoError(t, err)
+		signed, err := SignXAdES(xmlBytes, p12Bytes, "Prueba123", SHA1)
+		require.NoError(t, err)
+		assert.NoError(t, ValidateFactura(signed))
+
+		unordered := strings.Replace(string(xmlBytes), "<codDocReembolso>41</codDocReembolso>", "", 1)
+		unordered = strings.Replace(unordered, "</totalConImpuestos>", "</totalConImpuestos>\n    <codDocReembolso>41</codDocReembolso>", 1)
+		paths := validationPaths(t, ValidateFactura([]byte(unordered)))
+		assert.Contains(t, paths, "factura/infoFactura/codDocReembolso")
+	})
+
+	t.Run("Checks root attributes", func(t *testing.T) {
+		f := validFactura()
+		f.Version = "3.0.0"
+		xmlBytes, err := MarshalFactura(f)
+		require.NoError(t, err)
+
+		paths := validationPaths(t, ValidateFactura(xmlBytes))
+		assert.Contains(t, paths, "factura/@version")
+	})
+}
+
+func TestValidateNotaCredito(t *testing.T) {
+	nc := &NotaCredito{}
+	nc.InfoTributaria = validFactura().InfoTributaria
+	nc.InfoTributaria.CodDoc = "04"
+	nc.InfoNotaCredito = InfoNotaCredito{
+		FechaEmision: "15/01/2026", DirEstablecimiento: "Quito", TipoIdentificacionComprador: "05",
+		RazonSocialComprador: "Juan Perez", IdentificacionComprador: "1712345678", ObligadoContabilidad: "NO",
+		CodDocModificado: "01", NumDocModificado: "001-001-000000001", FechaEmisionDocSustento: "10/01/2026",
+		TotalSinImpuestos: "10.00", ValorModificacion: "11.50", Moneda: "DOLAR", Motivo: "Devolución",
+	}
+	nc.InfoNotaCredito.TotalConImpuestos.TotalImpuesto = []TotalImpuesto{{Codigo: "2", CodigoPorcentaje: "4", BaseImponible: "10.00", Valor: "1.50"}}
+	det := DetalleNC{CodigoInterno: "NC-RET", Descripcion: "Servicio", Cantidad: "1.000000", PrecioUnitario: "10.000000", Descuento: "0.00", PrecioTotalSinImpuesto: "10.00"}
+	det.Impuestos.Impuesto = []Impuesto{{Codigo: "2", CodigoPorcentaje: "4", Tarifa: "15", BaseImponible: "10.00", Valor: "1.50"}}
+	nc.Detalles.Detalle = []DetalleNC{det}
+
+	t.Run("Accepts a well formed credit note", func(t *testing.T) {
+		xmlBytes, err := MarshalNotaCredito(nc)
+		require.NoError(t, err)
+		assert.NoError(t, ValidateNotaCredito(xmlBytes))
+	})
+
+	t.Run("Accepts compensations", func(t *testing.T) {
+		xmlBytes, err := MarshalNotaCredito(nc)
+		require.NoError(t, err)
+		withCompensation := strings.Replace(string(xmlBytes), "<valorModificacion>",
+			"<compensaciones><compensacion><codigo>1</codigo><tarifa>2</tarifa><valor>0.20</valor></compensacion></compensaciones><valorModificacion>", 1)
+		assert.NoError(t, ValidateNotaCredito([]byte(withCompensation)))
+	})
+
+	t.Run("Rejects an invalid modified document number", func(t *testing.T) {
+		nc.InfoNotaCredito.NumDocModificado = "000-000-0001"
+		xmlBytes, err := MarshalNotaCredito(nc)
+		require.NoError(t, err)
+
+		paths := validationPaths(t, ValidateNotaCredito(xmlBytes))
+		assert.Contains(t, paths, "notaCredito/infoNotaCredito/numDocModificado")
+	})
+}
+
+func TestValidateATS(t *testing.T) {
+	validATS := func() *ATS {
+		return &ATS{
+			IdInformante: "1790012345001", RazonSocial: "EMPRESA DE PRUEBA SA", Anio: "2026", Mes: "02",
+			NumEstabRuc: "001", TotalVentas: "100.00",
+			Ventas: &ATSVentas{DetalleVentas: []DetalleVentas{{
+				TpIdCliente: "05", IdCliente: "1712345678", ParteRelVtas: "NO", TipoComprobante: "18", TipoEmision: "E",
+				NumeroComprobantes: 2, BaseNoGraIva: "0.00", BaseImponible: "0.00", BaseImpGrav: "100.00", MontoIva: "15.00",
+				MontoIce: "0.00", ValorRetIva: "0.00", ValorRetRenta: "0.00",
+				FormasDePago: &ATSFormasDePago{FormaPago: []string{"01"}},
+			}}},
+			VentasEstablecimiento: &ATSVentasEstablecimiento{VentaEst: []VentaEst{{CodEstab: "001", VentasEstab: "100.00", IvaComp: "0.00"}}},
+		}
+	}
+
+	t.Run("Accepts a well formed annex", func(t *testing.T) {
+		xmlBytes, err := MarshalATS(validATS())
+		require.NoError(t, err)
+		assert.NoError(t, ValidateATS(xmlBytes))
+	})
+
+	t.Run("Reports each invalid field", func(t *testing.T) {
+		ats := validATS()
+		ats.RazonSocial = "Empresa de Prueba S.A."
+		ats.Mes = "13"
+		ats.Ventas.DetalleVentas[0].TipoComprobante = "01"
+		xmlBytes, err := MarshalATS(ats)
+		require.NoError(t, err)
+
+		paths := validationPaths(t, ValidateATS(xmlBytes))
+		assert.Contains(t, paths, "iva/razonSocial")
+		assert.Contains(t, paths, "iva/Mes")
+		assert.Contains(t, paths, "iva/ventas/detalleVentas[1]/tipoComprobante")
+	})
+}
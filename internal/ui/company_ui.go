@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+// chooseCompany selects the company whose books the session works on and then calls onSelected.
+// With a single active company it is selected directly, unless alwaysAsk is set.
+func (ui *UI) chooseCompany(parent fyne.Window, alwaysAsk bool, onSelected func()) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	companies, err := ui.Services.CompanyService.GetCompanies(ctx)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("error al cargar las empresas: %w", err), parent)
+		return
+	}
+
+	var active []domain.Company
+	for _, c := range companies {
+		if c.IsActive {
+			active = append(active, c)
+		}
+	}
+
+	if len(active) == 0 {
+		if ui.currentUser.CanConfigureSystem() {
+			ui.showNewCompanyDialog(parent, func() { ui.chooseCompany(parent, alwaysAsk, onSelected) })
+			return
+		}
+		dialog.ShowError(fmt.Errorf("no hay empresas activas; solicite a un administrador que cree una"), parent)
+		return
+	}
+
+	if len(active) == 1 && !alwaysAsk {
+		ui.selectCompany(parent, active[0].ID, onSelected)
+		return
+	}
+
+	names := make([]string, len(active))
+	for i, c := range active {
+		names[i] = c.Name
+	}
+	companySelect := widget.NewSelect(names, nil)
+	companySelect.SetSelectedIndex(0)
+	if ui.currentCompany != nil {
+		companySelect.SetSelected(ui.currentCompany.Name)
+	}
+
+	content := container.NewVBox(widget.NewLabel("Seleccione la empresa con la que va a trabajar:"), companySelect)
+
+	var d dialog.Dialog
+	if ui.currentUser.CanConfigureSystem() {
+		newBtn := widget.NewButtonWithIcon("Nueva Empresa", theme.ContentAddIcon(), func() {
+			d.Hide()
+			ui.showNewCompanyDialog(parent, func() { ui.chooseCompany(parent, true, onSelected) })
+		})
+		content.Add(newBtn)
+	}
+
+	d = dialog.NewCustomConfirm("Empresa", "Ingresar", "Cancelar", content, func(confirm bool) {
+		i := companySelect.SelectedIndex()
+		if !confirm || i < 0 {
+			return
+		}
+		ui.selectCompany(parent, active[i].ID, onSelected)
+	}, parent)
+	d.Resize(fyne.NewSize(400, 200))
+	d.Show()
+}
+
+func (ui *UI) selectCompany(parent fyne.Window, id int, onSelected func()) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	company, err := ui.Services.CompanyService.SelectCompany(ctx, id)
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+	ui.currentCompany = company
+	onSelected()
+}
+
+func (ui *UI) showNewCompanyDialog(parent fyne.Window, onCreated func()) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Razón social o nombre comercial")
+
+	dialog.ShowForm("Nueva Empresa", "Crear", "Cancelar",
+		[]*widget.FormItem{widget.NewFormItem("Nombre", nameEntry)},
+		func(confirm bool) {
+			if !confirm {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			company := &domain.Company{Name: nameEntry.Text}
+			if err := ui.Services.CompanyService.CreateCompany(ctx, company, ui.currentUser); err != nil {
+				dialog.ShowError(fmt.Errorf("error al crear la empresa: %w", err), parent)
+				return
+			}
+			onCreated()
+		}, parent)
+}
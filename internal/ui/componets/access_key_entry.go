@@ -0,0 +1,106 @@
+package componets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/sri"
+)
+
+var printedAuthorization = regexp.MustCompile(`^\d{10}$`)
+
+// AccessKeyEntry es un campo para escribir o pegar claves de acceso del SRI. Valida la clave
+// (incluido el dígito verificador) mientras se escribe y muestra debajo a qué comprobante corresponde.
+type AccessKeyEntry struct {
+	Entry *widget.Entry
+	info  *widget.Label
+
+	// AllowPrinted acepta también el número de autorización de 10 dígitos de los comprobantes preimpresos.
+	AllowPrinted bool
+	// OnParsed recibe la clave cada vez que el texto cambia; nil si el texto no es una clave válida.
+	OnParsed func(key *sri.AccessKey)
+}
+
+func NewAccessKeyEntry() *AccessKeyEntry {
+	e := &AccessKeyEntry{
+		Entry: widget.NewEntry(),
+		info:  widget.NewLabel(""),
+	}
+	e.Entry.SetPlaceHolder("Clave de acceso (49 dígitos)")
+	e.info.TextStyle = fyne.TextStyle{Italic: true}
+	e.info.Wrapping = fyne.TextWrapWord
+
+	e.Entry.Validator = func(s string) error {
+		if s == "" || (e.AllowPrinted && printedAuthorization.MatchString(s)) {
+			return nil
+		}
+		_, err := sri.ParseAccessKey(s)
+		return err
+	}
+	e.Entry.OnChanged = e.onChanged
+	return e
+}
+
+// GetContent devuelve el campo junto con la línea que describe la clave.
+func (e *AccessKeyEntry) GetContent() fyne.CanvasObject {
+	return container.NewVBox(e.Entry, e.info)
+}
+
+func (e *AccessKeyEntry) onChanged(s string) {
+	// Las claves copiadas del RIDE o de un correo suelen venir partidas con espacios o saltos de línea
+	if compact := strings.Join(strings.Fields(s), ""); compact != s {
+		e.Entry.SetText(compact)
+		return
+	}
+
+	key, err := sri.ParseAccessKey(s)
+	switch {
+	case s == "":
+		e.info.SetText("")
+	case err == nil:
+		e.info.SetText(fmt.Sprintf("%s %s del %s", key.DocTypeName(), key.DocumentNumber(), key.Date.Format(AppDateFormat)))
+	case e.AllowPrinted && printedAuthorization.MatchString(s):
+		e.info.SetText("Autorización de comprobante preimpreso")
+	default:
+		e.info.SetText(err.Error())
+	}
+
+	if e.OnParsed != nil {
+		e.OnParsed(key)
+	}
+}
+
+// ShowAccessKeyChecker abre un diálogo para verificar una clave de acceso y ver sus campos.
+func ShowAccessKeyChecker(parent fyne.Window) {
+	entry := NewAccessKeyEntry()
+
+	detail := widget.NewForm()
+	entry.OnParsed = func(key *sri.AccessKey) {
+		detail.Items = nil
+		if key != nil {
+			environment := "Pruebas"
+			if key.Environment == 2 {
+				environment = "Producción"
+			}
+			detail.Items = []*widget.FormItem{
+				widget.NewFormItem("Comprobante:", widget.NewLabel(fmt.Sprintf("%s (%s)", key.DocTypeName(), key.DocType))),
+				widget.NewFormItem("Número:", widget.NewLabel(key.DocumentNumber())),
+				widget.NewFormItem("Fecha Emisión:", widget.NewLabel(key.Date.Format(AppDateFormat))),
+				widget.NewFormItem("RUC Emisor:", widget.NewLabel(key.RUC)),
+				widget.NewFormItem("Ambiente:", widget.NewLabel(environment)),
+				widget.NewFormItem("Código Numérico:", widget.NewLabel(key.NumericCode)),
+			}
+		}
+		detail.Refresh()
+	}
+
+	content := container.NewVBox(entry.GetContent(), widget.NewSeparator(), detail)
+	dlg := dialog.NewCustom("Verificar Clave de Acceso", "Cerrar", content, parent)
+	dlg.Resize(fyne.NewSize(520, 380))
+	dlg.Show()
+}
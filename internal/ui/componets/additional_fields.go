@@ -0,0 +1,83 @@
+package componets
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+// AdditionalFieldsEditor edita en memoria una lista de campos adicionales (nombre/valor) que
+// se imprimen en la sección "Información Adicional" de los comprobantes.
+type AdditionalFieldsEditor struct {
+	fields    []domain.AdditionalField
+	max       int
+	container *fyne.Container
+	parent    fyne.Window
+}
+
+func NewAdditionalFieldsEditor(parent fyne.Window, max int) *AdditionalFieldsEditor {
+	return &AdditionalFieldsEditor{
+		parent:    parent,
+		max:       max,
+		container: container.NewVBox(),
+	}
+}
+
+func (e *AdditionalFieldsEditor) GetContent() fyne.CanvasObject {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Nombre (ej. Orden de Compra)")
+	valueEntry := widget.NewEntry()
+	valueEntry.SetPlaceHolder("Valor")
+
+	addBtn := widget.NewButtonWithIcon("Agregar", theme.ContentAddIcon(), func() {
+		if len(e.fields) >= e.max {
+			dialog.ShowError(fmt.Errorf("se admiten máximo %d campos adicionales", e.max), e.parent)
+			return
+		}
+		field := domain.AdditionalField{Name: nameEntry.Text, Value: valueEntry.Text}
+		if err := domain.ValidateAdditionalFields([]domain.AdditionalField{field}); err != nil {
+			dialog.ShowError(err, e.parent)
+			return
+		}
+		e.fields = append(e.fields, field)
+		nameEntry.SetText("")
+		valueEntry.SetText("")
+		e.refreshList()
+	})
+
+	inputRow := container.NewBorder(nil, nil, nil, addBtn, container.NewGridWithColumns(2, nameEntry, valueEntry))
+
+	e.refreshList()
+	return container.NewVBox(inputRow, e.container)
+}
+
+// SetFields reemplaza la lista actual (ej. al cargar la configuración guardada).
+func (e *AdditionalFieldsEditor) SetFields(fields []domain.AdditionalField) {
+	e.fields = append([]domain.AdditionalField(nil), fields...)
+	e.refreshList()
+}
+
+// Fields devuelve los campos ingresados.
+func (e *AdditionalFieldsEditor) Fields() []domain.AdditionalField {
+	return e.fields
+}
+
+func (e *AdditionalFieldsEditor) refreshList() {
+	e.container.RemoveAll()
+	for i, f := range e.fields {
+		idx := i
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			e.fields = append(e.fields[:idx], e.fields[idx+1:]...)
+			e.refreshList()
+		})
+		e.container.Add(container.NewBorder(nil, nil, nil, removeBtn, container.NewGridWithColumns(2,
+			widget.NewLabelWithStyle(f.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewLabel(f.Value),
+		)))
+	}
+}
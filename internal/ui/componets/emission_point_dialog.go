@@ -16,6 +16,9 @@ import (
 type EmissionPointService interface {
 	GetEmissionPoints(ctx context.Context) ([]domain.EmissionPoint, error)
 	UpdateEmissionPoint(ctx context.Context, ep *domain.EmissionPoint) error
+	GetEstablishments(ctx context.Context) ([]domain.Establishment, error)
+	SaveEstablishment(ctx context.Context, e *domain.Establishment) error
+	AddEmissionPoint(ctx context.Context, establishmentCode, emissionPointCode string) error
 }
 
 type EmissionPointDialog struct {
@@ -59,9 +62,13 @@ func (d *EmissionPointDialog) Show() {
 		widget.NewLabelWithStyle("Acciones", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
 	)
 
+	establishmentsBtn := widget.NewButtonWithIcon("Establecimientos", theme.HomeIcon(), d.showEstablishments)
+	newPointBtn := widget.NewButtonWithIcon("Nuevo Punto", theme.ContentAddIcon(), d.showNewPoint)
+
 	content := container.NewBorder(
 		container.NewVBox(
 			widget.NewLabelWithStyle("Gestión de Secuenciales y Migración", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			container.NewHBox(establishmentsBtn, newPointBtn),
 			header,
 		),
 		nil, nil, nil,
@@ -95,8 +102,17 @@ func (d *EmissionPointDialog) fillPointListData(i widget.ListItemID, o fyne.Canv
 
 	// Tipo
 	name := "Factura (01)"
-	if p.ReceiptType == "04" {
+	switch p.ReceiptType {
+	case "03":
+		name = "Liquidación de Compra (03)"
+	case "04":
 		name = "Nota de Crédito (04)"
+	case "05":
+		name = "Nota de Débito (05)"
+	case "06":
+		name = "Guía de Remisión (06)"
+	case "07":
+		name = "Retención (07)"
 	}
 	row.Objects[0].(*widget.Label).SetText(name)
 
@@ -202,4 +218,128 @@ func (d *EmissionPointDialog) performUpdate(p domain.EmissionPoint, cur, ini int
 		d.refreshData()
 		dialog.ShowInformation("Actualizado", "Secuencial actualizado correctamente.", d.window)
 	}
-}
\ No newline at end of file
+}
+
+// showEstablishments lista los establecimientos del emisor. La matriz se edita en los datos del emisor;
+// los demás se abren al seleccionarlos.
+func (d *EmissionPointDialog) showEstablishments() {
+	establishments, err := d.service.GetEstablishments(context.Background())
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+
+	var listDlg dialog.Dialog
+	list := widget.NewList(
+		func() int { return len(establishments) },
+		func() fyne.CanvasObject { return widget.NewLabel("001 - Establecimiento") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			e := establishments[i]
+			text := fmt.Sprintf("%s - %s (%s)", e.Code, e.Name, e.Address)
+			if !e.IsActive {
+				text += " [Inactivo]"
+			}
+			o.(*widget.Label).SetText(text)
+		},
+	)
+	list.OnSelected = func(i widget.ListItemID) {
+		list.UnselectAll()
+		if i == 0 {
+			dialog.ShowInformation("Matriz", "La dirección de la matriz se modifica en los datos del emisor.", d.window)
+			return
+		}
+		e := establishments[i]
+		listDlg.Hide()
+		d.editEstablishment(&e)
+	}
+
+	addBtn := widget.NewButtonWithIcon("Agregar Establecimiento", theme.ContentAddIcon(), func() {
+		listDlg.Hide()
+		d.editEstablishment(&domain.Establishment{IsActive: true})
+	})
+
+	listDlg = dialog.NewCustom("Establecimientos", "Cerrar", container.NewBorder(addBtn, nil, nil, nil, list), d.window)
+	listDlg.Resize(fyne.NewSize(600, 400))
+	listDlg.Show()
+}
+
+func (d *EmissionPointDialog) editEstablishment(e *domain.Establishment) {
+	codeEntry := widget.NewEntry()
+	codeEntry.SetText(e.Code)
+	codeEntry.SetPlaceHolder("002")
+	if e.ID != 0 {
+		// El código ya se imprimió en comprobantes emitidos
+		codeEntry.Disable()
+	}
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(e.Name)
+	addressEntry := widget.NewEntry()
+	addressEntry.SetText(e.Address)
+	activeCheck := widget.NewCheck("Activo", nil)
+	activeCheck.SetChecked(e.IsActive)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Código", codeEntry),
+		widget.NewFormItem("Nombre", nameEntry),
+		widget.NewFormItem("Dirección", addressEntry),
+		widget.NewFormItem("", activeCheck),
+	}
+	form := dialog.NewForm("Establecimiento", "Guardar", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		e.Code = codeEntry.Text
+		e.Name = nameEntry.Text
+		e.Address = addressEntry.Text
+		e.IsActive = activeCheck.Checked
+		if err := d.service.SaveEstablishment(context.Background(), e); err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		d.showEstablishments()
+	}, d.window)
+	form.Resize(fyne.NewSize(500, 300))
+	form.Show()
+}
+
+// showNewPoint crea un punto de emisión en un establecimiento activo, con sus secuenciales para cada
+// tipo de comprobante.
+func (d *EmissionPointDialog) showNewPoint() {
+	establishments, err := d.service.GetEstablishments(context.Background())
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	var codes []string
+	for _, e := range establishments {
+		if e.IsActive {
+			codes = append(codes, e.Code)
+		}
+	}
+
+	estabSelect := widget.NewSelect(codes, nil)
+	if len(codes) > 0 {
+		estabSelect.SetSelected(codes[0])
+	}
+	pointEntry := widget.NewEntry()
+	pointEntry.SetPlaceHolder("002")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Establecimiento", estabSelect),
+		widget.NewFormItem("Punto de Emisión", pointEntry),
+	}
+	form := dialog.NewForm("Nuevo Punto de Emisión", "Crear", "Cancelar", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := d.service.AddEmissionPoint(context.Background(), estabSelect.Selected, pointEntry.Text); err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		d.refreshData()
+		dialog.ShowInformation("Punto Creado",
+			fmt.Sprintf("Se habilitó el punto %s.", domain.EmissionPointLabel(estabSelect.Selected, pointEntry.Text)), d.window)
+	}, d.window)
+	form.Resize(fyne.NewSize(400, 220))
+	form.Show()
+}
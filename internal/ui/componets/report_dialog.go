@@ -1,12 +1,16 @@
 package componets
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
 )
 
 type ReportDialog struct {
@@ -20,17 +24,41 @@ type ReportDialog struct {
 	// Daily Report Tab
 	dailyReportFormatSelect *widget.Select
 	onGenerateDailyReport   func(format string, outputPath string)
+
+	// ATS Tab
+	atsYearSelect  *widget.Select
+	atsMonthSelect *widget.Select
+	onGenerateATS  func(year int, month time.Month, outputPath string)
+
+	// Form 104 Tab
+	vatYearSelect          *widget.Select
+	vatMonthSelect         *widget.Select
+	vatPreviousCreditEntry *widget.Entry
+	vatFormatSelect        *widget.Select
+	onGenerateVAT          func(year int, month time.Month, previousCredit decimal.Decimal, format string, outputPath string)
+
+	// Legal Archive Tab
+	archiveFromEntry  *LatinDateEntry
+	archiveToEntry    *LatinDateEntry
+	archiveTypesCheck *widget.CheckGroup
+	onGenerateArchive func(from, to time.Time, receiptTypes []string, outputPath string)
 }
 
 func NewReportDialog(
 	parentWindow fyne.Window,
 	onGenerateTransactionReport func(format string, outputPath string),
 	onGenerateDailyReport func(format string, outputPath string),
+	onGenerateATS func(year int, month time.Month, outputPath string),
+	onGenerateVAT func(year int, month time.Month, previousCredit decimal.Decimal, format string, outputPath string),
+	onGenerateArchive func(from, to time.Time, receiptTypes []string, outputPath string),
 ) *ReportDialog {
 	return &ReportDialog{
 		parentWindow:                parentWindow,
 		onGenerateTransactionReport: onGenerateTransactionReport,
 		onGenerateDailyReport:       onGenerateDailyReport,
+		onGenerateATS:               onGenerateATS,
+		onGenerateVAT:               onGenerateVAT,
+		onGenerateArchive:           onGenerateArchive,
 	}
 }
 
@@ -39,9 +67,18 @@ func (rd *ReportDialog) Show() {
 		container.NewTabItem("Reporte Financiero Diario", rd.createDailyReportTab()),
 		container.NewTabItem("Reporte de Transacciones", rd.createTransactionReportTab()),
 	)
+	if rd.onGenerateATS != nil {
+		tabs.Append(container.NewTabItem("Anexo ATS", rd.createATSTab()))
+	}
+	if rd.onGenerateVAT != nil {
+		tabs.Append(container.NewTabItem("Formulario 104", rd.createVATTab()))
+	}
+	if rd.onGenerateArchive != nil {
+		tabs.Append(container.NewTabItem("Archivo Legal", rd.createArchiveTab()))
+	}
 
 	rd.dialog = dialog.NewCustom("Generar Reporte", "Cerrar", tabs, rd.parentWindow)
-	rd.dialog.Resize(fyne.NewSize(600, 230))
+	rd.dialog.Resize(fyne.NewSize(600, 300))
 	rd.dialog.Show()
 }
 
@@ -105,4 +142,186 @@ func (rd *ReportDialog) createDailyReportTab() fyne.CanvasObject {
 	generateBtn.Importance = widget.SuccessImportance
 
 	return container.NewVBox(form, generateBtn)
-}
\ No newline at end of file
+}
+
+var atsMonthNames = []string{
+	"Enero", "Febrero", "Marzo", "Abril", "Mayo", "Junio",
+	"Julio", "Agosto", "Septiembre", "Octubre", "Noviembre", "Diciembre",
+}
+
+// createATSTab genera el Anexo Transaccional Simplificado de un mes; por defecto el mes anterior,
+// que es el que se declara.
+func (rd *ReportDialog) createATSTab() fyne.CanvasObject {
+	previous := time.Now().AddDate(0, -1, 0)
+
+	years := make([]string, 0, 5)
+	for y := previous.Year(); y > previous.Year()-5; y-- {
+		years = append(years, strconv.Itoa(y))
+	}
+	rd.atsYearSelect = widget.NewSelect(years, nil)
+	rd.atsYearSelect.SetSelected(strconv.Itoa(previous.Year()))
+	rd.atsMonthSelect = widget.NewSelect(atsMonthNames, nil)
+	rd.atsMonthSelect.SetSelected(atsMonthNames[previous.Month()-1])
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Año", Widget: rd.atsYearSelect},
+			{Text: "Mes", Widget: rd.atsMonthSelect},
+		},
+	}
+
+	generateBtn := widget.NewButton("Generar", func() {
+		year, _ := strconv.Atoi(rd.atsYearSelect.Selected)
+		month := time.Month(rd.atsMonthSelect.SelectedIndex() + 1)
+
+		fileSaveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, rd.parentWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer func() { _ = writer.Close() }()
+			// Fire and forget. The caller is responsible for async execution and error handling.
+			rd.onGenerateATS(year, month, writer.URI().Path())
+		}, rd.parentWindow)
+		fileSaveDialog.SetFileName(fmt.Sprintf("AT%02d%d.xml", int(month), year))
+		fileSaveDialog.Show()
+	})
+	generateBtn.Importance = widget.SuccessImportance
+
+	return container.NewVBox(form, generateBtn)
+}
+
+// createVATTab genera la hoja de trabajo del formulario 104 de un mes. El crédito tributario del mes
+// anterior (casillero 605) se toma de la última declaración presentada.
+func (rd *ReportDialog) createVATTab() fyne.CanvasObject {
+	previous := time.Now().AddDate(0, -1, 0)
+
+	years := make([]string, 0, 5)
+	for y := previous.Year(); y > previous.Year()-5; y-- {
+		years = append(years, strconv.Itoa(y))
+	}
+	rd.vatYearSelect = widget.NewSelect(years, nil)
+	rd.vatYearSelect.SetSelected(strconv.Itoa(previous.Year()))
+	rd.vatMonthSelect = widget.NewSelect(atsMonthNames, nil)
+	rd.vatMonthSelect.SetSelected(atsMonthNames[previous.Month()-1])
+	rd.vatPreviousCreditEntry = widget.NewEntry()
+	rd.vatPreviousCreditEntry.SetText("0.00")
+	rd.vatFormatSelect = widget.NewSelect([]string{"PDF", "CSV"}, nil)
+	rd.vatFormatSelect.SetSelected("PDF")
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Año", Widget: rd.vatYearSelect},
+			{Text: "Mes", Widget: rd.vatMonthSelect},
+			{Text: "Crédito Mes Anterior", Widget: rd.vatPreviousCreditEntry, HintText: "Casillero 615 de la declaración anterior"},
+			{Text: "Formato", Widget: rd.vatFormatSelect},
+		},
+	}
+
+	generateBtn := widget.NewButton("Generar", func() {
+		year, _ := strconv.Atoi(rd.vatYearSelect.Selected)
+		month := time.Month(rd.vatMonthSelect.SelectedIndex() + 1)
+		previousCredit, err := decimal.NewFromString(strings.TrimSpace(rd.vatPreviousCreditEntry.Text))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("el crédito del mes anterior no es un número válido: %v", err), rd.parentWindow)
+			return
+		}
+		format := rd.vatFormatSelect.Selected
+
+		fileSaveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, rd.parentWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer func() { _ = writer.Close() }()
+			// Fire and forget. The caller is responsible for async execution and error handling.
+			rd.onGenerateVAT(year, month, previousCredit, format, writer.URI().Path())
+		}, rd.parentWindow)
+		fileSaveDialog.SetFileName(fmt.Sprintf("formulario104_%d%02d.%s", year, int(month), strings.ToLower(format)))
+		fileSaveDialog.Show()
+	})
+	generateBtn.Importance = widget.SuccessImportance
+
+	return container.NewVBox(form, generateBtn)
+}
+
+// archiveReceiptTypes son los tipos de comprobante que se pueden incluir en el archivo legal.
+var archiveReceiptTypes = []struct{ code, label string }{
+	{"01", "Facturas"},
+	{"03", "Liquidaciones de Compra"},
+	{"04", "Notas de Crédito"},
+	{"05", "Notas de Débito"},
+	{"06", "Guías de Remisión"},
+	{"07", "Retenciones"},
+}
+
+// createArchiveTab exporta los comprobantes autorizados de un período a un ZIP para conservarlos;
+// por defecto el año anterior completo.
+func (rd *ReportDialog) createArchiveTab() fyne.CanvasObject {
+	lastYear := time.Now().Year() - 1
+	rd.archiveFromEntry = NewLatinDateEntry(rd.parentWindow)
+	rd.archiveFromEntry.SetDate(time.Date(lastYear, time.January, 1, 0, 0, 0, 0, time.Local))
+	rd.archiveToEntry = NewLatinDateEntry(rd.parentWindow)
+	rd.archiveToEntry.SetDate(time.Date(lastYear, time.December, 31, 0, 0, 0, 0, time.Local))
+
+	labels := make([]string, len(archiveReceiptTypes))
+	for i, t := range archiveReceiptTypes {
+		labels[i] = t.label
+	}
+	rd.archiveTypesCheck = widget.NewCheckGroup(labels, nil)
+	rd.archiveTypesCheck.Horizontal = true
+	rd.archiveTypesCheck.SetSelected(labels)
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Desde", Widget: rd.archiveFromEntry},
+			{Text: "Hasta", Widget: rd.archiveToEntry},
+			{Text: "Comprobantes", Widget: rd.archiveTypesCheck},
+		},
+	}
+
+	generateBtn := widget.NewButton("Exportar", func() {
+		if rd.archiveFromEntry.Date == nil || rd.archiveToEntry.Date == nil {
+			dialog.ShowError(fmt.Errorf("ingrese las fechas con el formato DD/MM/YYYY"), rd.parentWindow)
+			return
+		}
+		from, to := *rd.archiveFromEntry.Date, *rd.archiveToEntry.Date
+
+		var receiptTypes []string
+		for _, t := range archiveReceiptTypes {
+			for _, selected := range rd.archiveTypesCheck.Selected {
+				if selected == t.label {
+					receiptTypes = append(receiptTypes, t.code)
+				}
+			}
+		}
+		if len(receiptTypes) == 0 {
+			dialog.ShowError(fmt.Errorf("seleccione al menos un tipo de comprobante"), rd.parentWindow)
+			return
+		}
+
+		fileSaveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, rd.parentWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer func() { _ = writer.Close() }()
+			// Fire and forget. The caller is responsible for async execution and error handling.
+			rd.onGenerateArchive(from, to, receiptTypes, writer.URI().Path())
+		}, rd.parentWindow)
+		fileSaveDialog.SetFileName(fmt.Sprintf("archivo_comprobantes_%s_%s.zip", from.Format("20060102"), to.Format("20060102")))
+		fileSaveDialog.Show()
+	})
+	generateBtn.Importance = widget.SuccessImportance
+
+	return container.NewVBox(form, generateBtn)
+}
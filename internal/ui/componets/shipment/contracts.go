@@ -0,0 +1,28 @@
+// Package shipment implements the dialogs for remission guides (Guía de Remisión 06).
+package shipment
+
+import (
+	"context"
+
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+type ShipmentService interface {
+	GetShipmentByID(ctx context.Context, id int) (*domain.Shipment, error)
+	GetAllShipments(ctx context.Context) ([]domain.Shipment, error)
+	CreateShipment(ctx context.Context, shipment *domain.Shipment, currentUser domain.User) error
+	NewShipmentFromTransaction(ctx context.Context, transactionID int) (*domain.Shipment, error)
+}
+
+type SriService interface {
+	EmitirGuiaRemision(ctx context.Context, shipmentID int, signaturePassword string) (string, error)
+	GenerateShipmentRide(ctx context.Context, shipmentID int) (string, error)
+}
+
+type TaxPayerService interface {
+	GetByID(ctx context.Context, id int) (*domain.TaxPayer, error)
+	GetByIdentification(ctx context.Context, identification string) (*domain.TaxPayer, error)
+	Create(ctx context.Context, tp *domain.TaxPayer) error
+	Update(ctx context.Context, tp *domain.TaxPayer) error
+	GetPaginated(ctx context.Context, page, pageSize int, search string) (*domain.PaginatedResult[domain.TaxPayer], error)
+}
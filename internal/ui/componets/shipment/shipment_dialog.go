@@ -0,0 +1,286 @@
+package shipment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/ui/componets"
+	"github.com/nelsonmarro/verith/internal/ui/componets/taxpayer"
+)
+
+// ShipmentDialog registra un traslado de mercadería y emite su Guía de Remisión (06).
+// Si recibe un borrador (creado desde una factura) precarga el destinatario y los ítems.
+type ShipmentDialog struct {
+	parent          fyne.Window
+	draft           *domain.Shipment
+	shipmentService ShipmentService
+	sriService      SriService
+	taxService      TaxPayerService
+	currentUser     domain.User
+	onSaved         func()
+
+	carrier        *domain.TaxPayer
+	carrierLabel   *widget.Label
+	recipient      *domain.TaxPayer
+	recipientLabel *widget.Label
+
+	plateEntry     *widget.Entry
+	departureEntry *widget.Entry
+	startEntry     *componets.LatinDateEntry
+	endEntry       *componets.LatinDateEntry
+	arrivalEntry   *widget.Entry
+	reasonEntry    *widget.Entry
+	routeEntry     *widget.Entry
+	docNumEntry    *widget.Entry
+	passEntry      *widget.Entry
+
+	items          []domain.ShipmentItem
+	itemsContainer *fyne.Container
+}
+
+func NewShipmentDialog(
+	parent fyne.Window,
+	draft *domain.Shipment,
+	shipmentService ShipmentService,
+	sriService SriService,
+	taxService TaxPayerService,
+	currentUser domain.User,
+	onSaved func(),
+) *ShipmentDialog {
+	if draft == nil {
+		now := time.Now()
+		draft = &domain.Shipment{StartDate: now, EndDate: now}
+	}
+	return &ShipmentDialog{
+		parent:          parent,
+		draft:           draft,
+		shipmentService: shipmentService,
+		sriService:      sriService,
+		taxService:      taxService,
+		currentUser:     currentUser,
+		onSaved:         onSaved,
+	}
+}
+
+func (d *ShipmentDialog) Show() {
+	d.carrierLabel = widget.NewLabel("Seleccione el transportista...")
+	carrierBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		taxpayer.NewSearchDialog(d.parent, log.Default(), d.taxService, func(tp *domain.TaxPayer) {
+			d.carrier = tp
+			d.carrierLabel.SetText(fmt.Sprintf("%s (%s)", tp.Name, tp.Identification))
+		}).Show()
+	})
+
+	d.recipientLabel = widget.NewLabel("Seleccione el destinatario...")
+	recipientBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		taxpayer.NewSearchDialog(d.parent, log.Default(), d.taxService, func(tp *domain.TaxPayer) {
+			d.recipient = tp
+			d.recipientLabel.SetText(fmt.Sprintf("%s (%s)", tp.Name, tp.Identification))
+			if d.arrivalEntry.Text == "" {
+				d.arrivalEntry.SetText(tp.Address)
+			}
+		}).Show()
+	})
+
+	d.plateEntry = widget.NewEntry()
+	d.plateEntry.SetPlaceHolder("ABC-1234")
+	d.departureEntry = widget.NewEntry()
+	d.departureEntry.SetPlaceHolder("Dirección de partida")
+	d.startEntry = componets.NewLatinDateEntry(d.parent)
+	d.startEntry.SetDate(d.draft.StartDate)
+	d.endEntry = componets.NewLatinDateEntry(d.parent)
+	d.endEntry.SetDate(d.draft.EndDate)
+
+	d.arrivalEntry = widget.NewEntry()
+	d.arrivalEntry.SetPlaceHolder("Dirección de llegada")
+	d.reasonEntry = widget.NewEntry()
+	d.reasonEntry.SetPlaceHolder("Motivo (ej. Venta)")
+	d.routeEntry = widget.NewEntry()
+	d.routeEntry.SetPlaceHolder("Ruta (opcional)")
+	d.docNumEntry = widget.NewEntry()
+	d.docNumEntry.SetPlaceHolder("001-001-000000123 (opcional)")
+	d.passEntry = widget.NewPasswordEntry()
+
+	d.loadDraft()
+
+	descEntry := widget.NewEntry()
+	descEntry.SetPlaceHolder("Descripción")
+	qtyEntry := widget.NewEntry()
+	qtyEntry.SetPlaceHolder("Cantidad")
+	addBtn := widget.NewButtonWithIcon("Agregar", theme.ContentAddIcon(), func() {
+		item, err := parseShipmentItem(descEntry.Text, qtyEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, d.parent)
+			return
+		}
+		d.items = append(d.items, item)
+		descEntry.SetText("")
+		qtyEntry.SetText("")
+		d.refreshItems()
+	})
+	d.itemsContainer = container.NewVBox()
+	d.refreshItems()
+
+	transportForm := widget.NewForm(
+		widget.NewFormItem("Transportista:", container.NewBorder(nil, nil, nil, carrierBtn, d.carrierLabel)),
+		widget.NewFormItem("Placa:", d.plateEntry),
+		widget.NewFormItem("Punto de Partida:", d.departureEntry),
+		widget.NewFormItem("Inicio Traslado:", d.startEntry),
+		widget.NewFormItem("Fin Traslado:", d.endEntry),
+	)
+
+	recipientForm := widget.NewForm(
+		widget.NewFormItem("Destinatario:", container.NewBorder(nil, nil, nil, recipientBtn, d.recipientLabel)),
+		widget.NewFormItem("Punto de Llegada:", d.arrivalEntry),
+		widget.NewFormItem("Motivo:", d.reasonEntry),
+		widget.NewFormItem("Ruta:", d.routeEntry),
+		widget.NewFormItem("Factura Sustento:", d.docNumEntry),
+	)
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Transporte", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		transportForm,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Destinatario", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		recipientForm,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Mercadería", fyne.TextAlignLeading, fyne.TextStyle{Italic: true}),
+		container.NewBorder(nil, nil, nil, addBtn, container.NewGridWithColumns(2, descEntry, qtyEntry)),
+		d.itemsContainer,
+		widget.NewSeparator(),
+		widget.NewForm(widget.NewFormItem("Contraseña Firma:", d.passEntry)),
+	)
+
+	dlg := dialog.NewCustomConfirm("Emitir Guía de Remisión", "Emitir", "Cancelar", container.NewVScroll(content), func(confirm bool) {
+		if confirm {
+			d.emit()
+		}
+	}, d.parent)
+	dlg.Resize(fyne.NewSize(700, 650))
+	dlg.Show()
+}
+
+// loadDraft precarga los datos del borrador (destinatario, factura sustento e ítems).
+func (d *ShipmentDialog) loadDraft() {
+	if d.draft.Carrier != nil {
+		d.carrier = d.draft.Carrier
+		d.carrierLabel.SetText(fmt.Sprintf("%s (%s)", d.carrier.Name, d.carrier.Identification))
+	}
+	d.plateEntry.SetText(d.draft.Plate)
+	d.departureEntry.SetText(d.draft.DepartureAddress)
+
+	if len(d.draft.Recipients) == 0 {
+		return
+	}
+	rcp := d.draft.Recipients[0]
+	if rcp.TaxPayer != nil {
+		d.recipient = rcp.TaxPayer
+		d.recipientLabel.SetText(fmt.Sprintf("%s (%s)", rcp.TaxPayer.Name, rcp.TaxPayer.Identification))
+	}
+	d.arrivalEntry.SetText(rcp.ArrivalAddress)
+	d.reasonEntry.SetText(rcp.Reason)
+	d.routeEntry.SetText(rcp.Route)
+	d.docNumEntry.SetText(rcp.SupportDocNumber)
+	d.items = append(d.items, rcp.Items...)
+}
+
+func (d *ShipmentDialog) refreshItems() {
+	d.itemsContainer.RemoveAll()
+	for i, item := range d.items {
+		idx := i
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			d.items = append(d.items[:idx], d.items[idx+1:]...)
+			d.refreshItems()
+		})
+		d.itemsContainer.Add(container.NewBorder(nil, nil, nil, removeBtn, container.NewGridWithColumns(2,
+			widget.NewLabel(item.Description),
+			widget.NewLabel(strconv.FormatFloat(item.Quantity, 'f', -1, 64)),
+		)))
+	}
+}
+
+func (d *ShipmentDialog) buildShipment() (*domain.Shipment, error) {
+	if d.carrier == nil {
+		return nil, errors.New("seleccione el transportista")
+	}
+	if d.recipient == nil {
+		return nil, errors.New("seleccione el destinatario")
+	}
+	if d.startEntry.Date == nil || d.endEntry.Date == nil {
+		return nil, errors.New("las fechas de traslado no son válidas")
+	}
+
+	s := &domain.Shipment{
+		TransactionID:    d.draft.TransactionID,
+		CarrierID:        d.carrier.ID,
+		Plate:            strings.TrimSpace(d.plateEntry.Text),
+		DepartureAddress: strings.TrimSpace(d.departureEntry.Text),
+		StartDate:        *d.startEntry.Date,
+		EndDate:          *d.endEntry.Date,
+	}
+
+	rcp := domain.ShipmentRecipient{
+		TaxPayerID:       d.recipient.ID,
+		ArrivalAddress:   strings.TrimSpace(d.arrivalEntry.Text),
+		Reason:           strings.TrimSpace(d.reasonEntry.Text),
+		Route:            strings.TrimSpace(d.routeEntry.Text),
+		SupportDocNumber: strings.TrimSpace(d.docNumEntry.Text),
+		Items:            d.items,
+	}
+	// Conserva la fecha y autorización de la factura si el número sustento no cambió
+	if len(d.draft.Recipients) > 0 && d.draft.Recipients[0].SupportDocNumber == rcp.SupportDocNumber {
+		rcp.SupportDocDate = d.draft.Recipients[0].SupportDocDate
+		rcp.SupportDocAuthKey = d.draft.Recipients[0].SupportDocAuthKey
+	}
+	s.Recipients = []domain.ShipmentRecipient{rcp}
+	return s, nil
+}
+
+func (d *ShipmentDialog) emit() {
+	s, err := d.buildShipment()
+	if err != nil {
+		dialog.ShowError(err, d.parent)
+		return
+	}
+	password := d.passEntry.Text
+
+	componets.HandleLongRunningOperation(d.parent, "Emitiendo Guía de Remisión al SRI...", func(ctx context.Context) error {
+		if err := d.shipmentService.CreateShipment(ctx, s, d.currentUser); err != nil {
+			return err
+		}
+		// El envío queda guardado; si el SRI falla se puede re-emitir desde el listado
+		if _, err := d.sriService.EmitirGuiaRemision(domain.ContextWithUser(ctx, &d.currentUser), s.ID, password); err != nil {
+			return fmt.Errorf("el envío fue registrado, pero la guía de remisión no se emitió: %w", err)
+		}
+		return nil
+	}, func() {
+		dialog.ShowInformation("Guía de Remisión", "La guía de remisión fue enviada al SRI.", d.parent)
+		if d.onSaved != nil {
+			d.onSaved()
+		}
+	})
+}
+
+func parseShipmentItem(description, quantity string) (domain.ShipmentItem, error) {
+	item := domain.ShipmentItem{Description: strings.TrimSpace(description)}
+	if item.Description == "" {
+		return item, errors.New("ingrese la descripción del ítem")
+	}
+	qty, err := strconv.ParseFloat(strings.TrimSpace(quantity), 64)
+	if err != nil || qty <= 0 {
+		return item, errors.New("la cantidad no es válida")
+	}
+	item.Quantity = qty
+	return item, nil
+}
@@ -0,0 +1,201 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/ui/componets"
+)
+
+// ShipmentListDialog lista los envíos registrados con el estado SRI de su guía.
+type ShipmentListDialog struct {
+	parent          fyne.Window
+	shipmentService ShipmentService
+	sriService      SriService
+	taxService      TaxPayerService
+	currentUser     domain.User
+
+	data []domain.Shipment
+	list *widget.List
+}
+
+func NewShipmentListDialog(
+	parent fyne.Window,
+	shipmentService ShipmentService,
+	sriService SriService,
+	taxService TaxPayerService,
+	currentUser domain.User,
+) *ShipmentListDialog {
+	return &ShipmentListDialog{
+		parent:          parent,
+		shipmentService: shipmentService,
+		sriService:      sriService,
+		taxService:      taxService,
+		currentUser:     currentUser,
+	}
+}
+
+func (d *ShipmentListDialog) Show() {
+	d.list = widget.NewList(
+		func() int { return len(d.data) },
+		func() fyne.CanvasObject {
+			return container.NewGridWithColumns(5,
+				widget.NewLabel("01/01/2026"),
+				widget.NewLabel("Transportista"),
+				widget.NewLabel("ABC-1234"),
+				widget.NewLabel("ESTADO"),
+				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.MailSendIcon(), nil),
+					widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), nil),
+				),
+			)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i >= len(d.data) {
+				return
+			}
+			s := d.data[i]
+			row := o.(*fyne.Container)
+
+			row.Objects[0].(*widget.Label).SetText(s.StartDate.Format(componets.AppDateFormat))
+			carrierLbl := row.Objects[1].(*widget.Label)
+			carrierLbl.Truncation = fyne.TextTruncateEllipsis
+			if s.Carrier != nil {
+				carrierLbl.SetText(s.Carrier.Name)
+			}
+			row.Objects[2].(*widget.Label).SetText(s.Plate)
+
+			status := "SIN EMITIR"
+			if s.ElectronicReceipt != nil {
+				status = string(s.ElectronicReceipt.SRIStatus)
+			}
+			row.Objects[3].(*widget.Label).SetText(status)
+
+			actions := row.Objects[4].(*fyne.Container)
+			emitBtn := actions.Objects[0].(*widget.Button)
+			rideBtn := actions.Objects[1].(*widget.Button)
+
+			authorized := status == "AUTORIZADO"
+			emitBtn.OnTapped = func() { d.reEmit(s.ID) }
+			rideBtn.OnTapped = func() { d.saveRide(s) }
+			if authorized {
+				emitBtn.Disable()
+				rideBtn.Enable()
+			} else {
+				emitBtn.Enable()
+				rideBtn.Disable()
+			}
+		},
+	)
+
+	header := container.NewGridWithColumns(5,
+		widget.NewLabelWithStyle("Inicio", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Transportista", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Placa", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Estado SRI", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Acciones", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+
+	newBtn := widget.NewButtonWithIcon("Nueva Guía", theme.ContentAddIcon(), func() {
+		NewShipmentDialog(d.parent, nil, d.shipmentService, d.sriService, d.taxService, d.currentUser, d.loadData).Show()
+	})
+	newBtn.Importance = widget.HighImportance
+	refreshBtn := widget.NewButtonWithIcon("Recargar", theme.ViewRefreshIcon(), d.loadData)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(newBtn, refreshBtn, layout.NewSpacer()),
+			header,
+			widget.NewSeparator(),
+		),
+		nil, nil, nil,
+		d.list,
+	)
+
+	dlg := dialog.NewCustom("Guías de Remisión", "Cerrar", content, d.parent)
+	dlg.Resize(fyne.NewSize(800, 500))
+	d.loadData()
+	dlg.Show()
+}
+
+func (d *ShipmentListDialog) loadData() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		shipments, err := d.shipmentService.GetAllShipments(ctx)
+		if err != nil {
+			fyne.Do(func() { dialog.ShowError(err, d.parent) })
+			return
+		}
+		fyne.Do(func() {
+			d.data = shipments
+			d.list.Refresh()
+		})
+	}()
+}
+
+// reEmit vuelve a firmar y enviar la guía de un envío ya registrado.
+func (d *ShipmentListDialog) reEmit(shipmentID int) {
+	passEntry := widget.NewPasswordEntry()
+	form := []*widget.FormItem{widget.NewFormItem("Contraseña Firma:", passEntry)}
+
+	dialog.ShowForm("Emitir Guía de Remisión", "Emitir", "Cancelar", form, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		componets.HandleLongRunningOperation(d.parent, "Emitiendo Guía de Remisión al SRI...", func(ctx context.Context) error {
+			_, err := d.sriService.EmitirGuiaRemision(domain.ContextWithUser(ctx, &d.currentUser), shipmentID, passEntry.Text)
+			return err
+		}, func() {
+			dialog.ShowInformation("Guía de Remisión", "La guía de remisión fue enviada al SRI.", d.parent)
+			d.loadData()
+		})
+	}, d.parent)
+}
+
+func (d *ShipmentListDialog) saveRide(s domain.Shipment) {
+	var tempPath string
+	componets.HandleLongRunningOperation(d.parent, "Generando RIDE...", func(ctx context.Context) error {
+		var err error
+		tempPath, err = d.sriService.GenerateShipmentRide(ctx, s.ID)
+		return err
+	}, func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			defer os.Remove(tempPath)
+			if err != nil {
+				dialog.ShowError(err, d.parent)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			src, err := os.Open(tempPath)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("error leyendo archivo temporal: %w", err), d.parent)
+				return
+			}
+			defer src.Close()
+
+			if _, err := io.Copy(writer, src); err != nil {
+				dialog.ShowError(fmt.Errorf("error guardando archivo: %w", err), d.parent)
+				return
+			}
+			dialog.ShowInformation("Éxito", "RIDE guardado correctamente", d.parent)
+		}, d.parent)
+		saveDialog.SetFileName(fmt.Sprintf("RIDE-%s.pdf", s.ElectronicReceipt.AccessKey))
+		saveDialog.Show()
+	})
+}
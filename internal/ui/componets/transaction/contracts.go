@@ -27,6 +27,8 @@ type TransactionService interface {
 	CreateTransaction(ctx context.Context, transaction *domain.Transaction, currentUser domain.User) error
 	UpdateTransaction(ctx context.Context, tx *domain.Transaction, currentUser domain.User) error
 	VoidTransaction(ctx context.Context, transactionID int, currentUser domain.User) (int, error)
+	PartialVoidTransaction(ctx context.Context, originalID int, returned []domain.ReturnedItem, currentUser domain.User) (int, error)
+	GetCreditedAmount(ctx context.Context, transactionID int) (float64, error)
 	RevertVoidTransaction(ctx context.Context, voidTransactionID int) error
 	ReconcileAccount(
 		ctx context.Context,
@@ -68,11 +70,17 @@ type ReportService interface {
 type SriService interface {
 	EmitirFactura(ctx context.Context, transactionID int, signaturePassword string) error
 	EmitirNotaCredito(ctx context.Context, voidTxID int, originalTxID int, motivo string, signaturePassword string) (string, error)
+	EmitirRetencion(ctx context.Context, w *domain.Withholding, signaturePassword string) (string, error)
+	EmitirLiquidacionCompra(ctx context.Context, ps *domain.PurchaseSettlement, signaturePassword string) (string, error)
+	EmitirNotaDebito(ctx context.Context, debitTxID int, originalTxID int, signaturePassword string) (string, error)
+	EmitirGuiaRemision(ctx context.Context, shipmentID int, signaturePassword string) (string, error)
 	GenerateRide(ctx context.Context, transactionID int) (string, error)
+	GenerateShipmentRide(ctx context.Context, shipmentID int) (string, error)
 	SyncReceipt(ctx context.Context, receipt *domain.ElectronicReceipt) (string, error)
 	ProcessBackgroundSync(ctx context.Context) (int, error)
 	ResendEmail(ctx context.Context, transactionID int) error
 	GetPendingQueue(ctx context.Context) ([]domain.ElectronicReceipt, error)
+	GetReceiptEvents(ctx context.Context, accessKey string) ([]domain.ReceiptEvent, error)
 }
 
 type TaxPayerService interface {
@@ -85,4 +93,19 @@ type TaxPayerService interface {
 
 type IssuerService interface {
 	GetActive(ctx context.Context) (*domain.Issuer, error)
+	GetTaxRates(ctx context.Context) (domain.TaxRateTable, error)
+	GetInvoicePoints(ctx context.Context) ([]domain.EmissionPoint, error)
+}
+
+// PurchaseImportService registra facturas de proveedores a partir de su XML autorizado o del reporte
+// de comprobantes recibidos del SRI.
+type PurchaseImportService interface {
+	ImportSupplierInvoice(ctx context.Context, xmlPath string, accountID, categoryID int, currentUser domain.User) (*domain.Transaction, error)
+	DownloadReceived(
+		ctx context.Context,
+		reportPath string,
+		accountID, categoryID int,
+		currentUser domain.User,
+		onProgress func(done, total int, result domain.ReceivedDocumentResult),
+	) ([]domain.ReceivedDocumentResult, error)
 }
@@ -0,0 +1,236 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/ui/componets"
+)
+
+// DebitNoteDialog registra un cargo adicional (intereses, recargos) sobre una factura
+// autorizada como un nuevo ingreso y emite la Nota de Débito (05) correspondiente.
+type DebitNoteDialog struct {
+	parent        fyne.Window
+	original      *domain.Transaction
+	txService     TransactionService
+	sriService    SriService
+	issuerService IssuerService
+	currentUser   domain.User
+	onEmitted     func()
+
+	taxRates       domain.TaxRateTable
+	taxOptions     domain.TaxRateTable // Tarifa general vigente y 0%, en el orden del selector
+	items          []domain.TransactionItem
+	itemsContainer *fyne.Container
+	totalLabel     *widget.Label
+	passEntry      *widget.Entry
+}
+
+func NewDebitNoteDialog(
+	parent fyne.Window,
+	original *domain.Transaction,
+	txService TransactionService,
+	sriService SriService,
+	issuerService IssuerService,
+	currentUser domain.User,
+	onEmitted func(),
+) *DebitNoteDialog {
+	return &DebitNoteDialog{
+		parent:        parent,
+		original:      original,
+		txService:     txService,
+		sriService:    sriService,
+		issuerService: issuerService,
+		currentUser:   currentUser,
+		onEmitted:     onEmitted,
+	}
+}
+
+// loadTaxOptions carga el catálogo de tarifas y arma las opciones del cargo: la tarifa general
+// vigente hoy y la de 0%.
+func (d *DebitNoteDialog) loadTaxOptions() ([]string, error) {
+	rates, err := d.issuerService.GetTaxRates(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	d.taxRates = rates
+	d.taxOptions = nil
+	now := time.Now()
+	if general, err := d.taxRates.General(now); err == nil {
+		d.taxOptions = append(d.taxOptions, general)
+	}
+	if zero, err := d.taxRates.Find(domain.TaxRateCodeZero, now); err == nil {
+		d.taxOptions = append(d.taxOptions, zero)
+	}
+
+	options := make([]string, len(d.taxOptions))
+	for i, r := range d.taxOptions {
+		options[i] = r.Label()
+	}
+	return options, nil
+}
+
+func (d *DebitNoteDialog) Show() {
+	taxOptions, err := d.loadTaxOptions()
+	if err != nil {
+		dialog.ShowError(err, d.parent)
+		return
+	}
+
+	reasonEntry := widget.NewEntry()
+	reasonEntry.SetPlaceHolder("Razón (ej. Intereses por mora)")
+	valueEntry := widget.NewEntry()
+	valueEntry.SetPlaceHolder("Valor sin IVA")
+	taxSelect := widget.NewSelect(taxOptions, nil)
+	if len(d.taxOptions) > 0 {
+		taxSelect.SetSelectedIndex(0)
+	}
+
+	addBtn := widget.NewButtonWithIcon("Agregar", theme.ContentAddIcon(), func() {
+		rate := domain.TaxRate{Code: domain.TaxRateCodeZero}
+		if i := taxSelect.SelectedIndex(); i >= 0 {
+			rate = d.taxOptions[i]
+		}
+		item, err := parseDebitNoteItem(reasonEntry.Text, valueEntry.Text, rate)
+		if err != nil {
+			dialog.ShowError(err, d.parent)
+			return
+		}
+		d.items = append(d.items, item)
+		reasonEntry.SetText("")
+		valueEntry.SetText("")
+		d.refreshItems()
+	})
+
+	inputRow := container.NewBorder(nil, nil, nil, addBtn,
+		container.NewGridWithColumns(3, reasonEntry, valueEntry, taxSelect))
+
+	d.itemsContainer = container.NewVBox()
+	d.totalLabel = widget.NewLabelWithStyle("Total: $0.00", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true})
+	d.passEntry = widget.NewPasswordEntry()
+	d.refreshItems()
+
+	info := widget.NewForm(
+		widget.NewFormItem("Factura:", widget.NewLabelWithStyle(d.original.TransactionNumber, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})),
+		widget.NewFormItem("Fecha:", widget.NewLabel(d.original.TransactionDate.Format(componets.AppDateFormat))),
+		widget.NewFormItem("Total Factura:", widget.NewLabel(fmt.Sprintf("$%.2f", d.original.Amount))),
+	)
+
+	content := container.NewVBox(
+		info,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Motivos del cargo", fyne.TextAlignLeading, fyne.TextStyle{Italic: true}),
+		inputRow,
+		d.itemsContainer,
+		d.totalLabel,
+		widget.NewSeparator(),
+		widget.NewForm(widget.NewFormItem("Contraseña Firma:", d.passEntry)),
+	)
+
+	dlg := dialog.NewCustomConfirm("Emitir Nota de Débito", "Emitir", "Cancelar", container.NewVScroll(content), func(confirm bool) {
+		if confirm {
+			d.emit()
+		}
+	}, d.parent)
+	dlg.Resize(fyne.NewSize(650, 500))
+	dlg.Show()
+}
+
+func (d *DebitNoteDialog) refreshItems() {
+	d.itemsContainer.RemoveAll()
+	for i, item := range d.items {
+		idx := i
+		taxName := fmt.Sprintf("Código %d", item.TaxRate)
+		if rate, err := d.taxRates.Find(item.TaxRate, time.Now()); err == nil {
+			taxName = rate.Label()
+		}
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			d.items = append(d.items[:idx], d.items[idx+1:]...)
+			d.refreshItems()
+		})
+		d.itemsContainer.Add(container.NewBorder(nil, nil, nil, removeBtn, container.NewGridWithColumns(3,
+			widget.NewLabel(item.Description),
+			widget.NewLabel(fmt.Sprintf("$%.2f", item.Subtotal)),
+			widget.NewLabel(taxName),
+		)))
+	}
+	tx, _ := d.buildTransaction()
+	d.totalLabel.SetText(fmt.Sprintf("Total: $%.2f", tx.Amount))
+}
+
+// buildTransaction arma el ingreso del cargo con el mismo cliente, cuenta y categoría de la factura;
+// el IVA se calcula con las tarifas vigentes hoy, que es la fecha del cargo.
+func (d *DebitNoteDialog) buildTransaction() (*domain.Transaction, error) {
+	originalID := d.original.ID
+	tx := &domain.Transaction{
+		Description:          fmt.Sprintf("Nota de Débito - Factura %s", d.original.TransactionNumber),
+		TransactionDate:      time.Now(),
+		AccountID:            d.original.AccountID,
+		CategoryID:           d.original.CategoryID,
+		TaxPayerID:           d.original.TaxPayerID,
+		RelatedTransactionID: &originalID,
+		Items:                d.items,
+	}
+	err := tx.RecalculateTotals(d.taxRates)
+	return tx, err
+}
+
+func (d *DebitNoteDialog) emit() {
+	if len(d.items) == 0 {
+		dialog.ShowError(errors.New("agregue al menos un motivo"), d.parent)
+		return
+	}
+
+	tx, err := d.buildTransaction()
+	if err != nil {
+		dialog.ShowError(err, d.parent)
+		return
+	}
+	password := d.passEntry.Text
+
+	componets.HandleLongRunningOperation(d.parent, "Emitiendo Nota de Débito al SRI...", func(ctx context.Context) error {
+		if err := d.txService.CreateTransaction(ctx, tx, d.currentUser); err != nil {
+			return err
+		}
+		// Si el envío falla el cargo queda registrado y se puede re-emitir desde sus detalles
+		if _, err := d.sriService.EmitirNotaDebito(domain.ContextWithUser(ctx, &d.currentUser), tx.ID, d.original.ID, password); err != nil {
+			return fmt.Errorf("el cargo %s fue registrado, pero la nota de débito no se emitió: %w", tx.TransactionNumber, err)
+		}
+		return nil
+	}, func() {
+		dialog.ShowInformation("Nota de Débito", "La nota de débito fue enviada al SRI.", d.parent)
+		if d.onEmitted != nil {
+			d.onEmitted()
+		}
+	})
+}
+
+func parseDebitNoteItem(reason, value string, rate domain.TaxRate) (domain.TransactionItem, error) {
+	item := domain.TransactionItem{
+		Description: strings.TrimSpace(reason),
+		Quantity:    1,
+	}
+	if item.Description == "" {
+		return item, errors.New("ingrese la razón del cargo")
+	}
+
+	val, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || val <= 0 {
+		return item, errors.New("el valor no es válido")
+	}
+	item.UnitPrice = math.Round(val*100) / 100
+	item.Subtotal = item.UnitPrice
+	item.TaxRate = rate.Code
+	return item, nil
+}
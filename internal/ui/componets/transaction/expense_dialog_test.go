@@ -56,6 +56,12 @@ func (m *MockTransactionService) UpdateTransaction(ctx context.Context, tx *doma
 func (m *MockTransactionService) VoidTransaction(ctx context.Context, id int, user domain.User) (int, error) {
 	return 0, nil
 }
+func (m *MockTransactionService) PartialVoidTransaction(ctx context.Context, id int, returned []domain.ReturnedItem, user domain.User) (int, error) {
+	return 0, nil
+}
+func (m *MockTransactionService) GetCreditedAmount(ctx context.Context, id int) (float64, error) {
+	return 0, nil
+}
 func (m *MockTransactionService) RevertVoidTransaction(ctx context.Context, id int) error {
 	return nil
 }
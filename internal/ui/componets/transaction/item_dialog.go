@@ -2,9 +2,12 @@ package transaction
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/nelsonmarro/verith/internal/domain"
@@ -18,19 +21,38 @@ type ItemDialog struct {
 	descEntry      *widget.Entry
 	qtyEntry       *widget.Entry
 	priceEntry     *widget.Entry
+	discountEntry  *widget.Entry
+	discountType   *widget.RadioGroup
 	taxSelect      *widget.Select
+	iceCodeEntry   *widget.Entry // Código de porcentaje del ICE; vacío si el ítem no lo grava
+	iceRateEntry   *widget.Entry
+	iceSpecific    *widget.Check
+	irbpnrCheck    *widget.Check
 	defaultTaxRate int
+	taxRates       domain.TaxRateTable // Tarifas vigentes, en el orden de las opciones de taxSelect
 }
 
-func NewItemDialog(parent fyne.Window, onSave func(domain.TransactionItem), defaultTax int) *ItemDialog {
+// NewItemDialog crea el diálogo de ítem; rates son las tarifas de IVA vigentes que se ofrecen.
+func NewItemDialog(parent fyne.Window, onSave func(domain.TransactionItem), defaultTax int, rates domain.TaxRateTable) *ItemDialog {
+	options := make([]string, len(rates))
+	for i, r := range rates {
+		options[i] = r.Label()
+	}
 	return &ItemDialog{
 		parent:         parent,
 		onSave:         onSave,
 		descEntry:      widget.NewEntry(),
 		qtyEntry:       widget.NewEntry(),
 		priceEntry:     widget.NewEntry(),
-		taxSelect:      widget.NewSelect([]string{"IVA 15%", "IVA 13%", "IVA 8%", "IVA 5%", "IVA 0%", "No Objeto (6)", "Exento (7)"}, nil),
+		discountEntry:  widget.NewEntry(),
+		discountType:   widget.NewRadioGroup([]string{"$", "%"}, nil),
+		taxSelect:      widget.NewSelect(options, nil),
+		iceCodeEntry:   widget.NewEntry(),
+		iceRateEntry:   widget.NewEntry(),
+		iceSpecific:    widget.NewCheck("Tarifa específica por unidad", nil),
+		irbpnrCheck:    widget.NewCheck(fmt.Sprintf("Botellas plásticas (IRBPNR $%.2f c/u)", domain.IRBPNRRate), nil),
 		defaultTaxRate: defaultTax,
+		taxRates:       rates,
 	}
 }
 
@@ -51,22 +73,21 @@ func (d *ItemDialog) Show() {
 			return
 		}
 
-		taxRate := 0
-		switch d.taxSelect.Selected {
-		case "IVA 15%":
-			taxRate = 4
-		case "IVA 13%":
-			taxRate = 10
-		case "IVA 8%":
-			taxRate = 8
-		case "IVA 5%":
-			taxRate = 5
-		case "IVA 0%":
-			taxRate = 0
-		case "No Objeto (6)":
-			taxRate = 6
-		case "Exento (7)":
-			taxRate = 7
+		discount, err := d.parseDiscount(qty * price)
+		if err != nil {
+			dialog.ShowError(err, d.parent)
+			return
+		}
+
+		taxes, err := d.parseItemTaxes()
+		if err != nil {
+			dialog.ShowError(err, d.parent)
+			return
+		}
+
+		taxRate := domain.TaxRateCodeZero
+		if i := d.taxSelect.SelectedIndex(); i >= 0 {
+			taxRate = d.taxRates[i].Code
 		}
 
 		item := domain.TransactionItem{
@@ -74,14 +95,16 @@ func (d *ItemDialog) Show() {
 			Quantity:    qty,
 			UnitPrice:   price,
 			TaxRate:     taxRate,
-			Subtotal:    qty * price,
+			Discount:    discount,
+			Taxes:       taxes,
 		}
+		item.Subtotal = item.GrossAmount() - discount
 
 		d.onSave(item)
 	}, d.parent)
 
 	// Force a comfortable size
-	dlg.Resize(fyne.NewSize(400, 300))
+	dlg.Resize(fyne.NewSize(420, 460))
 	dlg.Show()
 }
 
@@ -90,6 +113,7 @@ func (d *ItemDialog) buildForm() *widget.Form {
 		widget.NewFormItem("Descripción", d.descEntry),
 		widget.NewFormItem("Cantidad", d.qtyEntry),
 		widget.NewFormItem("Precio Unitario", d.priceEntry),
+		widget.NewFormItem("Descuento", container.NewBorder(nil, nil, nil, d.discountType, d.discountEntry)),
 		widget.NewFormItem("Impuesto", d.taxSelect),
 	}
 
@@ -106,41 +130,98 @@ func (d *ItemDialog) buildForm() *widget.Form {
 		items = append(items, widget.NewFormItem("", manualTaxCheck))
 	}
 
+	items = append(items,
+		widget.NewFormItem("ICE", container.NewGridWithColumns(2, d.iceCodeEntry, d.iceRateEntry)),
+		widget.NewFormItem("", d.iceSpecific),
+		widget.NewFormItem("", d.irbpnrCheck),
+	)
+
 	return widget.NewForm(items...)
 }
 
+// parseDiscount convierte el descuento ingresado (en dólares o porcentaje) a dólares redondeados.
+func (d *ItemDialog) parseDiscount(gross float64) (float64, error) {
+	if d.discountEntry.Text == "" {
+		return 0, nil
+	}
+	val, err := strconv.ParseFloat(d.discountEntry.Text, 64)
+	if err != nil || val < 0 {
+		return 0, fmt.Errorf("el descuento no es válido")
+	}
+	if d.discountType.Selected == "%" {
+		if val > 100 {
+			return 0, fmt.Errorf("el porcentaje de descuento no puede superar el 100%%")
+		}
+		val = gross * val / 100
+	}
+	discount := math.Round(val*100) / 100
+	if discount > math.Round(gross*100)/100 {
+		return 0, fmt.Errorf("el descuento supera el valor del ítem")
+	}
+	return discount, nil
+}
+
+// parseItemTaxes arma el ICE y el IRBPNR marcados en el diálogo. El valor se calcula al recalcular
+// los totales de la transacción.
+func (d *ItemDialog) parseItemTaxes() ([]domain.ItemTax, error) {
+	var taxes []domain.ItemTax
+	if code := strings.TrimSpace(d.iceCodeEntry.Text); code != "" {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(d.iceRateEntry.Text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("la tarifa del ICE no es válida")
+		}
+		taxes = append(taxes, domain.ItemTax{
+			TaxCode:        domain.ItemTaxICE,
+			PercentageCode: code,
+			Rate:           rate,
+			Specific:       d.iceSpecific.Checked,
+		})
+	}
+	if d.irbpnrCheck.Checked {
+		taxes = append(taxes, domain.ItemTax{
+			TaxCode:        domain.ItemTaxIRBPNR,
+			PercentageCode: "5001",
+			Rate:           domain.IRBPNRRate,
+			Specific:       true,
+		})
+	}
+	for _, tax := range taxes {
+		if err := tax.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return taxes, nil
+}
+
 func (d *ItemDialog) configureWidgets() {
 	d.descEntry.SetPlaceHolder("Descripción del producto/servicio")
 	d.qtyEntry.SetText("1")
 	d.priceEntry.SetPlaceHolder("0.00")
-	d.taxSelect.SetSelected("IVA 15%") // Default global
+	d.discountEntry.SetPlaceHolder("0.00")
+	d.discountType.Horizontal = true
+	d.discountType.SetSelected("$")
+	d.iceCodeEntry.SetPlaceHolder("Código (ej. 3610)")
+	d.iceRateEntry.SetPlaceHolder("Tarifa")
+	// Default global: la tarifa general vigente, que InForce deja entre las gravadas
+	for i, r := range d.taxRates {
+		if r.General {
+			d.taxSelect.SetSelectedIndex(i)
+			break
+		}
+	}
 
 	d.applyDefaultTax()
 }
 
 func (d *ItemDialog) applyDefaultTax() {
-	if d.defaultTaxRate >= 0 {
-		var taxStr string
-		switch d.defaultTaxRate {
-		case 4:
-			taxStr = "IVA 15%"
-		case 10:
-			taxStr = "IVA 13%"
-		case 8:
-			taxStr = "IVA 8%"
-		case 5:
-			taxStr = "IVA 5%"
-		case 0:
-			taxStr = "IVA 0%"
-		case 6:
-			taxStr = "No Objeto (6)"
-		case 7:
-			taxStr = "Exento (7)"
-		}
-
-		if taxStr != "" {
-			d.taxSelect.SetSelected(taxStr)
+	if d.defaultTaxRate < 0 {
+		return
+	}
+	for i, r := range d.taxRates {
+		if r.Code == d.defaultTaxRate {
+			d.taxSelect.SetSelectedIndex(i)
 			d.taxSelect.Disable()
+			return
 		}
 	}
 }
@@ -2,6 +2,7 @@ package transaction
 
 import (
 	"testing"
+	"time"
 
 	"fyne.io/fyne/v2/test"
 	"github.com/nelsonmarro/verith/internal/domain"
@@ -11,9 +12,10 @@ import (
 func TestItemDialog_DefaultTaxLogic(t *testing.T) {
 	app := test.NewApp()
 	win := app.NewWindow("Test")
+	rates := testTaxRates.InForce(time.Now())
 
 	t.Run("Default Tax 15% (Code 4)", func(t *testing.T) {
-		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 4)
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 4, rates)
 		dlg.configureWidgets() 
 		
 		assert.Equal(t, "IVA 15%", dlg.taxSelect.Selected)
@@ -21,7 +23,7 @@ func TestItemDialog_DefaultTaxLogic(t *testing.T) {
 	})
 
 	t.Run("Default Tax 0% (Code 0)", func(t *testing.T) {
-		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 0)
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 0, rates)
 		dlg.configureWidgets()
 		
 		assert.Equal(t, "IVA 0%", dlg.taxSelect.Selected)
@@ -29,7 +31,7 @@ func TestItemDialog_DefaultTaxLogic(t *testing.T) {
 	})
 
 	t.Run("Default Tax Exempt (Code 7)", func(t *testing.T) {
-		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 7)
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 7, rates)
 		dlg.configureWidgets()
 		
 		assert.Equal(t, "Exento (7)", dlg.taxSelect.Selected)
@@ -37,7 +39,7 @@ func TestItemDialog_DefaultTaxLogic(t *testing.T) {
 	})
 
 	t.Run("Default Tax No Object (Code 6)", func(t *testing.T) {
-		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 6)
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 6, rates)
 		dlg.configureWidgets()
 		
 		assert.Equal(t, "No Objeto (6)", dlg.taxSelect.Selected)
@@ -45,11 +47,87 @@ func TestItemDialog_DefaultTaxLogic(t *testing.T) {
 	})
 
 	t.Run("No Default Tax (Code -1)", func(t *testing.T) {
-		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, -1)
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, -1, rates)
 		dlg.configureWidgets()
 		
 		// Default UI behavior is usually 15% selected but ENABLED
 		assert.Equal(t, "IVA 15%", dlg.taxSelect.Selected, "Should default to 15% if no preference")
 		assert.False(t, dlg.taxSelect.Disabled(), "Should be enabled if no preference")
 	})
-}
\ No newline at end of file
+}
+func TestItemDialog_TaxOptionsFromCatalog(t *testing.T) {
+	app := test.NewApp()
+	win := app.NewWindow("Test")
+
+	t.Run("Offers only the rates in force on the date", func(t *testing.T) {
+		rates := testTaxRates.InForce(time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC))
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, -1, rates)
+		dlg.configureWidgets()
+
+		assert.Equal(t, []string{"IVA 12%", "IVA 8%", "IVA 0%", "No Objeto (6)", "Exento (7)"}, dlg.taxSelect.Options)
+		assert.Equal(t, "IVA 12%", dlg.taxSelect.Selected, "Should default to the general rate of the period")
+	})
+
+	t.Run("Ignores a default code that is not in force", func(t *testing.T) {
+		rates := testTaxRates.InForce(time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC))
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, 4, rates)
+		dlg.configureWidgets()
+
+		assert.Equal(t, "IVA 12%", dlg.taxSelect.Selected)
+		assert.False(t, dlg.taxSelect.Disabled())
+	})
+}
+
+func TestItemDialog_ItemTaxes(t *testing.T) {
+	app := test.NewApp()
+	win := app.NewWindow("Test")
+	rates := testTaxRates.InForce(time.Now())
+
+	t.Run("Builds the ICE and IRBPNR of the item", func(t *testing.T) {
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, -1, rates)
+		dlg.configureWidgets()
+		dlg.iceCodeEntry.SetText("3610")
+		dlg.iceRateEntry.SetText("20")
+		dlg.irbpnrCheck.SetChecked(true)
+
+		taxes, err := dlg.parseItemTaxes()
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.ItemTax{
+			{TaxCode: domain.ItemTaxICE, PercentageCode: "3610", Rate: 20},
+			{TaxCode: domain.ItemTaxIRBPNR, PercentageCode: "5001", Rate: domain.IRBPNRRate, Specific: true},
+		}, taxes)
+	})
+
+	t.Run("Rejects an invalid ICE code", func(t *testing.T) {
+		dlg := NewItemDialog(win, func(item domain.TransactionItem) {}, -1, rates)
+		dlg.configureWidgets()
+		dlg.iceCodeEntry.SetText("36")
+		dlg.iceRateEntry.SetText("20")
+
+		_, err := dlg.parseItemTaxes()
+		assert.ErrorContains(t, err, "4 dígitos")
+	})
+}
+
+// testTaxRates es el catálogo de tarifas de IVA que siembra la migración de tax_rates.
+var testTaxRates = func() domain.TaxRateTable {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	until := func(year int, month time.Month, day int) *time.Time {
+		d := date(year, month, day)
+		return &d
+	}
+	return domain.TaxRateTable{
+		{Code: 0, Percentage: 0, Description: "0%", ValidFrom: date(2000, 1, 1)},
+		{Code: 2, Percentage: 12, Description: "12%", General: true, ValidFrom: date(2000, 1, 1), ValidTo: until(2016, 5, 31)},
+		{Code: 3, Percentage: 14, Description: "14%", General: true, ValidFrom: date(2016, 6, 1), ValidTo: until(2017, 5, 31)},
+		{Code: 2, Percentage: 12, Description: "12%", General: true, ValidFrom: date(2017, 6, 1), ValidTo: until(2024, 3, 31)},
+		{Code: 4, Percentage: 15, Description: "15%", General: true, ValidFrom: date(2024, 4, 1)},
+		{Code: 5, Percentage: 5, Description: "5%", ValidFrom: date(2024, 4, 1)},
+		{Code: 6, Percentage: 0, Description: "No objeto de impuesto", ValidFrom: date(2000, 1, 1)},
+		{Code: 7, Percentage: 0, Description: "Exento de IVA", ValidFrom: date(2000, 1, 1)},
+		{Code: 8, Percentage: 8, Description: "IVA diferenciado (turismo)", ValidFrom: date(2023, 1, 1)},
+		{Code: 10, Percentage: 13, Description: "13%", ValidFrom: date(2025, 1, 1)},
+	}
+}()
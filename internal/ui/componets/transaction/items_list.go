@@ -2,6 +2,7 @@ package transaction
 
 import (
 	"fmt"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -14,15 +15,17 @@ import (
 type ItemsListManager struct {
 	items          []domain.TransactionItem
 	defaultTaxRate int
+	taxRates       domain.TaxRateTable
 	container      *fyne.Container
 	parent         fyne.Window
 	onUpdate       func(items []domain.TransactionItem) // Callback cuando cambia la lista
 }
 
-func NewItemsListManager(defaultTaxRate int, parent fyne.Window, onUpdate func([]domain.TransactionItem)) *ItemsListManager {
+func NewItemsListManager(defaultTaxRate int, taxRates domain.TaxRateTable, parent fyne.Window, onUpdate func([]domain.TransactionItem)) *ItemsListManager {
 	m := &ItemsListManager{
 		items:          make([]domain.TransactionItem, 0),
 		defaultTaxRate: defaultTaxRate,
+		taxRates:       taxRates,
 		parent:         parent,
 		onUpdate:       onUpdate,
 	}
@@ -60,13 +63,21 @@ func (m *ItemsListManager) refreshList() {
 		index := i
 		item := m.items[i]
 
-		desc := widget.NewLabel(item.Description)
+		descText := item.Description
+		for _, tax := range item.Taxes {
+			descText += " [" + tax.Name() + "]"
+		}
+		desc := widget.NewLabel(descText)
 		desc.Truncation = fyne.TextTruncateEllipsis
 
 		qty := widget.NewLabel(fmt.Sprintf("%.2f", item.Quantity))
 		qty.Alignment = fyne.TextAlignCenter
 
-		price := widget.NewLabel(fmt.Sprintf("$%.2f", item.UnitPrice))
+		priceText := fmt.Sprintf("$%.2f", item.UnitPrice)
+		if item.Discount > 0 {
+			priceText += fmt.Sprintf(" (-$%.2f)", item.Discount)
+		}
+		price := widget.NewLabel(priceText)
 		price.Alignment = fyne.TextAlignTrailing
 
 		deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
@@ -93,6 +104,6 @@ func (m *ItemsListManager) showAddItemDialog() {
 	d := NewItemDialog(m.parent, func(item domain.TransactionItem) {
 		m.items = append(m.items, item)
 		m.refreshList()
-	}, m.defaultTaxRate)
+	}, m.defaultTaxRate, m.taxRates.InForce(time.Now()))
 	d.Show()
 }
@@ -0,0 +1,167 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/ui/componets"
+)
+
+// PartialCreditNoteDialog registra la devolución de algunos ítems de una factura autorizada
+// y emite la Nota de Crédito (04) solo por el valor devuelto.
+type PartialCreditNoteDialog struct {
+	parent      fyne.Window
+	original    *domain.Transaction
+	txService   TransactionService
+	sriService  SriService
+	currentUser domain.User
+	onEmitted   func()
+
+	qtyEntries  []*widget.Entry
+	motivoEntry *widget.Entry
+	passEntry   *widget.Entry
+}
+
+func NewPartialCreditNoteDialog(
+	parent fyne.Window,
+	original *domain.Transaction,
+	txService TransactionService,
+	sriService SriService,
+	currentUser domain.User,
+	onEmitted func(),
+) *PartialCreditNoteDialog {
+	return &PartialCreditNoteDialog{
+		parent:      parent,
+		original:    original,
+		txService:   txService,
+		sriService:  sriService,
+		currentUser: currentUser,
+		onEmitted:   onEmitted,
+	}
+}
+
+func (d *PartialCreditNoteDialog) Show() {
+	var credited float64
+	componets.HandleLongRunningOperation(d.parent, "Consultando devoluciones previas...", func(ctx context.Context) error {
+		var err error
+		credited, err = d.txService.GetCreditedAmount(ctx, d.original.ID)
+		return err
+	}, func() {
+		d.showForm(credited)
+	})
+}
+
+func (d *PartialCreditNoteDialog) showForm(credited float64) {
+	header := container.NewGridWithColumns(4,
+		widget.NewLabelWithStyle("Descripción", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Vendido", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("P. Unitario", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("A Devolver", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	itemsContainer := container.NewVBox(header)
+
+	d.qtyEntries = make([]*widget.Entry, len(d.original.Items))
+	for i, item := range d.original.Items {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder("0")
+		d.qtyEntries[i] = entry
+		itemsContainer.Add(container.NewGridWithColumns(4,
+			widget.NewLabel(item.Description),
+			widget.NewLabel(strconv.FormatFloat(item.Quantity, 'f', -1, 64)),
+			widget.NewLabel(fmt.Sprintf("$%.2f", item.UnitPrice)),
+			entry,
+		))
+	}
+
+	d.motivoEntry = widget.NewEntry()
+	d.motivoEntry.SetPlaceHolder("Devolución de mercadería")
+	d.passEntry = widget.NewPasswordEntry()
+
+	info := widget.NewForm(
+		widget.NewFormItem("Factura:", widget.NewLabelWithStyle(d.original.TransactionNumber, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})),
+		widget.NewFormItem("Total Factura:", widget.NewLabel(fmt.Sprintf("$%.2f", d.original.Amount))),
+		widget.NewFormItem("Ya Acreditado:", widget.NewLabel(fmt.Sprintf("$%.2f", credited))),
+	)
+
+	content := container.NewVBox(
+		info,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Ítems devueltos", fyne.TextAlignLeading, fyne.TextStyle{Italic: true}),
+		itemsContainer,
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem("Motivo (NC):", d.motivoEntry),
+			widget.NewFormItem("Contraseña Firma:", d.passEntry),
+		),
+	)
+
+	dlg := dialog.NewCustomConfirm("Devolución Parcial", "Emitir", "Cancelar", container.NewVScroll(content), func(confirm bool) {
+		if confirm {
+			d.emit()
+		}
+	}, d.parent)
+	dlg.Resize(fyne.NewSize(650, 500))
+	dlg.Show()
+}
+
+// returnedItems lee las cantidades ingresadas; las filas vacías o en cero se omiten.
+func (d *PartialCreditNoteDialog) returnedItems() ([]domain.ReturnedItem, error) {
+	var returned []domain.ReturnedItem
+	for i, entry := range d.qtyEntries {
+		text := strings.TrimSpace(entry.Text)
+		if text == "" {
+			continue
+		}
+		qty, err := strconv.ParseFloat(text, 64)
+		if err != nil || qty < 0 {
+			return nil, fmt.Errorf("la cantidad de %q no es válida", d.original.Items[i].Description)
+		}
+		if qty == 0 {
+			continue
+		}
+		returned = append(returned, domain.ReturnedItem{ItemID: d.original.Items[i].ID, Quantity: qty})
+	}
+	if len(returned) == 0 {
+		return nil, errors.New("ingrese la cantidad devuelta de al menos un ítem")
+	}
+	return returned, nil
+}
+
+func (d *PartialCreditNoteDialog) emit() {
+	returned, err := d.returnedItems()
+	if err != nil {
+		dialog.ShowError(err, d.parent)
+		return
+	}
+
+	motivo := strings.TrimSpace(d.motivoEntry.Text)
+	if motivo == "" {
+		motivo = "Devolución de mercadería"
+	}
+	password := d.passEntry.Text
+
+	componets.HandleLongRunningOperation(d.parent, "Emitiendo Nota de Crédito al SRI...", func(ctx context.Context) error {
+		reversalID, err := d.txService.PartialVoidTransaction(ctx, d.original.ID, returned, d.currentUser)
+		if err != nil {
+			return err
+		}
+		// La devolución queda registrada; si el SRI falla se re-emite desde sus detalles
+		if _, err := d.sriService.EmitirNotaCredito(domain.ContextWithUser(ctx, &d.currentUser), reversalID, d.original.ID, motivo, password); err != nil {
+			return fmt.Errorf("la devolución fue registrada, pero la nota de crédito no se emitió: %w", err)
+		}
+		return nil
+	}, func() {
+		dialog.ShowInformation("Nota de Crédito", "La nota de crédito parcial fue enviada al SRI.", d.parent)
+		if d.onEmitted != nil {
+			d.onEmitted()
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+var paymentTimeUnits = []string{"dias", "meses"}
+
+// PaymentsListManager maneja el desglose de formas de pago de una factura en memoria.
+// Si queda vacío la factura se emite con 01 (sin utilización del sistema financiero) por el total.
+type PaymentsListManager struct {
+	payments     []domain.TransactionPayment
+	total        float64
+	container    *fyne.Container
+	pendingLabel *widget.Label
+	parent       fyne.Window
+}
+
+func NewPaymentsListManager(parent fyne.Window) *PaymentsListManager {
+	return &PaymentsListManager{
+		parent:       parent,
+		container:    container.NewVBox(),
+		pendingLabel: widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{Italic: true}),
+	}
+}
+
+func (m *PaymentsListManager) GetContent() fyne.CanvasObject {
+	options := make([]string, len(domain.PaymentMethods))
+	for i, pm := range domain.PaymentMethods {
+		options[i] = fmt.Sprintf("%s - %s", pm.Code, pm.Name)
+	}
+	methodSelect := widget.NewSelect(options, nil)
+	methodSelect.SetSelectedIndex(0)
+
+	amountEntry := widget.NewEntry()
+	amountEntry.SetPlaceHolder("Valor")
+	termEntry := widget.NewEntry()
+	termEntry.SetPlaceHolder("Plazo (opcional)")
+	unitSelect := widget.NewSelect(paymentTimeUnits, nil)
+	unitSelect.SetSelected(paymentTimeUnits[0])
+
+	addBtn := widget.NewButtonWithIcon("Agregar", theme.ContentAddIcon(), func() {
+		code := domain.PaymentMethods[methodSelect.SelectedIndex()].Code
+		payment, err := parsePayment(code, amountEntry.Text, termEntry.Text, unitSelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, m.parent)
+			return
+		}
+		m.payments = append(m.payments, payment)
+		amountEntry.SetText("")
+		termEntry.SetText("")
+		m.refreshList()
+	})
+
+	inputRow := container.NewBorder(nil, nil, nil, addBtn,
+		container.NewGridWithColumns(4, methodSelect, amountEntry, termEntry, unitSelect))
+
+	m.refreshList()
+	return container.NewVBox(inputRow, m.container, m.pendingLabel)
+}
+
+// SetTotal actualiza el total de la factura contra el que se compara el desglose.
+func (m *PaymentsListManager) SetTotal(total float64) {
+	m.total = total
+	m.refreshPending()
+}
+
+// Payments devuelve el desglose ingresado (vacío si el usuario no agregó formas de pago).
+func (m *PaymentsListManager) Payments() []domain.TransactionPayment {
+	return m.payments
+}
+
+func (m *PaymentsListManager) refreshList() {
+	m.container.RemoveAll()
+	for i, p := range m.payments {
+		idx := i
+		term := "Contado"
+		if p.Term > 0 {
+			term = fmt.Sprintf("%d %s", p.Term, p.TimeUnit)
+		}
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			m.payments = append(m.payments[:idx], m.payments[idx+1:]...)
+			m.refreshList()
+		})
+		m.container.Add(container.NewBorder(nil, nil, nil, removeBtn, container.NewGridWithColumns(3,
+			widget.NewLabel(p.PaymentMethod),
+			widget.NewLabel(fmt.Sprintf("$%.2f", p.Amount)),
+			widget.NewLabel(term),
+		)))
+	}
+	m.refreshPending()
+}
+
+func (m *PaymentsListManager) refreshPending() {
+	if len(m.payments) == 0 {
+		m.pendingLabel.SetText("Sin desglose: se emite todo como 01 - Sin utilización del sistema financiero")
+		return
+	}
+	var sum float64
+	for _, p := range m.payments {
+		sum += p.Amount
+	}
+	m.pendingLabel.SetText(fmt.Sprintf("Pendiente por asignar: $%.2f", math.Round((m.total-sum)*100)/100))
+}
+
+func parsePayment(code, amount, term, unit string) (domain.TransactionPayment, error) {
+	p := domain.TransactionPayment{PaymentMethod: code}
+
+	val, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+	if err != nil || val <= 0 {
+		return p, errors.New("el valor no es válido")
+	}
+	p.Amount = math.Round(val*100) / 100
+
+	if t := strings.TrimSpace(term); t != "" {
+		days, err := strconv.Atoi(t)
+		if err != nil || days < 0 {
+			return p, errors.New("el plazo debe ser un número entero")
+		}
+		p.Term = days
+		if days > 0 {
+			p.TimeUnit = unit
+		}
+	}
+	return p, nil
+}
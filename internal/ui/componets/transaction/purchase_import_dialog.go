@@ -0,0 +1,290 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/ui/componets/category"
+)
+
+// PurchaseImportDialog registra como gastos las facturas electrónicas de proveedores a partir de
+// sus XML autorizados. Se puede elegir un archivo, una carpeta con todos los XML del mes o el TXT de
+// comprobantes recibidos del portal del SRI, en cuyo caso los XML se descargan del SRI.
+type PurchaseImportDialog struct {
+	mainWin         fyne.Window
+	logger          *log.Logger
+	importService   PurchaseImportService
+	categoryService CategoryService
+	callbackAction  func()
+
+	categoryLabel *widget.Label
+	sourceLabel   *widget.Label
+
+	accountID        int
+	selectedCategory *domain.Category
+	files            []string
+	reportPath       string
+	currentUser      domain.User
+}
+
+func NewPurchaseImportDialog(
+	win fyne.Window,
+	l *log.Logger,
+	is PurchaseImportService,
+	cs CategoryService,
+	callback func(),
+	accountID int,
+	currentUser domain.User,
+) *PurchaseImportDialog {
+	return &PurchaseImportDialog{
+		mainWin:         win,
+		logger:          l,
+		importService:   is,
+		categoryService: cs,
+		callbackAction:  callback,
+		accountID:       accountID,
+		currentUser:     currentUser,
+		categoryLabel:   widget.NewLabel("Seleccione Categoría"),
+		sourceLabel:     widget.NewLabel("Ningún archivo seleccionado"),
+	}
+}
+
+func (d *PurchaseImportDialog) Show() {
+	searchCategoryBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		searchDialog := category.NewCategorySearchDialog(
+			d.mainWin,
+			d.logger,
+			d.categoryService,
+			func(cat *domain.Category) {
+				d.selectedCategory = cat
+				d.categoryLabel.SetText(cat.Name)
+			},
+		)
+		searchDialog.SetFilterType(domain.Outcome)
+		searchDialog.Show()
+	})
+
+	fileBtn := widget.NewButtonWithIcon("Archivo", theme.FileIcon(), func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, d.mainWin)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			defer reader.Close()
+			d.files = []string{reader.URI().Path()}
+			d.reportPath = ""
+			d.sourceLabel.SetText(reader.URI().Name())
+		}, d.mainWin)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".xml"}))
+		fd.Show()
+	})
+
+	folderBtn := widget.NewButtonWithIcon("Carpeta", theme.FolderOpenIcon(), func() {
+		fd := dialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, d.mainWin)
+				return
+			}
+			if dir == nil {
+				return
+			}
+			files, err := xmlFilesIn(dir.Path())
+			if err != nil {
+				dialog.ShowError(err, d.mainWin)
+				return
+			}
+			if len(files) == 0 {
+				dialog.ShowInformation("Sin archivos", "La carpeta no contiene archivos XML.", d.mainWin)
+				return
+			}
+			d.files = files
+			d.reportPath = ""
+			d.sourceLabel.SetText(fmt.Sprintf("%s (%d archivos XML)", dir.Name(), len(files)))
+		}, d.mainWin)
+		fd.Show()
+	})
+
+	reportBtn := widget.NewButtonWithIcon("Reporte SRI", theme.DownloadIcon(), func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, d.mainWin)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			defer reader.Close()
+			d.files = nil
+			d.reportPath = reader.URI().Path()
+			d.sourceLabel.SetText(reader.URI().Name() + " (descarga desde el SRI)")
+		}, d.mainWin)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".txt"}))
+		fd.Show()
+	})
+
+	note := widget.NewLabel("Se registra un gasto por cada factura con sus ítems e IVA. El proveedor se crea si no existe y el XML queda como adjunto. Las facturas ya importadas se omiten. Con el reporte de comprobantes recibidos del SRI la descarga se puede retomar cargando el mismo archivo.")
+	note.Wrapping = fyne.TextWrapWord
+	note.TextStyle = fyne.TextStyle{Italic: true}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Categoría", container.NewBorder(nil, nil, nil, searchCategoryBtn, d.categoryLabel)),
+		widget.NewFormItem("Facturas", container.NewBorder(nil, nil, nil, container.NewHBox(fileBtn, folderBtn, reportBtn), d.sourceLabel)),
+	)
+
+	dlg := dialog.NewCustomConfirm("Importar Facturas de Proveedores", "Importar", "Cancelar",
+		container.NewVBox(form, note),
+		func(confirm bool) {
+			if confirm {
+				d.handleSubmit()
+			}
+		}, d.mainWin)
+	dlg.Resize(fyne.NewSize(560, 320))
+	dlg.Show()
+}
+
+func (d *PurchaseImportDialog) handleSubmit() {
+	if d.selectedCategory == nil {
+		dialog.ShowError(errors.New("seleccione la categoría del gasto"), d.mainWin)
+		return
+	}
+	if d.reportPath != "" {
+		d.downloadReport()
+		return
+	}
+	if len(d.files) == 0 {
+		dialog.ShowError(errors.New("seleccione un archivo XML, una carpeta o el reporte del SRI"), d.mainWin)
+		return
+	}
+
+	progress := widget.NewProgressBar()
+	progress.Max = float64(len(d.files))
+	progressDialog := dialog.NewCustomWithoutButtons("Importando facturas...", progress, d.mainWin)
+	progressDialog.Show()
+
+	go func() {
+		var imported, duplicates int
+		var failures []string
+		for i, path := range d.files {
+			_, err := d.importService.ImportSupplierInvoice(context.Background(), path, d.accountID, d.selectedCategory.ID, d.currentUser)
+			switch {
+			case err == nil:
+				imported++
+			case errors.Is(err, domain.ErrPurchaseAlreadyImported):
+				duplicates++
+			default:
+				d.logger.Printf("Error importing supplier invoice %s: %v", path, err)
+				failures = append(failures, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			}
+			done := float64(i + 1)
+			fyne.Do(func() { progress.SetValue(done) })
+		}
+
+		fyne.Do(func() {
+			progressDialog.Hide()
+			d.showSummary(imported, duplicates, failures)
+			if imported > 0 && d.callbackAction != nil {
+				d.callbackAction()
+			}
+		})
+	}()
+}
+
+// downloadReport descarga del SRI las facturas listadas en el reporte de comprobantes recibidos y las importa.
+func (d *PurchaseImportDialog) downloadReport() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	progress := widget.NewProgressBar()
+	status := widget.NewLabel("Leyendo el reporte...")
+	progressDialog := dialog.NewCustom("Descargando comprobantes del SRI...", "Detener", container.NewVBox(status, progress), d.mainWin)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Show()
+
+	go func() {
+		defer cancel()
+		results, err := d.importService.DownloadReceived(ctx, d.reportPath, d.accountID, d.selectedCategory.ID, d.currentUser,
+			func(done, total int, result domain.ReceivedDocumentResult) {
+				fyne.Do(func() {
+					progress.Max = float64(total)
+					progress.SetValue(float64(done))
+					status.SetText(fmt.Sprintf("%d de %d: %s", done, total, result.AccessKey))
+				})
+			})
+
+		var imported, duplicates int
+		var failures []string
+		for _, r := range results {
+			switch r.Status {
+			case domain.ReceivedNew:
+				imported++
+			case domain.ReceivedAlreadyImported:
+				duplicates++
+			case domain.ReceivedFailed:
+				d.logger.Printf("Error downloading received document %s: %s", r.AccessKey, r.Message)
+				failures = append(failures, fmt.Sprintf("%s %s: %s", r.DocumentNumber, r.SupplierName, r.Message))
+			}
+		}
+		if errors.Is(err, context.Canceled) {
+			failures = append(failures, "Descarga detenida: cargue el mismo reporte para continuar.")
+		}
+
+		fyne.Do(func() {
+			progressDialog.Hide()
+			if err != nil && !errors.Is(err, context.Canceled) {
+				dialog.ShowError(err, d.mainWin)
+				return
+			}
+			d.showSummary(imported, duplicates, failures)
+			if imported > 0 && d.callbackAction != nil {
+				d.callbackAction()
+			}
+		})
+	}()
+}
+
+func (d *PurchaseImportDialog) showSummary(imported, duplicates int, failures []string) {
+	summary := fmt.Sprintf("Importadas: %d\nYa registradas (omitidas): %d\nCon errores: %d", imported, duplicates, len(failures))
+	if len(failures) == 0 {
+		dialog.ShowInformation("Importación finalizada", summary, d.mainWin)
+		return
+	}
+
+	details := widget.NewLabel(strings.Join(failures, "\n"))
+	details.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(details)
+	scroll.SetMinSize(fyne.NewSize(520, 200))
+
+	dlg := dialog.NewCustom("Importación finalizada", "Cerrar", container.NewVBox(widget.NewLabel(summary), scroll), d.mainWin)
+	dlg.Show()
+}
+
+// xmlFilesIn lista los archivos .xml de una carpeta (sin subcarpetas) en orden alfabético.
+func xmlFilesIn(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la carpeta: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".xml") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,119 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/ui/componets"
+	"github.com/nelsonmarro/verith/internal/ui/componets/taxpayer"
+)
+
+// PurchaseSettlementDialog emite la Liquidación de Compra (03) de un egreso
+// cuando el proveedor es una persona que no puede facturar.
+type PurchaseSettlementDialog struct {
+	parent     fyne.Window
+	tx         *domain.Transaction
+	sriService SriService
+	taxService TaxPayerService
+	onEmitted  func()
+
+	currentUser domain.User // Queda registrado en la historia del comprobante
+
+	supplier      *domain.TaxPayer
+	supplierLabel *widget.Label
+}
+
+func NewPurchaseSettlementDialog(
+	parent fyne.Window,
+	tx *domain.Transaction,
+	sriService SriService,
+	taxService TaxPayerService,
+	currentUser domain.User,
+	onEmitted func(),
+) *PurchaseSettlementDialog {
+	return &PurchaseSettlementDialog{
+		parent:      parent,
+		tx:          tx,
+		sriService:  sriService,
+		taxService:  taxService,
+		onEmitted:   onEmitted,
+		currentUser: currentUser,
+	}
+}
+
+func (d *PurchaseSettlementDialog) Show() {
+	d.supplierLabel = widget.NewLabel("Seleccione el proveedor...")
+	searchBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		taxpayer.NewSearchDialog(d.parent, log.Default(), d.taxService, func(tp *domain.TaxPayer) {
+			d.supplier = tp
+			d.supplierLabel.SetText(fmt.Sprintf("%s (%s)", tp.Name, tp.Identification))
+		}).Show()
+	})
+	d.loadSupplier()
+
+	passEntry := widget.NewPasswordEntry()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Egreso:", widget.NewLabel(d.tx.Description)),
+		widget.NewFormItem("Total:", widget.NewLabel(fmt.Sprintf("$%.2f", d.tx.Amount))),
+		widget.NewFormItem("Proveedor:", container.NewBorder(nil, nil, nil, searchBtn, d.supplierLabel)),
+		widget.NewFormItem("Contraseña Firma:", passEntry),
+	)
+
+	dlg := dialog.NewCustomConfirm("Emitir Liquidación de Compra", "Emitir", "Cancelar", form, func(confirm bool) {
+		if confirm {
+			d.emit(passEntry.Text)
+		}
+	}, d.parent)
+	dlg.Resize(fyne.NewSize(550, 250))
+	dlg.Show()
+}
+
+// loadSupplier precarga el proveedor registrado en el egreso.
+func (d *PurchaseSettlementDialog) loadSupplier() {
+	if d.tx.TaxPayerID == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		tp, err := d.taxService.GetByID(ctx, *d.tx.TaxPayerID)
+		if err != nil || tp == nil {
+			return
+		}
+		fyne.Do(func() {
+			d.supplier = tp
+			d.supplierLabel.SetText(fmt.Sprintf("%s (%s)", tp.Name, tp.Identification))
+		})
+	}()
+}
+
+func (d *PurchaseSettlementDialog) emit(password string) {
+	if d.supplier == nil {
+		dialog.ShowError(errors.New("seleccione el proveedor"), d.parent)
+		return
+	}
+	ps := &domain.PurchaseSettlement{
+		TransactionID: d.tx.ID,
+		SupplierID:    d.supplier.ID,
+	}
+
+	componets.HandleLongRunningOperation(d.parent, "Emitiendo Liquidación de Compra al SRI...", func(ctx context.Context) error {
+		_, err := d.sriService.EmitirLiquidacionCompra(domain.ContextWithUser(ctx, &d.currentUser), ps, password)
+		return err
+	}, func() {
+		dialog.ShowInformation("Liquidación de Compra", "La liquidación de compra fue enviada al SRI.", d.parent)
+		if d.onEmitted != nil {
+			d.onEmitted()
+		}
+	})
+}
@@ -13,22 +13,32 @@ import (
 	"fyne.io/fyne/v2/widget"
 	"github.com/nelsonmarro/verith/internal/domain"
 	"github.com/nelsonmarro/verith/internal/ui/componets"
+	"github.com/nelsonmarro/verith/internal/ui/componets/shipment"
 )
 
 type SriQueueDialog struct {
 	parent     fyne.Window
 	sriService SriService
 	txService  TransactionService // Needed for details
+	taxService TaxPayerService
+	user       domain.User
+
+	shipmentService shipment.ShipmentService
+	issuerService   IssuerService
 	dialog     dialog.Dialog
 	data       []domain.ElectronicReceipt
 	list       *widget.List
 }
 
-func NewSriQueueDialog(parent fyne.Window, sriService SriService, txService TransactionService) *SriQueueDialog {
+func NewSriQueueDialog(parent fyne.Window, sriService SriService, txService TransactionService, taxService TaxPayerService, shipmentService shipment.ShipmentService, issuerService IssuerService, user domain.User) *SriQueueDialog {
 	return &SriQueueDialog{
-		parent:     parent,
-		sriService: sriService,
-		txService:  txService,
+		parent:          parent,
+		sriService:      sriService,
+		txService:       txService,
+		taxService:      taxService,
+		shipmentService: shipmentService,
+		issuerService:   issuerService,
+		user:            user,
 	}
 }
 
@@ -70,7 +80,7 @@ func (d *SriQueueDialog) Show() {
 			icon := statusBox.Objects[0].(*widget.Icon)
 			lbl := statusBox.Objects[1].(*widget.Label)
 			
-			lbl.SetText(r.SRIStatus)
+			lbl.SetText(string(r.SRIStatus))
 			switch r.SRIStatus {
 			case "RECIBIDA":
 				icon.SetResource(theme.ConfirmIcon())
@@ -86,6 +96,12 @@ func (d *SriQueueDialog) Show() {
 
 	d.list.OnSelected = func(id widget.ListItemID) {
 		r := d.data[id]
+		// Las guías de remisión no tienen transacción; se gestionan desde su propio listado
+		if r.ShipmentID != nil {
+			d.list.Unselect(id)
+			shipment.NewShipmentListDialog(d.parent, d.shipmentService, d.sriService, d.taxService, d.user).Show()
+			return
+		}
 		// Abrir detalles
 		go func() {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -100,7 +116,7 @@ func (d *SriQueueDialog) Show() {
 			tx.ElectronicReceipt = &r
 
 			fyne.Do(func() {
-				detailsDlg := NewDetailsDialog(d.parent, tx, d.txService, d.sriService, func() {
+				detailsDlg := NewDetailsDialog(d.parent, tx, d.txService, d.sriService, d.taxService, d.shipmentService, d.issuerService, d.user, func() {
 					d.loadData() // Recargar lista al cerrar detalles
 				})
 				detailsDlg.Show()
@@ -126,12 +142,16 @@ func (d *SriQueueDialog) Show() {
 	})
 	processBtn.Importance = widget.HighImportance
 
+	checkKeyBtn := widget.NewButtonWithIcon("Verificar Clave", theme.SearchIcon(), func() {
+		componets.ShowAccessKeyChecker(d.parent)
+	})
+
 	infoLabel := widget.NewLabel("El sistema verifica automáticamente cada 5 minutos.")
 	infoLabel.TextStyle = fyne.TextStyle{Italic: true}
 
 	content := container.NewBorder(
 		container.NewVBox(
-			container.NewHBox(refreshBtn, processBtn, layout.NewSpacer(), infoLabel),
+			container.NewHBox(refreshBtn, processBtn, checkKeyBtn, layout.NewSpacer(), infoLabel),
 			header,
 			widget.NewSeparator(),
 		),
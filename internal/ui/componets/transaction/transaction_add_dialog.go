@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -38,15 +41,25 @@ type AddTransactionDialog struct {
 
 	// Tax & Client UI
 	subtotalLabel  *widget.Label
+	discountEntry  *widget.Entry
+	tipCheck       *widget.Check
+	tipLabel       *widget.Label
 	taxAmountLabel *widget.Label
+	otherTaxLabel  *widget.Label // ICE e IRBPNR de los ítems
 	totalLabel     *widget.Label
 
 	// Client Selector
 	taxPayerLabel     *widget.Label
 	searchTaxPayerBtn *widget.Button
 
+	// Punto de emisión de la factura
+	pointSelect *widget.Select
+	points      []domain.EmissionPoint // En el mismo orden que las opciones de pointSelect
+
 	// Maestro-Detalle
-	itemsManager *ItemsListManager
+	itemsManager    *ItemsListManager
+	paymentsManager *PaymentsListManager
+	fieldsEditor    *componets.AdditionalFieldsEditor
 
 	// Data
 	accountID        int
@@ -55,6 +68,8 @@ type AddTransactionDialog struct {
 	attachmentPath   string
 	currentUser      domain.User
 	items            []domain.TransactionItem
+	tipRate          float64 // % de propina; se toma del emisor al abrir el diálogo
+	taxRates         domain.TaxRateTable
 }
 
 // NewAddTransactionDialog creates a new dialog handler.
@@ -85,13 +100,23 @@ func NewAddTransactionDialog(
 		attachmentLabel:  widget.NewLabel("Ninguno"),
 		currentUser:      currentUser,
 		subtotalLabel:    widget.NewLabel("$0.00"),
+		discountEntry:    widget.NewEntry(),
+		tipLabel:         widget.NewLabel("$0.00"),
 		taxAmountLabel:   widget.NewLabel("$0.00"),
+		otherTaxLabel:    widget.NewLabel("$0.00"),
 		totalLabel:       widget.NewLabelWithStyle("$0.00", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		taxPayerLabel:    widget.NewLabel("Consumidor Final"), // Default
+		pointSelect:      widget.NewSelect(nil, nil),
 		items:            make([]domain.TransactionItem, 0),
+		tipRate:          domain.LegalServiceTipRate,
 	}
 
-	d.itemsManager = NewItemsListManager(-1, win, d.handleItemsUpdate)
+	d.itemsManager = NewItemsListManager(-1, d.taxRates, win, d.handleItemsUpdate)
+	d.paymentsManager = NewPaymentsListManager(win)
+
+	d.discountEntry.SetPlaceHolder("0.00")
+	d.discountEntry.OnChanged = func(string) { d.handleItemsUpdate(d.items) }
+	d.tipCheck = widget.NewCheck(fmt.Sprintf("Cobrar propina (%g%%)", d.tipRate), func(bool) { d.handleItemsUpdate(d.items) })
 
 	d.dateEntry.SetText(time.Now().Format(componets.AppDateFormat))
 
@@ -142,18 +167,78 @@ func NewAddTransactionDialog(
 
 func (d *AddTransactionDialog) handleItemsUpdate(items []domain.TransactionItem) {
 	d.items = items
-	var subtotal, tax float64
+	totals, err := d.buildTotals()
+	if err != nil {
+		d.logger.Printf("Error calculando totales: %v", err)
+	}
+
+	d.subtotalLabel.SetText(fmt.Sprintf("$%.2f", totals.Subtotal15+totals.Subtotal0))
+	d.taxAmountLabel.SetText(fmt.Sprintf("$%.2f", totals.TaxAmount))
+	d.otherTaxLabel.SetText(fmt.Sprintf("$%.2f", totals.ICEAmount+totals.IRBPNRAmount))
+	d.tipLabel.SetText(fmt.Sprintf("$%.2f", totals.Tip))
+	d.totalLabel.SetText(fmt.Sprintf("$%.2f", totals.Amount))
+	d.paymentsManager.SetTotal(totals.Amount)
+}
+
+// buildTotals calcula bases, IVA y total con los descuentos de los ítems y el global ingresado,
+// usando las tarifas vigentes en la fecha de la transacción.
+// Un descuento global inválido se ignora aquí; handleSubmit lo rechaza al guardar.
+// Si la propina está marcada se calcula sobre la base ya descontada y se suma al total.
+func (d *AddTransactionDialog) buildTotals() (*domain.Transaction, error) {
+	tx := &domain.Transaction{Items: d.items, TransactionDate: time.Now()}
+	if d.dateEntry.Date != nil {
+		tx.TransactionDate = *d.dateEntry.Date
+	}
+	if discount, err := strconv.ParseFloat(strings.TrimSpace(d.discountEntry.Text), 64); err == nil && discount > 0 {
+		tx.Discount = math.Round(discount*100) / 100
+	}
+	if err := tx.RecalculateTotals(d.taxRates); err != nil {
+		return tx, err
+	}
+	if d.tipCheck.Checked {
+		tx.Tip = tx.TipFor(d.tipRate)
+		if err := tx.RecalculateTotals(d.taxRates); err != nil {
+			return tx, err
+		}
+	}
+	return tx, nil
+}
 
-	for _, item := range items {
-		subtotal += item.Subtotal
-		if item.TaxRate == 4 { // IVA 15%
-			tax += item.Subtotal * 0.15
+// setPointOptions llena el selector de puntos de emisión y propone el del usuario o, si no tiene,
+// el principal del emisor.
+func (d *AddTransactionDialog) setPointOptions(points []domain.EmissionPoint, issuer *domain.Issuer) {
+	d.points = points
+	labels := make([]string, 0, len(points))
+	selected := ""
+	for _, p := range points {
+		label := domain.EmissionPointLabel(p.EstablishmentCode, p.EmissionPointCode)
+		labels = append(labels, label)
+		u := d.currentUser
+		if u.DefaultEstablishmentCode != nil && u.DefaultEmissionPointCode != nil &&
+			*u.DefaultEstablishmentCode == p.EstablishmentCode && *u.DefaultEmissionPointCode == p.EmissionPointCode {
+			selected = label
 		}
+		if selected == "" && issuer != nil &&
+			issuer.EstablishmentCode == p.EstablishmentCode && issuer.EmissionPointCode == p.EmissionPointCode {
+			selected = label
+		}
+	}
+	d.pointSelect.Options = labels
+	if selected == "" && len(labels) > 0 {
+		selected = labels[0]
 	}
+	d.pointSelect.SetSelected(selected)
+}
 
-	d.subtotalLabel.SetText(fmt.Sprintf("$%.2f", subtotal))
-	d.taxAmountLabel.SetText(fmt.Sprintf("$%.2f", tax))
-	d.totalLabel.SetText(fmt.Sprintf("$%.2f", subtotal+tax))
+// selectedPoint devuelve los códigos del punto elegido, o nil si no hay puntos cargados y la venta
+// queda con el punto predeterminado.
+func (d *AddTransactionDialog) selectedPoint() (*string, *string) {
+	i := d.pointSelect.SelectedIndex()
+	if i < 0 || i >= len(d.points) {
+		return nil, nil
+	}
+	p := d.points[i]
+	return &p.EstablishmentCode, &p.EmissionPointCode
 }
 
 // Show creates and displays the Fyne form dialog.
@@ -162,13 +247,33 @@ func (d *AddTransactionDialog) Show() {
 	activeIssuer, err := d.issuerService.GetActive(ctx)
 	
 	defaultTaxRate := 4 // Default fallback: 15%
+	// Los campos propios de la venta comparten el máximo del SRI con los del cliente y los del emisor
+	maxFields := domain.MaxIssuerAdditionalFields
 	if err == nil && activeIssuer != nil {
 		defaultTaxRate = activeIssuer.DefaultTaxRate
+		maxFields -= len(activeIssuer.AdditionalFields)
+		if activeIssuer.DefaultTipRate > 0 {
+			d.tipRate = activeIssuer.DefaultTipRate
+			d.tipCheck.Text = fmt.Sprintf("Cobrar propina (%g%%)", d.tipRate)
+			d.tipCheck.SetChecked(true)
+		}
 	} else {
 		d.logger.Printf("Advertencia: No se encontró emisor activo, usando IVA 15%% por defecto. Error: %v", err)
 	}
+	rates, err := d.issuerService.GetTaxRates(ctx)
+	if err != nil {
+		dialog.ShowError(err, d.mainWin)
+		return
+	}
+	d.taxRates = rates
+	if points, err := d.issuerService.GetInvoicePoints(ctx); err == nil {
+		d.setPointOptions(points, activeIssuer)
+	} else {
+		d.logger.Printf("Advertencia: No se pudieron cargar los puntos de emisión. Error: %v", err)
+	}
 
-	d.itemsManager = NewItemsListManager(defaultTaxRate, d.mainWin, d.handleItemsUpdate)
+	d.itemsManager = NewItemsListManager(defaultTaxRate, d.taxRates, d.mainWin, d.handleItemsUpdate)
+	d.fieldsEditor = componets.NewAdditionalFieldsEditor(d.mainWin, maxFields)
 
 	categoryContainer := container.NewBorder(nil, nil, nil, d.searchCategoryBtn, d.categoryLabel)
 	taxPayerContainer := container.NewBorder(nil, nil, nil, d.searchTaxPayerBtn, d.taxPayerLabel)
@@ -179,13 +284,18 @@ func (d *AddTransactionDialog) Show() {
 		widget.NewFormItem("Cliente", taxPayerContainer),
 		widget.NewFormItem("Fecha", d.dateEntry),
 		widget.NewFormItem("Categoría", categoryContainer),
+		widget.NewFormItem("Punto de Emisión", d.pointSelect),
 		widget.NewFormItem("Adjunto", attachmentContainer),
 	)
 
 	// Summary / Totals
 	summary := widget.NewForm(
+		widget.NewFormItem("Descuento Global ($)", d.discountEntry),
 		widget.NewFormItem("Subtotal", d.subtotalLabel),
 		widget.NewFormItem("IVA", d.taxAmountLabel),
+		widget.NewFormItem("ICE / IRBPNR", d.otherTaxLabel),
+		widget.NewFormItem("", d.tipCheck),
+		widget.NewFormItem("Propina", d.tipLabel),
 		widget.NewFormItem("TOTAL", d.totalLabel),
 	)
 
@@ -197,6 +307,12 @@ func (d *AddTransactionDialog) Show() {
 		d.itemsManager.GetContent(),
 		widget.NewSeparator(),
 		summary,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Formas de Pago (opcional)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		d.paymentsManager.GetContent(),
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Información Adicional (opcional)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		d.fieldsEditor.GetContent(),
 	)
 
 	formDialog := dialog.NewCustomConfirm("Crear Transacción", "Guardar", "Cancelar",
@@ -228,12 +344,26 @@ func (d *AddTransactionDialog) handleSubmit(_ bool) {
 		return
 	}
 
+	if text := strings.TrimSpace(d.discountEntry.Text); text != "" {
+		if v, err := strconv.ParseFloat(text, 64); err != nil || v < 0 {
+			dialog.ShowError(errors.New("el descuento global no es válido"), d.mainWin)
+			return
+		}
+	}
+
 	progressDialog := dialog.NewCustomWithoutButtons("Espere...", widget.NewProgressBarInfinite(), d.mainWin)
 	progressDialog.Show()
 
 	go func() {
 		// Calculate final totals
-		var sub15, sub0, taxTotal, total float64
+		totals, err := d.buildTotals()
+		if err != nil {
+			fyne.Do(func() {
+				progressDialog.Hide()
+				dialog.ShowError(err, d.mainWin)
+			})
+			return
+		}
 		var description string
 
 		for i, item := range d.items {
@@ -241,15 +371,7 @@ func (d *AddTransactionDialog) handleSubmit(_ bool) {
 				description += ", "
 			}
 			description += item.Description
-
-			if item.TaxRate == 4 {
-				sub15 += item.Subtotal
-				taxTotal += item.Subtotal * 0.15
-			} else {
-				sub0 += item.Subtotal
-			}
 		}
-		total = sub15 + sub0 + taxTotal
 
 		var attachmentPathPtr *string
 		if d.attachmentPath != "" {
@@ -265,20 +387,25 @@ func (d *AddTransactionDialog) handleSubmit(_ bool) {
 		defer cancel()
 
 		tx := &domain.Transaction{
-			Description:     description,
-			Amount:          total,
-			TransactionDate: transactionDate,
-			AccountID:       d.accountID,
-			CategoryID:      d.selectedCategory.ID,
-			AttachmentPath:  attachmentPathPtr,
-			Subtotal15:      sub15,
-			Subtotal0:       sub0,
-			TaxAmount:       taxTotal,
-			Items:           d.items,
-			TaxPayerID:      taxPayerID, // Set ID
+			Description:      description,
+			Amount:           totals.Amount,
+			TransactionDate:  transactionDate,
+			AccountID:        d.accountID,
+			CategoryID:       d.selectedCategory.ID,
+			AttachmentPath:   attachmentPathPtr,
+			Subtotal15:       totals.Subtotal15,
+			Subtotal0:        totals.Subtotal0,
+			TaxAmount:        totals.TaxAmount,
+			Discount:         totals.Discount,
+			Tip:              totals.Tip,
+			Items:            d.items,
+			Payments:         d.paymentsManager.Payments(),
+			AdditionalFields: d.fieldsEditor.Fields(),
+			TaxPayerID:       taxPayerID, // Set ID
 		}
+		tx.EstablishmentCode, tx.EmissionPointCode = d.selectedPoint()
 
-		err := d.txService.CreateTransaction(ctx, tx, d.currentUser)
+		err = d.txService.CreateTransaction(ctx, tx, d.currentUser)
 		if err != nil {
 			fyne.Do(func() {
 				progressDialog.Hide()
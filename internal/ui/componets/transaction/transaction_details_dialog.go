@@ -13,10 +13,13 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
 	"github.com/nelsonmarro/verith/internal/ui/componets"
+	"github.com/nelsonmarro/verith/internal/ui/componets/shipment"
 )
 
 type DetailsDialog struct {
@@ -24,8 +27,16 @@ type DetailsDialog struct {
 	tx         *domain.Transaction
 	txService  TransactionService
 	sriService SriService
+	taxService TaxPayerService
 	onChanged  func()
+
+	shipmentService shipment.ShipmentService
+	issuerService   IssuerService // Catálogo de tarifas de IVA para la Nota de Débito
 	dialog     dialog.Dialog // Added reference
+
+	currentUser domain.User // Para registrar el cargo de una Nota de Débito
+
+	events []domain.ReceiptEvent // Historia del comprobante electrónico
 }
 
 func NewDetailsDialog(
@@ -33,14 +44,22 @@ func NewDetailsDialog(
 	tx *domain.Transaction,
 	txService TransactionService,
 	sriService SriService,
+	taxService TaxPayerService,
+	shipmentService shipment.ShipmentService,
+	issuerService IssuerService,
+	currentUser domain.User,
 	onChanged func(), // Added
 ) *DetailsDialog {
 	return &DetailsDialog{
-		parent:     parent,
-		tx:         tx,
-		txService:  txService,
-		sriService: sriService,
-		onChanged:  onChanged, // Added
+		parent:          parent,
+		tx:              tx,
+		txService:       txService,
+		sriService:      sriService,
+		taxService:      taxService,
+		shipmentService: shipmentService,
+		issuerService:   issuerService,
+		currentUser:     currentUser,
+		onChanged:       onChanged, // Added
 	}
 }
 
@@ -74,6 +93,12 @@ func (d *DetailsDialog) Show() {
 		}
 		d.tx.Items = items
 
+		// 3. Historia del comprobante; si falla, el resto de los detalles se muestra igual
+		d.events = nil
+		if d.tx.ElectronicReceipt != nil {
+			d.events, _ = d.sriService.GetReceiptEvents(ctx, d.tx.ElectronicReceipt.AccessKey)
+		}
+
 		fyne.Do(func() {
 			progress.Hide()
 			content := d.buildContent()
@@ -124,18 +149,34 @@ func (d *DetailsDialog) buildContent() fyne.CanvasObject {
 	footer := widget.NewForm(
 		widget.NewFormItem("Subtotal 15%:", widget.NewLabel(fmt.Sprintf("$%.2f", d.tx.Subtotal15))),
 		widget.NewFormItem("Subtotal 0%:", widget.NewLabel(fmt.Sprintf("$%.2f", d.tx.Subtotal0))),
-		widget.NewFormItem("IVA 15%:", widget.NewLabel(fmt.Sprintf("$%.2f", d.tx.TaxAmount))),
+		widget.NewFormItem("IVA:", widget.NewLabel(fmt.Sprintf("$%.2f", d.tx.TaxAmount))),
 		widget.NewFormItem("TOTAL:", widget.NewLabelWithStyle(fmt.Sprintf("$%.2f", d.tx.Amount), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})),
 	)
+	if d.tx.Discount > 0 {
+		footer.Items = append([]*widget.FormItem{
+			widget.NewFormItem("Descuento Global:", widget.NewLabel(fmt.Sprintf("-$%.2f", d.tx.Discount))),
+		}, footer.Items...)
+	}
+	if d.tx.Tip > 0 {
+		// Va antes del TOTAL: la propina no grava IVA pero sí forma parte del importe
+		last := len(footer.Items) - 1
+		footer.Items = append(footer.Items[:last:last],
+			widget.NewFormItem("Propina:", widget.NewLabel(fmt.Sprintf("$%.2f", d.tx.Tip))),
+			footer.Items[last])
+	}
 
 	actions := container.NewHBox()
 
 	// Determine SRI Action Button
 	// Show if:
-	// 1. It already has an electronic receipt (Invoice or Credit Note).
+	// 1. It already has an electronic receipt (Invoice, Credit Note or Withholding).
 	// 2. OR it is a pure Income (Sale) that is NOT a reversal/void of another transaction.
-	if (d.tx.Category.Type == domain.Income && d.tx.VoidsTransactionID == nil) || d.tx.ElectronicReceipt != nil {
-		isAuthorized := d.tx.ElectronicReceipt != nil && d.tx.ElectronicReceipt.SRIStatus == "AUTORIZADO"
+	// 3. OR it is an active Outcome (Purchase) on which we can withhold taxes.
+	// 4. OR it is a partial return of an invoice still waiting for its credit note.
+	isSale := d.tx.Category.Type == domain.Income && d.tx.VoidsTransactionID == nil
+	isPurchase := d.tx.Category.Type == domain.Outcome && d.tx.VoidsTransactionID == nil && !d.tx.IsVoided && !d.tx.IsPartialCredit()
+	if isSale || isPurchase || d.tx.IsPartialCredit() || d.tx.ElectronicReceipt != nil {
+		isAuthorized := d.tx.ElectronicReceipt != nil && d.tx.ElectronicReceipt.SRIStatus == domain.ReceiptAuthorized
 		hasReceipt := d.tx.ElectronicReceipt != nil
 
 		if isAuthorized {
@@ -146,6 +187,24 @@ func (d *DetailsDialog) buildContent() fyne.CanvasObject {
 			actions.Add(statusLabel)
 			actions.Add(rideBtn)
 
+			// Sobre una factura autorizada se pueden cobrar intereses o recargos con Nota de Débito
+			if d.tx.ElectronicReceipt.ReceiptType == "01" && isSale && !d.tx.IsVoided {
+				debitBtn := widget.NewButtonWithIcon("Nota de Débito", theme.ContentAddIcon(), func() {
+					d.showDebitNoteDialog()
+				})
+				actions.Add(debitBtn)
+
+				shipmentBtn := widget.NewButtonWithIcon("Guía de Remisión", theme.MailForwardIcon(), func() {
+					d.showShipmentDialog()
+				})
+				actions.Add(shipmentBtn)
+
+				returnBtn := widget.NewButtonWithIcon("Devolución Parcial", theme.ContentUndoIcon(), func() {
+					d.showPartialCreditNoteDialog()
+				})
+				actions.Add(returnBtn)
+			}
+
 			// Email Status logic
 			if d.tx.ElectronicReceipt.EmailSent {
 				emailLabel := widget.NewLabelWithStyle("✉️ Email Enviado", fyne.TextAlignCenter, fyne.TextStyle{Italic: true})
@@ -163,10 +222,10 @@ func (d *DetailsDialog) buildContent() fyne.CanvasObject {
 			statusText := fmt.Sprintf("Estado SRI: %s", status)
 
 			// Detectar si está "trabada" en proceso por mucho tiempo (> 2 horas)
-			isStuck := status == "EN PROCESO" && time.Since(d.tx.ElectronicReceipt.CreatedAt) > 2*time.Hour
+			isStuck := d.tx.ElectronicReceipt.IsStuck(time.Now())
 
 			// Si es error temporal o está en proceso reciente, permitir sincronizar
-			if (status == "RECIBIDA" || status == "EN PROCESO" || status == "PENDIENTE" || status == "ERROR_RED") && !isStuck {
+			if !d.tx.ElectronicReceipt.CanReissue(time.Now()) {
 				statusLabel := widget.NewLabelWithStyle(statusText, fyne.TextAlignCenter, fyne.TextStyle{Italic: true})
 				retryBtn := widget.NewButtonWithIcon("Sincronizar / Reintentar", theme.ViewRefreshIcon(), func() {
 					d.retryEmission()
@@ -174,13 +233,21 @@ func (d *DetailsDialog) buildContent() fyne.CanvasObject {
 				actions.Add(statusLabel)
 				actions.Add(retryBtn)
 			} else {
-				// Terminal errors (NO AUTORIZADO, RECHAZADA, DEVUELTA) OR Stuck Process: Allow RE-EMIT (New Key)
+				// Terminal errors (RECHAZADA, DEVUELTA) OR Stuck Process: Allow RE-EMIT (New Key)
 				label := statusText + " (Fallido)"
 				if isStuck {
 					label = statusText + " (Sin respuesta > 2h)"
 				}
 				statusLabel := widget.NewLabelWithStyle(label, fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
 				reEmitBtn := widget.NewButtonWithIcon("Corregir y Re-Emitir", theme.ConfirmIcon(), func() {
+					switch d.tx.ElectronicReceipt.ReceiptType {
+					case "07":
+						d.showWithholdingDialog()
+						return
+					case "03":
+						d.showPurchaseSettlementDialog()
+						return
+					}
 					d.promptPasswordAndEmit()
 				})
 				reEmitBtn.Importance = widget.WarningImportance
@@ -188,13 +255,33 @@ func (d *DetailsDialog) buildContent() fyne.CanvasObject {
 				actions.Add(reEmitBtn)
 			}
 		} else {
-			// New emission (Only for Income, don't allow creating receipt for arbitrary outcomes unless via void process)
-			if d.tx.Category.Type == domain.Income {
-				emitBtn := widget.NewButtonWithIcon("Emitir Factura Electrónica", theme.ConfirmIcon(), func() {
+			// New emission: invoices for Income, withholding vouchers or purchase settlements for Outcome (voids go through the void process)
+			if d.tx.IsPartialCredit() {
+				emitBtn := widget.NewButtonWithIcon("Emitir Nota de Crédito", theme.ConfirmIcon(), func() {
+					d.promptPasswordAndEmit()
+				})
+				emitBtn.Importance = widget.HighImportance
+				actions.Add(emitBtn)
+			} else if d.tx.Category.Type == domain.Income {
+				label := "Emitir Factura Electrónica"
+				if d.tx.RelatedTransactionID != nil {
+					label = "Emitir Nota de Débito"
+				}
+				emitBtn := widget.NewButtonWithIcon(label, theme.ConfirmIcon(), func() {
 					d.promptPasswordAndEmit()
 				})
 				emitBtn.Importance = widget.HighImportance
 				actions.Add(emitBtn)
+			} else if isPurchase {
+				withholdBtn := widget.NewButtonWithIcon("Emitir Retención", theme.DocumentCreateIcon(), func() {
+					d.showWithholdingDialog()
+				})
+				actions.Add(withholdBtn)
+
+				settlementBtn := widget.NewButtonWithIcon("Emitir Liquidación de Compra", theme.DocumentCreateIcon(), func() {
+					d.showPurchaseSettlementDialog()
+				})
+				actions.Add(settlementBtn)
 			}
 		}
 	}
@@ -216,6 +303,11 @@ func (d *DetailsDialog) buildContent() fyne.CanvasObject {
 		widget.NewSeparator(),
 		container.NewBorder(nil, nil, nil, nil, footer),
 	)
+	if len(d.events) > 0 {
+		detailsContent.Add(widget.NewSeparator())
+		detailsContent.Add(widget.NewLabelWithStyle("Historia del Comprobante", fyne.TextAlignLeading, fyne.TextStyle{Italic: true}))
+		detailsContent.Add(container.NewPadded(d.buildTimeline()))
+	}
 
 	return container.NewBorder(
 		topActions,
@@ -224,6 +316,94 @@ func (d *DetailsDialog) buildContent() fyne.CanvasObject {
 	)
 }
 
+// buildTimeline lista los eventos del comprobante: cuándo, el cambio de estado, la respuesta del SRI
+// y quién lo originó.
+func (d *DetailsDialog) buildTimeline() fyne.CanvasObject {
+	timeline := container.NewVBox()
+	for _, e := range d.events {
+		change := string(e.ToStatus)
+		if e.FromStatus != "" && e.FromStatus != e.ToStatus {
+			change = fmt.Sprintf("%s → %s", e.FromStatus, e.ToStatus)
+		}
+		origin := "Sincronización automática"
+		if e.UserID != nil {
+			origin = e.UserName
+		}
+
+		row := container.NewVBox(
+			container.NewHBox(
+				widget.NewLabel(e.CreatedAt.Format(componets.AppDateFormat+" 15:04:05")),
+				widget.NewLabelWithStyle(change, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				layout.NewSpacer(),
+				widget.NewLabelWithStyle(origin, fyne.TextAlignTrailing, fyne.TextStyle{Italic: true}),
+			),
+		)
+		if e.Message != "" {
+			msg := widget.NewLabel(e.Message)
+			msg.Wrapping = fyne.TextWrapWord
+			row.Add(msg)
+		}
+		timeline.Add(row)
+	}
+	return timeline
+}
+
+func (d *DetailsDialog) showWithholdingDialog() {
+	NewWithholdingDialog(d.parent, d.tx, d.sriService, d.taxService, d.currentUser, func() {
+		if d.dialog != nil {
+			d.dialog.Hide()
+		}
+		if d.onChanged != nil {
+			d.onChanged()
+		}
+	}).Show()
+}
+
+func (d *DetailsDialog) showPurchaseSettlementDialog() {
+	NewPurchaseSettlementDialog(d.parent, d.tx, d.sriService, d.taxService, d.currentUser, func() {
+		if d.dialog != nil {
+			d.dialog.Hide()
+		}
+		if d.onChanged != nil {
+			d.onChanged()
+		}
+	}).Show()
+}
+
+func (d *DetailsDialog) showPartialCreditNoteDialog() {
+	NewPartialCreditNoteDialog(d.parent, d.tx, d.txService, d.sriService, d.currentUser, func() {
+		if d.dialog != nil {
+			d.dialog.Hide()
+		}
+		if d.onChanged != nil {
+			d.onChanged()
+		}
+	}).Show()
+}
+
+func (d *DetailsDialog) showDebitNoteDialog() {
+	NewDebitNoteDialog(d.parent, d.tx, d.txService, d.sriService, d.issuerService, d.currentUser, func() {
+		if d.dialog != nil {
+			d.dialog.Hide()
+		}
+		if d.onChanged != nil {
+			d.onChanged()
+		}
+	}).Show()
+}
+
+// showShipmentDialog abre la guía de remisión precargada con el cliente e ítems de la factura.
+func (d *DetailsDialog) showShipmentDialog() {
+	var draft *domain.Shipment
+	componets.HandleLongRunningOperation(d.parent, "Preparando Guía de Remisión...", func(ctx context.Context) error {
+		var err error
+		draft, err = d.shipmentService.NewShipmentFromTransaction(ctx, d.tx.ID)
+		return err
+	}, func() {
+		shipment.NewShipmentDialog(d.parent, draft, d.shipmentService, d.sriService, d.taxService, d.currentUser, nil).Show()
+	})
+}
+
 func (d *DetailsDialog) promptPasswordAndEmit() {
 	passEntry := widget.NewPasswordEntry()
 	motivoEntry := widget.NewEntry()
@@ -234,8 +414,8 @@ func (d *DetailsDialog) promptPasswordAndEmit() {
 	}
 
 	// Si es Nota de Crédito (Tipo 04), pedir motivo
-	// Es NC si el recibo dice "04" O si la transacción anula a otra (VoidsTransactionID != nil)
-	isNC := (d.tx.ElectronicReceipt != nil && d.tx.ElectronicReceipt.ReceiptType == "04") || d.tx.VoidsTransactionID != nil
+	// Es NC si el recibo dice "04", si la transacción anula a otra (VoidsTransactionID != nil) o si es una devolución parcial
+	isNC := (d.tx.ElectronicReceipt != nil && d.tx.ElectronicReceipt.ReceiptType == "04") || d.tx.VoidsTransactionID != nil || d.tx.IsPartialCredit()
 	if isNC {
 		items = append(items, widget.NewFormItem("Motivo (NC):", motivoEntry))
 	}
@@ -258,28 +438,49 @@ func (d *DetailsDialog) promptPasswordAndEmit() {
 
 func (d *DetailsDialog) emitDocument(password string, motivo string) {
 	msg := "Emitiendo Factura al SRI..."
-	// Es NC si el recibo dice "04" O si la transacción anula a otra
-	isNC := (d.tx.ElectronicReceipt != nil && d.tx.ElectronicReceipt.ReceiptType == "04") || d.tx.VoidsTransactionID != nil
+	// Es NC si el recibo dice "04", si la transacción anula a otra o si devuelve parte de una factura
+	isNC := (d.tx.ElectronicReceipt != nil && d.tx.ElectronicReceipt.ReceiptType == "04") || d.tx.VoidsTransactionID != nil || d.tx.IsPartialCredit()
+
+	// Es ND si el recibo dice "05" O si la transacción es un cargo sobre otra factura
+	isND := (d.tx.ElectronicReceipt != nil && d.tx.ElectronicReceipt.ReceiptType == "05") || d.tx.IsDebitNote()
 
 	if isNC {
 		msg = "Emitiendo Nota de Crédito al SRI..."
+	} else if isND {
+		msg = "Emitiendo Nota de Débito al SRI..."
 	}
 
 	componets.HandleLongRunningOperation(d.parent, msg, func(ctx context.Context) error {
+		ctx = domain.ContextWithUser(ctx, &d.currentUser)
 		var err error
 
 		// Decidir qué emitir
 		if isNC {
-			// Es Nota de Crédito
-			if d.tx.VoidsTransactionID == nil {
+			// Es Nota de Crédito (anulación total o devolución parcial)
+			originalID := d.tx.VoidsTransactionID
+			if d.tx.IsPartialCredit() {
+				originalID = d.tx.RelatedTransactionID
+			}
+			if originalID == nil {
 				return errors.New("error de datos: esta transacción de anulación no está vinculada a una factura original")
 			}
-			_, err = d.sriService.EmitirNotaCredito(ctx, d.tx.ID, *d.tx.VoidsTransactionID, motivo, password)
+			_, err = d.sriService.EmitirNotaCredito(ctx, d.tx.ID, *originalID, motivo, password)
+		} else if isND {
+			if d.tx.RelatedTransactionID == nil {
+				return errors.New("error de datos: esta nota de débito no está vinculada a una factura original")
+			}
+			_, err = d.sriService.EmitirNotaDebito(ctx, d.tx.ID, *d.tx.RelatedTransactionID, password)
 		} else {
 			// Es Factura (Por defecto o Tipo 01)
 			err = d.sriService.EmitirFactura(ctx, d.tx.ID, password)
 		}
 		if err != nil {
+			// Si el XML no cumple el esquema se lista cada campo para que el usuario sepa qué corregir
+			var verrs sri.ValidationErrors
+			if errors.As(err, &verrs) {
+				fyne.Do(func() { d.showValidationErrors(verrs) })
+				return nil
+			}
 			return err
 		}
 
@@ -305,6 +506,33 @@ func (d *DetailsDialog) emitDocument(password string, motivo string) {
 	}, nil)
 }
 
+// showValidationErrors muestra los campos del comprobante que no cumplen el esquema del SRI.
+func (d *DetailsDialog) showValidationErrors(verrs sri.ValidationErrors) {
+	scroll := container.NewVScroll(newValidationErrorsList(verrs))
+	scroll.SetMinSize(fyne.NewSize(500, 250))
+
+	content := container.NewBorder(
+		widget.NewLabel("El comprobante no se envió. Corrija estos campos y vuelva a emitirlo:"),
+		nil, nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("❌ Comprobante con errores", "Aceptar", content, d.parent)
+}
+
+// newValidationErrorsList arma una fila por error: la ruta del campo en negrita y el motivo debajo.
+func newValidationErrorsList(verrs sri.ValidationErrors) *fyne.Container {
+	list := container.NewVBox()
+	for _, ve := range verrs {
+		msg := widget.NewLabel(ve.Message)
+		msg.Wrapping = fyne.TextWrapWord
+		list.Add(container.NewVBox(
+			widget.NewLabelWithStyle(ve.Path, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			msg,
+		))
+	}
+	return list
+}
+
 func (d *DetailsDialog) showSRIFeedback(tx *domain.Transaction) {
 	if tx.ElectronicReceipt == nil {
 		return
@@ -315,15 +543,15 @@ func (d *DetailsDialog) showSRIFeedback(tx *domain.Transaction) {
 	var icon fyne.Resource
 
 	switch status {
-	case "AUTORIZADO":
+	case domain.ReceiptAuthorized:
 		title = "✅ Factura Autorizada"
 		message = fmt.Sprintf("La factura ha sido autorizada correctamente.\nClave: %s", tx.ElectronicReceipt.AccessKey)
 		icon = theme.ConfirmIcon()
-	case "EN PROCESO", "RECIBIDA", "PENDIENTE":
+	case domain.ReceiptProcessing, domain.ReceiptReceived, domain.ReceiptPending:
 		title = "⏳ En Proceso"
 		message = "La factura fue recibida por el SRI pero la autorización está pendiente.\nEl sistema verificará el estado automáticamente."
 		icon = theme.InfoIcon()
-	case "NO AUTORIZADO", "RECHAZADA", "DEVUELTA":
+	case domain.ReceiptRejected, domain.ReceiptReturned:
 		title = "❌ Factura Rechazada"
 		message = fmt.Sprintf("El SRI rechazó el comprobante.\nMotivo: %s", tx.ElectronicReceipt.SRIMessage)
 		icon = theme.ErrorIcon()
@@ -414,7 +642,7 @@ func (d *DetailsDialog) resendEmail() {
 
 func (d *DetailsDialog) retryEmission() {
 	componets.HandleLongRunningOperation(d.parent, "Sincronizando con SRI...", func(ctx context.Context) error {
-		_, err := d.sriService.SyncReceipt(ctx, d.tx.ElectronicReceipt)
+		_, err := d.sriService.SyncReceipt(domain.ContextWithUser(ctx, &d.currentUser), d.tx.ElectronicReceipt)
 		if err != nil {
 			return fmt.Errorf("error de sincronización: %w", err)
 		}
@@ -0,0 +1,31 @@
+package transaction
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewValidationErrorsList(t *testing.T) {
+	verrs := sri.ValidationErrors{
+		{Path: "factura/infoTributaria/ruc", Message: "valor \"123\" no cumple el patrón [0-9]{10}001"},
+		{Path: "factura/detalles", Message: "falta el elemento obligatorio detalle"},
+	}
+
+	list := newValidationErrorsList(verrs)
+	require.Len(t, list.Objects, 2)
+
+	for i, ve := range verrs {
+		row := list.Objects[i].(*fyne.Container)
+		require.Len(t, row.Objects, 2)
+		path := row.Objects[0].(*widget.Label)
+		msg := row.Objects[1].(*widget.Label)
+		assert.Equal(t, ve.Path, path.Text)
+		assert.True(t, path.TextStyle.Bold)
+		assert.Equal(t, ve.Message, msg.Text)
+	}
+}
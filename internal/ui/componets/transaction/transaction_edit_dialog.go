@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"path/filepath"
 	"time"
 
@@ -46,6 +47,7 @@ type EditTransactionDialog struct {
 	// Tax & Client UI
 	subtotalLabel  *widget.Label
 	taxAmountLabel *widget.Label
+	otherTaxLabel  *widget.Label // ICE e IRBPNR de los ítems
 	totalLabel     *widget.Label
 
 	// Client Selector
@@ -63,6 +65,7 @@ type EditTransactionDialog struct {
 	attachmentPath     string
 	currentUser        domain.User
 	items              []domain.TransactionItem
+	taxRates           domain.TaxRateTable
 }
 
 // NewEditTransactionDialog creates a new dialog handler for the edit action.
@@ -98,12 +101,13 @@ func NewEditTransactionDialog(
 		attachmentLabel:  widget.NewLabel("Ninguno"),
 		subtotalLabel:    widget.NewLabel("$0.00"),
 		taxAmountLabel:   widget.NewLabel("$0.00"),
+		otherTaxLabel:    widget.NewLabel("$0.00"),
 		totalLabel:       widget.NewLabelWithStyle("$0.00", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		taxPayerLabel:    widget.NewLabel("Consumidor Final"), // Default
 		items:            make([]domain.TransactionItem, 0),
 	}
 
-	d.itemsManager = NewItemsListManager(-1, win, d.handleItemsUpdate)
+	d.itemsManager = NewItemsListManager(-1, d.taxRates, win, d.handleItemsUpdate)
 
 	d.categoryButton = widget.NewButtonWithIcon("", theme.SearchIcon(), d.openCategorySearch)
 	d.searchDialog = category.NewCategorySearchDialog(win, l, cs, d.handleCategorySelect)
@@ -141,32 +145,40 @@ func NewEditTransactionDialog(
 
 func (d *EditTransactionDialog) handleItemsUpdate(items []domain.TransactionItem) {
 	d.items = items
-	var subtotal, tax float64
+	date := time.Now()
+	if d.dateEntry.Date != nil {
+		date = *d.dateEntry.Date
+	}
+	totals, err := d.buildTotals(date)
+	if err != nil {
+		d.logger.Printf("Error calculando totales: %v", err)
+	}
+
+	d.subtotalLabel.SetText(fmt.Sprintf("$%.2f", totals.Subtotal15+totals.Subtotal0))
+	d.taxAmountLabel.SetText(fmt.Sprintf("$%.2f", totals.TaxAmount))
+	d.otherTaxLabel.SetText(fmt.Sprintf("$%.2f", totals.ICEAmount+totals.IRBPNRAmount))
+	d.totalLabel.SetText(fmt.Sprintf("$%.2f", totals.Amount))
+}
+
+// buildTotals calcula bases, IVA y total de los ítems con las tarifas vigentes en la fecha dada.
+// Los egresos no generan IVA: todo va a la base 0%.
+func (d *EditTransactionDialog) buildTotals(date time.Time) (*domain.Transaction, error) {
+	tx := &domain.Transaction{Items: d.items, TransactionDate: date}
 
 	// Determine if we should calculate tax (Only for Income)
-	isOutcome := false
 	for _, cat := range d.categories {
 		if cat.ID == d.selectedCategoryID && cat.Type == domain.Outcome {
-			isOutcome = true
-			break
-		}
-	}
-
-	for _, item := range items {
-		subtotal += item.Subtotal
-		// Only calculate tax if it's NOT an outcome
-		if !isOutcome && item.TaxRate == 4 { // IVA 15%
-			tax += item.Subtotal * 0.15
+			for _, item := range d.items {
+				tx.Subtotal0 += item.Subtotal
+			}
+			tx.Subtotal0 = math.Round(tx.Subtotal0*100) / 100
+			tx.Amount = tx.Subtotal0
+			return tx, nil
 		}
 	}
 
-	if isOutcome {
-		tax = 0 // Forced safety
-	}
-
-	d.subtotalLabel.SetText(fmt.Sprintf("$%.2f", subtotal))
-	d.taxAmountLabel.SetText(fmt.Sprintf("$%.2f", tax))
-	d.totalLabel.SetText(fmt.Sprintf("$%.2f", subtotal+tax))
+	err := tx.RecalculateTotals(d.taxRates)
+	return tx, err
 }
 
 func (d *EditTransactionDialog) openCategorySearch() {
@@ -238,8 +250,15 @@ func (d *EditTransactionDialog) fetchTransaction(onSuccess func(tx *domain.Trans
 		} else {
 			d.logger.Printf("Advertencia: No se encontró emisor activo al editar, usando IVA 15%% por defecto. Error: %v", err)
 		}
+		rates, err := d.issuerService.GetTaxRates(ctx)
+		if err != nil {
+			fyne.Do(func() { progress.Hide() })
+			onFailure(err)
+			return
+		}
+		d.taxRates = rates
 
-		d.itemsManager = NewItemsListManager(defaultTaxRate, d.mainWin, d.handleItemsUpdate)
+		d.itemsManager = NewItemsListManager(defaultTaxRate, d.taxRates, d.mainWin, d.handleItemsUpdate)
 
 		fyne.Do(func() { progress.Hide() })
 		onSuccess(tx, items)
@@ -303,6 +322,7 @@ func (d *EditTransactionDialog) showEditForm(tx *domain.Transaction, items []dom
 	summary := widget.NewForm(
 		widget.NewFormItem("Subtotal", d.subtotalLabel),
 		widget.NewFormItem("IVA", d.taxAmountLabel),
+		widget.NewFormItem("ICE / IRBPNR", d.otherTaxLabel),
 		widget.NewFormItem("TOTAL", d.totalLabel),
 	)
 
@@ -354,31 +374,22 @@ func (d *EditTransactionDialog) handleSubmit(valid bool) {
 
 	go func() {
 		// Calculate final totals
-		var sub15, sub0, taxTotal, total float64
-		var description string
-
-		isOutcome := false
-		for _, cat := range d.categories {
-			if cat.ID == d.selectedCategoryID && cat.Type == domain.Outcome {
-				isOutcome = true
-				break
-			}
+		totals, err := d.buildTotals(transactionDate)
+		if err != nil {
+			fyne.Do(func() {
+				progress.Hide()
+				dialog.ShowError(err, d.mainWin)
+			})
+			return
 		}
 
+		var description string
 		for i, item := range d.items {
 			if i > 0 {
 				description += ", "
 			}
 			description += item.Description
-
-			if !isOutcome && item.TaxRate == 4 {
-				sub15 += item.Subtotal
-				taxTotal += item.Subtotal * 0.15
-			} else {
-				sub0 += item.Subtotal
-			}
 		}
-		total = sub15 + sub0 + taxTotal
 
 		var attachmentPathPtr *string
 		if d.attachmentPath != "" {
@@ -388,14 +399,14 @@ func (d *EditTransactionDialog) handleSubmit(valid bool) {
 		updatedTx := &domain.Transaction{
 			BaseEntity:      domain.BaseEntity{ID: d.txID},
 			Description:     description, // Auto-generated from items
-			Amount:          total,
+			Amount:          totals.Amount,
 			TransactionDate: transactionDate,
 			AccountID:       d.accountID,
 			CategoryID:      d.selectedCategoryID,
 			AttachmentPath:  attachmentPathPtr,
-			Subtotal15:      sub15,
-			Subtotal0:       sub0,
-			TaxAmount:       taxTotal,
+			Subtotal15:      totals.Subtotal15,
+			Subtotal0:       totals.Subtotal0,
+			TaxAmount:       totals.TaxAmount,
 			Items:           d.items, // Needs backend support to update items
 		}
 
@@ -403,7 +414,7 @@ func (d *EditTransactionDialog) handleSubmit(valid bool) {
 		defer cancel()
 
 		// NOTE: TransactionService.UpdateTransaction needs to be updated to handle Items update (Delete old + Insert new)
-		err := d.txService.UpdateTransaction(ctx, updatedTx, d.currentUser)
+		err = d.txService.UpdateTransaction(ctx, updatedTx, d.currentUser)
 		if err != nil {
 			fyne.Do(func() {
 				progress.Hide()
@@ -175,7 +175,7 @@ func (d *VoidTransactionDialog) executeVoid(sriParams *sriVoidParams) {
 
 		// Si es SRI, emitir Nota de Credito vinculada a la anulación
 		if sriParams != nil {
-			accessKey, err = d.sriService.EmitirNotaCredito(ctx, voidTxID, sriParams.OriginalTxID, sriParams.Motivo, sriParams.Password)
+			accessKey, err = d.sriService.EmitirNotaCredito(domain.ContextWithUser(ctx, &d.currentUser), voidTxID, sriParams.OriginalTxID, sriParams.Motivo, sriParams.Password)
 			if err != nil {
 				// ERROR EN SRI: Revertir la anulación local para mantener consistencia
 				d.logger.Printf("Fallo SRI (%v). Revirtiendo anulación local %d...", err, voidTxID)
@@ -0,0 +1,256 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
+	"github.com/nelsonmarro/verith/internal/ui/componets"
+	"github.com/nelsonmarro/verith/internal/ui/componets/taxpayer"
+)
+
+var withholdingTaxOptions = []string{"Renta", "IVA"}
+
+// WithholdingDialog captura los datos de la factura del proveedor y las líneas
+// de retención para emitir el Comprobante de Retención (07) de un egreso.
+type WithholdingDialog struct {
+	parent     fyne.Window
+	tx         *domain.Transaction
+	sriService SriService
+	taxService TaxPayerService
+	onEmitted  func()
+
+	currentUser domain.User // Queda registrado en la historia del comprobante
+
+	supplier      *domain.TaxPayer
+	supplierLabel *widget.Label
+	docNumEntry   *widget.Entry
+	docDateEntry  *componets.LatinDateEntry
+	authKeyEntry  *componets.AccessKeyEntry
+	passEntry     *widget.Entry
+
+	lines          []domain.WithholdingLine
+	linesContainer *fyne.Container
+	totalLabel     *widget.Label
+}
+
+func NewWithholdingDialog(
+	parent fyne.Window,
+	tx *domain.Transaction,
+	sriService SriService,
+	taxService TaxPayerService,
+	currentUser domain.User,
+	onEmitted func(),
+) *WithholdingDialog {
+	return &WithholdingDialog{
+		parent:      parent,
+		tx:          tx,
+		sriService:  sriService,
+		taxService:  taxService,
+		onEmitted:   onEmitted,
+		currentUser: currentUser,
+	}
+}
+
+func (d *WithholdingDialog) Show() {
+	d.supplierLabel = widget.NewLabel("Seleccione el proveedor...")
+	searchBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		taxpayer.NewSearchDialog(d.parent, log.Default(), d.taxService, func(tp *domain.TaxPayer) {
+			d.supplier = tp
+			d.supplierLabel.SetText(fmt.Sprintf("%s (%s)", tp.Name, tp.Identification))
+		}).Show()
+	})
+	d.loadSupplier()
+
+	d.docNumEntry = widget.NewEntry()
+	d.docNumEntry.SetPlaceHolder("001-001-000000123")
+	d.docDateEntry = componets.NewLatinDateEntry(d.parent)
+	d.docDateEntry.SetDate(d.tx.TransactionDate)
+	d.authKeyEntry = componets.NewAccessKeyEntry()
+	d.authKeyEntry.AllowPrinted = true
+	d.authKeyEntry.Entry.SetPlaceHolder("Clave de acceso o autorización de la factura")
+	// La clave de acceso trae el número y la fecha de la factura del proveedor
+	d.authKeyEntry.OnParsed = func(key *sri.AccessKey) {
+		if key == nil {
+			return
+		}
+		d.docNumEntry.SetText(key.DocumentNumber())
+		d.docDateEntry.SetDate(key.Date)
+	}
+	d.passEntry = widget.NewPasswordEntry()
+
+	docForm := widget.NewForm(
+		widget.NewFormItem("Proveedor:", container.NewBorder(nil, nil, nil, searchBtn, d.supplierLabel)),
+		widget.NewFormItem("Factura No.:", d.docNumEntry),
+		widget.NewFormItem("Fecha Factura:", d.docDateEntry),
+		widget.NewFormItem("Autorización:", d.authKeyEntry.GetContent()),
+	)
+
+	// --- Captura de líneas ---
+	taxSelect := widget.NewSelect(withholdingTaxOptions, nil)
+	taxSelect.SetSelected("Renta")
+	codeEntry := widget.NewEntry()
+	codeEntry.SetPlaceHolder("Código (ej. 312)")
+	baseEntry := widget.NewEntry()
+	baseEntry.SetPlaceHolder("Base")
+	percentEntry := widget.NewEntry()
+	percentEntry.SetPlaceHolder("%")
+
+	// Sugerimos la base según el impuesto elegido
+	taxSelect.OnChanged = func(s string) {
+		if s == "IVA" {
+			baseEntry.SetText(fmt.Sprintf("%.2f", d.tx.TaxAmount))
+		} else {
+			baseEntry.SetText(fmt.Sprintf("%.2f", d.tx.Subtotal15+d.tx.Subtotal0))
+		}
+	}
+	taxSelect.OnChanged(taxSelect.Selected)
+
+	addBtn := widget.NewButtonWithIcon("Agregar", theme.ContentAddIcon(), func() {
+		line, err := parseWithholdingLine(taxSelect.Selected, codeEntry.Text, baseEntry.Text, percentEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, d.parent)
+			return
+		}
+		d.lines = append(d.lines, line)
+		codeEntry.SetText("")
+		percentEntry.SetText("")
+		d.refreshLines()
+	})
+
+	inputRow := container.NewGridWithColumns(5, taxSelect, codeEntry, baseEntry, percentEntry, addBtn)
+
+	d.linesContainer = container.NewVBox()
+	d.totalLabel = widget.NewLabelWithStyle("Total Retenido: $0.00", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true})
+	d.refreshLines()
+
+	content := container.NewVBox(
+		docForm,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Retenciones", fyne.TextAlignLeading, fyne.TextStyle{Italic: true}),
+		inputRow,
+		d.linesContainer,
+		d.totalLabel,
+		widget.NewSeparator(),
+		widget.NewForm(widget.NewFormItem("Contraseña Firma:", d.passEntry)),
+	)
+
+	dlg := dialog.NewCustomConfirm("Emitir Comprobante de Retención", "Emitir", "Cancelar", container.NewVScroll(content), func(confirm bool) {
+		if confirm {
+			d.emit()
+		}
+	}, d.parent)
+	dlg.Resize(fyne.NewSize(700, 550))
+	dlg.Show()
+}
+
+// loadSupplier precarga el proveedor si el egreso ya tiene uno asignado.
+func (d *WithholdingDialog) loadSupplier() {
+	if d.tx.TaxPayerID == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		tp, err := d.taxService.GetByID(ctx, *d.tx.TaxPayerID)
+		if err != nil || tp == nil {
+			return
+		}
+		fyne.Do(func() {
+			d.supplier = tp
+			d.supplierLabel.SetText(fmt.Sprintf("%s (%s)", tp.Name, tp.Identification))
+		})
+	}()
+}
+
+func (d *WithholdingDialog) refreshLines() {
+	d.linesContainer.RemoveAll()
+	total := 0.0
+	for i, l := range d.lines {
+		idx := i
+		taxName := "Renta"
+		if l.TaxCode == domain.WithholdingTaxIVA {
+			taxName = "IVA"
+		}
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			d.lines = append(d.lines[:idx], d.lines[idx+1:]...)
+			d.refreshLines()
+		})
+		d.linesContainer.Add(container.NewGridWithColumns(5,
+			widget.NewLabel(taxName),
+			widget.NewLabel(l.RetentionCode),
+			widget.NewLabel(fmt.Sprintf("$%.2f", l.TaxBase)),
+			widget.NewLabel(fmt.Sprintf("%s%% = $%.2f", strconv.FormatFloat(l.Percentage, 'f', -1, 64), l.Amount())),
+			removeBtn,
+		))
+		total += l.Amount()
+	}
+	d.totalLabel.SetText(fmt.Sprintf("Total Retenido: $%.2f", total))
+}
+
+func (d *WithholdingDialog) emit() {
+	if d.supplier == nil {
+		dialog.ShowError(errors.New("debe seleccionar el proveedor"), d.parent)
+		return
+	}
+	if d.docDateEntry.Date == nil {
+		dialog.ShowError(errors.New("la fecha de la factura no es válida"), d.parent)
+		return
+	}
+
+	w := &domain.Withholding{
+		TransactionID:     d.tx.ID,
+		SupplierID:        d.supplier.ID,
+		SupportDocType:    "01",
+		SupportDocNumber:  strings.TrimSpace(d.docNumEntry.Text),
+		SupportDocDate:    *d.docDateEntry.Date,
+		SupportDocAuthKey: strings.TrimSpace(d.authKeyEntry.Entry.Text),
+		Lines:             d.lines,
+	}
+	password := d.passEntry.Text
+
+	componets.HandleLongRunningOperation(d.parent, "Emitiendo Retención al SRI...", func(ctx context.Context) error {
+		_, err := d.sriService.EmitirRetencion(domain.ContextWithUser(ctx, &d.currentUser), w, password)
+		return err
+	}, func() {
+		dialog.ShowInformation("Retención", "El comprobante de retención fue enviado al SRI.", d.parent)
+		if d.onEmitted != nil {
+			d.onEmitted()
+		}
+	})
+}
+
+func parseWithholdingLine(tax, code, base, percent string) (domain.WithholdingLine, error) {
+	line := domain.WithholdingLine{
+		TaxCode:       domain.WithholdingTaxRenta,
+		RetentionCode: strings.TrimSpace(code),
+	}
+	if tax == "IVA" {
+		line.TaxCode = domain.WithholdingTaxIVA
+	}
+	if line.RetentionCode == "" {
+		return line, errors.New("ingrese el código de retención")
+	}
+
+	var err error
+	line.TaxBase, err = strconv.ParseFloat(strings.TrimSpace(base), 64)
+	if err != nil || line.TaxBase <= 0 {
+		return line, errors.New("la base imponible no es válida")
+	}
+	line.Percentage, err = strconv.ParseFloat(strings.TrimSpace(percent), 64)
+	if err != nil || line.Percentage <= 0 || line.Percentage > 100 {
+		return line, errors.New("el porcentaje no es válido")
+	}
+	return line, nil
+}
@@ -10,6 +10,12 @@ import (
 type UserService interface {
 	CreateUser(ctx context.Context, username, password, firstName, lastName string, role domain.UserRole, currentUser *domain.User) error
 	UpdateUser(ctx context.Context, id int, username, password, firstName, lastName string, role domain.UserRole, currentUser *domain.User) error
+	SetDefaultEmissionPoint(ctx context.Context, id int, establishmentCode, emissionPointCode string, currentUser *domain.User) error
 	DeleteUser(ctx context.Context, id int, currentUser *domain.User) error
 	GetAllUsers(ctx context.Context, currentUser *domain.User) ([]domain.User, error)
 }
+
+// EmissionPointSource lists the invoicing points a user can be assigned to.
+type EmissionPointSource interface {
+	GetInvoicePoints(ctx context.Context) ([]domain.EmissionPoint, error)
+}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
+)
+
+// issuerPointOption is the option that clears the user's point so the issuer's one is used.
+const issuerPointOption = "Issuer default"
+
+// EmissionPointDialog assigns the emission point proposed on a user's invoices and credit notes.
+type EmissionPointDialog struct {
+	mainWin        fyne.Window
+	logger         *log.Logger
+	service        UserService
+	points         EmissionPointSource
+	callbackAction func()
+	currentUser    *domain.User
+	userToEdit     *domain.User
+
+	pointSelect *widget.Select
+	options     []domain.EmissionPoint // In the same order as pointSelect options, after issuerPointOption
+}
+
+// NewEmissionPointDialog creates a new dialog handler for the user's default emission point.
+func NewEmissionPointDialog(
+	win fyne.Window,
+	l *log.Logger,
+	service UserService,
+	points EmissionPointSource,
+	callback func(),
+	currentUser *domain.User,
+	userToEdit *domain.User,
+) *EmissionPointDialog {
+	return &EmissionPointDialog{
+		mainWin:        win,
+		logger:         l,
+		service:        service,
+		points:         points,
+		callbackAction: callback,
+		currentUser:    currentUser,
+		userToEdit:     userToEdit,
+		pointSelect:    widget.NewSelect(nil, nil),
+	}
+}
+
+// Show loads the invoicing points and displays the form.
+func (d *EmissionPointDialog) Show() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	points, err := d.points.GetInvoicePoints(ctx)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("error loading emission points: %w", err), d.mainWin)
+		return
+	}
+	d.setOptions(points)
+
+	form := dialog.NewForm(
+		fmt.Sprintf("Emission Point - %s", d.userToEdit.Username), "Save", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Emission Point", d.pointSelect)},
+		d.handleSubmit,
+		d.mainWin,
+	)
+	form.Resize(fyne.NewSize(400, 180))
+	form.Show()
+}
+
+func (d *EmissionPointDialog) setOptions(points []domain.EmissionPoint) {
+	d.options = points
+	labels := []string{issuerPointOption}
+	selected := issuerPointOption
+	for _, p := range points {
+		label := domain.EmissionPointLabel(p.EstablishmentCode, p.EmissionPointCode)
+		labels = append(labels, label)
+		u := d.userToEdit
+		if u.DefaultEstablishmentCode != nil && u.DefaultEmissionPointCode != nil &&
+			*u.DefaultEstablishmentCode == p.EstablishmentCode && *u.DefaultEmissionPointCode == p.EmissionPointCode {
+			selected = label
+		}
+	}
+	d.pointSelect.Options = labels
+	d.pointSelect.SetSelected(selected)
+}
+
+// selectedCodes returns the establishment and point codes chosen, or empty codes for the issuer's.
+func (d *EmissionPointDialog) selectedCodes() (string, string) {
+	i := d.pointSelect.SelectedIndex()
+	if i <= 0 {
+		return "", ""
+	}
+	p := d.options[i-1]
+	return p.EstablishmentCode, p.EmissionPointCode
+}
+
+func (d *EmissionPointDialog) handleSubmit(valid bool) {
+	if !valid {
+		return
+	}
+	establishmentCode, emissionPointCode := d.selectedCodes()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		err := d.service.SetDefaultEmissionPoint(ctx, d.userToEdit.ID, establishmentCode, emissionPointCode, d.currentUser)
+		if err != nil {
+			d.logger.Println("Error updating emission point:", err)
+			fyne.Do(func() {
+				dialog.ShowError(fmt.Errorf("error updating emission point: %w", err), d.mainWin)
+			})
+			return
+		}
+
+		fyne.Do(func() {
+			// The session user proposes the new point from the next sale on
+			if d.currentUser.ID == d.userToEdit.ID {
+				d.currentUser.DefaultEstablishmentCode = nil
+				d.currentUser.DefaultEmissionPointCode = nil
+				if establishmentCode != "" {
+					d.currentUser.DefaultEstablishmentCode = &establishmentCode
+					d.currentUser.DefaultEmissionPointCode = &emissionPointCode
+				}
+			}
+			go d.callbackAction()
+		})
+	}()
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
 	"github.com/shopspring/decimal"
 )
 
@@ -59,6 +60,8 @@ type TransactionService interface {
 	GetTransactionByID(ctx context.Context, id int) (*domain.Transaction, error)
 	GetItemsByTransactionID(ctx context.Context, transactionID int) ([]domain.TransactionItem, error)
 	VoidTransaction(ctx context.Context, transactionID int, currentUser domain.User) (int, error)
+	PartialVoidTransaction(ctx context.Context, originalID int, returned []domain.ReturnedItem, currentUser domain.User) (int, error)
+	GetCreditedAmount(ctx context.Context, transactionID int) (float64, error)
 	RevertVoidTransaction(ctx context.Context, voidTransactionID int) error
 	UpdateTransaction(ctx context.Context, tx *domain.Transaction, currentUser domain.User) error
 	ReconcileAccount(
@@ -84,6 +87,13 @@ type ReportService interface {
 	GenerateReconciliationReportFile(ctx context.Context, reconciliation *domain.Reconciliation, outputPath string, currentUser *domain.User) error
 	GenerateDailyReport(ctx context.Context, accountID int) (*domain.DailyReport, error)
 	GenerateDailyReportFile(ctx context.Context, report *domain.DailyReport, outputPath string, format string, currentUser *domain.User) error
+	GenerateVATDeclaration(ctx context.Context, year int, month time.Month, previousCredit decimal.Decimal) (*domain.VATDeclaration, error)
+	GenerateVATDeclarationFile(ctx context.Context, declaration *domain.VATDeclaration, outputPath string, format string, currentUser *domain.User) error
+}
+
+// TaxReportService genera los anexos tributarios para el SRI.
+type TaxReportService interface {
+	GenerateATS(ctx context.Context, year int, month time.Month, outputPath string) error
 }
 
 type RecurringTransactionService interface {
@@ -99,24 +109,38 @@ type IssuerService interface {
 	SaveIssuerConfig(ctx context.Context, issuer *domain.Issuer, password string) error
 	GetIssuerConfig(ctx context.Context) (*domain.Issuer, error)
 	GetSignaturePassword(ruc string) (string, error)
+	GetCertificateInfo(ctx context.Context) (*sri.CertificateInfo, error)
 	GetEmissionPoints(ctx context.Context) ([]domain.EmissionPoint, error)
 	UpdateEmissionPoint(ctx context.Context, ep *domain.EmissionPoint) error
+	GetInvoicePoints(ctx context.Context) ([]domain.EmissionPoint, error)
+	GetEstablishments(ctx context.Context) ([]domain.Establishment, error)
+	SaveEstablishment(ctx context.Context, e *domain.Establishment) error
+	AddEmissionPoint(ctx context.Context, establishmentCode, emissionPointCode string) error
+	GetTaxRates(ctx context.Context) (domain.TaxRateTable, error)
 }
 
 type SriService interface {
 	EmitirFactura(ctx context.Context, transactionID int, signaturePassword string) error
 	EmitirNotaCredito(ctx context.Context, voidTxID int, originalTxID int, motivo string, signaturePassword string) (string, error)
+	EmitirRetencion(ctx context.Context, w *domain.Withholding, signaturePassword string) (string, error)
+	EmitirLiquidacionCompra(ctx context.Context, ps *domain.PurchaseSettlement, signaturePassword string) (string, error)
+	EmitirNotaDebito(ctx context.Context, debitTxID int, originalTxID int, signaturePassword string) (string, error)
+	EmitirGuiaRemision(ctx context.Context, shipmentID int, signaturePassword string) (string, error)
 	GenerateRide(ctx context.Context, transactionID int) (string, error)
+	GenerateShipmentRide(ctx context.Context, shipmentID int) (string, error)
 	SyncReceipt(ctx context.Context, receipt *domain.ElectronicReceipt) (string, error)
 	ProcessBackgroundSync(ctx context.Context) (int, error)
 	ResendEmail(ctx context.Context, transactionID int) error
 	GetPendingQueue(ctx context.Context) ([]domain.ElectronicReceipt, error)
+	GetReceiptEvents(ctx context.Context, accessKey string) ([]domain.ReceiptEvent, error)
+	ExportArchive(ctx context.Context, from, to time.Time, receiptTypes []string, outputPath string) (int, error)
 }
 
 type UserService interface {
 	Login(ctx context.Context, username, password string) (*domain.User, error)
 	CreateUser(ctx context.Context, username, password, firstName, lastName string, role domain.UserRole, currentUser *domain.User) error
 	UpdateUser(ctx context.Context, id int, username, password, firstName, lastName string, role domain.UserRole, currentUser *domain.User) error
+	SetDefaultEmissionPoint(ctx context.Context, id int, establishmentCode, emissionPointCode string, currentUser *domain.User) error
 	DeleteUser(ctx context.Context, id int, currentUser *domain.User) error
 	GetAllUsers(ctx context.Context, currentUser *domain.User) ([]domain.User, error)
 	HasUsers(ctx context.Context) (bool, error)
@@ -124,6 +148,24 @@ type UserService interface {
 	ResetPassword(ctx context.Context, username, newPassword string) error
 }
 
+type ShipmentService interface {
+	GetShipmentByID(ctx context.Context, id int) (*domain.Shipment, error)
+	GetAllShipments(ctx context.Context) ([]domain.Shipment, error)
+	CreateShipment(ctx context.Context, shipment *domain.Shipment, currentUser domain.User) error
+	NewShipmentFromTransaction(ctx context.Context, transactionID int) (*domain.Shipment, error)
+}
+
+type PurchaseImportService interface {
+	ImportSupplierInvoice(ctx context.Context, xmlPath string, accountID, categoryID int, currentUser domain.User) (*domain.Transaction, error)
+	DownloadReceived(
+		ctx context.Context,
+		reportPath string,
+		accountID, categoryID int,
+		currentUser domain.User,
+		onProgress func(done, total int, result domain.ReceivedDocumentResult),
+	) ([]domain.ReceivedDocumentResult, error)
+}
+
 type TaxPayerService interface {
 	GetByID(ctx context.Context, id int) (*domain.TaxPayer, error)
 	GetByIdentification(ctx context.Context, identification string) (*domain.TaxPayer, error)
@@ -132,3 +174,10 @@ type TaxPayerService interface {
 	Search(ctx context.Context, query string) ([]domain.TaxPayer, error)
 	GetPaginated(ctx context.Context, page, pageSize int, search string) (*domain.PaginatedResult[domain.TaxPayer], error)
 }
+
+type CompanyService interface {
+	GetCompanies(ctx context.Context) ([]domain.Company, error)
+	CreateCompany(ctx context.Context, company *domain.Company, currentUser *domain.User) error
+	SelectCompany(ctx context.Context, id int) (*domain.Company, error)
+	ClearCompany()
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/nelsonmarro/verith/internal/application/helpers"
 	"github.com/nelsonmarro/verith/internal/domain"
 	"github.com/nelsonmarro/verith/internal/ui/componets"
+	"github.com/nelsonmarro/verith/internal/ui/componets/shipment"
 	"github.com/nelsonmarro/verith/internal/ui/componets/transaction"
+	"github.com/shopspring/decimal"
 )
 
 func (ui *UI) makeTransactionUI() fyne.CanvasObject {
@@ -64,6 +66,10 @@ func (ui *UI) makeTransactionUI() fyne.CanvasObject {
 			&tx,
 			ui.Services.TxService,
 			ui.Services.SriService,
+			ui.Services.TaxService,
+			ui.Services.ShipmentService,
+			ui.Services.IssuerService,
+			*ui.currentUser,
 			func() {
 				ui.loadTransactions(ui.transactionPaginator.GetCurrentPage(), ui.transactionPaginator.GetPageSize())
 			},
@@ -161,6 +167,13 @@ func (ui *UI) makeTransactionUI() fyne.CanvasObject {
 						ui.mainWindow,
 						func(format string, outputPath string) { go ui.generateReportFile(format, outputPath) },
 						func(format string, outputPath string) { go ui.generateDailyReportFile(format, outputPath) },
+						func(year int, month time.Month, outputPath string) { go ui.generateATSFile(year, month, outputPath) },
+						func(year int, month time.Month, previousCredit decimal.Decimal, format string, outputPath string) {
+							go ui.generateVATDeclarationFile(year, month, previousCredit, format, outputPath)
+						},
+						func(from, to time.Time, receiptTypes []string, outputPath string) {
+							go ui.generateArchiveFile(from, to, receiptTypes, outputPath)
+						},
 					)
 					reportDialog.Show()
 				}))
@@ -198,10 +211,34 @@ func (ui *UI) makeTransactionUI() fyne.CanvasObject {
 			// 4. Cola SRI
 			if ui.currentUser.CanConfigureSystem() {
 				menuItems = append(menuItems, fyne.NewMenuItem("Cola SRI", func() {
-					dialog := transaction.NewSriQueueDialog(ui.mainWindow, ui.Services.SriService, ui.Services.TxService)
+					dialog := transaction.NewSriQueueDialog(ui.mainWindow, ui.Services.SriService, ui.Services.TxService, ui.Services.TaxService, ui.Services.ShipmentService, ui.Services.IssuerService, *ui.currentUser)
 					dialog.Show()
 				}))
 			}
+
+			// Guías de Remisión (traslado de mercadería)
+			menuItems = append(menuItems, fyne.NewMenuItem("Guías de Remisión", func() {
+				shipment.NewShipmentListDialog(ui.mainWindow, ui.Services.ShipmentService, ui.Services.SriService, ui.Services.TaxService, *ui.currentUser).Show()
+			}))
+
+			// Compras desde los XML autorizados de los proveedores
+			menuItems = append(menuItems, fyne.NewMenuItem("Importar Facturas de Proveedores", func() {
+				if ui.selectedAccountID == 0 {
+					dialog.ShowError(fmt.Errorf("seleccione una cuenta primero"), ui.mainWindow)
+					return
+				}
+				transaction.NewPurchaseImportDialog(
+					ui.mainWindow,
+					ui.errorLogger,
+					ui.Services.PurchaseImportService,
+					ui.Services.CatService,
+					func() {
+						go ui.loadTransactions(1, ui.transactionPaginator.GetPageSize())
+					},
+					ui.selectedAccountID,
+					*ui.currentUser,
+				).Show()
+			}))
 		
 			// 5. Recargar (Siempre útil)
 			menuItems = append(menuItems, fyne.NewMenuItem("Recargar Datos", func() {
@@ -379,13 +416,13 @@ func (ui *UI) updateTransactionItem(i widget.ListItemID, o fyne.CanvasObject) {
 	if tx.ElectronicReceipt != nil {
 		sriIcon.Show()
 		switch tx.ElectronicReceipt.SRIStatus {
-		case "AUTORIZADO":
+		case domain.ReceiptAuthorized:
 			sriIcon.SetResource(theme.ConfirmIcon())
-		case "DEVUELTA", "RECHAZADO", "ANULADO":
+		case domain.ReceiptReturned:
 			sriIcon.SetResource(theme.WarningIcon())
-		case "RECIBIDA", "EN PROCESO", "PENDIENTE":
+		case domain.ReceiptReceived, domain.ReceiptProcessing, domain.ReceiptPending, domain.ReceiptSendError, domain.ReceiptNetworkError:
 			sriIcon.SetResource(theme.HistoryIcon())
-		case "NO AUTORIZADO":
+		case domain.ReceiptRejected:
 			sriIcon.SetResource(theme.ErrorIcon())
 		default:
 			sriIcon.SetResource(theme.QuestionIcon())
@@ -579,3 +616,30 @@ func (ui *UI) generateDailyReportFile(format string, outputPath string) {
 		return ui.Services.ReportService.GenerateDailyReportFile(ctx, report, outputPath, format, ui.currentUser)
 	}, nil)
 }
+
+func (ui *UI) generateATSFile(year int, month time.Month, outputPath string) {
+	componets.HandleLongRunningOperation(ui.mainWindow, "Generando Anexo Transaccional (ATS)...", func(ctx context.Context) error {
+		return ui.Services.TaxReportService.GenerateATS(ctx, year, month, outputPath)
+	}, nil)
+}
+
+func (ui *UI) generateVATDeclarationFile(year int, month time.Month, previousCredit decimal.Decimal, format string, outputPath string) {
+	componets.HandleLongRunningOperation(ui.mainWindow, "Generando Declaración de IVA...", func(ctx context.Context) error {
+		declaration, err := ui.Services.ReportService.GenerateVATDeclaration(ctx, year, month, previousCredit)
+		if err != nil {
+			return err
+		}
+		return ui.Services.ReportService.GenerateVATDeclarationFile(ctx, declaration, outputPath, format, ui.currentUser)
+	}, nil)
+}
+
+func (ui *UI) generateArchiveFile(from, to time.Time, receiptTypes []string, outputPath string) {
+	var count int
+	componets.HandleLongRunningOperation(ui.mainWindow, "Exportando Archivo de Comprobantes...", func(ctx context.Context) error {
+		var err error
+		count, err = ui.Services.SriService.ExportArchive(ctx, from, to, receiptTypes, outputPath)
+		return err
+	}, func() {
+		dialog.ShowInformation("Archivo Legal", fmt.Sprintf("Se exportaron %d comprobantes autorizados.", count), ui.mainWindow)
+	})
+}
@@ -33,6 +33,11 @@ type Services struct {
 	IssuerService IssuerService
 	SriService    SriService
 	TaxService    TaxPayerService // Added
+
+	ShipmentService       ShipmentService
+	PurchaseImportService PurchaseImportService
+	TaxReportService      TaxReportService
+	CompanyService        CompanyService
 }
 
 // The UI struct holds the dependencies and state for the Fyne UI.
@@ -45,7 +50,8 @@ type UI struct {
 	mainWindow fyne.Window // Reference to the currently active window
 
 	// ---- Auth State ----
-	currentUser *domain.User
+	currentUser    *domain.User
+	currentCompany *domain.Company
 
 	// ---- UI widgets (State) ----
 	userList *widget.List
@@ -81,6 +87,7 @@ type UI struct {
 	summaryTotalIncome     *canvas.Text
 	summaryTotalExpenses   *canvas.Text
 	summaryNetProfitLoss   *canvas.Text
+	summaryTipsLabel       *widget.Label
 	summaryChartsContainer *fyne.Container
 	summaryBudgetContainer *fyne.Container
 
@@ -173,7 +180,11 @@ func (ui *UI) openLoginWindow() {
 }
 
 func (ui *UI) openMainWindow() {
-	mainWindow := ui.app.NewWindow("Verith")
+	title := "Verith"
+	if ui.currentCompany != nil {
+		title = fmt.Sprintf("Verith - %s", ui.currentCompany.Name)
+	}
+	mainWindow := ui.app.NewWindow(title)
 	ui.mainWindow = mainWindow // Update the reference for dialogs
 
 	// --- License Check for UI ---
@@ -186,6 +197,8 @@ func (ui *UI) openMainWindow() {
 	// Create the menu with logout logic
 	logoutItem := fyne.NewMenuItem("Cerrar Sesión", func() {
 		ui.currentUser = nil
+		ui.currentCompany = nil
+		ui.Services.CompanyService.ClearCompany()
 		ui.openLoginWindow()
 
 		mainWindow.Hide()
@@ -195,6 +208,14 @@ func (ui *UI) openMainWindow() {
 		}()
 	})
 
+	// Los libros de otra empresa se abren en una ventana nueva
+	companyItem := fyne.NewMenuItem("Cambiar Empresa", func() {
+		ui.chooseCompany(mainWindow, true, func() {
+			ui.openMainWindow()
+			mainWindow.Close()
+		})
+	})
+
 	// Add License Management Item
 	licenseItem := fyne.NewMenuItem("Gestionar Licencia", func() {
 		ui.ShowLincenseWindow(licMgr, func() {
@@ -210,7 +231,7 @@ func (ui *UI) openMainWindow() {
 		_ = ui.app.OpenURL(docURL)
 	})
 
-	fileMenu := fyne.NewMenu("Sesión", licenseItem, docItem, fyne.NewMenuItemSeparator(), logoutItem)
+	fileMenu := fyne.NewMenu("Sesión", companyItem, licenseItem, docItem, fyne.NewMenuItemSeparator(), logoutItem)
 	mainWindow.SetMainMenu(fyne.NewMainMenu(fileMenu))
 
 	// Build tabs
@@ -312,6 +333,8 @@ func (ui *UI) openMainWindow() {
 						"El sistema aún no está configurado para facturación electrónica.\nPor favor, solicita a un administrador que configure los datos de la empresa.", mainWindow)
 				}
 			})
+		} else if issuer != nil {
+			ui.warnCertificateExpiry(ctx, mainWindow)
 		}
 	}()
 
@@ -357,6 +380,30 @@ func (ui *UI) openMainWindow() {
 	}()
 }
 
+// warnCertificateExpiry avisa desde CertificateWarningDays días antes de que venza la firma
+// electrónica, y cuando ya venció.
+func (ui *UI) warnCertificateExpiry(ctx context.Context, win fyne.Window) {
+	info, err := ui.Services.IssuerService.GetCertificateInfo(ctx)
+	if err != nil {
+		ui.errorLogger.Printf("Failed to read the signature certificate: %v", err)
+		return
+	}
+	now := time.Now()
+	if info == nil || !(info.Expired(now) || info.ExpiresSoon(now)) {
+		return
+	}
+
+	message := certificateStatus(info, now)
+	if ui.currentUser.CanConfigureSystem() {
+		message += "\nCargue el nuevo certificado en la pestaña Configuración SRI."
+	} else {
+		message += "\nPor favor, avise a un administrador."
+	}
+	fyne.Do(func() {
+		dialog.ShowInformation("Firma Electrónica", message, win)
+	})
+}
+
 func (ui *UI) lazyLoadTabsContent(tabs *container.AppTabs) {
 	tabs.OnSelected = func(item *container.TabItem) {
 		// Helper to check if content is a placeholder label
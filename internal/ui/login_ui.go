@@ -40,17 +40,20 @@ func (ui *UI) makeLoginUI(loginWindow fyne.Window) fyne.CanvasObject {
 			}
 			ui.currentUser = user
 
-			// Open main window first
-			ui.openMainWindow()
+			// Each session works on the books of a single company
+			ui.chooseCompany(loginWindow, false, func() {
+				// Open main window first
+				ui.openMainWindow()
 
-			// Hide login window immediately to give visual feedback
-			loginWindow.Hide()
+				// Hide login window immediately to give visual feedback
+				loginWindow.Hide()
 
-			// Close it slightly later to ensure events are finished
-			go func() {
-				time.Sleep(100 * time.Millisecond)
-				loginWindow.Close()
-			}()
+				// Close it slightly later to ensure events are finished
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					loginWindow.Close()
+				}()
+			})
 		},
 	}
 
@@ -29,6 +29,11 @@ func (m *MockUserService) UpdateUser(ctx context.Context, id int, username, pass
 	return args.Error(0)
 }
 
+func (m *MockUserService) SetDefaultEmissionPoint(ctx context.Context, id int, establishmentCode, emissionPointCode string, currentUser *domain.User) error {
+	args := m.Called(ctx, id, establishmentCode, emissionPointCode, currentUser)
+	return args.Error(0)
+}
+
 func (m *MockUserService) DeleteUser(ctx context.Context, id int, currentUser *domain.User) error {
 	args := m.Called(ctx, id, currentUser)
 	return args.Error(0)
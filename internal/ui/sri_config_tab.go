@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -12,6 +15,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/nelsonmarro/verith/internal/domain"
+	"github.com/nelsonmarro/verith/internal/sri"
 	"github.com/nelsonmarro/verith/internal/ui/componets"
 )
 
@@ -35,9 +39,22 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 	legalCard := widget.NewCard("Información Legal y Matriz", "Datos tal como constan en su RUC", legalForm)
 
 	// --- SECCIÓN 2: CONFIGURACIÓN DE EMISIÓN ---
-	taxOptions := []string{"Ninguno (Manual)", "IVA 15%", "IVA 13%", "IVA 8%", "IVA 5%", "IVA 0%", "No Objeto (6)", "Exento (7)"}
-	defaultTaxSelect := widget.NewSelect(taxOptions, nil)
-	defaultTaxSelect.SetSelected("Ninguno (Manual)")
+	// Opciones de IVA por defecto: "Ninguno" más las tarifas vigentes del catálogo
+	const noDefaultTax = "Ninguno (Manual)"
+	var taxRates domain.TaxRateTable
+	taxOptions := func() []string {
+		options := []string{noDefaultTax}
+		for _, r := range taxRates {
+			options = append(options, r.Label())
+		}
+		return options
+	}
+	defaultTaxSelect := widget.NewSelect(taxOptions(), nil)
+	defaultTaxSelect.SetSelected(noDefaultTax)
+
+	// Propina sugerida en ventas (ej. 10% de servicio en restaurantes); vacío o 0 la desactiva
+	defaultTipEntry := widget.NewEntry()
+	defaultTipEntry.SetPlaceHolder(fmt.Sprintf("0 = desactivada, %g = servicio legal", domain.LegalServiceTipRate))
 
 	estabCodeEntry := widget.NewEntry()
 	estabCodeEntry.SetText("001")
@@ -73,6 +90,7 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 			widget.NewFormItem("Régimen RIMPE", rimpeSelect),
 			widget.NewFormItem("Ambiente SRI", envSelect),
 			widget.NewFormItem("IVA Predeterminado", defaultTaxSelect),
+			widget.NewFormItem("Propina Sugerida (%)", defaultTipEntry),
 			widget.NewFormItem("Nro. Resolución", contribEntry),
 			widget.NewFormItem("", keepAccCheck),
 		),
@@ -101,7 +119,51 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 		widget.NewFormItem("Archivo Firma", container.NewBorder(nil, nil, nil, p12Btn, p12Label)),
 		widget.NewFormItem("Contraseña", passwordEntry),
 	)
-	securityCard := widget.NewCard("Firma Electrónica", "Certificado digital requerido para validez legal", securityForm)
+
+	// Datos del certificado guardado, leídos del .p12
+	certSubject := widget.NewLabel("-")
+	certID := widget.NewLabel("-")
+	certIssuer := widget.NewLabel("-")
+	certValidity := widget.NewLabel("-")
+	certStatus := widget.NewLabelWithStyle("Sin certificado configurado", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	certStatus.Wrapping = fyne.TextWrapWord
+	certForm := widget.NewForm(
+		widget.NewFormItem("Titular", certSubject),
+		widget.NewFormItem("RUC / Cédula", certID),
+		widget.NewFormItem("Entidad Emisora", certIssuer),
+		widget.NewFormItem("Vigencia", certValidity),
+		widget.NewFormItem("Estado", certStatus),
+	)
+	loadCertificate := func() {
+		go func() {
+			info, err := ui.Services.IssuerService.GetCertificateInfo(context.Background())
+			fyne.Do(func() {
+				certSubject.SetText("-")
+				certID.SetText("-")
+				certIssuer.SetText("-")
+				certValidity.SetText("-")
+				switch {
+				case err != nil:
+					certStatus.SetText(fmt.Sprintf("No se pudo leer el certificado: %v", err))
+				case info == nil:
+					certStatus.SetText("Sin certificado configurado")
+				default:
+					certSubject.SetText(info.Subject)
+					certID.SetText(info.Identification)
+					certIssuer.SetText(info.Issuer)
+					certValidity.SetText(fmt.Sprintf("%s al %s", info.NotBefore.Format(componets.AppDateFormat), info.NotAfter.Format(componets.AppDateFormat)))
+					certStatus.SetText(certificateStatus(info, time.Now()))
+				}
+			})
+		}()
+	}
+	loadCertificate()
+
+	securityCard := widget.NewCard("Firma Electrónica", "Certificado digital requerido para validez legal", container.NewVBox(
+		securityForm,
+		widget.NewSeparator(),
+		certForm,
+	))
 
 	// --- SECCIÓN 4: IDENTIDAD VISUAL ---
 	logoLabel := widget.NewLabel("No seleccionado")
@@ -123,29 +185,38 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 	)
 	logoCard := widget.NewCard("Identidad Visual", "Personalización de comprobantes", logoContainer)
 
+	// --- SECCIÓN 5: INFORMACIÓN ADICIONAL ---
+	fieldsEditor := componets.NewAdditionalFieldsEditor(ui.mainWindow, domain.MaxIssuerAdditionalFields)
+	fieldsContainer := container.NewVBox(
+		widget.NewLabel("Se imprimen en todos sus comprobantes junto con la dirección, teléfono y email del cliente."),
+		fieldsEditor.GetContent(),
+	)
+	fieldsCard := widget.NewCard("Información Adicional", "Campos por defecto (ej. Teléfono, Sitio Web, Cuenta Bancaria)", fieldsContainer)
+
 	// --- CARGAR DATOS EXISTENTES ---
 	go func() {
 		ctx := context.Background()
+		if rates, err := ui.Services.IssuerService.GetTaxRates(ctx); err == nil {
+			fyne.Do(func() {
+				taxRates = rates.InForce(time.Now())
+				defaultTaxSelect.SetOptions(taxOptions())
+			})
+		} else {
+			fyne.Do(func() { dialog.ShowError(err, ui.mainWindow) })
+		}
 		currentIssuer, _ := ui.Services.IssuerService.GetIssuerConfig(ctx)
 		if currentIssuer != nil {
 			fyne.Do(func() {
-				switch currentIssuer.DefaultTaxRate {
-				case 4:
-					defaultTaxSelect.SetSelected("IVA 15%")
-				case 10:
-					defaultTaxSelect.SetSelected("IVA 13%")
-				case 8:
-					defaultTaxSelect.SetSelected("IVA 8%")
-				case 5:
-					defaultTaxSelect.SetSelected("IVA 5%")
-				case 0:
-					defaultTaxSelect.SetSelected("IVA 0%")
-				case 6:
-					defaultTaxSelect.SetSelected("No Objeto (6)")
-				case 7:
-					defaultTaxSelect.SetSelected("Exento (7)")
-				default:
-					defaultTaxSelect.SetSelected("Ninguno (Manual)")
+				defaultTaxSelect.SetSelected(noDefaultTax)
+				for i, r := range taxRates {
+					if r.Code == currentIssuer.DefaultTaxRate {
+						defaultTaxSelect.SetSelectedIndex(i + 1)
+						break
+					}
+				}
+
+				if currentIssuer.DefaultTipRate > 0 {
+					defaultTipEntry.SetText(strconv.FormatFloat(currentIssuer.DefaultTipRate, 'f', -1, 64))
 				}
 
 				rucEntry.SetText(currentIssuer.RUC)
@@ -172,6 +243,7 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 					logoPath = currentIssuer.LogoPath
 					logoLabel.SetText(filepath.Base(logoPath))
 				}
+				fieldsEditor.SetFields(currentIssuer.AdditionalFields)
 			})
 		}
 	}()
@@ -193,21 +265,18 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 		}
 
 		taxRate := -1
-		switch defaultTaxSelect.Selected {
-		case "IVA 15%":
-			taxRate = 4
-		case "IVA 13%":
-			taxRate = 10
-		case "IVA 8%":
-			taxRate = 8
-		case "IVA 5%":
-			taxRate = 5
-		case "IVA 0%":
-			taxRate = 0
-		case "No Objeto (6)":
-			taxRate = 6
-		case "Exento (7)":
-			taxRate = 7
+		if i := defaultTaxSelect.SelectedIndex(); i > 0 {
+			taxRate = taxRates[i-1].Code
+		}
+
+		var tipRate float64
+		if text := strings.TrimSpace(defaultTipEntry.Text); text != "" {
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil || v < 0 || v > 100 {
+				dialog.ShowError(fmt.Errorf("el porcentaje de propina debe estar entre 0 y 100"), ui.mainWindow)
+				return
+			}
+			tipRate = v
 		}
 
 		issuer := &domain.Issuer{
@@ -225,6 +294,8 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 			SignaturePath:        p12Path,
 			LogoPath:             logoPath,
 			DefaultTaxRate:       taxRate,
+			DefaultTipRate:       tipRate,
+			AdditionalFields:     fieldsEditor.Fields(),
 			IsActive:             true,
 		}
 
@@ -232,6 +303,7 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 			return ui.Services.IssuerService.SaveIssuerConfig(ctx, issuer, passwordEntry.Text)
 		}, func() {
 			dialog.ShowInformation("Éxito", "Configuración actualizada correctamente.", ui.mainWindow)
+			loadCertificate()
 		})
 	})
 	saveBtn.Importance = widget.HighImportance
@@ -242,8 +314,21 @@ func (ui *UI) makeSriConfigTab() fyne.CanvasObject {
 		emissionCard,
 		securityCard,
 		logoCard,
+		fieldsCard,
 		container.NewPadded(saveBtn),
 	)
 
 	return container.NewVScroll(container.NewPadded(content))
 }
+
+// certificateStatus resume la vigencia del certificado de firma.
+func certificateStatus(info *sri.CertificateInfo, now time.Time) string {
+	switch {
+	case info.Expired(now):
+		return fmt.Sprintf("❌ Vencido desde el %s. No se pueden firmar comprobantes.", info.NotAfter.Format(componets.AppDateFormat))
+	case info.ExpiresSoon(now):
+		return fmt.Sprintf("⚠ Vence en %d días. Renueve su firma electrónica.", info.DaysToExpiry(now))
+	default:
+		return fmt.Sprintf("✅ Vigente (%d días restantes)", info.DaysToExpiry(now))
+	}
+}
@@ -127,6 +127,12 @@ func (ui *UI) generateSummary() {
 		updateMetricText(ui.summaryTotalIncome, summary.TotalIncome, domain.Income)
 		updateMetricText(ui.summaryTotalExpenses, summary.TotalExpenses, domain.Outcome)
 		updateMetricText(ui.summaryNetProfitLoss, summary.NetProfitLoss, "Net")
+		if summary.TotalTips.IsPositive() {
+			ui.summaryTipsLabel.SetText(fmt.Sprintf("Los ingresos incluyen $%s de propinas por servicio", summary.TotalTips.StringFixed(2)))
+			ui.summaryTipsLabel.Show()
+		} else {
+			ui.summaryTipsLabel.Hide()
+		}
 
 		// Update Charts using NEW Renderer
 		ui.summaryChartsContainer.Objects = nil
@@ -50,7 +50,14 @@ func (ui *UI) makeKPIGrid() fyne.CanvasObject {
 	card2 := widget.NewCard("Egresos", "", container.NewCenter(ui.summaryTotalExpenses))
 	card3 := widget.NewCard("Neto", "", container.NewCenter(ui.summaryNetProfitLoss))
 
-	return container.NewGridWithColumns(3, card1, card2, card3)
+	// Solo visible cuando en el periodo se cobraron propinas
+	ui.summaryTipsLabel = widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Italic: true})
+	ui.summaryTipsLabel.Hide()
+
+	return container.NewVBox(
+		container.NewGridWithColumns(3, card1, card2, card3),
+		ui.summaryTipsLabel,
+	)
 }
 
 // makeFilterBar crea una versión horizontal y compacta de los filtros
@@ -10,6 +10,7 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/nelsonmarro/verith/internal/domain"
 	"github.com/nelsonmarro/verith/internal/ui/componets/user"
 )
 
@@ -83,11 +84,13 @@ func (ui *UI) createUserItem() fyne.CanvasObject {
 	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 	deleteBtn.Importance = widget.DangerImportance
 
+	pointBtn := widget.NewButtonWithIcon("", theme.HomeIcon(), nil)
+
 	return container.NewGridWithColumns(4,
 		widget.NewLabel("username template"),
 		widget.NewLabel("full name template"),
 		widget.NewLabel("role template"),
-		container.NewHBox(editBtn, deleteBtn),
+		container.NewHBox(editBtn, deleteBtn, pointBtn),
 	)
 }
 
@@ -101,8 +104,12 @@ func (ui *UI) updateUserItem(i widget.ListItemID, o fyne.CanvasObject) {
 	fullNameLabel := grid.Objects[1].(*widget.Label)
 	fullNameLabel.SetText(fmt.Sprintf("%s %s", userToUpdate.FirstName, userToUpdate.LastName))
 
+	roleText := string(userToUpdate.Role)
+	if userToUpdate.DefaultEstablishmentCode != nil && userToUpdate.DefaultEmissionPointCode != nil {
+		roleText += " (" + domain.EmissionPointLabel(*userToUpdate.DefaultEstablishmentCode, *userToUpdate.DefaultEmissionPointCode) + ")"
+	}
 	roleLabel := grid.Objects[2].(*widget.Label)
-	roleLabel.SetText(string(userToUpdate.Role))
+	roleLabel.SetText(roleText)
 
 	actionsContainer := grid.Objects[3].(*fyne.Container)
 	editBtn := actionsContainer.Objects[0].(*widget.Button)
@@ -117,6 +124,13 @@ func (ui *UI) updateUserItem(i widget.ListItemID, o fyne.CanvasObject) {
 		dialogHandler.Show()
 	}
 
+	// The admin account can also sell from a register, so its point stays editable
+	pointBtn := actionsContainer.Objects[2].(*widget.Button)
+	pointBtn.OnTapped = func() {
+		dialogHandler := user.NewEmissionPointDialog(ui.mainWindow, ui.errorLogger, ui.Services.UserService, ui.Services.IssuerService, func() { ui.loadUsers() }, ui.currentUser, &userToUpdate)
+		dialogHandler.Show()
+	}
+
 	if userToUpdate.Username == "admin" {
 		editBtn.Disable()
 		deleteBtn.Disable()
@@ -0,0 +1,3 @@
+-- Documento al que modifica una Nota de Débito (u otro comprobante que referencie una factura)
+ALTER TABLE transactions
+ADD COLUMN related_transaction_id INTEGER REFERENCES transactions(id) NULL;
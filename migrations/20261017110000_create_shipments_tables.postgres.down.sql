@@ -0,0 +1,7 @@
+DELETE FROM electronic_receipts WHERE transaction_id IS NULL;
+ALTER TABLE electronic_receipts DROP COLUMN IF EXISTS shipment_id;
+ALTER TABLE electronic_receipts ALTER COLUMN transaction_id SET NOT NULL;
+
+DROP TABLE IF EXISTS shipment_items;
+DROP TABLE IF EXISTS shipment_recipients;
+DROP TABLE IF EXISTS shipments;
@@ -0,0 +1,49 @@
+CREATE TABLE shipments (
+  id SERIAL PRIMARY KEY,
+  transaction_id INT NULL, -- Factura de origen (opcional)
+  carrier_id INT NOT NULL, -- Transportista (tax_payers)
+  plate VARCHAR(20) NOT NULL,
+  departure_address TEXT NOT NULL,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  created_by_id INT NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE SET NULL,
+  FOREIGN KEY (carrier_id) REFERENCES tax_payers (id) ON DELETE RESTRICT,
+  FOREIGN KEY (created_by_id) REFERENCES users (id) ON DELETE RESTRICT
+);
+
+CREATE TABLE shipment_recipients (
+  id SERIAL PRIMARY KEY,
+  shipment_id INT NOT NULL,
+  tax_payer_id INT NOT NULL,
+  arrival_address TEXT NOT NULL,
+  reason TEXT NOT NULL, -- motivoTraslado
+  route TEXT,
+  support_doc_number VARCHAR(17), -- 001-001-000000123 de la factura que sustenta el traslado
+  support_doc_date DATE,
+  support_doc_auth_key VARCHAR(49),
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (shipment_id) REFERENCES shipments (id) ON DELETE CASCADE,
+  FOREIGN KEY (tax_payer_id) REFERENCES tax_payers (id) ON DELETE RESTRICT
+);
+
+CREATE TABLE shipment_items (
+  id SERIAL PRIMARY KEY,
+  recipient_id INT NOT NULL,
+  code VARCHAR(25),
+  description TEXT NOT NULL,
+  quantity NUMERIC(15, 6) NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (recipient_id) REFERENCES shipment_recipients (id) ON DELETE CASCADE
+);
+
+-- La Guía de Remisión no es una transacción financiera: su comprobante se vincula al envío
+ALTER TABLE electronic_receipts ALTER COLUMN transaction_id DROP NOT NULL;
+ALTER TABLE electronic_receipts ADD COLUMN shipment_id INT NULL REFERENCES shipments (id) ON DELETE CASCADE;
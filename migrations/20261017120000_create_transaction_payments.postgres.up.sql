@@ -0,0 +1,15 @@
+-- Formas de pago de la factura (tabla 24 de la ficha técnica del SRI)
+CREATE TABLE transaction_payments (
+  id SERIAL PRIMARY KEY,
+  transaction_id INT NOT NULL,
+  payment_method VARCHAR(2) NOT NULL, -- 01, 15, 16, 17, 19, 20, 21
+  amount NUMERIC(15, 2) NOT NULL,
+  term INT NOT NULL DEFAULT 0, -- plazo; 0 = contado
+  time_unit VARCHAR(10) NOT NULL DEFAULT '', -- dias, meses
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_transaction_payments_transaction_id ON transaction_payments (transaction_id);
@@ -0,0 +1,2 @@
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS discount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS discount;
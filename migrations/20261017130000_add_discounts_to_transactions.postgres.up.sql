@@ -0,0 +1,5 @@
+-- Descuento global de la factura (se prorratea entre los ítems al emitir)
+ALTER TABLE transactions ADD COLUMN discount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- Descuento por línea; subtotal pasa a ser quantity * unit_price - discount
+ALTER TABLE transaction_items ADD COLUMN discount NUMERIC(15, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE issuers DROP COLUMN IF EXISTS default_tip_rate;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tip;
@@ -0,0 +1,5 @@
+-- Propina por servicio (ej. 10% en restaurantes); se suma al total pero no a la base del IVA
+ALTER TABLE transactions ADD COLUMN tip NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- Porcentaje de propina sugerido al registrar ventas (0 = desactivado)
+ALTER TABLE issuers ADD COLUMN default_tip_rate NUMERIC(5, 2) DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS transaction_additional_fields;
+DROP TABLE IF EXISTS issuer_additional_fields;
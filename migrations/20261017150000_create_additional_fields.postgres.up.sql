@@ -0,0 +1,27 @@
+-- Campos adicionales (infoAdicional) que el emisor imprime en todos sus comprobantes
+CREATE TABLE issuer_additional_fields (
+  id SERIAL PRIMARY KEY,
+  issuer_id INT NOT NULL,
+  name VARCHAR(300) NOT NULL,
+  value VARCHAR(300) NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (issuer_id) REFERENCES issuers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_issuer_additional_fields_issuer_id ON issuer_additional_fields (issuer_id);
+
+-- Campos adicionales propios de una transacción (número de orden, observaciones, etc.)
+CREATE TABLE transaction_additional_fields (
+  id SERIAL PRIMARY KEY,
+  transaction_id INT NOT NULL,
+  name VARCHAR(300) NOT NULL,
+  value VARCHAR(300) NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_transaction_additional_fields_transaction_id ON transaction_additional_fields (transaction_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_supplier_access_key;
+ALTER TABLE transactions DROP COLUMN IF EXISTS supplier_access_key;
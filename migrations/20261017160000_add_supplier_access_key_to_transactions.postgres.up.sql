@@ -0,0 +1,7 @@
+-- Clave de acceso de la factura del proveedor en las compras importadas desde su XML autorizado.
+-- El índice único impide registrar dos veces la misma factura.
+ALTER TABLE transactions ADD COLUMN supplier_access_key VARCHAR(49);
+
+CREATE UNIQUE INDEX idx_transactions_supplier_access_key
+    ON transactions (supplier_access_key)
+    WHERE supplier_access_key IS NOT NULL;
@@ -0,0 +1,29 @@
+-- Catálogo de tarifas de IVA (tabla 17 de la ficha técnica del SRI) con su vigencia. Un mismo código
+-- puede tener varias filas si el SRI cambia su porcentaje; se usa la vigente a la fecha de la transacción.
+CREATE TABLE tax_rates (
+  id SERIAL PRIMARY KEY,
+  code INT NOT NULL, -- codigoPorcentaje
+  percentage NUMERIC(5, 2) NOT NULL,
+  description VARCHAR(100) NOT NULL,
+  is_general BOOLEAN NOT NULL DEFAULT FALSE, -- Tarifa general de IVA en su período
+  valid_from DATE NOT NULL,
+  valid_to DATE, -- Último día de vigencia; NULL = vigente
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+  CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX idx_tax_rates_code ON tax_rates (code, valid_from);
+
+INSERT INTO tax_rates (code, percentage, description, is_general, valid_from, valid_to) VALUES
+  (0, 0, '0%', FALSE, '2000-01-01', NULL),
+  (2, 12, '12%', TRUE, '2000-01-01', '2016-05-31'),
+  (3, 14, '14%', TRUE, '2016-06-01', '2017-05-31'),
+  (2, 12, '12%', TRUE, '2017-06-01', '2024-03-31'),
+  (4, 15, '15%', TRUE, '2024-04-01', NULL),
+  (5, 5, '5%', FALSE, '2024-04-01', NULL),
+  (6, 0, 'No objeto de impuesto', FALSE, '2000-01-01', NULL),
+  (7, 0, 'Exento de IVA', FALSE, '2000-01-01', NULL),
+  (8, 8, 'IVA diferenciado (turismo)', FALSE, '2023-01-01', NULL),
+  (10, 13, '13%', FALSE, '2025-01-01', NULL);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS irbpnr_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS ice_amount;
+DROP TABLE IF EXISTS transaction_item_taxes;
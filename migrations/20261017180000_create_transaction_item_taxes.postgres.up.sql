@@ -0,0 +1,22 @@
+-- Impuestos distintos del IVA por ítem (tabla 16 de la ficha técnica del SRI): 3 = ICE, 5 = IRBPNR
+CREATE TABLE transaction_item_taxes (
+  id SERIAL PRIMARY KEY,
+  transaction_item_id INT NOT NULL,
+  tax_code INT NOT NULL,
+  percentage_code VARCHAR(4) NOT NULL, -- codigoPorcentaje (ej. 3011, 5001)
+  rate NUMERIC(14, 2) NOT NULL, -- Porcentaje ad valorem o valor por unidad si is_specific
+  is_specific BOOLEAN NOT NULL DEFAULT FALSE,
+  base NUMERIC(15, 2) NOT NULL, -- Valor neto del ítem o número de unidades
+  amount NUMERIC(15, 2) NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (transaction_item_id) REFERENCES transaction_items (id) ON DELETE CASCADE,
+  CHECK (tax_code IN (3, 5))
+);
+
+CREATE INDEX idx_transaction_item_taxes_item_id ON transaction_item_taxes (transaction_item_id);
+
+-- Totales de ICE e IRBPNR de la transacción para los reportes tributarios
+ALTER TABLE transactions ADD COLUMN ice_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN irbpnr_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN IF EXISTS default_emission_point_code;
+ALTER TABLE users DROP COLUMN IF EXISTS default_establishment_code;
+ALTER TABLE transactions DROP COLUMN IF EXISTS emission_point_code;
+ALTER TABLE transactions DROP COLUMN IF EXISTS establishment_code;
+DROP TABLE IF EXISTS establishments;
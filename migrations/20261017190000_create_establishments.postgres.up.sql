@@ -0,0 +1,22 @@
+-- Establecimientos adicionales del emisor, cada uno con su dirección (dirEstablecimiento). El
+-- establecimiento principal sigue configurado en issuers.
+CREATE TABLE establishments (
+  id SERIAL PRIMARY KEY,
+  issuer_id INT NOT NULL,
+  code VARCHAR(3) NOT NULL, -- Ej: 002
+  name VARCHAR(100) NOT NULL,
+  address VARCHAR(300) NOT NULL,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  FOREIGN KEY (issuer_id) REFERENCES issuers (id) ON DELETE CASCADE,
+  UNIQUE (issuer_id, code)
+);
+
+-- Punto de emisión de la factura o nota de crédito; NULL = el del emisor
+ALTER TABLE transactions ADD COLUMN establishment_code VARCHAR(3);
+ALTER TABLE transactions ADD COLUMN emission_point_code VARCHAR(3);
+
+-- Punto de emisión por defecto de cada usuario (ej. su caja)
+ALTER TABLE users ADD COLUMN default_establishment_code VARCHAR(3);
+ALTER TABLE users ADD COLUMN default_emission_point_code VARCHAR(3);
@@ -0,0 +1,29 @@
+DROP INDEX IF EXISTS idx_transactions_company_id;
+DROP INDEX IF EXISTS idx_issuers_company_active;
+
+ALTER TABLE tax_payers DROP CONSTRAINT IF EXISTS tax_payers_company_identification_key;
+DROP INDEX IF EXISTS idx_transactions_supplier_access_key;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS uq_transaction_number;
+DROP INDEX IF EXISTS categories_company_name_type_idx;
+DROP INDEX IF EXISTS accounts_company_name_idx;
+
+-- Solo se conservan los datos de la primera empresa; los demás no caben en los índices globales
+DELETE FROM companies WHERE id <> (SELECT MIN(id) FROM companies);
+
+ALTER TABLE tax_payers ADD CONSTRAINT tax_payers_identification_key UNIQUE (identification);
+CREATE UNIQUE INDEX idx_transactions_supplier_access_key
+    ON transactions (supplier_access_key)
+    WHERE supplier_access_key IS NOT NULL;
+ALTER TABLE transactions ADD CONSTRAINT uq_transaction_number UNIQUE (transaction_number);
+CREATE UNIQUE INDEX categories_name_type_idx ON categories (name, type);
+CREATE UNIQUE INDEX accounts_name_idx ON accounts (name);
+
+ALTER TABLE shipments DROP COLUMN IF EXISTS company_id;
+ALTER TABLE recurring_transactions DROP COLUMN IF EXISTS company_id;
+ALTER TABLE tax_payers DROP COLUMN IF EXISTS company_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS company_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS company_id;
+ALTER TABLE accounts DROP COLUMN IF EXISTS company_id;
+ALTER TABLE issuers DROP COLUMN IF EXISTS company_id;
+
+DROP TABLE IF EXISTS companies;
@@ -0,0 +1,57 @@
+-- Empresas cuyos libros se llevan en la instalación. Los datos existentes pasan a la primera empresa.
+CREATE TABLE companies (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(300) NOT NULL UNIQUE,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO companies (name, created_at, updated_at) VALUES ('Empresa Principal', NOW(), NOW());
+
+ALTER TABLE issuers ADD COLUMN company_id INT REFERENCES companies (id) ON DELETE CASCADE;
+ALTER TABLE accounts ADD COLUMN company_id INT REFERENCES companies (id) ON DELETE CASCADE;
+ALTER TABLE categories ADD COLUMN company_id INT REFERENCES companies (id) ON DELETE CASCADE;
+ALTER TABLE transactions ADD COLUMN company_id INT REFERENCES companies (id) ON DELETE CASCADE;
+ALTER TABLE tax_payers ADD COLUMN company_id INT REFERENCES companies (id) ON DELETE CASCADE;
+ALTER TABLE recurring_transactions ADD COLUMN company_id INT REFERENCES companies (id) ON DELETE CASCADE;
+ALTER TABLE shipments ADD COLUMN company_id INT REFERENCES companies (id) ON DELETE CASCADE;
+
+UPDATE issuers SET company_id = (SELECT MIN(id) FROM companies);
+UPDATE accounts SET company_id = (SELECT MIN(id) FROM companies);
+UPDATE categories SET company_id = (SELECT MIN(id) FROM companies);
+UPDATE transactions SET company_id = (SELECT MIN(id) FROM companies);
+UPDATE tax_payers SET company_id = (SELECT MIN(id) FROM companies);
+UPDATE recurring_transactions SET company_id = (SELECT MIN(id) FROM companies);
+UPDATE shipments SET company_id = (SELECT MIN(id) FROM companies);
+
+ALTER TABLE issuers ALTER COLUMN company_id SET NOT NULL;
+ALTER TABLE accounts ALTER COLUMN company_id SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN company_id SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN company_id SET NOT NULL;
+ALTER TABLE tax_payers ALTER COLUMN company_id SET NOT NULL;
+ALTER TABLE recurring_transactions ALTER COLUMN company_id SET NOT NULL;
+ALTER TABLE shipments ALTER COLUMN company_id SET NOT NULL;
+
+-- Los nombres, números y cédulas solo deben ser únicos dentro de cada empresa
+DROP INDEX IF EXISTS accounts_name_idx;
+CREATE UNIQUE INDEX accounts_company_name_idx ON accounts (company_id, name);
+
+DROP INDEX IF EXISTS categories_name_type_idx;
+CREATE UNIQUE INDEX categories_company_name_type_idx ON categories (company_id, name, type);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS uq_transaction_number;
+ALTER TABLE transactions ADD CONSTRAINT uq_transaction_number UNIQUE (company_id, transaction_number);
+
+DROP INDEX IF EXISTS idx_transactions_supplier_access_key;
+CREATE UNIQUE INDEX idx_transactions_supplier_access_key
+    ON transactions (company_id, supplier_access_key)
+    WHERE supplier_access_key IS NOT NULL;
+
+ALTER TABLE tax_payers DROP CONSTRAINT IF EXISTS tax_payers_identification_key;
+ALTER TABLE tax_payers ADD CONSTRAINT tax_payers_company_identification_key UNIQUE (company_id, identification);
+
+-- Un solo emisor activo por empresa
+CREATE UNIQUE INDEX idx_issuers_company_active ON issuers (company_id) WHERE is_active = TRUE;
+
+CREATE INDEX idx_transactions_company_id ON transactions (company_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS electronic_receipt_events;
+
+ALTER TABLE electronic_receipts DROP CONSTRAINT IF EXISTS electronic_receipts_sri_status_check;
@@ -0,0 +1,27 @@
+-- Los rechazos se guardaban con el estado del SRI tal cual; el ciclo del comprobante usa RECHAZADA
+UPDATE electronic_receipts SET sri_status = 'RECHAZADA' WHERE sri_status IN ('NO AUTORIZADO', 'RECHAZADO');
+
+ALTER TABLE electronic_receipts ADD CONSTRAINT electronic_receipts_sri_status_check CHECK (
+  sri_status IN ('PENDIENTE', 'ERROR_ENVIO', 'ERROR_RED', 'RECIBIDA', 'DEVUELTA', 'EN PROCESO', 'AUTORIZADO', 'RECHAZADA')
+);
+
+-- Historia de cada comprobante: creación, envíos al SRI con su respuesta y re-emisiones
+CREATE TABLE electronic_receipt_events (
+  id SERIAL PRIMARY KEY,
+  receipt_id INT NOT NULL,
+  access_key VARCHAR(49) NOT NULL, -- Clave usada en ese momento; una re-emisión puede cambiarla
+  from_status VARCHAR(20), -- NULL en la creación
+  to_status VARCHAR(20) NOT NULL,
+  message TEXT,
+  user_id INT, -- NULL cuando lo originó la sincronización automática
+  created_at TIMESTAMP NOT NULL,
+
+  FOREIGN KEY (receipt_id) REFERENCES electronic_receipts (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_electronic_receipt_events_receipt_id ON electronic_receipt_events (receipt_id, created_at);
+
+-- Los comprobantes existentes empiezan su historia con el estado que tienen hoy
+INSERT INTO electronic_receipt_events (receipt_id, access_key, to_status, message, created_at)
+SELECT id, access_key, sri_status, sri_message, updated_at FROM electronic_receipts;
@@ -0,0 +1 @@
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS returned_item_id;
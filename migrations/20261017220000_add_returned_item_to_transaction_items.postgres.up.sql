@@ -0,0 +1,19 @@
+-- Ítem de la factura que devuelve cada línea de una devolución parcial, para no devolver más
+-- unidades de las facturadas entre varias notas de crédito
+ALTER TABLE transaction_items ADD COLUMN returned_item_id INT REFERENCES transaction_items(id);
+
+-- Las devoluciones ya registradas se vinculan por descripción y precio unitario
+UPDATE transaction_items ri
+SET returned_item_id = (
+    SELECT oi.id FROM transaction_items oi
+    WHERE oi.transaction_id = rt.related_transaction_id
+      AND oi.description = ri.description
+      AND oi.unit_price = ri.unit_price
+    ORDER BY oi.id
+    LIMIT 1
+)
+FROM transactions rt
+JOIN categories c ON c.id = rt.category_id
+WHERE ri.transaction_id = rt.id
+  AND rt.related_transaction_id IS NOT NULL
+  AND c.type = 'Egreso';